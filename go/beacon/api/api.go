@@ -4,6 +4,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
@@ -27,9 +28,14 @@ const (
 	BackendVRF = "vrf"
 )
 
-// ErrBeaconNotAvailable is the error returned when a beacon is not
-// available for the requested height for any reason.
-var ErrBeaconNotAvailable = errors.New(ModuleName, 1, "beacon: random beacon not available")
+var (
+	// ErrBeaconNotAvailable is the error returned when a beacon is not
+	// available for the requested height for any reason.
+	ErrBeaconNotAvailable = errors.New(ModuleName, 1, "beacon: random beacon not available")
+
+	// ErrInvalidArgument is the error returned on malformed argument(s).
+	ErrInvalidArgument = errors.New(ModuleName, 2, "beacon: invalid argument")
+)
 
 // EpochTime is the number of intervals (epochs) since a fixed instant
 // in time/block height (epoch date/height).
@@ -49,6 +55,49 @@ type EpochTimeState struct {
 	Height int64     `json:"height"`
 }
 
+// EpochTimingState is the epoch timing state used when epoch intervals are
+// derived from a target epoch duration.
+type EpochTimingState struct {
+	// Interval is the interval (in blocks) of the current epoch.
+	Interval int64 `json:"interval"`
+	// Time is the consensus time (in seconds since the UNIX epoch) at which
+	// the current epoch started.
+	Time int64 `json:"time"`
+}
+
+// MaxEpochScheduleCount is the maximum number of upcoming epoch transitions that can be predicted
+// by a single epoch schedule query.
+const MaxEpochScheduleCount = 100
+
+// EpochScheduleQuery is an epoch schedule query.
+type EpochScheduleQuery struct {
+	// Height is the consensus height to query at.
+	Height int64 `json:"height"`
+	// Count is the number of upcoming epoch transitions to predict (at most MaxEpochScheduleCount).
+	Count uint64 `json:"count"`
+}
+
+// EpochScheduleEntry is a (predicted) epoch transition.
+type EpochScheduleEntry struct {
+	// Epoch is the epoch that starts at the given height.
+	Epoch EpochTime `json:"epoch"`
+	// Height is the height of the first block of the epoch.
+	Height int64 `json:"height"`
+	// Time is the estimated consensus time of the first block of the epoch.
+	Time time.Time `json:"time"`
+}
+
+// EpochSchedule is the predicted schedule of upcoming epoch transitions.
+//
+// Predictions are based on the current consensus parameters and the average
+// block time observed during the current epoch, so they are only estimates.
+type EpochSchedule struct {
+	// Current is the start of the current epoch.
+	Current EpochScheduleEntry `json:"current"`
+	// Upcoming are the predicted upcoming epoch transitions.
+	Upcoming []EpochScheduleEntry `json:"upcoming"`
+}
+
 // Backend is a random beacon/time keeping implementation.
 type Backend interface {
 	// GetBaseEpoch returns the base epoch.
@@ -70,6 +119,10 @@ type Backend interface {
 	// epoch.
 	GetEpochBlock(context.Context, EpochTime) (int64, error)
 
+	// GetEpochSchedule returns the predicted schedule of upcoming epoch
+	// transitions (as heights and timestamps).
+	GetEpochSchedule(context.Context, *EpochScheduleQuery) (*EpochSchedule, error)
+
 	// WaitEpoch waits for a specific epoch.
 	//
 	// Note that an epoch is considered reached even if any epoch greater
@@ -135,49 +188,76 @@ type ConsensusParameters struct {
 	VRFParameters *VRFParameters `json:"vrf_parameters,omitempty"`
 }
 
+// ConsensusParameterChanges are allowed beacon consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// VRFParameters are the new parameters for the VRF backend.
+	VRFParameters *VRFParameters `json:"vrf_parameters,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.VRFParameters == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	if err := c.VRFParameters.SanityCheck(); err != nil {
+		return fmt.Errorf("invalid VRF parameters: %w", err)
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.VRFParameters != nil {
+		if params.Backend != BackendVRF {
+			return fmt.Errorf("VRF parameters can only be changed when using the VRF backend")
+		}
+		params.VRFParameters = c.VRFParameters
+	}
+	return nil
+}
+
 // InsecureParameters are the beacon parameters for the insecure backend.
 type InsecureParameters struct {
 	// Interval is the epoch interval (in blocks).
 	Interval int64 `json:"interval,omitempty"`
 }
 
-// SanityCheck does basic sanity checking on the genesis state.
-func (g *Genesis) SanityCheck() error {
-	switch g.Parameters.Backend {
+// SanityCheck performs a sanity check on the consensus parameters.
+func (p *ConsensusParameters) SanityCheck() error {
+	switch p.Backend {
 	case BackendInsecure:
-		params := g.Parameters.InsecureParameters
+		params := p.InsecureParameters
 		if params == nil {
-			return fmt.Errorf("beacon: sanity check failed: insecure backend not configured")
+			return fmt.Errorf("insecure backend not configured")
 		}
 
-		if params.Interval <= 0 && !g.Parameters.DebugMockBackend {
-			return fmt.Errorf("beacon: sanity check failed: epoch interval must be > 0")
+		if params.Interval <= 0 && !p.DebugMockBackend {
+			return fmt.Errorf("epoch interval must be > 0")
 		}
 	case BackendVRF:
-		params := g.Parameters.VRFParameters
+		params := p.VRFParameters
 		if params == nil {
-			return fmt.Errorf("beacon: sanity check failed: VRF backend not configured")
-		}
-
-		if params.AlphaHighQualityThreshold == 0 {
-			return fmt.Errorf("beacon: sanity check failed: alpha threshold must be > 0")
+			return fmt.Errorf("VRF backend not configured")
 		}
-		if params.Interval <= 0 {
-			return fmt.Errorf("beacon: sanity check failed: epoch interval must be > 0")
-		}
-		if params.ProofSubmissionDelay <= 0 {
-			return fmt.Errorf("beacon: sanity check failed: submission delay must be > 0")
-		}
-		if params.ProofSubmissionDelay >= params.Interval {
-			return fmt.Errorf("beacon: sanity check failed: submission delay must be < epoch interval")
+		if err := params.SanityCheck(); err != nil {
+			return err
 		}
 	default:
-		return fmt.Errorf("beacon: sanity check failed: unknown backend: '%s'", g.Parameters.Backend)
+		return fmt.Errorf("unknown backend: '%s'", p.Backend)
 	}
 
-	unsafeFlags := g.Parameters.DebugMockBackend
+	unsafeFlags := p.DebugMockBackend
 	if unsafeFlags && !flags.DebugDontBlameOasis() {
-		return fmt.Errorf("beacon: sanity check failed: one or more unsafe debug flags set")
+		return fmt.Errorf("one or more unsafe debug flags set")
+	}
+
+	return nil
+}
+
+// SanityCheck does basic sanity checking on the genesis state.
+func (g *Genesis) SanityCheck() error {
+	if err := g.Parameters.SanityCheck(); err != nil {
+		return fmt.Errorf("beacon: sanity check failed: %w", err)
 	}
 
 	if g.Base == EpochInvalid {
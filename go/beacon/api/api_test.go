@@ -33,3 +33,28 @@ func TestDiff(t *testing.T) {
 		require.Equal(tc.e1.AbsDiff(tc.e2), tc.diff)
 	}
 }
+
+func TestConsensusParametersSanityCheck(t *testing.T) {
+	require := require.New(t)
+
+	params := ConsensusParameters{
+		Backend:            BackendInsecure,
+		InsecureParameters: &InsecureParameters{Interval: 100},
+	}
+	require.NoError(params.SanityCheck(), "SanityCheck")
+
+	params.InsecureParameters.Interval = 0
+	require.Error(params.SanityCheck(), "SanityCheck should fail with zero epoch interval")
+
+	params = ConsensusParameters{Backend: BackendVRF}
+	require.Error(params.SanityCheck(), "SanityCheck should fail without VRF parameters")
+
+	params.VRFParameters = &VRFParameters{AlphaHighQualityThreshold: 1, Interval: 50, ProofSubmissionDelay: 50}
+	require.Error(params.SanityCheck(), "SanityCheck should fail with invalid VRF parameters")
+
+	params.VRFParameters.ProofSubmissionDelay = 10
+	require.NoError(params.SanityCheck(), "SanityCheck")
+
+	params = ConsensusParameters{Backend: "unknown"}
+	require.Error(params.SanityCheck(), "SanityCheck should fail with unknown backend")
+}
@@ -22,6 +22,8 @@ var (
 	methodGetFutureEpoch = serviceName.NewMethod("GetFutureEpoch", int64(0))
 	// methodGetEpochBlock is the GetEpochBlock method.
 	methodGetEpochBlock = serviceName.NewMethod("GetEpochBlock", EpochTime(0))
+	// methodGetEpochSchedule is the GetEpochSchedule method.
+	methodGetEpochSchedule = serviceName.NewMethod("GetEpochSchedule", EpochScheduleQuery{})
 	// methodWaitEpoch is the WaitEpoch method.
 	methodWaitEpoch = serviceName.NewMethod("WaitEpoch", EpochTime(0))
 	// methodGetBeacon is the GetBeacon method.
@@ -59,6 +61,10 @@ var (
 				MethodName: methodGetEpochBlock.ShortName(),
 				Handler:    handlerGetEpochBlock,
 			},
+			{
+				MethodName: methodGetEpochSchedule.ShortName(),
+				Handler:    handlerGetEpochSchedule,
+			},
 			{
 				MethodName: methodGetBeacon.ShortName(),
 				Handler:    handlerGetBeacon,
@@ -193,6 +199,29 @@ func handlerGetEpochBlock( //nolint:golint
 	return interceptor(ctx, epoch, info, handler)
 }
 
+func handlerGetEpochSchedule( //nolint:golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var query EpochScheduleQuery
+	if err := dec(&query); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Backend).GetEpochSchedule(ctx, &query)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetEpochSchedule.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Backend).GetEpochSchedule(ctx, req.(*EpochScheduleQuery))
+	}
+	return interceptor(ctx, &query, info, handler)
+}
+
 func handlerGetBeacon( //nolint:golint
 	srv interface{},
 	ctx context.Context,
@@ -331,6 +360,14 @@ func (c *beaconClient) GetEpochBlock(ctx context.Context, epoch EpochTime) (int6
 	return rsp, nil
 }
 
+func (c *beaconClient) GetEpochSchedule(ctx context.Context, query *EpochScheduleQuery) (*EpochSchedule, error) {
+	var rsp EpochSchedule
+	if err := c.conn.Invoke(ctx, methodGetEpochSchedule.FullName(), query, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 func (c *beaconClient) WaitEpoch(ctx context.Context, epoch EpochTime) error {
 	return c.conn.Invoke(ctx, methodWaitEpoch.FullName(), epoch, nil)
 }
@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
@@ -39,6 +41,97 @@ type VRFParameters struct {
 
 	// GasCosts are the VRF proof gas costs.
 	GasCosts transaction.Costs `json:"gas_costs,omitempty"`
+
+	// TargetEpochDuration is the target wall-clock duration of an epoch.
+	//
+	// If set, the epoch interval (in blocks) is derived at each epoch
+	// transition from the consensus block time observed during the previous
+	// epoch, so that epochs approximately last the target duration. In this
+	// case Interval is only used as the initial epoch interval.
+	TargetEpochDuration time.Duration `json:"target_epoch_duration,omitempty"`
+
+	// MaxIntervalAdjustment is the maximum change (in percent) of the epoch
+	// interval between two consecutive epochs when TargetEpochDuration is
+	// set.
+	MaxIntervalAdjustment uint8 `json:"max_interval_adjustment,omitempty"`
+}
+
+// IsTimeBased returns true iff the epoch interval is derived from the target
+// epoch duration.
+func (p *VRFParameters) IsTimeBased() bool {
+	return p.TargetEpochDuration > 0
+}
+
+// NextInterval computes the interval (in blocks) of the next epoch, given the
+// interval of the previous epoch and the time it took.
+//
+// The resulting interval is bounded by MaxIntervalAdjustment and is always
+// greater than ProofSubmissionDelay.
+func (p *VRFParameters) NextInterval(interval int64, elapsed time.Duration) int64 {
+	if interval <= 0 {
+		interval = p.Interval
+	}
+
+	maxDelta := interval * int64(p.MaxIntervalAdjustment) / 100
+	if maxDelta < 1 {
+		maxDelta = 1
+	}
+
+	// Consensus time is second-granular, so use seconds to avoid overflows.
+	var next int64
+	switch elapsedSecs := int64(elapsed / time.Second); {
+	case elapsedSecs <= 0:
+		next = interval + maxDelta
+	default:
+		next = interval * int64(p.TargetEpochDuration/time.Second) / elapsedSecs
+	}
+
+	switch {
+	case next > interval+maxDelta:
+		next = interval + maxDelta
+	case next < interval-maxDelta:
+		next = interval - maxDelta
+	}
+	if next <= p.ProofSubmissionDelay {
+		next = p.ProofSubmissionDelay + 1
+	}
+	return next
+}
+
+// SanityCheck performs a sanity check on the VRF parameters.
+func (p *VRFParameters) SanityCheck() error {
+	if p.AlphaHighQualityThreshold == 0 {
+		return fmt.Errorf("alpha threshold must be > 0")
+	}
+	if p.Interval <= 0 {
+		return fmt.Errorf("epoch interval must be > 0")
+	}
+	if p.ProofSubmissionDelay <= 0 {
+		return fmt.Errorf("submission delay must be > 0")
+	}
+	if p.ProofSubmissionDelay >= p.Interval {
+		return fmt.Errorf("submission delay must be < epoch interval")
+	}
+	if p.TargetEpochDuration < 0 {
+		return fmt.Errorf("target epoch duration must be >= 0")
+	}
+	switch p.IsTimeBased() {
+	case true:
+		if p.TargetEpochDuration < time.Second {
+			return fmt.Errorf("target epoch duration must be at least one second")
+		}
+		if p.TargetEpochDuration%time.Second != 0 {
+			return fmt.Errorf("target epoch duration must be a whole number of seconds")
+		}
+		if p.MaxIntervalAdjustment == 0 || p.MaxIntervalAdjustment > 100 {
+			return fmt.Errorf("max interval adjustment must be between 1 and 100")
+		}
+	case false:
+		if p.MaxIntervalAdjustment != 0 {
+			return fmt.Errorf("max interval adjustment requires a target epoch duration")
+		}
+	}
+	return nil
 }
 
 // VRFState is the VRF backend state.
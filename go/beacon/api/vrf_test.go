@@ -0,0 +1,112 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVRFParametersNextInterval(t *testing.T) {
+	require := require.New(t)
+
+	params := VRFParameters{
+		AlphaHighQualityThreshold: 3,
+		Interval:                  100,
+		ProofSubmissionDelay:      20,
+		TargetEpochDuration:       10 * time.Minute,
+		MaxIntervalAdjustment:     10,
+	}
+	require.NoError(params.SanityCheck(), "SanityCheck")
+	require.True(params.IsTimeBased(), "IsTimeBased")
+
+	for _, tc := range []struct {
+		interval int64
+		elapsed  time.Duration
+		expected int64
+		msg      string
+	}{
+		{100, 10 * time.Minute, 100, "on target"},
+		{100, 625 * time.Second, 96, "slightly slow blocks"},
+		{100, 20 * time.Minute, 90, "slow blocks, bounded adjustment"},
+		{100, 5 * time.Minute, 110, "fast blocks, bounded adjustment"},
+		{100, 0, 110, "no elapsed time"},
+		{0, 10 * time.Minute, 100, "missing interval"},
+		{5, 5 * time.Minute, 21, "minimum interval"},
+	} {
+		require.EqualValues(tc.expected, params.NextInterval(tc.interval, tc.elapsed), tc.msg)
+	}
+
+	params.MaxIntervalAdjustment = 0
+	require.Error(params.SanityCheck(), "SanityCheck should fail without max interval adjustment")
+	params.MaxIntervalAdjustment = 101
+	require.Error(params.SanityCheck(), "SanityCheck should fail with too large max interval adjustment")
+	params.MaxIntervalAdjustment = 10
+	params.TargetEpochDuration = time.Millisecond
+	require.Error(params.SanityCheck(), "SanityCheck should fail with sub-second target epoch duration")
+	params.TargetEpochDuration = 90 * time.Second
+	require.NoError(params.SanityCheck(), "SanityCheck")
+	params.TargetEpochDuration = 1500 * time.Millisecond
+	require.Error(params.SanityCheck(), "SanityCheck should fail with fractional target epoch duration")
+	params.TargetEpochDuration = -time.Minute
+	require.Error(params.SanityCheck(), "SanityCheck should fail with negative target epoch duration")
+	params.TargetEpochDuration = 0
+	require.Error(params.SanityCheck(), "SanityCheck should fail with max interval adjustment without target epoch duration")
+	params.MaxIntervalAdjustment = 0
+	require.NoError(params.SanityCheck(), "SanityCheck")
+	require.False(params.IsTimeBased(), "IsTimeBased")
+}
+
+func TestConsensusParameterChanges(t *testing.T) {
+	require := require.New(t)
+
+	vrfParams := VRFParameters{
+		AlphaHighQualityThreshold: 3,
+		Interval:                  100,
+		ProofSubmissionDelay:      20,
+		TargetEpochDuration:       10 * time.Minute,
+		MaxIntervalAdjustment:     10,
+	}
+
+	var changes ConsensusParameterChanges
+	require.Error(changes.SanityCheck(), "SanityCheck should fail for empty changes")
+
+	changes.VRFParameters = &vrfParams
+	require.NoError(changes.SanityCheck(), "SanityCheck")
+
+	// All VRF parameters should be validated.
+	for _, tc := range []struct {
+		modify func(p *VRFParameters)
+		msg    string
+	}{
+		{func(p *VRFParameters) { p.AlphaHighQualityThreshold = 0 }, "zero alpha threshold"},
+		{func(p *VRFParameters) { p.Interval = 0 }, "zero interval"},
+		{func(p *VRFParameters) { p.ProofSubmissionDelay = 0 }, "zero proof submission delay"},
+		{func(p *VRFParameters) { p.ProofSubmissionDelay = p.Interval }, "proof submission delay not below interval"},
+		{func(p *VRFParameters) { p.TargetEpochDuration = time.Millisecond }, "sub-second target epoch duration"},
+		{func(p *VRFParameters) { p.MaxIntervalAdjustment = 0 }, "zero max interval adjustment"},
+		{func(p *VRFParameters) { p.MaxIntervalAdjustment = 101 }, "too large max interval adjustment"},
+		{func(p *VRFParameters) { p.TargetEpochDuration = 0 }, "max interval adjustment without target epoch duration"},
+	} {
+		invalid := vrfParams
+		tc.modify(&invalid)
+		changes.VRFParameters = &invalid
+		require.Error(changes.SanityCheck(), "SanityCheck should fail with %s", tc.msg)
+	}
+
+	// VRF parameters can only be changed when using the VRF backend.
+	changes.VRFParameters = &vrfParams
+	params := ConsensusParameters{
+		Backend:            BackendInsecure,
+		InsecureParameters: &InsecureParameters{Interval: 100},
+	}
+	require.Error(changes.Apply(&params), "Apply should fail for the insecure backend")
+
+	params = ConsensusParameters{
+		Backend:       BackendVRF,
+		VRFParameters: &VRFParameters{AlphaHighQualityThreshold: 1, Interval: 50, ProofSubmissionDelay: 10},
+	}
+	require.NoError(changes.Apply(&params), "Apply")
+	require.Equal(&vrfParams, params.VRFParameters, "VRF parameters should be changed")
+	require.NoError(params.SanityCheck(), "changed parameters should pass sanity check")
+}
@@ -92,6 +92,20 @@ func EpochtimeSetableImplementationTest(t *testing.T, backend api.Backend) {
 	e, err = timeSource.GetEpoch(context.Background(), consensus.HeightLatest)
 	require.NoError(err, "GetEpoch after set")
 	require.Equal(epoch, e, "GetEpoch after set, epoch")
+
+	schedule, err := timeSource.GetEpochSchedule(context.Background(), &api.EpochScheduleQuery{
+		Height: consensus.HeightLatest,
+		Count:  3,
+	})
+	require.NoError(err, "GetEpochSchedule")
+	require.Equal(epoch, schedule.Current.Epoch, "GetEpochSchedule current epoch")
+	require.Empty(schedule.Upcoming, "GetEpochSchedule should not predict mock epoch transitions")
+
+	_, err = timeSource.GetEpochSchedule(context.Background(), &api.EpochScheduleQuery{
+		Height: consensus.HeightLatest,
+		Count:  api.MaxEpochScheduleCount + 1,
+	})
+	require.Error(err, "GetEpochSchedule should fail with a too large count")
 }
 
 // MustAdvanceEpoch advances the epoch and returns the new epoch.
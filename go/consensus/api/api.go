@@ -100,6 +100,10 @@ type ClientBackend interface {
 	// in a block. Use SubmitTxNoWait if you only need to broadcast the transaction.
 	SubmitTx(ctx context.Context, tx *transaction.SignedTransaction) error
 
+	// SubmitMultiSignedTx submits a consensus transaction signed by a multisig account and waits
+	// for the transaction to be included in a block.
+	SubmitMultiSignedTx(ctx context.Context, tx *transaction.MultiSignedTransaction) error
+
 	// StateToGenesis returns the genesis state at the specified block height.
 	StateToGenesis(ctx context.Context, height int64) (*genesis.Document, error)
 
@@ -264,6 +268,11 @@ type Backend interface {
 	//
 	// This may be nil in case checkpoints are disabled.
 	Checkpointer() checkpoint.Checkpointer
+
+	// PauseStatePruning pauses or unpauses consensus state pruning.
+	//
+	// This can be used to make sure that state is not pruned while checkpoints are being created.
+	PauseStatePruning(pause bool) error
 }
 
 // HaltHook is a function that gets called when consensus needs to halt for some reason.
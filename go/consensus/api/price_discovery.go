@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+)
+
+const (
+	// PriceDiscoveryStatic is the name of the static price discovery mechanism.
+	PriceDiscoveryStatic = "static"
+	// PriceDiscoveryDynamic is the name of the dynamic (fee market based) price discovery
+	// mechanism.
+	PriceDiscoveryDynamic = "dynamic"
+)
+
+// DynamicPriceDiscoveryConfig is the dynamic price discovery configuration.
+type DynamicPriceDiscoveryConfig struct {
+	// WindowSize is the number of most recent blocks that are sampled.
+	WindowSize uint64
+	// Percentile is the percentile (0-100) of sampled gas prices that is used as the gas price.
+	Percentile uint8
+	// MinPrice is the minimum gas price that is returned regardless of the sampled prices.
+	MinPrice uint64
+	// IncludeMempool specifies whether transactions in the local mempool should also be sampled.
+	IncludeMempool bool
+}
+
+// ValidateBasic performs basic dynamic price discovery configuration validity checks.
+func (cfg *DynamicPriceDiscoveryConfig) ValidateBasic() error {
+	if cfg.WindowSize == 0 {
+		return fmt.Errorf("window size must be positive")
+	}
+	if cfg.Percentile > 100 {
+		return fmt.Errorf("percentile must be between 0 and 100")
+	}
+	return nil
+}
+
+type blockGasPrices struct {
+	height int64
+	prices []*quantity.Quantity
+}
+
+type dynamicPriceDiscovery struct {
+	sync.Mutex
+
+	backend  ClientBackend
+	cfg      DynamicPriceDiscoveryConfig
+	minPrice quantity.Quantity
+
+	// window contains sampled gas prices of the most recent blocks, ordered by height.
+	window []*blockGasPrices
+
+	logger *logging.Logger
+}
+
+// NewDynamicPriceDiscovery creates a price discovery mechanism which derives the gas price from
+// the fees paid by transactions included in recent blocks and (optionally) transactions waiting
+// in the local mempool.
+//
+// The gas price is the configured percentile of all sampled gas prices over a sliding window of
+// recent blocks, but never lower than the configured minimum price.
+func NewDynamicPriceDiscovery(backend ClientBackend, cfg *DynamicPriceDiscoveryConfig) (PriceDiscovery, error) {
+	if err := cfg.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("submission: invalid dynamic price discovery configuration: %w", err)
+	}
+
+	pd := &dynamicPriceDiscovery{
+		backend: backend,
+		cfg:     *cfg,
+		logger:  logging.GetLogger("consensus/submission/pricediscovery"),
+	}
+	if err := pd.minPrice.FromUint64(cfg.MinPrice); err != nil {
+		return nil, fmt.Errorf("submission: failed to convert minimum gas price: %w", err)
+	}
+	return pd, nil
+}
+
+func (pd *dynamicPriceDiscovery) GasPrice(ctx context.Context) (*quantity.Quantity, error) {
+	pd.Lock()
+	defer pd.Unlock()
+
+	if err := pd.updateWindow(ctx); err != nil {
+		return nil, err
+	}
+
+	var prices []*quantity.Quantity
+	for _, blk := range pd.window {
+		prices = append(prices, blk.prices...)
+	}
+
+	if pd.cfg.IncludeMempool {
+		txs, err := pd.backend.GetUnconfirmedTransactions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("submission: failed to get unconfirmed transactions: %w", err)
+		}
+		for _, rawTx := range txs {
+			if price := rawTxGasPrice(rawTx); price != nil {
+				prices = append(prices, price)
+			}
+		}
+	}
+
+	price := percentile(prices, pd.cfg.Percentile)
+	if price == nil || price.Cmp(&pd.minPrice) < 0 {
+		return pd.minPrice.Clone(), nil
+	}
+	return price.Clone(), nil
+}
+
+// updateWindow samples any new blocks and drops blocks that fell out of the sliding window.
+func (pd *dynamicPriceDiscovery) updateWindow(ctx context.Context) error {
+	blk, err := pd.backend.GetBlock(ctx, HeightLatest)
+	if err != nil {
+		return fmt.Errorf("submission: failed to get latest block: %w", err)
+	}
+	latestHeight := blk.Height
+
+	startHeight := latestHeight - int64(pd.cfg.WindowSize) + 1
+	if n := len(pd.window); n > 0 && pd.window[n-1].height >= startHeight {
+		startHeight = pd.window[n-1].height + 1
+	}
+	if startHeight < 1 {
+		startHeight = 1
+	}
+
+	for height := startHeight; height <= latestHeight; height++ {
+		var txs *TransactionsWithResults
+		txs, err = pd.backend.GetTransactionsWithResults(ctx, height)
+		if err != nil {
+			// Some heights may not be available (e.g., due to pruning), skip them.
+			pd.logger.Debug("failed to sample block gas prices",
+				"err", err,
+				"height", height,
+			)
+			continue
+		}
+
+		sample := &blockGasPrices{height: height}
+		for i, rawTx := range txs.Transactions {
+			// Only consider transactions that have been successfully executed.
+			if i >= len(txs.Results) || !txs.Results[i].IsSuccess() {
+				continue
+			}
+			if price := rawTxGasPrice(rawTx); price != nil {
+				sample.prices = append(sample.prices, price)
+			}
+		}
+		pd.window = append(pd.window, sample)
+	}
+
+	// Drop blocks outside the window.
+	minHeight := latestHeight - int64(pd.cfg.WindowSize) + 1
+	var drop int
+	for drop < len(pd.window) && pd.window[drop].height < minHeight {
+		drop++
+	}
+	pd.window = pd.window[drop:]
+
+	return nil
+}
+
+// rawTxGasPrice returns the gas price of the given raw transaction or nil in case the transaction
+// cannot be decoded or does not pay any fees.
+//
+// Signatures are not verified as this is only used to sample gas prices.
+func rawTxGasPrice(rawTx []byte) *quantity.Quantity {
+	var blob []byte
+	var sigTx transaction.SignedTransaction
+	if err := cbor.Unmarshal(rawTx, &sigTx); err == nil {
+		blob = sigTx.Blob
+	} else {
+		var multiSigTx transaction.MultiSignedTransaction
+		if err = cbor.Unmarshal(rawTx, &multiSigTx); err != nil {
+			return nil
+		}
+		blob = multiSigTx.Blob
+	}
+
+	var tx transaction.Transaction
+	if err := cbor.Unmarshal(blob, &tx); err != nil {
+		return nil
+	}
+	if tx.Fee == nil || tx.Fee.Gas == 0 {
+		return nil
+	}
+	return tx.Fee.GasPrice()
+}
+
+// percentile returns the given percentile of the given prices or nil if there are no prices.
+func percentile(prices []*quantity.Quantity, p uint8) *quantity.Quantity {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+
+	// Use the nearest-rank method.
+	rank := (int(p)*len(prices) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return prices[rank-1]
+}
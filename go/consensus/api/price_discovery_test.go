@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+)
+
+func TestPercentile(t *testing.T) {
+	require := require.New(t)
+
+	require.Nil(percentile(nil, 50), "percentile of no prices should be nil")
+
+	var prices []*quantity.Quantity
+	for _, v := range []uint64{50, 10, 40, 20, 30} {
+		prices = append(prices, quantity.NewFromUint64(v))
+	}
+
+	for _, tc := range []struct {
+		p        uint8
+		expected uint64
+	}{
+		{0, 10},
+		{20, 10},
+		{50, 30},
+		{90, 50},
+		{100, 50},
+	} {
+		require.EqualValues(quantity.NewFromUint64(tc.expected), percentile(prices, tc.p), "percentile %d", tc.p)
+	}
+}
+
+func TestRawTxGasPrice(t *testing.T) {
+	require := require.New(t)
+
+	tx := transaction.NewTransaction(0, &transaction.Fee{
+		Amount: *quantity.NewFromUint64(1000),
+		Gas:    100,
+	}, transaction.MethodName("test.Method"), nil)
+	sigTx := transaction.SignedTransaction{Signed: signature.Signed{Blob: cbor.Marshal(tx)}}
+	require.EqualValues(quantity.NewFromUint64(10), rawTxGasPrice(cbor.Marshal(sigTx)))
+
+	multiSigTx := transaction.MultiSignedTransaction{MultiSigned: signature.MultiSigned{Blob: cbor.Marshal(tx)}}
+	require.EqualValues(quantity.NewFromUint64(10), rawTxGasPrice(cbor.Marshal(multiSigTx)))
+
+	noFeeTx := transaction.NewTransaction(0, nil, transaction.MethodName("test.Method"), nil)
+	sigTx = transaction.SignedTransaction{Signed: signature.Signed{Blob: cbor.Marshal(noFeeTx)}}
+	require.Nil(rawTxGasPrice(cbor.Marshal(sigTx)), "transactions without fees should be ignored")
+
+	require.Nil(rawTxGasPrice([]byte("garbage")), "malformed transactions should be ignored")
+}
@@ -21,6 +21,10 @@ const (
 	maxSubmissionRetryInterval    = 10 * time.Second
 )
 
+// errTxStuck is the error returned internally when a submitted transaction has not been included
+// in a block within the configured number of blocks.
+var errTxStuck = fmt.Errorf("submission: transaction stuck")
+
 // PriceDiscovery is the consensus fee price discovery interface.
 type PriceDiscovery interface {
 	// GasPrice returns the current consensus gas price.
@@ -60,14 +64,53 @@ type SubmissionManager interface {
 	SignAndSubmitTx(ctx context.Context, signer signature.Signer, tx *transaction.Transaction) error
 }
 
+// FeeBumpConfig is the fee bumping configuration of the submission manager.
+type FeeBumpConfig struct {
+	// StuckBlocks is the number of blocks after which a submitted transaction that has not yet
+	// been included in a block is considered stuck.
+	StuckBlocks uint64
+	// BumpPercent is the percentage by which the fee is increased on each resubmission.
+	BumpPercent uint64
+	// MaxBumps is the maximum number of times the fee is bumped before giving up.
+	MaxBumps uint64
+}
+
+// SubmissionOption is a submission manager option.
+type SubmissionOption func(*submissionManager)
+
+// WithFeeBump configures the submission manager to bump the fee and resubmit transactions that
+// are stuck for more than the configured number of blocks or that have been rejected due to a too
+// low gas price.
+//
+// Resubmitted transactions keep the original nonce so at most one version of the transaction
+// can ever be executed.
+func WithFeeBump(cfg *FeeBumpConfig) SubmissionOption {
+	return func(m *submissionManager) {
+		if cfg == nil || cfg.StuckBlocks == 0 || cfg.BumpPercent == 0 {
+			return
+		}
+		m.feeBump = cfg
+	}
+}
+
 type submissionManager struct {
 	backend        ClientBackend
 	priceDiscovery PriceDiscovery
 	maxFee         quantity.Quantity
+	feeBump        *FeeBumpConfig
 
 	logger *logging.Logger
 }
 
+// submissionState is the state of a single transaction submission across retries.
+type submissionState struct {
+	// nonceFixed is true when a version of the transaction has been accepted into the mempool
+	// and so all resubmissions must use the same nonce.
+	nonceFixed bool
+	// numBumps is the number of times the fee has been bumped.
+	numBumps uint64
+}
+
 // Implements SubmissionManager.
 func (m *submissionManager) PriceDiscovery() PriceDiscovery {
 	return m.priceDiscovery
@@ -118,19 +161,115 @@ func (m *submissionManager) EstimateGasAndSetFee(ctx context.Context, signer sig
 	return nil
 }
 
-func (m *submissionManager) signAndSubmitTx(ctx context.Context, signer signature.Signer, tx *transaction.Transaction) error {
+// bumpFee increases the transaction fee by the configured percentage.
+func (m *submissionManager) bumpFee(tx *transaction.Transaction, state *submissionState) error {
+	if state.numBumps >= m.feeBump.MaxBumps {
+		return fmt.Errorf("submission: transaction not included after %d fee bumps", state.numBumps)
+	}
+
+	var multiplier, divisor quantity.Quantity
+	_ = multiplier.FromUint64(100 + m.feeBump.BumpPercent)
+	_ = divisor.FromUint64(100)
+
+	amount := tx.Fee.Amount.Clone()
+	if err := amount.Mul(&multiplier); err != nil {
+		return fmt.Errorf("submission: failed to bump fee: %w", err)
+	}
+	if err := amount.Quo(&divisor); err != nil {
+		return fmt.Errorf("submission: failed to bump fee: %w", err)
+	}
+	if amount.Cmp(&tx.Fee.Amount) <= 0 {
+		// Make sure the fee is actually increased even for tiny amounts.
+		_ = amount.Add(quantity.NewFromUint64(1))
+	}
+
+	// Verify that the fee doesn't exceed a configured ceiling.
+	if !m.maxFee.IsZero() && amount.Cmp(&m.maxFee) == 1 {
+		return fmt.Errorf("submission: bumped fee exceeds configured maximum: %s (max: %s)",
+			amount,
+			m.maxFee,
+		)
+	}
+
+	m.logger.Info("bumping transaction fee",
+		"nonce", tx.Nonce,
+		"old_fee", tx.Fee.Amount,
+		"new_fee", amount,
+	)
+
+	tx.Fee = &transaction.Fee{
+		Gas:    tx.Fee.Gas,
+		Amount: *amount,
+	}
+	state.numBumps++
+	return nil
+}
+
+// submitTx submits the signed transaction and, in case fee bumping is enabled, watches for the
+// transaction getting stuck.
+func (m *submissionManager) submitTx(ctx context.Context, sigTx *transaction.SignedTransaction) error {
+	if m.feeBump == nil {
+		return m.backend.SubmitTx(ctx, sigTx)
+	}
+
+	blkCh, blkSub, err := m.backend.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("submission: failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	submitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- m.backend.SubmitTx(submitCtx, sigTx)
+	}()
+
+	var numBlocks uint64
+	for {
+		select {
+		case err = <-errCh:
+			return err
+		case <-blkCh:
+			numBlocks++
+			if numBlocks <= m.feeBump.StuckBlocks {
+				continue
+			}
+
+			// Stop waiting for the transaction. It could still have been included in the
+			// meantime, in which case the submission succeeded.
+			cancel()
+			if err = <-errCh; err == nil {
+				return nil
+			}
+			return errTxStuck
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *submissionManager) signAndSubmitTx(
+	ctx context.Context,
+	signer signature.Signer,
+	tx *transaction.Transaction,
+	state *submissionState,
+) error {
 	// Update transaction nonce.
 	var err error
 	signerAddr := staking.NewAddress(signer.Public())
 
-	tx.Nonce, err = m.backend.GetSignerNonce(ctx, &GetSignerNonceRequest{AccountAddress: signerAddr, Height: HeightLatest})
-	if err != nil {
-		if errors.Is(err, ErrNoCommittedBlocks) {
-			// No committed blocks available, retry submission.
-			m.logger.Debug("retrying transaction submission due to no committed blocks")
-			return err
+	if !state.nonceFixed {
+		tx.Nonce, err = m.backend.GetSignerNonce(ctx, &GetSignerNonceRequest{AccountAddress: signerAddr, Height: HeightLatest})
+		if err != nil {
+			if errors.Is(err, ErrNoCommittedBlocks) {
+				// No committed blocks available, retry submission.
+				m.logger.Debug("retrying transaction submission due to no committed blocks")
+				return err
+			}
+			return backoff.Permanent(err)
 		}
-		return backoff.Permanent(err)
 	}
 
 	// Estimate the fee.
@@ -147,8 +286,32 @@ func (m *submissionManager) signAndSubmitTx(ctx context.Context, signer signatur
 		return backoff.Permanent(err)
 	}
 
-	if err = m.backend.SubmitTx(ctx, sigTx); err != nil {
+	if err = m.submitTx(ctx, sigTx); err != nil {
 		switch {
+		case m.feeBump != nil && (errors.Is(err, errTxStuck) || errors.Is(err, transaction.ErrGasPriceTooLow)):
+			// Transaction stuck or rejected due to a too low gas price, bump the fee and retry.
+			if errors.Is(err, errTxStuck) {
+				// A version of the transaction is in the mempool, make sure that only one
+				// version can ever be executed.
+				state.nonceFixed = true
+			}
+			if err = m.bumpFee(tx, state); err != nil {
+				return backoff.Permanent(err)
+			}
+			return errTxStuck
+		case state.nonceFixed && errors.Is(err, transaction.ErrInvalidNonce):
+			// A previous version of the transaction may have been included in the meantime.
+			var nonce uint64
+			nonce, err = m.backend.GetSignerNonce(ctx, &GetSignerNonceRequest{AccountAddress: signerAddr, Height: HeightLatest})
+			if err == nil && nonce > tx.Nonce {
+				m.logger.Debug("previous version of the transaction has been included",
+					"account_address", signerAddr,
+					"nonce", tx.Nonce,
+				)
+				return nil
+			}
+			// Previous version is still pending, retry submission.
+			return transaction.ErrInvalidNonce
 		case errors.Is(err, transaction.ErrUpgradePending):
 			// Pending upgrade, retry submission.
 			m.logger.Debug("retrying transaction submission due to pending upgrade")
@@ -174,13 +337,24 @@ func (m *submissionManager) SignAndSubmitTx(ctx context.Context, signer signatur
 	sched.MaxInterval = maxSubmissionRetryInterval
 	sched.MaxElapsedTime = maxSubmissionRetryElapsedTime
 
+	var state submissionState
 	return backoff.Retry(func() error {
-		return m.signAndSubmitTx(ctx, signer, tx)
+		err := m.signAndSubmitTx(ctx, signer, tx, &state)
+		if errors.Is(err, errTxStuck) {
+			// Give each version of the transaction the full retry period.
+			sched.Reset()
+		}
+		return err
 	}, backoff.WithContext(sched, ctx))
 }
 
 // NewSubmissionManager creates a new transaction submission manager.
-func NewSubmissionManager(backend ClientBackend, priceDiscovery PriceDiscovery, maxFee uint64) SubmissionManager {
+func NewSubmissionManager(
+	backend ClientBackend,
+	priceDiscovery PriceDiscovery,
+	maxFee uint64,
+	opts ...SubmissionOption,
+) SubmissionManager {
 	sm := &submissionManager{
 		backend:        backend,
 		priceDiscovery: priceDiscovery,
@@ -188,6 +362,10 @@ func NewSubmissionManager(backend ClientBackend, priceDiscovery PriceDiscovery,
 	}
 	_ = sm.maxFee.FromUint64(maxFee)
 
+	for _, opt := range opts {
+		opt(sm)
+	}
+
 	return sm
 }
 
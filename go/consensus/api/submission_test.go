@@ -0,0 +1,403 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction/results"
+)
+
+const (
+	testGas      = 100
+	testGasPrice = 10
+
+	testBlockInterval = 10 * time.Millisecond
+)
+
+// submitBehavior is the behavior of a single transaction submission.
+type submitBehavior int
+
+const (
+	// submitOK makes the submission succeed.
+	submitOK submitBehavior = iota
+	// submitStuck makes the submission block until it is canceled.
+	submitStuck
+	// submitStuckIncluded makes the submission block until it is canceled, after which the
+	// transaction is reported as included.
+	submitStuckIncluded
+	// submitGasPriceTooLow makes the submission fail due to a too low gas price.
+	submitGasPriceTooLow
+	// submitInvalidNonce makes the submission fail due to an invalid nonce.
+	submitInvalidNonce
+)
+
+// testSubmissionBackend is a consensus backend that records submitted transactions and submits
+// them according to the configured behaviors.
+type testSubmissionBackend struct {
+	ClientBackend
+
+	sync.Mutex
+
+	behaviors  []submitBehavior
+	submitted  []*transaction.Transaction
+	nonces     []uint64
+	nonceCalls int
+
+	// Used for price discovery.
+	latestHeight int64
+	blocks       map[int64]*TransactionsWithResults
+	mempool      [][]byte
+}
+
+func (b *testSubmissionBackend) SubmitTx(ctx context.Context, sigTx *transaction.SignedTransaction) error {
+	var tx transaction.Transaction
+	if err := cbor.Unmarshal(sigTx.Blob, &tx); err != nil {
+		return err
+	}
+
+	b.Lock()
+	b.submitted = append(b.submitted, &tx)
+	behavior := submitOK
+	if len(b.behaviors) > 0 {
+		behavior, b.behaviors = b.behaviors[0], b.behaviors[1:]
+	}
+	b.Unlock()
+
+	switch behavior {
+	case submitStuck:
+		<-ctx.Done()
+		return ctx.Err()
+	case submitStuckIncluded:
+		<-ctx.Done()
+		return nil
+	case submitGasPriceTooLow:
+		return transaction.ErrGasPriceTooLow
+	case submitInvalidNonce:
+		return transaction.ErrInvalidNonce
+	default:
+		return nil
+	}
+}
+
+func (b *testSubmissionBackend) EstimateGas(ctx context.Context, req *EstimateGasRequest) (transaction.Gas, error) {
+	return testGas, nil
+}
+
+func (b *testSubmissionBackend) GetSignerNonce(ctx context.Context, req *GetSignerNonceRequest) (uint64, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	nonce := b.nonces[0]
+	if len(b.nonces) > 1 {
+		b.nonces = b.nonces[1:]
+	}
+	b.nonceCalls++
+	return nonce, nil
+}
+
+func (b *testSubmissionBackend) WatchBlocks(ctx context.Context) (<-chan *Block, pubsub.ClosableSubscription, error) {
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+	ch := make(chan *Block)
+	go func() {
+		ticker := time.NewTicker(testBlockInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			select {
+			case ch <- &Block{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, sub, nil
+}
+
+func (b *testSubmissionBackend) GetBlock(ctx context.Context, height int64) (*Block, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return &Block{Height: b.latestHeight}, nil
+}
+
+func (b *testSubmissionBackend) GetTransactionsWithResults(ctx context.Context, height int64) (*TransactionsWithResults, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	txs, ok := b.blocks[height]
+	if !ok {
+		return nil, ErrVersionNotFound
+	}
+	return txs, nil
+}
+
+func (b *testSubmissionBackend) GetUnconfirmedTransactions(ctx context.Context) ([][]byte, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.mempool, nil
+}
+
+func (b *testSubmissionBackend) getSubmitted() []*transaction.Transaction {
+	b.Lock()
+	defer b.Unlock()
+
+	return append([]*transaction.Transaction{}, b.submitted...)
+}
+
+func newTestSubmissionManager(t *testing.T, backend *testSubmissionBackend, maxFee uint64, feeBump *FeeBumpConfig) SubmissionManager {
+	pd, err := NewStaticPriceDiscovery(testGasPrice)
+	require.NoError(t, err, "NewStaticPriceDiscovery")
+	return NewSubmissionManager(backend, pd, maxFee, WithFeeBump(feeBump))
+}
+
+func newTestTx() *transaction.Transaction {
+	return transaction.NewTransaction(0, nil, transaction.MethodName("test.Method"), nil)
+}
+
+func requireFees(t *testing.T, txs []*transaction.Transaction, expected ...uint64) {
+	require.Len(t, txs, len(expected), "number of submissions should be correct")
+	for i, tx := range txs {
+		require.NotNil(t, tx.Fee, "fee should be set")
+		require.EqualValues(t, testGas, tx.Fee.Gas, "gas should not change")
+		require.Zero(t, tx.Fee.Amount.Cmp(quantity.NewFromUint64(expected[i])), "fee of submission %d should be %d (got: %s)", i, expected[i], tx.Fee.Amount)
+	}
+}
+
+func TestSubmissionFeeBump(t *testing.T) {
+	signature.SetChainContext("test: oasis-core tests")
+	signer := memorySigner.NewTestSigner("consensus/api: submission test")
+	ctx := context.Background()
+	feeBump := &FeeBumpConfig{
+		StuckBlocks: 2,
+		BumpPercent: 10,
+		MaxBumps:    2,
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		require := require.New(t)
+
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitGasPriceTooLow},
+			nonces:    []uint64{5},
+		}
+		err := newTestSubmissionManager(t, backend, 0, nil).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.True(errors.Is(err, transaction.ErrGasPriceTooLow), "rejection should not be retried without fee bumping")
+		requireFees(t, backend.getSubmitted(), 1000)
+	})
+
+	t.Run("Stuck", func(t *testing.T) {
+		require := require.New(t)
+
+		// Stuck transactions should be resubmitted with a bumped fee and the same nonce.
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitStuck, submitStuck, submitOK},
+			nonces:    []uint64{5, 6},
+		}
+		err := newTestSubmissionManager(t, backend, 0, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.NoError(err, "SignAndSubmitTx")
+
+		submitted := backend.getSubmitted()
+		requireFees(t, submitted, 1000, 1100, 1210)
+		for _, tx := range submitted {
+			require.EqualValues(5, tx.Nonce, "resubmissions should keep the nonce")
+		}
+		require.Equal(1, backend.nonceCalls, "nonce should only be queried once")
+	})
+
+	t.Run("StuckIncluded", func(t *testing.T) {
+		require := require.New(t)
+
+		// Transactions that are included while being considered stuck should not be resubmitted.
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitStuckIncluded},
+			nonces:    []uint64{5},
+		}
+		err := newTestSubmissionManager(t, backend, 0, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.NoError(err, "SignAndSubmitTx")
+		requireFees(t, backend.getSubmitted(), 1000)
+	})
+
+	t.Run("PreviousVersionIncluded", func(t *testing.T) {
+		require := require.New(t)
+
+		// A resubmission failing due to the previous version being included should succeed.
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitStuck, submitInvalidNonce},
+			nonces:    []uint64{5, 6},
+		}
+		err := newTestSubmissionManager(t, backend, 0, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.NoError(err, "SignAndSubmitTx")
+		requireFees(t, backend.getSubmitted(), 1000, 1100)
+		require.Equal(2, backend.nonceCalls, "nonce should be queried to detect inclusion")
+	})
+
+	t.Run("GasPriceTooLow", func(t *testing.T) {
+		require := require.New(t)
+
+		// Rejected transactions were never in the mempool, so the nonce should be refreshed.
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitGasPriceTooLow, submitOK},
+			nonces:    []uint64{5, 6},
+		}
+		err := newTestSubmissionManager(t, backend, 0, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.NoError(err, "SignAndSubmitTx")
+
+		submitted := backend.getSubmitted()
+		requireFees(t, submitted, 1000, 1100)
+		require.EqualValues(5, submitted[0].Nonce, "first submission should use the queried nonce")
+		require.EqualValues(6, submitted[1].Nonce, "resubmission should use the refreshed nonce")
+	})
+
+	t.Run("MaxBumps", func(t *testing.T) {
+		require := require.New(t)
+
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitStuck, submitStuck, submitStuck, submitStuck},
+			nonces:    []uint64{5},
+		}
+		err := newTestSubmissionManager(t, backend, 0, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.Error(err, "SignAndSubmitTx should fail after the maximum number of fee bumps")
+		requireFees(t, backend.getSubmitted(), 1000, 1100, 1210)
+	})
+
+	t.Run("MaxFee", func(t *testing.T) {
+		require := require.New(t)
+
+		backend := &testSubmissionBackend{
+			behaviors: []submitBehavior{submitStuck, submitStuck},
+			nonces:    []uint64{5},
+		}
+		err := newTestSubmissionManager(t, backend, 1100, feeBump).SignAndSubmitTx(ctx, signer, newTestTx())
+		require.Error(err, "SignAndSubmitTx should fail when the bumped fee exceeds the maximum")
+		requireFees(t, backend.getSubmitted(), 1000, 1100)
+	})
+}
+
+func TestBumpFee(t *testing.T) {
+	require := require.New(t)
+
+	m := &submissionManager{
+		feeBump: &FeeBumpConfig{
+			StuckBlocks: 1,
+			BumpPercent: 10,
+			MaxBumps:    2,
+		},
+		logger: logging.GetLogger("consensus/submission/test"),
+	}
+
+	// Fees should be increased even when the percentage rounds down to zero.
+	tx := newTestTx()
+	tx.Fee = &transaction.Fee{Amount: *quantity.NewFromUint64(5), Gas: testGas}
+	var state submissionState
+	require.NoError(m.bumpFee(tx, &state), "bumpFee")
+	require.Zero(tx.Fee.Amount.Cmp(quantity.NewFromUint64(6)), "fee should be increased")
+	require.EqualValues(testGas, tx.Fee.Gas, "gas should not change")
+	require.EqualValues(1, state.numBumps, "number of bumps should be tracked")
+
+	require.NoError(m.bumpFee(tx, &state), "bumpFee")
+	require.Error(m.bumpFee(tx, &state), "bumpFee should fail after the maximum number of bumps")
+}
+
+func newTestGasPriceTx(gasPrice uint64) []byte {
+	tx := transaction.NewTransaction(0, &transaction.Fee{
+		Amount: *quantity.NewFromUint64(gasPrice * testGas),
+		Gas:    testGas,
+	}, transaction.MethodName("test.Method"), nil)
+	sigTx := transaction.SignedTransaction{Signed: signature.Signed{Blob: cbor.Marshal(tx)}}
+	return cbor.Marshal(sigTx)
+}
+
+func TestDynamicPriceDiscovery(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	success := &results.Result{}
+	failure := &results.Result{Error: results.Error{Module: "test", Code: 1}}
+	backend := &testSubmissionBackend{
+		latestHeight: 3,
+		blocks: map[int64]*TransactionsWithResults{
+			1: {
+				Transactions: [][]byte{newTestGasPriceTx(100)},
+				Results:      []*results.Result{success},
+			},
+			// Height 2 is not available.
+			3: {
+				Transactions: [][]byte{newTestGasPriceTx(20), newTestGasPriceTx(1000), newTestGasPriceTx(30)},
+				Results:      []*results.Result{success, failure, success},
+			},
+		},
+	}
+
+	_, err := NewDynamicPriceDiscovery(backend, &DynamicPriceDiscoveryConfig{})
+	require.Error(err, "zero window size should be rejected")
+	_, err = NewDynamicPriceDiscovery(backend, &DynamicPriceDiscoveryConfig{WindowSize: 1, Percentile: 101})
+	require.Error(err, "invalid percentile should be rejected")
+
+	pd, err := NewDynamicPriceDiscovery(backend, &DynamicPriceDiscoveryConfig{
+		WindowSize: 3,
+		Percentile: 50,
+		MinPrice:   10,
+	})
+	require.NoError(err, "NewDynamicPriceDiscovery")
+
+	// Only successful transactions should be sampled.
+	price, err := pd.GasPrice(ctx)
+	require.NoError(err, "GasPrice")
+	require.Zero(price.Cmp(quantity.NewFromUint64(30)), "gas price should be the median (got: %s)", price)
+
+	// Blocks leaving the window should no longer be sampled.
+	backend.Lock()
+	backend.latestHeight = 4
+	backend.blocks[4] = &TransactionsWithResults{
+		Transactions: [][]byte{newTestGasPriceTx(1)},
+		Results:      []*results.Result{success},
+	}
+	backend.Unlock()
+	price, err = pd.GasPrice(ctx)
+	require.NoError(err, "GasPrice")
+	require.Zero(price.Cmp(quantity.NewFromUint64(20)), "gas price should be the median (got: %s)", price)
+
+	// The gas price should never be lower than the minimum price.
+	pd, err = NewDynamicPriceDiscovery(backend, &DynamicPriceDiscoveryConfig{
+		WindowSize: 1,
+		Percentile: 50,
+		MinPrice:   10,
+	})
+	require.NoError(err, "NewDynamicPriceDiscovery")
+	price, err = pd.GasPrice(ctx)
+	require.NoError(err, "GasPrice")
+	require.Zero(price.Cmp(quantity.NewFromUint64(10)), "gas price should be the minimum price (got: %s)", price)
+
+	// Transactions in the mempool should be sampled if configured.
+	backend.Lock()
+	backend.mempool = [][]byte{newTestGasPriceTx(500), newTestGasPriceTx(600)}
+	backend.Unlock()
+	pd, err = NewDynamicPriceDiscovery(backend, &DynamicPriceDiscoveryConfig{
+		WindowSize:     1,
+		Percentile:     50,
+		IncludeMempool: true,
+	})
+	require.NoError(err, "NewDynamicPriceDiscovery")
+	price, err = pd.GasPrice(ctx)
+	require.NoError(err, "GasPrice")
+	require.Zero(price.Cmp(quantity.NewFromUint64(500)), "gas price should include mempool transactions (got: %s)", price)
+}
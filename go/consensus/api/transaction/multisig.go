@@ -0,0 +1,290 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
+)
+
+// MaxMultisigSigners is the maximum number of signers of a multisig account.
+const MaxMultisigSigners = 16
+
+var (
+	// MultisigSignatureContext is the context used for signing multisig transactions.
+	//
+	// It is distinct from SignatureContext so that signatures made by individual multisig account
+	// signers cannot be used to authorize single-signer transactions and vice versa.
+	MultisigSignatureContext = signature.NewContext("oasis-core/consensus: multisig tx", signature.WithChainSeparation())
+
+	// ErrInvalidMultisigAccount is the error returned when a multisig account descriptor is
+	// malformed.
+	ErrInvalidMultisigAccount = errors.New(moduleName, 5, "transaction: invalid multisig account")
+
+	// ErrInsufficientSignatures is the error returned when a multisig transaction does not carry
+	// enough valid signatures to satisfy the account threshold.
+	ErrInsufficientSignatures = errors.New(moduleName, 6, "transaction: insufficient signatures")
+
+	// ErrMultisigNotSupported is the error returned when a multisig account is used to authorize
+	// a method that does not support multisig callers.
+	ErrMultisigNotSupported = errors.New(moduleName, 7, "transaction: method does not support multisig callers")
+
+	_ prettyprint.PrettyPrinter = (*MultiSignedTransaction)(nil)
+)
+
+// MultisigAccount is a threshold multi-signature account descriptor.
+//
+// The account address is derived from the descriptor, so the order of signers and the
+// threshold are significant.
+type MultisigAccount struct {
+	// Signers are the public keys that are allowed to sign on behalf of the account.
+	Signers []signature.PublicKey `json:"signers"`
+	// Threshold is the minimum number of distinct signers required to authorize a transaction.
+	Threshold uint8 `json:"threshold"`
+}
+
+// ValidateBasic performs basic multisig account descriptor validity checks.
+func (a *MultisigAccount) ValidateBasic() error {
+	if len(a.Signers) == 0 {
+		return fmt.Errorf("%w: no signers", ErrInvalidMultisigAccount)
+	}
+	if len(a.Signers) > MaxMultisigSigners {
+		return fmt.Errorf("%w: too many signers (max: %d)", ErrInvalidMultisigAccount, MaxMultisigSigners)
+	}
+	if a.Threshold == 0 {
+		return fmt.Errorf("%w: zero threshold", ErrInvalidMultisigAccount)
+	}
+	if int(a.Threshold) > len(a.Signers) {
+		return fmt.Errorf("%w: threshold exceeds number of signers", ErrInvalidMultisigAccount)
+	}
+
+	seen := make(map[signature.PublicKey]bool)
+	for _, pk := range a.Signers {
+		if !pk.IsValid() {
+			return fmt.Errorf("%w: invalid signer %s", ErrInvalidMultisigAccount, pk)
+		}
+		if seen[pk] {
+			return fmt.Errorf("%w: duplicate signer %s", ErrInvalidMultisigAccount, pk)
+		}
+		seen[pk] = true
+	}
+	return nil
+}
+
+// SignerIndex returns the index of the given public key in the list of signers or -1 in case
+// the public key is not one of the account signers.
+func (a *MultisigAccount) SignerIndex(pk signature.PublicKey) int {
+	for i, signer := range a.Signers {
+		if signer.Equal(pk) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Equal compares vs another multisig account descriptor for equality.
+func (a *MultisigAccount) Equal(other *MultisigAccount) bool {
+	if a.Threshold != other.Threshold || len(a.Signers) != len(other.Signers) {
+		return false
+	}
+	for i := range a.Signers {
+		if !a.Signers[i].Equal(other.Signers[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiSignedTransaction is a transaction signed by (a subset of) the signers of a multisig
+// account.
+type MultiSignedTransaction struct {
+	signature.MultiSigned
+
+	// Account is the descriptor of the multisig account authorizing the transaction.
+	Account MultisigAccount `json:"account"`
+}
+
+// multisigSigningPayload is the message signed by each of the multisig account signers. It binds
+// the signatures to the account descriptor so that they cannot be reused for another account.
+type multisigSigningPayload struct {
+	Account MultisigAccount `json:"account"`
+	Body    []byte          `json:"body"`
+}
+
+// signingPayload returns the message that is signed by each of the multisig account signers.
+func (s *MultiSignedTransaction) signingPayload() []byte {
+	return cbor.Marshal(&multisigSigningPayload{
+		Account: s.Account,
+		Body:    s.Blob,
+	})
+}
+
+// Hash returns the cryptographic hash of the encoded transaction.
+func (s *MultiSignedTransaction) Hash() hash.Hash {
+	return hash.NewFrom(s)
+}
+
+// PrettyPrint writes a pretty-printed representation of the type
+// to the given writer.
+func (s MultiSignedTransaction) PrettyPrint(ctx context.Context, prefix string, w io.Writer) {
+	fmt.Fprintf(w, "%sHash: %s\n", prefix, s.Hash())
+
+	fmt.Fprintf(w, "%sThreshold: %d\n", prefix, s.Account.Threshold)
+	fmt.Fprintf(w, "%sSigners:\n", prefix)
+	payload := s.signingPayload()
+	for _, pk := range s.Account.Signers {
+		fmt.Fprintf(w, "%s  %s", prefix, pk)
+
+		var signed bool
+		for _, sig := range s.Signatures {
+			if !sig.PublicKey.Equal(pk) {
+				continue
+			}
+			signed = true
+			if !sig.Verify(MultisigSignatureContext, payload) {
+				fmt.Fprintf(w, " [INVALID SIGNATURE]")
+			}
+		}
+		if !signed {
+			fmt.Fprintf(w, " [NOT SIGNED]")
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// Display the blob even if signature verification failed as it may
+	// be useful to look into it regardless.
+	var tx Transaction
+	fmt.Fprintf(w, "%sContent:\n", prefix)
+	if err := cbor.Unmarshal(s.Blob, &tx); err != nil {
+		fmt.Fprintf(w, "%s  <error: %s>\n", prefix, err)
+		fmt.Fprintf(w, "%s  <malformed: %s>\n", prefix, base64.StdEncoding.EncodeToString(s.Blob))
+		return
+	}
+
+	tx.PrettyPrint(ctx, prefix+"  ", w)
+}
+
+// PrettyType returns a representation of the type that can be used for pretty printing.
+func (s MultiSignedTransaction) PrettyType() (interface{}, error) {
+	var tx Transaction
+	if err := cbor.Unmarshal(s.Blob, &tx); err != nil {
+		return nil, fmt.Errorf("malformed signed blob: %w", err)
+	}
+	return signature.NewPrettyMultiSigned(s.MultiSigned, tx)
+}
+
+// Open first verifies that the transaction carries enough valid signatures made by distinct
+// account signers and then unmarshals the blob.
+func (s *MultiSignedTransaction) Open(tx *Transaction) error { // nolint: interfacer
+	if err := s.Account.ValidateBasic(); err != nil {
+		return err
+	}
+
+	seen := make(map[signature.PublicKey]bool)
+	for _, sig := range s.Signatures {
+		if s.Account.SignerIndex(sig.PublicKey) < 0 {
+			return fmt.Errorf("%w: signature by non-signer %s", signature.ErrVerifyFailed, sig.PublicKey)
+		}
+		if seen[sig.PublicKey] {
+			return fmt.Errorf("%w: duplicate signature by %s", signature.ErrVerifyFailed, sig.PublicKey)
+		}
+		seen[sig.PublicKey] = true
+	}
+	if len(seen) < int(s.Account.Threshold) {
+		return fmt.Errorf("%w: got %d, need %d", ErrInsufficientSignatures, len(seen), s.Account.Threshold)
+	}
+
+	payload := s.signingPayload()
+	for _, sig := range s.Signatures {
+		if !sig.Verify(MultisigSignatureContext, payload) {
+			return fmt.Errorf("%w: invalid signature by %s", signature.ErrVerifyFailed, sig.PublicKey)
+		}
+	}
+
+	return cbor.Unmarshal(s.Blob, tx)
+}
+
+// Sign adds a signature made by the given signer to the multisig transaction.
+//
+// The signer must be one of the account signers and must not have signed already. This can be
+// done offline by each of the signers independently, after which the partially signed
+// transactions can be combined via Combine.
+func (s *MultiSignedTransaction) Sign(signer signature.Signer) error {
+	pk := signer.Public()
+	if s.Account.SignerIndex(pk) < 0 {
+		return fmt.Errorf("transaction: %s is not a signer of the multisig account", pk)
+	}
+	if s.IsSignedBy(pk) {
+		return fmt.Errorf("transaction: already signed by %s", pk)
+	}
+
+	sig, err := signature.Sign(signer, MultisigSignatureContext, s.signingPayload())
+	if err != nil {
+		return err
+	}
+	s.Signatures = append(s.Signatures, *sig)
+	s.sortSignatures()
+
+	return nil
+}
+
+// Combine merges signatures from another partially signed copy of the same multisig
+// transaction.
+//
+// Signatures are not verified, that happens when the transaction is opened.
+func (s *MultiSignedTransaction) Combine(other *MultiSignedTransaction) error {
+	if !s.Account.Equal(&other.Account) {
+		return fmt.Errorf("transaction: multisig account mismatch")
+	}
+	if !bytes.Equal(s.Blob, other.Blob) {
+		return fmt.Errorf("transaction: multisig transaction body mismatch")
+	}
+
+	for _, sig := range other.Signatures {
+		if s.IsSignedBy(sig.PublicKey) {
+			continue
+		}
+		if s.Account.SignerIndex(sig.PublicKey) < 0 {
+			return fmt.Errorf("transaction: %s is not a signer of the multisig account", sig.PublicKey)
+		}
+		s.Signatures = append(s.Signatures, sig)
+	}
+	s.sortSignatures()
+
+	return nil
+}
+
+// sortSignatures orders signatures by the signer order in the account descriptor so that
+// combining the same set of signatures always results in the same encoding.
+func (s *MultiSignedTransaction) sortSignatures() {
+	sorted := make([]signature.Signature, 0, len(s.Signatures))
+	for _, pk := range s.Account.Signers {
+		for _, sig := range s.Signatures {
+			if sig.PublicKey.Equal(pk) {
+				sorted = append(sorted, sig)
+			}
+		}
+	}
+	s.Signatures = sorted
+}
+
+// NewMultiSignedTransaction creates a new multisig transaction without any signatures.
+func NewMultiSignedTransaction(account *MultisigAccount, tx *Transaction) (*MultiSignedTransaction, error) {
+	if err := account.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	return &MultiSignedTransaction{
+		MultiSigned: signature.MultiSigned{
+			Blob: cbor.Marshal(tx),
+		},
+		Account: *account,
+	}, nil
+}
@@ -0,0 +1,202 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+)
+
+func TestMultisigAccount(t *testing.T) {
+	require := require.New(t)
+
+	signer1, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+	signer2, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	for _, tc := range []struct {
+		account MultisigAccount
+		valid   bool
+		msg     string
+	}{
+		{MultisigAccount{Signers: []signature.PublicKey{signer1.Public(), signer2.Public()}, Threshold: 2}, true, "valid account"},
+		{MultisigAccount{Signers: []signature.PublicKey{signer1.Public(), signer2.Public()}, Threshold: 1}, true, "valid account with lower threshold"},
+		{MultisigAccount{Threshold: 1}, false, "no signers"},
+		{MultisigAccount{Signers: []signature.PublicKey{signer1.Public()}, Threshold: 0}, false, "zero threshold"},
+		{MultisigAccount{Signers: []signature.PublicKey{signer1.Public()}, Threshold: 2}, false, "threshold exceeds signers"},
+		{MultisigAccount{Signers: []signature.PublicKey{signer1.Public(), signer1.Public()}, Threshold: 1}, false, "duplicate signers"},
+		{MultisigAccount{Signers: make([]signature.PublicKey, MaxMultisigSigners+1), Threshold: 1}, false, "too many signers"},
+	} {
+		err = tc.account.ValidateBasic()
+		switch tc.valid {
+		case true:
+			require.NoError(err, tc.msg)
+		case false:
+			require.Error(err, tc.msg)
+			require.True(errors.Is(err, ErrInvalidMultisigAccount), tc.msg)
+		}
+	}
+}
+
+func TestMultiSignedTransaction(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	var signers []signature.Signer
+	var account MultisigAccount
+	for i := 0; i < 3; i++ {
+		signer, err := memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+		signers = append(signers, signer)
+		account.Signers = append(account.Signers, signer.Public())
+	}
+	account.Threshold = 2
+
+	nonSigner, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	tx := NewTransaction(42, nil, MethodName("test.Method"), nil)
+
+	multiSigTx, err := NewMultiSignedTransaction(&account, tx)
+	require.NoError(err, "NewMultiSignedTransaction")
+
+	var opened Transaction
+	err = multiSigTx.Open(&opened)
+	require.Error(err, "Open should fail without signatures")
+	require.True(errors.Is(err, ErrInsufficientSignatures))
+
+	// Sign offline by two signers independently.
+	partial1, err := NewMultiSignedTransaction(&account, tx)
+	require.NoError(err, "NewMultiSignedTransaction")
+	err = partial1.Sign(signers[2])
+	require.NoError(err, "Sign")
+	err = partial1.Sign(signers[2])
+	require.Error(err, "Sign should fail for duplicate signer")
+	err = partial1.Sign(nonSigner)
+	require.Error(err, "Sign should fail for non-signer")
+
+	err = partial1.Open(&opened)
+	require.Error(err, "Open should fail below threshold")
+	require.True(errors.Is(err, ErrInsufficientSignatures))
+
+	partial2, err := NewMultiSignedTransaction(&account, tx)
+	require.NoError(err, "NewMultiSignedTransaction")
+	err = partial2.Sign(signers[0])
+	require.NoError(err, "Sign")
+
+	// Combine partially signed transactions.
+	err = multiSigTx.Combine(partial1)
+	require.NoError(err, "Combine")
+	err = multiSigTx.Combine(partial2)
+	require.NoError(err, "Combine")
+	require.Len(multiSigTx.Signatures, 2)
+	require.True(multiSigTx.Signatures[0].PublicKey.Equal(signers[0].Public()), "signatures should be ordered")
+
+	err = multiSigTx.Open(&opened)
+	require.NoError(err, "Open")
+	require.EqualValues(tx.Nonce, opened.Nonce)
+	require.EqualValues(tx.Method, opened.Method)
+
+	// Make sure the envelope survives a serialization round trip.
+	var decoded MultiSignedTransaction
+	err = cbor.Unmarshal(cbor.Marshal(multiSigTx), &decoded)
+	require.NoError(err, "cbor.Unmarshal")
+	err = decoded.Open(&opened)
+	require.NoError(err, "Open")
+
+	// A single-signer envelope must not decode as a multisig envelope and vice versa.
+	sigTx, err := Sign(signers[0], tx)
+	require.NoError(err, "Sign")
+	err = cbor.Unmarshal(cbor.Marshal(sigTx), &decoded)
+	require.Error(err, "single-signer envelope should not decode as multisig")
+	var decodedSigTx SignedTransaction
+	err = cbor.Unmarshal(cbor.Marshal(multiSigTx), &decodedSigTx)
+	require.Error(err, "multisig envelope should not decode as single-signer")
+
+	// Combining different transactions or accounts should fail.
+	otherTx, err := NewMultiSignedTransaction(&account, NewTransaction(43, nil, MethodName("test.Method"), nil))
+	require.NoError(err, "NewMultiSignedTransaction")
+	err = multiSigTx.Combine(otherTx)
+	require.Error(err, "Combine should fail for different transactions")
+
+	otherAccount := account
+	otherAccount.Threshold = 1
+	otherAccountTx, err := NewMultiSignedTransaction(&otherAccount, tx)
+	require.NoError(err, "NewMultiSignedTransaction")
+	err = multiSigTx.Combine(otherAccountTx)
+	require.Error(err, "Combine should fail for different accounts")
+
+	// Signatures by non-signers should be rejected.
+	forged := *multiSigTx
+	forgedSig, err := signature.Sign(nonSigner, MultisigSignatureContext, forged.signingPayload())
+	require.NoError(err, "signature.Sign")
+	forged.Signatures = append(append([]signature.Signature{}, forged.Signatures...), *forgedSig)
+	err = forged.Open(&opened)
+	require.Error(err, "Open should fail with non-signer signature")
+
+	// Invalid signatures should be rejected.
+	tampered := *multiSigTx
+	tampered.Signatures = append([]signature.Signature{}, multiSigTx.Signatures...)
+	tampered.Signatures[1].Signature[0] ^= 0xff
+	err = tampered.Open(&opened)
+	require.Error(err, "Open should fail with invalid signature")
+
+	// Signatures must be bound to the account descriptor.
+	rebound := *multiSigTx
+	rebound.Account = MultisigAccount{
+		Signers:   []signature.PublicKey{signers[0].Public(), signers[2].Public()},
+		Threshold: 2,
+	}
+	err = rebound.Open(&opened)
+	require.Error(err, "Open should fail for signatures made for a different account")
+	require.True(errors.Is(err, signature.ErrVerifyFailed))
+}
+
+func TestMultisigPartialSignatureReplay(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	var signers []signature.Signer
+	var account MultisigAccount
+	for i := 0; i < 2; i++ {
+		signer, err := memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+		signers = append(signers, signer)
+		account.Signers = append(account.Signers, signer.Public())
+	}
+	account.Threshold = 2
+
+	tx := NewTransaction(0, nil, MethodName("test.Method"), nil)
+	multiSigTx, err := NewMultiSignedTransaction(&account, tx)
+	require.NoError(err, "NewMultiSignedTransaction")
+	err = multiSigTx.Sign(signers[0])
+	require.NoError(err, "Sign")
+
+	// A partial multisig signature must not be usable as a single-signer transaction spending
+	// from the signer's own account.
+	sigTx := SignedTransaction{
+		Signed: signature.Signed{
+			Blob:      multiSigTx.Blob,
+			Signature: multiSigTx.Signatures[0],
+		},
+	}
+	var opened Transaction
+	err = sigTx.Open(&opened)
+	require.Error(err, "partial multisig signature must not open as a single-signer transaction")
+
+	// Conversely, a single-signer signature must not be usable in a multisig envelope.
+	single, err := Sign(signers[1], tx)
+	require.NoError(err, "Sign")
+	multiSigTx.Signatures = append(multiSigTx.Signatures, single.Signature)
+	err = multiSigTx.Open(&opened)
+	require.Error(err, "single-signer signature must not open as a multisig signature")
+	require.True(errors.Is(err, signature.ErrVerifyFailed))
+}
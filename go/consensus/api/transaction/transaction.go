@@ -237,6 +237,12 @@ const (
 // MethodMetadata is the method metadata.
 type MethodMetadata struct {
 	Priority MethodPriority
+
+	// SupportsMultisig specifies whether the method can be authorized by a multisig account.
+	//
+	// Only methods whose handlers authorize the caller based on the caller address (and not
+	// the transaction signer's public key) may support multisig callers.
+	SupportsMultisig bool
 }
 
 // MethodMetadataProvider is the method metadata provider interface that can be implemented by
@@ -281,6 +287,11 @@ func (m MethodName) IsCritical() bool {
 	return m.Metadata().Priority == MethodPriorityCritical
 }
 
+// SupportsMultisig returns true if the method can be authorized by a multisig account.
+func (m MethodName) SupportsMultisig() bool {
+	return m.Metadata().SupportsMultisig
+}
+
 // NewMethodName creates a new method name.
 //
 // Module and method pair must be unique. If they are not, this method
@@ -0,0 +1,87 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
+)
+
+// LatestUnsignedTransactionVersion is the latest unsigned transaction envelope version.
+const LatestUnsignedTransactionVersion = 1
+
+var _ prettyprint.PrettyPrinter = (*UnsignedTransaction)(nil)
+
+// UnsignedTransaction is a versioned envelope for an unsigned transaction that is meant to be
+// signed offline (e.g., on an air-gapped machine).
+//
+// Besides the transaction itself, the envelope records the chain domain separation context and
+// (optionally) the intended signer, so that the signing side can reject mismatches.
+type UnsignedTransaction struct {
+	cbor.Versioned
+
+	// Transaction is the unsigned transaction.
+	Transaction Transaction `json:"transaction"`
+
+	// ChainContext is the chain domain separation context of the network the transaction is
+	// intended for.
+	ChainContext string `json:"chain_context"`
+
+	// Signer is the public key of the intended signer, if known.
+	Signer *signature.PublicKey `json:"signer,omitempty"`
+}
+
+// ValidateBasic performs basic unsigned transaction envelope validity checks.
+func (ut *UnsignedTransaction) ValidateBasic() error {
+	if ut.V != LatestUnsignedTransactionVersion {
+		return fmt.Errorf("transaction: unsupported unsigned transaction version: %d", ut.V)
+	}
+	if ut.ChainContext == "" {
+		return fmt.Errorf("transaction: missing chain context")
+	}
+	if ut.Signer != nil && !ut.Signer.IsValid() {
+		return fmt.Errorf("transaction: invalid intended signer %s", ut.Signer)
+	}
+	return nil
+}
+
+// Verify checks that the unsigned transaction is intended to be signed by the given signer under
+// the given chain domain separation context.
+func (ut *UnsignedTransaction) Verify(chainContext string, signer signature.PublicKey) error {
+	if ut.ChainContext != chainContext {
+		return fmt.Errorf("transaction: chain context mismatch (expected: %s got: %s)", chainContext, ut.ChainContext)
+	}
+	if ut.Signer != nil && !ut.Signer.Equal(signer) {
+		return fmt.Errorf("transaction: signer mismatch (expected: %s got: %s)", signer, ut.Signer)
+	}
+	return nil
+}
+
+// PrettyPrint writes a pretty-printed representation of the unsigned transaction to the given
+// writer.
+func (ut UnsignedTransaction) PrettyPrint(ctx context.Context, prefix string, w io.Writer) {
+	fmt.Fprintf(w, "%sChain context: %s\n", prefix, ut.ChainContext)
+	if ut.Signer != nil {
+		fmt.Fprintf(w, "%sSigner:        %s\n", prefix, ut.Signer)
+	}
+	fmt.Fprintf(w, "%sTransaction:\n", prefix)
+	ut.Transaction.PrettyPrint(ctx, prefix+"  ", w)
+}
+
+// PrettyType returns a representation of the type that can be used for pretty printing.
+func (ut UnsignedTransaction) PrettyType() (interface{}, error) {
+	return ut, nil
+}
+
+// NewUnsignedTransaction creates a new unsigned transaction envelope.
+func NewUnsignedTransaction(tx *Transaction, chainContext string, signer *signature.PublicKey) *UnsignedTransaction {
+	return &UnsignedTransaction{
+		Versioned:    cbor.NewVersioned(LatestUnsignedTransactionVersion),
+		Transaction:  *tx,
+		ChainContext: chainContext,
+		Signer:       signer,
+	}
+}
@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+)
+
+func TestUnsignedTransaction(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+	otherSigner, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	tx := NewTransaction(42, nil, MethodName("test.Method"), nil)
+	signerPk := signer.Public()
+	ut := NewUnsignedTransaction(tx, "chain-context", &signerPk)
+	require.NoError(ut.ValidateBasic(), "ValidateBasic")
+
+	// Serialization round trip.
+	var dec UnsignedTransaction
+	err = cbor.Unmarshal(cbor.Marshal(ut), &dec)
+	require.NoError(err, "Unmarshal")
+	require.EqualValues(*ut, dec, "serialization should round-trip")
+	v, err := cbor.GetVersion(cbor.Marshal(ut))
+	require.NoError(err, "GetVersion")
+	require.EqualValues(LatestUnsignedTransactionVersion, v)
+
+	// Raw unsigned transactions are not versioned.
+	_, err = cbor.GetVersion(cbor.Marshal(tx))
+	require.Error(err, "GetVersion should fail for raw transactions")
+
+	require.NoError(ut.Verify("chain-context", signer.Public()), "Verify")
+	err = ut.Verify("other-chain-context", signer.Public())
+	require.EqualError(err, "transaction: chain context mismatch (expected: other-chain-context got: chain-context)",
+		"Verify should fail on chain context mismatch")
+	require.Error(ut.Verify("chain-context", otherSigner.Public()), "Verify should fail on signer mismatch")
+
+	// Any signer is fine when the intended signer is not recorded.
+	ut = NewUnsignedTransaction(tx, "chain-context", nil)
+	require.NoError(ut.ValidateBasic(), "ValidateBasic")
+	require.NoError(ut.Verify("chain-context", otherSigner.Public()), "Verify")
+
+	ut = NewUnsignedTransaction(tx, "", nil)
+	require.Error(ut.ValidateBasic(), "ValidateBasic should fail without chain context")
+	ut = NewUnsignedTransaction(tx, "chain-context", nil)
+	ut.V = LatestUnsignedTransactionVersion + 1
+	require.Error(ut.ValidateBasic(), "ValidateBasic should fail with an unsupported version")
+}
@@ -58,8 +58,29 @@ var (
 			Help: "Total size of the ABCI database (MiB).",
 		},
 	)
+	abciPrunedVersions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "oasis_abci_pruned_versions",
+			Help: "Number of pruned ABCI state versions.",
+		},
+	)
+	abciPrunePendingVersions = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "oasis_abci_prune_pending_versions",
+			Help: "Number of ABCI state versions pending to be pruned.",
+		},
+	)
+	abciLastRetainedVersion = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "oasis_abci_last_retained_version",
+			Help: "Earliest retained ABCI state version.",
+		},
+	)
 	abciCollectors = []prometheus.Collector{
 		abciSize,
+		abciPrunedVersions,
+		abciPrunePendingVersions,
+		abciLastRetainedVersion,
 	}
 
 	metricsOnce sync.Once
@@ -497,10 +518,12 @@ func (mux *abciMux) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginB
 	return response
 }
 
-func (mux *abciMux) decodeTx(ctx *api.Context, rawTx []byte) (*transaction.Transaction, *transaction.SignedTransaction, error) {
+// decodeTx decodes the transaction envelope, verifies the transaction signatures and sets the
+// authenticated transaction signer on the context.
+func (mux *abciMux) decodeTx(ctx *api.Context, rawTx []byte) (*transaction.Transaction, error) {
 	if mux.state.haltMode {
 		ctx.Logger().Debug("executeTx: in halt, rejecting all transactions")
-		return nil, nil, fmt.Errorf("halt mode, rejecting all transactions")
+		return nil, fmt.Errorf("halt mode, rejecting all transactions")
 	}
 
 	params := mux.state.ConsensusParameters()
@@ -510,32 +533,50 @@ func (mux *abciMux) decodeTx(ctx *api.Context, rawTx []byte) (*transaction.Trans
 		ctx.Logger().Error("received oversized transaction",
 			"tx_size", len(rawTx),
 		)
-		return nil, nil, consensus.ErrOversizedTx
+		return nil, consensus.ErrOversizedTx
 	}
 
-	// Unmarshal envelope and verify transaction.
-	var sigTx transaction.SignedTransaction
-	if err := cbor.Unmarshal(rawTx, &sigTx); err != nil {
-		ctx.Logger().Error("failed to unmarshal signed transaction",
-			"tx", base64.StdEncoding.EncodeToString(rawTx),
-		)
-		return nil, nil, err
-	}
+	// Unmarshal envelope and verify transaction. Since decoding is strict, an envelope of a
+	// transaction signed by a multisig account will not decode as a single-signer envelope.
 	var tx transaction.Transaction
-	if err := sigTx.Open(&tx); err != nil {
-		ctx.Logger().Error("failed to verify transaction signature",
-			"tx", base64.StdEncoding.EncodeToString(rawTx),
-		)
-		return nil, nil, err
+	var sigTx transaction.SignedTransaction
+	if err := cbor.Unmarshal(rawTx, &sigTx); err == nil {
+		if err = sigTx.Open(&tx); err != nil {
+			ctx.Logger().Error("failed to verify transaction signature",
+				"tx", base64.StdEncoding.EncodeToString(rawTx),
+			)
+			return nil, err
+		}
+
+		// Set authenticated transaction signer.
+		ctx.SetTxSigner(sigTx.Signature.PublicKey)
+	} else {
+		var multiSigTx transaction.MultiSignedTransaction
+		if err = cbor.Unmarshal(rawTx, &multiSigTx); err != nil {
+			ctx.Logger().Error("failed to unmarshal signed transaction",
+				"tx", base64.StdEncoding.EncodeToString(rawTx),
+			)
+			return nil, err
+		}
+		if err = multiSigTx.Open(&tx); err != nil {
+			ctx.Logger().Error("failed to verify multisig transaction signatures",
+				"tx", base64.StdEncoding.EncodeToString(rawTx),
+				"err", err,
+			)
+			return nil, err
+		}
+
+		// Set authenticated multisig account.
+		ctx.SetTxMultisigSigner(&multiSigTx.Account)
 	}
 	if err := tx.SanityCheck(); err != nil {
 		ctx.Logger().Error("bad transaction",
 			"tx", base64.StdEncoding.EncodeToString(rawTx),
 		)
-		return nil, nil, err
+		return nil, err
 	}
 
-	return &tx, &sigTx, nil
+	return &tx, nil
 }
 
 func (mux *abciMux) processTx(ctx *api.Context, tx *transaction.Transaction, txSize int) error {
@@ -549,6 +590,17 @@ func (mux *abciMux) processTx(ctx *api.Context, tx *transaction.Transaction, txS
 		return fmt.Errorf("mux: unknown method: %s", tx.Method)
 	}
 
+	// Reject multisig callers for methods which authorize the transaction signer's public key as
+	// there is no such key for multisig accounts.
+	if ctx.TxMultisigAccount() != nil && !tx.Method.SupportsMultisig() {
+		ctx.Logger().Debug("method does not support multisig callers",
+			"tx", tx,
+			"caller", ctx.CallerAddress(),
+			"method", tx.Method,
+		)
+		return transaction.ErrMultisigNotSupported
+	}
+
 	// Pass the transaction through the fee handler if configured.
 	//
 	// Ignore fees for critical protocol methods to ensure they are processed in a block. Note that
@@ -557,7 +609,7 @@ func (mux *abciMux) processTx(ctx *api.Context, tx *transaction.Transaction, txS
 		if err := txAuthHandler.AuthenticateTx(ctx, tx); err != nil {
 			ctx.Logger().Debug("failed to authenticate transaction (pre-execute)",
 				"tx", tx,
-				"tx_signer", ctx.TxSigner(),
+				"caller", ctx.CallerAddress(),
 				"method", tx.Method,
 				"err", err,
 			)
@@ -586,7 +638,7 @@ func (mux *abciMux) processTx(ctx *api.Context, tx *transaction.Transaction, txS
 		if err := txAuthHandler.PostExecuteTx(ctx, tx); err != nil {
 			ctx.Logger().Debug("failed to authenticate transaction (post-execute)",
 				"tx", tx,
-				"tx_signer", ctx.TxSigner(),
+				"caller", ctx.CallerAddress(),
 				"method", tx.Method,
 				"err", err,
 			)
@@ -598,14 +650,11 @@ func (mux *abciMux) processTx(ctx *api.Context, tx *transaction.Transaction, txS
 }
 
 func (mux *abciMux) executeTx(ctx *api.Context, rawTx []byte) error {
-	tx, sigTx, err := mux.decodeTx(ctx, rawTx)
+	tx, err := mux.decodeTx(ctx, rawTx)
 	if err != nil {
 		return err
 	}
 
-	// Set authenticated transaction signer.
-	ctx.SetTxSigner(sigTx.Signature.PublicKey)
-
 	// If we are in CheckTx mode and there is a pending upgrade in this block, make sure to reject
 	// any transactions before processing as they may potentially query incompatible state.
 	if upgrader := mux.state.Upgrader(); upgrader != nil && ctx.IsCheckOnly() {
@@ -0,0 +1,158 @@
+package abci
+
+import (
+	"context"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/types"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	consensusGenesis "github.com/oasisprotocol/oasis-core/go/consensus/genesis"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	genesis "github.com/oasisprotocol/oasis-core/go/genesis/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	storageDB "github.com/oasisprotocol/oasis-core/go/storage/database"
+	"github.com/oasisprotocol/oasis-core/go/upgrade"
+)
+
+// testApp is a test application that handles a subset of the staking and registry methods and
+// records the caller of each executed transaction.
+type testApp struct {
+	callers []staking.Address
+}
+
+func (app *testApp) Name() string {
+	return "test"
+}
+
+func (app *testApp) ID() uint8 {
+	return 0xff
+}
+
+func (app *testApp) Methods() []transaction.MethodName {
+	return []transaction.MethodName{
+		staking.MethodTransfer,
+		registry.MethodRegisterNode,
+	}
+}
+
+func (app *testApp) Blessed() bool {
+	return false
+}
+
+func (app *testApp) Dependencies() []string {
+	return nil
+}
+
+func (app *testApp) QueryFactory() interface{} {
+	return nil
+}
+
+func (app *testApp) OnRegister(api.ApplicationState, api.MessageDispatcher) {
+}
+
+func (app *testApp) OnCleanup() {
+}
+
+func (app *testApp) ExecuteMessage(*api.Context, interface{}, interface{}) error {
+	return nil
+}
+
+func (app *testApp) ExecuteTx(ctx *api.Context, tx *transaction.Transaction) error {
+	app.callers = append(app.callers, ctx.CallerAddress())
+	return nil
+}
+
+func (app *testApp) InitChain(*api.Context, types.RequestInitChain, *genesis.Document) error {
+	return nil
+}
+
+func (app *testApp) BeginBlock(*api.Context, types.RequestBeginBlock) error {
+	return nil
+}
+
+func (app *testApp) EndBlock(*api.Context, types.RequestEndBlock) (types.ResponseEndBlock, error) {
+	return types.ResponseEndBlock{}, nil
+}
+
+func TestDeliverTxMultisig(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	dir, err := ioutil.TempDir("", "abci-mux.test")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	mux, err := newABCIMux(context.Background(), upgrade.NewDummyUpgradeManager(), &ApplicationConfig{
+		DataDir:             dir,
+		StorageBackend:      storageDB.BackendNameBadgerDB,
+		DisableCheckpointer: true,
+		MemoryOnlyStorage:   true,
+		InitialHeight:       1,
+		Pruning: PruneConfig{
+			PruneInterval: time.Second,
+		},
+	})
+	require.NoError(err, "newABCIMux")
+	defer mux.doCleanup()
+
+	app := &testApp{}
+	err = mux.doRegister(app)
+	require.NoError(err, "doRegister")
+	err = mux.finishInitialization()
+	require.NoError(err, "finishInitialization")
+	mux.state.blockParams = &consensusGenesis.Parameters{}
+
+	var signers []signature.Signer
+	var account transaction.MultisigAccount
+	for i := 0; i < 2; i++ {
+		var signer signature.Signer
+		signer, err = memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+		signers = append(signers, signer)
+		account.Signers = append(account.Signers, signer.Public())
+	}
+	account.Threshold = 2
+
+	deliverMultisig := func(tx *transaction.Transaction) types.ResponseDeliverTx {
+		multiSigTx, merr := transaction.NewMultiSignedTransaction(&account, tx)
+		require.NoError(merr, "NewMultiSignedTransaction")
+		for _, signer := range signers {
+			merr = multiSigTx.Sign(signer)
+			require.NoError(merr, "Sign")
+		}
+		return mux.DeliverTx(types.RequestDeliverTx{Tx: cbor.Marshal(multiSigTx)})
+	}
+
+	// A multisig transfer should be dispatched with the multisig account as the caller.
+	rsp := deliverMultisig(staking.NewTransferTx(0, nil, &staking.Transfer{}))
+	require.EqualValues(types.CodeTypeOK, rsp.Code, "multisig transfer should succeed: %s", rsp.Log)
+	require.Len(app.callers, 1, "transfer should be executed")
+	require.Equal(staking.NewMultisigAddress(&account), app.callers[0], "caller should be the multisig account")
+
+	// A multisig registry call should be rejected before reaching the application.
+	rsp = deliverMultisig(transaction.NewTransaction(0, nil, registry.MethodRegisterNode, nil))
+	module, code := errors.Code(transaction.ErrMultisigNotSupported)
+	require.Equal(module, rsp.Codespace, "multisig registry call should be rejected")
+	require.Equal(code, rsp.Code, "multisig registry call should be rejected")
+	require.Len(app.callers, 1, "registry call should not be executed")
+
+	// The same registry call should be dispatched when signed by a single signer.
+	sigTx, err := transaction.Sign(signers[0], transaction.NewTransaction(0, nil, registry.MethodRegisterNode, nil))
+	require.NoError(err, "Sign")
+	rsp = mux.DeliverTx(types.RequestDeliverTx{Tx: cbor.Marshal(sigTx)})
+	require.EqualValues(types.CodeTypeOK, rsp.Code, "single-signer registry call should succeed: %s", rsp.Log)
+	require.Len(app.callers, 2, "registry call should be executed")
+	require.Equal(staking.NewAddress(signers[0].Public()), app.callers[1], "caller should be the signer")
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
@@ -16,12 +17,17 @@ const (
 	// PruneDefault is the default PruneStrategy.
 	PruneDefault = pruneNone
 
-	pruneNone  = "none"
-	pruneKeepN = "keep_n"
+	pruneNone       = "none"
+	pruneKeepN      = "keep_n"
+	pruneKeepEpochs = "keep_epochs"
 
 	// LogEventABCIPruneDelete is a log event value that signals an ABCI pruning
 	// delete event.
 	LogEventABCIPruneDelete = "tendermint/abci/prune"
+
+	// The roothash checkCommittees call requires at least 1 previous block
+	// for timekeeping purposes.
+	minKept = 1
 )
 
 // PruneStrategy is the strategy to use when pruning the ABCI mux state.
@@ -33,6 +39,9 @@ const (
 
 	// PruneKeepN retains the last N latest versions.
 	PruneKeepN
+
+	// PruneKeepEpochs retains all versions of the last N epochs.
+	PruneKeepEpochs
 )
 
 func (s PruneStrategy) String() string {
@@ -41,6 +50,8 @@ func (s PruneStrategy) String() string {
 		return pruneNone
 	case PruneKeepN:
 		return pruneKeepN
+	case PruneKeepEpochs:
+		return pruneKeepEpochs
 	default:
 		return "[unknown]"
 	}
@@ -52,6 +63,8 @@ func (s *PruneStrategy) FromString(str string) error {
 		*s = PruneNone
 	case pruneKeepN:
 		*s = PruneKeepN
+	case pruneKeepEpochs:
+		*s = PruneKeepEpochs
 	default:
 		return fmt.Errorf("abci/pruner: unknown pruning strategy: '%v'", str)
 	}
@@ -67,8 +80,15 @@ type PruneConfig struct {
 	// NumKept is the number of versions retained when applicable.
 	NumKept uint64
 
+	// NumKeptEpochs is the number of epochs retained when applicable.
+	NumKeptEpochs uint64
+
 	// PruneInterval configures the pruning interval.
 	PruneInterval time.Duration
+
+	// BatchSize is the maximum number of versions pruned in each pruning
+	// interval. Zero means no limit.
+	BatchSize uint64
 }
 
 // StatePruner is a concrete ABCI mux state pruner implementation.
@@ -93,6 +113,22 @@ type statePrunerInitializer interface {
 	Initialize() error
 }
 
+// epochTimeSource is the subset of the epoch time backend needed by the
+// epoch-aware pruning strategies.
+type epochTimeSource interface {
+	GetBaseEpoch(ctx context.Context) (beacon.EpochTime, error)
+	GetEpoch(ctx context.Context, height int64) (beacon.EpochTime, error)
+	GetEpochBlock(ctx context.Context, epoch beacon.EpochTime) (int64, error)
+}
+
+type statePrunerTimeSourceSetter interface {
+	setTimeSource(timeSource epochTimeSource)
+}
+
+type statePrunerCheckpointPauser interface {
+	pauseForCheckpoint(pause bool)
+}
+
 type nonePruner struct{}
 
 func (p *nonePruner) Prune(ctx context.Context, latestVersion uint64) error {
@@ -107,17 +143,28 @@ func (p *nonePruner) GetLastRetainedVersion() uint64 {
 	return 0
 }
 
+func (p *nonePruner) Pause(pause bool) {
+}
+
 type genericPruner struct {
 	sync.Mutex
 
 	logger *logging.Logger
 	ndb    nodedb.NodeDB
 
+	// pruneLock is held while a pruning round is in progress.
+	pruneLock sync.Mutex
+
 	earliestVersion     uint64
 	keepN               uint64
+	keepEpochs          uint64
+	batchSize           uint64
 	lastRetainedVersion uint64
+	paused              bool
+	checkpointing       bool
 
-	handlers []api.StatePruneHandler
+	timeSource epochTimeSource
+	handlers   []api.StatePruneHandler
 }
 
 func (p *genericPruner) Initialize() error {
@@ -129,10 +176,54 @@ func (p *genericPruner) Initialize() error {
 
 	// Initially, the earliest version is the last retained version.
 	p.lastRetainedVersion = p.earliestVersion
+	abciLastRetainedVersion.Set(float64(p.lastRetainedVersion))
 
 	return nil
 }
 
+func (p *genericPruner) setTimeSource(timeSource epochTimeSource) {
+	p.Lock()
+	defer p.Unlock()
+	p.timeSource = timeSource
+}
+
+func (p *genericPruner) Pause(pause bool) {
+	p.Lock()
+	if p.paused != pause {
+		p.logger.Info("state pruning paused status changed",
+			"paused", pause,
+		)
+	}
+	p.paused = pause
+	p.Unlock()
+
+	if pause {
+		p.waitPruneRound()
+	}
+}
+
+func (p *genericPruner) pauseForCheckpoint(pause bool) {
+	p.Lock()
+	p.checkpointing = pause
+	p.Unlock()
+
+	if pause {
+		p.waitPruneRound()
+	}
+}
+
+// waitPruneRound waits for any in-progress pruning round to finish.
+func (p *genericPruner) waitPruneRound() {
+	p.pruneLock.Lock()
+	p.pruneLock.Unlock() // nolint: staticcheck
+}
+
+func (p *genericPruner) isPaused() bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.paused || p.checkpointing
+}
+
 func (p *genericPruner) GetLastRetainedVersion() uint64 {
 	p.Lock()
 	defer p.Unlock()
@@ -149,60 +240,179 @@ func (p *genericPruner) Prune(ctx context.Context, latestVersion uint64) error {
 	return nil
 }
 
+// preserveFrom returns the earliest version that must be retained given the
+// latest version, based on the underlying strategy.
+func (p *genericPruner) preserveFrom(ctx context.Context, latestVersion uint64) (uint64, error) {
+	if p.keepEpochs == 0 {
+		if latestVersion < p.keepN {
+			return 0, nil
+		}
+		return latestVersion - p.keepN, nil
+	}
+
+	p.Lock()
+	timeSource := p.timeSource
+	p.Unlock()
+	if timeSource == nil {
+		return 0, fmt.Errorf("no epoch time source configured")
+	}
+
+	epoch, err := timeSource.GetEpoch(ctx, int64(latestVersion))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch at version %d: %w", latestVersion, err)
+	}
+	baseEpoch, err := timeSource.GetBaseEpoch(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get base epoch: %w", err)
+	}
+	// Retain the current epoch and the (keepEpochs-1) preceding ones.
+	if epoch < baseEpoch+beacon.EpochTime(p.keepEpochs-1) {
+		return 0, nil
+	}
+	height, err := timeSource.GetEpochBlock(ctx, epoch-beacon.EpochTime(p.keepEpochs-1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch block: %w", err)
+	}
+
+	// Always retain whole epochs so that state at epoch boundaries remains
+	// available, but never retain less than the minimum number of versions.
+	preserveFrom := uint64(height)
+	if latestVersion-preserveFrom < minKept {
+		preserveFrom = latestVersion - minKept
+	}
+	return preserveFrom, nil
+}
+
+// nextPruneTarget returns the version up to which (exclusive) the next chunk of versions,
+// starting at the given version, can be pruned without crossing preserveFrom.
+//
+// Epoch-aware strategies prune whole epochs at once so that the earliest retained version is
+// always an epoch boundary height and state at the start of every retained epoch remains
+// available. In case no whole epoch can be pruned, the given version is returned.
+func (p *genericPruner) nextPruneTarget(ctx context.Context, version, preserveFrom uint64) (uint64, error) {
+	if p.keepEpochs == 0 {
+		return version + 1, nil
+	}
+
+	p.Lock()
+	timeSource := p.timeSource
+	p.Unlock()
+
+	epoch, err := timeSource.GetEpoch(ctx, int64(version))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch at version %d: %w", version, err)
+	}
+	preserveEpoch, err := timeSource.GetEpoch(ctx, int64(preserveFrom))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch at version %d: %w", preserveFrom, err)
+	}
+	if epoch >= preserveEpoch {
+		// The next epoch is not fully below preserveFrom.
+		return version, nil
+	}
+
+	// The next epoch starts at or before preserveFrom, so its first height is known.
+	height, err := timeSource.GetEpochBlock(ctx, epoch+1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch block for epoch %d: %w", epoch+1, err)
+	}
+	if target := uint64(height); target > version && target <= preserveFrom {
+		return target, nil
+	}
+	return version, nil
+}
+
 func (p *genericPruner) doPrune(ctx context.Context, latestVersion uint64) error {
-	if latestVersion < p.keepN {
+	p.pruneLock.Lock()
+	defer p.pruneLock.Unlock()
+
+	if p.isPaused() {
+		p.logger.Debug("Prune: paused, skipping",
+			"latest_version", latestVersion,
+		)
+		return nil
+	}
+
+	preserveFrom, err := p.preserveFrom(ctx, latestVersion)
+	if err != nil {
+		return err
+	}
+	if preserveFrom <= p.earliestVersion {
+		abciPrunePendingVersions.Set(0)
 		return nil
 	}
 
 	p.logger.Debug("Prune: Start",
 		"latest_version", latestVersion,
 		"start_version", p.earliestVersion,
+		"preserve_from", preserveFrom,
 	)
 
-	preserveFrom := latestVersion - p.keepN
-PruneLoop:
-	for i := p.earliestVersion; i <= latestVersion; i++ {
-		if i >= preserveFrom {
-			p.earliestVersion = i
-			break
-		}
+	var numPruned uint64
+	pruneErr := func() error {
+		for p.earliestVersion < preserveFrom {
+			if p.isPaused() {
+				return nil
+			}
 
-		// Before pruning anything, run all prune handlers. If any of them
-		// fails we abort the prune.
-		for _, ph := range p.handlers {
-			if err := ph.Prune(ctx, i); err != nil {
-				p.logger.Debug("prune handler blocked pruning version",
-					"err", err,
-					"latest_version", latestVersion,
-					"version", i,
-				)
-				p.earliestVersion = i
-				break PruneLoop
+			target, err := p.nextPruneTarget(ctx, p.earliestVersion, preserveFrom)
+			if err != nil {
+				return err
+			}
+			if target <= p.earliestVersion {
+				return nil
 			}
-		}
 
-		p.logger.Debug("Prune: Delete",
-			"latest_version", latestVersion,
-			"pruned_version", i,
-			logging.LogEvent, LogEventABCIPruneDelete,
-		)
+			// Limit the amount of work done in a single pruning round so that pruning a large
+			// backlog doesn't starve the rest of the node. Remaining versions will be pruned in
+			// subsequent rounds. At least one chunk is always pruned to guarantee progress.
+			if p.batchSize > 0 && numPruned > 0 && numPruned+(target-p.earliestVersion) > p.batchSize {
+				return nil
+			}
 
-		err := p.ndb.Prune(ctx, i)
-		switch err {
-		case nil:
-		case nodedb.ErrNotEarliest:
-			p.logger.Debug("Prune: skipping non-earliest version",
-				"version", i,
-			)
-			continue
-		default:
-			return err
+			// Before pruning anything, run all prune handlers for the whole chunk. If any of
+			// them fails we abort the prune.
+			for i := p.earliestVersion; i < target; i++ {
+				for _, ph := range p.handlers {
+					if err = ph.Prune(ctx, i); err != nil {
+						p.logger.Debug("prune handler blocked pruning version",
+							"err", err,
+							"latest_version", latestVersion,
+							"version", i,
+						)
+						return nil
+					}
+				}
+			}
+
+			for i := p.earliestVersion; i < target; i++ {
+				p.logger.Debug("Prune: Delete",
+					"latest_version", latestVersion,
+					"pruned_version", i,
+					logging.LogEvent, LogEventABCIPruneDelete,
+				)
+
+				err = p.ndb.Prune(ctx, i)
+				switch err {
+				case nil:
+					numPruned++
+					abciPrunedVersions.Inc()
+				case nodedb.ErrNotEarliest:
+					p.logger.Debug("Prune: skipping non-earliest version",
+						"version", i,
+					)
+				default:
+					return err
+				}
+			}
+			p.earliestVersion = target
 		}
-	}
+		return nil
+	}()
 
 	// Make sure to sync the underlying database before updating what can be discarded. Otherwise
 	// things can be pruned and in case of a crash replay will not be possible.
-	if err := p.ndb.Sync(); err != nil {
+	if err = p.ndb.Sync(); err != nil {
 		return fmt.Errorf("failed to sync state database: %w", err)
 	}
 
@@ -211,12 +421,21 @@ PruneLoop:
 	p.lastRetainedVersion = p.earliestVersion
 	p.Unlock()
 
+	abciLastRetainedVersion.Set(float64(p.earliestVersion))
+	var pending uint64
+	if preserveFrom > p.earliestVersion {
+		pending = preserveFrom - p.earliestVersion
+	}
+	abciPrunePendingVersions.Set(float64(pending))
+
 	p.logger.Debug("Prune: Finish",
 		"latest_version", latestVersion,
 		"eldest_version", p.earliestVersion,
+		"num_pruned", numPruned,
+		"num_pending", pending,
 	)
 
-	return nil
+	return pruneErr
 }
 
 func (p *genericPruner) RegisterHandler(handler api.StatePruneHandler) {
@@ -227,10 +446,6 @@ func (p *genericPruner) RegisterHandler(handler api.StatePruneHandler) {
 }
 
 func newStatePruner(cfg *PruneConfig, ndb nodedb.NodeDB) (StatePruner, error) {
-	// The roothash checkCommittees call requires at least 1 previous block
-	// for timekeeping purposes.
-	const minKept = 1
-
 	logger := logging.GetLogger("abci-mux/pruner")
 
 	var statePruner StatePruner
@@ -243,9 +458,21 @@ func newStatePruner(cfg *PruneConfig, ndb nodedb.NodeDB) (StatePruner, error) {
 		}
 
 		statePruner = &genericPruner{
-			logger: logger,
-			ndb:    ndb,
-			keepN:  cfg.NumKept,
+			logger:    logger,
+			ndb:       ndb,
+			keepN:     cfg.NumKept,
+			batchSize: cfg.BatchSize,
+		}
+	case PruneKeepEpochs:
+		if cfg.NumKeptEpochs < 1 {
+			return nil, fmt.Errorf("abci/pruner: invalid number of epochs retained: %v", cfg.NumKeptEpochs)
+		}
+
+		statePruner = &genericPruner{
+			logger:     logger,
+			ndb:        ndb,
+			keepEpochs: cfg.NumKeptEpochs,
+			batchSize:  cfg.BatchSize,
 		}
 	default:
 		return nil, fmt.Errorf("abci/pruner: unsupported pruning strategy: %v", cfg.Strategy)
@@ -260,6 +487,8 @@ func newStatePruner(cfg *PruneConfig, ndb nodedb.NodeDB) (StatePruner, error) {
 	logger.Debug("ABCI state pruner initialized",
 		"strategy", cfg.Strategy,
 		"num_kept", cfg.NumKept,
+		"num_kept_epochs", cfg.NumKeptEpochs,
+		"batch_size", cfg.BatchSize,
 	)
 
 	return statePruner, nil
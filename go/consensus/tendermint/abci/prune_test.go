@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
@@ -82,3 +83,190 @@ func TestPruneKeepN(t *testing.T) {
 	lastRetainedVersion = pruner.GetLastRetainedVersion()
 	require.EqualValues(9, lastRetainedVersion, "last retained version should be correct")
 }
+
+// testEpochTimeSource is an epoch time source where each epoch lasts 5 blocks, starting at
+// height 1.
+type testEpochTimeSource struct {
+	epochLookups int
+}
+
+func (ts *testEpochTimeSource) GetBaseEpoch(ctx context.Context) (beacon.EpochTime, error) {
+	return 0, nil
+}
+
+func (ts *testEpochTimeSource) GetEpoch(ctx context.Context, height int64) (beacon.EpochTime, error) {
+	ts.epochLookups++
+	return beacon.EpochTime((height - 1) / 5), nil
+}
+
+func (ts *testEpochTimeSource) GetEpochBlock(ctx context.Context, epoch beacon.EpochTime) (int64, error) {
+	return int64(epoch)*5 + 1, nil
+}
+
+func newTestPruneNodeDB(t *testing.T, numVersions uint64) (mkvsDB.NodeDB, func()) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "abci-prune.test.badger")
+	require.NoError(err, "TempDir")
+
+	ndb, err := mkvsBadgerDB.New(&mkvsDB.Config{
+		DB:           dir,
+		NoFsync:      true,
+		MaxCacheSize: 16 * 1024 * 1024,
+	})
+	require.NoError(err, "New")
+	tree := mkvs.New(nil, ndb, mkvsNode.RootTypeState)
+
+	ctx := context.Background()
+	for i := uint64(1); i <= numVersions; i++ {
+		err = tree.Insert(ctx, []byte(fmt.Sprintf("key:%d", i)), []byte(fmt.Sprintf("value:%d", i)))
+		require.NoError(err, "Insert")
+
+		var rootHash hash.Hash
+		_, rootHash, err = tree.Commit(ctx, common.Namespace{}, i)
+		require.NoError(err, "Commit")
+		err = ndb.Finalize(ctx, []mkvsNode.Root{{Namespace: common.Namespace{}, Version: i, Type: mkvsNode.RootTypeState, Hash: rootHash}})
+		require.NoError(err, "Finalize")
+	}
+
+	return ndb, func() {
+		ndb.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPruneKeepEpochs(t *testing.T) {
+	require := require.New(t)
+
+	ndb, cleanup := newTestPruneNodeDB(t, 23)
+	defer cleanup()
+
+	pruner, err := newStatePruner(&PruneConfig{
+		Strategy:      PruneKeepEpochs,
+		NumKeptEpochs: 2,
+	}, ndb)
+	require.NoError(err, "newStatePruner failed")
+
+	ctx := context.Background()
+	err = pruner.Prune(ctx, 23)
+	require.Error(err, "Prune should fail without an epoch time source")
+
+	timeSource := &testEpochTimeSource{}
+	pruner.(statePrunerTimeSourceSetter).setTimeSource(timeSource)
+
+	// Version 23 is in epoch 4 (heights 21-25), so epochs 3 and 4 should be retained.
+	err = pruner.Prune(ctx, 23)
+	require.NoError(err, "Prune")
+	require.Less(timeSource.epochLookups, 16, "epochs should not be looked up for every pruned version")
+
+	earliestVersion, err := ndb.GetEarliestVersion(ctx)
+	require.NoError(err, "GetEarliestVersion")
+	require.EqualValues(16, earliestVersion, "earliest version should be at the epoch boundary")
+	require.EqualValues(16, pruner.GetLastRetainedVersion(), "last retained version should be correct")
+
+	_, err = newStatePruner(&PruneConfig{
+		Strategy:      PruneKeepEpochs,
+		NumKeptEpochs: 0,
+	}, ndb)
+	require.Error(err, "newStatePruner should fail when no epochs are retained")
+}
+
+func TestPruneBatchSizeAndPause(t *testing.T) {
+	require := require.New(t)
+
+	ndb, cleanup := newTestPruneNodeDB(t, 11)
+	defer cleanup()
+
+	pruner, err := newStatePruner(&PruneConfig{
+		Strategy:  PruneKeepN,
+		NumKept:   2,
+		BatchSize: 3,
+	}, ndb)
+	require.NoError(err, "newStatePruner failed")
+
+	ctx := context.Background()
+
+	// Nothing should be pruned while paused.
+	pruner.Pause(true)
+	err = pruner.Prune(ctx, 11)
+	require.NoError(err, "Prune")
+	require.EqualValues(1, pruner.GetLastRetainedVersion(), "nothing should be pruned while paused")
+	pruner.Pause(false)
+
+	// Only a limited number of versions should be pruned in each round.
+	for _, expected := range []uint64{4, 7, 9, 9} {
+		err = pruner.Prune(ctx, 11)
+		require.NoError(err, "Prune")
+
+		var earliestVersion uint64
+		earliestVersion, err = ndb.GetEarliestVersion(ctx)
+		require.NoError(err, "GetEarliestVersion")
+		require.EqualValues(expected, earliestVersion, "earliest version should be correct")
+		require.EqualValues(expected, pruner.GetLastRetainedVersion(), "last retained version should be correct")
+	}
+}
+
+type blockingPruneHandler struct {
+	version uint64
+}
+
+func (h *blockingPruneHandler) Prune(ctx context.Context, version uint64) error {
+	if version == h.version {
+		return fmt.Errorf("version %d is blocked", version)
+	}
+	return nil
+}
+
+func TestPruneKeepEpochsBoundaries(t *testing.T) {
+	require := require.New(t)
+
+	ndb, cleanup := newTestPruneNodeDB(t, 23)
+	defer cleanup()
+
+	pruner, err := newStatePruner(&PruneConfig{
+		Strategy:      PruneKeepEpochs,
+		NumKeptEpochs: 2,
+		BatchSize:     3,
+	}, ndb)
+	require.NoError(err, "newStatePruner failed")
+	pruner.(statePrunerTimeSourceSetter).setTimeSource(&testEpochTimeSource{})
+	handler := &blockingPruneHandler{version: 18}
+	pruner.RegisterHandler(handler)
+
+	ctx := context.Background()
+
+	// Nothing should be pruned while a checkpoint is being created.
+	pruner.(statePrunerCheckpointPauser).pauseForCheckpoint(true)
+	err = pruner.Prune(ctx, 23)
+	require.NoError(err, "Prune")
+	require.EqualValues(1, pruner.GetLastRetainedVersion(), "nothing should be pruned while checkpointing")
+	pruner.(statePrunerCheckpointPauser).pauseForCheckpoint(false)
+
+	// Whole epochs should be pruned in each round even when they exceed the batch size, so that
+	// the earliest retained version is always an epoch boundary height.
+	for _, expected := range []uint64{6, 11, 16, 16} {
+		err = pruner.Prune(ctx, 23)
+		require.NoError(err, "Prune")
+
+		var earliestVersion uint64
+		earliestVersion, err = ndb.GetEarliestVersion(ctx)
+		require.NoError(err, "GetEarliestVersion")
+		require.EqualValues(expected, earliestVersion, "earliest version should be at the epoch boundary")
+		require.EqualValues(expected, pruner.GetLastRetainedVersion(), "last retained version should be correct")
+	}
+
+	// A prune handler blocking a version in the middle of an epoch should prevent the whole epoch
+	// from being pruned.
+	err = pruner.Prune(ctx, 28)
+	require.NoError(err, "Prune")
+	earliestVersion, err := ndb.GetEarliestVersion(ctx)
+	require.NoError(err, "GetEarliestVersion")
+	require.EqualValues(16, earliestVersion, "epoch with a blocked version should not be pruned")
+
+	handler.version = 0
+	err = pruner.Prune(ctx, 28)
+	require.NoError(err, "Prune")
+	earliestVersion, err = ndb.GetEarliestVersion(ctx)
+	require.NoError(err, "GetEarliestVersion")
+	require.EqualValues(21, earliestVersion, "earliest version should be at the epoch boundary")
+}
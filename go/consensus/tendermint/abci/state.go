@@ -410,6 +410,15 @@ func (s *applicationState) metricsWorker() {
 }
 
 func (s *applicationState) startPruner() error {
+	// Epoch-aware pruning strategies need access to epoch time which is only
+	// available once the beacon service has been registered.
+	if setter, ok := s.statePruner.(statePrunerTimeSourceSetter); ok {
+		if s.timeSource == nil {
+			return fmt.Errorf("no epoch time source configured")
+		}
+		setter.setTimeSource(s.timeSource)
+	}
+
 	go s.pruneWorker()
 	return nil
 }
@@ -603,6 +612,9 @@ func newApplicationState(ctx context.Context, upgrader upgrade.Backend, cfg *App
 				}, nil
 			},
 		}
+		if pauser, ok := statePruner.(statePrunerCheckpointPauser); ok {
+			checkpointerCfg.PausePruning = pauser.pauseForCheckpoint
+		}
 		s.checkpointer, err = checkpoint.NewCheckpointer(s.ctx, ndb, ldb.Checkpointer(), checkpointerCfg)
 		if err != nil {
 			return nil, fmt.Errorf("state: failed to create checkpointer: %w", err)
@@ -249,6 +249,10 @@ type StatePruneHandler interface {
 type StatePruner interface {
 	// RegisterHandler registers a prune handler.
 	RegisterHandler(handler StatePruneHandler)
+
+	// Pause pauses or unpauses pruning. While paused, no versions are pruned
+	// but pruning resumes from where it left off once unpaused.
+	Pause(pause bool)
 }
 
 // TransactionAuthHandler is the interface for ABCI applications that handle
@@ -389,3 +393,24 @@ type messageKind uint8
 // MessageStateSyncCompleted is the message kind for when the node successfully performs a state
 // sync. The message itself is nil.
 var MessageStateSyncCompleted = messageKind(0)
+
+// parametersMessageKind is the message kind for consensus parameter change messages targeting
+// a specific consensus backend module.
+type parametersMessageKind struct {
+	module string
+	apply  bool
+}
+
+// MessageValidateParameterChanges returns the message kind for validating consensus parameter
+// changes of the given module. The message is a *governance.ChangeParametersProposal.
+//
+// Subscribers must not modify any state when handling this message.
+func MessageValidateParameterChanges(module string) interface{} {
+	return parametersMessageKind{module: module}
+}
+
+// MessageChangeParameters returns the message kind for applying consensus parameter changes
+// to the given module. The message is a *governance.ChangeParametersProposal.
+func MessageChangeParameters(module string) interface{} {
+	return parametersMessageKind{module: module, apply: true}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
 )
@@ -72,8 +73,9 @@ type Context struct { // nolint: maligned
 	events        []types.Event
 	gasAccountant GasAccountant
 
-	txSigner      signature.PublicKey
-	callerAddress staking.Address
+	txSigner          signature.PublicKey
+	txMultisigAccount *transaction.MultisigAccount
+	callerAddress     staking.Address
 
 	appState      ApplicationState
 	state         mkvs.KeyValueTree
@@ -169,6 +171,9 @@ func (c *Context) Data() interface{} {
 
 // TxSigner returns the authenticated transaction signer.
 //
+// For transactions authorized by a multisig account there is no single signer and an empty
+// public key is returned. Use CallerAddress to identify the caller instead.
+//
 // In case the method is called on a non-transaction context, this method
 // will panic.
 func (c *Context) TxSigner() signature.PublicKey {
@@ -197,6 +202,40 @@ func (c *Context) SetTxSigner(txSigner signature.PublicKey) {
 	}
 }
 
+// TxMultisigAccount returns the authenticated multisig account descriptor in case the
+// transaction was authorized by a multisig account and nil otherwise.
+//
+// In case the method is called on a non-transaction context, this method
+// will panic.
+func (c *Context) TxMultisigAccount() *transaction.MultisigAccount {
+	switch c.mode {
+	case ContextCheckTx, ContextDeliverTx, ContextSimulateTx:
+		return c.txMultisigAccount
+	default:
+		panic("context: only available in transaction context")
+	}
+}
+
+// SetTxMultisigSigner sets the authenticated multisig account that authorized the transaction.
+//
+// This must only be done after verifying the transaction signatures against the account
+// descriptor. As there is no single transaction signer, the transaction signer is cleared and
+// the transaction must only be dispatched to methods that support multisig callers.
+//
+// In case the method is called on a non-transaction context, this method
+// will panic.
+func (c *Context) SetTxMultisigSigner(account *transaction.MultisigAccount) {
+	switch c.mode {
+	case ContextCheckTx, ContextDeliverTx, ContextSimulateTx:
+		c.txSigner = signature.PublicKey{}
+		c.txMultisigAccount = account
+		// The caller is the multisig account.
+		c.callerAddress = staking.NewMultisigAddress(account)
+	default:
+		panic("context: only available in transaction context")
+	}
+}
+
 // CallerAddress returns the authenticated address representing the caller.
 func (c *Context) CallerAddress() staking.Address {
 	return c.callerAddress
@@ -213,6 +252,7 @@ func (c *Context) NewChild() *Context {
 		isMessageExecution: c.isMessageExecution,
 		gasAccountant:      c.gasAccountant,
 		txSigner:           c.txSigner,
+		txMultisigAccount:  c.txMultisigAccount,
 		callerAddress:      c.callerAddress,
 		appState:           c.appState,
 		state:              c.state,
@@ -94,6 +94,16 @@ type ApplicationQueryState interface {
 	LastRetainedVersion() (int64, error)
 }
 
+// ImmutableStateProvider is an application query state that provides its own immutable state
+// instead of reading it from local storage (e.g., remote state verified by a light client).
+type ImmutableStateProvider interface {
+	ApplicationQueryState
+
+	// GetImmutableState returns the immutable state at the given version. A version of zero or
+	// less refers to the latest available version.
+	GetImmutableState(ctx context.Context, version int64) (*ImmutableState, error)
+}
+
 // MockApplicationState is the mock application state interface.
 type MockApplicationState interface {
 	ApplicationState
@@ -289,6 +299,11 @@ func NewImmutableState(ctx context.Context, state ApplicationQueryState, version
 		}
 	}
 
+	// Handle states that provide their own trees.
+	if sp, ok := state.(ImmutableStateProvider); ok {
+		return sp.GetImmutableState(ctx, version)
+	}
+
 	// Handle a regular (external) query where we need to create a new tree.
 	if state.BlockHeight() == 0 {
 		return nil, consensus.ErrNoCommittedBlocks
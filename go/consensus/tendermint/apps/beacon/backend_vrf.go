@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/tendermint/tendermint/abci/types"
 
@@ -133,8 +134,16 @@ func (impl *backendVRF) OnBeginBlock(
 				return fmt.Errorf("beacon: failed to get current epoch transition height: %w", err)
 			}
 
-			// Delay the mock transition up to the standard interval.
-			if height < lastHeight+params.VRFParameters.Interval {
+			// Delay the mock transition up to the current interval.
+			interval := params.VRFParameters.Interval
+			var timing *beacon.EpochTimingState
+			if timing, err = state.EpochTiming(ctx); err != nil {
+				return fmt.Errorf("beacon: failed to get epoch timing state: %w", err)
+			}
+			if timing != nil {
+				interval = timing.Interval
+			}
+			if height < lastHeight+interval {
 				return nil
 			}
 
@@ -424,9 +433,49 @@ func (impl *backendVRF) scheduleEpochTransitionBlock(
 	params *beacon.VRFParameters,
 	nextEpoch beacon.EpochTime,
 ) error {
-	// Schedule the epoch transition based on block height.
-	nextHeight := int64(nextEpoch) * params.Interval
-	return impl.app.scheduleEpochTransitionBlock(ctx, state, nextEpoch, nextHeight)
+	_, epochHeight, err := state.GetEpoch(ctx)
+	if err != nil {
+		return fmt.Errorf("beacon: failed to get current epoch: %w", err)
+	}
+	timing, err := state.EpochTiming(ctx)
+	if err != nil {
+		return fmt.Errorf("beacon: failed to get epoch timing state: %w", err)
+	}
+
+	if !params.IsTimeBased() {
+		if timing != nil {
+			// Time-based epochs have been disabled, so epoch heights are no
+			// longer aligned to the interval.
+			if err = state.ClearEpochTiming(ctx); err != nil {
+				return fmt.Errorf("beacon: failed to clear epoch timing state: %w", err)
+			}
+		}
+
+		// Schedule the epoch transition based on block height.
+		nextHeight := int64(nextEpoch) * params.Interval
+		if nextHeight <= epochHeight {
+			nextHeight = epochHeight + params.Interval
+		}
+		return impl.app.scheduleEpochTransitionBlock(ctx, state, nextEpoch, nextHeight)
+	}
+
+	// Schedule the epoch transition based on the target epoch duration, by
+	// adjusting the interval based on the duration of the previous epoch.
+
+	now := ctx.Now().Unix()
+	interval := params.Interval
+	if timing != nil {
+		elapsed := time.Duration(now-timing.Time) * time.Second
+		interval = params.NextInterval(timing.Interval, elapsed)
+	}
+	if err = state.SetEpochTiming(ctx, &beacon.EpochTimingState{
+		Interval: interval,
+		Time:     now,
+	}); err != nil {
+		return fmt.Errorf("beacon: failed to set epoch timing state: %w", err)
+	}
+
+	return impl.app.scheduleEpochTransitionBlock(ctx, state, nextEpoch, epochHeight+interval)
 }
 
 func (impl *backendVRF) initAlphaCommon(
@@ -50,6 +50,10 @@ func (app *beaconApplication) Dependencies() []string {
 
 func (app *beaconApplication) OnRegister(state api.ApplicationState, md api.MessageDispatcher) {
 	app.state = state
+
+	// Subscribe to messages emitted by other apps.
+	md.Subscribe(api.MessageValidateParameterChanges(beacon.ModuleName), app)
+	md.Subscribe(api.MessageChangeParameters(beacon.ModuleName), app)
 }
 
 func (app *beaconApplication) OnCleanup() {
@@ -71,7 +75,14 @@ func (app *beaconApplication) BeginBlock(ctx *api.Context, req types.RequestBegi
 }
 
 func (app *beaconApplication) ExecuteMessage(ctx *api.Context, kind, msg interface{}) error {
-	return fmt.Errorf("beacon: unexpected message")
+	switch kind {
+	case api.MessageValidateParameterChanges(beacon.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case api.MessageChangeParameters(beacon.ModuleName):
+		return app.changeParameters(ctx, msg, true)
+	default:
+		return fmt.Errorf("beacon: unexpected message")
+	}
 }
 
 func (app *beaconApplication) ExecuteTx(ctx *api.Context, tx *transaction.Transaction) error {
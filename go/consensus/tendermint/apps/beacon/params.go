@@ -0,0 +1,53 @@
+package beacon
+
+import (
+	"fmt"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	beaconState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/beacon/state"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+)
+
+// changeParameters validates and optionally applies consensus parameter changes.
+func (app *beaconApplication) changeParameters(ctx *api.Context, msg interface{}, apply bool) error {
+	proposal, ok := msg.(*governance.ChangeParametersProposal)
+	if !ok {
+		return fmt.Errorf("beacon: failed to type assert change parameters proposal")
+	}
+	if proposal.Module != beacon.ModuleName {
+		return fmt.Errorf("beacon: wrong module for parameter changes: %s", proposal.Module)
+	}
+
+	// Validate changes against the current parameters.
+	var changes beacon.ConsensusParameterChanges
+	if err := cbor.Unmarshal(proposal.Changes, &changes); err != nil {
+		return fmt.Errorf("beacon: failed to unmarshal consensus parameter changes: %w", err)
+	}
+	if err := changes.SanityCheck(); err != nil {
+		return fmt.Errorf("beacon: failed to validate consensus parameter changes: %w", err)
+	}
+
+	state := beaconState.NewMutableState(ctx.State())
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("beacon: failed to load consensus parameters: %w", err)
+	}
+	if err = changes.Apply(params); err != nil {
+		return fmt.Errorf("beacon: failed to apply consensus parameter changes: %w", err)
+	}
+	if err = params.SanityCheck(); err != nil {
+		return fmt.Errorf("beacon: failed to validate consensus parameters: %w", err)
+	}
+
+	if !apply {
+		return nil
+	}
+
+	// Apply changes.
+	if err = state.SetConsensusParameters(ctx, params); err != nil {
+		return fmt.Errorf("beacon: failed to update consensus parameters: %w", err)
+	}
+	return nil
+}
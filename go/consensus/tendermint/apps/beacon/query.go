@@ -16,6 +16,7 @@ type Query interface {
 	Genesis(context.Context) (*beacon.Genesis, error)
 	ConsensusParameters(context.Context) (*beacon.ConsensusParameters, error)
 	VRFState(context.Context) (*beacon.VRFState, error)
+	EpochTiming(context.Context) (*beacon.EpochTimingState, error)
 }
 
 // QueryFactory is the beacon query factory.
@@ -56,6 +57,10 @@ func (bq *beaconQuerier) VRFState(ctx context.Context) (*beacon.VRFState, error)
 	return bq.state.VRFState(ctx)
 }
 
+func (bq *beaconQuerier) EpochTiming(ctx context.Context) (*beacon.EpochTimingState, error) {
+	return bq.state.EpochTiming(ctx)
+}
+
 func (app *beaconApplication) QueryFactory() interface{} {
 	return &QueryFactory{app.state}
 }
@@ -10,8 +10,15 @@ import (
 	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 )
 
-// vrfStateKeyFmt is the current VRF state key format.
-var vrfStateKeyFmt = keyformat.New(0x46)
+var (
+	// vrfStateKeyFmt is the current VRF state key format.
+	vrfStateKeyFmt = keyformat.New(0x46)
+	// epochTimingKeyFmt is the epoch timing state key format.
+	//
+	// Value is CBOR-serialized epoch timing state. Only present when the
+	// epoch interval is derived from a target epoch duration.
+	epochTimingKeyFmt = keyformat.New(0x47)
+)
 
 func (s *ImmutableState) VRFState(ctx context.Context) (*beacon.VRFState, error) {
 	data, err := s.is.Get(ctx, vrfStateKeyFmt.Encode())
@@ -36,3 +43,29 @@ func (s *MutableState) SetVRFState(ctx context.Context, state *beacon.VRFState)
 	err := s.ms.Insert(ctx, vrfStateKeyFmt.Encode(), cbor.Marshal(state))
 	return abciAPI.UnavailableStateError(err)
 }
+
+func (s *ImmutableState) EpochTiming(ctx context.Context) (*beacon.EpochTimingState, error) {
+	data, err := s.is.Get(ctx, epochTimingKeyFmt.Encode())
+	if err != nil {
+		return nil, abciAPI.UnavailableStateError(err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var state beacon.EpochTimingState
+	if err = cbor.Unmarshal(data, &state); err != nil {
+		return nil, abciAPI.UnavailableStateError(err)
+	}
+	return &state, nil
+}
+
+func (s *MutableState) SetEpochTiming(ctx context.Context, state *beacon.EpochTimingState) error {
+	err := s.ms.Insert(ctx, epochTimingKeyFmt.Encode(), cbor.Marshal(state))
+	return abciAPI.UnavailableStateError(err)
+}
+
+func (s *MutableState) ClearEpochTiming(ctx context.Context) error {
+	err := s.ms.Remove(ctx, epochTimingKeyFmt.Encode())
+	return abciAPI.UnavailableStateError(err)
+}
@@ -26,6 +26,7 @@ var _ api.Application = (*governanceApplication)(nil)
 
 type governanceApplication struct {
 	state api.ApplicationState
+	md    api.MessageDispatcher
 }
 
 func (app *governanceApplication) Name() string {
@@ -50,9 +51,12 @@ func (app *governanceApplication) Dependencies() []string {
 
 func (app *governanceApplication) OnRegister(state api.ApplicationState, md api.MessageDispatcher) {
 	app.state = state
+	app.md = md
 
 	// Subscribe to messages emitted by other apps.
 	md.Subscribe(api.MessageStateSyncCompleted, app)
+	md.Subscribe(api.MessageValidateParameterChanges(governance.ModuleName), app)
+	md.Subscribe(api.MessageChangeParameters(governance.ModuleName), app)
 }
 
 func (app *governanceApplication) OnCleanup() {
@@ -109,6 +113,10 @@ func (app *governanceApplication) ExecuteMessage(ctx *api.Context, kind, msg int
 			}
 		}
 		return nil
+	case api.MessageValidateParameterChanges(governance.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case api.MessageChangeParameters(governance.ModuleName):
+		return app.changeParameters(ctx, msg, true)
 	default:
 		return governance.ErrInvalidArgument
 	}
@@ -260,6 +268,28 @@ func (app *governanceApplication) executeProposal(ctx *api.Context, state *gover
 				)
 			}
 		}
+	case proposal.Content.ChangeParameters != nil:
+		changes := proposal.Content.ChangeParameters
+
+		// Apply the changes in a separate transaction context so that a failure
+		// does not result in partially applied changes.
+		txCtx := ctx.NewTransaction()
+		defer txCtx.Close()
+
+		switch err := app.md.Publish(txCtx, api.MessageChangeParameters(changes.Module), changes); err {
+		case nil:
+		case api.ErrNoSubscribers:
+			return fmt.Errorf("%w: unsupported module: %s", governance.ErrInvalidParameterChanges, changes.Module)
+		default:
+			return fmt.Errorf("%w: %s", governance.ErrInvalidParameterChanges, err)
+		}
+		txCtx.Commit()
+
+		// Emit parameters changed event.
+		ctx.EmitEvent(api.NewEventBuilder(app.Name()).TypedAttribute(&governance.ParametersChangedEvent{
+			ID:     proposal.ID,
+			Module: changes.Module,
+		}))
 	default:
 		return governance.ErrInvalidArgument
 	}
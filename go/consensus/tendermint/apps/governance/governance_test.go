@@ -31,6 +31,35 @@ import (
 
 const numTestAccounts = 5
 
+// testMsgDispatcher is a message dispatcher that only dispatches messages to
+// the governance application.
+type testMsgDispatcher struct {
+	app *governanceApplication
+}
+
+// Implements MessageDispatcher.
+func (md *testMsgDispatcher) Subscribe(interface{}, abciAPI.MessageSubscriber) {
+}
+
+// Implements MessageDispatcher.
+func (md *testMsgDispatcher) Publish(ctx *abciAPI.Context, kind, msg interface{}) error {
+	switch kind {
+	case abciAPI.MessageValidateParameterChanges(governance.ModuleName),
+		abciAPI.MessageChangeParameters(governance.ModuleName):
+		return md.app.ExecuteMessage(ctx, kind, msg)
+	default:
+		return abciAPI.ErrNoSubscribers
+	}
+}
+
+func newTestApp(appState abciAPI.ApplicationState) *governanceApplication {
+	app := &governanceApplication{
+		state: appState,
+	}
+	app.md = &testMsgDispatcher{app}
+	return app
+}
+
 var testAccountsStake = quantity.NewFromUint64(100)
 
 func initValidatorsEscrowState(
@@ -343,9 +372,7 @@ func TestExecuteProposal(t *testing.T) {
 
 	// Setup governance state.
 	state := governanceState.NewMutableState(ctx.State())
-	app := &governanceApplication{
-		state: appState,
-	}
+	app := newTestApp(appState)
 	// Consensus parameters.
 	err = state.SetConsensusParameters(ctx, &governance.ConsensusParameters{
 		MinProposalDeposit:        *quantity.NewFromUint64(100),
@@ -366,6 +393,9 @@ func TestExecuteProposal(t *testing.T) {
 	err = state.SetPendingUpgrade(ctx, 1, &defaultUpgradeProposal.Descriptor)
 	require.NoError(err, "SetPendingUpgrade")
 
+	votingPeriod := beacon.EpochTime(5)
+	invalidStakeThreshold := uint8(50)
+
 	for _, tc := range []struct {
 		msg      string
 		proposal *governance.Proposal
@@ -471,6 +501,45 @@ func TestExecuteProposal(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			"executing change parameters proposal should fail for unsupported module",
+			&governance.Proposal{
+				ID: 13,
+				Content: governance.ProposalContent{
+					ChangeParameters: &governance.ChangeParametersProposal{
+						Module:  "unsupported",
+						Changes: cbor.Marshal(&governance.ConsensusParameterChanges{VotingPeriod: &votingPeriod}),
+					},
+				},
+			},
+			governance.ErrInvalidParameterChanges,
+		},
+		{
+			"executing change parameters proposal should fail for invalid changes",
+			&governance.Proposal{
+				ID: 14,
+				Content: governance.ProposalContent{
+					ChangeParameters: &governance.ChangeParametersProposal{
+						Module:  governance.ModuleName,
+						Changes: cbor.Marshal(&governance.ConsensusParameterChanges{StakeThreshold: &invalidStakeThreshold}),
+					},
+				},
+			},
+			governance.ErrInvalidParameterChanges,
+		},
+		{
+			"executing change parameters proposal should work",
+			&governance.Proposal{
+				ID: 15,
+				Content: governance.ProposalContent{
+					ChangeParameters: &governance.ChangeParametersProposal{
+						Module:  governance.ModuleName,
+						Changes: cbor.Marshal(&governance.ConsensusParameterChanges{VotingPeriod: &votingPeriod}),
+					},
+				},
+			},
+			nil,
+		},
 	} {
 		err = app.executeProposal(ctx, state, tc.proposal)
 		if tc.err != nil {
@@ -489,6 +558,12 @@ func TestExecuteProposal(t *testing.T) {
 		err = state.SetProposal(ctx, tc.proposal)
 		require.NoError(err, "SetProposal")
 	}
+
+	// Ensure parameter changes were applied.
+	params, err := state.ConsensusParameters(ctx)
+	require.NoError(err, "ConsensusParameters")
+	require.Equal(votingPeriod, params.VotingPeriod, "voting period should be changed")
+	require.EqualValues(90, params.StakeThreshold, "stake threshold should not be changed")
 }
 
 func TestBeginBlock(t *testing.T) {
@@ -0,0 +1,52 @@
+package governance
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	governanceState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/governance/state"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+)
+
+// changeParameters validates and optionally applies consensus parameter changes.
+func (app *governanceApplication) changeParameters(ctx *api.Context, msg interface{}, apply bool) error {
+	proposal, ok := msg.(*governance.ChangeParametersProposal)
+	if !ok {
+		return fmt.Errorf("governance: failed to type assert change parameters proposal")
+	}
+	if proposal.Module != governance.ModuleName {
+		return fmt.Errorf("governance: wrong module for parameter changes: %s", proposal.Module)
+	}
+
+	// Validate changes against the current parameters.
+	var changes governance.ConsensusParameterChanges
+	if err := cbor.Unmarshal(proposal.Changes, &changes); err != nil {
+		return fmt.Errorf("governance: failed to unmarshal consensus parameter changes: %w", err)
+	}
+	if err := changes.SanityCheck(); err != nil {
+		return fmt.Errorf("governance: failed to validate consensus parameter changes: %w", err)
+	}
+
+	state := governanceState.NewMutableState(ctx.State())
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("governance: failed to load consensus parameters: %w", err)
+	}
+	if err = changes.Apply(params); err != nil {
+		return fmt.Errorf("governance: failed to apply consensus parameter changes: %w", err)
+	}
+	if err = params.SanityCheck(); err != nil {
+		return fmt.Errorf("governance: failed to validate consensus parameters: %w", err)
+	}
+
+	if !apply {
+		return nil
+	}
+
+	// Apply changes.
+	if err = state.SetConsensusParameters(ctx, params); err != nil {
+		return fmt.Errorf("governance: failed to update consensus parameters: %w", err)
+	}
+	return nil
+}
@@ -47,8 +47,8 @@ func (app *governanceApplication) submitProposal(
 		return err
 	}
 
-	// Load submitter account.
-	submitterAddr := stakingAPI.NewAddress(ctx.TxSigner())
+	// Load submitter account. The submitter can also be a multisig account.
+	submitterAddr := ctx.CallerAddress()
 	if !submitterAddr.IsValid() {
 		return stakingAPI.ErrForbidden
 	}
@@ -127,6 +127,28 @@ func (app *governanceApplication) submitProposal(
 		if upgrade.Descriptor.Epoch < params.UpgradeCancelMinEpochDiff+epoch {
 			return governance.ErrUpgradeTooSoon
 		}
+
+	case proposalContent.ChangeParameters != nil:
+		changes := proposalContent.ChangeParameters
+		// Validate the changes in a separate transaction context to make sure that no state
+		// updates are propagated, even if a module misbehaves.
+		txCtx := ctx.NewTransaction()
+		defer txCtx.Close()
+
+		switch err = app.md.Publish(txCtx, api.MessageValidateParameterChanges(changes.Module), changes); err {
+		case nil:
+		case api.ErrNoSubscribers:
+			ctx.Logger().Error("governance: parameter changes for an unsupported module",
+				"module", changes.Module,
+			)
+			return governance.ErrInvalidParameterChanges
+		default:
+			ctx.Logger().Error("governance: invalid parameter changes",
+				"module", changes.Module,
+				"err", err,
+			)
+			return governance.ErrInvalidParameterChanges
+		}
 	}
 
 	// Deposit proposal funds.
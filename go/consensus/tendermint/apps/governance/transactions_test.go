@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
@@ -46,9 +47,7 @@ func TestSubmitProposal(t *testing.T) {
 
 	// Setup governance state.
 	state := governanceState.NewMutableState(ctx.State())
-	app := &governanceApplication{
-		state: appState,
-	}
+	app := newTestApp(appState)
 
 	minProposalDeposit := quantity.NewFromUint64(100)
 	baseConsParams := &governance.ConsensusParameters{
@@ -60,6 +59,9 @@ func TestSubmitProposal(t *testing.T) {
 		VotingPeriod:              beacon.EpochTime(50),
 	}
 
+	votingPeriod := beacon.EpochTime(60)
+	invalidVotingPeriod := beacon.EpochTime(200)
+
 	for _, tc := range []struct {
 		msg             string
 		params          *governance.ConsensusParameters
@@ -225,6 +227,70 @@ func TestSubmitProposal(t *testing.T) {
 			},
 			governance.ErrUpgradeAlreadyPending,
 		},
+		{
+			"should fail with invalid change parameters proposal",
+			baseConsParams,
+			pk1,
+			&governance.ProposalContent{
+				ChangeParameters: &governance.ChangeParametersProposal{
+					Module: governance.ModuleName,
+				},
+			},
+			func() {},
+			governance.ErrInvalidArgument,
+		},
+		{
+			"should fail change parameters proposal for unsupported module",
+			baseConsParams,
+			pk1,
+			&governance.ProposalContent{
+				ChangeParameters: &governance.ChangeParametersProposal{
+					Module:  "unsupported",
+					Changes: cbor.Marshal(&governance.ConsensusParameterChanges{VotingPeriod: &votingPeriod}),
+				},
+			},
+			func() {},
+			governance.ErrInvalidParameterChanges,
+		},
+		{
+			"should fail change parameters proposal with empty changes",
+			baseConsParams,
+			pk1,
+			&governance.ProposalContent{
+				ChangeParameters: &governance.ChangeParametersProposal{
+					Module:  governance.ModuleName,
+					Changes: cbor.Marshal(&governance.ConsensusParameterChanges{}),
+				},
+			},
+			func() {},
+			governance.ErrInvalidParameterChanges,
+		},
+		{
+			"should fail change parameters proposal with invalid changes",
+			baseConsParams,
+			pk1,
+			&governance.ProposalContent{
+				ChangeParameters: &governance.ChangeParametersProposal{
+					Module:  governance.ModuleName,
+					Changes: cbor.Marshal(&governance.ConsensusParameterChanges{VotingPeriod: &invalidVotingPeriod}),
+				},
+			},
+			func() {},
+			governance.ErrInvalidParameterChanges,
+		},
+		{
+			"should work with valid change parameters proposal",
+			baseConsParams,
+			pk1,
+			&governance.ProposalContent{
+				ChangeParameters: &governance.ChangeParametersProposal{
+					Module:  governance.ModuleName,
+					Changes: cbor.Marshal(&governance.ConsensusParameterChanges{VotingPeriod: &votingPeriod}),
+				},
+			},
+			func() {},
+			nil,
+		},
 	} {
 		err = state.SetConsensusParameters(ctx, tc.params)
 		require.NoError(err, "setting governance consensus parameters should not error")
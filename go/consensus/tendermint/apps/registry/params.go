@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+// changeParameters validates and optionally applies consensus parameter changes.
+func (app *registryApplication) changeParameters(ctx *api.Context, msg interface{}, apply bool) error {
+	proposal, ok := msg.(*governance.ChangeParametersProposal)
+	if !ok {
+		return fmt.Errorf("registry: failed to type assert change parameters proposal")
+	}
+	if proposal.Module != registry.ModuleName {
+		return fmt.Errorf("registry: wrong module for parameter changes: %s", proposal.Module)
+	}
+
+	// Validate changes against the current parameters.
+	var changes registry.ConsensusParameterChanges
+	if err := cbor.Unmarshal(proposal.Changes, &changes); err != nil {
+		return fmt.Errorf("registry: failed to unmarshal consensus parameter changes: %w", err)
+	}
+	if err := changes.SanityCheck(); err != nil {
+		return fmt.Errorf("registry: failed to validate consensus parameter changes: %w", err)
+	}
+
+	state := registryState.NewMutableState(ctx.State())
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("registry: failed to load consensus parameters: %w", err)
+	}
+	if err = changes.Apply(params); err != nil {
+		return fmt.Errorf("registry: failed to apply consensus parameter changes: %w", err)
+	}
+	if err = params.SanityCheck(); err != nil {
+		return fmt.Errorf("registry: failed to validate consensus parameters: %w", err)
+	}
+
+	if !apply {
+		return nil
+	}
+
+	// Apply changes.
+	if err = state.SetConsensusParameters(ctx, params); err != nil {
+		return fmt.Errorf("registry: failed to update consensus parameters: %w", err)
+	}
+	return nil
+}
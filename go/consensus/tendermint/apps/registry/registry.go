@@ -55,6 +55,8 @@ func (app *registryApplication) OnRegister(state api.ApplicationState, md api.Me
 
 	// Subscribe to messages emitted by other apps.
 	md.Subscribe(roothashApi.RuntimeMessageRegistry, app)
+	md.Subscribe(api.MessageValidateParameterChanges(registry.ModuleName), app)
+	md.Subscribe(api.MessageChangeParameters(registry.ModuleName), app)
 }
 
 func (app *registryApplication) OnCleanup() {
@@ -80,6 +82,10 @@ func (app *registryApplication) ExecuteMessage(ctx *api.Context, kind, msg inter
 		default:
 			return registry.ErrInvalidArgument
 		}
+	case api.MessageValidateParameterChanges(registry.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case api.MessageChangeParameters(registry.ModuleName):
+		return app.changeParameters(ctx, msg, true)
 	default:
 		return registry.ErrInvalidArgument
 	}
@@ -219,6 +225,11 @@ func (app *registryApplication) onRegistryEpochChanged(ctx *api.Context, registr
 		}
 	}
 
+	// Activate any scheduled runtime versions.
+	if err = app.promoteScheduledVersions(ctx, state, registryEpoch); err != nil {
+		return fmt.Errorf("registry: onRegistryEpochChanged: failed to promote scheduled versions: %w", err)
+	}
+
 	// Emit the RegistryNodeListEpoch notification event.
 	evb := api.NewEventBuilder(app.Name())
 	// (Dummy value, should be ignored.)
@@ -235,6 +246,54 @@ func (app *registryApplication) onRegistryEpochChanged(ctx *api.Context, registr
 	return nil
 }
 
+// promoteScheduledVersions replaces the current version of all runtimes with their scheduled
+// version in case the scheduled version becomes active at the given epoch.
+func (app *registryApplication) promoteScheduledVersions(
+	ctx *api.Context,
+	state *registryState.MutableState,
+	epoch beacon.EpochTime,
+) error {
+	runtimes, err := state.Runtimes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get runtimes: %w", err)
+	}
+	suspendedRuntimes, err := state.SuspendedRuntimes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get suspended runtimes: %w", err)
+	}
+
+	promote := func(rt *registry.Runtime, suspended bool) error {
+		if !rt.PromoteScheduledVersion(epoch) {
+			return nil
+		}
+
+		ctx.Logger().Info("activating scheduled runtime version",
+			"runtime_id", rt.ID,
+			"version", rt.Version.Version,
+			"epoch", epoch,
+		)
+
+		if err := state.SetRuntime(ctx, rt, suspended); err != nil {
+			return fmt.Errorf("failed to set runtime %s: %w", rt.ID, err)
+		}
+		if !suspended {
+			ctx.EmitEvent(api.NewEventBuilder(app.Name()).Attribute(KeyRuntimeRegistered, cbor.Marshal(rt)))
+		}
+		return nil
+	}
+	for _, rt := range runtimes {
+		if err = promote(rt, false); err != nil {
+			return err
+		}
+	}
+	for _, rt := range suspendedRuntimes {
+		if err = promote(rt, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // New constructs a new registry application instance.
 func New() api.Application {
 	return &registryApplication{}
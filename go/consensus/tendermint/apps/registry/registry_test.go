@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	requirePkg "github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+func TestPromoteScheduledVersions(t *testing.T) {
+	require := requirePkg.New(t)
+
+	now := time.Unix(1580461674, 0)
+	cfg := abciAPI.MockApplicationStateConfig{}
+	appState := abciAPI.NewMockApplicationState(&cfg)
+	ctx := appState.NewContext(abciAPI.ContextBeginBlock, now)
+	defer ctx.Close()
+
+	var md abciAPI.NoopMessageDispatcher
+	app := registryApplication{appState, &md}
+	state := registryState.NewMutableState(ctx.State())
+
+	scheduled := &registry.ScheduledVersionInfo{
+		VersionInfo: registry.VersionInfo{Version: version.Version{Major: 2}},
+		Epoch:       5,
+	}
+	rt := &registry.Runtime{
+		ID:               common.NewTestNamespaceFromSeed([]byte("runtime"), 0),
+		Version:          registry.VersionInfo{Version: version.Version{Major: 1}},
+		ScheduledVersion: scheduled,
+	}
+	suspendedRt := &registry.Runtime{
+		ID:               common.NewTestNamespaceFromSeed([]byte("suspended runtime"), 0),
+		Version:          registry.VersionInfo{Version: version.Version{Major: 1}},
+		ScheduledVersion: scheduled,
+	}
+	err := state.SetRuntime(ctx, rt, false)
+	require.NoError(err, "SetRuntime")
+	err = state.SetRuntime(ctx, suspendedRt, true)
+	require.NoError(err, "SetRuntime")
+
+	// Nothing should change before the scheduled epoch.
+	err = app.promoteScheduledVersions(ctx, state, 4)
+	require.NoError(err, "promoteScheduledVersions")
+	stored, err := state.AnyRuntime(ctx, rt.ID)
+	require.NoError(err, "AnyRuntime")
+	require.EqualValues(1, stored.Version.Version.Major, "version should not change before the scheduled epoch")
+	require.NotNil(stored.ScheduledVersion, "scheduled version should remain before the scheduled epoch")
+
+	// Both runtimes should be updated at the scheduled epoch.
+	err = app.promoteScheduledVersions(ctx, state, 5)
+	require.NoError(err, "promoteScheduledVersions")
+	stored, err = state.Runtime(ctx, rt.ID)
+	require.NoError(err, "Runtime")
+	require.EqualValues(2, stored.Version.Version.Major, "scheduled version should be promoted")
+	require.Nil(stored.ScheduledVersion, "scheduled version should be cleared")
+
+	stored, err = state.SuspendedRuntime(ctx, suspendedRt.ID)
+	require.NoError(err, "SuspendedRuntime")
+	require.EqualValues(2, stored.Version.Version.Major, "scheduled version of suspended runtime should be promoted")
+	require.Nil(stored.ScheduledVersion, "scheduled version should be cleared")
+}
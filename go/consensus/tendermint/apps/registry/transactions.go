@@ -1,9 +1,11 @@
 package registry
 
 import (
+	"bytes"
 	"fmt"
 
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/entity"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
@@ -358,6 +360,15 @@ func (app *registryApplication) registerNode( // nolint: gocyclo
 		}
 	}
 	feeCount := len(paidRuntimes) * int(additionalEpochs)
+	if params.EnableRuntimeVersionScheduling {
+		// The same runtime may be present multiple times in case a version change is scheduled,
+		// but the maintenance fee is only paid once per runtime.
+		uniqueRuntimes := make(map[common.Namespace]bool)
+		for _, rt := range paidRuntimes {
+			uniqueRuntimes[rt.ID] = true
+		}
+		feeCount = len(uniqueRuntimes) * int(additionalEpochs)
+	}
 	if err = ctx.Gas().UseGas(feeCount, registry.GasOpRuntimeEpochMaintenance, params.GasCosts); err != nil {
 		return err
 	}
@@ -393,7 +404,7 @@ func (app *registryApplication) registerNode( // nolint: gocyclo
 
 	// If the node already exists make sure to verify the node update.
 	if existingNode != nil {
-		if err = registry.VerifyNodeUpdate(ctx.Logger(), existingNode, newNode, epoch); err != nil {
+		if err = registry.VerifyNodeUpdate(ctx.Logger(), params, existingNode, newNode, epoch); err != nil {
 			ctx.Logger().Error("RegisterNode: failed to verify node update",
 				"err", err,
 				"new_node", newNode,
@@ -674,6 +685,24 @@ func (app *registryApplication) registerRuntime( // nolint: gocyclo
 		}
 	}
 
+	// If a version change is being (re)scheduled, make sure that it is scheduled for a future
+	// epoch so that nodes have time to provision the new version.
+	if sv := rt.ScheduledVersion; sv != nil && !ctx.IsInitChain() {
+		if existingRt == nil || !bytes.Equal(cbor.Marshal(existingRt.ScheduledVersion), cbor.Marshal(sv)) {
+			epoch, err := app.state.GetEpoch(ctx, ctx.BlockHeight()+1)
+			if err != nil {
+				return err
+			}
+			if sv.Epoch <= epoch {
+				ctx.Logger().Error("RegisterRuntime: scheduled version epoch is not in the future",
+					"scheduled_epoch", sv.Epoch,
+					"epoch", epoch,
+				)
+				return fmt.Errorf("%w: scheduled version epoch is not in the future", registry.ErrInvalidArgument)
+			}
+		}
+	}
+
 	if !ctx.IsInitChain() {
 		// Make sure the signer of the transaction matches the signer of the
 		// entity or runtime that is controlling the runtime.
@@ -0,0 +1,53 @@
+package roothash
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	tmapi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+)
+
+// changeParameters validates and optionally applies consensus parameter changes.
+func (app *rootHashApplication) changeParameters(ctx *tmapi.Context, msg interface{}, apply bool) error {
+	proposal, ok := msg.(*governance.ChangeParametersProposal)
+	if !ok {
+		return fmt.Errorf("roothash: failed to type assert change parameters proposal")
+	}
+	if proposal.Module != roothash.ModuleName {
+		return fmt.Errorf("roothash: wrong module for parameter changes: %s", proposal.Module)
+	}
+
+	// Validate changes against the current parameters.
+	var changes roothash.ConsensusParameterChanges
+	if err := cbor.Unmarshal(proposal.Changes, &changes); err != nil {
+		return fmt.Errorf("roothash: failed to unmarshal consensus parameter changes: %w", err)
+	}
+	if err := changes.SanityCheck(); err != nil {
+		return fmt.Errorf("roothash: failed to validate consensus parameter changes: %w", err)
+	}
+
+	state := roothashState.NewMutableState(ctx.State())
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("roothash: failed to load consensus parameters: %w", err)
+	}
+	if err = changes.Apply(params); err != nil {
+		return fmt.Errorf("roothash: failed to apply consensus parameter changes: %w", err)
+	}
+	if err = params.SanityCheck(); err != nil {
+		return fmt.Errorf("roothash: failed to validate consensus parameters: %w", err)
+	}
+
+	if !apply {
+		return nil
+	}
+
+	// Apply changes.
+	if err = state.SetConsensusParameters(ctx, params); err != nil {
+		return fmt.Errorf("roothash: failed to update consensus parameters: %w", err)
+	}
+	return nil
+}
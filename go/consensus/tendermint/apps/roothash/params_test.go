@@ -0,0 +1,58 @@
+package roothash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+)
+
+func TestChangeParameters(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{})
+	ctx := appState.NewContext(abciAPI.ContextEndBlock, now)
+	defer ctx.Close()
+
+	state := roothashState.NewMutableState(ctx.State())
+	params := &roothash.ConsensusParameters{
+		MaxRuntimeMessages: 32,
+		MaxEvidenceAge:     50,
+	}
+	err := state.SetConsensusParameters(ctx, params)
+	require.NoError(err, "SetConsensusParameters")
+
+	app := &rootHashApplication{state: appState}
+
+	newProposal := func(changes *roothash.ConsensusParameterChanges) *governance.ChangeParametersProposal {
+		return &governance.ChangeParametersProposal{
+			Module:  roothash.ModuleName,
+			Changes: cbor.Marshal(changes),
+		}
+	}
+
+	// Changes resulting in invalid parameters should be rejected.
+	var zero uint32
+	err = app.changeParameters(ctx, newProposal(&roothash.ConsensusParameterChanges{MaxRuntimeMessages: &zero}), true)
+	require.Error(err, "changing max runtime messages to zero should fail")
+
+	current, err := state.ConsensusParameters(ctx)
+	require.NoError(err, "ConsensusParameters")
+	require.EqualValues(params, current, "rejected changes should not be applied")
+
+	// Valid changes should be applied.
+	maxRuntimeMessages := uint32(64)
+	err = app.changeParameters(ctx, newProposal(&roothash.ConsensusParameterChanges{MaxRuntimeMessages: &maxRuntimeMessages}), true)
+	require.NoError(err, "changeParameters")
+
+	current, err = state.ConsensusParameters(ctx)
+	require.NoError(err, "ConsensusParameters")
+	require.EqualValues(64, current.MaxRuntimeMessages, "changes should be applied")
+}
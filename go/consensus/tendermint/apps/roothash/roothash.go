@@ -66,6 +66,8 @@ func (app *rootHashApplication) OnRegister(state tmapi.ApplicationState, md tmap
 	md.Subscribe(registryApi.MessageRuntimeUpdated, app)
 	md.Subscribe(registryApi.MessageRuntimeResumed, app)
 	md.Subscribe(roothashApi.RuntimeMessageNoop, app)
+	md.Subscribe(tmapi.MessageValidateParameterChanges(roothash.ModuleName), app)
+	md.Subscribe(tmapi.MessageChangeParameters(roothash.ModuleName), app)
 }
 
 func (app *rootHashApplication) OnCleanup() {
@@ -138,7 +140,7 @@ func (app *rootHashApplication) onCommitteeChanged(ctx *tmapi.Context, state *ro
 		rtState.Suspended = false
 
 		// Prepare new runtime committees based on what the scheduler did.
-		executorPool, empty, err := app.prepareNewCommittees(ctx, epoch, rtState, schedState, regState)
+		executorPool, empty, err := app.prepareNewCommittees(ctx, epoch, rt, rtState, schedState, regState)
 		if err != nil {
 			return err
 		}
@@ -239,6 +241,7 @@ func (app *rootHashApplication) suspendUnpaidRuntime(
 func (app *rootHashApplication) prepareNewCommittees(
 	ctx *tmapi.Context,
 	epoch beacon.EpochTime,
+	rt *registry.Runtime,
 	rtState *roothash.RuntimeState,
 	schedState *schedulerState.MutableState,
 	regState *registryState.MutableState,
@@ -268,6 +271,20 @@ func (app *rootHashApplication) prepareNewCommittees(
 			Runtime:   rtState.Runtime,
 			Committee: executorCommittee,
 		}
+
+		// Commitments need to be verified against the same runtime version that the committee was
+		// elected for.
+		var regParams *registry.ConsensusParameters
+		regParams, err = regState.ConsensusParameters(ctx)
+		if err != nil {
+			ctx.Logger().Error("checkCommittees: failed to get registry consensus parameters",
+				"err", err,
+			)
+			return
+		}
+		if regParams.EnableRuntimeVersionScheduling {
+			executorPool.ActiveVersion = &rt.ActiveVersion(epoch).Version
+		}
 	}
 	return
 }
@@ -331,6 +348,10 @@ func (app *rootHashApplication) ExecuteMessage(ctx *tmapi.Context, kind, msg int
 	case roothashApi.RuntimeMessageNoop:
 		// Noop message always succeeds.
 		return nil
+	case tmapi.MessageValidateParameterChanges(roothash.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case tmapi.MessageChangeParameters(roothash.ModuleName):
+		return app.changeParameters(ctx, msg, true)
 	default:
 		return roothash.ErrInvalidArgument
 	}
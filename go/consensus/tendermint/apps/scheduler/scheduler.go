@@ -69,6 +69,10 @@ func (app *schedulerApplication) Dependencies() []string {
 
 func (app *schedulerApplication) OnRegister(state api.ApplicationState, md api.MessageDispatcher) {
 	app.state = state
+
+	// Subscribe to messages emitted by other apps.
+	md.Subscribe(api.MessageValidateParameterChanges(scheduler.ModuleName), app)
+	md.Subscribe(api.MessageChangeParameters(scheduler.ModuleName), app)
 }
 
 func (app *schedulerApplication) OnCleanup() {}
@@ -118,6 +122,10 @@ func (app *schedulerApplication) BeginBlock(ctx *api.Context, request types.Requ
 		filterCommitteeNodes := beaconParameters.Backend == beacon.BackendVRF && !params.DebugAllowWeakAlpha
 
 		regState := registryState.NewMutableState(ctx.State())
+		registryParameters, err := regState.ConsensusParameters(ctx)
+		if err != nil {
+			return fmt.Errorf("tendermint/scheduler: couldn't get registry consensus parameters: %w", err)
+		}
 		runtimes, err := regState.Runtimes(ctx)
 		if err != nil {
 			return fmt.Errorf("tendermint/scheduler: couldn't get runtimes: %w", err)
@@ -195,6 +203,7 @@ func (app *schedulerApplication) BeginBlock(ctx *api.Context, request types.Requ
 				params,
 				beaconState,
 				beaconParameters,
+				registryParameters,
 				stakeAcc,
 				entitiesEligibleForReward,
 				validatorEntities,
@@ -233,7 +242,14 @@ func (app *schedulerApplication) BeginBlock(ctx *api.Context, request types.Requ
 }
 
 func (app *schedulerApplication) ExecuteMessage(ctx *api.Context, kind, msg interface{}) error {
-	return fmt.Errorf("scheduler: unexpected message")
+	switch kind {
+	case api.MessageValidateParameterChanges(scheduler.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case api.MessageChangeParameters(scheduler.ModuleName):
+		return app.changeParameters(ctx, msg, true)
+	default:
+		return fmt.Errorf("scheduler: unexpected message")
+	}
 }
 
 func (app *schedulerApplication) ExecuteTx(ctx *api.Context, tx *transaction.Transaction) error {
@@ -308,38 +324,45 @@ func (app *schedulerApplication) EndBlock(ctx *api.Context, req types.RequestEnd
 	return resp, nil
 }
 
-func (app *schedulerApplication) isSuitableExecutorWorker(ctx *api.Context, n *node.Node, rt *registry.Runtime) bool {
+func (app *schedulerApplication) isSuitableExecutorWorker(
+	ctx *api.Context,
+	n *node.Node,
+	rt *registry.Runtime,
+	activeVersion *registry.VersionInfo,
+) bool {
 	if !n.HasRoles(node.RoleComputeWorker) {
 		return false
 	}
+
+	// Only nodes that registered for the active runtime version are suitable.
 	for _, nrt := range n.Runtimes {
 		if !nrt.ID.Equal(&rt.ID) {
 			continue
 		}
-		if nrt.Version.MaskNonMajor() != rt.Version.Version.MaskNonMajor() {
-			return false
+		if nrt.Version.MaskNonMajor() != activeVersion.Version.MaskNonMajor() {
+			continue
 		}
 		switch rt.TEEHardware {
 		case node.TEEHardwareInvalid:
 			if nrt.Capabilities.TEE != nil {
-				return false
+				continue
 			}
 			return true
 		default:
 			if nrt.Capabilities.TEE == nil {
-				return false
+				continue
 			}
 			if nrt.Capabilities.TEE.Hardware != rt.TEEHardware {
-				return false
+				continue
 			}
-			if err := nrt.Capabilities.TEE.Verify(ctx.Now(), rt.Version.TEE); err != nil {
+			if err := nrt.Capabilities.TEE.Verify(ctx.Now(), activeVersion.TEE); err != nil {
 				ctx.Logger().Warn("failed to verify node TEE attestaion",
 					"err", err,
 					"node", n,
 					"time_stamp", ctx.Now(),
 					"runtime", rt.ID,
 				)
-				return false
+				continue
 			}
 			return true
 		}
@@ -364,6 +387,7 @@ func (app *schedulerApplication) electAllCommittees(
 	schedulerParameters *scheduler.ConsensusParameters,
 	beaconState *beaconState.MutableState,
 	beaconParameters *beacon.ConsensusParameters,
+	registryParameters *registry.ConsensusParameters,
 	stakeAcc *stakingState.StakeAccumulatorCache,
 	entitiesEligibleForReward map[staking.Address]bool,
 	validatorEntities map[staking.Address]bool,
@@ -378,6 +402,7 @@ func (app *schedulerApplication) electAllCommittees(
 			schedulerParameters,
 			beaconState,
 			beaconParameters,
+			registryParameters,
 			stakeAcc,
 			entitiesEligibleForReward,
 			validatorEntities,
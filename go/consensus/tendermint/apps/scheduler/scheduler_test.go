@@ -530,6 +530,7 @@ func TestElectCommittee(t *testing.T) {
 			schedulerParameters,
 			beaconState,
 			beaconParameters,
+			&registry.ConsensusParameters{},
 			nil,
 			nil,
 			tc.validatorEntities,
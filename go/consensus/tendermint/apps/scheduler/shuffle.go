@@ -147,6 +147,7 @@ func (app *schedulerApplication) electCommittee( //nolint: gocyclo
 	schedulerParameters *scheduler.ConsensusParameters,
 	beaconState *beaconState.MutableState,
 	beaconParameters *beacon.ConsensusParameters,
+	registryParameters *registry.ConsensusParameters,
 	stakeAcc *stakingState.StakeAccumulatorCache,
 	entitiesEligibleForReward map[staking.Address]bool,
 	validatorEntities map[staking.Address]bool,
@@ -194,7 +195,13 @@ func (app *schedulerApplication) electCommittee( //nolint: gocyclo
 	// Determine the committee size, and pre-filter the node-list based
 	// on eligibility, entity stake and other criteria.
 
-	var isSuitableFn func(*api.Context, *node.Node, *registry.Runtime) bool
+	// Determine the runtime version that the nodes need to be registered for.
+	activeVersion := &rt.Version
+	if registryParameters.EnableRuntimeVersionScheduling {
+		activeVersion = rt.ActiveVersion(epoch)
+	}
+
+	var isSuitableFn func(*api.Context, *node.Node, *registry.Runtime, *registry.VersionInfo) bool
 	groupSizes := make(map[scheduler.Role]int)
 	switch kind {
 	case scheduler.KindComputeExecutor:
@@ -231,7 +238,7 @@ func (app *schedulerApplication) electCommittee( //nolint: gocyclo
 			}
 		}
 		// Check general node compatibility.
-		if !isSuitableFn(ctx, n, rt) {
+		if !isSuitableFn(ctx, n, rt, activeVersion) {
 			continue
 		}
 
@@ -49,6 +49,8 @@ func (app *stakingApplication) OnRegister(state api.ApplicationState, md api.Mes
 
 	// Subscribe to messages emitted by other apps.
 	md.Subscribe(roothashApi.RuntimeMessageStaking, app)
+	md.Subscribe(api.MessageValidateParameterChanges(staking.ModuleName), app)
+	md.Subscribe(api.MessageChangeParameters(staking.ModuleName), app)
 }
 
 func (app *stakingApplication) OnCleanup() {
@@ -133,6 +135,10 @@ func (app *stakingApplication) ExecuteMessage(ctx *api.Context, kind, msg interf
 		default:
 			return staking.ErrInvalidArgument
 		}
+	case api.MessageValidateParameterChanges(staking.ModuleName):
+		return app.changeParameters(ctx, msg, false)
+	case api.MessageChangeParameters(staking.ModuleName):
+		return app.changeParameters(ctx, msg, true)
 	default:
 		return staking.ErrInvalidArgument
 	}
@@ -257,6 +263,7 @@ func (app *stakingApplication) onEpochChange(ctx *api.Context, epoch beacon.Epoc
 			)
 			return fmt.Errorf("staking/tendermint: failed to redeem debonding shares: %w", err)
 		}
+		delegator.General.ReturnEscrowedLocked(stakeAmount)
 
 		// Update state.
 		if err = state.RemoveFromDebondingQueue(ctx, e.Epoch, e.DelegatorAddr, e.EscrowAddr); err != nil {
@@ -24,14 +24,14 @@ type feeAccumulator struct {
 	balance quantity.Quantity
 }
 
-// AuthenticateAndPayFees authenticates the message signer and makes sure that
+// AuthenticateAndPayFees authenticates the account of the message caller and makes sure that
 // any gas fees are paid.
 //
 // This method transfers the fees to the per-block fee accumulator which is
 // persisted at the end of the block.
 func AuthenticateAndPayFees(
 	ctx *abciAPI.Context,
-	signer signature.PublicKey,
+	addr staking.Address,
 	nonce uint64,
 	fee *transaction.Fee,
 ) error {
@@ -45,8 +45,6 @@ func AuthenticateAndPayFees(
 		return nil
 	}
 
-	// Convert signer's public key to account address.
-	addr := staking.NewAddress(signer)
 	if addr.IsReserved() {
 		return fmt.Errorf("using reserved account address %s is prohibited", addr)
 	}
@@ -78,6 +76,9 @@ func AuthenticateAndPayFees(
 		if account.General.Balance.Cmp(&fee.Amount) < 0 {
 			return transaction.ErrInsufficientFeeBalance
 		}
+		if err = checkFeeSpendable(ctx, account, fee); err != nil {
+			return err
+		}
 
 		// Check fee against minimum gas price if in CheckTx. Always accept own transactions.
 		// NOTE: This is non-deterministic as it is derived from the local validator
@@ -92,6 +93,11 @@ func AuthenticateAndPayFees(
 		return nil
 	}
 
+	// Fees cannot be paid from funds that are still locked by the vesting schedule.
+	if err = checkFeeSpendable(ctx, account, fee); err != nil {
+		return err
+	}
+
 	// Transfer fee to per-block fee accumulator.
 	feeAcc := ctx.BlockContext().Get(feeAccumulatorKey{}).(*feeAccumulator)
 	if err := quantity.Move(&feeAcc.balance, &account.General.Balance, &fee.Amount); err != nil {
@@ -121,6 +127,25 @@ func AuthenticateAndPayFees(
 	return nil
 }
 
+// checkFeeSpendable checks that the fee can be paid without touching any funds locked by the
+// vesting schedule of the given account.
+func checkFeeSpendable(ctx *abciAPI.Context, account *staking.Account, fee *transaction.Fee) error {
+	if account.General.Vesting == nil || fee.Amount.IsZero() {
+		return nil
+	}
+
+	epoch, err := ctx.AppState().GetEpoch(ctx, ctx.BlockHeight()+1)
+	if err != nil {
+		return fmt.Errorf("failed to get current epoch: %w", err)
+	}
+	switch err = account.General.CheckSpendable(epoch, &fee.Amount); err {
+	case staking.ErrInsufficientBalance:
+		return transaction.ErrInsufficientFeeBalance
+	default:
+		return err
+	}
+}
+
 // BlockFees returns the accumulated fee balance for the current block.
 func BlockFees(ctx *abciAPI.Context) quantity.Quantity {
 	// Fetch accumulated fees in the current block.
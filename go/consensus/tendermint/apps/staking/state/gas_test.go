@@ -0,0 +1,70 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+func TestAuthenticateAndPayFeesVesting(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{
+		CurrentEpoch: 18,
+		MinGasPrice:  quantity.NewQuantity(),
+	})
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	s := NewMutableState(ctx.State())
+	pk := signature.NewPublicKey("aaafffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	addr := staking.NewAddress(pk)
+
+	// At epoch 18, 200 base units of the balance are still locked.
+	err := s.SetAccount(ctx, addr, &staking.Account{
+		General: staking.GeneralAccount{
+			Balance: *quantity.NewFromUint64(300),
+			Vesting: &staking.VestingSchedule{
+				Amount: *quantity.NewFromUint64(1000),
+				Start:  10,
+				Cliff:  15,
+				End:    20,
+			},
+		},
+	})
+	require.NoError(err, "SetAccount")
+
+	lockedFee := &transaction.Fee{Amount: *quantity.NewFromUint64(101)}
+	unlockedFee := &transaction.Fee{Amount: *quantity.NewFromUint64(100)}
+
+	checkCtx := appState.NewContext(abciAPI.ContextCheckTx, now)
+	defer checkCtx.Close()
+	err = AuthenticateAndPayFees(checkCtx, addr, 0, lockedFee)
+	require.Equal(staking.ErrBalanceLocked, err, "CheckTx paying fees from locked funds should fail")
+	err = AuthenticateAndPayFees(checkCtx, addr, 0, unlockedFee)
+	require.NoError(err, "CheckTx paying fees from unlocked funds should succeed")
+
+	err = AuthenticateAndPayFees(ctx, addr, 0, lockedFee)
+	require.Equal(staking.ErrBalanceLocked, err, "paying fees from locked funds should fail")
+	acct, err := s.Account(ctx, addr)
+	require.NoError(err, "Account")
+	require.EqualValues(0, acct.General.Nonce, "nonce should not be incremented")
+	require.Zero(acct.General.Balance.Cmp(quantity.NewFromUint64(300)), "balance should not change")
+
+	err = AuthenticateAndPayFees(ctx, addr, 0, unlockedFee)
+	require.NoError(err, "paying fees from unlocked funds should succeed")
+	acct, err = s.Account(ctx, addr)
+	require.NoError(err, "Account")
+	require.EqualValues(1, acct.General.Nonce, "nonce should be incremented")
+	require.Zero(acct.General.Balance.Cmp(quantity.NewFromUint64(200)), "fee should be paid")
+	fees := BlockFees(ctx)
+	require.Zero(fees.Cmp(quantity.NewFromUint64(100)), "fee should be accumulated")
+}
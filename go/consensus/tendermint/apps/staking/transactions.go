@@ -3,6 +3,7 @@ package staking
 import (
 	"fmt"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
@@ -20,6 +21,20 @@ func isTransferPermitted(params *staking.ConsensusParameters, fromAddr staking.A
 	return
 }
 
+// checkSpendable checks whether the given amount can be spent from the general balance of the
+// given account without touching any funds locked by its vesting schedule.
+func (app *stakingApplication) checkSpendable(ctx *api.Context, acct *staking.Account, amount *quantity.Quantity) error {
+	if acct.General.Vesting == nil {
+		return nil
+	}
+
+	epoch, err := app.state.GetEpoch(ctx, ctx.BlockHeight()+1)
+	if err != nil {
+		return err
+	}
+	return acct.General.CheckSpendable(epoch, amount)
+}
+
 func (app *stakingApplication) transfer(ctx *api.Context, state *stakingState.MutableState, xfer *staking.Transfer) error {
 	if ctx.IsCheckOnly() {
 		return nil
@@ -49,6 +64,16 @@ func (app *stakingApplication) transfer(ctx *api.Context, state *stakingState.Mu
 		return fmt.Errorf("failed to fetch account: %w", err)
 	}
 
+	if err = app.checkSpendable(ctx, from, &xfer.Amount); err != nil {
+		ctx.Logger().Error("Transfer: amount exceeds spendable balance",
+			"err", err,
+			"from", fromAddr,
+			"to", xfer.To,
+			"amount", xfer.Amount,
+		)
+		return err
+	}
+
 	if fromAddr.Equal(xfer.To) {
 		// Handle transfer to self as just a balance check.
 		if from.General.Balance.Cmp(&xfer.Amount) < 0 {
@@ -132,6 +157,15 @@ func (app *stakingApplication) burn(ctx *api.Context, state *stakingState.Mutabl
 		return fmt.Errorf("failed to fetch account: %w", err)
 	}
 
+	if err = app.checkSpendable(ctx, from, &burn.Amount); err != nil {
+		ctx.Logger().Error("Burn: amount exceeds spendable balance",
+			"err", err,
+			"from", fromAddr,
+			"amount", burn.Amount,
+		)
+		return err
+	}
+
 	if err = from.General.Balance.Sub(&burn.Amount); err != nil {
 		ctx.Logger().Error("Burn: failed to burn stake",
 			"err", err,
@@ -230,6 +264,17 @@ func (app *stakingApplication) addEscrow(ctx *api.Context, state *stakingState.M
 		return fmt.Errorf("failed to fetch delegation: %w", err)
 	}
 
+	// Locked funds can be escrowed, but need to be tracked so they don't lock other funds.
+	if from.General.Vesting != nil {
+		var epoch beacon.EpochTime
+		if epoch, err = app.state.GetEpoch(ctx, ctx.BlockHeight()+1); err != nil {
+			return err
+		}
+		if err = from.General.AddEscrowedLocked(epoch, &escrow.Amount); err != nil {
+			return fmt.Errorf("failed to track escrowed locked funds: %w", err)
+		}
+	}
+
 	obtainedShares, err := to.Escrow.Active.Deposit(&delegation.Shares, &from.General.Balance, &escrow.Amount)
 	if err != nil {
 		ctx.Logger().Error("AddEscrow: failed to escrow stake",
@@ -629,6 +674,10 @@ func (app *stakingApplication) withdraw(
 		return fmt.Errorf("failed to fetch account: %w", err)
 	}
 
+	if err = app.checkSpendable(ctx, from, &withdraw.Amount); err != nil {
+		return err
+	}
+
 	if err = quantity.Move(&to.General.Balance, &from.General.Balance, &withdraw.Amount); err != nil {
 		return staking.ErrInsufficientBalance
 	}
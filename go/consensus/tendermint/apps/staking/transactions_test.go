@@ -688,3 +688,96 @@ func TestAllowEscrowMessages(t *testing.T) {
 	err = app.reclaimEscrow(txCtx, stakeState, &staking.ReclaimEscrow{Account: addr1, Shares: *quantity.NewFromUint64(1)})
 	require.NoError(err, "reclaim escrow message should work")
 }
+
+func TestVesting(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{
+		CurrentEpoch: 18,
+	})
+	ctx := appState.NewContext(abciAPI.ContextEndBlock, now)
+	defer ctx.Close()
+
+	stakeState := stakingState.NewMutableState(ctx.State())
+
+	app := &stakingApplication{
+		state: appState,
+	}
+
+	pk1 := signature.NewPublicKey("aaafffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	addr1 := staking.NewAddress(pk1)
+	pk2 := signature.NewPublicKey("bbbfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	addr2 := staking.NewAddress(pk2)
+
+	err = stakeState.SetConsensusParameters(ctx, &staking.ConsensusParameters{
+		MaxAllowances: 1,
+	})
+	require.NoError(err, "setting staking consensus parameters should not error")
+
+	// At epoch 18, 200 base units of addr1's balance are still locked.
+	err = stakeState.SetAccount(ctx, addr1, &staking.Account{
+		General: staking.GeneralAccount{
+			Balance: *quantity.NewFromUint64(1000),
+			Allowances: map[staking.Address]quantity.Quantity{
+				addr2: *quantity.NewFromUint64(1000),
+			},
+			Vesting: &staking.VestingSchedule{
+				Amount: *quantity.NewFromUint64(1000),
+				Start:  10,
+				Cliff:  15,
+				End:    20,
+			},
+		},
+	})
+	require.NoError(err, "SetAccount")
+
+	newTxCtx := func(signer signature.PublicKey) *abciAPI.Context {
+		txCtx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+		txCtx.SetTxSigner(signer)
+		return txCtx
+	}
+
+	txCtx := newTxCtx(pk1)
+	defer txCtx.Close()
+	err = app.transfer(txCtx, stakeState, &staking.Transfer{To: addr2, Amount: *quantity.NewFromUint64(801)})
+	require.Equal(staking.ErrBalanceLocked, err, "transfer of locked funds should fail")
+	err = app.transfer(txCtx, stakeState, &staking.Transfer{To: addr1, Amount: *quantity.NewFromUint64(801)})
+	require.Equal(staking.ErrBalanceLocked, err, "self-transfer of locked funds should fail")
+	err = app.burn(txCtx, stakeState, &staking.Burn{Amount: *quantity.NewFromUint64(801)})
+	require.Equal(staking.ErrBalanceLocked, err, "burn of locked funds should fail")
+
+	err = app.transfer(txCtx, stakeState, &staking.Transfer{To: addr2, Amount: *quantity.NewFromUint64(500)})
+	require.NoError(err, "transfer of unlocked funds should succeed")
+
+	txCtx = newTxCtx(pk2)
+	defer txCtx.Close()
+	err = app.withdraw(txCtx, stakeState, &staking.Withdraw{From: addr1, Amount: *quantity.NewFromUint64(301)})
+	require.Equal(staking.ErrBalanceLocked, err, "withdraw of locked funds should fail")
+	err = app.withdraw(txCtx, stakeState, &staking.Withdraw{From: addr1, Amount: *quantity.NewFromUint64(300)})
+	require.NoError(err, "withdraw of unlocked funds should succeed")
+
+	// Locked funds can still be escrowed.
+	txCtx = newTxCtx(pk1)
+	defer txCtx.Close()
+	err = app.addEscrow(txCtx, stakeState, &staking.Escrow{Account: addr2, Amount: *quantity.NewFromUint64(200)})
+	require.NoError(err, "escrow of locked funds should succeed")
+
+	acct, err := stakeState.Account(ctx, addr1)
+	require.NoError(err, "Account")
+	require.True(acct.General.Balance.IsZero(), "general balance should be fully spent")
+	require.NotNil(acct.General.Vesting, "vesting schedule should be preserved")
+	require.Zero(acct.General.Vesting.Escrowed.Cmp(quantity.NewFromUint64(200)), "escrowed locked funds should be tracked")
+
+	// Funds received after escrowing locked funds should not be locked.
+	txCtx = newTxCtx(pk2)
+	defer txCtx.Close()
+	err = app.transfer(txCtx, stakeState, &staking.Transfer{To: addr1, Amount: *quantity.NewFromUint64(100)})
+	require.NoError(err, "transfer should succeed")
+
+	txCtx = newTxCtx(pk1)
+	defer txCtx.Close()
+	err = app.transfer(txCtx, stakeState, &staking.Transfer{To: addr2, Amount: *quantity.NewFromUint64(100)})
+	require.NoError(err, "transfer of received funds should succeed")
+}
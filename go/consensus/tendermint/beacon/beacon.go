@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/eapache/channels"
 	tmabcitypes "github.com/tendermint/tendermint/abci/types"
@@ -145,6 +146,165 @@ func (sc *serviceClient) GetEpochBlock(ctx context.Context, epoch beaconAPI.Epoc
 	}
 }
 
+func (sc *serviceClient) GetEpochSchedule(ctx context.Context, query *beaconAPI.EpochScheduleQuery) (*beaconAPI.EpochSchedule, error) {
+	if query.Count > beaconAPI.MaxEpochScheduleCount {
+		return nil, fmt.Errorf("%w: count must be at most %d", beaconAPI.ErrInvalidArgument, beaconAPI.MaxEpochScheduleCount)
+	}
+
+	q, err := sc.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+	params, err := q.ConsensusParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	epoch, epochHeight, err := q.Epoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	future, err := q.FutureEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timing, err := q.EpochTiming(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blk, err := sc.backend.GetBlock(ctx, query.Height)
+	if err != nil {
+		return nil, fmt.Errorf("beacon: failed to get block: %w", err)
+	}
+	blockTime, err := sc.estimateBlockTime(ctx, blk, epochHeight)
+	if err != nil {
+		return nil, err
+	}
+	estimateTime := func(height int64) time.Time {
+		return blk.Time.Add(time.Duration(height-blk.Height) * blockTime)
+	}
+
+	schedule := &beaconAPI.EpochSchedule{
+		Current: beaconAPI.EpochScheduleEntry{
+			Epoch:  epoch,
+			Height: epochHeight,
+			Time:   estimateTime(epochHeight),
+		},
+	}
+
+	// Epoch transitions can't be predicted when the mock backend is in use.
+	if future == nil || query.Count == 0 {
+		return schedule, nil
+	}
+
+	var (
+		interval    int64
+		isTimeBased bool
+	)
+	switch params.Backend {
+	case beaconAPI.BackendInsecure:
+		if params.InsecureParameters == nil {
+			return nil, fmt.Errorf("beacon: missing insecure backend parameters")
+		}
+		interval = params.InsecureParameters.Interval
+	case beaconAPI.BackendVRF:
+		if params.VRFParameters == nil {
+			return nil, fmt.Errorf("beacon: missing VRF backend parameters")
+		}
+		interval = params.VRFParameters.Interval
+		isTimeBased = params.VRFParameters.IsTimeBased()
+	default:
+		return nil, fmt.Errorf("beacon: unsupported backend: '%s'", params.Backend)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("beacon: invalid epoch interval: %d", interval)
+	}
+
+	// Track the interval and the start time of the current epoch so that the
+	// interval adjustments can be replicated for time-based epochs.
+	curInterval, curStart := future.Height-epochHeight, schedule.Current.Time.Unix()
+	if timing != nil {
+		curInterval, curStart = timing.Interval, timing.Time
+	}
+
+	next := beaconAPI.EpochScheduleEntry{
+		Epoch:  future.Epoch,
+		Height: future.Height,
+		Time:   estimateTime(future.Height),
+	}
+	for {
+		schedule.Upcoming = append(schedule.Upcoming, next)
+		if uint64(len(schedule.Upcoming)) >= query.Count {
+			break
+		}
+
+		var nextHeight int64
+		switch isTimeBased {
+		case true:
+			elapsed := time.Duration(next.Time.Unix()-curStart) * time.Second
+			curInterval = params.VRFParameters.NextInterval(curInterval, elapsed)
+			curStart = next.Time.Unix()
+			nextHeight = next.Height + curInterval
+		case false:
+			// Epoch transitions are aligned to the interval unless that would
+			// not advance the epoch (e.g., after time-based epochs have been
+			// disabled).
+			nextHeight = int64(next.Epoch+1) * interval
+			if nextHeight <= next.Height {
+				nextHeight = next.Height + interval
+			}
+		}
+		next = beaconAPI.EpochScheduleEntry{
+			Epoch:  next.Epoch + 1,
+			Height: nextHeight,
+			Time:   estimateTime(nextHeight),
+		}
+	}
+
+	return schedule, nil
+}
+
+// estimateBlockTime estimates the average block time based on the blocks in
+// the current epoch (or the blocks preceding it, if the epoch has just
+// started). In case no earlier blocks are available (e.g., at genesis or when
+// they have been pruned), the configured commit timeout is used instead.
+func (sc *serviceClient) estimateBlockTime(ctx context.Context, blk *consensus.Block, epochHeight int64) (time.Duration, error) {
+	status, err := sc.backend.GetStatus(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beacon: failed to get consensus status: %w", err)
+	}
+
+	refHeight := epochHeight
+	if refHeight >= blk.Height {
+		refHeight = blk.Height - 1
+	}
+	if refHeight < status.LastRetainedHeight {
+		refHeight = status.LastRetainedHeight
+	}
+	if refHeight > 0 && refHeight < blk.Height {
+		var refBlk *consensus.Block
+		refBlk, err = sc.backend.GetBlock(ctx, refHeight)
+		switch err {
+		case nil:
+			if blockTime := blk.Time.Sub(refBlk.Time) / time.Duration(blk.Height-refBlk.Height); blockTime > 0 {
+				return blockTime, nil
+			}
+		default:
+			// The reference block could have been pruned in the meantime.
+			sc.logger.Debug("failed to get reference block for block time estimation",
+				"err", err,
+				"height", refHeight,
+			)
+		}
+	}
+
+	doc, err := sc.backend.GetGenesisDocument(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beacon: failed to get genesis document: %w", err)
+	}
+	return doc.Consensus.Parameters.TimeoutCommit, nil
+}
+
 func (sc *serviceClient) WaitEpoch(ctx context.Context, epoch beaconAPI.EpochTime) error {
 	ch, sub, err := sc.WatchEpochs(ctx)
 	if err != nil {
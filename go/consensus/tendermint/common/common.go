@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 )
 
 const (
@@ -40,6 +41,25 @@ const (
 	CfgSubmissionGasPrice = "consensus.tendermint.submission.gas_price"
 	// CfgSubmissionMaxFee configures the maximum fee that can be set.
 	CfgSubmissionMaxFee = "consensus.tendermint.submission.max_fee"
+	// CfgSubmissionPriceDiscovery configures the gas price discovery mechanism used when
+	// submitting transactions.
+	CfgSubmissionPriceDiscovery = "consensus.tendermint.submission.price_discovery.mechanism"
+	// CfgSubmissionPriceDiscoveryWindow configures the number of recent blocks sampled by the
+	// dynamic price discovery mechanism.
+	CfgSubmissionPriceDiscoveryWindow = "consensus.tendermint.submission.price_discovery.window"
+	// CfgSubmissionPriceDiscoveryPercentile configures the percentile of sampled gas prices used
+	// by the dynamic price discovery mechanism.
+	CfgSubmissionPriceDiscoveryPercentile = "consensus.tendermint.submission.price_discovery.percentile"
+	// CfgSubmissionPriceDiscoveryMempool configures whether the dynamic price discovery mechanism
+	// also samples transactions in the local mempool.
+	CfgSubmissionPriceDiscoveryMempool = "consensus.tendermint.submission.price_discovery.mempool"
+	// CfgSubmissionFeeBumpStuckBlocks configures the number of blocks after which a submitted
+	// transaction is considered stuck and is resubmitted with a higher fee.
+	CfgSubmissionFeeBumpStuckBlocks = "consensus.tendermint.submission.fee_bump.stuck_blocks"
+	// CfgSubmissionFeeBumpPercent configures the percentage by which the fee is bumped.
+	CfgSubmissionFeeBumpPercent = "consensus.tendermint.submission.fee_bump.percent"
+	// CfgSubmissionFeeBumpMaxBumps configures the maximum number of fee bumps.
+	CfgSubmissionFeeBumpMaxBumps = "consensus.tendermint.submission.fee_bump.max_bumps"
 
 	// CfgP2PSeed configures tendermint's seed node(s).
 	CfgP2PSeed = "consensus.tendermint.p2p.seed"
@@ -128,6 +148,13 @@ func init() {
 
 	Flags.Uint64(CfgSubmissionGasPrice, 0, "gas price used when submitting consensus transactions")
 	Flags.Uint64(CfgSubmissionMaxFee, 0, "maximum transaction fee when submitting consensus transactions")
+	Flags.String(CfgSubmissionPriceDiscovery, consensus.PriceDiscoveryStatic, "gas price discovery mechanism (static, dynamic)")
+	Flags.Uint64(CfgSubmissionPriceDiscoveryWindow, 10, "number of recent blocks sampled by dynamic gas price discovery")
+	Flags.Uint8(CfgSubmissionPriceDiscoveryPercentile, 50, "percentile of sampled gas prices used by dynamic gas price discovery")
+	Flags.Bool(CfgSubmissionPriceDiscoveryMempool, true, "sample local mempool transactions in dynamic gas price discovery")
+	Flags.Uint64(CfgSubmissionFeeBumpStuckBlocks, 0, "number of blocks after which a stuck transaction is resubmitted with a higher fee (0 disables)")
+	Flags.Uint64(CfgSubmissionFeeBumpPercent, 10, "percentage by which the fee of a stuck transaction is bumped")
+	Flags.Uint64(CfgSubmissionFeeBumpMaxBumps, 5, "maximum number of fee bumps for a stuck transaction")
 
 	Flags.Bool(CfgLogDebug, false, "enable tendermint debug logs (very verbose)")
 
@@ -81,8 +81,13 @@ const (
 	CfgABCIPruneStrategy = "consensus.tendermint.abci.prune.strategy"
 	// CfgABCIPruneNumKept configures the amount of kept heights if pruning is enabled.
 	CfgABCIPruneNumKept = "consensus.tendermint.abci.prune.num_kept"
+	// CfgABCIPruneNumKeptEpochs configures the amount of kept epochs if pruning is enabled.
+	CfgABCIPruneNumKeptEpochs = "consensus.tendermint.abci.prune.num_kept_epochs"
 	// CfgABCIPruneInterval configures the ABCI state pruning interval.
 	CfgABCIPruneInterval = "consensus.tendermint.abci.prune.interval"
+	// CfgABCIPruneBatchSize configures the maximum number of ABCI state versions pruned per
+	// pruning interval.
+	CfgABCIPruneBatchSize = "consensus.tendermint.abci.prune.batch_size"
 
 	// CfgCheckpointerDisabled disables the ABCI state checkpointer.
 	CfgCheckpointerDisabled = "consensus.tendermint.checkpointer.disabled"
@@ -325,6 +330,11 @@ func (t *fullService) Checkpointer() checkpoint.Checkpointer {
 	return t.mux.State().Checkpointer()
 }
 
+func (t *fullService) PauseStatePruning(pause bool) error {
+	t.mux.Pruner().Pause(pause)
+	return nil
+}
+
 func (t *fullService) StateToGenesis(ctx context.Context, blockHeight int64) (*genesisAPI.Document, error) {
 	blk, err := t.GetTendermintBlock(ctx, blockHeight)
 	if err != nil {
@@ -445,8 +455,15 @@ func (t *fullService) RegisterHaltHook(hook consensusAPI.HaltHook) {
 }
 
 func (t *fullService) SubmitTx(ctx context.Context, tx *transaction.SignedTransaction) error {
+	return t.submitTxRaw(ctx, cbor.Marshal(tx))
+}
+
+func (t *fullService) SubmitMultiSignedTx(ctx context.Context, tx *transaction.MultiSignedTransaction) error {
+	return t.submitTxRaw(ctx, cbor.Marshal(tx))
+}
+
+func (t *fullService) submitTxRaw(ctx context.Context, data []byte) error {
 	// Subscribe to the transaction being included in a block.
-	data := cbor.Marshal(tx)
 	query := tmtypes.EventQueryTxFor(data)
 	subID := t.newSubscriberID()
 	txSub, err := t.subscribe(subID, query)
@@ -1145,7 +1162,9 @@ func (t *fullService) lazyInit() error {
 		return err
 	}
 	pruneCfg.NumKept = viper.GetUint64(CfgABCIPruneNumKept)
+	pruneCfg.NumKeptEpochs = viper.GetUint64(CfgABCIPruneNumKeptEpochs)
 	pruneCfg.PruneInterval = viper.GetDuration(CfgABCIPruneInterval)
+	pruneCfg.BatchSize = viper.GetUint64(CfgABCIPruneBatchSize)
 	const minPruneInterval = 1 * time.Second
 	if pruneCfg.PruneInterval < minPruneInterval {
 		pruneCfg.PruneInterval = minPruneInterval
@@ -1568,11 +1587,30 @@ func New(
 	t.Logger.Info("starting a full consensus node")
 
 	// Create the submission manager.
-	pd, err := consensusAPI.NewStaticPriceDiscovery(viper.GetUint64(tmcommon.CfgSubmissionGasPrice))
+	var pd consensusAPI.PriceDiscovery
+	switch mechanism := viper.GetString(tmcommon.CfgSubmissionPriceDiscovery); mechanism {
+	case consensusAPI.PriceDiscoveryStatic:
+		pd, err = consensusAPI.NewStaticPriceDiscovery(viper.GetUint64(tmcommon.CfgSubmissionGasPrice))
+	case consensusAPI.PriceDiscoveryDynamic:
+		pd, err = consensusAPI.NewDynamicPriceDiscovery(t, &consensusAPI.DynamicPriceDiscoveryConfig{
+			WindowSize:     viper.GetUint64(tmcommon.CfgSubmissionPriceDiscoveryWindow),
+			Percentile:     uint8(viper.GetUint(tmcommon.CfgSubmissionPriceDiscoveryPercentile)),
+			MinPrice:       viper.GetUint64(tmcommon.CfgSubmissionGasPrice),
+			IncludeMempool: viper.GetBool(tmcommon.CfgSubmissionPriceDiscoveryMempool),
+		})
+	default:
+		err = fmt.Errorf("unsupported price discovery mechanism: %s", mechanism)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("tendermint: failed to create submission manager: %w", err)
 	}
-	t.submissionMgr = consensusAPI.NewSubmissionManager(t, pd, viper.GetUint64(tmcommon.CfgSubmissionMaxFee))
+	t.submissionMgr = consensusAPI.NewSubmissionManager(t, pd, viper.GetUint64(tmcommon.CfgSubmissionMaxFee),
+		consensusAPI.WithFeeBump(&consensusAPI.FeeBumpConfig{
+			StuckBlocks: viper.GetUint64(tmcommon.CfgSubmissionFeeBumpStuckBlocks),
+			BumpPercent: viper.GetUint64(tmcommon.CfgSubmissionFeeBumpPercent),
+			MaxBumps:    viper.GetUint64(tmcommon.CfgSubmissionFeeBumpMaxBumps),
+		}),
+	)
 
 	return t, t.initialize()
 }
@@ -1580,7 +1618,9 @@ func New(
 func init() {
 	Flags.String(CfgABCIPruneStrategy, abci.PruneDefault, "ABCI state pruning strategy")
 	Flags.Uint64(CfgABCIPruneNumKept, 3600, "ABCI state versions kept (when applicable)")
+	Flags.Uint64(CfgABCIPruneNumKeptEpochs, 10, "ABCI state epochs kept (when applicable)")
 	Flags.Duration(CfgABCIPruneInterval, 2*time.Minute, "ABCI state pruning interval")
+	Flags.Uint64(CfgABCIPruneBatchSize, 0, "Maximum number of ABCI state versions pruned per interval (0 = unlimited)")
 	Flags.Bool(CfgCheckpointerDisabled, false, "Disable the ABCI state checkpointer")
 	Flags.Duration(CfgCheckpointerCheckInterval, 1*time.Minute, "ABCI state checkpointer check interval")
 	Flags.StringSlice(CfgSentryUpstreamAddress, []string{}, "Tendermint nodes for which we act as sentry of the form ID@ip:port")
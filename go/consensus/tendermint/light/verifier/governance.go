@@ -0,0 +1,99 @@
+package verifier
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	app "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/governance"
+	"github.com/oasisprotocol/oasis-core/go/governance/api"
+	upgrade "github.com/oasisprotocol/oasis-core/go/upgrade/api"
+)
+
+type governanceBackend struct {
+	querier *app.QueryFactory
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) ActiveProposals(ctx context.Context, height int64) ([]*api.Proposal, error) {
+	q, err := gb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ActiveProposals(ctx)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) Proposals(ctx context.Context, height int64) ([]*api.Proposal, error) {
+	q, err := gb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Proposals(ctx)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) Proposal(ctx context.Context, query *api.ProposalQuery) (*api.Proposal, error) {
+	q, err := gb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Proposal(ctx, query.ProposalID)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) Votes(ctx context.Context, query *api.ProposalQuery) ([]*api.VoteEntry, error) {
+	q, err := gb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Votes(ctx, query.ProposalID)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) PendingUpgrades(ctx context.Context, height int64) ([]*upgrade.Descriptor, error) {
+	q, err := gb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.PendingUpgrades(ctx)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) StateToGenesis(ctx context.Context, height int64) (*api.Genesis, error) {
+	q, err := gb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Genesis(ctx)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) ConsensusParameters(ctx context.Context, height int64) (*api.ConsensusParameters, error) {
+	q, err := gb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ConsensusParameters(ctx)
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) GetEvents(ctx context.Context, height int64) ([]*api.Event, error) {
+	return nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) WatchEvents(ctx context.Context) (<-chan *api.Event, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (gb *governanceBackend) Cleanup() {
+}
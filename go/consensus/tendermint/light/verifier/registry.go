@@ -0,0 +1,135 @@
+package verifier
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/entity"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	app "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry"
+	"github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+type registryBackend struct {
+	querier *app.QueryFactory
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetEntity(ctx context.Context, query *api.IDQuery) (*entity.Entity, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Entity(ctx, query.ID)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetEntities(ctx context.Context, height int64) ([]*entity.Entity, error) {
+	q, err := rb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Entities(ctx)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) WatchEntities(ctx context.Context) (<-chan *api.EntityEvent, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetNode(ctx context.Context, query *api.IDQuery) (*node.Node, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Node(ctx, query.ID)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetNodeStatus(ctx context.Context, query *api.IDQuery) (*api.NodeStatus, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.NodeStatus(ctx, query.ID)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetNodes(ctx context.Context, height int64) ([]*node.Node, error) {
+	q, err := rb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Nodes(ctx)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetNodeByConsensusAddress(ctx context.Context, query *api.ConsensusAddressQuery) (*node.Node, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.NodeByConsensusAddress(ctx, query.Address)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) WatchNodes(ctx context.Context) (<-chan *api.NodeEvent, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) WatchNodeList(ctx context.Context) (<-chan *api.NodeList, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetRuntime(ctx context.Context, query *api.NamespaceQuery) (*api.Runtime, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Runtime(ctx, query.ID)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetRuntimes(ctx context.Context, query *api.GetRuntimesQuery) ([]*api.Runtime, error) {
+	q, err := rb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Runtimes(ctx, query.IncludeSuspended)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) WatchRuntimes(ctx context.Context) (<-chan *api.Runtime, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) StateToGenesis(ctx context.Context, height int64) (*api.Genesis, error) {
+	q, err := rb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Genesis(ctx)
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) GetEvents(ctx context.Context, height int64) ([]*api.Event, error) {
+	return nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (rb *registryBackend) Cleanup() {
+}
@@ -0,0 +1,75 @@
+package verifier
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	app "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/scheduler"
+	"github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+type schedulerBackend struct {
+	querier *app.QueryFactory
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) GetValidators(ctx context.Context, height int64) ([]*api.Validator, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Validators(ctx)
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) GetCommittees(ctx context.Context, request *api.GetCommitteesRequest) ([]*api.Committee, error) {
+	q, err := sb.querier.QueryAt(ctx, request.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	committees, err := q.AllCommittees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var runtimeCommittees []*api.Committee
+	for _, c := range committees {
+		if c.RuntimeID.Equal(&request.RuntimeID) {
+			runtimeCommittees = append(runtimeCommittees, c)
+		}
+	}
+
+	return runtimeCommittees, nil
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) WatchCommittees(ctx context.Context) (<-chan *api.Committee, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) StateToGenesis(ctx context.Context, height int64) (*api.Genesis, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Genesis(ctx)
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) ConsensusParameters(ctx context.Context, height int64) (*api.ConsensusParameters, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ConsensusParameters(ctx)
+}
+
+// Implements api.Backend.
+func (sb *schedulerBackend) Cleanup() {
+}
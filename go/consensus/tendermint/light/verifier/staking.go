@@ -0,0 +1,201 @@
+package verifier
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	app "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking"
+	"github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+type stakingBackend struct {
+	querier *app.QueryFactory
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) TokenSymbol(ctx context.Context) (string, error) {
+	// The token symbol is only part of the genesis document.
+	return "", consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) TokenValueExponent(ctx context.Context) (uint8, error) {
+	// The token value exponent is only part of the genesis document.
+	return 0, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) TotalSupply(ctx context.Context, height int64) (*quantity.Quantity, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.TotalSupply(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) CommonPool(ctx context.Context, height int64) (*quantity.Quantity, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.CommonPool(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) LastBlockFees(ctx context.Context, height int64) (*quantity.Quantity, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.LastBlockFees(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) GovernanceDeposits(ctx context.Context, height int64) (*quantity.Quantity, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.GovernanceDeposits(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) Threshold(ctx context.Context, query *api.ThresholdQuery) (*quantity.Quantity, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Threshold(ctx, query.Kind)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) Addresses(ctx context.Context, height int64) ([]api.Address, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Addresses(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) Account(ctx context.Context, query *api.OwnerQuery) (*api.Account, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Account(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DelegationsFor(ctx context.Context, query *api.OwnerQuery) (map[api.Address]*api.Delegation, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DelegationsFor(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DelegationInfosFor(ctx context.Context, query *api.OwnerQuery) (map[api.Address]*api.DelegationInfo, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DelegationInfosFor(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DelegationsTo(ctx context.Context, query *api.OwnerQuery) (map[api.Address]*api.Delegation, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DelegationsTo(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DebondingDelegationsFor(ctx context.Context, query *api.OwnerQuery) (map[api.Address][]*api.DebondingDelegation, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DebondingDelegationsFor(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DebondingDelegationInfosFor(ctx context.Context, query *api.OwnerQuery) (map[api.Address][]*api.DebondingDelegationInfo, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DebondingDelegationInfosFor(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) DebondingDelegationsTo(ctx context.Context, query *api.OwnerQuery) (map[api.Address][]*api.DebondingDelegation, error) {
+	q, err := sb.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DebondingDelegationsTo(ctx, query.Owner)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) Allowance(ctx context.Context, query *api.AllowanceQuery) (*quantity.Quantity, error) {
+	acct, err := sb.Account(ctx, &api.OwnerQuery{
+		Height: query.Height,
+		Owner:  query.Owner,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allowance := acct.General.Allowances[query.Beneficiary]
+	return &allowance, nil
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) StateToGenesis(ctx context.Context, height int64) (*api.Genesis, error) {
+	// The staking genesis document includes static values that are not part of consensus state.
+	return nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) ConsensusParameters(ctx context.Context, height int64) (*api.ConsensusParameters, error) {
+	q, err := sb.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ConsensusParameters(ctx)
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) GetEvents(ctx context.Context, height int64) ([]*api.Event, error) {
+	return nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) WatchEvents(ctx context.Context) (<-chan *api.Event, pubsub.ClosableSubscription, error) {
+	return nil, nil, consensus.ErrUnsupported
+}
+
+// Implements api.Backend.
+func (sb *stakingBackend) Cleanup() {
+}
@@ -0,0 +1,208 @@
+// Package verifier implements consensus service backends that read consensus state from an
+// untrusted node and verify it against state roots obtained from light client verified headers.
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	beaconApp "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/beacon"
+	governanceApp "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/governance"
+	registryApp "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry"
+	schedulerApp "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/scheduler"
+	stakingApp "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/light"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	mkvsNode "github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+// Client is a consensus services client that verifies all query results.
+//
+// Only queries that can be answered by reading consensus state are supported. Methods that
+// require events or other data that is not committed to in the consensus state return
+// consensus.ErrUnsupported.
+type Client interface {
+	// Staking returns the verifying staking backend.
+	Staking() staking.Backend
+
+	// Registry returns the verifying registry backend.
+	Registry() registry.Backend
+
+	// Scheduler returns the verifying scheduler backend.
+	Scheduler() scheduler.Backend
+
+	// Governance returns the verifying governance backend.
+	Governance() governance.Backend
+}
+
+type client struct {
+	staking    staking.Backend
+	registry   registry.Backend
+	scheduler  scheduler.Backend
+	governance governance.Backend
+}
+
+// Implements Client.
+func (c *client) Staking() staking.Backend {
+	return c.staking
+}
+
+// Implements Client.
+func (c *client) Registry() registry.Backend {
+	return c.registry
+}
+
+// Implements Client.
+func (c *client) Scheduler() scheduler.Backend {
+	return c.scheduler
+}
+
+// Implements Client.
+func (c *client) Governance() governance.Backend {
+	return c.governance
+}
+
+var _ abciAPI.ImmutableStateProvider = (*verifiedState)(nil)
+
+// verifiedState is an application query state that reads consensus state from the light client's
+// primary node and verifies it against state roots from verified light blocks.
+type verifiedState struct {
+	lc     light.Client
+	beacon *beaconApp.QueryFactory
+
+	heightLock sync.RWMutex
+	height     int64
+}
+
+// Implements abciAPI.ApplicationQueryState.
+func (s *verifiedState) Storage() storage.LocalBackend {
+	// There is no local storage, all state is fetched from the remote node.
+	return nil
+}
+
+// Implements abciAPI.ApplicationQueryState.
+func (s *verifiedState) Checkpointer() checkpoint.Checkpointer {
+	return nil
+}
+
+// Implements abciAPI.ApplicationQueryState.
+func (s *verifiedState) BlockHeight() int64 {
+	s.heightLock.RLock()
+	defer s.heightLock.RUnlock()
+
+	return s.height
+}
+
+// Implements abciAPI.ApplicationQueryState.
+func (s *verifiedState) GetEpoch(ctx context.Context, blockHeight int64) (beacon.EpochTime, error) {
+	q, err := s.beacon.QueryAt(ctx, blockHeight)
+	if err != nil {
+		return beacon.EpochInvalid, err
+	}
+
+	epoch, _, err := q.Epoch(ctx)
+	return epoch, err
+}
+
+// Implements abciAPI.ApplicationQueryState.
+func (s *verifiedState) LastRetainedVersion() (int64, error) {
+	return 0, consensus.ErrUnsupported
+}
+
+// Implements abciAPI.ImmutableStateProvider.
+func (s *verifiedState) GetImmutableState(ctx context.Context, version int64) (*abciAPI.ImmutableState, error) {
+	// The state root for a given version is committed to in the header of the following block, so
+	// the latest state that can be verified is the one preceding the latest block.
+	var height int64
+	switch {
+	case version <= 0:
+		lb, err := s.lc.GetLightBlock(ctx, consensus.HeightLatest)
+		if err != nil {
+			return nil, fmt.Errorf("verifier: failed to fetch latest light block: %w", err)
+		}
+		height = lb.Height
+	default:
+		height = version + 1
+	}
+	if height <= 0 {
+		return nil, consensus.ErrNoCommittedBlocks
+	}
+
+	tlb, err := s.lc.GetVerifiedLightBlock(ctx, height)
+	if err != nil {
+		if version > 0 {
+			// Distinguish the case where the state at the requested version has not yet been
+			// committed to by any block (e.g., the version is the latest height).
+			lb, lerr := s.lc.GetLightBlock(ctx, consensus.HeightLatest)
+			if lerr == nil && version >= lb.Height {
+				return nil, fmt.Errorf("%w: state at height %d cannot be verified before block %d is available",
+					consensus.ErrVersionNotFound, version, height,
+				)
+			}
+		}
+		return nil, fmt.Errorf("verifier: failed to verify light block at height %d: %w", height, err)
+	}
+
+	var stateRoot hash.Hash
+	switch tlb.AppHash {
+	case nil:
+		stateRoot.Empty()
+	default:
+		if err = stateRoot.UnmarshalBinary(tlb.AppHash); err != nil {
+			return nil, fmt.Errorf("verifier: malformed state root at height %d: %w", height, err)
+		}
+	}
+	s.updateHeight(height - 1)
+
+	// All reads through the tree are verified against the trusted state root.
+	tree := mkvs.NewWithRoot(s.lc.State(), nil, mkvsNode.Root{
+		Version: uint64(height) - 1,
+		Type:    mkvsNode.RootTypeState,
+		Hash:    stateRoot,
+	})
+	return &abciAPI.ImmutableState{ImmutableKeyValueTree: tree}, nil
+}
+
+func (s *verifiedState) updateHeight(height int64) {
+	s.heightLock.Lock()
+	defer s.heightLock.Unlock()
+
+	if height > s.height {
+		s.height = height
+	}
+}
+
+// New creates a new verifying consensus services client that uses the given light client to
+// verify consensus state read from its primary node.
+func New(lc light.Client) Client {
+	state := &verifiedState{
+		lc: lc,
+	}
+	state.beacon = beaconApp.NewQueryFactory(state)
+
+	return &client{
+		staking: &stakingBackend{
+			querier: stakingApp.NewQueryFactory(state),
+		},
+		registry: &registryBackend{
+			querier: registryApp.NewQueryFactory(state),
+		},
+		scheduler: &schedulerBackend{
+			querier: schedulerApp.NewQueryFactory(state),
+		},
+		governance: &governanceBackend{
+			querier: governanceApp.NewQueryFactory(state),
+		},
+	}
+}
@@ -0,0 +1,183 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	mkvsNode "github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+const testLatestHeight = 10
+
+var testAddress = staking.NewAddress(signature.NewPublicKey("aaafffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+
+// testLightClient is a light client that serves a fixed set of verified light blocks and reads
+// state from the given (untrusted) read syncer.
+type testLightClient struct {
+	stateRoots map[int64]hash.Hash
+	state      syncer.ReadSyncer
+}
+
+func (lc *testLightClient) GetLightBlock(ctx context.Context, height int64) (*consensus.LightBlock, error) {
+	if height == consensus.HeightLatest {
+		height = testLatestHeight
+	}
+	return &consensus.LightBlock{Height: height}, nil
+}
+
+func (lc *testLightClient) GetParameters(ctx context.Context, height int64) (*consensus.Parameters, error) {
+	return nil, consensus.ErrUnsupported
+}
+
+func (lc *testLightClient) State() syncer.ReadSyncer {
+	return lc.state
+}
+
+func (lc *testLightClient) SubmitTxNoWait(ctx context.Context, tx *transaction.SignedTransaction) error {
+	return consensus.ErrUnsupported
+}
+
+func (lc *testLightClient) SubmitEvidence(ctx context.Context, evidence *consensus.Evidence) error {
+	return consensus.ErrUnsupported
+}
+
+func (lc *testLightClient) GetVerifiedLightBlock(ctx context.Context, height int64) (*tmtypes.LightBlock, error) {
+	stateRoot, ok := lc.stateRoots[height]
+	if !ok {
+		return nil, fmt.Errorf("light block at height %d not available", height)
+	}
+	return &tmtypes.LightBlock{
+		SignedHeader: &tmtypes.SignedHeader{
+			Header: &tmtypes.Header{
+				Height:  height,
+				AppHash: stateRoot[:],
+			},
+		},
+	}, nil
+}
+
+func (lc *testLightClient) GetVerifiedParameters(ctx context.Context, height int64) (*tmproto.ConsensusParams, error) {
+	return nil, consensus.ErrUnsupported
+}
+
+// maliciousSyncer is a read syncer that serves proofs for its own tree regardless of the requested
+// root and optionally tampers with them.
+type maliciousSyncer struct {
+	tree mkvs.Tree
+	root syncer.TreeID
+
+	tamper bool
+}
+
+func (s *maliciousSyncer) SyncGet(ctx context.Context, request *syncer.GetRequest) (*syncer.ProofResponse, error) {
+	rq := *request
+	rq.Tree.Root = s.root.Root
+	rsp, err := s.tree.SyncGet(ctx, &rq)
+	if err != nil {
+		return nil, err
+	}
+	if s.tamper {
+		entries := rsp.Proof.Entries
+		for i := len(entries) - 1; i >= 0; i-- {
+			if len(entries[i]) > 1 {
+				entries[i][len(entries[i])-1] ^= 0xff
+				break
+			}
+		}
+	}
+	return rsp, nil
+}
+
+func (s *maliciousSyncer) SyncGetPrefixes(ctx context.Context, request *syncer.GetPrefixesRequest) (*syncer.ProofResponse, error) {
+	return nil, syncer.ErrUnsupported
+}
+
+func (s *maliciousSyncer) SyncIterate(ctx context.Context, request *syncer.IterateRequest) (*syncer.ProofResponse, error) {
+	return nil, syncer.ErrUnsupported
+}
+
+// newTestState creates a committed consensus state tree at the given version containing an account
+// with the given balance.
+func newTestState(t *testing.T, version int64, balance uint64) (mkvs.Tree, syncer.TreeID) {
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{})
+	ctx := appState.NewContext(abciAPI.ContextEndBlock, time.Now())
+	defer ctx.Close()
+
+	err := stakingState.NewMutableState(ctx.State()).SetAccount(ctx, testAddress, &staking.Account{
+		General: staking.GeneralAccount{
+			Balance: *quantity.NewFromUint64(balance),
+		},
+	})
+	require.NoError(t, err, "SetAccount")
+
+	tree := ctx.State().(mkvs.Tree)
+	_, rootHash, err := tree.Commit(ctx, common.Namespace{}, uint64(version))
+	require.NoError(t, err, "Commit")
+
+	var root syncer.TreeID
+	root.Root.Version = uint64(version)
+	root.Root.Type = mkvsNode.RootTypeState
+	root.Root.Hash = rootHash
+	return tree, root
+}
+
+func TestVerifiedState(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	// State at the latest verifiable version is committed to in the latest block.
+	version := int64(testLatestHeight - 1)
+	tree, root := newTestState(t, version, 100)
+	forgedTree, forgedRoot := newTestState(t, version, 1000)
+	require.NotEqual(root.Root.Hash, forgedRoot.Root.Hash, "forged state root should differ")
+
+	lc := &testLightClient{
+		stateRoots: map[int64]hash.Hash{
+			testLatestHeight: root.Root.Hash,
+		},
+		state: tree,
+	}
+	client := New(lc)
+
+	// Honest node.
+	acct, err := client.Staking().Account(ctx, &staking.OwnerQuery{Height: version, Owner: testAddress})
+	require.NoError(err, "Account")
+	require.Zero(acct.General.Balance.Cmp(quantity.NewFromUint64(100)), "account balance should be correct")
+
+	acct, err = client.Staking().Account(ctx, &staking.OwnerQuery{Height: consensus.HeightLatest, Owner: testAddress})
+	require.NoError(err, "Account (latest)")
+	require.Zero(acct.General.Balance.Cmp(quantity.NewFromUint64(100)), "account balance should be correct")
+
+	// State at the latest height is not yet committed to by any block.
+	_, err = client.Staking().Account(ctx, &staking.OwnerQuery{Height: testLatestHeight, Owner: testAddress})
+	require.ErrorIs(err, consensus.ErrVersionNotFound, "state at the latest height should not be verifiable")
+
+	// Node serving state for a wrong root.
+	lc.state = &maliciousSyncer{tree: forgedTree, root: forgedRoot}
+	client = New(lc)
+	_, err = client.Staking().Account(ctx, &staking.OwnerQuery{Height: version, Owner: testAddress})
+	require.Error(err, "state for a wrong root should be rejected")
+
+	// Node serving tampered proofs.
+	lc.state = &maliciousSyncer{tree: tree, root: root, tamper: true}
+	client = New(lc)
+	_, err = client.Staking().Account(ctx, &staking.OwnerQuery{Height: version, Owner: testAddress})
+	require.Error(err, "tampered proof should be rejected")
+}
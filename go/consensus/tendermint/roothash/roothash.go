@@ -59,6 +59,10 @@ type cmdTrackRuntime struct {
 	blockHistory api.BlockHistory
 }
 
+type cmdUntrackRuntime struct {
+	runtimeID common.Namespace
+}
+
 type serviceClient struct {
 	tmapi.BaseServiceClient
 	sync.RWMutex
@@ -236,6 +240,22 @@ func (sc *serviceClient) trackRuntime(ctx context.Context, id common.Namespace,
 	return nil
 }
 
+// Implements api.Backend.
+func (sc *serviceClient) UntrackRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	sc.pruneHandler.untrackRuntime(runtimeID)
+
+	cmd := &cmdUntrackRuntime{
+		runtimeID: runtimeID,
+	}
+
+	select {
+	case sc.cmdCh <- cmd:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
 // Implements api.Backend.
 func (sc *serviceClient) StateToGenesis(ctx context.Context, height int64) (*api.Genesis, error) {
 	q, err := sc.querier.QueryAt(ctx, height)
@@ -491,6 +511,17 @@ func (sc *serviceClient) DeliverCommand(ctx context.Context, height int64, cmd i
 		}
 		// Make sure we reindex again when receiving the first event.
 		tr.reindexDone = false
+	case *cmdUntrackRuntime:
+		// Request to stop tracking a runtime.
+		if sc.trackedRuntime[c.runtimeID] == nil {
+			break
+		}
+
+		sc.logger.Debug("no longer tracking runtime",
+			"runtime_id", c.runtimeID,
+			"height", height,
+		)
+		delete(sc.trackedRuntime, c.runtimeID)
 	default:
 		return fmt.Errorf("roothash: unknown command: %T", cmd)
 	}
@@ -731,6 +762,20 @@ func (ph *pruneHandler) trackRuntime(bh api.BlockHistory) {
 	ph.trackedRuntimes = append(ph.trackedRuntimes, bh)
 }
 
+func (ph *pruneHandler) untrackRuntime(runtimeID common.Namespace) {
+	ph.Lock()
+	defer ph.Unlock()
+
+	var trackedRuntimes []api.BlockHistory
+	for _, bh := range ph.trackedRuntimes {
+		if id := bh.RuntimeID(); id.Equal(&runtimeID) {
+			continue
+		}
+		trackedRuntimes = append(trackedRuntimes, bh)
+	}
+	ph.trackedRuntimes = trackedRuntimes
+}
+
 // Implements api.StatePruneHandler.
 func (ph *pruneHandler) Prune(ctx context.Context, version uint64) error {
 	ph.Lock()
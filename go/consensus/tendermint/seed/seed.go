@@ -184,6 +184,11 @@ func (srv *seedService) Checkpointer() checkpoint.Checkpointer {
 	return nil
 }
 
+// Implements Backend.
+func (srv *seedService) PauseStatePruning(pause bool) error {
+	return consensus.ErrUnsupported
+}
+
 // Implements Backend.
 func (srv *seedService) SubmitEvidence(ctx context.Context, evidence *consensus.Evidence) error {
 	return consensus.ErrUnsupported
@@ -194,6 +199,11 @@ func (srv *seedService) SubmitTx(ctx context.Context, tx *transaction.SignedTran
 	return consensus.ErrUnsupported
 }
 
+// Implements Backend.
+func (srv *seedService) SubmitMultiSignedTx(ctx context.Context, tx *transaction.MultiSignedTransaction) error {
+	return consensus.ErrUnsupported
+}
+
 // Implements Backend.
 func (srv *seedService) StateToGenesis(ctx context.Context, height int64) (*genesis.Document, error) {
 	return nil, consensus.ErrUnsupported
@@ -51,6 +51,35 @@ type NodeController interface {
 
 	// GetStatus returns the current status overview of the node.
 	GetStatus(ctx context.Context) (*Status, error)
+
+	// PauseConsensusPruning pauses or unpauses consensus state pruning.
+	//
+	// This can be used to make sure that state is not pruned while checkpoints
+	// are being created.
+	PauseConsensusPruning(ctx context.Context, pause bool) error
+
+	// AddRuntime starts hosting the given runtime without restarting the node.
+	//
+	// The node will re-register so that its descriptor includes the new runtime.
+	AddRuntime(ctx context.Context, req *AddRuntimeRequest) error
+
+	// RemoveRuntime stops hosting the given runtime without restarting the node.
+	//
+	// The node first re-registers without the runtime and then tears down all of the
+	// runtime's services.
+	RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error
+}
+
+// AddRuntimeRequest is a request to start hosting a runtime.
+type AddRuntimeRequest struct {
+	// RuntimeID is the identifier of the runtime.
+	RuntimeID common.Namespace `json:"runtime_id"`
+
+	// Path is the path to the runtime binary.
+	Path string `json:"path"`
+
+	// SGXSignaturePath is the optional path to the SGX signature of the runtime binary.
+	SGXSignaturePath string `json:"sgx_signature_path,omitempty"`
 }
 
 // Status is the current status overview.
@@ -128,6 +157,11 @@ type RuntimeStatus struct {
 	// LastRetainedHash is the hash of the oldest retained block.
 	LastRetainedHash hash.Hash `json:"last_retained_hash"`
 
+	// Degraded is true in case the hosted runtime has been restarted more often than allowed by
+	// the node's runtime restart policy. While degraded, the node does not register for executor
+	// roles.
+	Degraded bool `json:"degraded,omitempty"`
+
 	// Committee contains the runtime worker status in case this node is a (candidate) member of a
 	// runtime committee (e.g., compute or storage).
 	Committee *commonWorker.Status `json:"committee"`
@@ -154,6 +188,12 @@ type ControlledNode interface {
 
 	// GetPendingUpgrade returns the node's pending upgrades.
 	GetPendingUpgrades(ctx context.Context) ([]*upgrade.PendingUpgrade, error)
+
+	// AddRuntime starts hosting the given runtime.
+	AddRuntime(ctx context.Context, req *AddRuntimeRequest) error
+
+	// RemoveRuntime stops hosting the given runtime.
+	RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error
 }
 
 // DebugModuleName is the module name for the debug controller service.
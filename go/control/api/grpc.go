@@ -5,6 +5,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
 	upgradeApi "github.com/oasisprotocol/oasis-core/go/upgrade/api"
 )
@@ -29,6 +30,12 @@ var (
 	methodCancelUpgrade = serviceName.NewMethod("CancelUpgrade", nil)
 	// methodGetStatus is the GetStatus method.
 	methodGetStatus = serviceName.NewMethod("GetStatus", nil)
+	// methodPauseConsensusPruning is the PauseConsensusPruning method.
+	methodPauseConsensusPruning = serviceName.NewMethod("PauseConsensusPruning", false)
+	// methodAddRuntime is the AddRuntime method.
+	methodAddRuntime = serviceName.NewMethod("AddRuntime", AddRuntimeRequest{})
+	// methodRemoveRuntime is the RemoveRuntime method.
+	methodRemoveRuntime = serviceName.NewMethod("RemoveRuntime", common.Namespace{})
 
 	// serviceDesc is the gRPC service descriptor.
 	serviceDesc = grpc.ServiceDesc{
@@ -67,6 +74,18 @@ var (
 				MethodName: methodGetStatus.ShortName(),
 				Handler:    handlerGetStatus,
 			},
+			{
+				MethodName: methodPauseConsensusPruning.ShortName(),
+				Handler:    handlerPauseConsensusPruning,
+			},
+			{
+				MethodName: methodAddRuntime.ShortName(),
+				Handler:    handlerAddRuntime,
+			},
+			{
+				MethodName: methodRemoveRuntime.ShortName(),
+				Handler:    handlerRemoveRuntime,
+			},
 		},
 		Streams: []grpc.StreamDesc{},
 	}
@@ -236,6 +255,75 @@ func handlerGetStatus( // nolint: golint
 	return interceptor(ctx, nil, info, handler)
 }
 
+func handlerPauseConsensusPruning( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var pause bool
+	if err := dec(&pause); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return nil, srv.(NodeController).PauseConsensusPruning(ctx, pause)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodPauseConsensusPruning.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, srv.(NodeController).PauseConsensusPruning(ctx, req.(bool))
+	}
+	return interceptor(ctx, pause, info, handler)
+}
+
+func handlerAddRuntime( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req AddRuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return nil, srv.(NodeController).AddRuntime(ctx, &req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodAddRuntime.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, srv.(NodeController).AddRuntime(ctx, req.(*AddRuntimeRequest))
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerRemoveRuntime( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var runtimeID common.Namespace
+	if err := dec(&runtimeID); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return nil, srv.(NodeController).RemoveRuntime(ctx, runtimeID)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodRemoveRuntime.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, srv.(NodeController).RemoveRuntime(ctx, *req.(*common.Namespace))
+	}
+	return interceptor(ctx, &runtimeID, info, handler)
+}
+
 // RegisterService registers a new node controller service with the given gRPC server.
 func RegisterService(server *grpc.Server, service NodeController) {
 	server.RegisterService(&serviceDesc, service)
@@ -281,6 +369,18 @@ func (c *nodeControllerClient) CancelUpgrade(ctx context.Context, descriptor *up
 	return c.conn.Invoke(ctx, methodCancelUpgrade.FullName(), descriptor, nil)
 }
 
+func (c *nodeControllerClient) PauseConsensusPruning(ctx context.Context, pause bool) error {
+	return c.conn.Invoke(ctx, methodPauseConsensusPruning.FullName(), pause, nil)
+}
+
+func (c *nodeControllerClient) AddRuntime(ctx context.Context, req *AddRuntimeRequest) error {
+	return c.conn.Invoke(ctx, methodAddRuntime.FullName(), req, nil)
+}
+
+func (c *nodeControllerClient) RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	return c.conn.Invoke(ctx, methodRemoveRuntime.FullName(), runtimeID, nil)
+}
+
 func (c *nodeControllerClient) GetStatus(ctx context.Context) (*Status, error) {
 	var rsp Status
 	if err := c.conn.Invoke(ctx, methodGetStatus.FullName(), nil, &rsp); err != nil {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/version"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	control "github.com/oasisprotocol/oasis-core/go/control/api"
@@ -81,6 +82,18 @@ func (c *nodeController) CancelUpgrade(ctx context.Context, descriptor *upgrade.
 	return c.upgrader.CancelUpgrade(ctx, descriptor)
 }
 
+func (c *nodeController) PauseConsensusPruning(ctx context.Context, pause bool) error {
+	return c.consensus.PauseStatePruning(pause)
+}
+
+func (c *nodeController) AddRuntime(ctx context.Context, req *control.AddRuntimeRequest) error {
+	return c.node.AddRuntime(ctx, req)
+}
+
+func (c *nodeController) RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	return c.node.RemoveRuntime(ctx, runtimeID)
+}
+
 func (c *nodeController) GetStatus(ctx context.Context) (*control.Status, error) {
 	cs, err := c.consensus.GetStatus(ctx)
 	if err != nil {
@@ -2,11 +2,14 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"sort"
 
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
@@ -39,6 +42,9 @@ var (
 	ErrNotEligible = errors.New(ModuleName, 6, "governance: not eligible")
 	// ErrVotingIsClosed is the error returned when a vote is cast for a non-active proposal.
 	ErrVotingIsClosed = errors.New(ModuleName, 7, "governance: voting is closed")
+	// ErrInvalidParameterChanges is the error returned when a change parameters proposal
+	// contains changes that are not supported or not valid for the target module.
+	ErrInvalidParameterChanges = errors.New(ModuleName, 8, "governance: invalid parameter changes")
 
 	// MethodSubmitProposal submits a new consensus layer governance proposal.
 	MethodSubmitProposal = transaction.NewMethodName(ModuleName, "SubmitProposal", ProposalContent{})
@@ -54,25 +60,44 @@ var (
 	_ prettyprint.PrettyPrinter = (*ProposalContent)(nil)
 	_ prettyprint.PrettyPrinter = (*UpgradeProposal)(nil)
 	_ prettyprint.PrettyPrinter = (*CancelUpgradeProposal)(nil)
+	_ prettyprint.PrettyPrinter = (*ChangeParametersProposal)(nil)
 	_ prettyprint.PrettyPrinter = (*ProposalVote)(nil)
 )
 
 // ProposalContent is a consensus layer governance proposal content.
 type ProposalContent struct {
-	Upgrade       *UpgradeProposal       `json:"upgrade,omitempty"`
-	CancelUpgrade *CancelUpgradeProposal `json:"cancel_upgrade,omitempty"`
+	Upgrade          *UpgradeProposal          `json:"upgrade,omitempty"`
+	CancelUpgrade    *CancelUpgradeProposal    `json:"cancel_upgrade,omitempty"`
+	ChangeParameters *ChangeParametersProposal `json:"change_parameters,omitempty"`
+}
+
+// numFieldsSet returns the number of proposal content fields that are set.
+func (p *ProposalContent) numFieldsSet() int {
+	var n int
+	if p.Upgrade != nil {
+		n++
+	}
+	if p.CancelUpgrade != nil {
+		n++
+	}
+	if p.ChangeParameters != nil {
+		n++
+	}
+	return n
 }
 
 // ValidateBasic performs basic proposal content validity checks.
 func (p *ProposalContent) ValidateBasic() error {
 	switch {
-	case p.Upgrade != nil && p.CancelUpgrade != nil:
+	case p.numFieldsSet() > 1:
 		return fmt.Errorf("proposal content has multiple fields set")
 	case p.Upgrade != nil:
 		return p.Upgrade.ValidateBasic()
 	case p.CancelUpgrade != nil:
 		// No validation at this time.
 		return nil
+	case p.ChangeParameters != nil:
+		return p.ChangeParameters.ValidateBasic()
 	default:
 		return fmt.Errorf("proposal content has no fields set")
 	}
@@ -88,6 +113,8 @@ func (p *ProposalContent) Equals(other *ProposalContent) bool {
 		return p.CancelUpgrade.ProposalID == other.CancelUpgrade.ProposalID
 	case p.Upgrade != nil && other.Upgrade != nil:
 		return p.Upgrade.Descriptor.Equals(&other.Upgrade.Descriptor)
+	case p.ChangeParameters != nil && other.ChangeParameters != nil:
+		return p.ChangeParameters.Equals(other.ChangeParameters)
 	default:
 		return false
 	}
@@ -97,14 +124,17 @@ func (p *ProposalContent) Equals(other *ProposalContent) bool {
 // given writer.
 func (p ProposalContent) PrettyPrint(ctx context.Context, prefix string, w io.Writer) {
 	switch {
-	case p.Upgrade != nil && p.CancelUpgrade == nil:
+	case p.numFieldsSet() != 1:
+		fmt.Fprintf(w, "%s%s\n", prefix, ProposalContentInvalidText)
+	case p.Upgrade != nil:
 		fmt.Fprintf(w, "%sUpgrade:\n", prefix)
 		p.Upgrade.PrettyPrint(ctx, prefix+"  ", w)
-	case p.CancelUpgrade != nil && p.Upgrade == nil:
+	case p.CancelUpgrade != nil:
 		fmt.Fprintf(w, "%sCancel Upgrade:\n", prefix)
 		p.CancelUpgrade.PrettyPrint(ctx, prefix+"  ", w)
-	default:
-		fmt.Fprintf(w, "%s%s\n", prefix, ProposalContentInvalidText)
+	case p.ChangeParameters != nil:
+		fmt.Fprintf(w, "%sChange Parameters:\n", prefix)
+		p.ChangeParameters.PrettyPrint(ctx, prefix+"  ", w)
 	}
 }
 
@@ -114,6 +144,12 @@ func (p ProposalContent) PrettyType() (interface{}, error) {
 	return p, nil
 }
 
+// MethodMetadata returns the metadata of the submit proposal method.
+func (p ProposalContent) MethodMetadata() transaction.MethodMetadata {
+	// The submitter is identified by the caller address so multisig accounts can submit proposals.
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // UpgradeProposal is an upgrade proposal.
 type UpgradeProposal struct {
 	upgrade.Descriptor
@@ -149,6 +185,65 @@ func (cu CancelUpgradeProposal) PrettyType() (interface{}, error) {
 	return cu, nil
 }
 
+// ChangeParametersProposal is a consensus parameters change proposal.
+type ChangeParametersProposal struct {
+	// Module identifies the consensus backend module to which the changes
+	// should be applied.
+	Module string `json:"module"`
+	// Changes are the CBOR-encoded consensus parameter changes that should be
+	// applied to the module. The format of the changes is module specific.
+	Changes cbor.RawMessage `json:"changes"`
+}
+
+// ValidateBasic performs basic change parameters proposal validity checks.
+//
+// Note: the validity of the changes themselves can only be checked by the
+// target module.
+func (p *ChangeParametersProposal) ValidateBasic() error {
+	if p.Module == "" {
+		return fmt.Errorf("change parameters proposal: module not set")
+	}
+	if len(p.Changes) == 0 {
+		return fmt.Errorf("change parameters proposal: changes not set")
+	}
+	return nil
+}
+
+// Equals checks if change parameters proposals are equal.
+func (p *ChangeParametersProposal) Equals(other *ChangeParametersProposal) bool {
+	return p.Module == other.Module && bytes.Equal(p.Changes, other.Changes)
+}
+
+// PrettyPrint writes a pretty-printed representation of ChangeParametersProposal
+// to the given writer.
+func (p ChangeParametersProposal) PrettyPrint(ctx context.Context, prefix string, w io.Writer) {
+	fmt.Fprintf(w, "%sModule: %s\n", prefix, p.Module)
+
+	// The changes are module specific, so only a generic representation
+	// can be shown here.
+	var changes map[string]interface{}
+	if err := cbor.Unmarshal(p.Changes, &changes); err != nil {
+		fmt.Fprintf(w, "%sChanges: %s\n", prefix, ProposalContentInvalidText)
+		return
+	}
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "%sChanges:\n", prefix)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s  %s: %v\n", prefix, k, changes[k])
+	}
+}
+
+// PrettyType returns a representation of ChangeParametersProposal that can be
+// used for pretty printing.
+func (p ChangeParametersProposal) PrettyType() (interface{}, error) {
+	return p, nil
+}
+
 // ProposalVote is a vote for a proposal.
 type ProposalVote struct {
 	// ID is the unique identifier of a proposal.
@@ -258,6 +353,64 @@ type ConsensusParameters struct {
 	UpgradeCancelMinEpochDiff beacon.EpochTime `json:"upgrade_cancel_min_epoch_diff,omitempty"`
 }
 
+// ConsensusParameterChanges are allowed governance consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// GasCosts are the new gas costs. If set, they replace all existing gas costs.
+	GasCosts transaction.Costs `json:"gas_costs,omitempty"`
+
+	// MinProposalDeposit is the new minimal proposal deposit.
+	MinProposalDeposit *quantity.Quantity `json:"min_proposal_deposit,omitempty"`
+
+	// VotingPeriod is the new voting period.
+	VotingPeriod *beacon.EpochTime `json:"voting_period,omitempty"`
+
+	// StakeThreshold is the new stake threshold.
+	StakeThreshold *uint8 `json:"stake_threshold,omitempty"`
+
+	// UpgradeMinEpochDiff is the new minimal epoch difference between two pending upgrades.
+	UpgradeMinEpochDiff *beacon.EpochTime `json:"upgrade_min_epoch_diff,omitempty"`
+
+	// UpgradeCancelMinEpochDiff is the new minimal epoch difference for the upgrade
+	// cancellation proposal to be valid.
+	UpgradeCancelMinEpochDiff *beacon.EpochTime `json:"upgrade_cancel_min_epoch_diff,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.GasCosts == nil &&
+		c.MinProposalDeposit == nil &&
+		c.VotingPeriod == nil &&
+		c.StakeThreshold == nil &&
+		c.UpgradeMinEpochDiff == nil &&
+		c.UpgradeCancelMinEpochDiff == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.GasCosts != nil {
+		params.GasCosts = c.GasCosts
+	}
+	if c.MinProposalDeposit != nil {
+		params.MinProposalDeposit = *c.MinProposalDeposit
+	}
+	if c.VotingPeriod != nil {
+		params.VotingPeriod = *c.VotingPeriod
+	}
+	if c.StakeThreshold != nil {
+		params.StakeThreshold = *c.StakeThreshold
+	}
+	if c.UpgradeMinEpochDiff != nil {
+		params.UpgradeMinEpochDiff = *c.UpgradeMinEpochDiff
+	}
+	if c.UpgradeCancelMinEpochDiff != nil {
+		params.UpgradeCancelMinEpochDiff = *c.UpgradeCancelMinEpochDiff
+	}
+	return nil
+}
+
 // Event signifies a governance event, returned via GetEvents.
 type Event struct {
 	Height int64     `json:"height,omitempty"`
@@ -267,6 +420,7 @@ type Event struct {
 	ProposalExecuted  *ProposalExecutedEvent  `json:"proposal_executed,omitempty"`
 	ProposalFinalized *ProposalFinalizedEvent `json:"proposal_finalized,omitempty"`
 	Vote              *VoteEvent              `json:"vote,omitempty"`
+	ParametersChanged *ParametersChangedEvent `json:"parameters_changed,omitempty"`
 }
 
 // ProposalSubmittedEvent is the event emitted when a new proposal is submitted.
@@ -321,6 +475,20 @@ func (e *VoteEvent) EventKind() string {
 	return "vote"
 }
 
+// ParametersChangedEvent is the event emitted when a change parameters proposal
+// is executed and the consensus parameters of a module have been changed.
+type ParametersChangedEvent struct {
+	// ID is the unique identifier of the executed proposal.
+	ID uint64 `json:"id"`
+	// Module is the consensus backend module whose parameters have been changed.
+	Module string `json:"module"`
+}
+
+// EventKind returns a string representation of this event's kind.
+func (e *ParametersChangedEvent) EventKind() string {
+	return "parameters-changed"
+}
+
 // NewSubmitProposalTx creates a new submit proposal transaction.
 func NewSubmitProposalTx(nonce uint64, fee *transaction.Fee, proposal *ProposalContent) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodSubmitProposal, proposal)
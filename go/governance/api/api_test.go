@@ -59,6 +59,34 @@ func TestValidateBasic(t *testing.T) {
 			},
 			shouldErr: false,
 		},
+		{
+			msg: "change parameters proposal without module should fail",
+			p: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			msg: "change parameters proposal without changes should fail",
+			p: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module: ModuleName,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			msg: "change parameters proposal content should not fail",
+			p: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+			shouldErr: false,
+		},
 	} {
 		err := tc.p.ValidateBasic()
 		if tc.shouldErr {
@@ -136,6 +164,38 @@ func TestProposalContentEquals(t *testing.T) {
 			},
 			equals: false,
 		},
+		{
+			msg: "change parameters proposals should be equal",
+			p1: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+			p2: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+			equals: true,
+		},
+		{
+			msg: "change parameters proposals should not be equal",
+			p1: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+			p2: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 24}),
+				},
+			},
+			equals: false,
+		},
 	} {
 		require.Equal(t, tc.equals, tc.p1.Equals(tc.p2), tc.msg)
 	}
@@ -160,6 +220,15 @@ func TestProposalContentPrettyPrint(t *testing.T) {
 				CancelUpgrade: &CancelUpgradeProposal{ProposalID: 42},
 			},
 		},
+		{
+			expRegex: "^Change Parameters:",
+			p: &ProposalContent{
+				ChangeParameters: &ChangeParametersProposal{
+					Module:  ModuleName,
+					Changes: cbor.Marshal(map[string]uint64{"voting_period": 42}),
+				},
+			},
+		},
 		{
 			expRegex: ProposalContentInvalidText,
 			p:        &ProposalContent{},
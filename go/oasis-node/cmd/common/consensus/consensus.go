@@ -11,10 +11,12 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	signerFile "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/file"
 	signerPlugin "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/plugin"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
 	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
 	genesisAPI "github.com/oasisprotocol/oasis-core/go/genesis/api"
 	genesisFile "github.com/oasisprotocol/oasis-core/go/genesis/file"
@@ -38,6 +40,10 @@ const (
 
 	// CfgTxUnsigned makes SaveTx save an unsigned transaction.
 	CfgTxUnsigned = "transaction.unsigned"
+
+	// CfgTxSigner configures the public key of the intended signer recorded in unsigned
+	// transactions.
+	CfgTxSigner = "transaction.signer"
 )
 
 var (
@@ -93,30 +99,9 @@ func GetTxNonceAndFee() (uint64, *transaction.Fee) {
 	return nonce, &fee
 }
 
-func SignAndSaveTx(ctx context.Context, tx *transaction.Transaction, signer signature.Signer) {
-	if viper.GetBool(CfgTxUnsigned) {
-		rawUnsignedTx := cbor.Marshal(tx)
-		if err := ioutil.WriteFile(viper.GetString(CfgTxFile), rawUnsignedTx, 0o600); err != nil {
-			logger.Error("failed to save unsigned transaction",
-				"err", err,
-			)
-			os.Exit(1)
-		}
-		return
-	}
-
-	if signer == nil {
-		var err error
-		_, signer, err = cmdCommon.LoadEntitySigner()
-		if err != nil {
-			logger.Error("failed to load signer",
-				"err", err,
-			)
-			os.Exit(1)
-		}
-		defer signer.Reset()
-	}
-
+// ConfirmSignTx displays the transaction that is about to be signed and asks the user for
+// confirmation in case a file-based signer is used.
+func ConfirmSignTx(ctx context.Context, tx *transaction.Transaction) {
 	fmt.Printf("You are about to sign the following transaction:\n")
 	tx.PrettyPrint(ctx, "  ", os.Stdout)
 
@@ -132,15 +117,39 @@ func SignAndSaveTx(ctx context.Context, tx *transaction.Transaction, signer sign
 			fmt.Println("\nYou may need to review the transaction on your device if you use a hardware-based signer plugin...")
 		}
 	}
+}
 
-	sigTx, err := transaction.Sign(signer, tx)
+// LoadUnsignedTx loads an unsigned transaction from the given file.
+//
+// Both versioned unsigned transaction envelopes and raw CBOR-serialized transactions are
+// supported. In the latter case, the returned envelope has an empty chain context.
+func LoadUnsignedTx(fn string) (*transaction.UnsignedTransaction, error) {
+	rawUnsignedTx, err := ioutil.ReadFile(fn)
 	if err != nil {
-		logger.Error("failed to sign transaction",
-			"err", err,
-		)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to read raw serialized unsigned transaction: %w", err)
 	}
 
+	if _, err = cbor.GetVersion(rawUnsignedTx); err != nil {
+		// Not versioned, this must be a raw unsigned transaction.
+		var tx transaction.Transaction
+		if err = cbor.Unmarshal(rawUnsignedTx, &tx); err != nil {
+			return nil, fmt.Errorf("failed to parse serialized unsigned transaction: %w", err)
+		}
+		return &transaction.UnsignedTransaction{Transaction: tx}, nil
+	}
+
+	var ut transaction.UnsignedTransaction
+	if err = cbor.Unmarshal(rawUnsignedTx, &ut); err != nil {
+		return nil, fmt.Errorf("failed to parse serialized unsigned transaction: %w", err)
+	}
+	if err = ut.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("invalid unsigned transaction: %w", err)
+	}
+	return &ut, nil
+}
+
+// SaveSignedTx saves the given signed transaction to the given file.
+func SaveSignedTx(fn string, sigTx *transaction.SignedTransaction) {
 	prettySigTx, err := cmdCommon.PrettyJSONMarshal(sigTx)
 	if err != nil {
 		logger.Error("failed to get pretty JSON of signed transaction",
@@ -148,7 +157,7 @@ func SignAndSaveTx(ctx context.Context, tx *transaction.Transaction, signer sign
 		)
 		os.Exit(1)
 	}
-	if err = ioutil.WriteFile(viper.GetString(CfgTxFile), prettySigTx, 0o600); err != nil {
+	if err = ioutil.WriteFile(fn, prettySigTx, 0o600); err != nil {
 		logger.Error("failed to save signed transaction",
 			"err", err,
 		)
@@ -156,6 +165,69 @@ func SignAndSaveTx(ctx context.Context, tx *transaction.Transaction, signer sign
 	}
 }
 
+func saveUnsignedTx(ctx context.Context, tx *transaction.Transaction, signer signature.Signer) {
+	genesisHash, ok := ctx.Value(prettyprint.ContextKeyGenesisHash).(hash.Hash)
+	if !ok {
+		logger.Error("failed to determine chain context")
+		os.Exit(1)
+	}
+
+	var signerPk *signature.PublicKey
+	switch {
+	case signer != nil:
+		pk := signer.Public()
+		signerPk = &pk
+	case viper.GetString(CfgTxSigner) != "":
+		var pk signature.PublicKey
+		if err := pk.UnmarshalText([]byte(viper.GetString(CfgTxSigner))); err != nil {
+			logger.Error("failed to unmarshal intended signer public key",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+		signerPk = &pk
+	}
+
+	ut := transaction.NewUnsignedTransaction(tx, genesisHash.Hex(), signerPk)
+	if err := ioutil.WriteFile(viper.GetString(CfgTxFile), cbor.Marshal(ut), 0o600); err != nil {
+		logger.Error("failed to save unsigned transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+}
+
+func SignAndSaveTx(ctx context.Context, tx *transaction.Transaction, signer signature.Signer) {
+	if viper.GetBool(CfgTxUnsigned) {
+		saveUnsignedTx(ctx, tx, signer)
+		return
+	}
+
+	if signer == nil {
+		var err error
+		_, signer, err = cmdCommon.LoadEntitySigner()
+		if err != nil {
+			logger.Error("failed to load signer",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+		defer signer.Reset()
+	}
+
+	ConfirmSignTx(ctx, tx)
+
+	sigTx, err := transaction.Sign(signer, tx)
+	if err != nil {
+		logger.Error("failed to sign transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	SaveSignedTx(viper.GetString(CfgTxFile), sigTx)
+}
+
 func init() {
 	TxFileFlags.String(CfgTxFile, "", "path to the transaction")
 	_ = viper.BindPFlags(TxFileFlags)
@@ -164,6 +236,7 @@ func init() {
 	TxFlags.Uint64(CfgTxFeeAmount, 0, "transaction fee in base units")
 	TxFlags.String(CfgTxFeeGas, "0", "maximum transaction gas limit")
 	TxFlags.Bool(CfgTxUnsigned, false, "generate an unsigned transaction")
+	TxFlags.String(CfgTxSigner, "", "public key of the intended signer of an unsigned transaction, in base64")
 	_ = viper.BindPFlags(TxFlags)
 	TxFlags.AddFlagSet(TxFileFlags)
 	TxFlags.AddFlagSet(cmdFlags.DebugTestEntityFlags)
@@ -12,7 +12,6 @@ import (
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 
-	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
@@ -75,7 +74,9 @@ func doConnect(cmd *cobra.Command) (*grpc.ClientConn, consensus.ClientBackend) {
 	return conn, client
 }
 
-func loadTx() *transaction.SignedTransaction {
+// loadTx loads a pre-signed transaction which can either be a regular signed transaction or a
+// transaction signed by a multisig account. Exactly one of the returned values is non-nil.
+func loadTx() (*transaction.SignedTransaction, *transaction.MultiSignedTransaction) {
 	rawTx, err := ioutil.ReadFile(viper.GetString(cmdConsensus.CfgTxFile))
 	if err != nil {
 		logger.Error("failed to read raw serialized transaction",
@@ -84,6 +85,21 @@ func loadTx() *transaction.SignedTransaction {
 		os.Exit(1)
 	}
 
+	// Check whether this is a multisig transaction envelope.
+	var envelope struct {
+		Account *transaction.MultisigAccount `json:"account"`
+	}
+	if err = json.Unmarshal(rawTx, &envelope); err == nil && envelope.Account != nil {
+		var multiSigTx transaction.MultiSignedTransaction
+		if err = json.Unmarshal(rawTx, &multiSigTx); err != nil {
+			logger.Error("failed to parse serialized multisig transaction",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+		return nil, &multiSigTx
+	}
+
 	var tx transaction.SignedTransaction
 	if err = json.Unmarshal(rawTx, &tx); err != nil {
 		logger.Error("failed to parse serialized transaction",
@@ -92,27 +108,19 @@ func loadTx() *transaction.SignedTransaction {
 		os.Exit(1)
 	}
 
-	return &tx
+	return &tx, nil
 }
 
 func loadUnsignedTx() *transaction.Transaction {
-	rawUnsignedTx, err := ioutil.ReadFile(viper.GetString(cmdConsensus.CfgTxFile))
+	ut, err := cmdConsensus.LoadUnsignedTx(viper.GetString(cmdConsensus.CfgTxFile))
 	if err != nil {
-		logger.Error("failed to read raw serialized unsigned transaction",
+		logger.Error("failed to load unsigned transaction",
 			"err", err,
 		)
 		os.Exit(1)
 	}
 
-	var tx transaction.Transaction
-	if err = cbor.Unmarshal(rawUnsignedTx, &tx); err != nil {
-		logger.Error("failed to parse serialized unsigned transaction",
-			"err", err,
-		)
-		os.Exit(1)
-	}
-
-	return &tx
+	return &ut.Transaction
 }
 
 func doSubmitTx(cmd *cobra.Command, args []string) {
@@ -123,9 +131,14 @@ func doSubmitTx(cmd *cobra.Command, args []string) {
 	conn, client := doConnect(cmd)
 	defer conn.Close()
 
-	tx := loadTx()
-
-	if err := client.SubmitTx(context.Background(), tx); err != nil {
+	var err error
+	switch tx, multiSigTx := loadTx(); {
+	case tx != nil:
+		err = client.SubmitTx(context.Background(), tx)
+	default:
+		err = client.SubmitMultiSignedTx(context.Background(), multiSigTx)
+	}
+	if err != nil {
 		logger.Error("failed to submit transaction",
 			"err", err,
 		)
@@ -145,8 +158,12 @@ func doShowTx(cmd *cobra.Command, args []string) {
 	ctx = context.WithValue(ctx, prettyprint.ContextKeyTokenValueExponent, genesis.Staking.TokenValueExponent)
 	ctx = context.WithValue(ctx, prettyprint.ContextKeyGenesisHash, genesis.Hash())
 
-	sigTx := loadTx()
-	sigTx.PrettyPrint(ctx, "", os.Stdout)
+	switch sigTx, multiSigTx := loadTx(); {
+	case sigTx != nil:
+		sigTx.PrettyPrint(ctx, "", os.Stdout)
+	default:
+		multiSigTx.PrettyPrint(ctx, "", os.Stdout)
+	}
 }
 
 func doEstimateGas(cmd *cobra.Command, args []string) {
@@ -226,5 +243,7 @@ func Register(parentCmd *cobra.Command) {
 
 	nextBlockStateCmd.Flags().AddFlagSet(cmdGrpc.ClientFlags)
 
+	registerMultisig(consensusCmd)
+
 	parentCmd.AddCommand(consensusCmd)
 }
@@ -0,0 +1,281 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdConsensus "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/consensus"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+	cmdSigner "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/signer"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+const (
+	// CfgMultisigSigner configures the public key of a multisig account signer.
+	CfgMultisigSigner = "multisig.signer"
+
+	// CfgMultisigThreshold configures the multisig account threshold.
+	CfgMultisigThreshold = "multisig.threshold"
+
+	// CfgMultisigOutputFile configures the filename for the resulting multisig transaction.
+	CfgMultisigOutputFile = "multisig.output_file"
+)
+
+var (
+	multisigAccountFlags = flag.NewFlagSet("", flag.ContinueOnError)
+	multisigOutputFlags  = flag.NewFlagSet("", flag.ContinueOnError)
+
+	multisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "multisig account and transaction commands",
+	}
+
+	multisigAddressCmd = &cobra.Command{
+		Use:   "address",
+		Short: "Show the staking account address of a multisig account",
+		Run:   doMultisigAddress,
+	}
+
+	multisigInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Create an unsigned multisig transaction from an unsigned transaction",
+		Run:   doMultisigInit,
+	}
+
+	multisigSignCmd = &cobra.Command{
+		Use:   "sign",
+		Short: "Add a signature to a multisig transaction",
+		Run:   doMultisigSign,
+	}
+
+	multisigCombineCmd = &cobra.Command{
+		Use:   "combine <tx-file> [<tx-file>...]",
+		Short: "Combine signatures of partially signed multisig transactions",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   doMultisigCombine,
+	}
+)
+
+func loadMultisigAccount() *transaction.MultisigAccount {
+	var account transaction.MultisigAccount
+	for _, v := range viper.GetStringSlice(CfgMultisigSigner) {
+		var pk signature.PublicKey
+		if err := pk.UnmarshalText([]byte(v)); err != nil {
+			logger.Error("failed to unmarshal multisig signer public key",
+				"err", err,
+				"signer", v,
+			)
+			os.Exit(1)
+		}
+		account.Signers = append(account.Signers, pk)
+	}
+	account.Threshold = uint8(viper.GetUint(CfgMultisigThreshold))
+
+	if err := account.ValidateBasic(); err != nil {
+		logger.Error("invalid multisig account",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	return &account
+}
+
+func loadMultiSignedTx(fn string) *transaction.MultiSignedTransaction {
+	rawTx, err := ioutil.ReadFile(fn)
+	if err != nil {
+		logger.Error("failed to read multisig transaction",
+			"err", err,
+			"file", fn,
+		)
+		os.Exit(1)
+	}
+
+	var tx transaction.MultiSignedTransaction
+	if err = json.Unmarshal(rawTx, &tx); err != nil {
+		logger.Error("failed to parse multisig transaction",
+			"err", err,
+			"file", fn,
+		)
+		os.Exit(1)
+	}
+	if err = tx.Account.ValidateBasic(); err != nil {
+		logger.Error("invalid multisig account",
+			"err", err,
+			"file", fn,
+		)
+		os.Exit(1)
+	}
+
+	return &tx
+}
+
+func saveMultiSignedTx(tx *transaction.MultiSignedTransaction) {
+	prettyTx, err := cmdCommon.PrettyJSONMarshal(tx)
+	if err != nil {
+		logger.Error("failed to get pretty JSON of multisig transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile(viper.GetString(CfgMultisigOutputFile), prettyTx, 0o600); err != nil {
+		logger.Error("failed to save multisig transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+}
+
+func assertMultisigOutputFileOK() {
+	if viper.GetString(CfgMultisigOutputFile) == "" {
+		logger.Error("failed to determine multisig output file")
+		os.Exit(1)
+	}
+}
+
+func doMultisigAddress(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	account := loadMultisigAccount()
+	fmt.Println(staking.NewMultisigAddress(account))
+}
+
+func doMultisigInit(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	assertMultisigOutputFileOK()
+
+	account := loadMultisigAccount()
+	tx := loadUnsignedTx()
+
+	multiSigTx, err := transaction.NewMultiSignedTransaction(account, tx)
+	if err != nil {
+		logger.Error("failed to create multisig transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	saveMultiSignedTx(multiSigTx)
+}
+
+func doMultisigSign(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	assertMultisigOutputFileOK()
+
+	genesis := cmdConsensus.InitGenesis()
+
+	multiSigTx := loadMultiSignedTx(viper.GetString(cmdConsensus.CfgTxFile))
+	var tx transaction.Transaction
+	if err := cbor.Unmarshal(multiSigTx.Blob, &tx); err != nil {
+		logger.Error("failed to parse multisig transaction body",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	_, signer, err := cmdCommon.LoadEntitySigner()
+	if err != nil {
+		logger.Error("failed to load signer",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	defer signer.Reset()
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, prettyprint.ContextKeyTokenSymbol, genesis.Staking.TokenSymbol)
+	ctx = context.WithValue(ctx, prettyprint.ContextKeyTokenValueExponent, genesis.Staking.TokenValueExponent)
+	ctx = context.WithValue(ctx, prettyprint.ContextKeyGenesisHash, genesis.Hash())
+	cmdConsensus.ConfirmSignTx(ctx, &tx)
+
+	if err = multiSigTx.Sign(signer); err != nil {
+		logger.Error("failed to sign multisig transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	saveMultiSignedTx(multiSigTx)
+}
+
+func doMultisigCombine(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	assertMultisigOutputFileOK()
+
+	combined := loadMultiSignedTx(args[0])
+	for _, fn := range args[1:] {
+		if err := combined.Combine(loadMultiSignedTx(fn)); err != nil {
+			logger.Error("failed to combine multisig transactions",
+				"err", err,
+				"file", fn,
+			)
+			os.Exit(1)
+		}
+	}
+
+	if len(combined.Signatures) < int(combined.Account.Threshold) {
+		fmt.Printf("Combined transaction has %d of the %d required signatures.\n",
+			len(combined.Signatures),
+			combined.Account.Threshold,
+		)
+	}
+	saveMultiSignedTx(combined)
+}
+
+func registerMultisig(parentCmd *cobra.Command) {
+	for _, v := range []*cobra.Command{
+		multisigAddressCmd,
+		multisigInitCmd,
+		multisigSignCmd,
+		multisigCombineCmd,
+	} {
+		multisigCmd.AddCommand(v)
+	}
+
+	multisigAddressCmd.Flags().AddFlagSet(multisigAccountFlags)
+
+	multisigInitCmd.Flags().AddFlagSet(multisigAccountFlags)
+	multisigInitCmd.Flags().AddFlagSet(multisigOutputFlags)
+	multisigInitCmd.Flags().AddFlagSet(cmdConsensus.TxFileFlags)
+
+	multisigSignCmd.Flags().AddFlagSet(multisigOutputFlags)
+	multisigSignCmd.Flags().AddFlagSet(cmdConsensus.TxFileFlags)
+	multisigSignCmd.Flags().AddFlagSet(cmdFlags.DebugTestEntityFlags)
+	multisigSignCmd.Flags().AddFlagSet(cmdFlags.AssumeYesFlag)
+	multisigSignCmd.Flags().AddFlagSet(cmdSigner.Flags)
+	multisigSignCmd.Flags().AddFlagSet(cmdSigner.CLIFlags)
+	multisigSignCmd.Flags().AddFlagSet(cmdFlags.GenesisFileFlags)
+
+	multisigCombineCmd.Flags().AddFlagSet(multisigOutputFlags)
+
+	parentCmd.AddCommand(multisigCmd)
+}
+
+func init() {
+	multisigAccountFlags.StringSlice(CfgMultisigSigner, nil, "public key of a multisig account signer (can be specified multiple times)")
+	multisigAccountFlags.Uint8(CfgMultisigThreshold, 1, "minimum number of signers required to authorize a transaction")
+	_ = viper.BindPFlags(multisigAccountFlags)
+
+	multisigOutputFlags.String(CfgMultisigOutputFile, "", "path to the resulting multisig transaction")
+	_ = viper.BindPFlags(multisigOutputFlags)
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	control "github.com/oasisprotocol/oasis-core/go/control/api"
 	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
@@ -19,7 +21,8 @@ import (
 )
 
 var (
-	shutdownWait = false
+	shutdownWait        = false
+	runtimeSGXSignature string
 
 	controlCmd = &cobra.Command{
 		Use:   "control",
@@ -63,6 +66,43 @@ var (
 		Run:   doStatus,
 	}
 
+	controlPausePruningCmd = &cobra.Command{
+		Use:   "pause-pruning",
+		Short: "pause consensus state pruning (e.g., while creating checkpoints)",
+		Run:   doPausePruning,
+	}
+
+	controlResumePruningCmd = &cobra.Command{
+		Use:   "resume-pruning",
+		Short: "resume previously paused consensus state pruning",
+		Run:   doResumePruning,
+	}
+
+	controlRuntimeCmd = &cobra.Command{
+		Use:   "runtime",
+		Short: "hosted runtime management",
+	}
+
+	controlRuntimeAddCmd = &cobra.Command{
+		Use:   "add <runtime-id> <runtime-path>",
+		Short: "start hosting a runtime without restarting the node",
+		Args:  cobra.ExactArgs(2),
+		Run:   doRuntimeAdd,
+	}
+
+	controlRuntimeRemoveCmd = &cobra.Command{
+		Use:   "remove <runtime-id>",
+		Short: "stop hosting a runtime without restarting the node",
+		Args:  cobra.ExactArgs(1),
+		Run:   doRuntimeRemove,
+	}
+
+	controlRuntimeListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list hosted runtimes",
+		Run:   doRuntimeList,
+	}
+
 	logger = logging.GetLogger("cmd/control")
 )
 
@@ -230,6 +270,105 @@ func doStatus(cmd *cobra.Command, args []string) {
 	fmt.Println(string(prettyStatus))
 }
 
+func doPauseConsensusPruning(cmd *cobra.Command, pause bool) {
+	conn, client := DoConnect(cmd)
+	defer conn.Close()
+
+	logger.Debug("setting consensus pruning paused status",
+		"paused", pause,
+	)
+
+	if err := client.PauseConsensusPruning(context.Background(), pause); err != nil {
+		logger.Error("failed to set consensus pruning paused status",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+}
+
+func doPausePruning(cmd *cobra.Command, args []string) {
+	doPauseConsensusPruning(cmd, true)
+}
+
+func doResumePruning(cmd *cobra.Command, args []string) {
+	doPauseConsensusPruning(cmd, false)
+}
+
+func parseRuntimeID(raw string) common.Namespace {
+	var runtimeID common.Namespace
+	if err := runtimeID.UnmarshalHex(raw); err != nil {
+		logger.Error("malformed runtime identifier",
+			"err", err,
+			"runtime_id", raw,
+		)
+		os.Exit(1)
+	}
+	return runtimeID
+}
+
+func doRuntimeAdd(cmd *cobra.Command, args []string) {
+	conn, client := DoConnect(cmd)
+	defer conn.Close()
+
+	req := &control.AddRuntimeRequest{
+		RuntimeID:        parseRuntimeID(args[0]),
+		Path:             args[1],
+		SGXSignaturePath: runtimeSGXSignature,
+	}
+
+	logger.Debug("adding runtime",
+		"runtime_id", req.RuntimeID,
+		"path", req.Path,
+	)
+
+	if err := client.AddRuntime(context.Background(), req); err != nil {
+		logger.Error("failed to add runtime",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+}
+
+func doRuntimeRemove(cmd *cobra.Command, args []string) {
+	conn, client := DoConnect(cmd)
+	defer conn.Close()
+
+	runtimeID := parseRuntimeID(args[0])
+
+	logger.Debug("removing runtime",
+		"runtime_id", runtimeID,
+	)
+
+	if err := client.RemoveRuntime(context.Background(), runtimeID); err != nil {
+		logger.Error("failed to remove runtime",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+}
+
+func doRuntimeList(cmd *cobra.Command, args []string) {
+	conn, client := DoConnect(cmd)
+	defer conn.Close()
+
+	status, err := client.GetStatus(context.Background())
+	if err != nil {
+		logger.Error("failed to query status",
+			"err", err,
+		)
+		os.Exit(128)
+	}
+
+	runtimeIDs := make([]string, 0, len(status.Runtimes))
+	for id := range status.Runtimes {
+		runtimeIDs = append(runtimeIDs, id.String())
+	}
+	sort.Strings(runtimeIDs)
+	for _, id := range runtimeIDs {
+		fmt.Println(id)
+	}
+}
+
 // Register registers the client sub-command and all of it's children.
 func Register(parentCmd *cobra.Command) {
 	controlCmd.PersistentFlags().AddFlagSet(cmdGrpc.ClientFlags)
@@ -242,5 +381,14 @@ func Register(parentCmd *cobra.Command) {
 	controlCmd.AddCommand(controlUpgradeBinaryCmd)
 	controlCmd.AddCommand(controlCancelUpgradeCmd)
 	controlCmd.AddCommand(controlStatusCmd)
+	controlCmd.AddCommand(controlPausePruningCmd)
+	controlCmd.AddCommand(controlResumePruningCmd)
+
+	controlRuntimeAddCmd.Flags().StringVar(&runtimeSGXSignature, "sgx-signature", "", "path to the SGX signature of the runtime binary")
+	controlRuntimeCmd.AddCommand(controlRuntimeAddCmd)
+	controlRuntimeCmd.AddCommand(controlRuntimeRemoveCmd)
+	controlRuntimeCmd.AddCommand(controlRuntimeListCmd)
+	controlCmd.AddCommand(controlRuntimeCmd)
+
 	parentCmd.AddCommand(controlCmd)
 }
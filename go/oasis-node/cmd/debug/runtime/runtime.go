@@ -0,0 +1,205 @@
+// Package runtime implements the runtime debug sub-commands.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common/diff"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/sandbox/process"
+)
+
+const (
+	cfgReplayRuntime   = "replay.runtime"
+	cfgReplayRecording = "replay.recording"
+	cfgReplayTimeout   = "replay.timeout"
+
+	runtimeConnectTimeout = 5 * time.Second
+)
+
+var (
+	runtimeCmd = &cobra.Command{
+		Use:   "runtime",
+		Short: "debug runtimes",
+	}
+
+	replayCmd = &cobra.Command{
+		Use:   "replay",
+		Short: "replay a recorded runtime host protocol session against a runtime binary",
+		Run:   doReplay,
+	}
+
+	replayFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	logger = logging.GetLogger("cmd/debug/runtime")
+)
+
+func startRuntime(runtimePath string) (process.Process, net.Conn, error) {
+	runtimeDir, err := ioutil.TempDir("", "oasis-runtime-replay")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(runtimeDir)
+
+	hostSocket := filepath.Join(runtimeDir, "host.sock")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: hostSocket})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create host socket: %w", err)
+	}
+	defer listener.Close()
+
+	p, err := process.NewNaked(process.Config{
+		Path: runtimePath,
+		Env: map[string]string{
+			"OASIS_WORKER_HOST": hostSocket,
+		},
+		// Keep the standard output free for the replay report.
+		Stdout: os.Stderr,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start runtime: %w", err)
+	}
+
+	if err = listener.SetDeadline(time.Now().Add(runtimeConnectTimeout)); err != nil {
+		p.Kill()
+		return nil, nil, fmt.Errorf("failed to set listener deadline: %w", err)
+	}
+	conn, err := listener.Accept()
+	if err != nil {
+		p.Kill()
+		return nil, nil, fmt.Errorf("error while accepting runtime connection: %w", err)
+	}
+	return p, conn, nil
+}
+
+func printMismatch(mismatch *protocol.ReplayMismatch) error {
+	kind := "unknown"
+	if mismatch.Request != nil {
+		kind = mismatch.Request.Body.Type()
+	}
+	fmt.Printf("mismatch: %s (%s)\n", mismatch.Reason, kind)
+
+	expected, err := cmdCommon.PrettyJSONMarshal(mismatch.Expected)
+	if err != nil {
+		return fmt.Errorf("failed to marshal expected body: %w", err)
+	}
+	actual, err := cmdCommon.PrettyJSONMarshal(mismatch.Actual)
+	if err != nil {
+		return fmt.Errorf("failed to marshal actual body: %w", err)
+	}
+	diffStr, err := diff.UnifiedDiffString(string(actual), string(expected), "Replayed", "Recorded")
+	if err != nil {
+		return err
+	}
+	fmt.Println(diffStr)
+	return nil
+}
+
+func doReplay(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	runtimePath := viper.GetString(cfgReplayRuntime)
+	recordingPath := viper.GetString(cfgReplayRecording)
+	if runtimePath == "" || recordingPath == "" {
+		logger.Error("both the runtime binary and the recording must be set")
+		return
+	}
+
+	// Load the recording.
+	f, err := os.Open(recordingPath)
+	if err != nil {
+		logger.Error("failed to open recording",
+			"err", err,
+		)
+		return
+	}
+	msgs, err := protocol.NewRecordingReader(f).ReadAll()
+	f.Close()
+	if err != nil {
+		logger.Error("failed to read recording",
+			"err", err,
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration(cfgReplayTimeout))
+	defer cancel()
+
+	// Start the runtime and replay the recording.
+	p, conn, err := startRuntime(runtimePath)
+	if err != nil {
+		logger.Error("failed to start runtime",
+			"err", err,
+		)
+		return
+	}
+	defer func() {
+		conn.Close()
+		p.Kill()
+		<-p.Wait()
+	}()
+
+	logger.Info("replaying recording",
+		"recording", recordingPath,
+		"messages", len(msgs),
+	)
+
+	result, err := protocol.Replay(ctx, conn, msgs)
+	if err != nil {
+		logger.Error("failed to replay recording",
+			"err", err,
+		)
+		return
+	}
+
+	for _, mismatch := range result.Mismatches {
+		if err = printMismatch(mismatch); err != nil {
+			logger.Error("failed to print mismatch",
+				"err", err,
+			)
+			return
+		}
+	}
+	fmt.Printf("replayed %d host requests, answered %d runtime requests, %d mismatches\n",
+		result.HostRequests,
+		result.RuntimeRequests,
+		len(result.Mismatches),
+	)
+
+	ok = len(result.Mismatches) == 0
+}
+
+// Register registers the runtime sub-command and all of it's children.
+func Register(parentCmd *cobra.Command) {
+	replayCmd.Flags().AddFlagSet(replayFlags)
+	runtimeCmd.AddCommand(replayCmd)
+	parentCmd.AddCommand(runtimeCmd)
+}
+
+func init() {
+	replayFlags.String(cfgReplayRuntime, "", "path to the runtime binary")
+	replayFlags.String(cfgReplayRecording, "", "path to the runtime host protocol recording")
+	replayFlags.Duration(cfgReplayTimeout, 5*time.Minute, "replay timeout")
+	_ = viper.BindPFlags(replayFlags)
+}
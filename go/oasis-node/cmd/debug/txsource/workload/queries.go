@@ -213,14 +213,20 @@ func (q *queries) doConsensusQueries(ctx context.Context, rng *rand.Rand, height
 	if block.Height != height {
 		return fmt.Errorf("block.Height: %d == %d violated", block.Height, height)
 	}
-	if params := q.epochtimeParams.InsecureParameters; params != nil && !q.epochtimeParams.DebugMockBackend {
-		expectedEpoch := beacon.EpochTime(block.Height / params.Interval)
-		if expectedEpoch != epoch {
+	if !q.epochtimeParams.DebugMockBackend && !q.queryingEarliest && height > 1 {
+		// Epoch heights are not necessarily aligned to the epoch interval
+		// (e.g., when time-based epoch intervals are used), so only make
+		// sure that epochs advance by at most one per block.
+		var prevEpoch beacon.EpochTime
+		prevEpoch, err = q.beacon.GetEpoch(ctx, height-1)
+		if err != nil {
+			return fmt.Errorf("GetEpoch at height %d: %w", height-1, err)
+		}
+		if epoch != prevEpoch && epoch != prevEpoch+1 {
 			q.logger.Error("invalid epoch",
-				"expected", expectedEpoch,
+				"previous_epoch", prevEpoch,
 				"epoch", epoch,
 				"height", block.Height,
-				"epoch_interval", params.Interval,
 			)
 			return fmt.Errorf("invalid epoch: %d", epoch)
 		}
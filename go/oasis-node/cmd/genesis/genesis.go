@@ -57,12 +57,13 @@ const (
 	cfgInitialHeight = "initial_height"
 
 	// Registry config flags.
-	CfgRegistryMaxNodeExpiration             = "registry.max_node_expiration"
-	CfgRegistryDisableRuntimeRegistration    = "registry.disable_runtime_registration"
-	cfgRegistryDebugAllowUnroutableAddresses = "registry.debug.allow_unroutable_addresses"
-	CfgRegistryDebugAllowTestRuntimes        = "registry.debug.allow_test_runtimes"
-	cfgRegistryDebugBypassStake              = "registry.debug.bypass_stake" // nolint: gosec
-	cfgRegistryEnableRuntimeGovernanceModels = "registry.enable_runtime_governance_models"
+	CfgRegistryMaxNodeExpiration              = "registry.max_node_expiration"
+	CfgRegistryDisableRuntimeRegistration     = "registry.disable_runtime_registration"
+	cfgRegistryDebugAllowUnroutableAddresses  = "registry.debug.allow_unroutable_addresses"
+	CfgRegistryDebugAllowTestRuntimes         = "registry.debug.allow_test_runtimes"
+	cfgRegistryDebugBypassStake               = "registry.debug.bypass_stake" // nolint: gosec
+	cfgRegistryEnableRuntimeGovernanceModels  = "registry.enable_runtime_governance_models"
+	CfgRegistryEnableRuntimeVersionScheduling = "registry.enable_runtime_version_scheduling"
 
 	// Scheduler config flags.
 	cfgSchedulerMinValidators          = "scheduler.min_validators"
@@ -86,6 +87,8 @@ const (
 	CfgBeaconVRFAlphaThreshold          = "beacon.vrf.alpha_threshold"
 	CfgBeaconVRFInterval                = "beacon.vrf.interval"
 	CfgBeaconVRFProofSubmissionDelay    = "beacon.vrf.submission_delay"
+	CfgBeaconVRFTargetEpochDuration     = "beacon.vrf.target_epoch_duration"
+	CfgBeaconVRFMaxIntervalAdjustment   = "beacon.vrf.max_interval_adjustment"
 
 	// Roothash config flags.
 	cfgRoothashDebugDoNotSuspendRuntimes = "roothash.debug.do_not_suspend_runtimes"
@@ -318,8 +321,12 @@ func doInitGenesis(cmd *cobra.Command, args []string) {
 			AlphaHighQualityThreshold: viper.GetUint64(CfgBeaconVRFAlphaThreshold),
 			Interval:                  viper.GetInt64(CfgBeaconVRFInterval),
 			ProofSubmissionDelay:      viper.GetInt64(CfgBeaconVRFProofSubmissionDelay),
+			TargetEpochDuration:       viper.GetDuration(CfgBeaconVRFTargetEpochDuration),
 			GasCosts:                  beacon.DefaultVRFGasCosts, // TODO: configurable.
 		}
+		if doc.Beacon.Parameters.VRFParameters.IsTimeBased() {
+			doc.Beacon.Parameters.VRFParameters.MaxIntervalAdjustment = uint8(viper.GetUint(CfgBeaconVRFMaxIntervalAdjustment))
+		}
 	default:
 		logger.Error("unsupported beacon backend",
 			"backend", doc.Beacon.Parameters.Backend,
@@ -385,13 +392,14 @@ func doInitGenesis(cmd *cobra.Command, args []string) {
 func AppendRegistryState(doc *genesis.Document, entities, runtimes, nodes []string, l *logging.Logger) error {
 	regSt := registry.Genesis{
 		Parameters: registry.ConsensusParameters{
-			DebugAllowUnroutableAddresses: viper.GetBool(cfgRegistryDebugAllowUnroutableAddresses),
-			DebugAllowTestRuntimes:        viper.GetBool(CfgRegistryDebugAllowTestRuntimes),
-			DebugBypassStake:              viper.GetBool(cfgRegistryDebugBypassStake),
-			GasCosts:                      registry.DefaultGasCosts, // TODO: Make these configurable.
-			MaxNodeExpiration:             viper.GetUint64(CfgRegistryMaxNodeExpiration),
-			DisableRuntimeRegistration:    viper.GetBool(CfgRegistryDisableRuntimeRegistration),
-			EnableRuntimeGovernanceModels: make(map[registry.RuntimeGovernanceModel]bool),
+			DebugAllowUnroutableAddresses:  viper.GetBool(cfgRegistryDebugAllowUnroutableAddresses),
+			DebugAllowTestRuntimes:         viper.GetBool(CfgRegistryDebugAllowTestRuntimes),
+			DebugBypassStake:               viper.GetBool(cfgRegistryDebugBypassStake),
+			GasCosts:                       registry.DefaultGasCosts, // TODO: Make these configurable.
+			MaxNodeExpiration:              viper.GetUint64(CfgRegistryMaxNodeExpiration),
+			DisableRuntimeRegistration:     viper.GetBool(CfgRegistryDisableRuntimeRegistration),
+			EnableRuntimeGovernanceModels:  make(map[registry.RuntimeGovernanceModel]bool),
+			EnableRuntimeVersionScheduling: viper.GetBool(CfgRegistryEnableRuntimeVersionScheduling),
 		},
 		Entities: make([]*entity.SignedEntity, 0, len(entities)),
 		Runtimes: make([]*registry.Runtime, 0, len(runtimes)),
@@ -813,6 +821,7 @@ func init() {
 	initGenesisFlags.Bool(CfgRegistryDebugAllowTestRuntimes, false, "enable test runtime registration")
 	initGenesisFlags.Bool(cfgRegistryDebugBypassStake, false, "bypass all stake checks and operations (UNSAFE)")
 	initGenesisFlags.StringSlice(cfgRegistryEnableRuntimeGovernanceModels, []string{"entity"}, "set of enabled runtime governance models")
+	initGenesisFlags.Bool(CfgRegistryEnableRuntimeVersionScheduling, false, "enable scheduled runtime version upgrades")
 	_ = initGenesisFlags.MarkHidden(cfgRegistryDebugAllowUnroutableAddresses)
 	_ = initGenesisFlags.MarkHidden(CfgRegistryDebugAllowTestRuntimes)
 	_ = initGenesisFlags.MarkHidden(cfgRegistryDebugBypassStake)
@@ -842,6 +851,8 @@ func init() {
 	initGenesisFlags.Uint64(CfgBeaconVRFAlphaThreshold, 1, "Number of proofs required to allow runtime elections")
 	initGenesisFlags.Int64(CfgBeaconVRFInterval, 86300, "Epoch interval (in blocks)")
 	initGenesisFlags.Int64(CfgBeaconVRFProofSubmissionDelay, 43150, "Proof submission delay (in blocks)")
+	initGenesisFlags.Duration(CfgBeaconVRFTargetEpochDuration, 0, "Target epoch duration (0 to use a fixed epoch interval)")
+	initGenesisFlags.Uint8(CfgBeaconVRFMaxIntervalAdjustment, 10, "Maximum epoch interval adjustment between epochs (in percent, only used with a target epoch duration)")
 	_ = initGenesisFlags.MarkHidden(CfgBeaconDebugMockBackend)
 
 	// Roothash config flags.
@@ -13,6 +13,8 @@ import (
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
@@ -23,12 +25,17 @@ import (
 	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
 	cmdSigner "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/signer"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 	upgrade "github.com/oasisprotocol/oasis-core/go/upgrade/api"
 )
 
 const (
 	cfgProposalCancelUpgradeID   = "proposal.cancel_upgrade.id"
 	cfgProposalUpgradeDescriptor = "proposal.upgrade.descriptor"
+	cfgProposalChangeParameters  = "change-parameters"
 
 	cfgVote           = "vote"
 	cfgVoteProposalID = "vote.proposal.id"
@@ -143,9 +150,42 @@ func doGenSubmitProposal(cmd *cobra.Command, args []string) {
 				ProposalID: viper.GetUint64(cfgProposalCancelUpgradeID),
 			},
 		})
+	// Change parameters.
+	case viper.GetString(cfgProposalChangeParameters) != "":
+		proposalBytes, err := ioutil.ReadFile(viper.GetString(cfgProposalChangeParameters))
+		if err != nil {
+			logger.Error("failed to read change parameters proposal",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+
+		var proposal changeParametersProposal
+		if err = json.Unmarshal(proposalBytes, &proposal); err != nil {
+			logger.Error("can't parse change parameters proposal",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+
+		changes, err := parseParameterChanges(proposal.Module, proposal.Changes)
+		if err != nil {
+			logger.Error("submitted consensus parameter changes are not valid",
+				"err", err,
+				"module", proposal.Module,
+			)
+			os.Exit(1)
+		}
+
+		tx = governance.NewSubmitProposalTx(nonce, fee, &governance.ProposalContent{
+			ChangeParameters: &governance.ChangeParametersProposal{
+				Module:  proposal.Module,
+				Changes: changes,
+			},
+		})
 	default:
-		logger.Error(fmt.Sprintf("missing required arguments: either '%v' or '%v' required",
-			cfgProposalUpgradeDescriptor, cfgProposalCancelUpgradeID,
+		logger.Error(fmt.Sprintf("missing required arguments: either '%v', '%v' or '%v' required",
+			cfgProposalUpgradeDescriptor, cfgProposalCancelUpgradeID, cfgProposalChangeParameters,
 		))
 		os.Exit(1)
 	}
@@ -153,6 +193,50 @@ func doGenSubmitProposal(cmd *cobra.Command, args []string) {
 	cmdConsensus.SignAndSaveTx(cmdContext.GetCtxWithGenesisInfo(genesis), tx, nil)
 }
 
+// changeParametersProposal is the JSON representation of a change parameters proposal with
+// human-readable parameter changes.
+type changeParametersProposal struct {
+	// Module is the name of the module whose parameters should be changed.
+	Module string `json:"module"`
+	// Changes are the JSON-encoded consensus parameter changes of the module.
+	Changes json.RawMessage `json:"changes"`
+}
+
+// parameterChanges is the interface implemented by consensus parameter changes of all modules.
+type parameterChanges interface {
+	SanityCheck() error
+}
+
+// parseParameterChanges parses JSON-encoded consensus parameter changes for the given module and
+// returns their CBOR encoding.
+func parseParameterChanges(module string, raw []byte) (cbor.RawMessage, error) {
+	var changes parameterChanges
+	switch module {
+	case staking.ModuleName:
+		changes = &staking.ConsensusParameterChanges{}
+	case registry.ModuleName:
+		changes = &registry.ConsensusParameterChanges{}
+	case scheduler.ModuleName:
+		changes = &scheduler.ConsensusParameterChanges{}
+	case roothash.ModuleName:
+		changes = &roothash.ConsensusParameterChanges{}
+	case governance.ModuleName:
+		changes = &governance.ConsensusParameterChanges{}
+	case beacon.ModuleName:
+		changes = &beacon.ConsensusParameterChanges{}
+	default:
+		return nil, fmt.Errorf("unsupported module: %s", module)
+	}
+
+	if err := json.Unmarshal(raw, changes); err != nil {
+		return nil, fmt.Errorf("can't parse consensus parameter changes: %w", err)
+	}
+	if err := changes.SanityCheck(); err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(changes), nil
+}
+
 func doGenCastVote(cmd *cobra.Command, args []string) {
 	if err := cmdCommon.Init(); err != nil {
 		cmdCommon.EarlyLogAndExit(err)
@@ -312,6 +396,7 @@ func init() {
 
 	submitProposalFlags.String(cfgProposalUpgradeDescriptor, "", "Path to the proposal upgrade descriptor")
 	submitProposalFlags.Uint64(cfgProposalCancelUpgradeID, 0, "Cancel upgrade proposal ID")
+	submitProposalFlags.String(cfgProposalChangeParameters, "", "Path to the change parameters proposal (JSON with the module name and its consensus parameter changes)")
 	_ = viper.BindPFlags(submitProposalFlags)
 	submitProposalFlags.AddFlagSet(cmdConsensus.TxFlags)
 	submitProposalFlags.AddFlagSet(cmdFlags.AssumeYesFlag)
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
@@ -9,8 +10,10 @@ import (
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	control "github.com/oasisprotocol/oasis-core/go/control/api"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	upgrade "github.com/oasisprotocol/oasis-core/go/upgrade/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 )
 
@@ -147,6 +150,7 @@ func (n *Node) GetRuntimeStatus(ctx context.Context) (map[common.Namespace]contr
 
 		// Fetch common committee worker status.
 		if rtNode := n.CommonWorker.GetRuntime(rt.ID()); rtNode != nil {
+			status.Degraded = rtNode.IsRuntimeDegraded()
 			status.Committee, err = rtNode.GetStatus(ctx)
 			if err != nil {
 				n.logger.Error("failed to fetch common committee worker status",
@@ -176,3 +180,122 @@ func (n *Node) GetRuntimeStatus(ctx context.Context) (map[common.Namespace]contr
 func (n *Node) GetPendingUpgrades(ctx context.Context) ([]*upgrade.PendingUpgrade, error) {
 	return n.Upgrader.PendingUpgrades(ctx)
 }
+
+func (n *Node) checkRuntimeControl() error {
+	select {
+	case <-n.readyCh:
+	default:
+		return fmt.Errorf("node is not ready")
+	}
+
+	// Seed node doesn't have a runtime registry.
+	if n.RuntimeRegistry == nil || !n.CommonWorker.Enabled() {
+		return fmt.Errorf("node does not support runtimes")
+	}
+	return nil
+}
+
+// Implements control.ControlledNode.
+func (n *Node) AddRuntime(ctx context.Context, req *control.AddRuntimeRequest) (rerr error) {
+	n.runtimesLock.Lock()
+	defer n.runtimesLock.Unlock()
+
+	if err := n.checkRuntimeControl(); err != nil {
+		return err
+	}
+
+	n.logger.Info("adding runtime",
+		"runtime_id", req.RuntimeID,
+		"path", req.Path,
+	)
+
+	hostCfg := runtimeRegistry.NewRuntimeHostConfig(req.RuntimeID, req.Path, req.SGXSignaturePath, nil)
+	// The runtime must outlive the request so use the node's context.
+	rt, err := n.RuntimeRegistry.AddRuntime(n.svcMgr.Ctx, hostCfg)
+	if err != nil {
+		return fmt.Errorf("failed to add runtime to registry: %w", err)
+	}
+	defer func() {
+		if rerr == nil {
+			return
+		}
+		if err := n.removeRuntime(ctx, req.RuntimeID); err != nil {
+			n.logger.Error("failed to roll back runtime addition",
+				"err", err,
+				"runtime_id", req.RuntimeID,
+			)
+		}
+	}()
+
+	err = n.CommonWorker.AddRuntime(rt, func(commonNode *committee.Node) error {
+		if err := n.StorageWorker.AddRuntime(commonNode); err != nil {
+			return fmt.Errorf("failed to add runtime to storage worker: %w", err)
+		}
+		if err := n.ExecutorWorker.AddRuntime(commonNode); err != nil {
+			return fmt.Errorf("failed to add runtime to executor worker: %w", err)
+		}
+		if err := n.ClientWorker.AddRuntime(commonNode); err != nil {
+			return fmt.Errorf("failed to add runtime to client worker: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start runtime workers: %w", err)
+	}
+
+	n.logger.Info("runtime added",
+		"runtime_id", req.RuntimeID,
+	)
+
+	return nil
+}
+
+// Implements control.ControlledNode.
+func (n *Node) RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	n.runtimesLock.Lock()
+	defer n.runtimesLock.Unlock()
+
+	if err := n.checkRuntimeControl(); err != nil {
+		return err
+	}
+	if _, err := n.RuntimeRegistry.GetRuntime(runtimeID); err != nil {
+		return err
+	}
+
+	n.logger.Info("removing runtime",
+		"runtime_id", runtimeID,
+	)
+
+	if err := n.removeRuntime(ctx, runtimeID); err != nil {
+		return err
+	}
+
+	n.logger.Info("runtime removed",
+		"runtime_id", runtimeID,
+	)
+
+	return nil
+}
+
+func (n *Node) removeRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	// Stop advertising the runtime first so that the node is not scheduled for any further work
+	// while the runtime is being torn down.
+	n.RegistrationWorker.RemoveRuntimeRoleProviders(runtimeID)
+
+	if err := n.ClientWorker.RemoveRuntime(ctx, runtimeID); err != nil {
+		return fmt.Errorf("failed to remove runtime from client worker: %w", err)
+	}
+	if err := n.ExecutorWorker.RemoveRuntime(ctx, runtimeID); err != nil {
+		return fmt.Errorf("failed to remove runtime from executor worker: %w", err)
+	}
+	if err := n.StorageWorker.RemoveRuntime(ctx, runtimeID); err != nil {
+		return fmt.Errorf("failed to remove runtime from storage worker: %w", err)
+	}
+	if err := n.CommonWorker.RemoveRuntime(ctx, runtimeID); err != nil {
+		return fmt.Errorf("failed to remove runtime from common worker: %w", err)
+	}
+	if err := n.RuntimeRegistry.RemoveRuntime(ctx, runtimeID); err != nil {
+		return fmt.Errorf("failed to remove runtime from registry: %w", err)
+	}
+	return nil
+}
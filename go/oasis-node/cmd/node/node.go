@@ -42,11 +42,14 @@ import (
 	cmdSigner "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/signer"
 	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
 	roothashAPI "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	runtimeIndexer "github.com/oasisprotocol/oasis-core/go/runtime/indexer"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
 	"github.com/oasisprotocol/oasis-core/go/sentry"
 	sentryAPI "github.com/oasisprotocol/oasis-core/go/sentry/api"
 	stakingAPI "github.com/oasisprotocol/oasis-core/go/staking/api"
+	stakingIndexer "github.com/oasisprotocol/oasis-core/go/staking/indexer"
+	stakingIndexerAPI "github.com/oasisprotocol/oasis-core/go/staking/indexer/api"
 	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/upgrade"
 	upgradeAPI "github.com/oasisprotocol/oasis-core/go/upgrade/api"
@@ -108,7 +111,11 @@ type Node struct {
 	Sentry   sentryAPI.LocalBackend
 	IAS      iasAPI.Endpoint
 
+	StakingIndexer *stakingIndexer.Indexer
+
 	RuntimeRegistry runtimeRegistry.Registry
+	// runtimesLock serializes runtime additions and removals requested via the node controller.
+	runtimesLock sync.Mutex
 
 	CommonWorker       *workerCommon.Worker
 	ExecutorWorker     *executor.Worker
@@ -694,6 +701,19 @@ func NewNode() (node *Node, err error) { // nolint: gocyclo
 			return nil, err
 		}
 
+		// Initialize the staking history indexer if enabled.
+		if stakingIndexer.Enabled() {
+			node.StakingIndexer, err = stakingIndexer.New(dataDir, node.Consensus)
+			if err != nil {
+				logger.Error("failed to initialize staking history indexer",
+					"err", err,
+				)
+				return nil, err
+			}
+			node.svcMgr.Register(node.StakingIndexer)
+			stakingIndexerAPI.RegisterService(node.grpcInternal.Server(), node.StakingIndexer)
+		}
+
 		if flags.DebugDontBlameOasis() {
 			// Initialize and start the debug controller if we are in debug mode.
 			node.DebugController = control.NewDebug(node.Consensus)
@@ -717,6 +737,16 @@ func NewNode() (node *Node, err error) { // nolint: gocyclo
 		return nil, err
 	}
 
+	// Start the staking history indexer.
+	if node.StakingIndexer != nil {
+		if err = node.StakingIndexer.Start(); err != nil {
+			logger.Error("failed to start staking history indexer",
+				"err", err,
+			)
+			return nil, err
+		}
+	}
+
 	logger.Info("initialization complete: ready to serve")
 	startOk = true
 
@@ -745,9 +775,11 @@ func init() {
 		pprof.Flags,
 		tendermint.Flags,
 		seed.Flags,
+		stakingIndexer.Flags,
 		ias.Flags,
 		workerKeymanager.Flags,
 		runtimeRegistry.Flags,
+		runtimeIndexer.Flags,
 		p2p.Flags,
 		registration.Flags,
 		workerCommon.Flags,
@@ -2,15 +2,31 @@
 package signer
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
 	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdConsensus "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/consensus"
+	cmdContext "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/context"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	cmdSigner "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/signer"
 )
 
+const (
+	// CfgSignTxOutputFile configures the filename for the signed transaction.
+	CfgSignTxOutputFile = "signer.output_file"
+
+	// CfgSignTxAllowUnverified allows signing raw unsigned transactions which do not specify a
+	// chain context or signer and can therefore not be verified.
+	CfgSignTxAllowUnverified = "signer.allow_unverified"
+)
+
 var (
 	signerCmd = &cobra.Command{
 		Use:   "signer",
@@ -23,6 +39,14 @@ var (
 		Run:   doExport,
 	}
 
+	signTxCmd = &cobra.Command{
+		Use:   "sign-tx",
+		Short: "sign an unsigned transaction file",
+		Run:   doSignTx,
+	}
+
+	signTxFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
 	logger = logging.GetLogger("cmd/signer")
 )
 
@@ -45,10 +69,88 @@ func doExport(cmd *cobra.Command, args []string) {
 	}
 }
 
+func doSignTx(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	outputFile := viper.GetString(CfgSignTxOutputFile)
+	if outputFile == "" {
+		logger.Error("failed to determine signed transaction output file")
+		os.Exit(1)
+	}
+
+	genesis := cmdConsensus.InitGenesis()
+
+	ut, err := cmdConsensus.LoadUnsignedTx(viper.GetString(cmdConsensus.CfgTxFile))
+	if err != nil {
+		logger.Error("failed to load unsigned transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	_, signer, err := cmdCommon.LoadEntitySigner()
+	if err != nil {
+		logger.Error("failed to load signer",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	defer signer.Reset()
+
+	// Reject transactions that were prepared for a different network or signer. Raw unsigned
+	// transactions don't carry this information, so they can only be signed if explicitly allowed.
+	switch ut.ChainContext {
+	case "":
+		if !viper.GetBool(CfgSignTxAllowUnverified) {
+			logger.Error("unsigned transaction does not specify a chain context or signer, unable to verify")
+			os.Exit(1)
+		}
+		logger.Warn("signing unverified unsigned transaction")
+	default:
+		if err = ut.Verify(genesis.ChainContext(), signer.Public()); err != nil {
+			logger.Error("unsigned transaction is not intended for this signer",
+				"err", err,
+			)
+			os.Exit(1)
+		}
+	}
+
+	ctx := cmdContext.GetCtxWithGenesisInfo(genesis)
+	fmt.Printf("Chain context: %s\n", genesis.ChainContext())
+	fmt.Printf("Signer:        %s\n", signer.Public())
+	cmdConsensus.ConfirmSignTx(ctx, &ut.Transaction)
+
+	sigTx, err := transaction.Sign(signer, &ut.Transaction)
+	if err != nil {
+		logger.Error("failed to sign transaction",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	cmdConsensus.SaveSignedTx(outputFile, sigTx)
+}
+
 func Register(parentCmd *cobra.Command) {
 	exportCmd.Flags().AddFlagSet(cmdSigner.Flags)
 	exportCmd.Flags().AddFlagSet(cmdSigner.CLIFlags)
 
+	signTxCmd.Flags().AddFlagSet(signTxFlags)
+	signTxCmd.Flags().AddFlagSet(cmdConsensus.TxFileFlags)
+	signTxCmd.Flags().AddFlagSet(cmdSigner.Flags)
+	signTxCmd.Flags().AddFlagSet(cmdSigner.CLIFlags)
+	signTxCmd.Flags().AddFlagSet(cmdFlags.DebugTestEntityFlags)
+	signTxCmd.Flags().AddFlagSet(cmdFlags.GenesisFileFlags)
+	signTxCmd.Flags().AddFlagSet(cmdFlags.AssumeYesFlag)
+
 	signerCmd.AddCommand(exportCmd)
+	signerCmd.AddCommand(signTxCmd)
 	parentCmd.AddCommand(signerCmd)
 }
+
+func init() {
+	signTxFlags.String(CfgSignTxOutputFile, "", "path to the resulting signed transaction")
+	signTxFlags.Bool(CfgSignTxAllowUnverified, false, "allow signing raw unsigned transactions without a chain context")
+	_ = viper.BindPFlags(signTxFlags)
+}
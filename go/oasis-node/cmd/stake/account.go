@@ -17,6 +17,7 @@ import (
 	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
 	"github.com/oasisprotocol/oasis-core/go/staking/api"
+	"github.com/oasisprotocol/oasis-core/go/staking/api/token"
 )
 
 const (
@@ -165,6 +166,16 @@ func doAccountInfo(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
+	if vs := acct.General.Vesting; vs != nil {
+		epoch := getEpoch(ctx, cmd, conn)
+		fmt.Println("Vesting Schedule:")
+		vs.PrettyPrint(ctx, "  ", os.Stdout)
+		fmt.Print("  Locked: ")
+		token.PrettyPrintAmount(ctx, *vs.LockedAmount(epoch), os.Stdout)
+		fmt.Printf(" (at epoch %d)\n", epoch)
+		fmt.Println()
+	}
+
 	if len(incomingDelegations) > 0 {
 		fmt.Println("Active Delegations to this Account:")
 		prettyPrintDelegationsTo(ctx, addr, acct.Escrow.Active, incomingDelegations, "  ", os.Stdout)
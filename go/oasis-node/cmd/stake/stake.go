@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
@@ -104,6 +105,17 @@ func getAccount(ctx context.Context, cmd *cobra.Command, addr api.Address, clien
 	return acct
 }
 
+func getEpoch(ctx context.Context, cmd *cobra.Command, conn *grpc.ClientConn) beacon.EpochTime {
+	epoch, err := beacon.NewBeaconClient(conn).GetEpoch(ctx, consensus.HeightLatest)
+	if err != nil {
+		logger.Error("failed to query current epoch",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	return epoch
+}
+
 func getDelegationInfosFor(
 	ctx context.Context,
 	cmd *cobra.Command,
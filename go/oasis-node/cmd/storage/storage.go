@@ -5,11 +5,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
@@ -19,12 +22,16 @@ import (
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/history"
 	"github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/storage/database"
 	db "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/badger"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
 	workerStorage "github.com/oasisprotocol/oasis-core/go/worker/storage"
 )
 
+// cfgTargetBackend configures the storage backend to migrate node databases to.
+const cfgTargetBackend = "storage.migrate_backend.target"
+
 var (
 	storageCmd = &cobra.Command{
 		Use:   "storage",
@@ -38,6 +45,15 @@ var (
 		RunE:  doMigrate,
 	}
 
+	storageMigrateBackendCmd = &cobra.Command{
+		Use:   "migrate-backend <runtime...>",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "copy node databases to a different storage backend",
+		RunE:  doMigrateBackend,
+	}
+
+	storageMigrateBackendFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
 	storageCheckCmd = &cobra.Command{
 		Use:   "check <runtime...>",
 		Args:  cobra.MinimumNArgs(1),
@@ -199,6 +215,107 @@ func doMigrate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func doMigrateBackend(cmd *cobra.Command, args []string) error {
+	dataDir := cmdCommon.DataDir()
+	ctx := context.Background()
+
+	runtimes, err := parseRuntimes(args)
+	cobra.CheckErr(err)
+
+	srcBackend := strings.ToLower(viper.GetString(workerStorage.CfgBackend))
+	dstBackend := strings.ToLower(viper.GetString(cfgTargetBackend))
+	for _, backend := range []string{srcBackend, dstBackend} {
+		switch backend {
+		case database.BackendNameBadgerDB, database.BackendNameBoltDB:
+		default:
+			return fmt.Errorf("unsupported storage backend: '%s'", backend)
+		}
+	}
+	if srcBackend == dstBackend {
+		return fmt.Errorf("source and target storage backends are the same: '%s'", srcBackend)
+	}
+
+	for _, rt := range runtimes {
+		if pretty {
+			fmt.Printf(" ** Migrating storage database for runtime %v from %s to %s...\n", rt, srcBackend, dstBackend)
+		}
+		err := func() error {
+			runtimeDir := registry.GetRuntimeStateDir(dataDir, rt)
+
+			srcDir := workerStorage.GetLocalBackendDBDir(runtimeDir, srcBackend)
+			if _, err := os.Stat(srcDir); err != nil {
+				return fmt.Errorf("failed to stat source node database: %w", err)
+			}
+			dstDir := workerStorage.GetLocalBackendDBDir(runtimeDir, dstBackend)
+			if _, err := os.Stat(dstDir); !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("target node database already exists: %s", dstDir)
+			}
+
+			src, err := database.NewNodeDB(srcBackend, &db.Config{
+				DB:        srcDir,
+				Namespace: rt,
+				ReadOnly:  true,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to open source node database: %w", err)
+			}
+			defer src.Close()
+
+			dst, err := database.NewNodeDB(dstBackend, &db.Config{
+				DB:        dstDir,
+				Namespace: rt,
+				NoFsync:   true,
+			})
+			if err != nil {
+				_ = os.RemoveAll(dstDir)
+				return fmt.Errorf("failed to open target node database: %w", err)
+			}
+			var ok bool
+			defer func() {
+				dst.Close()
+				if !ok {
+					// Remove the partially migrated database so that the migration can be retried.
+					_ = os.RemoveAll(dstDir)
+				}
+			}()
+
+			checkpointDir, err := ioutil.TempDir(runtimeDir, "migrate-backend")
+			if err != nil {
+				return fmt.Errorf("failed to create checkpoint directory: %w", err)
+			}
+			defer os.RemoveAll(checkpointDir)
+
+			display := &displayHelper{}
+			if err = database.MigrateNodeDB(ctx, rt, src, dst, checkpointDir, display); err != nil {
+				return fmt.Errorf("node database migration returned error: %w", err)
+			}
+			if err = dst.Sync(); err != nil {
+				return fmt.Errorf("failed to sync target node database: %w", err)
+			}
+			ok = true
+
+			logger.Info("successfully migrated node database to a different backend",
+				"rt", rt,
+				"backend", dstBackend,
+			)
+			if pretty {
+				fmt.Printf("Node database migrated, set %s to '%s' to use it. The old database at %s can be removed.\n",
+					workerStorage.CfgBackend, dstBackend, srcDir,
+				)
+			}
+			return nil
+		}()
+		if err != nil {
+			logger.Error("error migrating node database", "rt", rt, "err", err)
+			if pretty {
+				fmt.Printf("error migrating node database for runtime %v: %v\n", rt, err)
+			}
+			return fmt.Errorf("error migrating node database for runtime %v: %w", rt, err)
+		}
+	}
+	return nil
+}
+
 func doCheck(cmd *cobra.Command, args []string) error {
 	dataDir := cmdCommon.DataDir()
 	ctx := context.Background()
@@ -284,9 +401,17 @@ func doRenameNs(cmd *cobra.Command, args []string) error {
 // Register registers the client sub-command and all of its children.
 func Register(parentCmd *cobra.Command) {
 	storageMigrateCmd.Flags().AddFlagSet(registry.Flags)
+	storageMigrateBackendCmd.Flags().AddFlagSet(registry.Flags)
+	storageMigrateBackendCmd.Flags().AddFlagSet(storageMigrateBackendFlags)
 	storageCheckCmd.Flags().AddFlagSet(registry.Flags)
 	storageCmd.AddCommand(storageMigrateCmd)
+	storageCmd.AddCommand(storageMigrateBackendCmd)
 	storageCmd.AddCommand(storageCheckCmd)
 	storageCmd.AddCommand(storageRenameNsCmd)
 	parentCmd.AddCommand(storageCmd)
 }
+
+func init() {
+	storageMigrateBackendFlags.String(cfgTargetBackend, database.BackendNameBoltDB, "storage backend to migrate node databases to")
+	_ = viper.BindPFlags(storageMigrateBackendFlags)
+}
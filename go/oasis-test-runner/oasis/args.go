@@ -388,6 +388,15 @@ func (args *argBuilder) runtimePath(id common.Namespace, fn string) *argBuilder
 	return args
 }
 
+func (args *argBuilder) runtimeNextPath(id common.Namespace, fn string) *argBuilder {
+	args.vec = append(args.vec, Argument{
+		Name:        runtimeRegistry.CfgRuntimeNextPaths,
+		Values:      []string{id.String() + "=" + fn},
+		MultiValued: true,
+	})
+	return args
+}
+
 func (args *argBuilder) workerKeymanagerRuntimeID(id common.Namespace) *argBuilder {
 	args.vec = append(args.vec, Argument{
 		Name:   keymanager.CfgRuntimeID,
@@ -47,6 +47,7 @@ type Compute struct { // nolint: maligned
 
 	runtimes      []int
 	runtimeConfig map[int]map[string]interface{}
+	nextRuntimes  []int
 }
 
 // ComputeCfg is the Oasis compute node configuration.
@@ -74,6 +75,15 @@ func (worker *Compute) UpdateRuntimes(runtimes []int) {
 	worker.runtimes = runtimes
 }
 
+// UpdateNextRuntimes updates the worker node next version runtimes.
+//
+// Each next version runtime must have the same runtime ID as one of the worker node runtimes.
+func (worker *Compute) UpdateNextRuntimes(runtimes []int) {
+	worker.Lock()
+	defer worker.Unlock()
+	worker.nextRuntimes = runtimes
+}
+
 // IdentityKeyPath returns the path to the node's identity key.
 func (worker *Compute) IdentityKeyPath() string {
 	return nodeIdentityKeyPath(worker.dir)
@@ -176,6 +186,10 @@ func (worker *Compute) AddArgs(args *argBuilder) error {
 		// XXX: could support configurable binary idx if ever needed.
 		worker.addHostedRuntime(v, v.teeHardware, 0, worker.runtimeConfig[idx])
 	}
+	for _, idx := range worker.nextRuntimes {
+		v := worker.net.runtimes[idx]
+		args.runtimeNextPath(v.id, v.binaries[v.teeHardware][0])
+	}
 
 	// Sentry configuration.
 	sentries, err := resolveSentries(worker.net, worker.sentryIndices)
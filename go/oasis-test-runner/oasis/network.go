@@ -124,6 +124,9 @@ type NetworkCfg struct { // nolint: maligned
 	// GovernanceParameters are the governance consensus parameters.
 	GovernanceParameters *governance.ConsensusParameters `json:"governance_parameters,omitempty"`
 
+	// EnableRuntimeVersionScheduling enables scheduled runtime version upgrades.
+	EnableRuntimeVersionScheduling bool `json:"enable_runtime_version_scheduling,omitempty"`
+
 	// SchedulerWeakAlpkaOk is for disabling the VRF alpha entropy requirement.
 	SchedulerWeakAlphaOk bool `json:"scheduler_weak_alpha_ok,omitempty"`
 
@@ -732,6 +735,9 @@ func (net *Network) MakeGenesis() error {
 	if net.cfg.Beacon.DebugMockBackend {
 		args = append(args, "--"+genesis.CfgBeaconDebugMockBackend)
 	}
+	if net.cfg.EnableRuntimeVersionScheduling {
+		args = append(args, "--"+genesis.CfgRegistryEnableRuntimeVersionScheduling)
+	}
 	if cfg := net.cfg.GovernanceParameters; cfg != nil {
 		args = append(args, []string{
 			"--" + genesis.CfgGovernanceMinProposalDeposit, strconv.FormatUint(cfg.MinProposalDeposit.ToBigInt().Uint64(), 10),
@@ -689,6 +689,8 @@ func RegisterScenarios() error {
 		KeymanagerUpgrade,
 		// RuntimeUpgrade test.
 		RuntimeUpgrade,
+		// RuntimeUpgradeScheduled test.
+		RuntimeUpgradeScheduled,
 		// HistoryReindex test.
 		HistoryReindex,
 		// TrustRoot test.
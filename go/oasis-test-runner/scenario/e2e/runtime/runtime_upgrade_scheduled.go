@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/env"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis/cli"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/scenario"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+// scheduledUpgradeEpochDelta is the number of epochs in the future at which the runtime version
+// change is scheduled. It needs to give the compute workers enough time to provision the next
+// version of the runtime.
+const scheduledUpgradeEpochDelta = 3
+
+// RuntimeUpgradeScheduled is the scheduled runtime upgrade scenario where compute workers host
+// the next runtime version side by side with the current one and switch without any downtime.
+var RuntimeUpgradeScheduled scenario.Scenario = newRuntimeUpgradeScheduledImpl()
+
+type runtimeUpgradeScheduledImpl struct {
+	runtimeUpgradeImpl
+}
+
+func newRuntimeUpgradeScheduledImpl() scenario.Scenario {
+	return &runtimeUpgradeScheduledImpl{
+		runtimeUpgradeImpl: runtimeUpgradeImpl{
+			runtimeImpl: *newRuntimeImpl("runtime-upgrade-scheduled", BasicKVEncTestClient),
+		},
+	}
+}
+
+func (sc *runtimeUpgradeScheduledImpl) Clone() scenario.Scenario {
+	return &runtimeUpgradeScheduledImpl{
+		runtimeUpgradeImpl: runtimeUpgradeImpl{
+			runtimeImpl: *sc.runtimeImpl.Clone().(*runtimeImpl),
+		},
+	}
+}
+
+func (sc *runtimeUpgradeScheduledImpl) Fixture() (*oasis.NetworkFixture, error) {
+	f, err := sc.runtimeUpgradeImpl.Fixture()
+	if err != nil {
+		return nil, err
+	}
+
+	// Scheduled runtime version changes need to be enabled in the registry.
+	f.Network.EnableRuntimeVersionScheduling = true
+
+	return f, nil
+}
+
+func (sc *runtimeUpgradeScheduledImpl) Run(childEnv *env.Env) error {
+	ctx := context.Background()
+	cli := cli.New(childEnv, sc.Net, sc.Logger)
+
+	if err := sc.startNetworkAndTestClient(ctx, childEnv); err != nil {
+		return err
+	}
+	sc.Logger.Info("waiting for client to exit")
+	// Wait for the client to exit.
+	if err := sc.waitTestClientOnly(); err != nil {
+		return err
+	}
+
+	// Generate and update a policy that will allow the new runtime to run.
+	if err := sc.applyUpgradePolicy(childEnv); err != nil {
+		return fmt.Errorf("updating policies: %w", err)
+	}
+
+	// Schedule the version change in the current runtime descriptor.
+	epoch, err := sc.Net.Controller().Beacon.GetEpoch(ctx, consensus.HeightLatest)
+	if err != nil {
+		return fmt.Errorf("failed to get current epoch: %w", err)
+	}
+	switchEpoch := epoch + scheduledUpgradeEpochDelta
+
+	sc.Logger.Info("scheduling runtime version change",
+		"epoch", epoch,
+		"switch_epoch", switchEpoch,
+	)
+	rtDsc := sc.Net.Runtimes()[1].ToRuntimeDescriptor()
+	newRtDsc := sc.Net.Runtimes()[sc.upgradedRuntimeIndex].ToRuntimeDescriptor()
+	rtDsc.ScheduledVersion = &registry.ScheduledVersionInfo{
+		VersionInfo: newRtDsc.Version,
+		Epoch:       switchEpoch,
+	}
+	// Both runtime binaries report the same version, make sure the scheduled one differs in case
+	// there is no TEE to distinguish them.
+	rtDsc.ScheduledVersion.Version.Minor++
+
+	txPath := filepath.Join(childEnv.Dir(), "register_scheduled_compute_runtime.json")
+	if err = cli.Registry.GenerateRegisterRuntimeTx(childEnv.Dir(), rtDsc, sc.nonce, txPath); err != nil {
+		return fmt.Errorf("failed to generate register compute runtime tx: %w", err)
+	}
+	sc.nonce++
+	if err = cli.Consensus.SubmitTx(txPath); err != nil {
+		return fmt.Errorf("failed to update compute runtime: %w", err)
+	}
+
+	// Restart the compute workers with the next runtime version configured.
+	sc.Logger.Info("restarting compute workers with next runtime version")
+	for _, worker := range sc.Net.ComputeWorkers() {
+		worker.UpdateNextRuntimes([]int{sc.upgradedRuntimeIndex})
+		if err = worker.Restart(ctx); err != nil {
+			return fmt.Errorf("failed to restart compute worker '%s': %w", worker.Name, err)
+		}
+	}
+
+	sc.Logger.Info("waiting for compute workers to be ready")
+	for _, worker := range sc.Net.ComputeWorkers() {
+		if err = worker.WaitReady(ctx); err != nil {
+			return fmt.Errorf("error waiting for compute node '%s' to become ready: %w", worker.Name, err)
+		}
+	}
+
+	// Wait for the scheduled version change to take effect.
+	sc.Logger.Info("waiting for the scheduled version change",
+		"switch_epoch", switchEpoch,
+	)
+	if err = sc.Net.Controller().Beacon.WaitEpoch(ctx, switchEpoch); err != nil {
+		return fmt.Errorf("failed to wait for epoch %d: %w", switchEpoch, err)
+	}
+
+	// Run client again.
+	sc.Logger.Info("starting a second client to check if runtime works")
+	newTestClient := sc.testClient.Clone().(*KeyValueEncTestClient)
+	sc.runtimeImpl.testClient = newTestClient.WithKey("key2").WithSeed("second_seed")
+
+	if err = sc.startTestClientOnly(ctx, childEnv); err != nil {
+		return err
+	}
+	return sc.waitTestClient()
+}
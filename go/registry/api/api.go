@@ -500,16 +500,15 @@ func VerifyRegisterNodeArgs( // nolint: gocyclo
 			return nil, nil, fmt.Errorf("%w: missing runtimes", ErrInvalidArgument)
 		}
 	default:
-		rtMap := make(map[common.Namespace]bool)
+		rtMap := make(map[common.Namespace]int)
 
 		for _, rt := range n.Runtimes {
-			if rtMap[rt.ID] {
+			if !params.EnableRuntimeVersionScheduling && rtMap[rt.ID] > 0 {
 				logger.Error("RegisterNode: duplicate runtime IDs",
 					"runtime_id", rt.ID,
 				)
 				return nil, nil, fmt.Errorf("%w: duplicate runtime IDs", ErrInvalidArgument)
 			}
-			rtMap[rt.ID] = true
 
 			// Make sure that the claimed runtime actually exists.
 			regRt, err := runtimeLookup.AnyRuntime(ctx, rt.ID)
@@ -521,9 +520,19 @@ func VerifyRegisterNodeArgs( // nolint: gocyclo
 				return nil, nil, fmt.Errorf("failed to lookup runtime: %w", err)
 			}
 
+			// A node may only register the same runtime multiple times in case a version change
+			// is scheduled, once for each of the allowed versions.
+			rtMap[rt.ID]++
+			if rtMap[rt.ID] > len(regRt.AllowedVersions(epoch)) {
+				logger.Error("RegisterNode: duplicate runtime IDs",
+					"runtime_id", rt.ID,
+				)
+				return nil, nil, fmt.Errorf("%w: duplicate runtime IDs", ErrInvalidArgument)
+			}
+
 			// If the node indicates TEE support for any of it's runtimes,
 			// validate the attestation evidence.
-			if err := VerifyNodeRuntimeEnclaveIDs(logger, rt, regRt, now); err != nil {
+			if err := VerifyNodeRuntimeEnclaveIDs(logger, rt, regRt, epoch, now); err != nil {
 				return nil, nil, err
 			}
 
@@ -703,7 +712,13 @@ func VerifyRegisterNodeArgs( // nolint: gocyclo
 }
 
 // VerifyNodeRuntimeEnclaveIDs verifies TEE-specific attributes of the node's runtime.
-func VerifyNodeRuntimeEnclaveIDs(logger *logging.Logger, rt *node.Runtime, regRt *Runtime, ts time.Time) error {
+func VerifyNodeRuntimeEnclaveIDs(
+	logger *logging.Logger,
+	rt *node.Runtime,
+	regRt *Runtime,
+	epoch beacon.EpochTime,
+	ts time.Time,
+) error {
 	// If no TEE available, do nothing.
 	if rt.Capabilities.TEE == nil {
 		return nil
@@ -719,16 +734,20 @@ func VerifyNodeRuntimeEnclaveIDs(logger *logging.Logger, rt *node.Runtime, regRt
 		return ErrTEEHardwareMismatch
 	}
 
-	if err := rt.Capabilities.TEE.Verify(ts, regRt.Version.TEE); err != nil {
-		logger.Error("VerifyNodeRuntimeEnclaveIDs: failed to validate attestation",
-			"runtime_id", rt.ID,
-			"ts", ts,
-			"err", err,
-		)
-		return err
+	// The attestation must be valid for any of the currently allowed runtime versions.
+	var err error
+	for _, ver := range regRt.AllowedVersions(epoch) {
+		if err = rt.Capabilities.TEE.Verify(ts, ver.TEE); err == nil {
+			return nil
+		}
 	}
 
-	return nil
+	logger.Error("VerifyNodeRuntimeEnclaveIDs: failed to validate attestation",
+		"runtime_id", rt.ID,
+		"ts", ts,
+		"err", err,
+	)
+	return err
 }
 
 // VerifyAddress verifies a node address.
@@ -789,8 +808,16 @@ func verifyAddresses(params *ConsensusParameters, addressRequired bool, addresse
 }
 
 // verifyNodeRuntimeChanges verifies node runtime changes.
-func verifyNodeRuntimeChanges(logger *logging.Logger, currentRuntimes, newRuntimes []*node.Runtime) bool {
-	if len(newRuntimes) < len(currentRuntimes) {
+func verifyNodeRuntimeChanges(
+	logger *logging.Logger,
+	params *ConsensusParameters,
+	currentRuntimes []*node.Runtime,
+	newRuntimes []*node.Runtime,
+) bool {
+	// With runtime version scheduling the same runtime may be present multiple
+	// times in case a version change is scheduled, so the number of entries may
+	// shrink once the change is complete. All runtimes, however, must remain.
+	if !params.EnableRuntimeVersionScheduling && len(newRuntimes) < len(currentRuntimes) {
 		logger.Error("RegisterNode: trying to update runtimes, cannot remove existing runtimes",
 			"current_runtimes", currentRuntimes,
 			"new_runtimes", newRuntimes,
@@ -852,7 +879,13 @@ func verifyRuntimeCapabilities(logger *logging.Logger, currentCaps, newCaps *nod
 }
 
 // VerifyNodeUpdate verifies changes while updating the node.
-func VerifyNodeUpdate(logger *logging.Logger, currentNode, newNode *node.Node, epoch beacon.EpochTime) error {
+func VerifyNodeUpdate(
+	logger *logging.Logger,
+	params *ConsensusParameters,
+	currentNode *node.Node,
+	newNode *node.Node,
+	epoch beacon.EpochTime,
+) error {
 	// XXX: In future we might want to allow updating some of these fields as well. But these updates
 	//      should only happen after the epoch transition.
 	//      For now, node should un-register and re-register to update any of these fields.
@@ -884,7 +917,7 @@ func VerifyNodeUpdate(logger *logging.Logger, currentNode, newNode *node.Node, e
 		return nil
 	}
 
-	if !verifyNodeRuntimeChanges(logger, currentNode.Runtimes, newNode.Runtimes) {
+	if !verifyNodeRuntimeChanges(logger, params, currentNode.Runtimes, newNode.Runtimes) {
 		curNodeRuntimes, _ := json.Marshal(currentNode.Runtimes)
 		newNodeRuntimes, _ := json.Marshal(newNode.Runtimes)
 		logger.Error("RegisterNode: trying to update node runtimes",
@@ -957,6 +990,14 @@ func VerifyRuntime( // nolint: gocyclo
 		return fmt.Errorf("%w: runtime governance model is not enabled: %s", ErrForbidden, rt.GovernanceModel.String())
 	}
 
+	// Make sure runtime version scheduling is allowed.
+	if rt.ScheduledVersion != nil && !params.EnableRuntimeVersionScheduling {
+		logger.Error("RegisterRuntime: runtime version scheduling is not enabled",
+			"runtime", rt,
+		)
+		return fmt.Errorf("%w: runtime version scheduling is not enabled", ErrForbidden)
+	}
+
 	// Ensure a valid TEE hardware is specified.
 	if rt.TEEHardware >= node.TEEHardwareReserved {
 		logger.Error("RegisterRuntime: invalid TEE hardware specified",
@@ -969,15 +1010,22 @@ func VerifyRuntime( // nolint: gocyclo
 	if rt.TEEHardware != node.TEEHardwareInvalid {
 		switch rt.TEEHardware {
 		case node.TEEHardwareIntelSGX:
-			var cs node.SGXConstraints
-			if err := cbor.Unmarshal(rt.Version.TEE, &cs); err != nil {
-				logger.Error("RegisterRuntime: invalid SGX TEE constraints",
-					"err", err,
-				)
-				return fmt.Errorf("%w: invalid SGX TEE constraints", ErrInvalidArgument)
+			versions := []*VersionInfo{&rt.Version}
+			if sv := rt.ScheduledVersion; sv != nil {
+				versions = append(versions, &sv.VersionInfo)
 			}
-			if len(cs.Enclaves) == 0 {
-				return fmt.Errorf("%w: invalid SGX TEE constraints", ErrNoEnclaveForRuntime)
+			for _, ver := range versions {
+				var cs node.SGXConstraints
+				if err := cbor.Unmarshal(ver.TEE, &cs); err != nil {
+					logger.Error("RegisterRuntime: invalid SGX TEE constraints",
+						"err", err,
+						"version", ver.Version,
+					)
+					return fmt.Errorf("%w: invalid SGX TEE constraints", ErrInvalidArgument)
+				}
+				if len(cs.Enclaves) == 0 {
+					return fmt.Errorf("%w: invalid SGX TEE constraints", ErrNoEnclaveForRuntime)
+				}
 			}
 		}
 	}
@@ -1182,6 +1230,71 @@ type ConsensusParameters struct {
 
 	// EnableRuntimeGovernanceModels is a set of enabled runtime governance models.
 	EnableRuntimeGovernanceModels map[RuntimeGovernanceModel]bool `json:"enable_runtime_governance_models,omitempty"`
+
+	// EnableRuntimeVersionScheduling is true iff runtimes may schedule version changes and nodes
+	// may register for multiple versions of the same runtime while a version change is pending.
+	EnableRuntimeVersionScheduling bool `json:"enable_runtime_version_scheduling,omitempty"`
+}
+
+// ConsensusParameterChanges are allowed registry consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// DisableRuntimeRegistration is the new disable runtime registration flag.
+	DisableRuntimeRegistration *bool `json:"disable_runtime_registration,omitempty"`
+
+	// DisableKeyManagerRuntimeRegistration is the new disable key manager runtime registration flag.
+	DisableKeyManagerRuntimeRegistration *bool `json:"disable_km_runtime_registration,omitempty"`
+
+	// GasCosts are the new gas costs. If set, they replace all existing gas costs.
+	GasCosts transaction.Costs `json:"gas_costs,omitempty"`
+
+	// MaxNodeExpiration is the maximum node expiration.
+	MaxNodeExpiration *uint64 `json:"max_node_expiration,omitempty"`
+
+	// EnableRuntimeGovernanceModels are the new enabled runtime governance models. If set, they
+	// replace all existing enabled runtime governance models.
+	EnableRuntimeGovernanceModels map[RuntimeGovernanceModel]bool `json:"enable_runtime_governance_models,omitempty"`
+
+	// EnableRuntimeVersionScheduling is the new enable runtime version scheduling flag.
+	EnableRuntimeVersionScheduling *bool `json:"enable_runtime_version_scheduling,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.DisableRuntimeRegistration == nil &&
+		c.DisableKeyManagerRuntimeRegistration == nil &&
+		c.GasCosts == nil &&
+		c.MaxNodeExpiration == nil &&
+		c.EnableRuntimeGovernanceModels == nil &&
+		c.EnableRuntimeVersionScheduling == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	if c.MaxNodeExpiration != nil && *c.MaxNodeExpiration == 0 {
+		return fmt.Errorf("maximum node expiration must be non-zero")
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.DisableRuntimeRegistration != nil {
+		params.DisableRuntimeRegistration = *c.DisableRuntimeRegistration
+	}
+	if c.DisableKeyManagerRuntimeRegistration != nil {
+		params.DisableKeyManagerRuntimeRegistration = *c.DisableKeyManagerRuntimeRegistration
+	}
+	if c.GasCosts != nil {
+		params.GasCosts = c.GasCosts
+	}
+	if c.MaxNodeExpiration != nil {
+		params.MaxNodeExpiration = *c.MaxNodeExpiration
+	}
+	if c.EnableRuntimeGovernanceModels != nil {
+		params.EnableRuntimeGovernanceModels = c.EnableRuntimeGovernanceModels
+	}
+	if c.EnableRuntimeVersionScheduling != nil {
+		params.EnableRuntimeVersionScheduling = *c.EnableRuntimeVersionScheduling
+	}
+	return nil
 }
 
 const (
@@ -1247,10 +1360,17 @@ func StakeThresholdsForNode(n *node.Node, rts []*Runtime) (thresholds []staking.
 	}
 
 	// Add runtime-specific role thresholds for each registered runtime.
+	seen := make(map[common.Namespace]bool)
 	for i, rt := range rts {
 		if !n.Runtimes[i].ID.Equal(&rt.ID) {
 			panic(fmt.Errorf("registry: mismatched runtime order"))
 		}
+		// The same runtime may be registered multiple times in case a version change is
+		// scheduled, but it should only be accounted for once.
+		if seen[rt.ID] {
+			continue
+		}
+		seen[rt.ID] = true
 
 		var roleThresholds []staking.ThresholdKind
 		if n.HasRoles(node.RoleKeyManager) {
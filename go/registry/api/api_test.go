@@ -10,6 +10,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
 )
 
 func TestVerifyNodeUpdate(t *testing.T) {
@@ -130,7 +131,90 @@ func TestVerifyNodeUpdate(t *testing.T) {
 			msg:   "expired node consensus ID update should not be allowed",
 		},
 	} {
-		err := VerifyNodeUpdate(logger, &existingNode, tc.nodeFn(), tc.epoch)
+		err := VerifyNodeUpdate(logger, &ConsensusParameters{}, &existingNode, tc.nodeFn(), tc.epoch)
 		require.Equal(t, tc.err, err, tc.msg)
 	}
+
+	// A node registered for multiple versions of the same runtime may only drop the old version
+	// when runtime version scheduling is enabled.
+	upgradingNode := existingNode
+	upgradingNode.Runtimes = []*node.Runtime{
+		{ID: rtID1, Version: version.Version{Major: 1}},
+		{ID: rtID1, Version: version.Version{Major: 2}},
+	}
+	upgradedNode := existingNode
+	upgradedNode.Runtimes = []*node.Runtime{
+		{ID: rtID1, Version: version.Version{Major: 2}},
+	}
+	err := VerifyNodeUpdate(logger, &ConsensusParameters{}, &upgradingNode, &upgradedNode, 0)
+	require.Equal(t, ErrNodeUpdateNotAllowed, err, "dropping a runtime version should not be allowed")
+	params := ConsensusParameters{EnableRuntimeVersionScheduling: true}
+	err = VerifyNodeUpdate(logger, &params, &upgradingNode, &upgradedNode, 0)
+	require.NoError(t, err, "dropping a runtime version should be allowed with version scheduling")
+	err = VerifyNodeUpdate(logger, &params, &upgradingNode, &node.Node{
+		ID:        nodeID1,
+		EntityID:  entityID1,
+		Consensus: existingNode.Consensus,
+		Roles:     existingNode.Roles,
+	}, 0)
+	require.Equal(t, ErrNodeUpdateNotAllowed, err, "removing a runtime should not be allowed")
+}
+
+func TestRuntimeAllowedVersions(t *testing.T) {
+	require := require.New(t)
+
+	rt := Runtime{
+		Version: VersionInfo{Version: version.Version{Major: 1}},
+	}
+	require.Len(rt.AllowedVersions(10), 1, "without a scheduled version only the current version should be allowed")
+	require.False(rt.PromoteScheduledVersion(10), "nothing should be promoted without a scheduled version")
+
+	rt.ScheduledVersion = &ScheduledVersionInfo{
+		VersionInfo: VersionInfo{Version: version.Version{Major: 2}},
+		Epoch:       10,
+	}
+	versions := rt.AllowedVersions(9)
+	require.Len(versions, 2, "both versions should be allowed before the scheduled epoch")
+	require.EqualValues(1, versions[0].Version.Major)
+	require.EqualValues(2, versions[1].Version.Major)
+	require.EqualValues(1, rt.ActiveVersion(9).Version.Major)
+
+	versions = rt.AllowedVersions(10)
+	require.Len(versions, 1, "only the scheduled version should be allowed at the scheduled epoch")
+	require.EqualValues(2, versions[0].Version.Major)
+	require.EqualValues(2, rt.ActiveVersion(10).Version.Major)
+
+	require.False(rt.PromoteScheduledVersion(9), "scheduled version should not be promoted early")
+	require.True(rt.PromoteScheduledVersion(10), "scheduled version should be promoted")
+	require.EqualValues(2, rt.Version.Version.Major)
+	require.Nil(rt.ScheduledVersion, "scheduled version should be cleared after promotion")
+	require.Len(rt.AllowedVersions(10), 1)
+}
+
+func TestConsensusParametersSanityCheck(t *testing.T) {
+	require := require.New(t)
+
+	params := ConsensusParameters{
+		MaxNodeExpiration: 5,
+		EnableRuntimeGovernanceModels: map[RuntimeGovernanceModel]bool{
+			GovernanceEntity:    true,
+			GovernanceConsensus: true,
+		},
+	}
+	require.NoError(params.SanityCheck(), "SanityCheck")
+
+	// Changes should be sanity checked after being applied.
+	changes := ConsensusParameterChanges{
+		EnableRuntimeGovernanceModels: map[RuntimeGovernanceModel]bool{
+			GovernanceInvalid: true,
+		},
+	}
+	require.NoError(changes.SanityCheck(), "changes SanityCheck")
+	require.NoError(changes.Apply(&params), "Apply")
+	require.Error(params.SanityCheck(), "SanityCheck should fail with invalid governance model")
+
+	params.EnableRuntimeGovernanceModels = map[RuntimeGovernanceModel]bool{
+		GovernanceMax + 1: true,
+	}
+	require.Error(params.SanityCheck(), "SanityCheck should fail with unsupported governance model")
 }
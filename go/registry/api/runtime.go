@@ -1,12 +1,14 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
@@ -48,6 +50,9 @@ const (
 
 	// TxnSchedulerSimple is the name of the simple batching algorithm.
 	TxnSchedulerSimple = "simple"
+	// TxnSchedulerOrdered is the name of the batching algorithm which orders transactions by
+	// priority while respecting per-sender sequence numbers.
+	TxnSchedulerOrdered = "ordered"
 )
 
 // String returns a string representation of a runtime kind.
@@ -118,8 +123,7 @@ type TxnSchedulerParameters struct {
 	// Algorithm is the transaction scheduling algorithm.
 	Algorithm string `json:"algorithm"`
 
-	// BatchFlushTimeout denotes, if using the "simple" algorithm, how long to
-	// wait for a scheduled batch.
+	// BatchFlushTimeout denotes how long to wait for a scheduled batch.
 	BatchFlushTimeout time.Duration `json:"batch_flush_timeout"`
 
 	// MaxBatchSize denotes what is the max size of a scheduled batch.
@@ -136,7 +140,9 @@ type TxnSchedulerParameters struct {
 // ValidateBasic performs basic transaction scheduler parameter validity checks.
 func (t *TxnSchedulerParameters) ValidateBasic() error {
 	// Ensure txnscheduler parameters have sensible values.
-	if t.Algorithm != TxnSchedulerSimple {
+	switch t.Algorithm {
+	case TxnSchedulerSimple, TxnSchedulerOrdered:
+	default:
 		return fmt.Errorf("invalid transaction scheduler algorithm")
 	}
 	if t.BatchFlushTimeout < 50*time.Millisecond {
@@ -314,6 +320,11 @@ type Runtime struct { // nolint: maligned
 	// Version is the runtime version information.
 	Version VersionInfo `json:"versions"`
 
+	// ScheduledVersion is the optional runtime version information that will replace Version
+	// starting with the given epoch. Nodes may register for both versions ahead of time so that
+	// they can switch to the new version without any downtime.
+	ScheduledVersion *ScheduledVersionInfo `json:"scheduled_version,omitempty"`
+
 	// KeyManager is the key manager runtime ID for this runtime.
 	KeyManager *common.Namespace `json:"key_manager,omitempty"`
 
@@ -456,6 +467,12 @@ func (r *Runtime) ValidateBasic(strictVersion bool) error {
 		return fmt.Errorf("bad runtime kind: %s", r.Kind)
 	}
 
+	if sv := r.ScheduledVersion; sv != nil {
+		if sv.Version == r.Version.Version && bytes.Equal(sv.TEE, r.Version.TEE) {
+			return fmt.Errorf("scheduled version is the same as the current version")
+		}
+	}
+
 	if err := r.Staking.ValidateBasic(r.Kind); err != nil {
 		return fmt.Errorf("bad staking parameters: %w", err)
 	}
@@ -503,6 +520,53 @@ type VersionInfo struct {
 	TEE []byte `json:"tee,omitempty"`
 }
 
+// ScheduledVersionInfo is the runtime version information scheduled to become active at a given
+// epoch.
+type ScheduledVersionInfo struct {
+	VersionInfo
+
+	// Epoch is the epoch starting with which the version becomes active.
+	Epoch beacon.EpochTime `json:"epoch"`
+}
+
+// ActiveVersion returns the runtime version information that is active at the given epoch.
+func (r *Runtime) ActiveVersion(epoch beacon.EpochTime) *VersionInfo {
+	if sv := r.ScheduledVersion; sv != nil && epoch >= sv.Epoch {
+		return &sv.VersionInfo
+	}
+	return &r.Version
+}
+
+// AllowedVersions returns the runtime version information for all versions that nodes may
+// register with at the given epoch.
+//
+// While a version change is pending, both the current and the scheduled version are allowed so
+// that nodes can provision the new version in advance. Once the scheduled version becomes active,
+// the previous version is no longer allowed.
+func (r *Runtime) AllowedVersions(epoch beacon.EpochTime) []*VersionInfo {
+	sv := r.ScheduledVersion
+	switch {
+	case sv == nil:
+		return []*VersionInfo{&r.Version}
+	case epoch >= sv.Epoch:
+		return []*VersionInfo{&sv.VersionInfo}
+	default:
+		return []*VersionInfo{&r.Version, &sv.VersionInfo}
+	}
+}
+
+// PromoteScheduledVersion replaces the current version with the scheduled version in case the
+// scheduled version is active at the given epoch. It returns true iff the version was promoted.
+func (r *Runtime) PromoteScheduledVersion(epoch beacon.EpochTime) bool {
+	sv := r.ScheduledVersion
+	if sv == nil || epoch < sv.Epoch {
+		return false
+	}
+	r.Version = sv.VersionInfo
+	r.ScheduledVersion = nil
+	return true
+}
+
 // RuntimeGenesis is the runtime genesis information that is used to
 // initialize runtime state in the first block.
 type RuntimeGenesis struct {
@@ -16,6 +16,24 @@ import (
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
 
+// SanityCheck performs a sanity check on the consensus parameters.
+func (p *ConsensusParameters) SanityCheck() error {
+	if !flags.DebugDontBlameOasis() {
+		if p.DebugAllowUnroutableAddresses || p.DebugBypassStake {
+			return fmt.Errorf("one or more unsafe debug flags set")
+		}
+		if p.MaxNodeExpiration == 0 {
+			return fmt.Errorf("maximum node expiration not specified")
+		}
+	}
+	for gm := range p.EnableRuntimeGovernanceModels {
+		if gm == GovernanceInvalid || gm > GovernanceMax {
+			return fmt.Errorf("%w: %d", ErrUnsupportedRuntimeGovernanceModel, gm)
+		}
+	}
+	return nil
+}
+
 // SanityCheck does basic sanity checking on the genesis state.
 func (g *Genesis) SanityCheck(
 	now time.Time,
@@ -26,13 +44,8 @@ func (g *Genesis) SanityCheck(
 ) error {
 	logger := logging.GetLogger("genesis/sanity-check")
 
-	if !flags.DebugDontBlameOasis() {
-		if g.Parameters.DebugAllowUnroutableAddresses || g.Parameters.DebugBypassStake {
-			return fmt.Errorf("registry: sanity check failed: one or more unsafe debug flags set")
-		}
-		if g.Parameters.MaxNodeExpiration == 0 {
-			return fmt.Errorf("registry: sanity check failed: maximum node expiration not specified")
-		}
+	if err := g.Parameters.SanityCheck(); err != nil {
+		return fmt.Errorf("registry: sanity check failed: %w", err)
 	}
 
 	// Check entities.
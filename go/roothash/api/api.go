@@ -121,6 +121,9 @@ type Backend interface {
 	// TrackRuntime adds a runtime the history of which should be tracked.
 	TrackRuntime(ctx context.Context, history BlockHistory) error
 
+	// UntrackRuntime stops tracking the history of the given runtime.
+	UntrackRuntime(ctx context.Context, runtimeID common.Namespace) error
+
 	// StateToGenesis returns the genesis state at specified block height.
 	StateToGenesis(ctx context.Context, height int64) (*Genesis, error)
 
@@ -439,6 +442,48 @@ type ConsensusParameters struct {
 	MaxEvidenceAge uint64 `json:"max_evidence_age"`
 }
 
+// ConsensusParameterChanges are allowed roothash consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// GasCosts are the new gas costs. If set, they replace all existing gas costs.
+	GasCosts transaction.Costs `json:"gas_costs,omitempty"`
+
+	// MaxRuntimeMessages is the new maximum number of runtime messages.
+	MaxRuntimeMessages *uint32 `json:"max_runtime_messages,omitempty"`
+
+	// MaxEvidenceAge is the new maximum evidence age.
+	MaxEvidenceAge *uint64 `json:"max_evidence_age,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameters.
+func (p *ConsensusParameters) SanityCheck() error {
+	if p.MaxRuntimeMessages == 0 {
+		return fmt.Errorf("maximum number of runtime messages must be positive")
+	}
+	return nil
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.GasCosts == nil && c.MaxRuntimeMessages == nil && c.MaxEvidenceAge == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.GasCosts != nil {
+		params.GasCosts = c.GasCosts
+	}
+	if c.MaxRuntimeMessages != nil {
+		params.MaxRuntimeMessages = *c.MaxRuntimeMessages
+	}
+	if c.MaxEvidenceAge != nil {
+		params.MaxEvidenceAge = *c.MaxEvidenceAge
+	}
+	return nil
+}
+
 const (
 	// GasOpComputeCommit is the gas operation identifier for compute commits.
 	GasOpComputeCommit transaction.Op = "compute_commit"
@@ -645,3 +645,20 @@ func TestEquivocationExecutorEvidenceValidateBasic(t *testing.T) {
 		}
 	}
 }
+
+func TestConsensusParametersSanityCheck(t *testing.T) {
+	require := require.New(t)
+
+	params := ConsensusParameters{
+		MaxRuntimeMessages: 32,
+		MaxEvidenceAge:     50,
+	}
+	require.NoError(params.SanityCheck(), "SanityCheck")
+
+	// Changes should be sanity checked after being applied.
+	var maxRuntimeMessages uint32
+	changes := ConsensusParameterChanges{MaxRuntimeMessages: &maxRuntimeMessages}
+	require.NoError(changes.SanityCheck(), "changes SanityCheck")
+	require.NoError(changes.Apply(&params), "Apply")
+	require.Error(params.SanityCheck(), "SanityCheck should fail with zero max runtime messages")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
@@ -75,6 +76,10 @@ type Pool struct {
 	Runtime *registry.Runtime `json:"runtime"`
 	// Committee is the committee this pool is collecting the commitments for.
 	Committee *scheduler.Committee `json:"committee"`
+	// ActiveVersion is the runtime version that committee members were elected for. It is only set
+	// when runtime version scheduling is enabled as otherwise nodes can only be registered for a
+	// single version of the runtime.
+	ActiveVersion *version.Version `json:"active_version,omitempty"`
 	// Round is the current protocol round.
 	Round uint64 `json:"round"`
 	// ExecuteCommitments are the commitments in the pool iff Committee.Kind
@@ -146,6 +151,25 @@ func (p *Pool) isScheduler(id signature.PublicKey) bool {
 	return scheduler.PublicKey.Equal(id)
 }
 
+// getNodeRuntime returns the node's runtime descriptor that commitments need to be verified
+// against. In case a runtime version change is scheduled, the node may be registered for multiple
+// versions of the runtime and only the active version is used, same as for committee elections.
+func (p *Pool) getNodeRuntime(n *node.Node) *node.Runtime {
+	if p.ActiveVersion == nil {
+		return n.GetRuntime(p.Runtime.ID)
+	}
+	for _, rt := range n.Runtimes {
+		if !rt.ID.Equal(&p.Runtime.ID) {
+			continue
+		}
+		if rt.Version.MaskNonMajor() != p.ActiveVersion.MaskNonMajor() {
+			continue
+		}
+		return rt
+	}
+	return nil
+}
+
 // ResetCommitments resets the commitments in the pool, clears the discrepancy flag and the next
 // timeout height.
 func (p *Pool) ResetCommitments(round uint64) {
@@ -248,7 +272,7 @@ func (p *Pool) addVerifiedExecutorCommitment( // nolint: gocyclo
 				return ErrNotInCommittee
 			}
 
-			rt := n.GetRuntime(p.Runtime.ID)
+			rt := p.getNodeRuntime(n)
 			if rt == nil {
 				// We currently prevent this case throughout the rest of the system.
 				// Still, it's prudent to check.
@@ -259,6 +283,9 @@ func (p *Pool) addVerifiedExecutorCommitment( // nolint: gocyclo
 				return ErrNotInCommittee
 			}
 
+			if rt.Capabilities.TEE == nil {
+				return ErrRakSigInvalid
+			}
 			if err = commit.Header.VerifyRAK(rt.Capabilities.TEE.RAK); err != nil {
 				return ErrRakSigInvalid
 			}
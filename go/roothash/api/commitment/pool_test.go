@@ -14,6 +14,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
 	genesisTestHelpers "github.com/oasisprotocol/oasis-core/go/genesis/tests"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
@@ -24,13 +25,19 @@ import (
 
 type staticNodeLookup struct {
 	runtime *node.Runtime
+	// runtimes are used instead of runtime in case they are set.
+	runtimes []*node.Runtime
 }
 
 func (n *staticNodeLookup) Node(ctx context.Context, id signature.PublicKey) (*node.Node, error) {
+	runtimes := n.runtimes
+	if runtimes == nil {
+		runtimes = []*node.Runtime{n.runtime}
+	}
 	return &node.Node{
 		Versioned: cbor.NewVersioned(node.LatestNodeDescriptorVersion),
 		ID:        id,
-		Runtimes:  []*node.Runtime{n.runtime},
+		Runtimes:  runtimes,
 	}, nil
 }
 
@@ -280,6 +287,106 @@ func TestPoolSingleCommitmentTEE(t *testing.T) {
 	require.EqualValues(t, &ec, ddEc, "DD should return the correct commitment")
 }
 
+func TestPoolSingleCommitmentTEEScheduledVersion(t *testing.T) {
+	genesisTestHelpers.SetTestChainContext()
+	require := require.New(t)
+
+	var rtID common.Namespace
+	_ = rtID.UnmarshalHex("0000000000000000000000000000000000000000000000000000000000000000")
+
+	rt := &registry.Runtime{
+		Versioned:       cbor.NewVersioned(registry.LatestRuntimeDescriptorVersion),
+		ID:              rtID,
+		Kind:            registry.KindCompute,
+		TEEHardware:     node.TEEHardwareIntelSGX,
+		GovernanceModel: registry.GovernanceEntity,
+	}
+
+	sk, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	// The node is registered for both the current and the scheduled runtime version, each with
+	// its own RAK.
+	versions := []version.Version{{Major: 1}, {Major: 2}}
+	raks := make([]signature.Signer, 0, len(versions))
+	nl := &staticNodeLookup{}
+	for _, v := range versions {
+		skRAK, rakErr := memorySigner.NewSigner(rand.Reader)
+		require.NoError(rakErr, "NewSigner")
+		raks = append(raks, skRAK)
+		nl.runtimes = append(nl.runtimes, &node.Runtime{
+			ID:      rtID,
+			Version: v,
+			Capabilities: node.Capabilities{
+				TEE: &node.CapabilityTEE{
+					Hardware: node.TEEHardwareIntelSGX,
+					RAK:      skRAK.Public(),
+				},
+			},
+		})
+	}
+
+	newCommitment := func(pool *Pool, skRAK signature.Signer) (*block.Block, *ExecutorCommitment) {
+		childBlk, _, ec := generateExecutorCommitment(t, pool.Round)
+		rakSig, sigErr := signature.Sign(skRAK, ComputeResultsHeaderSignatureContext, cbor.Marshal(ec.Header.ComputeResultsHeader))
+		require.NoError(sigErr, "Sign")
+		ec.Header.RAKSignature = &rakSig.Signature
+		ec.NodeID = sk.Public()
+		require.NoError(ec.Sign(sk, rtID), "ec.Sign")
+		return childBlk, &ec
+	}
+
+	for _, tc := range []struct {
+		name          string
+		activeVersion *version.Version
+		validRAK      int
+	}{
+		// Without runtime version scheduling the first registered runtime is used.
+		{"Unscheduled", nil, 0},
+		{"Current", &versions[0], 0},
+		{"Scheduled", &versions[1], 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, skRAK := range raks {
+				pool := Pool{
+					Runtime: rt,
+					Committee: &scheduler.Committee{
+						Kind: scheduler.KindComputeExecutor,
+						Members: []*scheduler.CommitteeNode{
+							{Role: scheduler.RoleWorker, PublicKey: sk.Public()},
+						},
+					},
+					ActiveVersion: tc.activeVersion,
+				}
+
+				childBlk, ec := newCommitment(&pool, skRAK)
+				err := pool.AddExecutorCommitment(context.Background(), childBlk, nl, ec, nil)
+				switch i {
+				case tc.validRAK:
+					require.NoError(err, "AddExecutorCommitment")
+				default:
+					require.Equal(ErrRakSigInvalid, err, "commitment signed by a RAK of an inactive version should be rejected")
+				}
+			}
+		})
+	}
+
+	// Nodes not registered for the active version should not be able to commit.
+	pool := Pool{
+		Runtime: rt,
+		Committee: &scheduler.Committee{
+			Kind: scheduler.KindComputeExecutor,
+			Members: []*scheduler.CommitteeNode{
+				{Role: scheduler.RoleWorker, PublicKey: sk.Public()},
+			},
+		},
+		ActiveVersion: &version.Version{Major: 3},
+	}
+	childBlk, ec := newCommitment(&pool, raks[1])
+	err = pool.AddExecutorCommitment(context.Background(), childBlk, nl, ec, nil)
+	require.Equal(ErrNotInCommittee, err, "commitment from a node not registered for the active version should be rejected")
+}
+
 func TestPoolStragglers(t *testing.T) {
 	genesisTestHelpers.SetTestChainContext()
 
@@ -348,6 +348,10 @@ func (c *roothashClient) TrackRuntime(ctx context.Context, history BlockHistory)
 	return ErrInvalidArgument
 }
 
+func (c *roothashClient) UntrackRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	return ErrInvalidArgument
+}
+
 func (c *roothashClient) StateToGenesis(ctx context.Context, height int64) (*Genesis, error) {
 	var rsp Genesis
 	if err := c.conn.Invoke(ctx, methodStateToGenesis.FullName(), height, &rsp); err != nil {
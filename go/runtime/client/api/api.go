@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
@@ -18,6 +19,9 @@ const (
 
 	// RoundLatest is a special round number always referring to the latest round.
 	RoundLatest = roothash.RoundLatest
+
+	// MaxQueryTxsLimit is the maximum number of transactions returned by a single QueryTxs call.
+	MaxQueryTxsLimit = 1000
 )
 
 var (
@@ -34,6 +38,14 @@ var (
 	ErrCheckTxFailed = errors.New(ModuleName, 5, "client: transaction check failed")
 	// ErrNoHostedRuntime is returned when the hosted runtime is not available locally.
 	ErrNoHostedRuntime = errors.New(ModuleName, 6, "client: no hosted runtime is available")
+	// ErrIndexerDisabled is returned when a transaction index query is made but the transaction
+	// indexer is not enabled.
+	ErrIndexerDisabled = errors.New(ModuleName, 7, "client: transaction indexer is disabled")
+	// ErrInvalidQuery is returned when a malformed transaction index query is made.
+	ErrInvalidQuery = errors.New(ModuleName, 8, "client: invalid query")
+	// ErrTransactionEvicted is an error returned when a transaction was evicted from the
+	// transaction pool before being included in a block (e.g., because it was replaced).
+	ErrTransactionEvicted = errors.New(ModuleName, 9, "client: transaction evicted")
 )
 
 // RuntimeClient is the runtime client interface.
@@ -75,11 +87,34 @@ type RuntimeClient interface {
 	// GetEvents returns all events emitted in a given block.
 	GetEvents(ctx context.Context, request *GetEventsRequest) ([]*Event, error)
 
+	// GetTransactionByHash looks up an indexed transaction by its hash.
+	//
+	// This requires the transaction indexer to be enabled.
+	GetTransactionByHash(ctx context.Context, request *GetTransactionByHashRequest) (*IndexedTransaction, error)
+
+	// QueryTxs queries the transaction index for transactions matching the given tag conditions.
+	//
+	// This requires the transaction indexer to be enabled.
+	QueryTxs(ctx context.Context, request *QueryTxsRequest) ([]*IndexedTransaction, error)
+
 	// Query makes a runtime-specific query.
 	Query(ctx context.Context, request *QueryRequest) (*QueryResponse, error)
 
 	// WatchBlocks subscribes to blocks for a specific runtimes.
 	WatchBlocks(ctx context.Context, runtimeID common.Namespace) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error)
+
+	// SubmitTxStream submits a transaction to the runtime transaction scheduler and returns a
+	// stream of transaction status updates.
+	//
+	// The stream is closed after a final status update (rejected, included, expired or evicted).
+	SubmitTxStream(ctx context.Context, request *SubmitTxRequest) (<-chan *TxStatusUpdate, pubsub.ClosableSubscription, error)
+
+	// WatchTx subscribes to status updates of a transaction that has already been submitted.
+	//
+	// The stream is closed after a final status update (rejected, included, expired or evicted).
+	// In case the transaction is neither pending in the local transaction pool nor found in the
+	// transaction index, ErrNotFound is returned.
+	WatchTx(ctx context.Context, request *WatchTxRequest) (<-chan *TxStatusUpdate, pubsub.ClosableSubscription, error)
 }
 
 // SubmitTxResult is the raw result of submitting a transaction for processing.
@@ -107,6 +142,83 @@ type SubmitTxMetaResponse struct {
 	CheckTxError *protocol.Error `json:"check_tx_error,omitempty"`
 }
 
+// TxStatus is the status of a submitted transaction.
+type TxStatus uint8
+
+const (
+	// TxStatusChecked is the status of a transaction that passed the local transaction check and
+	// was accepted into the transaction pool.
+	TxStatusChecked TxStatus = iota + 1
+	// TxStatusRejected is the status of a transaction that failed the local transaction check,
+	// either when submitted or when rechecked while pending.
+	TxStatusRejected
+	// TxStatusGossiped is the status of a transaction that was published to the P2P network.
+	TxStatusGossiped
+	// TxStatusScheduled is the status of a transaction that was scheduled in a proposed batch.
+	TxStatusScheduled
+	// TxStatusIncluded is the status of a transaction that was included in a block.
+	TxStatusIncluded
+	// TxStatusExpired is the status of a transaction that expired before being included in a
+	// block.
+	TxStatusExpired
+	// TxStatusEvicted is the status of a transaction that was evicted from the transaction pool
+	// before being included in a block (e.g., because it was replaced or displaced by higher
+	// priority transactions).
+	TxStatusEvicted
+)
+
+// String returns a string representation of the transaction status.
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusChecked:
+		return "checked"
+	case TxStatusRejected:
+		return "rejected"
+	case TxStatusGossiped:
+		return "gossiped"
+	case TxStatusScheduled:
+		return "scheduled"
+	case TxStatusIncluded:
+		return "included"
+	case TxStatusExpired:
+		return "expired"
+	case TxStatusEvicted:
+		return "evicted"
+	default:
+		return fmt.Sprintf("[unknown status: %d]", s)
+	}
+}
+
+// IsFinal returns true iff the status is a final transaction status after which no further
+// status updates are emitted.
+func (s TxStatus) IsFinal() bool {
+	switch s {
+	case TxStatusRejected, TxStatusIncluded, TxStatusExpired, TxStatusEvicted:
+		return true
+	default:
+		return false
+	}
+}
+
+// TxStatusUpdate is a transaction status update.
+type TxStatusUpdate struct {
+	// TxHash is the transaction hash.
+	TxHash hash.Hash `json:"tx_hash"`
+	// Status is the new transaction status.
+	Status TxStatus `json:"status"`
+	// Round is the runtime round in which the status changed. For scheduled transactions, this is
+	// the round the transaction was scheduled for.
+	Round uint64 `json:"round,omitempty"`
+	// Result is the transaction result. It is only set for rejected and included transactions.
+	Result *SubmitTxMetaResponse `json:"result,omitempty"`
+}
+
+// WatchTxRequest is a WatchTx request.
+type WatchTxRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	TxHash    hash.Hash        `json:"tx_hash"`
+}
+
 // CheckTxRequest is a CheckTx request.
 type CheckTxRequest struct {
 	RuntimeID common.Namespace `json:"runtime_id"`
@@ -156,6 +268,81 @@ type PlainEvent struct {
 	Value []byte `json:"value"`
 }
 
+// GetTransactionByHashRequest is a GetTransactionByHash request.
+type GetTransactionByHashRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	TxHash    hash.Hash        `json:"tx_hash"`
+}
+
+// QueryTxsRequest is a QueryTxs request.
+type QueryTxsRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Query     TxQuery          `json:"query"`
+}
+
+// TxQuery is a complex query against the transaction index.
+//
+// A transaction matches the query if it was included in a block with a round in the given range
+// and, for every condition, it emitted a tag with the condition key and one of the condition
+// values.
+type TxQuery struct {
+	// RoundMin is the minimum round (inclusive).
+	RoundMin uint64 `json:"round_min,omitempty"`
+	// RoundMax is the maximum round (inclusive). Zero means there is no upper bound.
+	RoundMax uint64 `json:"round_max,omitempty"`
+
+	// Conditions are the query conditions. At least one condition is required.
+	Conditions []TxQueryCondition `json:"conditions"`
+
+	// Offset is the number of matching transactions to skip.
+	Offset uint64 `json:"offset,omitempty"`
+	// Limit is the maximum number of matching transactions to return. Zero means that the
+	// maximum limit (MaxQueryTxsLimit) is used.
+	Limit uint64 `json:"limit,omitempty"`
+}
+
+// ValidateBasic performs basic transaction query validity checks.
+func (q *TxQuery) ValidateBasic() error {
+	if len(q.Conditions) == 0 {
+		return fmt.Errorf("%w: no conditions", ErrInvalidQuery)
+	}
+	for _, cond := range q.Conditions {
+		if len(cond.Values) == 0 {
+			return fmt.Errorf("%w: condition without values", ErrInvalidQuery)
+		}
+	}
+	if q.RoundMax != 0 && q.RoundMin > q.RoundMax {
+		return fmt.Errorf("%w: minimum round greater than maximum round", ErrInvalidQuery)
+	}
+	if q.Limit > MaxQueryTxsLimit {
+		return fmt.Errorf("%w: limit exceeds maximum (%d)", ErrInvalidQuery, MaxQueryTxsLimit)
+	}
+	return nil
+}
+
+// TxQueryCondition is a transaction query condition.
+type TxQueryCondition struct {
+	// Key is the tag key that should be matched.
+	Key []byte `json:"key"`
+	// Values are a list of tag values that the given tag key should have. They are combined using
+	// an OR query which means that any of the values will match.
+	Values [][]byte `json:"values"`
+}
+
+// IndexedTransaction is an indexed runtime transaction together with its results.
+type IndexedTransaction struct {
+	// Round is the round in which the transaction was executed.
+	Round uint64 `json:"round"`
+	// BatchOrder is the order of the transaction in the execution batch.
+	BatchOrder uint32 `json:"batch_order"`
+	// TxHash is the transaction hash.
+	TxHash hash.Hash `json:"tx_hash"`
+
+	Tx     []byte        `json:"tx"`
+	Result []byte        `json:"result"`
+	Events []*PlainEvent `json:"events,omitempty"`
+}
+
 // QueryRequest is a Query request.
 type QueryRequest struct {
 	RuntimeID common.Namespace `json:"runtime_id"`
@@ -39,11 +39,19 @@ var (
 	methodGetTransactionsWithResults = serviceName.NewMethod("GetTransactionsWithResults", GetTransactionsRequest{})
 	// methodGetEvents is the GetEvents method.
 	methodGetEvents = serviceName.NewMethod("GetEvents", GetEventsRequest{})
+	// methodGetTransactionByHash is the GetTransactionByHash method.
+	methodGetTransactionByHash = serviceName.NewMethod("GetTransactionByHash", GetTransactionByHashRequest{})
+	// methodQueryTxs is the QueryTxs method.
+	methodQueryTxs = serviceName.NewMethod("QueryTxs", QueryTxsRequest{})
 	// methodQuery is the Query method.
 	methodQuery = serviceName.NewMethod("Query", QueryRequest{})
 
 	// methodWatchBlocks is the WatchBlocks method.
 	methodWatchBlocks = serviceName.NewMethod("WatchBlocks", common.Namespace{})
+	// methodSubmitTxStream is the SubmitTxStream method.
+	methodSubmitTxStream = serviceName.NewMethod("SubmitTxStream", SubmitTxRequest{})
+	// methodWatchTx is the WatchTx method.
+	methodWatchTx = serviceName.NewMethod("WatchTx", WatchTxRequest{})
 
 	// serviceDesc is the gRPC service descriptor.
 	serviceDesc = grpc.ServiceDesc{
@@ -90,6 +98,14 @@ var (
 				MethodName: methodGetEvents.ShortName(),
 				Handler:    handlerGetEvents,
 			},
+			{
+				MethodName: methodGetTransactionByHash.ShortName(),
+				Handler:    handlerGetTransactionByHash,
+			},
+			{
+				MethodName: methodQueryTxs.ShortName(),
+				Handler:    handlerQueryTxs,
+			},
 			{
 				MethodName: methodQuery.ShortName(),
 				Handler:    handlerQuery,
@@ -101,6 +117,16 @@ var (
 				Handler:       handlerWatchBlocks,
 				ServerStreams: true,
 			},
+			{
+				StreamName:    methodSubmitTxStream.ShortName(),
+				Handler:       handlerSubmitTxStream,
+				ServerStreams: true,
+			},
+			{
+				StreamName:    methodWatchTx.ShortName(),
+				Handler:       handlerWatchTx,
+				ServerStreams: true,
+			},
 		},
 	}
 )
@@ -368,6 +394,54 @@ func handlerGetEvents( // nolint: golint
 	return interceptor(ctx, &rq, info, handler)
 }
 
+func handlerGetTransactionByHash( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var rq GetTransactionByHashRequest
+	if err := dec(&rq); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		rsp, err := srv.(RuntimeClient).GetTransactionByHash(ctx, &rq)
+		return rsp, errorWrapNotFound(err)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetTransactionByHash.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		rsp, err := srv.(RuntimeClient).GetTransactionByHash(ctx, req.(*GetTransactionByHashRequest))
+		return rsp, errorWrapNotFound(err)
+	}
+	return interceptor(ctx, &rq, info, handler)
+}
+
+func handlerQueryTxs( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var rq QueryTxsRequest
+	if err := dec(&rq); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuntimeClient).QueryTxs(ctx, &rq)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodQueryTxs.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuntimeClient).QueryTxs(ctx, req.(*QueryTxsRequest))
+	}
+	return interceptor(ctx, &rq, info, handler)
+}
+
 func handlerQuery( // nolint: golint
 	srv interface{},
 	ctx context.Context,
@@ -422,6 +496,55 @@ func handlerWatchBlocks(srv interface{}, stream grpc.ServerStream) error {
 	}
 }
 
+func handlerSubmitTxStream(srv interface{}, stream grpc.ServerStream) error {
+	var rq SubmitTxRequest
+	if err := stream.RecvMsg(&rq); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	ch, sub, err := srv.(RuntimeClient).SubmitTxStream(ctx, &rq)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	return sendTxStatusUpdates(ctx, stream, ch)
+}
+
+func handlerWatchTx(srv interface{}, stream grpc.ServerStream) error {
+	var rq WatchTxRequest
+	if err := stream.RecvMsg(&rq); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	ch, sub, err := srv.(RuntimeClient).WatchTx(ctx, &rq)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	return sendTxStatusUpdates(ctx, stream, ch)
+}
+
+func sendTxStatusUpdates(ctx context.Context, stream grpc.ServerStream, ch <-chan *TxStatusUpdate) error {
+	for {
+		select {
+		case update, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			if err := stream.SendMsg(update); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // RegisterService registers a new runtime client service with the given gRPC server.
 func RegisterService(server *grpc.Server, service RuntimeClient) {
 	server.RegisterService(&serviceDesc, service)
@@ -503,6 +626,22 @@ func (c *runtimeClient) GetEvents(ctx context.Context, request *GetEventsRequest
 	return rsp, nil
 }
 
+func (c *runtimeClient) GetTransactionByHash(ctx context.Context, request *GetTransactionByHashRequest) (*IndexedTransaction, error) {
+	var rsp IndexedTransaction
+	if err := c.conn.Invoke(ctx, methodGetTransactionByHash.FullName(), request, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *runtimeClient) QueryTxs(ctx context.Context, request *QueryTxsRequest) ([]*IndexedTransaction, error) {
+	var rsp []*IndexedTransaction
+	if err := c.conn.Invoke(ctx, methodQueryTxs.FullName(), request, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
 func (c *runtimeClient) Query(ctx context.Context, request *QueryRequest) (*QueryResponse, error) {
 	var rsp QueryResponse
 	if err := c.conn.Invoke(ctx, methodQuery.FullName(), request, &rsp); err != nil {
@@ -546,6 +685,54 @@ func (c *runtimeClient) WatchBlocks(ctx context.Context, runtimeID common.Namesp
 	return ch, sub, nil
 }
 
+func (c *runtimeClient) SubmitTxStream(ctx context.Context, request *SubmitTxRequest) (<-chan *TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	return c.watchTxStatusUpdates(ctx, &serviceDesc.Streams[1], methodSubmitTxStream.FullName(), request)
+}
+
+func (c *runtimeClient) WatchTx(ctx context.Context, request *WatchTxRequest) (<-chan *TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	return c.watchTxStatusUpdates(ctx, &serviceDesc.Streams[2], methodWatchTx.FullName(), request)
+}
+
+func (c *runtimeClient) watchTxStatusUpdates(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	method string,
+	request interface{},
+) (<-chan *TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+
+	stream, err := c.conn.NewStream(ctx, desc, method)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = stream.SendMsg(request); err != nil {
+		return nil, nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan *TxStatusUpdate)
+	go func() {
+		defer close(ch)
+
+		for {
+			var update TxStatusUpdate
+			if serr := stream.RecvMsg(&update); serr != nil {
+				return
+			}
+
+			select {
+			case ch <- &update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, sub, nil
+}
+
 // NewRuntimeClient creates a new gRPC runtime client service.
 func NewRuntimeClient(c *grpc.ClientConn) RuntimeClient {
 	return &runtimeClient{
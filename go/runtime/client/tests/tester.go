@@ -11,6 +11,7 @@ import (
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/mock"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
@@ -51,6 +52,13 @@ func ClientImplementationTests(
 		defer cancelFunc()
 		testFailSubmitTransaction(ctx, t, runtimeID, client, testInput)
 	})
+
+	streamInput := "cuttlefish at: " + time.Now().String()
+	t.Run("SubmitTxStream", func(t *testing.T) {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+		defer cancelFunc()
+		testSubmitTransactionStream(ctx, t, runtimeID, client, streamInput)
+	})
 }
 
 func testSubmitTransaction(
@@ -101,6 +109,76 @@ func testFailSubmitTransaction(
 	require.Error(t, err, "SubmitTx should fail for unsupported runtime")
 }
 
+func testSubmitTransactionStream(
+	ctx context.Context,
+	t *testing.T,
+	runtimeID common.Namespace,
+	c api.RuntimeClient,
+	input string,
+) {
+	waitFinal := func(ch <-chan *api.TxStatusUpdate) *api.TxStatusUpdate {
+		for {
+			select {
+			case update, ok := <-ch:
+				require.True(t, ok, "status update stream should not be closed before a final update")
+				if update.Status.IsFinal() {
+					return update
+				}
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for final status update")
+			}
+		}
+	}
+
+	// Submit a test transaction.
+	testInput := []byte(input)
+	ch, sub, err := c.SubmitTxStream(ctx, &api.SubmitTxRequest{Data: testInput, RuntimeID: runtimeID})
+	require.NoError(t, err, "SubmitTxStream")
+	defer sub.Close()
+
+	update := waitFinal(ch)
+	require.EqualValues(t, api.TxStatusIncluded, update.Status, "transaction should be included")
+	require.EqualValues(t, hash.NewFromBytes(testInput), update.TxHash, "status update should be for the submitted transaction")
+	require.NotNil(t, update.Result, "included transaction should have a result")
+	require.EqualValues(t, testInput, update.Result.Output)
+	require.True(t, update.Round > 0, "included transaction round should be non zero")
+
+	// Watching an already included transaction should terminate (with the final status in case
+	// the transaction indexer is enabled).
+	includedRound := update.Round
+	wch, wsub, err := c.WatchTx(ctx, &api.WatchTxRequest{RuntimeID: runtimeID, TxHash: hash.NewFromBytes(testInput)})
+	if err == nil {
+		defer wsub.Close()
+		if update, ok := <-wch; ok {
+			require.EqualValues(t, api.TxStatusIncluded, update.Status, "transaction should be included")
+			require.EqualValues(t, includedRound, update.Round, "included transaction round should match")
+			_, ok = <-wch
+			require.False(t, ok, "stream should be closed after the final status update")
+		}
+	}
+
+	// Watching an unknown transaction should terminate.
+	wch, wsub, err = c.WatchTx(ctx, &api.WatchTxRequest{RuntimeID: runtimeID, TxHash: hash.NewFromBytes([]byte("unknown"))})
+	if err == nil {
+		defer wsub.Close()
+		_, ok := <-wch
+		require.False(t, ok, "stream for an unknown transaction should be closed")
+	}
+
+	// Failures during CheckTx.
+	ch, sub, err = c.SubmitTxStream(ctx, &api.SubmitTxRequest{Data: mock.CheckTxFailInput, RuntimeID: runtimeID})
+	require.NoError(t, err, "SubmitTxStream")
+	defer sub.Close()
+
+	update = waitFinal(ch)
+	require.EqualValues(t, api.TxStatusRejected, update.Status, "transaction should be rejected")
+	require.NotNil(t, update.Result, "rejected transaction should have a result")
+	require.EqualValues(t, &protocol.Error{
+		Module: "mock",
+		Code:   1,
+	}, update.Result.CheckTxError, "SubmitTxStream should fail check tx")
+}
+
 func testQuery(
 	ctx context.Context,
 	t *testing.T,
@@ -29,6 +29,25 @@ type Config struct {
 
 	// LocalConfig is the node-local runtime configuration.
 	LocalConfig map[string]interface{}
+
+	// ResourceLimits are the optional resource limits for the provisioned runtime. Provisioners
+	// that do not support enforcing resource limits ignore this field.
+	ResourceLimits *ResourceLimits
+}
+
+// ResourceLimits are the resource limits for a provisioned runtime.
+type ResourceLimits struct {
+	// CPUQuota is the maximum CPU bandwidth available to the runtime expressed as a number of CPUs
+	// (e.g., 1.5 means one and a half CPUs). Zero means no limit.
+	CPUQuota float64 `mapstructure:"cpu_quota"`
+
+	// MemoryLimit is the maximum amount of memory (in bytes) available to the runtime. Zero means
+	// no limit.
+	MemoryLimit uint64 `mapstructure:"memory_limit"`
+
+	// PidsLimit is the maximum number of processes and threads available to the runtime. Zero
+	// means no limit.
+	PidsLimit uint64 `mapstructure:"pids_limit"`
 }
 
 // Provisioner is the runtime provisioner interface.
@@ -71,10 +90,13 @@ type RuntimeEventEmitter interface {
 
 // Event is a runtime host event.
 type Event struct {
-	Started       *StartedEvent
-	FailedToStart *FailedToStartEvent
-	Stopped       *StoppedEvent
-	Updated       *UpdatedEvent
+	Started          *StartedEvent
+	FailedToStart    *FailedToStartEvent
+	Stopped          *StoppedEvent
+	Updated          *UpdatedEvent
+	NextVersion      *NextVersionEvent
+	ResourceExceeded *ResourceExceededEvent
+	Degraded         *DegradedEvent
 }
 
 // StartedEvent is a runtime started event.
@@ -102,3 +124,28 @@ type UpdatedEvent struct {
 	// not running inside a TEE.
 	CapabilityTEE *node.CapabilityTEE
 }
+
+// ResourceExceededEvent is a runtime resource limit exceeded event. It is emitted when the runtime
+// is terminated because it exceeded one of its resource limits and is followed by a StoppedEvent.
+type ResourceExceededEvent struct {
+	// Resource is the name of the exceeded resource.
+	Resource string
+}
+
+// NextVersionEvent is a next runtime version status changed event. It is only emitted by hosts
+// that provision the next version of a runtime ahead of a scheduled version change.
+type NextVersionEvent struct {
+	// Started is the started event of the next runtime version. It is nil in case the next version
+	// is not running.
+	Started *StartedEvent
+}
+
+// DegradedEvent is a runtime degraded state changed event. A runtime is degraded while it has been
+// restarted more often than allowed by its restart policy.
+type DegradedEvent struct {
+	// Degraded is true iff the runtime is degraded.
+	Degraded bool
+
+	// Restarts is the number of restarts within the restart policy window.
+	Restarts int
+}
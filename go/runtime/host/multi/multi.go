@@ -0,0 +1,254 @@
+// Package multi implements support for hosting multiple versions of the same runtime side by side
+// and atomically switching between them.
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+)
+
+var (
+	// ErrNoNextVersion is the error returned when switching to the next version of a runtime is
+	// requested but no next version is being hosted.
+	ErrNoNextVersion = errors.New("runtime/host/multi: no next version")
+
+	// ErrNextVersionNotRunning is the error returned when switching to the next version of a
+	// runtime is requested but the next version has not (yet) started.
+	ErrNextVersionNotRunning = errors.New("runtime/host/multi: next version is not running")
+)
+
+// aggregatedHost is a single runtime host that is part of an aggregate.
+type aggregatedHost struct {
+	host host.Runtime
+
+	// started is the last started event emitted by the host. It is nil in case the host is not
+	// running. Protected by the aggregate lock.
+	started *host.StartedEvent
+	// degraded is the last degraded state reported by the host. Protected by the aggregate lock.
+	degraded bool
+
+	// inflight keeps track of calls that are currently being processed by the host.
+	inflight sync.WaitGroup
+
+	stopCh chan struct{}
+}
+
+func (ah *aggregatedHost) stop() {
+	ah.host.Stop()
+	close(ah.stopCh)
+}
+
+// Aggregate is a runtime host that aggregates the currently active version of a runtime and
+// optionally the next version of the same runtime which is provisioned ahead of a scheduled
+// version change.
+//
+// All calls are routed to the active version. Events of the active version are forwarded as-is,
+// while status changes of the next version are emitted as NextVersion events.
+type Aggregate struct {
+	sync.RWMutex
+
+	id common.Namespace
+
+	active *aggregatedHost
+	next   *aggregatedHost
+
+	stopped  bool
+	notifier *pubsub.Broker
+
+	logger *logging.Logger
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) ID() common.Namespace {
+	return agg.id
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) Call(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
+	agg.RLock()
+	active := agg.active
+	active.inflight.Add(1)
+	agg.RUnlock()
+	defer active.inflight.Done()
+
+	return active.host.Call(ctx, body)
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) WatchEvents(ctx context.Context) (<-chan *host.Event, pubsub.ClosableSubscription, error) {
+	typedCh := make(chan *host.Event)
+	sub := agg.notifier.Subscribe()
+	sub.Unwrap(typedCh)
+
+	return typedCh, sub, nil
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) Start() error {
+	agg.RLock()
+	hosts := []*aggregatedHost{agg.active}
+	if agg.next != nil {
+		hosts = append(hosts, agg.next)
+	}
+	agg.RUnlock()
+
+	for _, ah := range hosts {
+		ch, sub, err := ah.host.WatchEvents(context.Background())
+		if err != nil {
+			return fmt.Errorf("runtime/host/multi: failed to watch host events: %w", err)
+		}
+		go agg.watchEvents(ah, ch, sub)
+
+		if err = ah.host.Start(); err != nil {
+			return fmt.Errorf("runtime/host/multi: failed to start host: %w", err)
+		}
+	}
+	return nil
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) Abort(ctx context.Context, force bool) error {
+	agg.RLock()
+	active := agg.active
+	agg.RUnlock()
+
+	return active.host.Abort(ctx, force)
+}
+
+// Implements host.Runtime.
+func (agg *Aggregate) Stop() {
+	agg.Lock()
+	defer agg.Unlock()
+
+	if agg.stopped {
+		return
+	}
+	agg.stopped = true
+
+	agg.active.stop()
+	if agg.next != nil {
+		agg.next.stop()
+	}
+}
+
+// HasNext returns true iff the aggregate is hosting the next version of the runtime.
+func (agg *Aggregate) HasNext() bool {
+	agg.RLock()
+	defer agg.RUnlock()
+
+	return agg.next != nil
+}
+
+// SwitchToNext atomically switches the active runtime host to the next version.
+//
+// After the switch all new calls are routed to the new version. The previous version is stopped
+// as soon as all of its in-flight calls complete.
+func (agg *Aggregate) SwitchToNext() error {
+	agg.Lock()
+	defer agg.Unlock()
+
+	if agg.stopped || agg.next == nil {
+		return ErrNoNextVersion
+	}
+	if agg.next.started == nil {
+		return ErrNextVersionNotRunning
+	}
+
+	old := agg.active
+	agg.active, agg.next = agg.next, nil
+
+	agg.logger.Info("switched to next runtime version",
+		"version", agg.active.started.Version,
+	)
+
+	agg.notifier.Broadcast(&host.Event{Started: agg.active.started})
+	agg.notifier.Broadcast(&host.Event{NextVersion: &host.NextVersionEvent{}})
+	if old.degraded != agg.active.degraded {
+		agg.notifier.Broadcast(&host.Event{Degraded: &host.DegradedEvent{Degraded: agg.active.degraded}})
+	}
+
+	go func() {
+		old.inflight.Wait()
+		old.stop()
+	}()
+
+	return nil
+}
+
+func (agg *Aggregate) watchEvents(ah *aggregatedHost, ch <-chan *host.Event, sub pubsub.ClosableSubscription) {
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ah.stopCh:
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			agg.handleEvent(ah, ev)
+		}
+	}
+}
+
+func (agg *Aggregate) handleEvent(ah *aggregatedHost, ev *host.Event) {
+	agg.Lock()
+	defer agg.Unlock()
+
+	switch {
+	case ev.Started != nil:
+		ah.started = ev.Started
+	case ev.Updated != nil:
+		if ah.started != nil {
+			started := *ah.started
+			started.CapabilityTEE = ev.Updated.CapabilityTEE
+			ah.started = &started
+		}
+	case ev.FailedToStart != nil, ev.Stopped != nil:
+		ah.started = nil
+	case ev.Degraded != nil:
+		ah.degraded = ev.Degraded.Degraded
+	}
+
+	switch ah {
+	case agg.active:
+		agg.notifier.Broadcast(ev)
+	case agg.next:
+		agg.notifier.Broadcast(&host.Event{NextVersion: &host.NextVersionEvent{Started: ah.started}})
+	default:
+		// Events from hosts that are being retired are ignored.
+	}
+}
+
+// New creates a new runtime host aggregate with the given active version and an optional next
+// version of the same runtime.
+func New(id common.Namespace, active, next host.Runtime) (*Aggregate, error) {
+	if active == nil {
+		return nil, fmt.Errorf("runtime/host/multi: active host must not be nil")
+	}
+
+	agg := &Aggregate{
+		id: id,
+		active: &aggregatedHost{
+			host:   active,
+			stopCh: make(chan struct{}),
+		},
+		notifier: pubsub.NewBroker(false),
+		logger:   logging.GetLogger("runtime/host/multi").With("runtime_id", id),
+	}
+	if next != nil {
+		agg.next = &aggregatedHost{
+			host:   next,
+			stopCh: make(chan struct{}),
+		}
+	}
+	return agg, nil
+}
@@ -0,0 +1,85 @@
+package multi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/mock"
+)
+
+const recvTimeout = 5 * time.Second
+
+func TestAggregate(t *testing.T) {
+	require := require.New(t)
+
+	var id common.Namespace
+	cfg := host.Config{RuntimeID: id}
+	provisioner := mock.New()
+	active, err := provisioner.NewRuntime(context.Background(), cfg)
+	require.NoError(err, "NewRuntime")
+	next, err := provisioner.NewRuntime(context.Background(), cfg)
+	require.NoError(err, "NewRuntime")
+
+	agg, err := New(id, active, nil)
+	require.NoError(err, "New")
+	require.False(agg.HasNext(), "HasNext should be false without a next version")
+	require.ErrorIs(agg.SwitchToNext(), ErrNoNextVersion)
+
+	agg, err = New(id, active, next)
+	require.NoError(err, "New")
+	require.True(agg.HasNext(), "HasNext should be true with a next version")
+
+	ch, sub, err := agg.WatchEvents(context.Background())
+	require.NoError(err, "WatchEvents")
+	defer sub.Close()
+
+	err = agg.Start()
+	require.NoError(err, "Start")
+	defer agg.Stop()
+
+	// Both the active and the next version should start.
+	var gotStarted, gotNextStarted bool
+	for !gotStarted || !gotNextStarted {
+		select {
+		case ev := <-ch:
+			switch {
+			case ev.Started != nil:
+				gotStarted = true
+			case ev.NextVersion != nil:
+				require.NotNil(ev.NextVersion.Started, "next version should be started")
+				gotNextStarted = true
+			default:
+				t.Fatalf("unexpected event: %+v", ev)
+			}
+		case <-time.After(recvTimeout):
+			t.Fatalf("failed to receive started events")
+		}
+	}
+
+	err = agg.SwitchToNext()
+	require.NoError(err, "SwitchToNext")
+	require.False(agg.HasNext(), "HasNext should be false after switch")
+
+	// The next version should now be reported as active.
+	select {
+	case ev := <-ch:
+		require.NotNil(ev.Started, "switch should emit a started event")
+	case <-time.After(recvTimeout):
+		t.Fatalf("failed to receive started event")
+	}
+	select {
+	case ev := <-ch:
+		require.NotNil(ev.NextVersion, "switch should emit a next version event")
+		require.Nil(ev.NextVersion.Started, "next version should no longer be running")
+	case <-time.After(recvTimeout):
+		t.Fatalf("failed to receive next version event")
+	}
+
+	// There should be no next version to switch to anymore.
+	require.ErrorIs(agg.SwitchToNext(), ErrNoNextVersion)
+}
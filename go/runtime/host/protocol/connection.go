@@ -190,8 +190,9 @@ var validStateTransitions = map[state][]state{
 type connection struct { // nolint: maligned
 	sync.RWMutex
 
-	conn  net.Conn
-	codec *cbor.MessageCodec
+	conn     net.Conn
+	codec    *cbor.MessageCodec
+	recorder *Recorder
 
 	runtimeID common.Namespace
 	handler   Handler
@@ -252,6 +253,14 @@ func (c *connection) Close() {
 
 	// Wait for all the connection-handling goroutines to terminate.
 	c.quitWg.Wait()
+
+	if c.recorder != nil {
+		if err := c.recorder.Close(); err != nil {
+			c.logger.Error("error while closing recorder",
+				"err", err,
+			)
+		}
+	}
 }
 
 // Implements Connection.
@@ -347,6 +356,9 @@ func (c *connection) workerOutgoing() {
 				)
 			}
 			// Outgoing message, send it.
+			if c.recorder != nil {
+				c.recorder.Record(RecordDirectionOutgoing, msg)
+			}
 			if err := c.codec.Write(msg); err != nil {
 				c.logger.Error("error while sending message",
 					"err", err,
@@ -473,6 +485,9 @@ func (c *connection) workerIncoming() {
 			)
 			break
 		}
+		if c.recorder != nil {
+			c.recorder.Record(RecordDirectionIncoming, &message)
+		}
 
 		// Handle message in a separate goroutine.
 		go c.handleMessage(ctx, &message)
@@ -558,19 +573,28 @@ func (c *connection) InitHost(ctx context.Context, conn net.Conn, hi *HostInfo)
 
 // NewConnection creates a new uninitialized RHP connection.
 func NewConnection(logger *logging.Logger, runtimeID common.Namespace, handler Handler) (Connection, error) {
+	return newConnection(logger, runtimeID, handler, nil), nil
+}
+
+// NewRecordingConnection creates a new uninitialized RHP connection that records all exchanged
+// messages using the given recorder. The recorder is closed when the connection is closed.
+func NewRecordingConnection(logger *logging.Logger, runtimeID common.Namespace, handler Handler, recorder *Recorder) (Connection, error) {
+	return newConnection(logger, runtimeID, handler, recorder), nil
+}
+
+func newConnection(logger *logging.Logger, runtimeID common.Namespace, handler Handler, recorder *Recorder) *connection {
 	metricsOnce.Do(func() {
 		prometheus.MustRegister(rhpCollectors...)
 	})
 
-	c := &connection{
+	return &connection{
 		runtimeID:       runtimeID,
 		handler:         handler,
+		recorder:        recorder,
 		state:           stateUninitialized,
 		pendingRequests: make(map[uint64]chan *Body),
 		outCh:           make(chan *Message),
 		closeCh:         make(chan struct{}),
 		logger:          logger,
 	}
-
-	return c, nil
 }
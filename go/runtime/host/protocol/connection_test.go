@@ -1,7 +1,9 @@
 package protocol
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net"
 	"testing"
 
@@ -14,6 +16,15 @@ import (
 
 // TODO: add tests with incorrect handlers (wrong version, malformed response)
 
+// nopWriteCloser is a writer with a no-op Close method.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
 type testHandler struct {
 	calls int
 }
@@ -122,3 +133,52 @@ func TestBigMessage(t *testing.T) {
 	require.EqualValues(0, handlerA.calls, "Handler A must not be called")
 	require.EqualValues(1, handlerB.calls, "Handler B must be called")
 }
+
+func TestRecording(t *testing.T) {
+	require := require.New(t)
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("test conn"), 0)
+	logger := logging.GetLogger("test")
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(nopWriteCloser{&buf})
+
+	connA, connB := net.Pipe()
+	handlerA := &testHandler{}
+	protoA, err := NewConnection(logger, runtimeID, handlerA)
+	require.NoError(err, "A.New()")
+	handlerB := &testHandler{}
+	protoB, err := NewRecordingConnection(logger, runtimeID, handlerB, recorder)
+	require.NoError(err, "B.New()")
+
+	err = protoA.InitGuest(context.Background(), connA)
+	require.NoError(err, "A.InitGuest()")
+	_, err = protoB.InitHost(context.Background(), connB, &HostInfo{})
+	require.NoError(err, "B.InitHost()")
+
+	reqB := Body{RuntimeRPCCallRequest: &RuntimeRPCCallRequest{Request: []byte("hello")}}
+	_, err = protoB.Call(context.Background(), &reqB)
+	require.NoError(err, "B.Call()")
+
+	protoA.Close()
+	protoB.Close()
+
+	msgs, err := NewRecordingReader(&buf).ReadAll()
+	require.NoError(err, "ReadAll")
+	require.Len(msgs, 4, "all exchanged messages should be recorded")
+
+	require.Equal(RecordDirectionOutgoing, msgs[0].Direction)
+	require.Equal(MessageRequest, msgs[0].Message.MessageType)
+	require.NotNil(msgs[0].Message.Body.RuntimeInfoRequest)
+	require.Equal(RecordDirectionIncoming, msgs[1].Direction)
+	require.Equal(MessageResponse, msgs[1].Message.MessageType)
+	require.NotNil(msgs[1].Message.Body.RuntimeInfoResponse)
+	require.Equal(RecordDirectionOutgoing, msgs[2].Direction)
+	require.EqualValues(reqB, msgs[2].Message.Body)
+	require.Equal(RecordDirectionIncoming, msgs[3].Direction)
+	require.EqualValues(reqB, msgs[3].Message.Body)
+	require.Equal(msgs[2].Message.ID, msgs[3].Message.ID, "response should match the request")
+
+	for i := 1; i < len(msgs); i++ {
+		require.GreaterOrEqual(msgs[i].Timestamp, msgs[i-1].Timestamp, "timestamps should be ordered")
+	}
+}
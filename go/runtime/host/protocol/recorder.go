@@ -0,0 +1,162 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// recordingModuleName is the module name used for recording codec metrics.
+const recordingModuleName = "rhp/recording"
+
+// RecordDirection is the direction of a recorded message.
+type RecordDirection uint8
+
+const (
+	// RecordDirectionOutgoing is the direction of messages sent by the recording side.
+	RecordDirectionOutgoing RecordDirection = 0
+	// RecordDirectionIncoming is the direction of messages received by the recording side.
+	RecordDirectionIncoming RecordDirection = 1
+)
+
+// String returns a string representation of the record direction.
+func (d RecordDirection) String() string {
+	switch d {
+	case RecordDirectionOutgoing:
+		return "outgoing"
+	case RecordDirectionIncoming:
+		return "incoming"
+	default:
+		return fmt.Sprintf("[malformed: %d]", d)
+	}
+}
+
+// RecordedMessage is a protocol message as stored in a recording.
+type RecordedMessage struct {
+	// Timestamp is the UNIX timestamp (in nanoseconds) at which the message has been sent or
+	// received.
+	Timestamp int64 `json:"timestamp"`
+
+	// Direction is the direction of the message.
+	Direction RecordDirection `json:"direction"`
+
+	// Message is the recorded message.
+	Message Message `json:"message"`
+}
+
+// Recorder records framed protocol messages exchanged over a connection.
+//
+// Recordings are a sequence of length-prefixed CBOR-encoded RecordedMessage structures.
+type Recorder struct {
+	sync.Mutex
+
+	w      io.WriteCloser
+	codec  *cbor.MessageWriter
+	failed bool
+}
+
+// Record records the given message.
+//
+// Recording failures do not affect the connection, after the first failure no further messages
+// are recorded.
+func (r *Recorder) Record(direction RecordDirection, msg *Message) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.failed {
+		return
+	}
+
+	err := r.codec.Write(&RecordedMessage{
+		Timestamp: time.Now().UnixNano(),
+		Direction: direction,
+		Message:   *msg,
+	})
+	if err != nil {
+		r.failed = true
+	}
+}
+
+// Close closes the underlying recording writer.
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.failed = true
+	return r.w.Close()
+}
+
+// NewRecorder creates a new protocol message recorder writing to the given writer.
+func NewRecorder(w io.WriteCloser) *Recorder {
+	codec := cbor.NewMessageCodec(&struct {
+		io.Reader
+		io.Writer
+	}{nil, w}, recordingModuleName)
+
+	return &Recorder{
+		w:     w,
+		codec: &codec.MessageWriter,
+	}
+}
+
+// NewFileRecorder creates a new protocol message recorder writing to a newly created file at the
+// given path.
+func NewFileRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("rhp: failed to create recording: %w", err)
+	}
+	return NewRecorder(f), nil
+}
+
+// RecordingReader reads protocol messages from a recording.
+type RecordingReader struct {
+	codec *cbor.MessageReader
+}
+
+// Read reads the next recorded message.
+//
+// In case there are no more messages in the recording, io.EOF is returned.
+func (rr *RecordingReader) Read() (*RecordedMessage, error) {
+	var msg RecordedMessage
+	if err := rr.codec.Read(&msg); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("rhp: truncated recording: %w", err)
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// ReadAll reads all remaining recorded messages.
+func (rr *RecordingReader) ReadAll() ([]*RecordedMessage, error) {
+	var msgs []*RecordedMessage
+	for {
+		msg, err := rr.Read()
+		switch err {
+		case nil:
+			msgs = append(msgs, msg)
+		case io.EOF:
+			return msgs, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// NewRecordingReader creates a new reader for recorded protocol messages.
+func NewRecordingReader(r io.Reader) *RecordingReader {
+	codec := cbor.NewMessageCodec(&struct {
+		io.Reader
+		io.Writer
+	}{r, nil}, recordingModuleName)
+
+	return &RecordingReader{
+		codec: &codec.MessageReader,
+	}
+}
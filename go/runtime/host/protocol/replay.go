@@ -0,0 +1,186 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// replayModuleName is the module name used for replay codec metrics.
+const replayModuleName = "rhp/replay"
+
+// ReplayMismatch is a difference between the recorded and the replayed runtime behavior.
+type ReplayMismatch struct {
+	// Request is the request that caused the mismatch. For mismatching responses it is the host
+	// request, for unrecorded runtime requests it is the runtime request.
+	Request *Message
+
+	// Expected is the recorded body. It is nil in case there is no matching recorded message.
+	Expected *Body
+	// Actual is the body produced by the replayed runtime. It is nil in case the runtime did not
+	// produce a message.
+	Actual *Body
+
+	// Reason is a human readable description of the mismatch.
+	Reason string
+}
+
+// ReplayResult is the result of replaying a recording.
+type ReplayResult struct {
+	// HostRequests is the number of host requests that have been replayed.
+	HostRequests int
+	// RuntimeRequests is the number of runtime requests that have been answered.
+	RuntimeRequests int
+
+	// Mismatches are the differences between the recorded and replayed runtime behavior.
+	Mismatches []*ReplayMismatch
+}
+
+// recordedExchange is a request sent by the runtime together with the recorded host response.
+type recordedExchange struct {
+	request  []byte
+	response *Body
+	used     bool
+}
+
+// Replay drives a runtime connected via the given connection with the recorded messages.
+//
+// The recording must have been made by the host. All recorded host requests are sent to the
+// runtime in order and the runtime responses are compared against the recorded responses. Any
+// requests made by the runtime (e.g., storage sync requests) are answered with the recorded host
+// responses to identical recorded requests.
+func Replay(ctx context.Context, conn net.Conn, msgs []*RecordedMessage) (*ReplayResult, error) {
+	// Split the recording into host requests with expected runtime responses and runtime requests
+	// with recorded host responses.
+	var hostRequests []*Message
+	expected := make(map[uint64]*Body)
+	runtimeRequests := make(map[uint64][]byte)
+	var exchanges []*recordedExchange
+	for _, rm := range msgs {
+		msg := rm.Message
+		switch {
+		case rm.Direction == RecordDirectionOutgoing && msg.MessageType == MessageRequest:
+			hostRequests = append(hostRequests, &msg)
+		case rm.Direction == RecordDirectionIncoming && msg.MessageType == MessageResponse:
+			expected[msg.ID] = &msg.Body
+		case rm.Direction == RecordDirectionIncoming && msg.MessageType == MessageRequest:
+			runtimeRequests[msg.ID] = cbor.Marshal(msg.Body)
+		case rm.Direction == RecordDirectionOutgoing && msg.MessageType == MessageResponse:
+			rq, ok := runtimeRequests[msg.ID]
+			if !ok {
+				return nil, fmt.Errorf("rhp: recorded response without request (id: %d)", msg.ID)
+			}
+			delete(runtimeRequests, msg.ID)
+			exchanges = append(exchanges, &recordedExchange{request: rq, response: &msg.Body})
+		default:
+			return nil, fmt.Errorf("rhp: malformed recorded message (direction: %s type: %s)", rm.Direction, msg.MessageType)
+		}
+	}
+
+	codec := cbor.NewMessageCodec(conn, replayModuleName)
+	incomingCh := make(chan *Message)
+	go func() {
+		defer close(incomingCh)
+
+		for {
+			var msg Message
+			if err := codec.Read(&msg); err != nil {
+				return
+			}
+			select {
+			case incomingCh <- &msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var result ReplayResult
+	for _, rq := range hostRequests {
+		if err := codec.Write(rq); err != nil {
+			return nil, fmt.Errorf("rhp: failed to send request: %w", err)
+		}
+		result.HostRequests++
+
+		exp := expected[rq.ID]
+	WaitResponse:
+		for {
+			var msg *Message
+			select {
+			case msg = <-incomingCh:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			switch {
+			case msg == nil:
+				// Runtime has terminated the connection.
+				if exp != nil {
+					result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+						Request:  rq,
+						Expected: exp,
+						Reason:   "runtime terminated the connection",
+					})
+				}
+				return &result, nil
+			case msg.MessageType == MessageRequest:
+				// Request from the runtime, answer it with the recorded response.
+				result.RuntimeRequests++
+
+				rsp := replayRuntimeRequest(exchanges, msg)
+				if rsp == nil {
+					result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+						Request: msg,
+						Actual:  &msg.Body,
+						Reason:  "runtime request not in recording",
+					})
+					rsp = errorToBody(fmt.Errorf("rhp: request not in recording"))
+				}
+				if err := codec.Write(newResponseMessage(msg, rsp)); err != nil {
+					return nil, fmt.Errorf("rhp: failed to send response: %w", err)
+				}
+			case msg.MessageType == MessageResponse && msg.ID == rq.ID:
+				// Response to the replayed request, compare it with the recorded response.
+				switch {
+				case exp == nil:
+					result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+						Request: rq,
+						Actual:  &msg.Body,
+						Reason:  "response not in recording",
+					})
+				case !bytes.Equal(cbor.Marshal(exp), cbor.Marshal(msg.Body)):
+					result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+						Request:  rq,
+						Expected: exp,
+						Actual:   &msg.Body,
+						Reason:   "response differs from recording",
+					})
+				}
+				break WaitResponse
+			default:
+				result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+					Actual: &msg.Body,
+					Reason: fmt.Sprintf("unexpected message (id: %d type: %s)", msg.ID, msg.MessageType),
+				})
+			}
+		}
+	}
+	return &result, nil
+}
+
+// replayRuntimeRequest returns the first unused recorded host response to a runtime request that
+// is identical to the given request.
+func replayRuntimeRequest(exchanges []*recordedExchange, msg *Message) *Body {
+	rq := cbor.Marshal(msg.Body)
+	for _, ex := range exchanges {
+		if ex.used || !bytes.Equal(ex.request, rq) {
+			continue
+		}
+		ex.used = true
+		return ex.response
+	}
+	return nil
+}
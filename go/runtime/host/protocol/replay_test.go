@@ -0,0 +1,77 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+func TestReplay(t *testing.T) {
+	require := require.New(t)
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("test conn"), 0)
+	logger := logging.GetLogger("test")
+
+	// Record a session.
+	var buf bytes.Buffer
+	recorder := NewRecorder(nopWriteCloser{&buf})
+
+	connA, connB := net.Pipe()
+	protoA, err := NewConnection(logger, runtimeID, &testHandler{})
+	require.NoError(err, "A.New()")
+	protoB, err := NewRecordingConnection(logger, runtimeID, &testHandler{}, recorder)
+	require.NoError(err, "B.New()")
+
+	err = protoA.InitGuest(context.Background(), connA)
+	require.NoError(err, "A.InitGuest()")
+	_, err = protoB.InitHost(context.Background(), connB, &HostInfo{})
+	require.NoError(err, "B.InitHost()")
+
+	for _, rq := range [][]byte{[]byte("first"), []byte("second")} {
+		_, err = protoB.Call(context.Background(), &Body{RuntimeRPCCallRequest: &RuntimeRPCCallRequest{Request: rq}})
+		require.NoError(err, "B.Call()")
+	}
+
+	protoA.Close()
+	protoB.Close()
+
+	msgs, err := NewRecordingReader(&buf).ReadAll()
+	require.NoError(err, "ReadAll")
+
+	replay := func(msgs []*RecordedMessage) *ReplayResult {
+		connA, connB := net.Pipe()
+		defer connB.Close()
+		protoA, err := NewConnection(logger, runtimeID, &testHandler{})
+		require.NoError(err, "A.New()")
+		defer protoA.Close()
+		err = protoA.InitGuest(context.Background(), connA)
+		require.NoError(err, "A.InitGuest()")
+
+		result, err := Replay(context.Background(), connB, msgs)
+		require.NoError(err, "Replay")
+		return result
+	}
+
+	// Replaying against an identical runtime should produce no mismatches.
+	result := replay(msgs)
+	require.EqualValues(3, result.HostRequests, "all host requests should be replayed")
+	require.Empty(result.Mismatches, "there should be no mismatches")
+
+	// Tamper with a recorded response and make sure the mismatch is detected.
+	for _, msg := range msgs {
+		if msg.Direction == RecordDirectionIncoming && msg.Message.Body.RuntimeRPCCallRequest != nil {
+			msg.Message.Body.RuntimeRPCCallRequest = &RuntimeRPCCallRequest{Request: []byte("tampered")}
+			break
+		}
+	}
+	result = replay(msgs)
+	require.EqualValues(3, result.HostRequests, "all host requests should be replayed")
+	require.Len(result.Mismatches, 1, "the tampered response should be reported")
+	require.Equal([]byte("tampered"), result.Mismatches[0].Expected.RuntimeRPCCallRequest.Request)
+	require.Equal([]byte("first"), result.Mismatches[0].Actual.RuntimeRPCCallRequest.Request)
+}
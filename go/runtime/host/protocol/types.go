@@ -236,6 +236,23 @@ type CheckTxMetadata struct {
 
 	// Weight are runtime specific transaction weights.
 	Weights map[transaction.Weight]uint64 `json:"weights,omitempty"`
+
+	// Sender is an opaque identifier of the transaction sender which can be used by schedulers
+	// that order transactions of the same sender.
+	Sender []byte `json:"sender,omitempty"`
+	// SenderSeq is the transaction sequence number (nonce) among transactions of the same sender.
+	SenderSeq uint64 `json:"sender_seq,omitempty"`
+	// SenderStateSeq is the sequence number of the next transaction of the sender that can be
+	// executed given the current state. It must be set whenever Sender is set, as schedulers use
+	// it to detect gaps in the sender's sequence numbers.
+	SenderStateSeq uint64 `json:"sender_state_seq,omitempty"`
+
+	// ExpiryRound is the last round in which the transaction is still valid. After this round the
+	// transaction is evicted from the transaction pool (zero means no round-based expiry).
+	ExpiryRound uint64 `json:"expiry_round,omitempty"`
+	// ExpiryTime is the UNIX timestamp after which the transaction is evicted from the transaction
+	// pool (zero means no time-based expiry).
+	ExpiryTime uint64 `json:"expiry_time,omitempty"`
 }
 
 // IsSuccess returns true if transaction execution was successful.
@@ -251,7 +268,14 @@ func (r *CheckTxResult) ToCheckedTransaction(rawTx []byte) *transaction.CheckedT
 	case nil:
 		return transaction.NewCheckedTransaction(rawTx, 0, nil)
 	default:
-		return transaction.NewCheckedTransaction(rawTx, r.Meta.Priority, r.Meta.Weights)
+		return transaction.NewCheckedTransactionWithSender(
+			rawTx,
+			r.Meta.Priority,
+			r.Meta.Weights,
+			r.Meta.Sender,
+			r.Meta.SenderSeq,
+			r.Meta.SenderStateSeq,
+		)
 	}
 }
 
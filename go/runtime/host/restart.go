@@ -0,0 +1,109 @@
+package host
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultRestartPolicy is the default restart policy for hosted runtimes.
+var DefaultRestartPolicy = RestartPolicy{
+	InitialInterval: 1 * time.Second,
+	MaxInterval:     1 * time.Minute,
+	MaxRestarts:     5,
+	Window:          10 * time.Minute,
+}
+
+// RestartPolicy is the policy for automatically restarting hosted runtimes that fail to start or
+// terminate unexpectedly.
+type RestartPolicy struct {
+	// InitialInterval is the delay before the first restart within the window. Each subsequent
+	// restart within the window doubles the delay.
+	InitialInterval time.Duration
+
+	// MaxInterval is the maximum delay before a restart.
+	MaxInterval time.Duration
+
+	// MaxRestarts is the maximum number of restarts within the window. In case more restarts
+	// happen, the runtime is considered degraded until enough restarts leave the window.
+	MaxRestarts int
+
+	// Window is the time window over which restarts are counted.
+	Window time.Duration
+}
+
+// Validate validates the restart policy.
+func (p *RestartPolicy) Validate() error {
+	if p.InitialInterval <= 0 {
+		return fmt.Errorf("initial restart interval must be positive")
+	}
+	if p.MaxInterval < p.InitialInterval {
+		return fmt.Errorf("maximum restart interval must not be smaller than the initial interval")
+	}
+	if p.MaxRestarts <= 0 {
+		return fmt.Errorf("maximum number of restarts must be positive")
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("restart window must be positive")
+	}
+	return nil
+}
+
+// RestartTracker keeps track of runtime restarts according to a restart policy.
+type RestartTracker struct {
+	policy   RestartPolicy
+	restarts []time.Time
+}
+
+func (rt *RestartTracker) prune(now time.Time) {
+	var i int
+	for i < len(rt.restarts) && now.Sub(rt.restarts[i]) >= rt.policy.Window {
+		i++
+	}
+	rt.restarts = rt.restarts[i:]
+}
+
+// Restart records a restart at the given time and returns the delay after which the runtime
+// should be restarted.
+func (rt *RestartTracker) Restart(now time.Time) time.Duration {
+	rt.prune(now)
+	rt.restarts = append(rt.restarts, now)
+
+	delay := rt.policy.InitialInterval
+	for i := 1; i < len(rt.restarts) && delay < rt.policy.MaxInterval; i++ {
+		delay *= 2
+	}
+	if delay > rt.policy.MaxInterval {
+		delay = rt.policy.MaxInterval
+	}
+	return delay
+}
+
+// Restarts returns the number of restarts within the window ending at the given time.
+func (rt *RestartTracker) Restarts(now time.Time) int {
+	rt.prune(now)
+	return len(rt.restarts)
+}
+
+// IsDegraded returns true iff the number of restarts within the window ending at the given time
+// exceeds the maximum allowed by the policy.
+func (rt *RestartTracker) IsDegraded(now time.Time) bool {
+	return rt.Restarts(now) > rt.policy.MaxRestarts
+}
+
+// NextExpiry returns the time at which the oldest restart within the window leaves the window.
+//
+// In case there are no restarts within the window, the zero time is returned.
+func (rt *RestartTracker) NextExpiry(now time.Time) time.Time {
+	rt.prune(now)
+	if len(rt.restarts) == 0 {
+		return time.Time{}
+	}
+	return rt.restarts[0].Add(rt.policy.Window)
+}
+
+// NewRestartTracker creates a new restart tracker for the given restart policy.
+func NewRestartTracker(policy RestartPolicy) *RestartTracker {
+	return &RestartTracker{
+		policy: policy,
+	}
+}
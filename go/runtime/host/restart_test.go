@@ -0,0 +1,66 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartPolicyValidate(t *testing.T) {
+	require := require.New(t)
+
+	policy := DefaultRestartPolicy
+	require.NoError(policy.Validate(), "default policy should be valid")
+
+	policy.MaxInterval = policy.InitialInterval / 2
+	require.Error(policy.Validate(), "max interval smaller than initial interval should be invalid")
+
+	policy = DefaultRestartPolicy
+	policy.MaxRestarts = 0
+	require.Error(policy.Validate(), "zero max restarts should be invalid")
+
+	policy = DefaultRestartPolicy
+	policy.Window = 0
+	require.Error(policy.Validate(), "zero window should be invalid")
+}
+
+func TestRestartTracker(t *testing.T) {
+	require := require.New(t)
+
+	policy := RestartPolicy{
+		InitialInterval: 1 * time.Second,
+		MaxInterval:     5 * time.Second,
+		MaxRestarts:     3,
+		Window:          1 * time.Minute,
+	}
+	rt := NewRestartTracker(policy)
+	now := time.Unix(1000, 0)
+
+	require.False(rt.IsDegraded(now), "tracker should not be degraded initially")
+	require.True(rt.NextExpiry(now).IsZero(), "there should be no expiry without restarts")
+
+	// Delays should increase exponentially up to the maximum interval.
+	for _, expected := range []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+	} {
+		require.Equal(expected, rt.Restart(now), "restart delay")
+		now = now.Add(time.Second)
+	}
+	require.Equal(4, rt.Restarts(now))
+	require.True(rt.IsDegraded(now), "tracker should be degraded after too many restarts")
+	require.Equal(time.Unix(1060, 0), rt.NextExpiry(now))
+
+	// Once the oldest restart leaves the window, the tracker should no longer be degraded.
+	now = time.Unix(1060, 0)
+	require.Equal(3, rt.Restarts(now))
+	require.False(rt.IsDegraded(now), "tracker should recover once restarts leave the window")
+
+	// Once all restarts leave the window, the delay should be reset.
+	now = now.Add(time.Minute)
+	require.Equal(0, rt.Restarts(now))
+	require.Equal(1*time.Second, rt.Restart(now), "restart delay should reset")
+}
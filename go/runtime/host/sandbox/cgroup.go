@@ -0,0 +1,203 @@
+package sandbox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+)
+
+const (
+	// cgroupCPUPeriod is the CPU bandwidth period (in microseconds) used for enforcing CPU quotas.
+	cgroupCPUPeriod = 100000
+
+	// cgroupNodeLeaf is the name of the leaf cgroup under the cgroup root into which any processes
+	// residing directly in the cgroup root (e.g., the node itself) are moved.
+	cgroupNodeLeaf = "node"
+
+	cgroupRemoveRetries = 10
+	cgroupRemoveBackoff = 100 * time.Millisecond
+)
+
+// cgroupStats are the resource usage statistics of a cgroup.
+type cgroupStats struct {
+	// CPUTime is the total CPU time consumed by all processes in the cgroup.
+	CPUTime time.Duration
+	// RSS is the resident memory (anonymous and file mappings) of all processes in the cgroup.
+	RSS uint64
+	// OOMKills is the number of processes in the cgroup killed by the OOM killer.
+	OOMKills uint64
+}
+
+// cgroup is a cgroup v2 subtree holding a single sandboxed runtime.
+type cgroup struct {
+	path string
+}
+
+func (cg *cgroup) writeFile(name, value string) error {
+	return ioutil.WriteFile(filepath.Join(cg.path, name), []byte(value), 0o600)
+}
+
+func (cg *cgroup) readKeyed(name string) (map[string]uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(cg.path, name))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value for '%s' in %s: %w", fields[0], name, err)
+		}
+		values[fields[0]] = v
+	}
+	return values, scanner.Err()
+}
+
+// stats returns the current resource usage statistics of the cgroup.
+func (cg *cgroup) stats() (*cgroupStats, error) {
+	cpuStat, err := cg.readKeyed("cpu.stat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CPU statistics: %w", err)
+	}
+	memStat, err := cg.readKeyed("memory.stat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory statistics: %w", err)
+	}
+	memEvents, err := cg.readKeyed("memory.events")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory events: %w", err)
+	}
+
+	return &cgroupStats{
+		CPUTime:  time.Duration(cpuStat["usage_usec"]) * time.Microsecond,
+		RSS:      memStat["anon"] + memStat["file_mapped"],
+		OOMKills: memEvents["oom_kill"],
+	}, nil
+}
+
+// remove kills any remaining processes in the cgroup and removes it.
+func (cg *cgroup) remove() error {
+	// Killing all processes is only supported on newer kernels, ignore any failures.
+	_ = cg.writeFile("cgroup.kill", "1")
+
+	var err error
+	for i := 0; i < cgroupRemoveRetries; i++ {
+		// The cgroup can only be removed after all of its processes have terminated.
+		if err = os.Remove(cg.path); err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		time.Sleep(cgroupRemoveBackoff)
+	}
+	return err
+}
+
+// moveProcsToLeaf moves all processes residing directly in the given cgroup into a leaf cgroup.
+func moveProcsToLeaf(root string) error {
+	leaf := &cgroup{
+		path: filepath.Join(root, cgroupNodeLeaf),
+	}
+	if err := os.Mkdir(leaf.path, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create leaf cgroup: %w", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(root, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("failed to read cgroup processes: %w", err)
+	}
+	for _, pid := range strings.Fields(string(data)) {
+		// Processes could have terminated in the meantime.
+		if err = leaf.writeFile("cgroup.procs", pid); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to move process %s to leaf cgroup: %w", pid, err)
+		}
+	}
+	return nil
+}
+
+// enableControllers enables the given controllers for the children of the given cgroup.
+//
+// Controllers can only be enabled for the children of a cgroup that does not contain any processes
+// itself (the "no internal processes" rule), which is not the case when the node has been started
+// directly in the cgroup root. In this case any such processes are moved into a leaf cgroup first.
+func enableControllers(root string, controllers []string) error {
+	subtreeControl := filepath.Join(root, "cgroup.subtree_control")
+	value := []byte(strings.Join(controllers, " "))
+
+	err := ioutil.WriteFile(subtreeControl, value, 0o600)
+	if !errors.Is(err, syscall.EBUSY) {
+		return err
+	}
+	if err = moveProcsToLeaf(root); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(subtreeControl, value, 0o600)
+}
+
+// newCgroup creates a new cgroup v2 subtree under the given (delegated) root and configures the
+// given resource limits.
+func newCgroup(root, name string, limits *host.ResourceLimits) (_ *cgroup, rerr error) {
+	// Enable the required controllers for the children of the root. The memory controller is
+	// always enabled as it is also needed for collecting memory statistics.
+	controllers := []string{"+memory"}
+	if limits.CPUQuota > 0 {
+		controllers = append(controllers, "+cpu")
+	}
+	if limits.PidsLimit > 0 {
+		controllers = append(controllers, "+pids")
+	}
+	if err := enableControllers(root, controllers); err != nil {
+		return nil, fmt.Errorf("failed to enable cgroup controllers: %w", err)
+	}
+
+	cg := &cgroup{
+		path: filepath.Join(root, name),
+	}
+	err := os.Mkdir(cg.path, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	defer func() {
+		if rerr != nil {
+			_ = cg.remove()
+		}
+	}()
+
+	if limits.CPUQuota > 0 {
+		quota := int64(limits.CPUQuota * cgroupCPUPeriod)
+		if err = cg.writeFile("cpu.max", fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)); err != nil {
+			return nil, fmt.Errorf("failed to configure CPU quota: %w", err)
+		}
+	}
+	if limits.MemoryLimit > 0 {
+		if err = cg.writeFile("memory.max", strconv.FormatUint(limits.MemoryLimit, 10)); err != nil {
+			return nil, fmt.Errorf("failed to configure memory limit: %w", err)
+		}
+		// Make sure the memory limit cannot be circumvented by swapping. Swap accounting may not
+		// be available in which case there is nothing to limit.
+		if err = cg.writeFile("memory.swap.max", "0"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to configure swap limit: %w", err)
+		}
+	}
+	if limits.PidsLimit > 0 {
+		if err = cg.writeFile("pids.max", strconv.FormatUint(limits.PidsLimit, 10)); err != nil {
+			return nil, fmt.Errorf("failed to configure pids limit: %w", err)
+		}
+	}
+
+	return cg, nil
+}
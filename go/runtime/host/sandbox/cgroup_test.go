@@ -0,0 +1,115 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+)
+
+// newTestCgroupRoot creates a temporary directory mimicking a delegated cgroup root.
+func newTestCgroupRoot(t *testing.T) string {
+	root, err := ioutil.TempDir("", "oasis-cgroup-test_")
+	require.NoError(t, err, "TempDir")
+	t.Cleanup(func() {
+		os.RemoveAll(root)
+	})
+	return root
+}
+
+// newTestCgroup creates a cgroup populated with the statistics from the fixture directory.
+func newTestCgroup(t *testing.T) *cgroup {
+	cg := &cgroup{
+		path: filepath.Join(newTestCgroupRoot(t), "runtime"),
+	}
+	require.NoError(t, os.Mkdir(cg.path, 0o700), "Mkdir")
+
+	files, err := ioutil.ReadDir(filepath.Join("testdata", "cgroup"))
+	require.NoError(t, err, "ReadDir")
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "cgroup", f.Name()))
+		require.NoError(t, err, "ReadFile")
+		require.NoError(t, cg.writeFile(f.Name(), string(data)), "writeFile")
+	}
+	return cg
+}
+
+func requireCgroupFile(t *testing.T, dir, name, expected string) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err, "ReadFile(%s)", name)
+	require.Equal(t, expected, string(data), "%s should be configured", name)
+}
+
+func TestCgroupStats(t *testing.T) {
+	require := require.New(t)
+
+	cg := newTestCgroup(t)
+	stats, err := cg.stats()
+	require.NoError(err, "stats")
+	require.Equal(2500*time.Millisecond, stats.CPUTime, "CPU time should be parsed")
+	require.EqualValues(10485760+1048576, stats.RSS, "RSS should include anonymous and mapped memory")
+	require.EqualValues(1, stats.OOMKills, "OOM kills should be parsed")
+
+	// Malformed values should be rejected.
+	require.NoError(cg.writeFile("memory.events", "oom_kill foo\n"), "writeFile")
+	_, err = cg.stats()
+	require.Error(err, "stats with malformed values should fail")
+
+	// Missing statistics should be rejected.
+	require.NoError(os.Remove(filepath.Join(cg.path, "cpu.stat")), "Remove")
+	_, err = cg.stats()
+	require.Error(err, "stats with missing statistics should fail")
+}
+
+func TestNewCgroup(t *testing.T) {
+	require := require.New(t)
+
+	// Without limits only the memory controller should be enabled.
+	root := newTestCgroupRoot(t)
+	cg, err := newCgroup(root, "runtime", &host.ResourceLimits{})
+	require.NoError(err, "newCgroup")
+	require.Equal(filepath.Join(root, "runtime"), cg.path, "cgroup should be created under the root")
+	requireCgroupFile(t, root, "cgroup.subtree_control", "+memory")
+	for _, name := range []string{"cpu.max", "memory.max", "memory.swap.max", "pids.max"} {
+		_, err = os.Stat(filepath.Join(cg.path, name))
+		require.True(os.IsNotExist(err), "%s should not be configured without limits", name)
+	}
+
+	// All configured limits should be written.
+	root = newTestCgroupRoot(t)
+	cg, err = newCgroup(root, "runtime", &host.ResourceLimits{
+		CPUQuota:    1.5,
+		MemoryLimit: 64 * 1024 * 1024,
+		PidsLimit:   128,
+	})
+	require.NoError(err, "newCgroup")
+	requireCgroupFile(t, root, "cgroup.subtree_control", "+memory +cpu +pids")
+	requireCgroupFile(t, cg.path, "cpu.max", "150000 100000")
+	requireCgroupFile(t, cg.path, "memory.max", "67108864")
+	requireCgroupFile(t, cg.path, "memory.swap.max", "0")
+	requireCgroupFile(t, cg.path, "pids.max", "128")
+
+	// Creating an existing cgroup should fail.
+	_, err = newCgroup(root, "runtime", &host.ResourceLimits{})
+	require.Error(err, "newCgroup for an existing cgroup should fail")
+}
+
+func TestMoveProcsToLeaf(t *testing.T) {
+	require := require.New(t)
+
+	root := newTestCgroupRoot(t)
+	require.NoError(ioutil.WriteFile(filepath.Join(root, "cgroup.procs"), []byte("1234\n"), 0o600), "WriteFile")
+
+	err := moveProcsToLeaf(root)
+	require.NoError(err, "moveProcsToLeaf")
+	requireCgroupFile(t, filepath.Join(root, cgroupNodeLeaf), "cgroup.procs", "1234")
+
+	// Moving processes again should reuse the existing leaf cgroup.
+	err = moveProcsToLeaf(root)
+	require.NoError(err, "moveProcsToLeaf with an existing leaf cgroup")
+}
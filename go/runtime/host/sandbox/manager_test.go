@@ -0,0 +1,232 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	tendermint "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/multi"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/sandbox/process"
+)
+
+const (
+	// envMockRuntime is the environment variable which makes the test binary act as a mock runtime
+	// with the given behavior.
+	envMockRuntime = "OASIS_TEST_SANDBOX_MOCK_RUNTIME"
+
+	// mockRuntimeExit makes the mock runtime exit immediately without connecting.
+	mockRuntimeExit = "exit"
+	// mockRuntimeCrash makes the mock runtime exit shortly after it has been initialized.
+	mockRuntimeCrash = "crash"
+	// mockRuntimeServe makes the mock runtime serve requests until it is killed.
+	mockRuntimeServe = "serve"
+
+	eventTimeout = 10 * time.Second
+)
+
+var testMockRuntimeID = common.NewTestNamespaceFromSeed([]byte("sandbox manager test"), 0)
+
+func TestMain(m *testing.M) {
+	switch mode := os.Getenv(envMockRuntime); mode {
+	case "":
+		os.Exit(m.Run())
+	case mockRuntimeExit:
+		os.Exit(1)
+	default:
+		if err := runMockRuntime(mode); err != nil {
+			fmt.Fprintf(os.Stderr, "mock runtime failed: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+}
+
+type mockRuntimeHandler struct {
+	initCh chan struct{}
+}
+
+// Implements protocol.Handler.
+func (h *mockRuntimeHandler) Handle(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
+	switch {
+	case body.RuntimeInfoRequest != nil:
+		defer close(h.initCh)
+		return &protocol.Body{
+			RuntimeInfoResponse: &protocol.RuntimeInfoResponse{
+				ProtocolVersion: version.RuntimeHostProtocol,
+			},
+		}, nil
+	case body.RuntimeAbortRequest != nil:
+		return &protocol.Body{RuntimeAbortResponse: &protocol.Empty{}}, nil
+	default:
+		return nil, fmt.Errorf("method not supported")
+	}
+}
+
+func runMockRuntime(mode string) error {
+	conn, err := net.Dial("unix", os.Getenv("OASIS_WORKER_HOST"))
+	if err != nil {
+		return err
+	}
+
+	handler := &mockRuntimeHandler{initCh: make(chan struct{})}
+	pc, err := protocol.NewConnection(logging.GetLogger("mock runtime"), testMockRuntimeID, handler)
+	if err != nil {
+		return err
+	}
+	if err = pc.InitGuest(context.Background(), conn); err != nil {
+		return err
+	}
+	<-handler.initCh
+
+	switch mode {
+	case mockRuntimeCrash:
+		// Give the host some time to complete initialization.
+		time.Sleep(100 * time.Millisecond)
+		return fmt.Errorf("crashed")
+	default:
+		select {}
+	}
+}
+
+// mockRuntimeEvents collects events of a runtime.
+type mockRuntimeEvents struct {
+	t  *testing.T
+	ch <-chan *host.Event
+}
+
+// next waits for the next event that matches the given predicate, failing in case any of the
+// skipped events matches the given forbidden predicate.
+func (e *mockRuntimeEvents) next(match, forbidden func(*host.Event) bool) *host.Event {
+	timeout := time.After(eventTimeout)
+	for {
+		select {
+		case ev := <-e.ch:
+			if match(ev) {
+				return ev
+			}
+			if forbidden != nil && forbidden(ev) {
+				require.FailNow(e.t, "unexpected runtime event", "event: %+v", ev)
+			}
+		case <-timeout:
+			require.FailNow(e.t, "timed out waiting for runtime event")
+			return nil
+		}
+	}
+}
+
+func isStarted(ev *host.Event) bool {
+	return ev.Started != nil
+}
+
+func isStopped(ev *host.Event) bool {
+	return ev.Stopped != nil
+}
+
+func isFailedToStart(ev *host.Event) bool {
+	return ev.FailedToStart != nil
+}
+
+func isDegraded(ev *host.Event) bool {
+	return ev.Degraded != nil
+}
+
+func TestManagerRestartPolicy(t *testing.T) {
+	require := require.New(t)
+
+	policy := host.RestartPolicy{
+		InitialInterval: 50 * time.Millisecond,
+		MaxInterval:     100 * time.Millisecond,
+		MaxRestarts:     2,
+		Window:          3 * time.Second,
+	}
+
+	var mode atomic.Value
+	mode.Store(mockRuntimeExit)
+
+	provisioner, err := New(Config{
+		GetSandboxConfig: func(hostCfg host.Config, socketPath, runtimeDir string) (process.Config, error) {
+			return process.Config{
+				Path: os.Args[0],
+				Env: map[string]string{
+					"OASIS_WORKER_HOST": socketPath,
+					envMockRuntime:      mode.Load().(string),
+				},
+			}, nil
+		},
+		HostInfo: &protocol.HostInfo{
+			ConsensusBackend:         tendermint.BackendName,
+			ConsensusProtocolVersion: version.Versions.ConsensusProtocol,
+		},
+		InsecureNoSandbox: true,
+		RestartPolicy:     &policy,
+	})
+	require.NoError(err, "New")
+
+	rt, err := provisioner.NewRuntime(context.Background(), host.Config{RuntimeID: testMockRuntimeID})
+	require.NoError(err, "NewRuntime")
+
+	// Degraded state changes should be propagated through the aggregate.
+	agg, err := multi.New(testMockRuntimeID, rt, nil)
+	require.NoError(err, "multi.New")
+	ch, sub, err := agg.WatchEvents(context.Background())
+	require.NoError(err, "WatchEvents")
+	defer sub.Close()
+	events := &mockRuntimeEvents{t: t, ch: ch}
+
+	require.NoError(agg.Start(), "Start")
+	defer agg.Stop()
+
+	// A runtime that exits before connecting should fail to start.
+	events.next(isFailedToStart, isStarted)
+	lastFailure := time.Now()
+
+	// A runtime that crashes after being started should be restarted with a backoff until it is
+	// marked as degraded.
+	mode.Store(mockRuntimeCrash)
+	events.next(isStarted, isDegraded)
+	require.True(time.Since(lastFailure) >= policy.InitialInterval/2, "restart should be delayed")
+
+	ev := events.next(isDegraded, isFailedToStart)
+	require.True(ev.Degraded.Degraded, "runtime should be degraded")
+	require.Equal(policy.MaxRestarts+1, ev.Degraded.Restarts, "number of restarts should be reported")
+
+	// The runtime should recover once it no longer crashes and the restarts leave the window.
+	mode.Store(mockRuntimeServe)
+	events.next(isStarted, nil)
+	ev = events.next(isDegraded, isFailedToStart)
+	require.False(ev.Degraded.Degraded, "runtime should no longer be degraded")
+
+	// Requested restarts should not count against the restart policy.
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+	for i := 0; i <= policy.MaxRestarts; i++ {
+		err = agg.Abort(ctx, true)
+		require.NoError(err, "Abort")
+		events.next(isStopped, isDegraded)
+		events.next(isStarted, func(ev *host.Event) bool {
+			return isDegraded(ev) || isFailedToStart(ev)
+		})
+	}
+
+	// A non-forced abort of a responsive runtime should not restart it.
+	err = agg.Abort(ctx, false)
+	require.NoError(err, "Abort")
+
+	select {
+	case ev = <-ch:
+		require.FailNow("unexpected runtime event", "event: %+v", ev)
+	case <-time.After(policy.MaxInterval * 2):
+	}
+}
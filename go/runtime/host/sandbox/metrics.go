@@ -0,0 +1,54 @@
+package sandbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsInterval is the interval at which resource usage metrics of runtimes are collected.
+const metricsInterval = 10 * time.Second
+
+var (
+	cpuTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oasis_runtime_host_cpu_time_seconds",
+			Help: "CPU time consumed by the sandboxed runtime since it was last started (seconds).",
+		},
+		[]string{"runtime"},
+	)
+	memRSS = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oasis_runtime_host_mem_rss_bytes",
+			Help: "Resident memory of the sandboxed runtime (bytes).",
+		},
+		[]string{"runtime"},
+	)
+	oomKillCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_runtime_host_oom_kill_count",
+			Help: "Number of times the sandboxed runtime was killed due to exceeding its memory limit.",
+		},
+		[]string{"runtime"},
+	)
+	sandboxCollectors = []prometheus.Collector{
+		cpuTime,
+		memRSS,
+		oomKillCount,
+	}
+
+	metricsOnce sync.Once
+)
+
+func (r *sandboxedRuntime) getMetricLabels() prometheus.Labels {
+	return prometheus.Labels{
+		"runtime": r.rtCfg.RuntimeID.String(),
+	}
+}
+
+func initMetrics() {
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(sandboxCollectors...)
+	})
+}
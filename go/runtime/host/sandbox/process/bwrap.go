@@ -173,12 +173,14 @@ func NewBubbleWrap(cfg Config) (Process, error) {
 		dataPipes = append(dataPipes, rwPipe{reader, pipe})
 	}
 
-	// Start our sandbox.
+	// Start our sandbox. Note that the sandbox will not spawn the entrypoint binary before it
+	// receives its arguments, so it is safe to move it into a cgroup after it has been started.
 	n, err := NewNaked(Config{
-		Path:   cfg.SandboxBinaryPath,
-		Args:   cliArgs,
-		Stdout: cfg.Stdout,
-		Stderr: cfg.Stderr,
+		Path:       cfg.SandboxBinaryPath,
+		Args:       cliArgs,
+		Stdout:     cfg.Stdout,
+		Stderr:     cfg.Stderr,
+		CgroupPath: cfg.CgroupPath,
 		// Pass all the pipe file descriptors.
 		// NOTE: Entry i becomes file descriptor 3+i.
 		extraFiles: fdPipes.pipes,
@@ -3,9 +3,11 @@ package process
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"syscall"
 )
@@ -112,6 +114,16 @@ func NewNaked(cfg Config) (Process, error) {
 		return nil, err
 	}
 
+	// Move the process into the configured cgroup.
+	if cfg.CgroupPath != "" {
+		pid := []byte(strconv.Itoa(cmd.Process.Pid))
+		if err := ioutil.WriteFile(filepath.Join(cfg.CgroupPath, "cgroup.procs"), pid, 0o600); err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("failed to move process into cgroup: %w", err)
+		}
+	}
+
 	n := &naked{
 		cmd:    cmd,
 		waitCh: make(chan struct{}),
@@ -42,6 +42,10 @@ type Config struct {
 	// SandboxBinaryPath is the path to the sandbox support binary.
 	SandboxBinaryPath string
 
+	// CgroupPath is the optional path to a cgroup v2 directory that the process should be placed
+	// into immediately after it has been started.
+	CgroupPath string
+
 	extraFiles []*os.File
 }
 
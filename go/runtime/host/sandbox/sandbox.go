@@ -56,6 +56,21 @@ type Config struct {
 
 	// InsecureNoSandbox disables the sandbox and runs the runtime binary directly.
 	InsecureNoSandbox bool
+
+	// CgroupRoot is the path to a delegated cgroup v2 directory under which each runtime is placed
+	// into its own cgroup when resource limits are configured.
+	//
+	// In case the node itself resides directly in this directory, it is moved into a "node" leaf
+	// cgroup as cgroup v2 does not allow enabling controllers for cgroups containing processes.
+	CgroupRoot string
+
+	// RestartPolicy is the policy for restarting runtimes that fail to start or terminate
+	// unexpectedly. In case it is not specified the default policy is used.
+	RestartPolicy *host.RestartPolicy
+
+	// RecordDir is an optional path to a directory where all runtime host protocol messages are
+	// recorded for later replay. A new recording is created each time a runtime is started.
+	RecordDir string
 }
 
 type provisioner struct {
@@ -94,7 +109,9 @@ type sandboxedRuntime struct {
 	ctrlCh chan interface{}
 
 	started  bool
+	degraded bool
 	process  process.Process
+	cgroup   *cgroup
 	conn     protocol.Connection
 	notifier *pubsub.Broker
 
@@ -203,9 +220,38 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 	// in any case.
 	defer listener.Close()
 
+	// Create a dedicated cgroup for the runtime in case a cgroup root is available. This enables
+	// enforcing resource limits and collecting resource usage metrics.
+	var (
+		cg         *cgroup
+		cgroupPath string
+		ok         bool
+	)
+	limits := r.rtCfg.ResourceLimits
+	switch {
+	case r.cfg.CgroupRoot != "":
+		if limits == nil {
+			limits = &host.ResourceLimits{}
+		}
+
+		cg, err = newCgroup(r.cfg.CgroupRoot, r.rtCfg.RuntimeID.String()+"-"+filepath.Base(runtimeDir), limits)
+		if err != nil {
+			return fmt.Errorf("failed to create cgroup: %w", err)
+		}
+		cgroupPath = cg.path
+
+		defer func() {
+			// Make sure the cgroup gets removed in case of errors.
+			if !ok {
+				_ = cg.remove()
+			}
+		}()
+	case limits != nil:
+		return fmt.Errorf("resource limits configured but no cgroup root available")
+	}
+
 	// Create the sandbox as configured.
 	var p process.Process
-	var ok bool
 	defer func() {
 		// Make sure the process gets killed in case of errors.
 		if !ok && p != nil {
@@ -222,6 +268,7 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 		if cErr != nil {
 			return fmt.Errorf("failed to configure process: %w", cErr)
 		}
+		cfg.CgroupPath = cgroupPath
 
 		p, err = process.NewNaked(cfg)
 		if err != nil {
@@ -233,6 +280,7 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 		if cErr != nil {
 			return fmt.Errorf("failed to configure sandbox: %w", cErr)
 		}
+		cfg.CgroupPath = cgroupPath
 
 		if cfg.BindRW == nil {
 			cfg.BindRW = make(map[string]string)
@@ -295,7 +343,27 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 		"pid", p.GetPID(),
 	)
 
-	pc, err := protocol.NewConnection(r.logger, r.rtCfg.RuntimeID, r.rtCfg.MessageHandler)
+	var pc protocol.Connection
+	switch r.cfg.RecordDir {
+	case "":
+		pc, err = protocol.NewConnection(r.logger, r.rtCfg.RuntimeID, r.rtCfg.MessageHandler)
+	default:
+		// Record all exchanged messages so that the session can be replayed later.
+		recordingPath := filepath.Join(r.cfg.RecordDir, fmt.Sprintf("%s-%d.rhp", r.rtCfg.RuntimeID, time.Now().UnixNano()))
+		recorder, rErr := protocol.NewFileRecorder(recordingPath)
+		if rErr != nil {
+			return fmt.Errorf("failed to create recorder: %w", rErr)
+		}
+
+		r.logger.Info("recording runtime host protocol messages",
+			"path", recordingPath,
+		)
+
+		pc, err = protocol.NewRecordingConnection(r.logger, r.rtCfg.RuntimeID, r.rtCfg.MessageHandler, recorder)
+		if err != nil {
+			_ = recorder.Close()
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create connection: %w", err)
 	}
@@ -339,6 +407,7 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 
 	ok = true
 	r.process = p
+	r.cgroup = cg
 	r.conn = pc
 
 	// Notify subscribers that a runtime has been started.
@@ -383,6 +452,7 @@ func (r *sandboxedRuntime) handleAbortRequest(rq *abortRequest) error {
 	r.process = nil
 	r.conn = nil
 	r.Unlock()
+	r.removeCgroup()
 
 	// Notify subscribers that the runtime has stopped.
 	r.notifier.Broadcast(&host.Event{Stopped: &host.StoppedEvent{}})
@@ -390,21 +460,123 @@ func (r *sandboxedRuntime) handleAbortRequest(rq *abortRequest) error {
 	return nil
 }
 
+// removeCgroup removes the cgroup of the terminated runtime process (if any) and returns true in
+// case the process has been killed due to exceeding its memory limit.
+func (r *sandboxedRuntime) removeCgroup() (oomKilled bool) {
+	cg := r.cgroup
+	if cg == nil {
+		return false
+	}
+	r.cgroup = nil
+
+	// Resource usage metrics are only meaningful while the runtime is running.
+	cpuTime.Delete(r.getMetricLabels())
+	memRSS.Delete(r.getMetricLabels())
+
+	stats, err := cg.stats()
+	switch err {
+	case nil:
+		oomKilled = stats.OOMKills > 0
+	default:
+		r.logger.Warn("failed to collect final runtime resource usage",
+			"err", err,
+		)
+	}
+
+	if err = cg.remove(); err != nil {
+		r.logger.Warn("failed to remove runtime cgroup",
+			"err", err,
+			"path", cg.path,
+		)
+	}
+	return
+}
+
+// updateMetrics updates the resource usage metrics of the running runtime process.
+func (r *sandboxedRuntime) updateMetrics() {
+	if r.cgroup == nil {
+		return
+	}
+
+	stats, err := r.cgroup.stats()
+	if err != nil {
+		r.logger.Warn("failed to collect runtime resource usage",
+			"err", err,
+		)
+		return
+	}
+
+	cpuTime.With(r.getMetricLabels()).Set(stats.CPUTime.Seconds())
+	memRSS.With(r.getMetricLabels()).Set(float64(stats.RSS))
+}
+
+// updateDegraded updates the degraded state of the runtime based on the restart tracker and
+// notifies subscribers in case the state has changed.
+func (r *sandboxedRuntime) updateDegraded(restarts *host.RestartTracker) {
+	now := time.Now()
+	degraded := restarts.IsDegraded(now)
+	if degraded == r.degraded {
+		return
+	}
+	r.degraded = degraded
+
+	numRestarts := restarts.Restarts(now)
+	switch degraded {
+	case true:
+		r.logger.Error("runtime is restarting too often, marking it as degraded",
+			"restarts", numRestarts,
+		)
+	case false:
+		r.logger.Info("runtime is no longer degraded",
+			"restarts", numRestarts,
+		)
+	}
+
+	// Notify subscribers that the degraded state has changed.
+	r.notifier.Broadcast(&host.Event{
+		Degraded: &host.DegradedEvent{
+			Degraded: degraded,
+			Restarts: numRestarts,
+		},
+	})
+}
+
 func (r *sandboxedRuntime) manager() {
-	// Initialize a ticker channel for restarting the process. Initialize it with a closed channel
-	// so that the first time, the process will be restarted immediately.
-	var ticker *backoff.Ticker
-	var tickerCh <-chan time.Time
-	ch := make(chan time.Time)
-	tickerCh = ch
-	close(ch)
+	// Initialize a timer for restarting the process. Initialize it with a zero duration so that
+	// the first time, the process will be started immediately.
+	restartTimer := time.NewTimer(0)
+	restarts := host.NewRestartTracker(*r.cfg.RestartPolicy)
+
+	// Initialize a timer for re-evaluating the degraded state once restarts leave the window.
+	var recoveryTimer *time.Timer
+	var recoveryCh <-chan time.Time
+
+	scheduleRestart := func() {
+		delay := restarts.Restart(time.Now())
+		r.updateDegraded(restarts)
+
+		if r.degraded {
+			// Make sure to re-evaluate the degraded state once the oldest restart leaves the
+			// window as the runtime may have recovered by then.
+			if recoveryTimer != nil {
+				recoveryTimer.Stop()
+			}
+			recoveryTimer = time.NewTimer(time.Until(restarts.NextExpiry(time.Now())))
+			recoveryCh = recoveryTimer.C
+		}
+
+		r.logger.Info("scheduling runtime restart",
+			"delay", delay,
+		)
+		restartTimer.Reset(delay)
+	}
 
 	defer func() {
 		r.logger.Warn("terminating runtime")
 
-		if ticker != nil {
-			ticker.Stop()
-			ticker = nil
+		restartTimer.Stop()
+		if recoveryTimer != nil {
+			recoveryTimer.Stop()
 		}
 		if r.process != nil {
 			r.conn.Close()
@@ -415,6 +587,7 @@ func (r *sandboxedRuntime) manager() {
 			r.Lock()
 			r.conn = nil
 			r.Unlock()
+			r.removeCgroup()
 
 			// Notify subscribers that the runtime has stopped.
 			r.notifier.Broadcast(&host.Event{Stopped: &host.StoppedEvent{}})
@@ -423,6 +596,9 @@ func (r *sandboxedRuntime) manager() {
 		close(r.quitCh)
 	}()
 
+	metricsTicker := time.NewTicker(metricsInterval)
+	defer metricsTicker.Stop()
+
 	var attempt int
 	for {
 		// Make sure to restart the process if terminated.
@@ -431,7 +607,7 @@ func (r *sandboxedRuntime) manager() {
 			case <-r.stopCh:
 				r.logger.Warn("termination requested")
 				return
-			case <-tickerCh:
+			case <-restartTimer.C:
 				attempt++
 				r.logger.Info("starting runtime",
 					"attempt", attempt,
@@ -449,18 +625,11 @@ func (r *sandboxedRuntime) manager() {
 						},
 					})
 
-					if ticker == nil {
-						ticker = backoff.NewTicker(cmnBackoff.NewExponentialBackOff())
-						tickerCh = ticker.C
-					}
+					scheduleRestart()
 					continue
 				}
 
 				// Runtime started successfully.
-				if ticker != nil {
-					ticker.Stop()
-					ticker = nil
-				}
 				attempt = 0
 			}
 		}
@@ -473,12 +642,28 @@ func (r *sandboxedRuntime) manager() {
 				// Request to abort the runtime.
 				rq.ch <- r.handleAbortRequest(rq)
 				close(rq.ch)
+
+				// Restart the runtime immediately in case it has been killed. Requested restarts
+				// do not count against the restart policy.
+				if r.process == nil {
+					restartTimer.Reset(0)
+				}
 			default:
 				r.logger.Error("received unknown request type",
 					"request_type", fmt.Sprintf("%T", rq),
 				)
 				continue
 			}
+		case <-metricsTicker.C:
+			r.updateMetrics()
+		case <-recoveryCh:
+			// Re-evaluate the degraded state as restarts may have left the window.
+			recoveryCh = nil
+			r.updateDegraded(restarts)
+			if r.degraded {
+				recoveryTimer.Reset(time.Until(restarts.NextExpiry(time.Now())))
+				recoveryCh = recoveryTimer.C
+			}
 		case <-r.stopCh:
 			r.logger.Warn("termination requested")
 			return
@@ -494,8 +679,22 @@ func (r *sandboxedRuntime) manager() {
 			r.conn = nil
 			r.Unlock()
 
+			if oomKilled := r.removeCgroup(); oomKilled {
+				r.logger.Error("runtime process has been killed due to exceeding its memory limit")
+				oomKillCount.With(r.getMetricLabels()).Inc()
+
+				// Notify subscribers that the runtime has exceeded its resource limits.
+				r.notifier.Broadcast(&host.Event{
+					ResourceExceeded: &host.ResourceExceededEvent{
+						Resource: "memory",
+					},
+				})
+			}
+
 			// Notify subscribers that the runtime has stopped.
 			r.notifier.Broadcast(&host.Event{Stopped: &host.StoppedEvent{}})
+
+			scheduleRestart()
 			continue
 		}
 	}
@@ -537,5 +736,14 @@ func New(cfg Config) (host.Provisioner, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = logging.GetLogger("runtime/host/sandbox")
 	}
+	// Use a default RestartPolicy if none was provided.
+	if cfg.RestartPolicy == nil {
+		cfg.RestartPolicy = &host.DefaultRestartPolicy
+	} else if err := cfg.RestartPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid restart policy: %w", err)
+	}
+
+	initMetrics()
+
 	return &provisioner{cfg: cfg}, nil
 }
@@ -4,6 +4,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/version"
 	tendermint "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
@@ -50,3 +55,34 @@ func TestProvisionerSandbox(t *testing.T) {
 		}, nil)
 	})
 }
+
+func TestRuntimeCgroupMetrics(t *testing.T) {
+	require := require.New(t)
+
+	cg := newTestCgroup(t)
+	r := &sandboxedRuntime{
+		rtCfg: host.Config{
+			RuntimeID: common.NewTestNamespaceFromSeed([]byte("sandbox cgroup test"), 0),
+		},
+		cgroup: cg,
+		logger: logging.GetLogger("runtime/host/sandbox/test"),
+	}
+	labels := r.getMetricLabels()
+
+	// Resource usage metrics should be collected from the cgroup.
+	r.updateMetrics()
+	require.EqualValues(2.5, testutil.ToFloat64(cpuTime.With(labels)), "CPU time should be reported")
+	require.EqualValues(10485760+1048576, testutil.ToFloat64(memRSS.With(labels)), "RSS should be reported")
+
+	// Resource usage metrics should be removed once the runtime has stopped.
+	oomKilled := r.removeCgroup()
+	require.True(oomKilled, "OOM kill should be detected")
+	require.Nil(r.cgroup, "cgroup should be cleared")
+	require.False(cpuTime.Delete(labels), "CPU time should no longer be reported")
+	require.False(memRSS.Delete(labels), "RSS should no longer be reported")
+
+	// Without a cgroup there is nothing to do.
+	r.updateMetrics()
+	require.False(cpuTime.Delete(labels), "CPU time should not be reported without a cgroup")
+	require.False(r.removeCgroup(), "OOM kill should not be reported without a cgroup")
+}
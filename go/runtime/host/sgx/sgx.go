@@ -66,6 +66,18 @@ type Config struct {
 
 	// InsecureNoSandbox disables the sandbox and runs the loader directly.
 	InsecureNoSandbox bool
+
+	// CgroupRoot is the path to a delegated cgroup v2 directory under which runtimes are placed
+	// when resource limits are configured.
+	CgroupRoot string
+
+	// RestartPolicy is the policy for restarting runtimes that fail to start or terminate
+	// unexpectedly. In case it is not specified the default policy is used.
+	RestartPolicy *host.RestartPolicy
+
+	// RecordDir is an optional path to a directory where all runtime host protocol messages are
+	// recorded for later replay.
+	RecordDir string
 }
 
 // RuntimeExtra is the extra configuration for SGX runtimes.
@@ -398,6 +410,9 @@ func New(cfg Config) (host.Provisioner, error) {
 		HostInfo:          cfg.HostInfo,
 		HostInitializer:   s.hostInitializer,
 		InsecureNoSandbox: cfg.InsecureNoSandbox,
+		CgroupRoot:        cfg.CgroupRoot,
+		RestartPolicy:     cfg.RestartPolicy,
+		RecordDir:         cfg.RecordDir,
 		Logger:            s.logger,
 	})
 	if err != nil {
@@ -0,0 +1,517 @@
+package indexer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+const dbVersion = 1
+
+var (
+	// metadataKeyFmt is the metadata key format.
+	//
+	// Value is CBOR-serialized dbMetadata.
+	metadataKeyFmt = keyformat.New(0x01)
+	// txKeyFmt is the transaction index key format.
+	//
+	// Key format is: 0x02 <tx hash> <round>
+	//
+	// Value is CBOR-serialized transaction batch order (uint32).
+	txKeyFmt = keyformat.New(0x02, &hash.Hash{}, uint64(0))
+	// roundTxKeyFmt is the per-round transaction index key format.
+	//
+	// Key format is: 0x03 <round> <batch order>
+	//
+	// Value is CBOR-serialized transaction hash.
+	roundTxKeyFmt = keyformat.New(0x03, uint64(0), uint32(0))
+	// tagKeyFmt is the tag index key format.
+	//
+	// Key format is: 0x04 H(<tag key>) H(<tag value>) <round> <batch order>
+	//
+	// Value is empty.
+	tagKeyFmt = keyformat.New(0x04, &hash.Hash{}, &hash.Hash{}, uint64(0), uint32(0))
+	// roundTagKeyFmt is the per-round tag index key format used for pruning.
+	//
+	// Key format is: 0x05 <round> <tag index>
+	//
+	// Value is the corresponding tag index key.
+	roundTagKeyFmt = keyformat.New(0x05, uint64(0), uint32(0))
+)
+
+type dbMetadata struct {
+	// RuntimeID is the runtime ID this database is for.
+	RuntimeID common.Namespace `json:"runtime_id"`
+	// Version is the database schema version.
+	Version uint64 `json:"version"`
+
+	// NextRound is the next round that should be indexed. Zero means that nothing has been
+	// indexed yet.
+	NextRound uint64 `json:"next_round"`
+}
+
+// Result is a transaction index query result.
+type Result struct {
+	// Round is the round in which the transaction was executed.
+	Round uint64
+	// BatchOrder is the order of the transaction in the execution batch.
+	BatchOrder uint32
+	// TxHash is the transaction hash.
+	TxHash hash.Hash
+}
+
+// DB is the transaction index database.
+type DB struct {
+	logger *logging.Logger
+
+	db *badger.DB
+	gc *cmnBadger.GCWorker
+}
+
+func newDB(fn string, runtimeID common.Namespace) (*DB, error) {
+	logger := logging.GetLogger("runtime/indexer").With("path", fn)
+
+	opts := badger.DefaultOptions(fn)
+	opts = opts.WithLogger(cmnBadger.NewLogAdapter(logger))
+	opts = opts.WithSyncWrites(true)
+	opts = opts.WithCompression(options.None)
+
+	db, err := cmnBadger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("runtime/indexer: failed to open database: %w", err)
+	}
+
+	d := &DB{
+		logger: logger,
+		db:     db,
+		gc:     cmnBadger.NewGCWorker(logger, db),
+	}
+
+	// Ensure metadata is valid.
+	if err = d.ensureMetadata(runtimeID); err != nil {
+		d.close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *DB) queryGetMetadata(tx *badger.Txn) (*dbMetadata, error) {
+	item, err := tx.Get(metadataKeyFmt.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var meta dbMetadata
+	err = item.Value(func(val []byte) error {
+		return cbor.Unmarshal(val, &meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (d *DB) ensureMetadata(runtimeID common.Namespace) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		meta, err := d.queryGetMetadata(tx)
+		switch err {
+		case nil:
+		case badger.ErrKeyNotFound:
+			// Create new metadata section.
+			meta := dbMetadata{
+				RuntimeID: runtimeID,
+				Version:   dbVersion,
+			}
+			return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+		default:
+			return err
+		}
+
+		// Verify metadata section.
+		if meta.Version != dbVersion {
+			return fmt.Errorf("runtime/indexer: unsupported database version (expected: %d got: %d)",
+				dbVersion,
+				meta.Version,
+			)
+		}
+
+		if !meta.RuntimeID.Equal(&runtimeID) {
+			return fmt.Errorf("runtime/indexer: database for different runtime (expected: %s got: %s)",
+				runtimeID,
+				meta.RuntimeID,
+			)
+		}
+		return nil
+	})
+}
+
+func (d *DB) metadata() (*dbMetadata, error) {
+	var meta *dbMetadata
+	err := d.db.View(func(tx *badger.Txn) error {
+		var err error
+		meta, err = d.queryGetMetadata(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// commit indexes the given transactions and tags emitted in the given round.
+//
+// Rounds must be committed in increasing order. Block tags that are not tied to a specific
+// transaction are not indexed.
+func (d *DB) commit(round uint64, txs []*transaction.Transaction, tags transaction.Tags) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		meta, err := d.queryGetMetadata(tx)
+		if err != nil {
+			return err
+		}
+
+		if meta.NextRound != 0 && round < meta.NextRound {
+			return fmt.Errorf("runtime/indexer: commit at lower round (next: %d wanted: %d)",
+				meta.NextRound,
+				round,
+			)
+		}
+
+		batchOrders := make(map[hash.Hash]uint32)
+		for _, t := range txs {
+			txHash := t.Hash()
+			batchOrders[txHash] = t.BatchOrder
+
+			if err = tx.Set(txKeyFmt.Encode(&txHash, round), cbor.Marshal(t.BatchOrder)); err != nil {
+				return err
+			}
+			if err = tx.Set(roundTxKeyFmt.Encode(round, t.BatchOrder), cbor.Marshal(txHash)); err != nil {
+				return err
+			}
+		}
+
+		var tagIndex uint32
+		for _, tag := range tags {
+			batchOrder, ok := batchOrders[tag.TxHash]
+			if !ok {
+				// Block tags and tags for unknown transactions are not indexed.
+				continue
+			}
+
+			keyHash := hash.NewFromBytes(tag.Key)
+			valueHash := hash.NewFromBytes(tag.Value)
+			tagKey := tagKeyFmt.Encode(&keyHash, &valueHash, round, batchOrder)
+			if err = tx.Set(tagKey, []byte{}); err != nil {
+				return err
+			}
+			if err = tx.Set(roundTagKeyFmt.Encode(round, tagIndex), tagKey); err != nil {
+				return err
+			}
+			tagIndex++
+		}
+
+		meta.NextRound = round + 1
+		return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+	})
+}
+
+// prune removes all index entries for the given round.
+func (d *DB) prune(round uint64) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		// Collect keys of all transactions and tags first as only one iterator can be active at
+		// any given time in a read-write transaction.
+		var toDelete [][]byte
+		err := d.forEachRoundEntry(tx, roundTxKeyFmt, round, func(key, val []byte) error {
+			var txHash hash.Hash
+			if err := cbor.UnmarshalTrusted(val, &txHash); err != nil {
+				return err
+			}
+			toDelete = append(toDelete, key, txKeyFmt.Encode(&txHash, round))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = d.forEachRoundEntry(tx, roundTagKeyFmt, round, func(key, val []byte) error {
+			toDelete = append(toDelete, key, val)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range toDelete {
+			if err = tx.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *DB) forEachRoundEntry(
+	tx *badger.Txn,
+	keyFmt *keyformat.KeyFormat,
+	round uint64,
+	fn func(key, val []byte) error,
+) error {
+	it := tx.NewIterator(badger.IteratorOptions{Prefix: keyFmt.Encode(round)})
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err = fn(item.KeyCopy(nil), val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DB) getTransaction(txHash hash.Hash) (*Result, error) {
+	var result *Result
+	txErr := d.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: txKeyFmt.Encode(&txHash)})
+		defer it.Close()
+
+		// In case the same transaction has been included in multiple rounds, return the latest.
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+
+			var (
+				decHash hash.Hash
+				round   uint64
+			)
+			if !txKeyFmt.Decode(item.Key(), &decHash, &round) {
+				break
+			}
+
+			var batchOrder uint32
+			if err := item.Value(func(val []byte) error {
+				return cbor.UnmarshalTrusted(val, &batchOrder)
+			}); err != nil {
+				return err
+			}
+
+			result = &Result{
+				Round:      round,
+				BatchOrder: batchOrder,
+				TxHash:     txHash,
+			}
+		}
+		if result == nil {
+			return api.ErrNotFound
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return result, nil
+}
+
+type txPosition struct {
+	round      uint64
+	batchOrder uint32
+}
+
+// less returns true iff the position is before the other position.
+func (p txPosition) less(other txPosition) bool {
+	if p.round != other.round {
+		return p.round < other.round
+	}
+	return p.batchOrder < other.batchOrder
+}
+
+// tagIterator iterates over positions of transactions that emitted the given tag, in increasing
+// position order.
+type tagIterator struct {
+	it        *badger.Iterator
+	keyHash   *hash.Hash
+	valueHash *hash.Hash
+	roundMax  uint64
+
+	pos   txPosition
+	valid bool
+}
+
+func newTagIterator(tx *badger.Txn, keyHash, valueHash *hash.Hash, roundMin, roundMax uint64) *tagIterator {
+	ti := &tagIterator{
+		it:        tx.NewIterator(badger.IteratorOptions{Prefix: tagKeyFmt.Encode(keyHash, valueHash)}),
+		keyHash:   keyHash,
+		valueHash: valueHash,
+		roundMax:  roundMax,
+	}
+	ti.it.Seek(tagKeyFmt.Encode(keyHash, valueHash, roundMin))
+	ti.decode()
+	return ti
+}
+
+func (ti *tagIterator) decode() {
+	ti.valid = false
+	if !ti.it.Valid() {
+		return
+	}
+
+	var decKeyHash, decValueHash hash.Hash
+	if !tagKeyFmt.Decode(ti.it.Item().Key(), &decKeyHash, &decValueHash, &ti.pos.round, &ti.pos.batchOrder) {
+		return
+	}
+	if ti.roundMax != 0 && ti.pos.round > ti.roundMax {
+		return
+	}
+	ti.valid = true
+}
+
+// seek advances the iterator to the first position that is not before the given position.
+func (ti *tagIterator) seek(pos txPosition) {
+	if !ti.valid || !ti.pos.less(pos) {
+		return
+	}
+	ti.it.Seek(tagKeyFmt.Encode(ti.keyHash, ti.valueHash, pos.round, pos.batchOrder))
+	ti.decode()
+}
+
+func (ti *tagIterator) close() {
+	ti.it.Close()
+}
+
+// conditionIterator iterates over positions of transactions matching any of the condition
+// values, in increasing position order.
+type conditionIterator struct {
+	tags []*tagIterator
+}
+
+// seek advances the iterator to the first position that is not before the given position and
+// returns it. The returned boolean is false in case there are no more matching positions.
+func (ci *conditionIterator) seek(pos txPosition) (txPosition, bool) {
+	var (
+		minPos txPosition
+		valid  bool
+	)
+	for _, ti := range ci.tags {
+		ti.seek(pos)
+		if ti.valid && (!valid || ti.pos.less(minPos)) {
+			minPos = ti.pos
+			valid = true
+		}
+	}
+	return minPos, valid
+}
+
+func (ci *conditionIterator) close() {
+	for _, ti := range ci.tags {
+		ti.close()
+	}
+}
+
+func (d *DB) queryTxs(query *api.TxQuery) ([]*Result, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = api.MaxQueryTxsLimit
+	}
+
+	var results []*Result
+	txErr := d.db.View(func(tx *badger.Txn) error {
+		conds := make([]*conditionIterator, 0, len(query.Conditions))
+		defer func() {
+			for _, ci := range conds {
+				ci.close()
+			}
+		}()
+		for _, cond := range query.Conditions {
+			keyHash := hash.NewFromBytes(cond.Key)
+			ci := &conditionIterator{}
+			for _, value := range cond.Values {
+				valueHash := hash.NewFromBytes(value)
+				ci.tags = append(ci.tags, newTagIterator(tx, &keyHash, &valueHash, query.RoundMin, query.RoundMax))
+			}
+			conds = append(conds, ci)
+		}
+
+		// Intersect the conditions by advancing all of them to the furthest position until they
+		// agree. As positions are visited in order, pagination can be applied while iterating.
+		var (
+			target  txPosition
+			skipped uint64
+		)
+	Matches:
+		for uint64(len(results)) < limit {
+			for _, ci := range conds {
+				pos, ok := ci.seek(target)
+				if !ok {
+					break Matches
+				}
+				if target.less(pos) {
+					target = pos
+					continue Matches
+				}
+			}
+
+			if skipped < query.Offset {
+				skipped++
+			} else {
+				result, err := d.queryResult(tx, target)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+
+			// Advance past the current match.
+			switch target.batchOrder {
+			case math.MaxUint32:
+				target = txPosition{round: target.round + 1}
+			default:
+				target.batchOrder++
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+	return results, nil
+}
+
+func (d *DB) queryResult(tx *badger.Txn, pos txPosition) (*Result, error) {
+	item, err := tx.Get(roundTxKeyFmt.Encode(pos.round, pos.batchOrder))
+	if err != nil {
+		return nil, err
+	}
+
+	var txHash hash.Hash
+	if err = item.Value(func(val []byte) error {
+		return cbor.UnmarshalTrusted(val, &txHash)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Round:      pos.round,
+		BatchOrder: pos.batchOrder,
+		TxHash:     txHash,
+	}, nil
+}
+
+func (d *DB) close() {
+	d.gc.Close()
+	d.db.Close()
+}
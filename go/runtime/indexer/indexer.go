@@ -0,0 +1,278 @@
+// Package indexer implements the runtime transaction and tag indexer used by client nodes.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/history"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+const (
+	// CfgEnabled enables the runtime transaction indexer.
+	CfgEnabled = "runtime.indexer.enabled"
+
+	// DbFilename is the filename of the transaction index database.
+	DbFilename = "indexer.db"
+)
+
+// Flags has the configuration flags.
+var Flags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var _ history.PruneHandler = (*pruneHandler)(nil)
+
+type pruneHandler struct {
+	logger *logging.Logger
+	db     *DB
+}
+
+func (p *pruneHandler) Prune(ctx context.Context, rounds []uint64) error {
+	for _, round := range rounds {
+		if err := p.db.prune(round); err != nil {
+			p.logger.Error("failed to prune transaction index",
+				"err", err,
+				"round", round,
+			)
+			return fmt.Errorf("runtime/indexer: failed to prune round %d: %w", round, err)
+		}
+	}
+	return nil
+}
+
+// Indexer is the runtime transaction indexer service.
+//
+// The indexer follows runtime blocks and indexes transaction hashes and the tags emitted by
+// transactions, so that transactions can be looked up without scanning rounds. Index entries
+// are pruned together with runtime history.
+type Indexer struct {
+	logger *logging.Logger
+
+	runtime   runtimeRegistry.Runtime
+	consensus consensus.Backend
+
+	db *DB
+
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	quitCh    chan struct{}
+}
+
+// Name returns the service name.
+func (idx *Indexer) Name() string {
+	return "runtime transaction indexer"
+}
+
+// Start starts the service.
+func (idx *Indexer) Start() error {
+	go idx.worker()
+	return nil
+}
+
+// Stop halts the service.
+func (idx *Indexer) Stop() {
+	idx.cancelCtx()
+}
+
+// Quit returns a channel that will be closed when the service terminates.
+func (idx *Indexer) Quit() <-chan struct{} {
+	return idx.quitCh
+}
+
+// Cleanup performs the service specific post-termination cleanup.
+func (idx *Indexer) Cleanup() {
+	idx.db.close()
+}
+
+// GetTransaction looks up a transaction by its hash.
+//
+// In case the same transaction has been included in multiple rounds, the latest one is returned.
+func (idx *Indexer) GetTransaction(ctx context.Context, txHash hash.Hash) (*Result, error) {
+	return idx.db.getTransaction(txHash)
+}
+
+// QueryTxs queries the index for transactions matching the given query.
+func (idx *Indexer) QueryTxs(ctx context.Context, query *api.TxQuery) ([]*Result, error) {
+	if err := query.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return idx.db.queryTxs(query)
+}
+
+func (idx *Indexer) indexBlock(ctx context.Context, blk *block.Block) error {
+	if blk.Header.IORoot.IsEmpty() {
+		return idx.db.commit(blk.Header.Round, nil, nil)
+	}
+
+	ioRoot := storage.Root{
+		Namespace: blk.Header.Namespace,
+		Version:   blk.Header.Round,
+		Type:      storage.RootTypeIO,
+		Hash:      blk.Header.IORoot,
+	}
+
+	tree := transaction.NewTree(idx.runtime.Storage(), ioRoot)
+	defer tree.Close()
+
+	txs, err := tree.GetTransactions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions from storage: %w", err)
+	}
+	tags, err := tree.GetTags(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tags from storage: %w", err)
+	}
+
+	return idx.db.commit(blk.Header.Round, txs, tags)
+}
+
+// indexUpTo indexes all rounds following the last indexed round up to and including the given
+// round, as long as they are available in runtime history.
+func (idx *Indexer) indexUpTo(ctx context.Context, round uint64) error {
+	meta, err := idx.db.metadata()
+	if err != nil {
+		return err
+	}
+
+	h := idx.runtime.History()
+	startRound := meta.NextRound
+	if startRound == 0 {
+		// Nothing has been indexed yet, start at the earliest block in history.
+		var earliest *block.Block
+		earliest, err = h.GetEarliestBlock(ctx)
+		switch {
+		case err == nil:
+		case errors.Is(err, roothash.ErrNotFound):
+			// No blocks in history yet.
+			return nil
+		default:
+			return fmt.Errorf("failed to get earliest block: %w", err)
+		}
+		startRound = earliest.Header.Round
+	}
+
+	latest, err := h.GetBlock(ctx, roothash.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if round > latest.Header.Round {
+		round = latest.Header.Round
+	}
+
+	for r := startRound; r <= round; r++ {
+		var blk *block.Block
+		blk, err = h.GetBlock(ctx, r)
+		switch {
+		case err == nil:
+		case errors.Is(err, roothash.ErrNotFound):
+			// Round not available in history (e.g., it has been pruned), skip it.
+			continue
+		default:
+			return fmt.Errorf("failed to get block %d: %w", r, err)
+		}
+
+		if err = idx.indexBlock(ctx, blk); err != nil {
+			return fmt.Errorf("failed to index block %d: %w", r, err)
+		}
+	}
+	return nil
+}
+
+func (idx *Indexer) worker() {
+	defer close(idx.quitCh)
+
+	idx.index()
+
+	// Indexing stopping on its own should not terminate the node, so only quit once the service
+	// is stopped.
+	<-idx.ctx.Done()
+}
+
+func (idx *Indexer) index() {
+	blkCh, blkSub, err := idx.consensus.RootHash().WatchBlocks(idx.ctx, idx.runtime.ID())
+	if err != nil {
+		idx.logger.Error("failed to watch blocks",
+			"err", err,
+		)
+		return
+	}
+	defer blkSub.Close()
+
+	for {
+		select {
+		case <-idx.ctx.Done():
+			return
+		case annBlk, ok := <-blkCh:
+			if !ok {
+				idx.logger.Error("block channel closed, transaction indexing stopped")
+				return
+			}
+
+			// Any rounds that failed to be indexed before will be retried.
+			round := annBlk.Block.Header.Round
+			if err = idx.indexUpTo(idx.ctx, round); err != nil {
+				idx.logger.Error("failed to index transactions",
+					"err", err,
+					"round", round,
+				)
+			}
+		}
+	}
+}
+
+// New creates a new runtime transaction indexer.
+func New(dataDir string, runtime runtimeRegistry.Runtime, consensus consensus.Backend) (*Indexer, error) {
+	path, err := runtimeRegistry.EnsureRuntimeStateDir(dataDir, runtime.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newDB(filepath.Join(path, DbFilename), runtime.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.GetLogger("runtime/indexer").With("runtime_id", runtime.ID())
+
+	// Make sure index entries are pruned together with runtime history.
+	runtime.History().Pruner().RegisterHandler(&pruneHandler{
+		logger: logger,
+		db:     db,
+	})
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	return &Indexer{
+		logger:    logger,
+		runtime:   runtime,
+		consensus: consensus,
+		db:        db,
+		ctx:       ctx,
+		cancelCtx: cancelCtx,
+		quitCh:    make(chan struct{}),
+	}, nil
+}
+
+// Enabled reads our enabled flag from viper.
+func Enabled() bool {
+	return viper.GetBool(CfgEnabled)
+}
+
+func init() {
+	Flags.Bool(CfgEnabled, false, "Enable the runtime transaction indexer on client nodes")
+
+	_ = viper.BindPFlags(Flags)
+}
@@ -0,0 +1,282 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+func TestIndexer(t *testing.T) {
+	require := require.New(t)
+
+	// Create a new random temporary directory under /tmp.
+	dataDir, err := ioutil.TempDir("", "oasis-runtime-indexer-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	var runtimeID common.Namespace
+	_ = runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000")
+
+	db, err := newDB(filepath.Join(dataDir, DbFilename), runtimeID)
+	require.NoError(err, "newDB")
+	idx := &Indexer{db: db}
+
+	ctx := context.Background()
+
+	tx1 := &transaction.Transaction{Input: []byte("tx1"), Output: []byte("out1"), BatchOrder: 0}
+	tx2 := &transaction.Transaction{Input: []byte("tx2"), Output: []byte("out2"), BatchOrder: 1}
+	tx3 := &transaction.Transaction{Input: []byte("tx3"), Output: []byte("out3"), BatchOrder: 0}
+
+	// Round 10: tx1 (transfer from alice) and tx2 (transfer from bob).
+	err = db.commit(10, []*transaction.Transaction{tx1, tx2}, transaction.Tags{
+		{Key: []byte("method"), Value: []byte("transfer"), TxHash: tx1.Hash()},
+		{Key: []byte("from"), Value: []byte("alice"), TxHash: tx1.Hash()},
+		{Key: []byte("method"), Value: []byte("transfer"), TxHash: tx2.Hash()},
+		{Key: []byte("from"), Value: []byte("bob"), TxHash: tx2.Hash()},
+		{Key: []byte("block"), Value: []byte("tag"), TxHash: transaction.TagBlockTxHash},
+	})
+	require.NoError(err, "commit")
+
+	// Round 11: no transactions.
+	err = db.commit(11, nil, nil)
+	require.NoError(err, "commit")
+
+	// Round 12: tx3 (mint to alice).
+	err = db.commit(12, []*transaction.Transaction{tx3}, transaction.Tags{
+		{Key: []byte("method"), Value: []byte("mint"), TxHash: tx3.Hash()},
+		{Key: []byte("from"), Value: []byte("alice"), TxHash: tx3.Hash()},
+	})
+	require.NoError(err, "commit")
+
+	err = db.commit(11, nil, nil)
+	require.Error(err, "commit should fail for lower round")
+
+	result, err := idx.GetTransaction(ctx, tx2.Hash())
+	require.NoError(err, "GetTransaction")
+	require.EqualValues(10, result.Round)
+	require.EqualValues(1, result.BatchOrder)
+	require.EqualValues(tx2.Hash(), result.TxHash)
+
+	_, err = idx.GetTransaction(ctx, hash.NewFromBytes([]byte("unknown")))
+	require.Error(err, "GetTransaction should fail for unknown transactions")
+	require.True(errors.Is(err, api.ErrNotFound))
+
+	// Single condition.
+	results, err := idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 2)
+	require.EqualValues(tx1.Hash(), results[0].TxHash)
+	require.EqualValues(tx3.Hash(), results[1].TxHash)
+
+	// Multiple conditions are combined using AND.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice")}},
+			{Key: []byte("method"), Values: [][]byte{[]byte("transfer")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 1)
+	require.EqualValues(tx1.Hash(), results[0].TxHash)
+
+	// Multiple values are combined using OR.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("method"), Values: [][]byte{[]byte("transfer"), []byte("mint")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 3)
+	require.EqualValues(tx1.Hash(), results[0].TxHash)
+	require.EqualValues(tx2.Hash(), results[1].TxHash)
+	require.EqualValues(tx3.Hash(), results[2].TxHash)
+
+	// Round range.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		RoundMin: 11,
+		RoundMax: 12,
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 1)
+	require.EqualValues(tx3.Hash(), results[0].TxHash)
+
+	// Offset and limit.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("method"), Values: [][]byte{[]byte("transfer"), []byte("mint")}},
+		},
+		Offset: 1,
+		Limit:  1,
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 1)
+	require.EqualValues(tx2.Hash(), results[0].TxHash)
+
+	// Offset and limit with multiple conditions.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice"), []byte("bob")}},
+			{Key: []byte("method"), Values: [][]byte{[]byte("transfer"), []byte("mint")}},
+		},
+		Offset: 1,
+		Limit:  2,
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 2)
+	require.EqualValues(tx2.Hash(), results[0].TxHash)
+	require.EqualValues(tx3.Hash(), results[1].TxHash)
+
+	// Offset past the last match.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("method"), Values: [][]byte{[]byte("transfer"), []byte("mint")}},
+		},
+		Offset: 3,
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 0)
+
+	// Block tags are not indexed.
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("block"), Values: [][]byte{[]byte("tag")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 0)
+
+	_, err = idx.QueryTxs(ctx, &api.TxQuery{})
+	require.Error(err, "QueryTxs should fail without conditions")
+	require.True(errors.Is(err, api.ErrInvalidQuery))
+
+	_, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice")}},
+		},
+		Limit: api.MaxQueryTxsLimit + 1,
+	})
+	require.Error(err, "QueryTxs should fail with too large limit")
+	require.True(errors.Is(err, api.ErrInvalidQuery))
+
+	// Prune round 10.
+	err = (&pruneHandler{db: db}).Prune(ctx, []uint64{10})
+	require.NoError(err, "Prune")
+
+	_, err = idx.GetTransaction(ctx, tx1.Hash())
+	require.Error(err, "GetTransaction should fail for pruned transactions")
+	require.True(errors.Is(err, api.ErrNotFound))
+
+	results, err = idx.QueryTxs(ctx, &api.TxQuery{
+		Conditions: []api.TxQueryCondition{
+			{Key: []byte("from"), Values: [][]byte{[]byte("alice")}},
+		},
+	})
+	require.NoError(err, "QueryTxs")
+	require.Len(results, 1)
+	require.EqualValues(tx3.Hash(), results[0].TxHash)
+
+	// Reopen the database and make sure the index persists.
+	db.close()
+	db, err = newDB(filepath.Join(dataDir, DbFilename), runtimeID)
+	require.NoError(err, "newDB")
+	idx = &Indexer{db: db}
+
+	result, err = idx.GetTransaction(ctx, tx3.Hash())
+	require.NoError(err, "GetTransaction")
+	require.EqualValues(12, result.Round)
+
+	meta, err := db.metadata()
+	require.NoError(err, "metadata")
+	require.EqualValues(13, meta.NextRound)
+
+	// Opening the database for a different runtime should fail.
+	db.close()
+	var otherRuntimeID common.Namespace
+	_ = otherRuntimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000001")
+	_, err = newDB(filepath.Join(dataDir, DbFilename), otherRuntimeID)
+	require.Error(err, "newDB should fail for a different runtime")
+
+	db, err = newDB(filepath.Join(dataDir, DbFilename), runtimeID)
+	require.NoError(err, "newDB")
+	db.close()
+}
+
+type testRuntime struct {
+	runtimeRegistry.Runtime
+
+	id common.Namespace
+}
+
+func (r *testRuntime) ID() common.Namespace {
+	return r.id
+}
+
+type testConsensus struct {
+	consensus.Backend
+}
+
+func (c *testConsensus) RootHash() roothash.Backend {
+	return &testRootHash{}
+}
+
+// testRootHash is a roothash backend whose block watch channel is closed immediately.
+type testRootHash struct {
+	roothash.Backend
+}
+
+func (r *testRootHash) WatchBlocks(ctx context.Context, runtimeID common.Namespace) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error) {
+	_, sub := pubsub.NewContextSubscription(ctx)
+	ch := make(chan *roothash.AnnotatedBlock)
+	close(ch)
+	return ch, sub, nil
+}
+
+func TestIndexerStopped(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	idx := &Indexer{
+		logger:    logging.GetLogger("runtime/indexer/test"),
+		runtime:   &testRuntime{},
+		consensus: &testConsensus{},
+		ctx:       ctx,
+		cancelCtx: cancelCtx,
+		quitCh:    make(chan struct{}),
+	}
+	require.NoError(t, idx.Start(), "Start")
+
+	// Indexing stopping on its own should not terminate the service.
+	select {
+	case <-idx.Quit():
+		t.Fatalf("service should not quit before being stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	idx.Stop()
+	select {
+	case <-idx.Quit():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to wait for the service to quit")
+	}
+}
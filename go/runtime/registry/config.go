@@ -35,6 +35,9 @@ const (
 	CfgRuntimePaths = "runtime.paths"
 	// CfgSandboxBinary configures the runtime sandbox binary location.
 	CfgSandboxBinary = "runtime.sandbox.binary"
+	// CfgSandboxCgroupRoot configures the delegated cgroup v2 directory under which sandboxed
+	// runtimes are placed. It is required for enforcing runtime resource limits.
+	CfgSandboxCgroupRoot = "runtime.sandbox.cgroup_root"
 	// CfgRuntimeSGXLoader configures the runtime loader binary required for SGX runtimes.
 	//
 	// The same loader is used for all runtimes.
@@ -43,9 +46,39 @@ const (
 	//
 	// The value should be a map of runtime IDs to corresponding resource paths.
 	CfgRuntimeSGXSignatures = "runtime.sgx.signatures"
+	// CfgRuntimeNextPaths configures the paths for the next versions of supported runtimes.
+	//
+	// The value should be a map of runtime IDs to corresponding resource paths. The next version
+	// is provisioned side by side with the current version and becomes active once the version
+	// change scheduled in the runtime descriptor takes effect. Each runtime must also be configured
+	// in CfgRuntimePaths.
+	CfgRuntimeNextPaths = "runtime.next_paths"
+	// CfgRuntimeNextSGXSignatures configures signatures for the next versions of supported
+	// runtimes.
+	//
+	// The value should be a map of runtime IDs to corresponding resource paths.
+	CfgRuntimeNextSGXSignatures = "runtime.sgx.next_signatures"
 
 	// CfgRuntimeConfig configures node-local runtime configuration.
 	CfgRuntimeConfig = "runtime.config"
+	// cfgRuntimeConfigResourceLimits is the key in the node-local runtime configuration that
+	// configures the runtime resource limits. It is not passed to the runtime.
+	cfgRuntimeConfigResourceLimits = "resource_limits"
+
+	// CfgRestartInitialInterval configures the delay before the first restart of a runtime that
+	// failed to start or terminated unexpectedly.
+	CfgRestartInitialInterval = "runtime.restart.initial_interval"
+	// CfgRestartMaxInterval configures the maximum delay before restarting a runtime.
+	CfgRestartMaxInterval = "runtime.restart.max_interval"
+	// CfgRestartMaxRestarts configures the maximum number of runtime restarts within the restart
+	// window after which the runtime is considered degraded.
+	CfgRestartMaxRestarts = "runtime.restart.max_restarts"
+	// CfgRestartWindow configures the time window over which runtime restarts are counted.
+	CfgRestartWindow = "runtime.restart.window"
+
+	// CfgDebugRecordDir configures the directory where runtime host protocol messages of sandboxed
+	// runtimes are recorded for later replay.
+	CfgDebugRecordDir = "runtime.debug.record_dir"
 
 	// CfgHistoryPrunerStrategy configures the history pruner strategy.
 	CfgHistoryPrunerStrategy = "runtime.history.pruner.strategy"
@@ -150,6 +183,40 @@ type RuntimeHostConfig struct {
 	// Runtimes contains per-runtime provisioning configuration. Some fields may be omitted as they
 	// are provided when the runtime is provisioned.
 	Runtimes map[common.Namespace]*runtimeHost.Config
+
+	// NextRuntimes contains per-runtime provisioning configuration for the next versions of
+	// runtimes that have a version change scheduled. Each runtime must also be present in Runtimes.
+	NextRuntimes map[common.Namespace]*runtimeHost.Config
+}
+
+// NewRuntimeHostConfig creates a new per-runtime provisioning configuration.
+func NewRuntimeHostConfig(
+	id common.Namespace,
+	path string,
+	sgxSignaturePath string,
+	localConfig map[string]interface{},
+) *runtimeHost.Config {
+	runtimeHostCfg := &runtimeHost.Config{
+		RuntimeID:   id,
+		Path:        path,
+		LocalConfig: localConfig,
+	}
+
+	// This config is SGX specific, but that's all that's supported
+	// right now that needs this anyway, the non-SGX provisioner
+	// currently ignores this.
+	if sgxSignaturePath != "" {
+		runtimeHostCfg.Extra = &hostSgx.RuntimeExtra{
+			SignaturePath: sgxSignaturePath,
+		}
+	} else {
+		// HACK HACK HACK: Allow dummy SIGSTRUCT generation.
+		runtimeHostCfg.Extra = &hostSgx.RuntimeExtra{
+			UnsafeDebugGenerateSigstruct: true,
+		}
+	}
+
+	return runtimeHostCfg
 }
 
 func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, error) {
@@ -196,6 +263,17 @@ func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, e
 		// Register provisioners based on the configured provisioner.
 		var insecureNoSandbox bool
 		sandboxBinary := viper.GetString(CfgSandboxBinary)
+		cgroupRoot := viper.GetString(CfgSandboxCgroupRoot)
+		recordDir := viper.GetString(CfgDebugRecordDir)
+		restartPolicy := &runtimeHost.RestartPolicy{
+			InitialInterval: viper.GetDuration(CfgRestartInitialInterval),
+			MaxInterval:     viper.GetDuration(CfgRestartMaxInterval),
+			MaxRestarts:     viper.GetInt(CfgRestartMaxRestarts),
+			Window:          viper.GetDuration(CfgRestartWindow),
+		}
+		if err = restartPolicy.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid runtime restart policy: %w", err)
+		}
 		rh.Provisioners = make(map[node.TEEHardware]runtimeHost.Provisioner)
 		switch p := viper.GetString(CfgRuntimeProvisioner); p {
 		case RuntimeProvisionerMock:
@@ -226,6 +304,9 @@ func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, e
 				HostInfo:          hostInfo,
 				InsecureNoSandbox: insecureNoSandbox,
 				SandboxBinaryPath: sandboxBinary,
+				CgroupRoot:        cgroupRoot,
+				RestartPolicy:     restartPolicy,
+				RecordDir:         recordDir,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to create runtime provisioner: %w", err)
@@ -238,6 +319,9 @@ func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, e
 					HostInfo:          hostInfo,
 					InsecureNoSandbox: insecureNoSandbox,
 					SandboxBinaryPath: sandboxBinary,
+					CgroupRoot:        cgroupRoot,
+					RestartPolicy:     restartPolicy,
+					RecordDir:         recordDir,
 				})
 				if err != nil {
 					return nil, fmt.Errorf("failed to create runtime provisioner: %w", err)
@@ -250,6 +334,9 @@ func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, e
 					IAS:               ias,
 					SandboxBinaryPath: sandboxBinary,
 					InsecureNoSandbox: insecureNoSandbox,
+					CgroupRoot:        cgroupRoot,
+					RestartPolicy:     restartPolicy,
+					RecordDir:         recordDir,
 				})
 				if err != nil {
 					return nil, fmt.Errorf("failed to create SGX runtime provisioner: %w", err)
@@ -269,39 +356,50 @@ func newConfig(consensus consensus.Backend, ias ias.Endpoint) (*RuntimeConfig, e
 			}
 
 			// Unmarshal any local runtime configuration.
-			var localConfig map[string]interface{}
+			var (
+				localConfig    map[string]interface{}
+				resourceLimits *runtimeHost.ResourceLimits
+			)
 			if sub := viper.Sub(CfgRuntimeConfig); sub != nil {
 				if err := sub.UnmarshalKey(runtimeID, &localConfig); err != nil {
 					return nil, fmt.Errorf("bad runtime configuration: %w", err)
 				}
-			}
 
-			runtimeHostCfg := &runtimeHost.Config{
-				RuntimeID:   id,
-				Path:        path,
-				LocalConfig: localConfig,
-			}
-
-			// This config is SGX specific, but that's all that's supported
-			// right now that needs this anyway, the non-SGX provisioner
-			// currently ignores this.
-			if sigPath := runtimeSGXSignatures[runtimeID]; sigPath != "" {
-				runtimeHostCfg.Extra = &hostSgx.RuntimeExtra{
-					SignaturePath: sigPath,
-				}
-			} else {
-				// HACK HACK HACK: Allow dummy SIGSTRUCT generation.
-				runtimeHostCfg.Extra = &hostSgx.RuntimeExtra{
-					UnsafeDebugGenerateSigstruct: true,
+				// Resource limits are enforced by the node and are not passed to the runtime.
+				if _, ok := localConfig[cfgRuntimeConfigResourceLimits]; ok {
+					resourceLimits = new(runtimeHost.ResourceLimits)
+					if err := sub.UnmarshalKey(runtimeID+"."+cfgRuntimeConfigResourceLimits, resourceLimits); err != nil {
+						return nil, fmt.Errorf("bad runtime resource limits: %w", err)
+					}
+					delete(localConfig, cfgRuntimeConfigResourceLimits)
 				}
 			}
 
-			rh.Runtimes[id] = runtimeHostCfg
+			rh.Runtimes[id] = NewRuntimeHostConfig(id, path, runtimeSGXSignatures[runtimeID], localConfig)
+			rh.Runtimes[id].ResourceLimits = resourceLimits
 		}
 		if len(rh.Runtimes) == 0 {
 			return nil, fmt.Errorf("no runtimes configured")
 		}
 
+		// Configure next runtime versions.
+		runtimeNextSGXSignatures := viper.GetStringMapString(CfgRuntimeNextSGXSignatures)
+		rh.NextRuntimes = make(map[common.Namespace]*runtimeHost.Config)
+		for runtimeID, path := range viper.GetStringMapString(CfgRuntimeNextPaths) {
+			var id common.Namespace
+			if err := id.UnmarshalHex(runtimeID); err != nil {
+				return nil, fmt.Errorf("bad runtime identifier '%s': %w", runtimeID, err)
+			}
+
+			rtCfg, ok := rh.Runtimes[id]
+			if !ok {
+				return nil, fmt.Errorf("next version configured for runtime '%s' which is not configured", runtimeID)
+			}
+
+			rh.NextRuntimes[id] = NewRuntimeHostConfig(id, path, runtimeNextSGXSignatures[runtimeID], rtCfg.LocalConfig)
+			rh.NextRuntimes[id].ResourceLimits = rtCfg.ResourceLimits
+		}
+
 		cfg.Host = &rh
 	}
 
@@ -329,8 +427,18 @@ func init() {
 	Flags.String(CfgRuntimeProvisioner, RuntimeProvisionerSandboxed, "Runtime provisioner to use")
 	Flags.StringToString(CfgRuntimePaths, nil, "Paths to runtime resources (format: <rt1-ID>=<path>,<rt2-ID>=<path>)")
 	Flags.String(CfgSandboxBinary, "/usr/bin/bwrap", "Path to the sandbox binary (bubblewrap)")
+	Flags.String(CfgSandboxCgroupRoot, "", "Path to a delegated cgroup v2 directory for sandboxed runtimes")
 	Flags.String(CfgRuntimeSGXLoader, "", "(for SGX runtimes) Path to SGXS runtime loader binary")
 	Flags.StringToString(CfgRuntimeSGXSignatures, nil, "(for SGX runtimes) Paths to signatures (format: <rt1-ID>=<path>,<rt2-ID>=<path>")
+	Flags.StringToString(CfgRuntimeNextPaths, nil, "Paths to next version runtime resources (format: <rt1-ID>=<path>,<rt2-ID>=<path>)")
+	Flags.StringToString(CfgRuntimeNextSGXSignatures, nil, "(for SGX runtimes) Paths to next version signatures (format: <rt1-ID>=<path>,<rt2-ID>=<path>")
+
+	Flags.Duration(CfgRestartInitialInterval, runtimeHost.DefaultRestartPolicy.InitialInterval, "Delay before the first runtime restart")
+	Flags.Duration(CfgRestartMaxInterval, runtimeHost.DefaultRestartPolicy.MaxInterval, "Maximum delay before a runtime restart")
+	Flags.Int(CfgRestartMaxRestarts, runtimeHost.DefaultRestartPolicy.MaxRestarts, "Maximum number of runtime restarts within the restart window before the runtime is considered degraded")
+	Flags.Duration(CfgRestartWindow, runtimeHost.DefaultRestartPolicy.Window, "Time window over which runtime restarts are counted")
+
+	Flags.String(CfgDebugRecordDir, "", "Path to a directory where runtime host protocol messages are recorded (may contain sensitive data)")
 
 	Flags.String(CfgHistoryPrunerStrategy, history.PrunerStrategyNone, "History pruner strategy")
 	Flags.Duration(CfgHistoryPrunerInterval, 2*time.Minute, "History pruning interval")
@@ -17,6 +17,7 @@ import (
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/multi"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
@@ -32,6 +33,7 @@ type RuntimeHostNode struct {
 	factory  RuntimeHostHandlerFactory
 	notifier protocol.Notifier
 
+	agg           *multi.Aggregate
 	runtime       host.RichRuntime
 	runtimeNotify chan struct{}
 }
@@ -52,10 +54,33 @@ func (n *RuntimeHostNode) ProvisionHostedRuntime(ctx context.Context) (host.Rich
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to provision runtime: %w", err)
 	}
-	notifier := n.factory.NewRuntimeHostNotifier(ctx, prt)
-	rr := host.NewRichRuntime(prt)
+
+	// Provision the next version of the runtime if configured so it is ready by the time the
+	// scheduled version change takes effect.
+	var nextPrt host.Runtime
+	nextCfg, nextProvisioner, err := n.factory.GetRuntime().NextHost(ctx)
+	switch err {
+	case nil:
+		nextCfg.MessageHandler = n.factory.NewRuntimeHostHandler()
+
+		nextPrt, err = nextProvisioner.NewRuntime(ctx, nextCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to provision next runtime version: %w", err)
+		}
+	case ErrRuntimeHostNotConfigured:
+	default:
+		return nil, nil, fmt.Errorf("failed to get next runtime host: %w", err)
+	}
+
+	agg, err := multi.New(cfg.RuntimeID, prt, nextPrt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create runtime host aggregate: %w", err)
+	}
+	notifier := n.factory.NewRuntimeHostNotifier(ctx, agg)
+	rr := host.NewRichRuntime(agg)
 
 	n.Lock()
+	n.agg = agg
 	n.runtime = rr
 	n.notifier = notifier
 	n.Unlock()
@@ -73,6 +98,20 @@ func (n *RuntimeHostNode) GetHostedRuntime() host.RichRuntime {
 	return rt
 }
 
+// ActivateNextHostedRuntime switches the provisioned hosted runtime to the next version.
+//
+// In case no next version of the runtime has been provisioned, multi.ErrNoNextVersion is returned.
+func (n *RuntimeHostNode) ActivateNextHostedRuntime() error {
+	n.Lock()
+	agg := n.agg
+	n.Unlock()
+
+	if agg == nil {
+		return multi.ErrNoNextVersion
+	}
+	return agg.SwitchToNext()
+}
+
 // WaitHostedRuntime waits for the hosted runtime to be provisioned and returns it.
 func (n *RuntimeHostNode) WaitHostedRuntime(ctx context.Context) (host.RichRuntime, error) {
 	select {
@@ -58,6 +58,17 @@ type Registry interface {
 	// to set the role for all runtimes.
 	AddRoles(roles node.RolesMask, runtimeID *common.Namespace) error
 
+	// AddRuntime adds a new supported runtime that will be hosted using the given provisioning
+	// configuration.
+	//
+	// This can be used to start supporting runtimes without restarting the node.
+	AddRuntime(ctx context.Context, hostCfg *runtimeHost.Config) (Runtime, error)
+
+	// RemoveRuntime removes a supported runtime and releases all of its resources.
+	//
+	// Any services using the runtime must be stopped before the runtime is removed.
+	RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error
+
 	// StorageRouter returns a storage backend which routes requests to the
 	// correct per-runtime storage backend based on the namespace contained
 	// in the request.
@@ -113,6 +124,10 @@ type Runtime interface {
 
 	// Host returns the runtime host configuration and provisioner if configured.
 	Host(ctx context.Context) (runtimeHost.Config, runtimeHost.Provisioner, error)
+
+	// NextHost returns the runtime host configuration and provisioner for the next version of the
+	// runtime if configured.
+	NextHost(ctx context.Context) (runtimeHost.Config, runtimeHost.Provisioner, error)
 }
 
 type runtime struct { // nolint: maligned
@@ -139,6 +154,7 @@ type runtime struct { // nolint: maligned
 
 	hostProvisioners map[node.TEEHardware]runtimeHost.Provisioner
 	hostConfig       *runtimeHost.Config
+	nextHostConfig   *runtimeHost.Config
 
 	logger *logging.Logger
 }
@@ -238,7 +254,15 @@ func (r *runtime) HasHost() bool {
 }
 
 func (r *runtime) Host(ctx context.Context) (runtimeHost.Config, runtimeHost.Provisioner, error) {
-	if r.hostProvisioners == nil || r.hostConfig == nil {
+	return r.host(ctx, r.hostConfig)
+}
+
+func (r *runtime) NextHost(ctx context.Context) (runtimeHost.Config, runtimeHost.Provisioner, error) {
+	return r.host(ctx, r.nextHostConfig)
+}
+
+func (r *runtime) host(ctx context.Context, cfg *runtimeHost.Config) (runtimeHost.Config, runtimeHost.Provisioner, error) {
+	if r.hostProvisioners == nil || cfg == nil {
 		return runtimeHost.Config{}, nil, ErrRuntimeHostNotConfigured
 	}
 
@@ -252,7 +276,7 @@ func (r *runtime) Host(ctx context.Context) (runtimeHost.Config, runtimeHost.Pro
 		return runtimeHost.Config{}, nil, fmt.Errorf("no provisioner suitable for TEE hardware '%s'", rt.TEEHardware)
 	}
 
-	return *r.hostConfig, provisioner, nil
+	return *cfg, provisioner, nil
 }
 
 func (r *runtime) stop() {
@@ -402,7 +426,8 @@ type runtimeRegistry struct {
 	consensus consensus.Backend
 	identity  *identity.Identity
 
-	runtimes map[common.Namespace]*runtime
+	runtimes    map[common.Namespace]*runtime
+	initialized bool
 }
 
 func (r *runtimeRegistry) Mode() RuntimeMode {
@@ -432,6 +457,9 @@ func (r *runtimeRegistry) Runtimes() []Runtime {
 }
 
 func (r *runtimeRegistry) NewUnmanagedRuntime(ctx context.Context, runtimeID common.Namespace) (Runtime, error) {
+	r.RLock()
+	defer r.RUnlock()
+
 	return newRuntime(ctx, runtimeID, r.cfg, r.consensus, r.logger)
 }
 
@@ -454,6 +482,77 @@ func (r *runtimeRegistry) AddRoles(roles node.RolesMask, runtimeID *common.Names
 	return nil
 }
 
+func (r *runtimeRegistry) AddRuntime(ctx context.Context, hostCfg *runtimeHost.Config) (Runtime, error) {
+	switch {
+	case r.cfg.Mode == RuntimeModeNone || r.cfg.Mode == RuntimeModeKeymanager:
+		return nil, fmt.Errorf("runtime/registry: adding runtimes is not supported in %s mode", r.cfg.Mode)
+	case r.cfg.Host == nil:
+		return nil, ErrRuntimeHostNotConfigured
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	id := hostCfg.RuntimeID
+	if _, ok := r.runtimes[id]; ok {
+		return nil, fmt.Errorf("runtime/registry: runtime already registered: %s", id)
+	}
+
+	r.logger.Info("adding supported runtime",
+		"id", id,
+	)
+
+	r.cfg.Host.Runtimes[id] = hostCfg
+	rt, err := r.addSupportedRuntimeLocked(ctx, id)
+	if err != nil {
+		delete(r.cfg.Host.Runtimes, id)
+		return nil, err
+	}
+
+	// In case the registry has already been initialized, the runtime needs to be initialized
+	// immediately.
+	if r.initialized {
+		if err = rt.finishInitialization(ctx, r.identity); err != nil {
+			_ = r.removeRuntimeLocked(ctx, rt)
+			return nil, err
+		}
+	}
+
+	return rt, nil
+}
+
+func (r *runtimeRegistry) RemoveRuntime(ctx context.Context, runtimeID common.Namespace) error {
+	r.Lock()
+	defer r.Unlock()
+
+	rt, ok := r.runtimes[runtimeID]
+	if !ok {
+		return fmt.Errorf("runtime/registry: runtime %s is not supported", runtimeID)
+	}
+
+	r.logger.Info("removing supported runtime",
+		"id", runtimeID,
+	)
+
+	return r.removeRuntimeLocked(ctx, rt)
+}
+
+func (r *runtimeRegistry) removeRuntimeLocked(ctx context.Context, rt *runtime) error {
+	// Stop tracking this runtime.
+	if err := r.consensus.RootHash().UntrackRuntime(ctx, rt.id); err != nil {
+		return fmt.Errorf("runtime/registry: cannot untrack runtime %s: %w", rt.id, err)
+	}
+
+	rt.stop()
+	delete(r.runtimes, rt.id)
+	if r.cfg.Host != nil {
+		delete(r.cfg.Host.Runtimes, rt.id)
+		delete(r.cfg.Host.NextRuntimes, rt.id)
+	}
+
+	return nil
+}
+
 func (r *runtimeRegistry) StorageRouter() storageAPI.Backend {
 	return NewStorageRouter(
 		func(ns common.Namespace) (storageAPI.Backend, error) {
@@ -481,44 +580,56 @@ func (r *runtimeRegistry) Cleanup() {
 }
 
 func (r *runtimeRegistry) FinishInitialization(ctx context.Context) error {
-	r.RLock()
-	defer r.RUnlock()
+	r.Lock()
+	defer r.Unlock()
 
 	for _, rt := range r.runtimes {
 		if err := rt.finishInitialization(ctx, r.identity); err != nil {
 			return err
 		}
 	}
+	r.initialized = true
+
 	return nil
 }
 
-func (r *runtimeRegistry) addSupportedRuntime(ctx context.Context, id common.Namespace) (rerr error) {
+func (r *runtimeRegistry) addSupportedRuntime(ctx context.Context, id common.Namespace) error {
 	r.Lock()
 	defer r.Unlock()
 
+	_, err := r.addSupportedRuntimeLocked(ctx, id)
+	return err
+}
+
+func (r *runtimeRegistry) addSupportedRuntimeLocked(ctx context.Context, id common.Namespace) (_ *runtime, rerr error) {
 	if len(r.runtimes) >= MaxRuntimeCount {
-		return fmt.Errorf("runtime/registry: too many registered runtimes")
+		return nil, fmt.Errorf("runtime/registry: too many registered runtimes")
 	}
 
 	if _, ok := r.runtimes[id]; ok {
-		return fmt.Errorf("runtime/registry: runtime already registered: %s", id)
+		return nil, fmt.Errorf("runtime/registry: runtime already registered: %s", id)
 	}
 
 	path, err := EnsureRuntimeStateDir(r.dataDir, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rt, err := newRuntime(ctx, id, r.cfg, r.consensus, r.logger)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	rt.managed = true
+	defer func() {
+		if rerr != nil {
+			rt.cancelCtx()
+		}
+	}()
 
 	// Create runtime history keeper.
 	history, err := history.New(path, id, &r.cfg.History)
 	if err != nil {
-		return fmt.Errorf("runtime/registry: cannot create block history for runtime %s: %w", id, err)
+		return nil, fmt.Errorf("runtime/registry: cannot create block history for runtime %s: %w", id, err)
 	}
 	defer func() {
 		if rerr != nil {
@@ -529,7 +640,7 @@ func (r *runtimeRegistry) addSupportedRuntime(ctx context.Context, id common.Nam
 	// Create runtime-specific local storage backend.
 	localStorage, err := localstorage.New(path, LocalStorageFile, id)
 	if err != nil {
-		return fmt.Errorf("runtime/registry: cannot create local storage for runtime %s: %w", id, err)
+		return nil, fmt.Errorf("runtime/registry: cannot create local storage for runtime %s: %w", id, err)
 	}
 	defer func() {
 		if rerr != nil {
@@ -543,14 +654,14 @@ func (r *runtimeRegistry) addSupportedRuntime(ctx context.Context, id common.Nam
 
 	// Start tracking this runtime.
 	if err = r.consensus.RootHash().TrackRuntime(ctx, history); err != nil {
-		return fmt.Errorf("runtime/registry: cannot track runtime %s: %w", id, err)
+		return nil, fmt.Errorf("runtime/registry: cannot track runtime %s: %w", id, err)
 	}
 
 	rt.localStorage = localStorage
 	rt.history = history
 	r.runtimes[id] = rt
 
-	return nil
+	return rt, nil
 }
 
 func newRuntime(
@@ -578,6 +689,7 @@ func newRuntime(
 	if cfg.Host != nil {
 		rt.hostProvisioners = cfg.Host.Provisioners
 		rt.hostConfig = cfg.Host.Runtimes[id]
+		rt.nextHostConfig = cfg.Host.NextRuntimes[id]
 	}
 
 	return rt, nil
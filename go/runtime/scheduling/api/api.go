@@ -32,6 +32,11 @@ type Scheduler interface {
 	// Specifying a zero limit will return all transactions.
 	GetTransactions(limit int) []*transaction.CheckedTransaction
 
+	// TakeEvicted returns the hashes of transactions that were evicted by the scheduler itself
+	// (e.g., because they were replaced, displaced by higher priority transactions or no longer
+	// fit the batch weight limits) since the last call.
+	TakeEvicted() []hash.Hash
+
 	// UnscheduledSize returns number of unscheduled items.
 	UnscheduledSize() uint64
 
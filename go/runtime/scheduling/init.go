@@ -4,16 +4,24 @@ import (
 	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/ordered"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/priorityqueue"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
 
 // New creates a new scheduler.
-func New(maxTxPoolSize uint64, algo string, weightLimits map[transaction.Weight]uint64) (api.Scheduler, error) {
+func New(
+	maxTxPoolSize uint64,
+	maxTxsPerSender uint64,
+	algo string,
+	weightLimits map[transaction.Weight]uint64,
+) (api.Scheduler, error) {
 	switch algo {
 	case simple.Name:
 		return simple.New(priorityqueue.Name, maxTxPoolSize, weightLimits)
+	case ordered.Name:
+		return ordered.New(maxTxPoolSize, maxTxsPerSender, weightLimits)
 	default:
 		return nil, fmt.Errorf("invalid transaction scheduler algorithm: %s", algo)
 	}
@@ -0,0 +1,528 @@
+// Package ordered implements a batching transaction scheduler which orders transactions by priority
+// while respecting per-sender sequence numbers.
+//
+// In addition to the behavior of the simple scheduler it supports:
+//
+//   - Per-sender ordering: transactions of the same sender (as reported by the runtime in CheckTx
+//     metadata) are always scheduled in order of their sequence numbers.
+//   - Gap detection: transactions are only scheduled when all transactions of the same sender with
+//     lower sequence numbers have either been executed (based on the sender's state sequence number
+//     reported in CheckTx metadata) or are scheduled before them. Transactions with already used
+//     sequence numbers are evicted.
+//   - Replacement: a transaction with the same sender and sequence number as an already queued
+//     transaction replaces it in case it has a strictly higher priority.
+//   - Per-sender quotas: the number of queued transactions of a single sender can be limited.
+//   - Eviction: when the pool is full, the lowest priority transaction that is not a dependency of
+//     any other queued transaction is evicted in case the incoming transaction has higher priority.
+//
+// Transactions without sender information are treated as independent.
+package ordered
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/btree"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/api"
+	txpool "github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+const (
+	// Name of the scheduler.
+	Name = registry.TxnSchedulerOrdered
+
+	// gapRecoveryAttempts is the number of consecutive batches in which a sender may be blocked by
+	// a gap in its sequence numbers before the scheduler assumes that its view of the sender's
+	// state is outdated (e.g., because the missing transactions were executed without passing
+	// through the local pool) and schedules the sender's transactions regardless.
+	gapRecoveryAttempts = 10
+)
+
+var (
+	// ErrReplacementUnderpriced is the error returned when a transaction would replace a queued
+	// transaction of the same sender and sequence number without having a higher priority.
+	ErrReplacementUnderpriced = fmt.Errorf("replacement transaction underpriced")
+	// ErrSenderQuotaExceeded is the error returned when a sender already has the maximum number
+	// of transactions queued.
+	ErrSenderQuotaExceeded = fmt.Errorf("sender transaction quota exceeded")
+	// ErrStaleSequence is the error returned when a transaction's sequence number has already
+	// been used by an executed transaction of the same sender.
+	ErrStaleSequence = fmt.Errorf("transaction sequence number already used")
+)
+
+type item struct {
+	tx *transaction.CheckedTransaction
+}
+
+func (i *item) Less(other btree.Item) bool {
+	i2 := other.(*item)
+	if p1, p2 := i.tx.Priority(), i2.tx.Priority(); p1 != p2 {
+		return p1 < p2
+	}
+	// If transactions have same priority, sort arbitrary.
+	h1 := i.tx.Hash()
+	h2 := i2.tx.Hash()
+	return bytes.Compare(h1[:], h2[:]) < 0
+}
+
+// senderQueue is a list of transactions of the same sender, ordered by sequence number.
+type senderQueue []*item
+
+func (sq senderQueue) search(seq uint64) int {
+	return sort.Search(len(sq), func(i int) bool {
+		return sq[i].tx.SenderSeq() >= seq
+	})
+}
+
+// readyItem is a transaction that can be scheduled as all of its dependencies have already been
+// scheduled.
+type readyItem struct {
+	*item
+
+	// queue is the sender queue the item is part of (if any).
+	queue senderQueue
+	// pos is the position of the item in the sender queue.
+	pos int
+}
+
+// readyHeap is a max-heap of ready transactions.
+type readyHeap []*readyItem
+
+func (h readyHeap) Len() int {
+	return len(h)
+}
+
+func (h readyHeap) Less(i, j int) bool {
+	return h[j].item.Less(h[i].item)
+}
+
+func (h readyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *readyHeap) Push(x interface{}) {
+	*h = append(*h, x.(*readyItem))
+}
+
+func (h *readyHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+type scheduler struct {
+	sync.Mutex
+
+	logger *logging.Logger
+
+	transactions map[hash.Hash]*item
+	senders      map[string]senderQueue
+
+	// stateSeqs contains the sequence number of the next executable transaction of each sender
+	// with queued transactions.
+	stateSeqs map[string]uint64
+	// gapAttempts contains the number of consecutive batches each sender was blocked by a gap in
+	// its sequence numbers.
+	gapAttempts map[string]uint64
+
+	// evictIndex contains all transactions that can be evicted without breaking the sequence of
+	// another queued transaction: transactions without a sender and the last transaction of
+	// each sender.
+	evictIndex *btree.BTree
+
+	maxTxPoolSize   uint64
+	maxTxsPerSender uint64
+
+	poolWeights  map[transaction.Weight]uint64
+	weightLimits map[transaction.Weight]uint64
+
+	// evicted contains the hashes of transactions evicted since the last call to TakeEvicted.
+	evicted []hash.Hash
+}
+
+func (s *scheduler) QueueTx(tx *transaction.CheckedTransaction) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for w, l := range s.weightLimits {
+		if tx.Weight(w) > l {
+			return fmt.Errorf("transaction doesn't fit batch weight limit: %w", txpool.ErrCallTooLarge)
+		}
+	}
+
+	if _, ok := s.transactions[tx.Hash()]; ok {
+		// Return success in case of duplicate calls to avoid the client
+		// mistaking this for an actual error.
+		s.logger.Warn("ignoring duplicate call",
+			"tx", tx,
+		)
+		return nil
+	}
+
+	// Check whether the transaction replaces an existing transaction of the same sender.
+	var replaced *item
+	if sender := tx.Sender(); len(sender) > 0 {
+		if tx.SenderSeq() < tx.SenderStateSeq() || tx.SenderSeq() < s.stateSeqs[string(sender)] {
+			return ErrStaleSequence
+		}
+
+		queue := s.senders[string(sender)]
+		switch idx := queue.search(tx.SenderSeq()); {
+		case idx < len(queue) && queue[idx].tx.SenderSeq() == tx.SenderSeq():
+			replaced = queue[idx]
+			if tx.Priority() <= replaced.tx.Priority() {
+				return ErrReplacementUnderpriced
+			}
+		case s.maxTxsPerSender > 0 && uint64(len(queue)) >= s.maxTxsPerSender:
+			return ErrSenderQuotaExceeded
+		}
+	}
+
+	switch {
+	case replaced != nil:
+		s.logger.Debug("replacing transaction",
+			"tx", tx,
+			"replaced", replaced.tx,
+		)
+		s.evictTxLocked(replaced)
+	case s.poolWeights[transaction.WeightCount] >= s.maxTxPoolSize:
+		// Evict the lowest priority transaction when the pool is full.
+		lowest := s.evictIndex.Min()
+		if lowest == nil || lowest.(*item).tx.Priority() >= tx.Priority() {
+			return txpool.ErrFull
+		}
+		s.logger.Debug("evicting transaction",
+			"tx", lowest.(*item).tx,
+		)
+		s.evictTxLocked(lowest.(*item))
+	}
+
+	s.addTxLocked(&item{tx: tx})
+
+	return nil
+}
+
+// NOTE: Assumes lock is held.
+func (s *scheduler) addTxLocked(it *item) {
+	s.transactions[it.tx.Hash()] = it
+	for k, v := range it.tx.Weights() {
+		s.poolWeights[k] += v
+	}
+
+	sender := it.tx.Sender()
+	if len(sender) == 0 {
+		s.evictIndex.ReplaceOrInsert(it)
+		return
+	}
+
+	queue := s.senders[string(sender)]
+	idx := queue.search(it.tx.SenderSeq())
+	queue = append(queue, nil)
+	copy(queue[idx+1:], queue[idx:])
+	queue[idx] = it
+	if idx == len(queue)-1 {
+		// The transaction is the new last transaction of the sender.
+		if idx > 0 {
+			s.evictIndex.Delete(queue[idx-1])
+		}
+		s.evictIndex.ReplaceOrInsert(it)
+	}
+	s.senders[string(sender)] = queue
+
+	// Evict any transactions that have been made stale by a more recent sender state.
+	if stateSeq := it.tx.SenderStateSeq(); stateSeq > s.stateSeqs[string(sender)] {
+		s.stateSeqs[string(sender)] = stateSeq
+		for queue[0].tx.SenderSeq() < stateSeq {
+			s.logger.Debug("evicting stale transaction",
+				"tx", queue[0].tx,
+				"sender_seq", queue[0].tx.SenderSeq(),
+				"state_seq", stateSeq,
+			)
+			s.evictTxLocked(queue[0])
+			queue = s.senders[string(sender)]
+		}
+	}
+}
+
+// NOTE: Assumes lock is held.
+func (s *scheduler) removeTxLocked(it *item) {
+	delete(s.transactions, it.tx.Hash())
+	for k, v := range it.tx.Weights() {
+		s.poolWeights[k] -= v
+	}
+
+	sender := it.tx.Sender()
+	if len(sender) == 0 {
+		s.evictIndex.Delete(it)
+		return
+	}
+
+	queue := s.senders[string(sender)]
+	idx := queue.search(it.tx.SenderSeq())
+	if idx >= len(queue) || queue[idx] != it {
+		panic(fmt.Errorf("inconsistent sender queue for transaction %s", it.tx.Hash()))
+	}
+	if idx == len(queue)-1 {
+		// The transaction is the last transaction of the sender.
+		s.evictIndex.Delete(it)
+		if idx > 0 {
+			s.evictIndex.ReplaceOrInsert(queue[idx-1])
+		}
+	}
+	queue = append(queue[:idx], queue[idx+1:]...)
+
+	switch len(queue) {
+	case 0:
+		delete(s.senders, string(sender))
+		delete(s.stateSeqs, string(sender))
+		delete(s.gapAttempts, string(sender))
+	default:
+		s.senders[string(sender)] = queue
+	}
+}
+
+// NOTE: Assumes lock is held.
+func (s *scheduler) evictTxLocked(it *item) {
+	s.evicted = append(s.evicted, it.tx.Hash())
+	s.removeTxLocked(it)
+}
+
+func (s *scheduler) RemoveTxBatch(batch []hash.Hash) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, txHash := range batch {
+		it, ok := s.transactions[txHash]
+		if !ok {
+			continue
+		}
+
+		// Transactions are mostly removed after being included in a block, so assume that the
+		// sender's state has advanced. In case it has not, the sender's following transactions
+		// will fail execution the same as they would without gap detection.
+		if sender := string(it.tx.Sender()); len(sender) > 0 && it.tx.SenderSeq() >= s.stateSeqs[sender] {
+			s.stateSeqs[sender] = it.tx.SenderSeq() + 1
+		}
+		s.removeTxLocked(it)
+	}
+}
+
+// isSenderReadyLocked returns true iff the given first queued transaction of a sender can be
+// scheduled without leaving a gap in the sender's sequence numbers.
+//
+// NOTE: Assumes lock is held.
+func (s *scheduler) isSenderReadyLocked(sender string, first *item) bool {
+	stateSeq := s.stateSeqs[sender]
+	if first.tx.SenderSeq() <= stateSeq {
+		delete(s.gapAttempts, sender)
+		return true
+	}
+
+	s.gapAttempts[sender]++
+	if s.gapAttempts[sender] < gapRecoveryAttempts {
+		return false
+	}
+
+	s.logger.Warn("sequence gap persisted, assuming outdated sender state",
+		"tx", first.tx,
+		"sender_seq", first.tx.SenderSeq(),
+		"state_seq", stateSeq,
+	)
+	s.stateSeqs[sender] = first.tx.SenderSeq()
+	delete(s.gapAttempts, sender)
+	return true
+}
+
+func (s *scheduler) GetBatch(force bool) []*transaction.CheckedTransaction {
+	s.Lock()
+	defer s.Unlock()
+
+	// Check if a batch is ready.
+	var weightLimitReached bool
+	for k, v := range s.weightLimits {
+		if s.poolWeights[k] >= v {
+			weightLimitReached = true
+			break
+		}
+	}
+	if !weightLimitReached && !force {
+		return nil
+	}
+
+	// Initially, transactions without a sender and the first transaction of each sender that is
+	// not blocked by a gap in its sequence numbers are ready.
+	var ready readyHeap
+	for _, it := range s.transactions {
+		if len(it.tx.Sender()) == 0 {
+			ready = append(ready, &readyItem{item: it})
+		}
+	}
+	for sender, queue := range s.senders {
+		if !s.isSenderReadyLocked(sender, queue[0]) {
+			continue
+		}
+		ready = append(ready, &readyItem{item: queue[0], queue: queue})
+	}
+	heap.Init(&ready)
+
+	var batch []*transaction.CheckedTransaction
+	batchWeights := make(map[transaction.Weight]uint64)
+	var toRemove []*item
+BatchLoop:
+	for ready.Len() > 0 {
+		ri := heap.Pop(&ready).(*readyItem)
+
+		// Check if the call fits into the batch.
+		for w, limit := range s.weightLimits {
+			txW := ri.tx.Weight(w)
+			// Transaction weight greater than the limit. Drop the tx from the pool. Any following
+			// transactions of the same sender are not scheduled as they depend on this one.
+			if txW > limit {
+				toRemove = append(toRemove, ri.item)
+				continue BatchLoop
+			}
+
+			// Batch full, schedule the batch.
+			if batchWeights[w]+txW > limit {
+				break BatchLoop
+			}
+		}
+
+		// Add the tx to the batch.
+		batch = append(batch, ri.tx)
+		for w, val := range ri.tx.Weights() {
+			if _, ok := s.weightLimits[w]; ok {
+				batchWeights[w] += val
+			}
+		}
+
+		// The next transaction of the same sender is now ready unless there is a gap in the
+		// sequence numbers.
+		if next := ri.pos + 1; ri.queue != nil && next < len(ri.queue) && ri.queue[next].tx.SenderSeq() == ri.tx.SenderSeq()+1 {
+			heap.Push(&ready, &readyItem{item: ri.queue[next], queue: ri.queue, pos: next})
+		}
+	}
+
+	// Remove transactions discovered to be too big to even fit the batch.
+	// This can happen if weight limits changed after the transaction was
+	// already set to be scheduled.
+	for _, it := range toRemove {
+		s.evictTxLocked(it)
+	}
+
+	return batch
+}
+
+func (s *scheduler) GetKnownBatch(batch []hash.Hash) ([]*transaction.CheckedTransaction, map[hash.Hash]int) {
+	s.Lock()
+	defer s.Unlock()
+
+	result := make([]*transaction.CheckedTransaction, 0, len(batch))
+	missing := make(map[hash.Hash]int)
+	for index, txHash := range batch {
+		if it, ok := s.transactions[txHash]; ok {
+			result = append(result, it.tx)
+		} else {
+			result = append(result, nil)
+			missing[txHash] = index
+		}
+	}
+	return result, missing
+}
+
+func (s *scheduler) GetTransactions(limit int) []*transaction.CheckedTransaction {
+	s.Lock()
+	defer s.Unlock()
+
+	count := len(s.transactions)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	result := make([]*transaction.CheckedTransaction, 0, count)
+	for _, it := range s.transactions {
+		if len(result) >= count {
+			break
+		}
+		result = append(result, it.tx)
+	}
+	return result
+}
+
+func (s *scheduler) TakeEvicted() []hash.Hash {
+	s.Lock()
+	defer s.Unlock()
+
+	evicted := s.evicted
+	s.evicted = nil
+	return evicted
+}
+
+func (s *scheduler) UnscheduledSize() uint64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.poolWeights[transaction.WeightCount]
+}
+
+func (s *scheduler) IsQueued(id hash.Hash) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	_, ok := s.transactions[id]
+	return ok
+}
+
+func (s *scheduler) UpdateParameters(weightLimits map[transaction.Weight]uint64) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.weightLimits = weightLimits
+
+	// Any transaction not within the new limits will get removed during GetBatch iteration.
+}
+
+func (s *scheduler) Clear() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.transactions = make(map[hash.Hash]*item)
+	s.senders = make(map[string]senderQueue)
+	s.stateSeqs = make(map[string]uint64)
+	s.gapAttempts = make(map[string]uint64)
+	s.evictIndex.Clear(true)
+	s.poolWeights = make(map[transaction.Weight]uint64)
+	s.evicted = nil
+}
+
+func (s *scheduler) Name() string {
+	return Name
+}
+
+// New creates a new ordered scheduler.
+//
+// A zero maxTxsPerSender means that the number of transactions per sender is not limited.
+func New(maxTxPoolSize, maxTxsPerSender uint64, weightLimits map[transaction.Weight]uint64) (api.Scheduler, error) {
+	return &scheduler{
+		logger:          logging.GetLogger("runtime/scheduling").With("scheduler", Name),
+		transactions:    make(map[hash.Hash]*item),
+		senders:         make(map[string]senderQueue),
+		stateSeqs:       make(map[string]uint64),
+		gapAttempts:     make(map[string]uint64),
+		evictIndex:      btree.New(2),
+		maxTxPoolSize:   maxTxPoolSize,
+		maxTxsPerSender: maxTxsPerSender,
+		poolWeights:     make(map[transaction.Weight]uint64),
+		weightLimits:    weightLimits,
+	}, nil
+}
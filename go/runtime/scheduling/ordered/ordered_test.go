@@ -0,0 +1,227 @@
+package ordered
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	txpool "github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/tests"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+func newTx(data string, priority uint64, sender string, seq, stateSeq uint64) *transaction.CheckedTransaction {
+	var s []byte
+	if sender != "" {
+		s = []byte(sender)
+	}
+	return transaction.NewCheckedTransactionWithSender([]byte(data), priority, nil, s, seq, stateSeq)
+}
+
+func removeBatch(scheduler interface{ RemoveTxBatch([]hash.Hash) }, batch []*transaction.CheckedTransaction) {
+	hashes := make([]hash.Hash, len(batch))
+	for i, tx := range batch {
+		hashes[i] = tx.Hash()
+	}
+	scheduler.RemoveTxBatch(hashes)
+}
+
+func TestOrderedScheduler(t *testing.T) {
+	weightLimits := map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 16 * 1024 * 1024,
+	}
+
+	algo, err := New(100, 0, weightLimits)
+	require.NoError(t, err, "New()")
+	tests.SchedulerImplementationTests(t, algo)
+}
+
+func TestOrderedSchedulerSenderOrdering(t *testing.T) {
+	require := require.New(t)
+
+	algo, err := New(100, 0, map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 1000,
+	})
+	require.NoError(err, "New()")
+
+	// Sender A's later transactions have higher priorities, but must still be scheduled in order.
+	a0 := newTx("a0", 1, "alice", 0, 0)
+	a1 := newTx("a1", 50, "alice", 1, 0)
+	a2 := newTx("a2", 100, "alice", 2, 0)
+	b0 := newTx("b0", 10, "bob", 5, 5)
+	b1 := newTx("b1", 5, "bob", 6, 5)
+	c := newTx("c", 20, "", 0, 0)
+	for _, tx := range []*transaction.CheckedTransaction{a2, b1, a1, c, b0, a0} {
+		require.NoError(algo.QueueTx(tx), "QueueTx(%s)", tx)
+	}
+
+	batch := algo.GetBatch(true)
+	require.EqualValues([]*transaction.CheckedTransaction{c, b0, b1, a0, a1, a2}, batch, "transactions should be ordered")
+
+	// Limited batch sizes should respect ordering as well.
+	algo.UpdateParameters(map[transaction.Weight]uint64{
+		transaction.WeightCount:     2,
+		transaction.WeightSizeBytes: 1000,
+	})
+	batch = algo.GetBatch(false)
+	require.EqualValues([]*transaction.CheckedTransaction{c, b0}, batch, "transactions should be ordered")
+	removeBatch(algo, batch)
+	batch = algo.GetBatch(false)
+	require.EqualValues([]*transaction.CheckedTransaction{b1, a0}, batch, "transactions should be ordered")
+	removeBatch(algo, batch)
+	batch = algo.GetBatch(true)
+	require.EqualValues([]*transaction.CheckedTransaction{a1, a2}, batch, "transactions should be ordered")
+	removeBatch(algo, batch)
+	require.EqualValues(0, algo.UnscheduledSize(), "no transactions should remain")
+}
+
+func TestOrderedSchedulerReplacement(t *testing.T) {
+	require := require.New(t)
+
+	algo, err := New(100, 0, map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 1000,
+	})
+	require.NoError(err, "New()")
+
+	tx := newTx("tx", 10, "alice", 0, 0)
+	require.NoError(algo.QueueTx(tx), "QueueTx")
+
+	err = algo.QueueTx(newTx("cheaper", 5, "alice", 0, 0))
+	require.Equal(ErrReplacementUnderpriced, err, "replacement with lower priority should fail")
+	err = algo.QueueTx(newTx("same", 10, "alice", 0, 0))
+	require.Equal(ErrReplacementUnderpriced, err, "replacement with equal priority should fail")
+	require.True(algo.IsQueued(tx.Hash()), "original transaction should remain queued")
+
+	replacement := newTx("replacement", 11, "alice", 0, 0)
+	require.NoError(algo.QueueTx(replacement), "replacement with higher priority should succeed")
+	require.False(algo.IsQueued(tx.Hash()), "original transaction should be replaced")
+	require.True(algo.IsQueued(replacement.Hash()), "replacement should be queued")
+	require.EqualValues(1, algo.UnscheduledSize())
+	require.Equal([]hash.Hash{tx.Hash()}, algo.TakeEvicted(), "replaced transaction should be reported as evicted")
+	require.Empty(algo.TakeEvicted(), "evicted transactions should only be reported once")
+
+	require.EqualValues([]*transaction.CheckedTransaction{replacement}, algo.GetBatch(true))
+}
+
+func TestOrderedSchedulerSenderQuota(t *testing.T) {
+	require := require.New(t)
+
+	algo, err := New(100, 2, map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 1000,
+	})
+	require.NoError(err, "New()")
+
+	require.NoError(algo.QueueTx(newTx("a0", 1, "alice", 0, 0)))
+	require.NoError(algo.QueueTx(newTx("a1", 1, "alice", 1, 0)))
+	err = algo.QueueTx(newTx("a2", 1, "alice", 2, 0))
+	require.Equal(ErrSenderQuotaExceeded, err, "sender quota should be enforced")
+
+	// Replacements do not count towards the quota.
+	require.NoError(algo.QueueTx(newTx("a1-replacement", 2, "alice", 1, 0)))
+	// Other senders are not affected.
+	require.NoError(algo.QueueTx(newTx("b0", 1, "bob", 0, 0)))
+	require.EqualValues(3, algo.UnscheduledSize())
+}
+
+func TestOrderedSchedulerEviction(t *testing.T) {
+	require := require.New(t)
+
+	algo, err := New(3, 0, map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 1000,
+	})
+	require.NoError(err, "New()")
+
+	// Sender's first transaction has the lowest priority, but evicting it would break the sender's
+	// sequence so the last transaction of the sender must be evicted instead.
+	a0 := newTx("a0", 1, "alice", 0, 0)
+	a1 := newTx("a1", 5, "alice", 1, 0)
+	c := newTx("c", 10, "", 0, 0)
+	for _, tx := range []*transaction.CheckedTransaction{a0, a1, c} {
+		require.NoError(algo.QueueTx(tx), "QueueTx(%s)", tx)
+	}
+
+	err = algo.QueueTx(newTx("low", 5, "", 0, 0))
+	require.Equal(txpool.ErrFull, err, "transaction with too low priority should be rejected")
+
+	high := newTx("high", 6, "", 0, 0)
+	require.NoError(algo.QueueTx(high), "transaction with higher priority should evict")
+	require.False(algo.IsQueued(a1.Hash()), "last transaction of the sender should be evicted")
+	require.True(algo.IsQueued(a0.Hash()), "first transaction of the sender should remain")
+	require.EqualValues(3, algo.UnscheduledSize())
+
+	// Now the sender's first transaction is the eviction candidate.
+	higher := newTx("higher", 7, "", 0, 0)
+	require.NoError(algo.QueueTx(higher), "transaction with higher priority should evict")
+	require.False(algo.IsQueued(a0.Hash()), "remaining transaction of the sender should be evicted")
+	require.Equal([]hash.Hash{a1.Hash(), a0.Hash()}, algo.TakeEvicted(), "evicted transactions should be reported")
+
+	for i := 0; i < 3; i++ {
+		err = algo.QueueTx(newTx(fmt.Sprintf("tx-%d", i), 1, "", 0, 0))
+		require.Equal(txpool.ErrFull, err, "transaction with too low priority should be rejected")
+	}
+	require.ElementsMatch([]*transaction.CheckedTransaction{c, high, higher}, algo.GetTransactions(0))
+}
+
+func TestOrderedSchedulerSequenceGaps(t *testing.T) {
+	require := require.New(t)
+
+	algo, err := New(100, 0, map[transaction.Weight]uint64{
+		transaction.WeightCount:     10,
+		transaction.WeightSizeBytes: 1000,
+	})
+	require.NoError(err, "New()")
+
+	// Transactions following a gap should not be scheduled until the gap is filled.
+	a0 := newTx("a0", 1, "alice", 0, 0)
+	a2 := newTx("a2", 1, "alice", 2, 0)
+	require.NoError(algo.QueueTx(a0), "QueueTx")
+	require.NoError(algo.QueueTx(a2), "QueueTx")
+	require.EqualValues([]*transaction.CheckedTransaction{a0}, algo.GetBatch(true), "gap should be detected")
+
+	a1 := newTx("a1", 1, "alice", 1, 0)
+	require.NoError(algo.QueueTx(a1), "QueueTx")
+	require.EqualValues([]*transaction.CheckedTransaction{a0, a1, a2}, algo.GetBatch(true), "filled gap should be scheduled")
+
+	// Included transactions advance the sender's state.
+	removeBatch(algo, []*transaction.CheckedTransaction{a0})
+	require.EqualValues([]*transaction.CheckedTransaction{a1, a2}, algo.GetBatch(true), "remaining transactions should be scheduled")
+	err = algo.QueueTx(newTx("a0-again", 2, "alice", 0, 0))
+	require.Equal(ErrStaleSequence, err, "transaction with used sequence number should be rejected")
+
+	// A more recent sender state should evict stale transactions.
+	a3 := newTx("a3", 1, "alice", 3, 2)
+	require.NoError(algo.QueueTx(a3), "QueueTx")
+	require.False(algo.IsQueued(a1.Hash()), "stale transaction should be evicted")
+	require.Equal([]hash.Hash{a1.Hash()}, algo.TakeEvicted(), "stale transaction should be reported as evicted")
+	require.EqualValues([]*transaction.CheckedTransaction{a2, a3}, algo.GetBatch(true), "remaining transactions should be scheduled")
+	removeBatch(algo, []*transaction.CheckedTransaction{a2, a3})
+
+	// Senders blocked by a gap for too long should be scheduled regardless.
+	b5 := newTx("b5", 1, "bob", 5, 3)
+	require.NoError(algo.QueueTx(b5), "QueueTx")
+	for i := 0; i < gapRecoveryAttempts-1; i++ {
+		require.Empty(algo.GetBatch(true), "sender should be blocked by gap")
+	}
+	require.EqualValues([]*transaction.CheckedTransaction{b5}, algo.GetBatch(true), "sender should recover from gap")
+
+	removeBatch(algo, []*transaction.CheckedTransaction{b5})
+	require.EqualValues(0, algo.UnscheduledSize(), "no transactions should remain")
+}
+
+func BenchmarkOrderedScheduler(b *testing.B) {
+	weightLimits := map[transaction.Weight]uint64{
+		transaction.WeightCount:     1000,
+		transaction.WeightSizeBytes: 16 * 1024 * 1024,
+	}
+
+	algo, err := New(1000000, 0, weightLimits)
+	require.NoError(b, err, "New()")
+	tests.SchedulerImplementationBenchmarks(b, algo)
+}
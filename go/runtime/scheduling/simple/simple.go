@@ -57,6 +57,10 @@ func (s *scheduler) GetTransactions(limit int) []*transaction.CheckedTransaction
 	return s.txPool.GetTransactions(limit)
 }
 
+func (s *scheduler) TakeEvicted() []hash.Hash {
+	return s.txPool.TakeEvicted()
+}
+
 func (s *scheduler) UnscheduledSize() uint64 {
 	return s.txPool.Size()
 }
@@ -48,6 +48,11 @@ type TxPool interface {
 	// RemoveBatch removes a batch from the transaction pool.
 	RemoveBatch(batch []hash.Hash)
 
+	// TakeEvicted returns the hashes of transactions that were evicted by the transaction pool
+	// itself (e.g., because they were displaced by higher priority transactions or no longer fit
+	// the batch weight limits) since the last call.
+	TakeEvicted() []hash.Hash
+
 	// IsQueued returns whether a transaction is in the queue already.
 	IsQueued(txHash hash.Hash) bool
 
@@ -43,6 +43,9 @@ type priorityQueue struct {
 	weightLimits map[transaction.Weight]uint64
 
 	lowestPriority uint64
+
+	// evicted contains the hashes of transactions evicted since the last call to TakeEvicted.
+	evicted []hash.Hash
 }
 
 // Implements api.TxPool.
@@ -73,7 +76,7 @@ func (q *priorityQueue) Add(tx *transaction.CheckedTransaction) error {
 	if needsPop {
 		lpi := q.priorityIndex.Min()
 		if lpi != nil {
-			q.removeTxsLocked([]*item{lpi.(*item)})
+			q.evictTxsLocked([]*item{lpi.(*item)})
 		}
 	}
 
@@ -156,11 +159,19 @@ func (q *priorityQueue) GetBatch(force bool) []*transaction.CheckedTransaction {
 	// Remove transactions discovered to be too big to even fit the batch.
 	// This can happen if weight limits changed after the transaction was
 	// already set to be scheduled.
-	q.removeTxsLocked(toRemove)
+	q.evictTxsLocked(toRemove)
 
 	return batch
 }
 
+// NOTE: Assumes lock is held.
+func (q *priorityQueue) evictTxsLocked(items []*item) {
+	for _, item := range items {
+		q.evicted = append(q.evicted, item.tx.Hash())
+	}
+	q.removeTxsLocked(items)
+}
+
 func (q *priorityQueue) removeTxsLocked(items []*item) {
 	for _, item := range items {
 		delete(q.transactions, item.tx.Hash())
@@ -239,6 +250,16 @@ func (q *priorityQueue) RemoveBatch(batch []hash.Hash) {
 	q.removeTxsLocked(items)
 }
 
+// Implements api.TxPool.
+func (q *priorityQueue) TakeEvicted() []hash.Hash {
+	q.Lock()
+	defer q.Unlock()
+
+	evicted := q.evicted
+	q.evicted = nil
+	return evicted
+}
+
 // Implements api.TxPool.
 func (q *priorityQueue) IsQueued(txHash hash.Hash) bool {
 	q.Lock()
@@ -275,6 +296,7 @@ func (q *priorityQueue) Clear() {
 	q.transactions = make(map[hash.Hash]*item)
 	q.poolWeights = make(map[transaction.Weight]uint64)
 	q.lowestPriority = 0
+	q.evicted = nil
 }
 
 // NOTE: Assumes lock is held.
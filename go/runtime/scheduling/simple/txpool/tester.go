@@ -224,6 +224,8 @@ func testUpdateConfig(t *testing.T, pool api.TxPool) {
 	require.Empty(t, batch, "no transaction should be returned")
 	// Make sure the transaction was removed.
 	require.EqualValues(t, 0, pool.Size(), "transaction should get removed after update")
+	require.Len(t, pool.TakeEvicted(), 1, "removed transaction should be reported as evicted")
+	require.Empty(t, pool.TakeEvicted(), "evicted transactions should only be reported once")
 }
 
 func testWeights(t *testing.T, pool api.TxPool) {
@@ -352,6 +354,7 @@ func testPriority(t *testing.T, pool api.TxPool) {
 	)
 	err := pool.Add(highTx)
 	require.NoError(t, err, "higher priority transaction should still get queued")
+	require.Equal(t, []hash.Hash{txs[1].Hash()}, pool.TakeEvicted(), "lowest priority transaction should be evicted")
 
 	batch = pool.GetBatch(true)
 	require.Len(t, batch, 3, "three transactions should be returned")
@@ -183,6 +183,16 @@ type CheckedTransaction struct {
 	// in the CheckTx response.
 	weights map[Weight]uint64
 
+	// sender is the optional opaque transaction sender identifier as specified
+	// by the runtime in the CheckTx response.
+	sender []byte
+	// senderSeq is the transaction's sequence number (nonce) among transactions
+	// of the same sender.
+	senderSeq uint64
+	// senderStateSeq is the sequence number of the sender's next transaction
+	// that can be executed given the state at the time of the check.
+	senderStateSeq uint64
+
 	hash hash.Hash
 }
 
@@ -199,14 +209,31 @@ func RawCheckedTransaction(raw []byte) *CheckedTransaction {
 // NewCheckedTransaction creates a new CheckedTransactions from the provided
 // bytes, priority and weights.
 func NewCheckedTransaction(tx []byte, priority uint64, weights map[Weight]uint64) *CheckedTransaction {
+	return NewCheckedTransactionWithSender(tx, priority, weights, nil, 0, 0)
+}
+
+// NewCheckedTransactionWithSender creates a new CheckedTransactions from the
+// provided bytes, priority, weights, sender, sender sequence number and the
+// sender's state sequence number.
+func NewCheckedTransactionWithSender(
+	tx []byte,
+	priority uint64,
+	weights map[Weight]uint64,
+	sender []byte,
+	senderSeq uint64,
+	senderStateSeq uint64,
+) *CheckedTransaction {
 	if weights == nil {
 		weights = make(map[Weight]uint64)
 	}
 	checkedTx := &CheckedTransaction{
-		tx:       tx,
-		priority: priority,
-		weights:  weights,
-		hash:     hash.NewFromBytes(tx),
+		tx:             tx,
+		priority:       priority,
+		weights:        weights,
+		sender:         sender,
+		senderSeq:      senderSeq,
+		senderStateSeq: senderStateSeq,
+		hash:           hash.NewFromBytes(tx),
 	}
 	checkedTx.weights[WeightSizeBytes] = checkedTx.Size()
 	checkedTx.weights[WeightCount] = 1
@@ -232,6 +259,25 @@ func (t *CheckedTransaction) Weights() map[Weight]uint64 {
 	return t.weights
 }
 
+// Sender returns the transaction sender identifier.
+//
+// An empty sender means that the runtime did not provide any sender information.
+func (t *CheckedTransaction) Sender() []byte {
+	return t.sender
+}
+
+// SenderSeq returns the transaction sequence number among transactions of the
+// same sender.
+func (t *CheckedTransaction) SenderSeq() uint64 {
+	return t.senderSeq
+}
+
+// SenderStateSeq returns the sequence number of the sender's next transaction
+// that could be executed at the time the transaction was checked.
+func (t *CheckedTransaction) SenderStateSeq() uint64 {
+	return t.senderStateSeq
+}
+
 // Hash returns the hash of the transaction binary data.
 func (t *CheckedTransaction) Hash() hash.Hash {
 	return t.hash
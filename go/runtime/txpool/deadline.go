@@ -0,0 +1,161 @@
+package txpool
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/google/btree"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+)
+
+// txDeadline is the deadline after which a transaction expires and is evicted from the pool.
+type txDeadline struct {
+	// round is the last round in which the transaction is still valid (zero means no round-based
+	// deadline).
+	round uint64
+	// time is the time after which the transaction expires (zero means no time-based deadline).
+	time time.Time
+}
+
+// isExpired returns true iff the deadline has passed given the latest runtime round and the
+// current time. Once the latest round reaches the round deadline, the transaction can no longer be
+// included in any future round.
+func (d *txDeadline) isExpired(round uint64, now time.Time) bool {
+	if d.round != 0 && round >= d.round {
+		return true
+	}
+	if !d.time.IsZero() && now.After(d.time) {
+		return true
+	}
+	return false
+}
+
+// newTxDeadline computes the deadline of a transaction accepted at the given latest round and time
+// based on the configured maximum lifetime and the (optional) deadline provided by the runtime. In
+// case both are available, the earlier one is used.
+//
+// In case the transaction has no deadline, nil is returned.
+func newTxDeadline(cfg *Config, meta *protocol.CheckTxMetadata, round uint64, now time.Time) *txDeadline {
+	var d txDeadline
+	if cfg.MaxLifetimeRounds > 0 {
+		d.round = round + cfg.MaxLifetimeRounds
+	}
+	if cfg.MaxLifetime > 0 {
+		d.time = now.Add(cfg.MaxLifetime)
+	}
+
+	if meta != nil {
+		if meta.ExpiryRound != 0 && (d.round == 0 || meta.ExpiryRound < d.round) {
+			d.round = meta.ExpiryRound
+		}
+		if meta.ExpiryTime != 0 {
+			expiryTime := time.Unix(int64(meta.ExpiryTime), 0)
+			if d.time.IsZero() || expiryTime.Before(d.time) {
+				d.time = expiryTime
+			}
+		}
+	}
+
+	if d.round == 0 && d.time.IsZero() {
+		return nil
+	}
+	return &d
+}
+
+// deadlineItem is an entry in a deadline index.
+type deadlineItem struct {
+	// deadline is either the round or the UNIX time in nanoseconds, depending on the index.
+	deadline uint64
+	txHash   hash.Hash
+}
+
+func (i *deadlineItem) Less(other btree.Item) bool {
+	i2 := other.(*deadlineItem)
+	if i.deadline != i2.deadline {
+		return i.deadline < i2.deadline
+	}
+	return bytes.Compare(i.txHash[:], i2.txHash[:]) < 0
+}
+
+// txDeadlines tracks the deadlines of transactions. Deadlines are indexed by round and by time so
+// that finding expired transactions does not require visiting all tracked transactions.
+type txDeadlines struct {
+	deadlines map[hash.Hash]*txDeadline
+	byRound   *btree.BTree
+	byTime    *btree.BTree
+}
+
+// add starts tracking the deadline of the given transaction.
+func (d *txDeadlines) add(txHash hash.Hash, deadline *txDeadline) {
+	d.remove(txHash)
+
+	d.deadlines[txHash] = deadline
+	if deadline.round != 0 {
+		d.byRound.ReplaceOrInsert(&deadlineItem{deadline: deadline.round, txHash: txHash})
+	}
+	if !deadline.time.IsZero() {
+		d.byTime.ReplaceOrInsert(&deadlineItem{deadline: uint64(deadline.time.UnixNano()), txHash: txHash})
+	}
+}
+
+// get returns the deadline of the given transaction (if any).
+func (d *txDeadlines) get(txHash hash.Hash) *txDeadline {
+	return d.deadlines[txHash]
+}
+
+// remove stops tracking the deadline of the given transaction.
+func (d *txDeadlines) remove(txHash hash.Hash) {
+	deadline, ok := d.deadlines[txHash]
+	if !ok {
+		return
+	}
+
+	delete(d.deadlines, txHash)
+	if deadline.round != 0 {
+		d.byRound.Delete(&deadlineItem{deadline: deadline.round, txHash: txHash})
+	}
+	if !deadline.time.IsZero() {
+		d.byTime.Delete(&deadlineItem{deadline: uint64(deadline.time.UnixNano()), txHash: txHash})
+	}
+}
+
+// removeExpired stops tracking and returns all transactions that have expired given the latest
+// runtime round and the current time.
+func (d *txDeadlines) removeExpired(round uint64, now time.Time) []hash.Hash {
+	var expired []hash.Hash
+	seen := make(map[hash.Hash]bool)
+	collect := func(i btree.Item) bool {
+		txHash := i.(*deadlineItem).txHash
+		if !seen[txHash] {
+			seen[txHash] = true
+			expired = append(expired, txHash)
+		}
+		return true
+	}
+	// Transactions expire once the latest round reaches the round deadline.
+	d.byRound.AscendLessThan(&deadlineItem{deadline: round + 1}, collect)
+	// Transactions expire once the current time is after the time deadline.
+	d.byTime.AscendLessThan(&deadlineItem{deadline: uint64(now.UnixNano())}, collect)
+
+	for _, txHash := range expired {
+		d.remove(txHash)
+	}
+	return expired
+}
+
+// clear stops tracking all deadlines.
+func (d *txDeadlines) clear() {
+	d.deadlines = make(map[hash.Hash]*txDeadline)
+	d.byRound.Clear(false)
+	d.byTime.Clear(false)
+}
+
+func newTxDeadlines() *txDeadlines {
+	return &txDeadlines{
+		deadlines: make(map[hash.Hash]*txDeadline),
+		byRound:   btree.New(2),
+		byTime:    btree.New(2),
+	}
+}
@@ -0,0 +1,82 @@
+package txpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+)
+
+func TestTxDeadline(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1_000_000, 0)
+
+	// No configured lifetime and no runtime-provided deadline.
+	require.Nil(newTxDeadline(&Config{}, nil, 10, now), "transactions should not expire by default")
+	require.Nil(newTxDeadline(&Config{}, &protocol.CheckTxMetadata{}, 10, now), "transactions should not expire by default")
+
+	// Configured lifetime.
+	cfg := &Config{
+		MaxLifetimeRounds: 5,
+		MaxLifetime:       time.Minute,
+	}
+	d := newTxDeadline(cfg, nil, 10, now)
+	require.NotNil(d)
+	require.EqualValues(15, d.round)
+	require.Equal(now.Add(time.Minute), d.time)
+	require.False(d.isExpired(14, now), "transaction should not expire before the deadline")
+	require.True(d.isExpired(15, now), "transaction should expire once the round deadline is reached")
+	require.True(d.isExpired(10, now.Add(2*time.Minute)), "transaction should expire after the time deadline")
+
+	// Runtime-provided deadlines should be used when earlier than the configured ones.
+	d = newTxDeadline(cfg, &protocol.CheckTxMetadata{
+		ExpiryRound: 12,
+		ExpiryTime:  uint64(now.Add(time.Hour).Unix()),
+	}, 10, now)
+	require.NotNil(d)
+	require.EqualValues(12, d.round, "earlier runtime-provided round deadline should be used")
+	require.Equal(now.Add(time.Minute), d.time, "earlier configured time deadline should be used")
+
+	// Runtime-provided deadlines should be used when there is no configured lifetime.
+	d = newTxDeadline(&Config{}, &protocol.CheckTxMetadata{ExpiryRound: 20}, 10, now)
+	require.NotNil(d)
+	require.EqualValues(20, d.round)
+	require.True(d.time.IsZero())
+	require.False(d.isExpired(19, now.Add(24*time.Hour)), "transaction should not expire based on time")
+	require.True(d.isExpired(20, now))
+}
+
+func TestTxDeadlines(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1_000_000, 0)
+	txA := hash.NewFromBytes([]byte("tx A"))
+	txB := hash.NewFromBytes([]byte("tx B"))
+	txC := hash.NewFromBytes([]byte("tx C"))
+
+	d := newTxDeadlines()
+	d.add(txA, &txDeadline{round: 10})
+	d.add(txB, &txDeadline{time: now.Add(time.Minute)})
+	d.add(txC, &txDeadline{round: 12, time: now.Add(time.Minute)})
+
+	require.Empty(d.removeExpired(9, now), "nothing should expire before the deadlines")
+	require.Equal([]hash.Hash{txA}, d.removeExpired(10, now), "round deadline should expire")
+	require.Nil(d.get(txA), "expired transactions should no longer be tracked")
+	require.Empty(d.removeExpired(10, now), "expired transactions should only be reported once")
+
+	// Updating a deadline should replace the previous one.
+	d.add(txB, &txDeadline{time: now.Add(time.Hour)})
+	require.Equal([]hash.Hash{txC}, d.removeExpired(13, now.Add(2*time.Minute)), "transactions should be reported once")
+	require.NotNil(d.get(txB), "updated deadline should be used")
+
+	d.remove(txB)
+	require.Empty(d.removeExpired(100, now.Add(24*time.Hour)), "removed transactions should not expire")
+
+	d.add(txA, &txDeadline{round: 10})
+	d.clear()
+	require.Empty(d.removeExpired(100, now.Add(24*time.Hour)), "cleared transactions should not expire")
+}
@@ -0,0 +1,350 @@
+package txpool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+// JournalFilename is the filename of the persistent transaction journal.
+const JournalFilename = "txpool_journal.badger.db"
+
+const journalVersion = 1
+
+var (
+	// journalMetadataKeyFmt is the metadata key format.
+	//
+	// Value is CBOR-serialized journalMetadata.
+	journalMetadataKeyFmt = keyformat.New(0x01)
+	// journalTxKeyFmt is the journaled transaction key format.
+	//
+	// Value is CBOR-serialized journalEntry.
+	journalTxKeyFmt = keyformat.New(0x02, &hash.Hash{})
+	// journalTxByTimeKeyFmt is the index of journaled transactions by the time they were first
+	// accepted (UNIX timestamp) which enables pruning without scanning the whole journal.
+	//
+	// Value is empty.
+	journalTxByTimeKeyFmt = keyformat.New(0x03, uint64(0), &hash.Hash{})
+
+	errJournalClosed = fmt.Errorf("journal closed")
+)
+
+type journalMetadata struct {
+	// RuntimeID is the runtime ID this journal is for.
+	RuntimeID common.Namespace `json:"runtime_id"`
+	// Version is the journal schema version.
+	Version uint64 `json:"version"`
+}
+
+// journalEntry is a journaled transaction together with its metadata.
+type journalEntry struct {
+	// Tx is the raw transaction.
+	Tx []byte `json:"tx"`
+	// Local is a flag indicating that the transaction was obtained from a local client.
+	Local bool `json:"local,omitempty"`
+	// Timestamp is the UNIX timestamp of when the transaction was first accepted.
+	Timestamp int64 `json:"timestamp"`
+	// Round is the latest runtime round at the time the transaction was first accepted.
+	Round uint64 `json:"round,omitempty"`
+}
+
+// journal is a persistent journal of transactions accepted into the transaction pool.
+type journal struct {
+	sync.RWMutex
+
+	logger *logging.Logger
+
+	db     *badger.DB
+	gc     *cmnBadger.GCWorker
+	closed bool
+}
+
+// add records the given transactions accepted at the given round. Transactions that are already
+// journaled keep their original timestamp and round.
+func (j *journal) add(txs [][]byte, local []bool, round uint64) error {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.closed {
+		return errJournalClosed
+	}
+
+	// Skip transactions that are already journaled.
+	var (
+		hashes  []hash.Hash
+		entries []journalEntry
+	)
+	now := time.Now().Unix()
+	err := j.db.View(func(tx *badger.Txn) error {
+		for i, rawTx := range txs {
+			txHash := hash.NewFromBytes(rawTx)
+			_, err := tx.Get(journalTxKeyFmt.Encode(&txHash))
+			switch err {
+			case nil:
+				continue
+			case badger.ErrKeyNotFound:
+			default:
+				return err
+			}
+
+			hashes = append(hashes, txHash)
+			entries = append(entries, journalEntry{
+				Tx:        rawTx,
+				Local:     local[i],
+				Timestamp: now,
+				Round:     round,
+			})
+		}
+		return nil
+	})
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+
+	wb := j.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for i := range hashes {
+		if err = wb.Set(journalTxKeyFmt.Encode(&hashes[i]), cbor.Marshal(entries[i])); err != nil {
+			return err
+		}
+		if err = wb.Set(journalTxByTimeKeyFmt.Encode(uint64(now), &hashes[i]), []byte{}); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// remove removes the given transactions from the journal.
+func (j *journal) remove(txHashes []hash.Hash) error {
+	if len(txHashes) == 0 {
+		return nil
+	}
+
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.closed {
+		return errJournalClosed
+	}
+
+	// Look up the timestamps of journaled transactions so the index can be updated.
+	var keys [][]byte
+	err := j.db.View(func(tx *badger.Txn) error {
+		for i := range txHashes {
+			key := journalTxKeyFmt.Encode(&txHashes[i])
+			item, err := tx.Get(key)
+			switch err {
+			case nil:
+			case badger.ErrKeyNotFound:
+				continue
+			default:
+				return err
+			}
+
+			var entry journalEntry
+			if err = item.Value(func(val []byte) error {
+				return cbor.UnmarshalTrusted(val, &entry)
+			}); err != nil {
+				return err
+			}
+			keys = append(keys, key, journalTxByTimeKeyFmt.Encode(uint64(entry.Timestamp), &txHashes[i]))
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	return j.deleteKeys(keys)
+}
+
+// clear removes all transactions from the journal.
+func (j *journal) clear() error {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.closed {
+		return errJournalClosed
+	}
+
+	return j.db.DropPrefix(journalTxKeyFmt.Encode(), journalTxByTimeKeyFmt.Encode())
+}
+
+// entries returns all journaled transactions.
+func (j *journal) entries() ([]*journalEntry, error) {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.closed {
+		return nil, errJournalClosed
+	}
+
+	var entries []*journalEntry
+	err := j.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: journalTxKeyFmt.Encode()})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var entry journalEntry
+			err := it.Item().Value(func(val []byte) error {
+				return cbor.UnmarshalTrusted(val, &entry)
+			})
+			if err != nil {
+				return err
+			}
+			entries = append(entries, &entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// pruneExpired removes all transactions that were accepted more than maxAge ago and returns the
+// number of removed transactions.
+//
+// Only expired transactions are visited as the journal is indexed by timestamp.
+func (j *journal) pruneExpired(maxAge time.Duration) (int, error) {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.closed {
+		return 0, errJournalClosed
+	}
+
+	cutoff := time.Now().Add(-maxAge).Unix()
+
+	var keys [][]byte
+	err := j.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: journalTxByTimeKeyFmt.Encode()})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var (
+				ts     uint64
+				txHash hash.Hash
+			)
+			key := it.Item().KeyCopy(nil)
+			if !journalTxByTimeKeyFmt.Decode(key, &ts, &txHash) {
+				return fmt.Errorf("malformed journal index key: %X", key)
+			}
+			if int64(ts) > cutoff {
+				break
+			}
+			keys = append(keys, key, journalTxKeyFmt.Encode(&txHash))
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	if err = j.deleteKeys(keys); err != nil {
+		return 0, err
+	}
+	return len(keys) / 2, nil
+}
+
+func (j *journal) deleteKeys(keys [][]byte) error {
+	wb := j.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, key := range keys {
+		if err := wb.Delete(key); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (j *journal) ensureMetadata(runtimeID common.Namespace) error {
+	return j.db.Update(func(tx *badger.Txn) error {
+		item, err := tx.Get(journalMetadataKeyFmt.Encode())
+		switch err {
+		case nil:
+		case badger.ErrKeyNotFound:
+			// Create new metadata section.
+			meta := journalMetadata{
+				RuntimeID: runtimeID,
+				Version:   journalVersion,
+			}
+			return tx.Set(journalMetadataKeyFmt.Encode(), cbor.Marshal(meta))
+		default:
+			return err
+		}
+
+		var meta journalMetadata
+		if err = item.Value(func(val []byte) error {
+			return cbor.Unmarshal(val, &meta)
+		}); err != nil {
+			return err
+		}
+
+		// Verify metadata section.
+		if meta.Version != journalVersion {
+			return fmt.Errorf("unsupported journal version (expected: %d got: %d)",
+				journalVersion,
+				meta.Version,
+			)
+		}
+		if !meta.RuntimeID.Equal(&runtimeID) {
+			return fmt.Errorf("journal for different runtime (expected: %s got: %s)",
+				runtimeID,
+				meta.RuntimeID,
+			)
+		}
+		return nil
+	})
+}
+
+func (j *journal) close() {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.closed {
+		return
+	}
+	j.closed = true
+
+	j.gc.Close()
+	j.db.Close()
+}
+
+func openJournal(fn string, runtimeID common.Namespace) (*journal, error) {
+	logger := logging.GetLogger("runtime/txpool/journal").With("path", fn)
+
+	opts := badger.DefaultOptions(fn)
+	opts = opts.WithLogger(cmnBadger.NewLogAdapter(logger))
+	opts = opts.WithSyncWrites(true)
+	opts = opts.WithCompression(options.None)
+
+	db, err := cmnBadger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	j := &journal{
+		logger: logger,
+		db:     db,
+		gc:     cmnBadger.NewGCWorker(logger, db),
+	}
+
+	if err = j.ensureMetadata(runtimeID); err != nil {
+		j.close()
+		return nil, err
+	}
+	return j, nil
+}
@@ -0,0 +1,138 @@
+package txpool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+)
+
+func TestJournal(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-txpool-journal-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, JournalFilename)
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("txpool journal test"), 0)
+
+	j, err := openJournal(fn, runtimeID)
+	require.NoError(err, "openJournal")
+
+	txA := []byte("tx A")
+	txB := []byte("tx B")
+	txC := []byte("tx C")
+
+	err = j.add([][]byte{txA, txB}, []bool{true, false}, 10)
+	require.NoError(err, "add")
+	err = j.add([][]byte{txC}, []bool{false}, 11)
+	require.NoError(err, "add")
+
+	entries, err := j.entries()
+	require.NoError(err, "entries")
+	require.Len(entries, 3, "all added transactions should be journaled")
+
+	err = j.remove([]hash.Hash{hash.NewFromBytes(txB)})
+	require.NoError(err, "remove")
+
+	// Reopen the journal to make sure entries survive restarts.
+	j.close()
+	j, err = openJournal(fn, runtimeID)
+	require.NoError(err, "openJournal")
+
+	entries, err = j.entries()
+	require.NoError(err, "entries")
+	require.Len(entries, 2, "removed transactions should not be journaled")
+	local := make(map[string]bool)
+	for _, entry := range entries {
+		local[string(entry.Tx)] = entry.Local
+	}
+	require.Equal(map[string]bool{string(txA): true, string(txC): false}, local, "transaction metadata should be preserved")
+
+	// Re-adding transactions should not overwrite existing entries.
+	err = j.add([][]byte{txA}, []bool{false}, 20)
+	require.NoError(err, "add")
+	entries, err = j.entries()
+	require.NoError(err, "entries")
+	for _, entry := range entries {
+		if string(entry.Tx) == string(txA) {
+			require.True(entry.Local, "re-adding should not overwrite existing entries")
+			require.EqualValues(10, entry.Round, "re-adding should not overwrite existing entries")
+		}
+	}
+
+	// Removing transactions that are not journaled should be a no-op.
+	err = j.remove([]hash.Hash{hash.NewFromBytes(txB)})
+	require.NoError(err, "remove")
+
+	// Nothing should be pruned while transactions are fresh.
+	pruned, err := j.pruneExpired(time.Hour)
+	require.NoError(err, "pruneExpired")
+	require.EqualValues(0, pruned, "fresh transactions should not be pruned")
+
+	// Everything should be pruned once transactions expire.
+	pruned, err = j.pruneExpired(-time.Hour)
+	require.NoError(err, "pruneExpired")
+	require.EqualValues(2, pruned, "expired transactions should be pruned")
+	entries, err = j.entries()
+	require.NoError(err, "entries")
+	require.Empty(entries, "expired transactions should not be journaled")
+	pruned, err = j.pruneExpired(-time.Hour)
+	require.NoError(err, "pruneExpired")
+	require.EqualValues(0, pruned, "pruned transactions should be removed from the index")
+
+	// Clearing the journal should remove all transactions.
+	err = j.add([][]byte{txA, txB}, []bool{true, false}, 0)
+	require.NoError(err, "add")
+	err = j.clear()
+	require.NoError(err, "clear")
+	entries, err = j.entries()
+	require.NoError(err, "entries")
+	require.Empty(entries, "cleared transactions should not be journaled")
+	pruned, err = j.pruneExpired(-time.Hour)
+	require.NoError(err, "pruneExpired")
+	require.EqualValues(0, pruned, "cleared transactions should be removed from the index")
+
+	// Operations on a closed journal should fail.
+	j.close()
+	j.close()
+	err = j.add([][]byte{txA}, []bool{false}, 0)
+	require.Error(err, "add on a closed journal should fail")
+
+	// Opening the journal for a different runtime should fail.
+	_, err = openJournal(fn, common.NewTestNamespaceFromSeed([]byte("other runtime"), 0))
+	require.Error(err, "openJournal should fail for a different runtime")
+}
+
+func TestJournalClosedOnStop(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-txpool-journal-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("txpool journal test"), 0)
+	cfg := &Config{
+		MaxPoolSize:          10,
+		MaxCheckTxBatchSize:  10,
+		MaxLastSeenCacheSize: 10,
+		MaxStaleCacheSize:    10,
+		JournalDir:           dir,
+	}
+
+	// Stopping a transaction pool that was never started should release the journal.
+	txPool, err := New(runtimeID, cfg, nil, nil)
+	require.NoError(err, "New")
+	txPool.Stop()
+
+	j, err := openJournal(filepath.Join(dir, JournalFilename), runtimeID)
+	require.NoError(err, "openJournal should succeed after the transaction pool is stopped")
+	j.close()
+}
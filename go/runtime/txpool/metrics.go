@@ -21,9 +21,17 @@ var (
 		},
 		[]string{"runtime"},
 	)
+	evictedTxCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_txpool_evicted_tx_count",
+			Help: "Number of transactions evicted from the pool due to expiry.",
+		},
+		[]string{"runtime"},
+	)
 	txpoolCollectors = []prometheus.Collector{
 		pendingCheckSize,
 		pendingScheduleSize,
+		evictedTxCount,
 	}
 
 	metricsOnce sync.Once
@@ -3,6 +3,7 @@ package txpool
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -24,9 +25,13 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
 
+// expiryCheckInterval is the interval at which time-based transaction deadlines are checked.
+const expiryCheckInterval = 1 * time.Second
+
 // Config is the transaction pool configuration.
 type Config struct {
 	MaxPoolSize          uint64
+	MaxTxsPerSender      uint64
 	MaxCheckTxBatchSize  uint64
 	MaxLastSeenCacheSize uint64
 	MaxStaleCacheSize    uint64
@@ -36,6 +41,20 @@ type Config struct {
 	// RecheckInterval is the interval (in rounds) when any pending transactions are subject to a
 	// recheck and any non-passing transactions are removed.
 	RecheckInterval uint64
+
+	// MaxLifetimeRounds is the maximum number of rounds that a transaction can stay in the pool
+	// before it expires and is evicted. Zero means no limit.
+	MaxLifetimeRounds uint64
+	// MaxLifetime is the maximum duration that a transaction can stay in the pool before it
+	// expires and is evicted. Zero means no limit.
+	MaxLifetime time.Duration
+
+	// JournalDir is the directory where the persistent transaction journal is stored. If empty,
+	// the journal is disabled and pending transactions are lost on restart.
+	JournalDir string
+	// JournalMaxAge is the maximum age of journaled transactions. Older transactions are pruned
+	// from the journal and are not replayed on startup. Zero means no limit.
+	JournalMaxAge time.Duration
 }
 
 // TransactionMeta contains the per-transaction metadata.
@@ -49,6 +68,67 @@ type TransactionMeta struct {
 	// Recheck is a flag indicating that this transaction is already in the scheduler pool and is
 	// being subject to recheck.
 	Recheck bool
+
+	// journaled is the journal entry of a transaction that is being replayed from the journal.
+	journaled *journalEntry
+}
+
+// TxStatus is the status of a transaction in the transaction pool.
+type TxStatus uint8
+
+const (
+	// TxStatusAccepted is the status of a transaction that passed checks and was accepted into
+	// the pool.
+	TxStatusAccepted TxStatus = iota + 1
+	// TxStatusScheduled is the status of a transaction that was scheduled in a proposed batch.
+	TxStatusScheduled
+	// TxStatusIncluded is the status of a transaction that was included in a block.
+	TxStatusIncluded
+	// TxStatusEvicted is the status of a transaction that was evicted from the pool for a reason
+	// other than expiry or a failed recheck (e.g., because it was replaced, displaced by higher
+	// priority transactions or the pool was cleared).
+	TxStatusEvicted
+	// TxStatusPublished is the status of a local transaction that was published to the P2P
+	// network.
+	TxStatusPublished
+	// TxStatusExpired is the status of a transaction that expired and was evicted from the pool.
+	TxStatusExpired
+	// TxStatusRejected is the status of a transaction that failed a recheck and was evicted from
+	// the pool.
+	TxStatusRejected
+)
+
+// String returns a string representation of the transaction status.
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusAccepted:
+		return "accepted"
+	case TxStatusScheduled:
+		return "scheduled"
+	case TxStatusIncluded:
+		return "included"
+	case TxStatusEvicted:
+		return "evicted"
+	case TxStatusPublished:
+		return "published"
+	case TxStatusExpired:
+		return "expired"
+	case TxStatusRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("[unknown status: %d]", s)
+	}
+}
+
+// TxEvent is a transaction status change event.
+type TxEvent struct {
+	// Hash is the transaction hash.
+	Hash hash.Hash
+	// Status is the new transaction status.
+	Status TxStatus
+	// Round is the runtime round in which the status changed. For scheduled transactions, this
+	// is the round the transaction was scheduled for.
+	Round uint64
 }
 
 // TransactionPool is an interface for managing a pool of transactions.
@@ -69,9 +149,21 @@ type TransactionPool interface {
 	// SubmitTxNoWait adds the transaction into the transaction pool and returns immediately.
 	SubmitTxNoWait(ctx context.Context, tx []byte, meta *TransactionMeta) error
 
-	// RemoveTxBatch removes a transaction batch from the transaction pool.
+	// SubmitProposedBatch adds the given batch of transactions, obtained from peers in order to
+	// resolve a proposed batch, into the transaction pool.
+	//
+	// The transactions are not checked as they have been proposed by the transaction scheduler so
+	// they are only used when resolving proposed batches via GetKnownBatch and are never queued
+	// for scheduling. They are discarded once the next runtime block is processed.
+	SubmitProposedBatch(batch [][]byte)
+
+	// RemoveTxBatch removes a transaction batch that has been included in a block from the
+	// transaction pool.
 	RemoveTxBatch(txs []hash.Hash)
 
+	// IsPending returns true iff the given transaction is pending to be checked or scheduled.
+	IsPending(txHash hash.Hash) bool
+
 	// GetScheduledBatch returns a batch of transactions ready for scheduling.
 	GetScheduledBatch(force bool) []*transaction.CheckedTransaction
 
@@ -101,6 +193,9 @@ type TransactionPool interface {
 	// in the transaction pool for scheduling.
 	WatchCheckedTransactions() (pubsub.ClosableSubscription, <-chan []*transaction.CheckedTransaction)
 
+	// WatchTxEvents subscribes to transaction status change events.
+	WatchTxEvents() (pubsub.ClosableSubscription, <-chan []*TxEvent)
+
 	// PendingCheckSize returns the number of transactions currently pending to be checked.
 	PendingCheckSize() uint64
 
@@ -159,6 +254,11 @@ type txPool struct {
 	// when clearing the txpool and consulted only when fetching known batches.
 	staleCache *lru.Cache
 
+	// proposedTxs maps from transaction hashes to *transaction.CheckedTransaction. It contains
+	// unchecked transactions obtained from peers to resolve proposed batches.
+	proposedTxsLock sync.Mutex
+	proposedTxs     map[hash.Hash]*transaction.CheckedTransaction
+
 	checkTxCh       *channels.RingChannel
 	checkTxQueue    *checkTxQueue
 	checkTxNotifier *pubsub.Broker
@@ -176,8 +276,19 @@ type txPool struct {
 	epoCh       *channels.RingChannel
 	republishCh *channels.RingChannel
 
+	// journal is the persistent transaction journal. It is nil in case the journal is disabled.
+	journal *journal
+
 	// roundWeightLimits is guarded by schedulerLock.
 	roundWeightLimits map[transaction.Weight]uint64
+	// deadlines tracks the deadlines of scheduled transactions that can expire. It is guarded by
+	// schedulerLock.
+	deadlines *txDeadlines
+	// scheduledRounds maps from transaction hashes to the last round for which the transaction was
+	// reported as scheduled. It is guarded by schedulerLock.
+	scheduledRounds map[hash.Hash]uint64
+
+	txEventNotifier *pubsub.Broker
 }
 
 func (t *txPool) Start() error {
@@ -185,11 +296,18 @@ func (t *txPool) Start() error {
 	go t.republishWorker()
 	go t.recheckWorker()
 	go t.flushWorker()
+	go t.expiryWorker()
 	return nil
 }
 
 func (t *txPool) Stop() {
 	close(t.stopCh)
+
+	// NOTE: The journal is closed here instead of in a worker so it is also released in case the
+	// service was never started. Any concurrent journal operations will fail gracefully.
+	if t.journal != nil {
+		t.journal.close()
+	}
 }
 
 func (t *txPool) Quit() <-chan struct{} {
@@ -255,23 +373,122 @@ func (t *txPool) submitTx(ctx context.Context, rawTx []byte, meta *TransactionMe
 	return nil
 }
 
+func (t *txPool) SubmitProposedBatch(batch [][]byte) {
+	t.proposedTxsLock.Lock()
+	defer t.proposedTxsLock.Unlock()
+
+	for _, rawTx := range batch {
+		tx := transaction.RawCheckedTransaction(rawTx)
+		t.proposedTxs[tx.Hash()] = tx
+	}
+}
+
+func (t *txPool) clearProposedTxs() {
+	t.proposedTxsLock.Lock()
+	defer t.proposedTxsLock.Unlock()
+
+	t.proposedTxs = make(map[hash.Hash]*transaction.CheckedTransaction)
+}
+
 func (t *txPool) RemoveTxBatch(txs []hash.Hash) {
+	t.removeTxBatch(txs, TxStatusIncluded)
+}
+
+// removeTxBatch removes a transaction batch from the transaction pool and emits events with the
+// given status.
+func (t *txPool) removeTxBatch(txs []hash.Hash, status TxStatus) {
+	if len(txs) == 0 {
+		return
+	}
+	round := t.getCurrentRound()
+
 	t.schedulerLock.Lock()
-	defer t.schedulerLock.Unlock()
+	t.removeTxBatchLocked(txs)
+	t.schedulerLock.Unlock()
+
+	t.removeFromJournal(txs)
+	t.emitTxEvents(txs, status, round)
+}
 
+// Guarded by t.schedulerLock.
+func (t *txPool) removeTxBatchLocked(txs []hash.Hash) {
 	t.scheduler.RemoveTxBatch(txs)
 	for _, txHash := range txs {
 		_ = t.staleCache.Remove(txHash)
+		t.untrackTxLocked(txHash)
 	}
 
 	pendingScheduleSize.With(t.getMetricLabels()).Set(float64(t.scheduler.UnscheduledSize()))
 }
 
+// takeEvictedLocked returns any transactions that have been evicted by the scheduler itself and
+// stops tracking them. The caller should pass them to handleEvicted after releasing the lock.
+//
+// Guarded by t.schedulerLock.
+func (t *txPool) takeEvictedLocked() []hash.Hash {
+	evicted := t.scheduler.TakeEvicted()
+	for _, txHash := range evicted {
+		t.untrackTxLocked(txHash)
+	}
+	return evicted
+}
+
+// untrackTxLocked removes any per-transaction state of a transaction that is no longer queued.
+//
+// Guarded by t.schedulerLock.
+func (t *txPool) untrackTxLocked(txHash hash.Hash) {
+	t.deadlines.remove(txHash)
+	delete(t.scheduledRounds, txHash)
+}
+
+// handleEvicted removes the given evicted transactions from the journal and emits events.
+func (t *txPool) handleEvicted(txs []hash.Hash, round uint64) {
+	if len(txs) == 0 {
+		return
+	}
+
+	t.logger.Debug("transactions evicted by the scheduler",
+		"num_txs", len(txs),
+	)
+	t.removeFromJournal(txs)
+	t.emitTxEvents(txs, TxStatusEvicted, round)
+}
+
 func (t *txPool) GetScheduledBatch(force bool) []*transaction.CheckedTransaction {
+	round := t.getCurrentRound() + 1
+
+	t.schedulerLock.Lock()
+	batch := t.scheduler.GetBatch(force)
+	evicted := t.takeEvictedLocked()
+
+	// Only report transactions that have not yet been reported as scheduled for this round as
+	// batches may be requested multiple times per round.
+	var scheduled []hash.Hash
+	for _, tx := range batch {
+		txHash := tx.Hash()
+		if lastRound, ok := t.scheduledRounds[txHash]; ok && lastRound == round {
+			continue
+		}
+		t.scheduledRounds[txHash] = round
+		scheduled = append(scheduled, txHash)
+	}
+	t.schedulerLock.Unlock()
+
+	t.handleEvicted(evicted, round-1)
+	t.emitTxEvents(scheduled, TxStatusScheduled, round)
+
+	return batch
+}
+
+func (t *txPool) IsPending(txHash hash.Hash) bool {
+	if t.checkTxQueue.IsQueued(txHash) {
+		return true
+	}
+
 	t.schedulerLock.Lock()
 	defer t.schedulerLock.Unlock()
 
-	return t.scheduler.GetBatch(force)
+	return t.scheduler != nil && t.scheduler.IsQueued(txHash)
 }
 
 func (t *txPool) GetKnownBatch(batch []hash.Hash) ([]*transaction.CheckedTransaction, map[hash.Hash]int) {
@@ -291,6 +508,20 @@ func (t *txPool) GetKnownBatch(batch []hash.Hash) ([]*transaction.CheckedTransac
 		txs[idx] = tx.(*transaction.CheckedTransaction)
 		delete(missing, txHash)
 	}
+
+	// Check any transactions obtained from peers to resolve proposed batches.
+	t.proposedTxsLock.Lock()
+	defer t.proposedTxsLock.Unlock()
+
+	for txHash, idx := range missing {
+		tx, exists := t.proposedTxs[txHash]
+		if !exists {
+			continue
+		}
+
+		txs[idx] = tx
+		delete(missing, txHash)
+	}
 	return txs, missing
 }
 
@@ -300,9 +531,11 @@ func (t *txPool) ProcessBlock(bi *BlockInfo) error {
 
 	if t.blockInfo == nil || bi.RuntimeBlock.Header.HeaderType == block.EpochTransition {
 		// Handle scheduler updates.
-		if err := t.updateScheduler(bi); err != nil {
+		evicted, err := t.updateScheduler(bi)
+		if err != nil {
 			return fmt.Errorf("failed to update scheduler: %w", err)
 		}
+		t.handleEvicted(evicted, bi.RuntimeBlock.Header.Round)
 
 		t.epoCh.In() <- struct{}{}
 		// Force recheck on epoch transitions.
@@ -311,6 +544,15 @@ func (t *txPool) ProcessBlock(bi *BlockInfo) error {
 
 	t.blockInfo = bi
 
+	// Evict any transactions that have expired.
+	t.evictExpired(bi.RuntimeBlock.Header.Round)
+
+	// Any proposed batches have either been processed or are no longer valid.
+	t.clearProposedTxs()
+
+	// Prune any expired transactions from the journal.
+	t.pruneJournal()
+
 	// Trigger transaction rechecks if needed.
 	if (bi.RuntimeBlock.Header.Round - t.lastRecheckRound) > t.cfg.RecheckInterval {
 		t.recheckTxCh.In() <- struct{}{}
@@ -320,7 +562,9 @@ func (t *txPool) ProcessBlock(bi *BlockInfo) error {
 	return nil
 }
 
-func (t *txPool) updateScheduler(bi *BlockInfo) error {
+// updateScheduler updates the scheduler based on the given block and returns any transactions that
+// had to be evicted in the process.
+func (t *txPool) updateScheduler(bi *BlockInfo) ([]hash.Hash, error) {
 	t.schedulerLock.Lock()
 	defer t.schedulerLock.Unlock()
 
@@ -329,6 +573,7 @@ func (t *txPool) updateScheduler(bi *BlockInfo) error {
 	t.roundWeightLimits[transaction.WeightSizeBytes] = bi.ActiveDescriptor.TxnScheduler.MaxBatchSizeBytes
 	t.roundWeightLimits[transaction.WeightCount] = bi.ActiveDescriptor.TxnScheduler.MaxBatchSize
 
+	var evicted []hash.Hash
 	switch t.scheduler {
 	case nil:
 		// We still need to initialize the scheduler.
@@ -336,23 +581,47 @@ func (t *txPool) updateScheduler(bi *BlockInfo) error {
 			"algorithm", bi.ActiveDescriptor.TxnScheduler.Algorithm,
 		)
 
-		sched, err := scheduling.New(t.cfg.MaxPoolSize, bi.ActiveDescriptor.TxnScheduler.Algorithm, t.roundWeightLimits)
+		sched, err := scheduling.New(
+			t.cfg.MaxPoolSize,
+			t.cfg.MaxTxsPerSender,
+			bi.ActiveDescriptor.TxnScheduler.Algorithm,
+			t.roundWeightLimits,
+		)
 		if err != nil {
-			return fmt.Errorf("failed to create transaction scheduler: %w", err)
+			return nil, fmt.Errorf("failed to create transaction scheduler: %w", err)
 		}
 
 		t.scheduler = sched
 		close(t.initCh)
 	default:
 		// Scheduler already initialized.
-
-		// NOTE: Once there are multiple scheduling algorithms, this should handle the case of the
-		//       algorithm itself being updated (and the scheduler being recreated).
-		if bi.ActiveDescriptor.TxnScheduler.Algorithm != t.scheduler.Name() {
-			t.logger.Error("attempted to update transaction scheduler algorithm",
+		if algo := bi.ActiveDescriptor.TxnScheduler.Algorithm; algo != t.scheduler.Name() {
+			// The scheduling algorithm has been changed, recreate the scheduler and move over
+			// any pending transactions.
+			t.logger.Info("updating transaction scheduler algorithm",
 				"current", t.scheduler.Name(),
-				"new", bi.ActiveDescriptor.TxnScheduler.Algorithm,
+				"new", algo,
 			)
+
+			sched, err := scheduling.New(t.cfg.MaxPoolSize, t.cfg.MaxTxsPerSender, algo, t.roundWeightLimits)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create transaction scheduler: %w", err)
+			}
+			// Make sure evictions not yet taken from the current scheduler are not lost.
+			evicted = append(evicted, t.takeEvictedLocked()...)
+			for _, tx := range t.scheduler.GetTransactions(0) {
+				if err = sched.QueueTx(tx); err != nil {
+					t.logger.Warn("failed to move transaction to new scheduler",
+						"err", err,
+						"tx", tx,
+					)
+					evicted = append(evicted, tx.Hash())
+					t.untrackTxLocked(tx.Hash())
+				}
+			}
+			t.scheduler = sched
+			evicted = append(evicted, t.takeEvictedLocked()...)
+			break
 		}
 
 		// Update parameters.
@@ -362,7 +631,7 @@ func (t *txPool) updateScheduler(bi *BlockInfo) error {
 	// Reset ticker to the new interval.
 	t.schedulerTicker.Reset(bi.ActiveDescriptor.TxnScheduler.BatchFlushTimeout)
 
-	return nil
+	return evicted, nil
 }
 
 func (t *txPool) UpdateWeightLimits(limits map[transaction.Weight]uint64) error {
@@ -403,21 +672,39 @@ func (t *txPool) WakeupScheduler() {
 }
 
 func (t *txPool) Clear() {
-	t.schedulerLock.Lock()
-	defer t.schedulerLock.Unlock()
+	round := t.getCurrentRound()
 
+	t.schedulerLock.Lock()
+	var evicted []hash.Hash
 	if t.scheduler != nil {
 		// Before clearing, move some transactions to the stale cache.
-		txs := t.scheduler.GetTransactions(int(t.cfg.MaxStaleCacheSize))
-		for _, tx := range txs {
-			_ = t.staleCache.Put(tx.Hash(), tx)
+		txs := t.scheduler.GetTransactions(0)
+		for i, tx := range txs {
+			if uint64(i) < t.cfg.MaxStaleCacheSize {
+				_ = t.staleCache.Put(tx.Hash(), tx)
+			}
+			evicted = append(evicted, tx.Hash())
 		}
 
 		t.scheduler.Clear()
 	}
+	t.deadlines.clear()
+	t.scheduledRounds = make(map[hash.Hash]uint64)
 	t.seenCache.Clear()
+	t.clearProposedTxs()
 
 	pendingScheduleSize.With(t.getMetricLabels()).Set(0)
+	t.schedulerLock.Unlock()
+
+	// All journaled transactions have been dropped.
+	if t.journal != nil {
+		if err := t.journal.clear(); err != nil {
+			t.logger.Warn("failed to clear transaction journal",
+				"err", err,
+			)
+		}
+	}
+	t.emitTxEvents(evicted, TxStatusEvicted, round)
 }
 
 func (t *txPool) WatchScheduler() (pubsub.ClosableSubscription, <-chan bool) {
@@ -434,6 +721,56 @@ func (t *txPool) WatchCheckedTransactions() (pubsub.ClosableSubscription, <-chan
 	return sub, ch
 }
 
+func (t *txPool) WatchTxEvents() (pubsub.ClosableSubscription, <-chan []*TxEvent) {
+	sub := t.txEventNotifier.Subscribe()
+	ch := make(chan []*TxEvent)
+	sub.Unwrap(ch)
+	return sub, ch
+}
+
+func (t *txPool) emitTxEvents(txs []hash.Hash, status TxStatus, round uint64) {
+	if len(txs) == 0 {
+		return
+	}
+
+	events := make([]*TxEvent, 0, len(txs))
+	for _, txHash := range txs {
+		events = append(events, &TxEvent{
+			Hash:   txHash,
+			Status: status,
+			Round:  round,
+		})
+	}
+	t.txEventNotifier.Broadcast(events)
+}
+
+// evictExpired evicts all transactions that have expired as of the given round.
+func (t *txPool) evictExpired(round uint64) {
+	t.schedulerLock.Lock()
+	if t.scheduler == nil {
+		t.schedulerLock.Unlock()
+		return
+	}
+
+	expired := t.deadlines.removeExpired(round, time.Now())
+	if len(expired) > 0 {
+		t.removeTxBatchLocked(expired)
+	}
+	t.schedulerLock.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+	t.removeFromJournal(expired)
+
+	t.logger.Debug("evicted expired transactions",
+		"num_txs", len(expired),
+		"round", round,
+	)
+	evictedTxCount.With(t.getMetricLabels()).Add(float64(len(expired)))
+	t.emitTxEvents(expired, TxStatusExpired, round)
+}
+
 func (t *txPool) PendingCheckSize() uint64 {
 	return t.checkTxQueue.Size()
 }
@@ -448,6 +785,17 @@ func (t *txPool) PendingScheduleSize() uint64 {
 	return t.scheduler.UnscheduledSize()
 }
 
+// getCurrentRound returns the round of the last known runtime block (zero if not yet known).
+func (t *txPool) getCurrentRound() uint64 {
+	t.blockInfoLock.Lock()
+	defer t.blockInfoLock.Unlock()
+
+	if t.blockInfo == nil {
+		return 0
+	}
+	return t.blockInfo.RuntimeBlock.Header.Round
+}
+
 func (t *txPool) getCurrentBlockInfo() (*BlockInfo, error) {
 	t.blockInfoLock.Lock()
 	defer t.blockInfoLock.Unlock()
@@ -492,8 +840,10 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 	pendingCheckSize.With(t.getMetricLabels()).Set(float64(t.PendingCheckSize()))
 
 	txs := make([]*transaction.CheckedTransaction, 0, len(results))
+	metas := make([]*protocol.CheckTxMetadata, 0, len(results))
 	isLocal := make([]bool, 0, len(results))
-	var unschedule []hash.Hash
+	journaled := make([]*journalEntry, 0, len(results))
+	var unschedule, rejected []hash.Hash
 	for i, res := range results {
 		// Send back the result of running the checks.
 		if batch[i].NotifyCh != nil {
@@ -509,8 +859,11 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 			)
 
 			// If this was a recheck, make sure to remove the transaction from the scheduling queue.
+			// Otherwise make sure that a transaction replayed from the journal is removed.
 			if batch[i].Meta.Recheck {
 				unschedule = append(unschedule, batch[i].TxHash)
+			} else {
+				rejected = append(rejected, batch[i].TxHash)
 			}
 			continue
 		}
@@ -520,11 +873,14 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 		}
 
 		txs = append(txs, res.ToCheckedTransaction(rawTxBatch[i]))
+		metas = append(metas, res.Meta)
 		isLocal = append(isLocal, batch[i].Meta.Local)
+		journaled = append(journaled, batch[i].Meta.journaled)
 	}
 
 	// Unschedule any transactions that are being rechecked and have failed checks.
-	t.RemoveTxBatch(unschedule)
+	t.removeTxBatch(unschedule, TxStatusRejected)
+	t.removeFromJournal(rejected)
 
 	if len(txs) == 0 {
 		return
@@ -535,6 +891,15 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 	)
 
 	// Queue checked transactions for scheduling.
+	var (
+		accepted        [][]byte
+		acceptedLocal   []bool
+		acceptedHashes  []hash.Hash
+		publishedHashes []hash.Hash
+	)
+	round := bi.RuntimeBlock.Header.Round
+	now := time.Now()
+	var evicted []hash.Hash
 	for i, tx := range txs {
 		t.schedulerLock.Lock()
 		// NOTE: Scheduler exists as otherwise there would be no current block info above.
@@ -543,8 +908,25 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 			t.logger.Error("unable to schedule transaction", "tx", tx)
 			continue
 		}
+		// Transactions replayed from the journal keep their original deadline.
+		acceptedRound, acceptedTime := round, now
+		if entry := journaled[i]; entry != nil {
+			if entry.Round != 0 {
+				acceptedRound = entry.Round
+			}
+			acceptedTime = time.Unix(entry.Timestamp, 0)
+		}
+		if deadline := newTxDeadline(t.cfg, metas[i], acceptedRound, acceptedTime); deadline != nil {
+			t.deadlines.add(tx.Hash(), deadline)
+		}
+		// Queuing the transaction may have caused other transactions to be evicted.
+		evicted = append(evicted, t.takeEvictedLocked()...)
 		t.schedulerLock.Unlock()
 
+		accepted = append(accepted, tx.Raw())
+		acceptedHashes = append(acceptedHashes, tx.Hash())
+		acceptedLocal = append(acceptedLocal, isLocal[i])
+
 		// Publish local transactions immediately.
 		publishTime := time.Now()
 		if isLocal[i] {
@@ -557,6 +939,8 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 				// Since publication failed, make sure we retry early.
 				t.republishCh.In() <- struct{}{}
 				publishTime = time.Time{}
+			} else {
+				publishedHashes = append(publishedHashes, tx.Hash())
 			}
 		}
 
@@ -565,6 +949,14 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 		_ = t.seenCache.Put(tx.Hash(), publishTime)
 	}
 
+	// Record accepted transactions so they survive restarts.
+	t.addToJournal(accepted, acceptedLocal, round)
+	t.emitTxEvents(acceptedHashes, TxStatusAccepted, round)
+	t.emitTxEvents(publishedHashes, TxStatusPublished, round)
+	// NOTE: Evictions are handled after the journal is updated as transactions from this batch
+	//       may have already been evicted.
+	t.handleEvicted(evicted, round)
+
 	// Notify subscribers that we have received new transactions.
 	t.checkTxNotifier.Broadcast(txs)
 	t.schedulerNotifier.Broadcast(false)
@@ -572,6 +964,82 @@ func (t *txPool) checkTxBatch(ctx context.Context, rr host.RichRuntime) {
 	pendingScheduleSize.With(t.getMetricLabels()).Set(float64(t.PendingScheduleSize()))
 }
 
+// replayJournal submits all journaled transactions for checks.
+func (t *txPool) replayJournal(ctx context.Context) {
+	if t.journal == nil {
+		return
+	}
+
+	t.pruneJournal()
+	entries, err := t.journal.entries()
+	if err != nil {
+		t.logger.Error("failed to load transaction journal",
+			"err", err,
+		)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	t.logger.Info("replaying journaled transactions",
+		"num_txs", len(entries),
+	)
+
+	for _, entry := range entries {
+		if err = t.submitTx(ctx, entry.Tx, &TransactionMeta{Local: entry.Local, journaled: entry}, nil); err != nil {
+			t.logger.Warn("failed to submit journaled transaction",
+				"err", err,
+			)
+			// The transaction will not make it into the pool, so make sure it is not replayed.
+			t.removeFromJournal([]hash.Hash{hash.NewFromBytes(entry.Tx)})
+		}
+	}
+}
+
+func (t *txPool) addToJournal(txs [][]byte, local []bool, round uint64) {
+	if t.journal == nil || len(txs) == 0 {
+		return
+	}
+
+	if err := t.journal.add(txs, local, round); err != nil {
+		t.logger.Warn("failed to add transactions to journal",
+			"err", err,
+		)
+	}
+}
+
+func (t *txPool) removeFromJournal(txs []hash.Hash) {
+	if t.journal == nil {
+		return
+	}
+
+	if err := t.journal.remove(txs); err != nil {
+		t.logger.Warn("failed to remove transactions from journal",
+			"err", err,
+		)
+	}
+}
+
+func (t *txPool) pruneJournal() {
+	if t.journal == nil || t.cfg.JournalMaxAge == 0 {
+		return
+	}
+
+	pruned, err := t.journal.pruneExpired(t.cfg.JournalMaxAge)
+	if err != nil {
+		t.logger.Warn("failed to prune transaction journal",
+			"err", err,
+		)
+		return
+	}
+	if pruned > 0 {
+		t.logger.Debug("pruned expired transactions from journal",
+			"num_txs", pruned,
+		)
+	}
+}
+
 func (t *txPool) ensureInitialized() error {
 	select {
 	case <-t.stopCh:
@@ -606,6 +1074,9 @@ func (t *txPool) checkWorker() {
 		return
 	}
 
+	// Replay any journaled transactions from before a restart.
+	t.replayJournal(ctx)
+
 	for {
 		select {
 		case <-t.stopCh:
@@ -786,6 +1257,27 @@ func (t *txPool) flushWorker() {
 	}
 }
 
+func (t *txPool) expiryWorker() {
+	// Wait for initialization to make sure that we have the scheduler available.
+	if err := t.ensureInitialized(); err != nil {
+		return
+	}
+
+	// Round-based deadlines are handled when processing blocks, but time-based deadlines must be
+	// enforced even when no new blocks are being produced.
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.evictExpired(t.getCurrentRound())
+		}
+	}
+}
+
 // New creates a new transaction pool instance.
 func New(
 	runtimeID common.Namespace,
@@ -805,6 +1297,13 @@ func New(
 		return nil, fmt.Errorf("error creating stale cache: %w", err)
 	}
 
+	var jrnl *journal
+	if cfg.JournalDir != "" {
+		if jrnl, err = openJournal(filepath.Join(cfg.JournalDir, JournalFilename), runtimeID); err != nil {
+			return nil, fmt.Errorf("error opening transaction journal: %w", err)
+		}
+	}
+
 	return &txPool{
 		logger:            logging.GetLogger("runtime/txpool"),
 		stopCh:            make(chan struct{}),
@@ -816,6 +1315,7 @@ func New(
 		txPublisher:       txPublisher,
 		seenCache:         seenCache,
 		staleCache:        staleCache,
+		proposedTxs:       make(map[hash.Hash]*transaction.CheckedTransaction),
 		checkTxQueue:      newCheckTxQueue(cfg.MaxPoolSize, cfg.MaxCheckTxBatchSize),
 		checkTxCh:         channels.NewRingChannel(1),
 		checkTxNotifier:   pubsub.NewBroker(false),
@@ -825,5 +1325,9 @@ func New(
 		epoCh:             channels.NewRingChannel(1),
 		republishCh:       channels.NewRingChannel(1),
 		roundWeightLimits: make(map[transaction.Weight]uint64),
+		deadlines:         newTxDeadlines(),
+		scheduledRounds:   make(map[hash.Hash]uint64),
+		txEventNotifier:   pubsub.NewBroker(false),
+		journal:           jrnl,
 	}, nil
 }
@@ -0,0 +1,368 @@
+package txpool
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+const testEventTimeout = 5 * time.Second
+
+var testRuntimeID = common.NewTestNamespaceFromSeed([]byte("txpool test"), 0)
+
+// testRuntime is a hosted runtime that accepts all transactions that are not configured to fail and
+// returns the configured check metadata.
+type testRuntime struct {
+	host.RichRuntime
+
+	sync.Mutex
+	meta map[string]*protocol.CheckTxMetadata
+	fail map[string]bool
+}
+
+func (r *testRuntime) setFail(tx []byte) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.fail[string(tx)] = true
+}
+
+func (r *testRuntime) setMeta(tx []byte, meta *protocol.CheckTxMetadata) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.meta[string(tx)] = meta
+}
+
+func (r *testRuntime) CheckTx(
+	ctx context.Context,
+	rb *block.Block,
+	lb *consensus.LightBlock,
+	epoch beacon.EpochTime,
+	maxMessages uint32,
+	batch transaction.RawBatch,
+) ([]protocol.CheckTxResult, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	results := make([]protocol.CheckTxResult, 0, len(batch))
+	for _, tx := range batch {
+		if r.fail[string(tx)] {
+			results = append(results, protocol.CheckTxResult{Error: protocol.Error{Module: "test", Code: 1}})
+			continue
+		}
+		results = append(results, protocol.CheckTxResult{Meta: r.meta[string(tx)]})
+	}
+	return results, nil
+}
+
+func (r *testRuntime) WaitHostedRuntime(ctx context.Context) (host.RichRuntime, error) {
+	return r, nil
+}
+
+type testPublisher struct{}
+
+func (p *testPublisher) PublishTx(ctx context.Context, tx []byte) error {
+	return nil
+}
+
+func (p *testPublisher) GetMinRepublishInterval() time.Duration {
+	return time.Hour
+}
+
+func newTestTxPool(t *testing.T, cfg *Config) (*txPool, *testRuntime) {
+	cfg.MaxPoolSize = 100
+	cfg.MaxCheckTxBatchSize = 100
+	cfg.MaxLastSeenCacheSize = 100
+	cfg.MaxStaleCacheSize = 100
+	cfg.RepublishInterval = time.Hour
+	cfg.RecheckInterval = 1000
+
+	rt := &testRuntime{
+		meta: make(map[string]*protocol.CheckTxMetadata),
+		fail: make(map[string]bool),
+	}
+	tp, err := New(testRuntimeID, cfg, rt, &testPublisher{})
+	require.NoError(t, err, "New")
+	require.NoError(t, tp.Start(), "Start")
+
+	return tp.(*txPool), rt
+}
+
+func processBlock(t *testing.T, tp *txPool, round uint64) {
+	processBlockWithType(t, tp, round, block.Normal)
+}
+
+func processBlockWithType(t *testing.T, tp *txPool, round uint64, headerType block.HeaderType) {
+	doProcessBlock(t, tp, round, headerType, registry.TxnSchedulerSimple)
+}
+
+func doProcessBlock(t *testing.T, tp *txPool, round uint64, headerType block.HeaderType, algorithm string) {
+	blk := block.NewGenesisBlock(testRuntimeID, 0)
+	blk.Header.Round = round
+	blk.Header.HeaderType = headerType
+
+	err := tp.ProcessBlock(&BlockInfo{
+		RuntimeBlock:   blk,
+		ConsensusBlock: &consensus.LightBlock{},
+		ActiveDescriptor: &registry.Runtime{
+			TxnScheduler: registry.TxnSchedulerParameters{
+				Algorithm:         algorithm,
+				BatchFlushTimeout: time.Hour,
+				MaxBatchSize:      10,
+				MaxBatchSizeBytes: 1024,
+			},
+		},
+	})
+	require.NoError(t, err, "ProcessBlock")
+}
+
+func submitTx(t *testing.T, tp *txPool, tx []byte) {
+	result, err := tp.SubmitTx(context.Background(), tx, &TransactionMeta{Local: true})
+	require.NoError(t, err, "SubmitTx")
+	require.True(t, result.IsSuccess(), "transaction should pass checks")
+}
+
+// requireTxEvent waits for an event with the given status for the given transaction, skipping any
+// other events.
+func requireTxEvent(t *testing.T, ch <-chan []*TxEvent, tx []byte, status TxStatus) *TxEvent {
+	txHash := hash.NewFromBytes(tx)
+	timeout := time.After(testEventTimeout)
+	for {
+		select {
+		case events := <-ch:
+			for _, ev := range events {
+				if ev.Hash.Equal(&txHash) && ev.Status == status {
+					return ev
+				}
+			}
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for transaction event", "tx: %s status: %s", tx, status)
+		}
+	}
+}
+
+// requireNoTxEvent makes sure that no event with the given status is emitted for the given
+// transaction within a short time.
+func requireNoTxEvent(t *testing.T, ch <-chan []*TxEvent, tx []byte, status TxStatus) {
+	txHash := hash.NewFromBytes(tx)
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case events := <-ch:
+			for _, ev := range events {
+				if ev.Hash.Equal(&txHash) && ev.Status == status {
+					require.FailNow(t, "unexpected transaction event", "tx: %s status: %s", tx, status)
+				}
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestTxPoolEvents(t *testing.T) {
+	require := require.New(t)
+
+	tp, _ := newTestTxPool(t, &Config{})
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	processBlock(t, tp, 10)
+
+	tx := []byte("tx")
+	submitTx(t, tp, tx)
+	ev := requireTxEvent(t, ch, tx, TxStatusAccepted)
+	require.EqualValues(10, ev.Round, "accepted event should be for the latest round")
+	requireTxEvent(t, ch, tx, TxStatusPublished)
+
+	// Scheduled events should only be emitted once per round.
+	batch := tp.GetScheduledBatch(true)
+	require.Len(batch, 1, "transaction should be scheduled")
+	ev = requireTxEvent(t, ch, tx, TxStatusScheduled)
+	require.EqualValues(11, ev.Round, "scheduled event should be for the next round")
+	_ = tp.GetScheduledBatch(true)
+	requireNoTxEvent(t, ch, tx, TxStatusScheduled)
+
+	// The transaction can be scheduled again in the next round.
+	processBlock(t, tp, 11)
+	_ = tp.GetScheduledBatch(true)
+	ev = requireTxEvent(t, ch, tx, TxStatusScheduled)
+	require.EqualValues(12, ev.Round, "scheduled event should be for the next round")
+
+	tp.RemoveTxBatch([]hash.Hash{hash.NewFromBytes(tx)})
+	ev = requireTxEvent(t, ch, tx, TxStatusIncluded)
+	require.EqualValues(11, ev.Round, "included event should be for the latest round")
+	require.EqualValues(0, tp.PendingScheduleSize(), "included transaction should be removed")
+}
+
+func TestTxPoolExpiry(t *testing.T) {
+	require := require.New(t)
+
+	tp, rt := newTestTxPool(t, &Config{
+		MaxLifetimeRounds: 5,
+	})
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	processBlock(t, tp, 10)
+
+	// Transaction expiring based on the configured round lifetime.
+	txRound := []byte("round")
+	// Transaction expiring earlier based on the runtime-provided round.
+	txRuntimeRound := []byte("runtime round")
+	rt.setMeta(txRuntimeRound, &protocol.CheckTxMetadata{ExpiryRound: 12})
+	// Transaction expiring based on the runtime-provided time.
+	txTime := []byte("time")
+	rt.setMeta(txTime, &protocol.CheckTxMetadata{ExpiryTime: uint64(time.Now().Add(time.Second).Unix())})
+
+	for _, tx := range [][]byte{txRound, txRuntimeRound, txTime} {
+		submitTx(t, tp, tx)
+		requireTxEvent(t, ch, tx, TxStatusAccepted)
+	}
+	require.EqualValues(3, tp.PendingScheduleSize())
+
+	// Time-based deadlines should be enforced even without new blocks.
+	ev := requireTxEvent(t, ch, txTime, TxStatusExpired)
+	require.EqualValues(10, ev.Round, "evicted event should be for the latest round")
+	require.EqualValues(2, tp.PendingScheduleSize())
+
+	processBlock(t, tp, 11)
+	requireNoTxEvent(t, ch, txRuntimeRound, TxStatusExpired)
+
+	processBlock(t, tp, 12)
+	requireTxEvent(t, ch, txRuntimeRound, TxStatusExpired)
+	require.EqualValues(1, tp.PendingScheduleSize())
+
+	processBlock(t, tp, 15)
+	requireTxEvent(t, ch, txRound, TxStatusExpired)
+	require.EqualValues(0, tp.PendingScheduleSize())
+}
+
+func TestTxPoolRecheck(t *testing.T) {
+	require := require.New(t)
+
+	tp, rt := newTestTxPool(t, &Config{})
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	processBlock(t, tp, 10)
+
+	txValid := []byte("valid")
+	txInvalid := []byte("invalid")
+	for _, tx := range [][]byte{txValid, txInvalid} {
+		submitTx(t, tp, tx)
+		requireTxEvent(t, ch, tx, TxStatusAccepted)
+	}
+	require.True(tp.IsPending(hash.NewFromBytes(txInvalid)), "transaction should be pending")
+
+	// Transactions failing a recheck should be reported as rejected.
+	rt.setFail(txInvalid)
+	processBlockWithType(t, tp, 11, block.EpochTransition)
+	requireTxEvent(t, ch, txInvalid, TxStatusRejected)
+	require.False(tp.IsPending(hash.NewFromBytes(txInvalid)), "rejected transaction should not be pending")
+	require.True(tp.IsPending(hash.NewFromBytes(txValid)), "valid transaction should remain pending")
+}
+
+func TestTxPoolSchedulerChange(t *testing.T) {
+	require := require.New(t)
+
+	tp, rt := newTestTxPool(t, &Config{
+		MaxTxsPerSender:   1,
+		MaxLifetimeRounds: 100,
+	})
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	processBlock(t, tp, 10)
+
+	// The simple scheduler does not enforce the per-sender quota.
+	tx1 := []byte("tx1")
+	rt.setMeta(tx1, &protocol.CheckTxMetadata{Sender: []byte("sender"), SenderSeq: 0})
+	tx2 := []byte("tx2")
+	rt.setMeta(tx2, &protocol.CheckTxMetadata{Sender: []byte("sender"), SenderSeq: 1})
+	for _, tx := range [][]byte{tx1, tx2} {
+		submitTx(t, tp, tx)
+		requireTxEvent(t, ch, tx, TxStatusAccepted)
+	}
+	require.EqualValues(2, tp.PendingScheduleSize())
+
+	// Transactions that can not be moved over to the new scheduler should be evicted.
+	doProcessBlock(t, tp, 11, block.EpochTransition, registry.TxnSchedulerOrdered)
+	requireTxEvent(t, ch, tx2, TxStatusEvicted)
+	require.True(tp.IsPending(hash.NewFromBytes(tx1)), "moved transaction should remain pending")
+	require.False(tp.IsPending(hash.NewFromBytes(tx2)), "evicted transaction should not be pending")
+
+	tp.schedulerLock.Lock()
+	deadline := tp.deadlines.get(hash.NewFromBytes(tx2))
+	tp.schedulerLock.Unlock()
+	require.Nil(deadline, "evicted transaction should no longer be tracked")
+}
+
+func TestTxPoolJournalReplay(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-txpool-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	cfg := &Config{
+		MaxLifetimeRounds: 5,
+		JournalDir:        dir,
+	}
+
+	tx := []byte("tx")
+	txHash := hash.NewFromBytes(tx)
+	func() {
+		tp, _ := newTestTxPool(t, cfg)
+		defer tp.Stop()
+		sub, ch := tp.WatchTxEvents()
+		defer sub.Close()
+
+		processBlock(t, tp, 10)
+		submitTx(t, tp, tx)
+		// Transactions are journaled before being reported as accepted.
+		requireTxEvent(t, ch, tx, TxStatusAccepted)
+	}()
+
+	// After a restart the transaction should be replayed and keep its original deadline.
+	tp, _ := newTestTxPool(t, cfg)
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	processBlock(t, tp, 13)
+	requireTxEvent(t, ch, tx, TxStatusAccepted)
+
+	tp.schedulerLock.Lock()
+	deadline := tp.deadlines.get(txHash)
+	tp.schedulerLock.Unlock()
+	require.NotNil(deadline, "replayed transaction should have a deadline")
+	require.EqualValues(15, deadline.round, "replayed transaction should keep its original deadline")
+
+	processBlock(t, tp, 15)
+	requireTxEvent(t, ch, tx, TxStatusExpired)
+
+	entries, err := tp.journal.entries()
+	require.NoError(err, "entries")
+	require.Empty(entries, "evicted transaction should be removed from the journal")
+}
@@ -288,6 +288,66 @@ type ConsensusParameters struct {
 	DebugAllowWeakAlpha bool `json:"debug_allow_weak_alpha,omitempty"`
 }
 
+// ConsensusParameterChanges are allowed scheduler consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// MinValidators is the new minimum number of validators.
+	MinValidators *int `json:"min_validators,omitempty"`
+
+	// MaxValidators is the new maximum number of validators.
+	MaxValidators *int `json:"max_validators,omitempty"`
+
+	// MaxValidatorsPerEntity is the new maximum number of validators per entity.
+	MaxValidatorsPerEntity *int `json:"max_validators_per_entity,omitempty"`
+
+	// RewardFactorEpochElectionAny is the new epoch election reward factor.
+	RewardFactorEpochElectionAny *quantity.Quantity `json:"reward_factor_epoch_election_any,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.MinValidators == nil &&
+		c.MaxValidators == nil &&
+		c.MaxValidatorsPerEntity == nil &&
+		c.RewardFactorEpochElectionAny == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.MinValidators != nil {
+		params.MinValidators = *c.MinValidators
+	}
+	if c.MaxValidators != nil {
+		params.MaxValidators = *c.MaxValidators
+	}
+	if c.MaxValidatorsPerEntity != nil {
+		params.MaxValidatorsPerEntity = *c.MaxValidatorsPerEntity
+	}
+	if c.RewardFactorEpochElectionAny != nil {
+		params.RewardFactorEpochElectionAny = *c.RewardFactorEpochElectionAny
+	}
+	return nil
+}
+
+// SanityCheck performs a sanity check on the consensus parameters.
+func (p *ConsensusParameters) SanityCheck() error {
+	if p.MinValidators <= 0 {
+		return fmt.Errorf("minimum number of validators must be positive")
+	}
+	if p.MaxValidators < p.MinValidators {
+		return fmt.Errorf("maximum number of validators must not be less than the minimum")
+	}
+	if p.MaxValidatorsPerEntity <= 0 {
+		return fmt.Errorf("maximum number of validators per entity must be positive")
+	}
+	if !p.RewardFactorEpochElectionAny.IsValid() {
+		return fmt.Errorf("reward factor epoch election any has invalid value")
+	}
+	return nil
+}
+
 // ForceElectCommitteeRole is the committee kind/role that a force-elected
 // node is elected as.
 type ForceElectCommitteeRole struct {
@@ -77,6 +77,10 @@ var (
 	// consensus parameters.
 	ErrUnderMinDelegationAmount = errors.New(ModuleName, 8, "staking: amount is lower than the minimum delegation amount")
 
+	// ErrBalanceLocked is the error returned when an operation would
+	// spend funds that are still locked by the account's vesting schedule.
+	ErrBalanceLocked = errors.New(ModuleName, 9, "staking: balance is locked by vesting schedule")
+
 	// MethodTransfer is the method name for transfers.
 	MethodTransfer = transaction.NewMethodName(ModuleName, "Transfer", Transfer{})
 	// MethodBurn is the method name for burns.
@@ -351,6 +355,11 @@ func (t Transfer) PrettyType() (interface{}, error) {
 	return t, nil
 }
 
+// MethodMetadata returns the metadata of the Transfer method.
+func (t Transfer) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewTransferTx creates a new transfer transaction.
 func NewTransferTx(nonce uint64, fee *transaction.Fee, xfer *Transfer) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodTransfer, xfer)
@@ -375,6 +384,11 @@ func (b Burn) PrettyType() (interface{}, error) {
 	return b, nil
 }
 
+// MethodMetadata returns the metadata of the Burn method.
+func (b Burn) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewBurnTx creates a new burn transaction.
 func NewBurnTx(nonce uint64, fee *transaction.Fee, burn *Burn) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodBurn, burn)
@@ -402,6 +416,11 @@ func (e Escrow) PrettyType() (interface{}, error) {
 	return e, nil
 }
 
+// MethodMetadata returns the metadata of the AddEscrow method.
+func (e Escrow) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewAddEscrowTx creates a new add escrow transaction.
 func NewAddEscrowTx(nonce uint64, fee *transaction.Fee, escrow *Escrow) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodAddEscrow, escrow)
@@ -427,6 +446,11 @@ func (re ReclaimEscrow) PrettyType() (interface{}, error) {
 	return re, nil
 }
 
+// MethodMetadata returns the metadata of the ReclaimEscrow method.
+func (re ReclaimEscrow) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewReclaimEscrowTx creates a new reclaim escrow transaction.
 func NewReclaimEscrowTx(nonce uint64, fee *transaction.Fee, reclaim *ReclaimEscrow) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodReclaimEscrow, reclaim)
@@ -450,6 +474,11 @@ func (acs AmendCommissionSchedule) PrettyType() (interface{}, error) {
 	return acs, nil
 }
 
+// MethodMetadata returns the metadata of the AmendCommissionSchedule method.
+func (acs AmendCommissionSchedule) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewAmendCommissionScheduleTx creates a new amend commission schedule transaction.
 func NewAmendCommissionScheduleTx(nonce uint64, fee *transaction.Fee, amend *AmendCommissionSchedule) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodAmendCommissionSchedule, amend)
@@ -481,6 +510,11 @@ func (aw Allow) PrettyType() (interface{}, error) {
 	return aw, nil
 }
 
+// MethodMetadata returns the metadata of the Allow method.
+func (aw Allow) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewAllowTx creates a new beneficiary allowance configuration transaction.
 func NewAllowTx(nonce uint64, fee *transaction.Fee, allow *Allow) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodAllow, allow)
@@ -506,6 +540,11 @@ func (wt Withdraw) PrettyType() (interface{}, error) {
 	return wt, nil
 }
 
+// MethodMetadata returns the metadata of the Withdraw method.
+func (wt Withdraw) MethodMetadata() transaction.MethodMetadata {
+	return transaction.MethodMetadata{SupportsMultisig: true}
+}
+
 // NewWithdrawTx creates a new beneficiary allowance configuration transaction.
 func NewWithdrawTx(nonce uint64, fee *transaction.Fee, withdraw *Withdraw) *transaction.Transaction {
 	return transaction.NewTransaction(nonce, fee, MethodWithdraw, withdraw)
@@ -879,6 +918,60 @@ type GeneralAccount struct {
 	Nonce   uint64            `json:"nonce,omitempty"`
 
 	Allowances map[Address]quantity.Quantity `json:"allowances,omitempty"`
+
+	// Vesting is an optional vesting schedule which locks part of the balance.
+	Vesting *VestingSchedule `json:"vesting,omitempty"`
+}
+
+// CheckSpendable checks whether the given amount can be spent from the
+// general balance at the given epoch without touching funds that are still
+// locked by the vesting schedule.
+func (ga *GeneralAccount) CheckSpendable(epoch beacon.EpochTime, amount *quantity.Quantity) error {
+	if ga.Balance.Cmp(amount) < 0 {
+		return ErrInsufficientBalance
+	}
+	if ga.Vesting == nil || amount.IsZero() {
+		return nil
+	}
+
+	remaining := ga.Balance.Clone()
+	_ = remaining.Sub(amount)
+	if remaining.Cmp(ga.Vesting.LockedBalance(epoch)) < 0 {
+		return ErrBalanceLocked
+	}
+	return nil
+}
+
+// AddEscrowedLocked records that the given amount is being escrowed from the general balance at
+// the given epoch. Any part of the amount that comes from funds locked by the vesting schedule is
+// tracked so that it no longer locks the general balance.
+//
+// This must be called before the amount is removed from the general balance.
+func (ga *GeneralAccount) AddEscrowedLocked(epoch beacon.EpochTime, amount *quantity.Quantity) error {
+	if ga.Vesting == nil {
+		return nil
+	}
+
+	// Unlocked funds are escrowed first.
+	unlocked := ga.Balance.Clone()
+	if err := unlocked.Sub(ga.Vesting.LockedBalance(epoch)); err != nil {
+		unlocked = quantity.NewQuantity()
+	}
+	if amount.Cmp(unlocked) <= 0 {
+		return nil
+	}
+	locked := amount.Clone()
+	_ = locked.Sub(unlocked)
+	return ga.Vesting.Escrowed.Add(locked)
+}
+
+// ReturnEscrowedLocked records that the given amount of debonded stake has been returned to the
+// general balance. Previously escrowed locked funds are considered to be returned first.
+func (ga *GeneralAccount) ReturnEscrowedLocked(amount *quantity.Quantity) {
+	if ga.Vesting == nil {
+		return
+	}
+	_, _ = ga.Vesting.Escrowed.SubUpTo(amount)
 }
 
 // PrettyPrint writes a pretty-printed representation of GeneralAccount to the
@@ -900,6 +993,11 @@ func (ga GeneralAccount) PrettyPrint(ctx context.Context, prefix string, w io.Wr
 			fmt.Fprintln(w)
 		}
 	}
+
+	if ga.Vesting != nil {
+		fmt.Fprintf(w, "%sVesting Schedule:\n", prefix)
+		ga.Vesting.PrettyPrint(ctx, prefix+"  ", w)
+	}
 }
 
 // PrettyType returns a representation of GeneralAccount that can be used for
@@ -1125,6 +1223,148 @@ type ConsensusParameters struct { // nolint: maligned
 	RewardFactorBlockProposed quantity.Quantity `json:"reward_factor_block_proposed"`
 }
 
+// ConsensusParameterChanges are allowed staking consensus parameter changes.
+type ConsensusParameterChanges struct {
+	// Thresholds are the new thresholds. If set, they replace all existing thresholds.
+	Thresholds map[ThresholdKind]quantity.Quantity `json:"thresholds,omitempty"`
+
+	// DebondingInterval is the new debonding interval.
+	DebondingInterval *beacon.EpochTime `json:"debonding_interval,omitempty"`
+
+	// RewardSchedule is the new reward schedule.
+	RewardSchedule []RewardStep `json:"reward_schedule,omitempty"`
+
+	// SigningRewardThresholdNumerator is the new signing reward threshold numerator.
+	SigningRewardThresholdNumerator *uint64 `json:"signing_reward_threshold_numerator,omitempty"`
+
+	// SigningRewardThresholdDenominator is the new signing reward threshold denominator.
+	SigningRewardThresholdDenominator *uint64 `json:"signing_reward_threshold_denominator,omitempty"`
+
+	// CommissionScheduleRules are the new commission schedule rules.
+	CommissionScheduleRules *CommissionScheduleRules `json:"commission_schedule_rules,omitempty"`
+
+	// Slashing are the new slashing parameters. If set, they replace all existing
+	// slashing parameters.
+	Slashing map[SlashReason]Slash `json:"slashing,omitempty"`
+
+	// GasCosts are the new gas costs. If set, they replace all existing gas costs.
+	GasCosts transaction.Costs `json:"gas_costs,omitempty"`
+
+	// MinDelegationAmount is the new minimum delegation amount.
+	MinDelegationAmount *quantity.Quantity `json:"min_delegation,omitempty"`
+
+	// DisableTransfers is the new disable transfers flag.
+	DisableTransfers *bool `json:"disable_transfers,omitempty"`
+
+	// DisableDelegation is the new disable delegation flag.
+	DisableDelegation *bool `json:"disable_delegation,omitempty"`
+
+	// AllowEscrowMessages is the new allow escrow messages flag.
+	AllowEscrowMessages *bool `json:"allow_escrow_messages,omitempty"`
+
+	// MaxAllowances is the new maximum number of allowances.
+	MaxAllowances *uint32 `json:"max_allowances,omitempty"`
+
+	// FeeSplitWeightPropose is the new propose fee split weight.
+	FeeSplitWeightPropose *quantity.Quantity `json:"fee_split_weight_propose,omitempty"`
+
+	// FeeSplitWeightVote is the new vote fee split weight.
+	FeeSplitWeightVote *quantity.Quantity `json:"fee_split_weight_vote,omitempty"`
+
+	// FeeSplitWeightNextPropose is the new next propose fee split weight.
+	FeeSplitWeightNextPropose *quantity.Quantity `json:"fee_split_weight_next_propose,omitempty"`
+
+	// RewardFactorEpochSigned is the new epoch signed reward factor.
+	RewardFactorEpochSigned *quantity.Quantity `json:"reward_factor_epoch_signed,omitempty"`
+
+	// RewardFactorBlockProposed is the new block proposed reward factor.
+	RewardFactorBlockProposed *quantity.Quantity `json:"reward_factor_block_proposed,omitempty"`
+}
+
+// SanityCheck performs a sanity check on the consensus parameter changes.
+func (c *ConsensusParameterChanges) SanityCheck() error {
+	if c.Thresholds == nil &&
+		c.DebondingInterval == nil &&
+		c.RewardSchedule == nil &&
+		c.SigningRewardThresholdNumerator == nil &&
+		c.SigningRewardThresholdDenominator == nil &&
+		c.CommissionScheduleRules == nil &&
+		c.Slashing == nil &&
+		c.GasCosts == nil &&
+		c.MinDelegationAmount == nil &&
+		c.DisableTransfers == nil &&
+		c.DisableDelegation == nil &&
+		c.AllowEscrowMessages == nil &&
+		c.MaxAllowances == nil &&
+		c.FeeSplitWeightPropose == nil &&
+		c.FeeSplitWeightVote == nil &&
+		c.FeeSplitWeightNextPropose == nil &&
+		c.RewardFactorEpochSigned == nil &&
+		c.RewardFactorBlockProposed == nil {
+		return fmt.Errorf("consensus parameter changes should not be empty")
+	}
+	return nil
+}
+
+// Apply applies changes to the given consensus parameters.
+func (c *ConsensusParameterChanges) Apply(params *ConsensusParameters) error {
+	if c.Thresholds != nil {
+		params.Thresholds = c.Thresholds
+	}
+	if c.DebondingInterval != nil {
+		params.DebondingInterval = *c.DebondingInterval
+	}
+	if c.RewardSchedule != nil {
+		params.RewardSchedule = c.RewardSchedule
+	}
+	if c.SigningRewardThresholdNumerator != nil {
+		params.SigningRewardThresholdNumerator = *c.SigningRewardThresholdNumerator
+	}
+	if c.SigningRewardThresholdDenominator != nil {
+		params.SigningRewardThresholdDenominator = *c.SigningRewardThresholdDenominator
+	}
+	if c.CommissionScheduleRules != nil {
+		params.CommissionScheduleRules = *c.CommissionScheduleRules
+	}
+	if c.Slashing != nil {
+		params.Slashing = c.Slashing
+	}
+	if c.GasCosts != nil {
+		params.GasCosts = c.GasCosts
+	}
+	if c.MinDelegationAmount != nil {
+		params.MinDelegationAmount = *c.MinDelegationAmount
+	}
+	if c.DisableTransfers != nil {
+		params.DisableTransfers = *c.DisableTransfers
+	}
+	if c.DisableDelegation != nil {
+		params.DisableDelegation = *c.DisableDelegation
+	}
+	if c.AllowEscrowMessages != nil {
+		params.AllowEscrowMessages = *c.AllowEscrowMessages
+	}
+	if c.MaxAllowances != nil {
+		params.MaxAllowances = *c.MaxAllowances
+	}
+	if c.FeeSplitWeightPropose != nil {
+		params.FeeSplitWeightPropose = *c.FeeSplitWeightPropose
+	}
+	if c.FeeSplitWeightVote != nil {
+		params.FeeSplitWeightVote = *c.FeeSplitWeightVote
+	}
+	if c.FeeSplitWeightNextPropose != nil {
+		params.FeeSplitWeightNextPropose = *c.FeeSplitWeightNextPropose
+	}
+	if c.RewardFactorEpochSigned != nil {
+		params.RewardFactorEpochSigned = *c.RewardFactorEpochSigned
+	}
+	if c.RewardFactorBlockProposed != nil {
+		params.RewardFactorBlockProposed = *c.RewardFactorBlockProposed
+	}
+	return nil
+}
+
 const (
 	// GasOpTransfer is the gas operation identifier for transfer.
 	GasOpTransfer transaction.Op = "transfer"
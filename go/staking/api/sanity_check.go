@@ -90,6 +90,12 @@ func SanityCheckAccount(
 		}
 	}
 
+	if acct.General.Vesting != nil {
+		if err := acct.General.Vesting.ValidateBasic(); err != nil {
+			return fmt.Errorf("staking: sanity check failed: vesting schedule for account %s is invalid: %w", addr, err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/prettyprint"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/staking/api/token"
+)
+
+var _ prettyprint.PrettyPrinter = (*VestingSchedule)(nil)
+
+// VestingSchedule is a vesting (lockup) schedule of a general account.
+//
+// None of the vesting amount is released before the cliff epoch. Starting with the cliff epoch,
+// the amount is released linearly (by epoch) over the period from the start epoch to the end
+// epoch, at which point the whole amount is released.
+//
+// Locked funds cannot be transferred, burned or withdrawn, but they can be escrowed. Locked funds
+// that are escrowed no longer lock the general balance until they are returned after debonding.
+type VestingSchedule struct {
+	// Amount is the total amount subject to the vesting schedule.
+	Amount quantity.Quantity `json:"amount,omitempty"`
+	// Start is the epoch at which the linear release starts accruing.
+	Start beacon.EpochTime `json:"start,omitempty"`
+	// Cliff is the epoch before which nothing is released.
+	Cliff beacon.EpochTime `json:"cliff,omitempty"`
+	// End is the epoch at which the whole amount is released.
+	End beacon.EpochTime `json:"end,omitempty"`
+
+	// Escrowed is the amount of locked funds that has been escrowed and not yet returned to the
+	// general balance.
+	Escrowed quantity.Quantity `json:"escrowed,omitempty"`
+}
+
+// ValidateBasic performs basic vesting schedule validity checks.
+func (vs *VestingSchedule) ValidateBasic() error {
+	if !vs.Amount.IsValid() || vs.Amount.IsZero() {
+		return fmt.Errorf("vesting amount must be positive")
+	}
+	if vs.End == beacon.EpochInvalid {
+		return fmt.Errorf("vesting end epoch is invalid")
+	}
+	if vs.Start > vs.Cliff {
+		return fmt.Errorf("vesting cliff epoch (%d) is before start epoch (%d)", vs.Cliff, vs.Start)
+	}
+	if vs.Cliff > vs.End {
+		return fmt.Errorf("vesting end epoch (%d) is before cliff epoch (%d)", vs.End, vs.Cliff)
+	}
+	if !vs.Escrowed.IsValid() || vs.Escrowed.Cmp(&vs.Amount) > 0 {
+		return fmt.Errorf("vesting escrowed amount exceeds the vesting amount")
+	}
+	return nil
+}
+
+// ReleasedAmount returns the amount released by the vesting schedule at the given epoch.
+func (vs *VestingSchedule) ReleasedAmount(epoch beacon.EpochTime) *quantity.Quantity {
+	switch {
+	case epoch < vs.Cliff:
+		return quantity.NewQuantity()
+	case epoch >= vs.End:
+		return vs.Amount.Clone()
+	}
+
+	// Start < Cliff <= epoch < End, so the division below is safe.
+	released := vs.Amount.Clone()
+	_ = released.Mul(quantity.NewFromUint64(uint64(epoch - vs.Start)))
+	_ = released.Quo(quantity.NewFromUint64(uint64(vs.End - vs.Start)))
+	return released
+}
+
+// LockedAmount returns the amount that is still locked by the vesting schedule at the given epoch.
+func (vs *VestingSchedule) LockedAmount(epoch beacon.EpochTime) *quantity.Quantity {
+	locked := vs.Amount.Clone()
+	_ = locked.Sub(vs.ReleasedAmount(epoch))
+	return locked
+}
+
+// LockedBalance returns the amount of the general balance that is still locked by the vesting
+// schedule at the given epoch, excluding any locked funds that are currently escrowed.
+func (vs *VestingSchedule) LockedBalance(epoch beacon.EpochTime) *quantity.Quantity {
+	locked := vs.LockedAmount(epoch)
+	if locked.Cmp(&vs.Escrowed) <= 0 {
+		return quantity.NewQuantity()
+	}
+	_ = locked.Sub(&vs.Escrowed)
+	return locked
+}
+
+// PrettyPrint writes a pretty-printed representation of VestingSchedule to the given writer.
+func (vs VestingSchedule) PrettyPrint(ctx context.Context, prefix string, w io.Writer) {
+	fmt.Fprintf(w, "%sAmount: ", prefix)
+	token.PrettyPrintAmount(ctx, vs.Amount, w)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%sStart:  epoch %d\n", prefix, vs.Start)
+	fmt.Fprintf(w, "%sCliff:  epoch %d\n", prefix, vs.Cliff)
+	fmt.Fprintf(w, "%sEnd:    epoch %d\n", prefix, vs.End)
+
+	fmt.Fprintf(w, "%sEscrowed: ", prefix)
+	token.PrettyPrintAmount(ctx, vs.Escrowed, w)
+	fmt.Fprintln(w)
+}
+
+// PrettyType returns a representation of VestingSchedule that can be used for pretty printing.
+func (vs VestingSchedule) PrettyType() (interface{}, error) {
+	return vs, nil
+}
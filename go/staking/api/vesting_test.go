@@ -0,0 +1,138 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+)
+
+func TestVestingSchedule(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		vs    VestingSchedule
+		valid bool
+		msg   string
+	}{
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 10, Cliff: 15, End: 20}, true, "valid schedule"},
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 10, Cliff: 10, End: 20}, true, "valid schedule without cliff"},
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 20, Cliff: 20, End: 20}, true, "valid lockup"},
+		{VestingSchedule{Start: 10, Cliff: 15, End: 20}, false, "zero amount"},
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 15, Cliff: 10, End: 20}, false, "cliff before start"},
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 10, Cliff: 20, End: 15}, false, "end before cliff"},
+		{VestingSchedule{Amount: *quantity.NewFromUint64(100), Start: 10, Cliff: 15, End: beacon.EpochInvalid}, false, "invalid end"},
+	} {
+		err := tc.vs.ValidateBasic()
+		switch tc.valid {
+		case true:
+			require.NoError(err, tc.msg)
+		case false:
+			require.Error(err, tc.msg)
+		}
+	}
+
+	vs := VestingSchedule{
+		Amount: *quantity.NewFromUint64(1000),
+		Start:  10,
+		Cliff:  15,
+		End:    20,
+	}
+	for _, tc := range []struct {
+		epoch  beacon.EpochTime
+		locked uint64
+	}{
+		{0, 1000},
+		{10, 1000},
+		{14, 1000},
+		{15, 500},
+		{16, 400},
+		{19, 100},
+		{20, 0},
+		{100, 0},
+	} {
+		require.Zero(vs.LockedAmount(tc.epoch).Cmp(quantity.NewFromUint64(tc.locked)), "locked amount at epoch %d", tc.epoch)
+		require.Zero(vs.ReleasedAmount(tc.epoch).Cmp(quantity.NewFromUint64(1000-tc.locked)), "released amount at epoch %d", tc.epoch)
+	}
+}
+
+func TestGeneralAccountCheckSpendable(t *testing.T) {
+	require := require.New(t)
+
+	ga := GeneralAccount{
+		Balance: *quantity.NewFromUint64(800),
+	}
+	require.NoError(ga.CheckSpendable(0, quantity.NewFromUint64(800)), "account without vesting schedule")
+	require.Equal(ErrInsufficientBalance, ga.CheckSpendable(0, quantity.NewFromUint64(801)))
+
+	// 200 of the locked funds have been escrowed.
+	ga.Vesting = &VestingSchedule{
+		Amount:   *quantity.NewFromUint64(1000),
+		Start:    10,
+		Cliff:    15,
+		End:      20,
+		Escrowed: *quantity.NewFromUint64(200),
+	}
+	// Nothing can be spent before the cliff.
+	require.Equal(ErrBalanceLocked, ga.CheckSpendable(10, quantity.NewFromUint64(1)))
+	require.NoError(ga.CheckSpendable(10, quantity.NewQuantity()))
+	// At epoch 16, 400 is still locked of which 200 is escrowed.
+	require.NoError(ga.CheckSpendable(16, quantity.NewFromUint64(600)))
+	require.Equal(ErrBalanceLocked, ga.CheckSpendable(16, quantity.NewFromUint64(601)))
+	// At epoch 18, all of the remaining locked funds are escrowed.
+	require.NoError(ga.CheckSpendable(18, quantity.NewFromUint64(800)))
+	require.Equal(ErrInsufficientBalance, ga.CheckSpendable(18, quantity.NewFromUint64(801)))
+	// Everything is released at the end epoch.
+	require.NoError(ga.CheckSpendable(20, quantity.NewFromUint64(800)))
+
+	// Funds received after all locked funds have been escrowed are not locked.
+	ga.Balance = *quantity.NewFromUint64(500)
+	ga.Vesting.Escrowed = *quantity.NewFromUint64(1000)
+	require.NoError(ga.CheckSpendable(10, quantity.NewFromUint64(500)))
+}
+
+func TestGeneralAccountEscrowedLocked(t *testing.T) {
+	require := require.New(t)
+
+	ga := GeneralAccount{
+		Balance: *quantity.NewFromUint64(1000),
+		Vesting: &VestingSchedule{
+			Amount: *quantity.NewFromUint64(1000),
+			Start:  10,
+			Cliff:  15,
+			End:    20,
+		},
+	}
+	escrow := func(amount uint64) {
+		q := quantity.NewFromUint64(amount)
+		require.NoError(ga.AddEscrowedLocked(18, q), "AddEscrowedLocked")
+		require.NoError(ga.Balance.Sub(q), "Sub")
+	}
+	requireEscrowed := func(amount uint64, msg string) {
+		require.Zero(ga.Vesting.Escrowed.Cmp(quantity.NewFromUint64(amount)), "%s (escrowed: %s)", msg, ga.Vesting.Escrowed)
+	}
+
+	// At epoch 18, 200 is still locked so unlocked funds are escrowed first.
+	escrow(700)
+	requireEscrowed(0, "escrowing unlocked funds should not be tracked")
+	escrow(300)
+	requireEscrowed(200, "escrowing locked funds should be tracked")
+	require.NoError(ga.Vesting.ValidateBasic(), "ValidateBasic")
+
+	// Funds received after escrowing locked funds are not locked.
+	require.NoError(ga.Balance.Add(quantity.NewFromUint64(50)), "Add")
+	require.NoError(ga.CheckSpendable(18, quantity.NewFromUint64(50)))
+
+	// Returned stake should lock the general balance again.
+	ga.ReturnEscrowedLocked(quantity.NewFromUint64(150))
+	requireEscrowed(50, "returned locked funds should no longer be tracked")
+	require.Zero(ga.Vesting.LockedBalance(18).Cmp(quantity.NewFromUint64(150)), "locked balance")
+	ga.ReturnEscrowedLocked(quantity.NewFromUint64(100))
+	requireEscrowed(0, "returned locked funds should no longer be tracked")
+
+	// Escrowed amount may not exceed the vesting amount.
+	ga.Vesting.Escrowed = *quantity.NewFromUint64(1001)
+	require.Error(ga.Vesting.ValidateBasic(), "ValidateBasic should fail with too large escrowed amount")
+}
@@ -0,0 +1,95 @@
+// Package api implements the staking history indexer API.
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// ModuleName is a unique module name for the staking indexer module.
+const ModuleName = "staking/indexer"
+
+var (
+	// ErrInvalidArgument is the error returned on malformed argument(s).
+	ErrInvalidArgument = errors.New(ModuleName, 1, "staking/indexer: invalid argument")
+
+	// ErrNotIndexed is the error returned when the requested height range has
+	// not (yet) been indexed.
+	ErrNotIndexed = errors.New(ModuleName, 2, "staking/indexer: height not indexed")
+
+	// ErrNotAvailable is the error returned when the indexer has not indexed
+	// any heights yet.
+	ErrNotAvailable = errors.New(ModuleName, 3, "staking/indexer: index not available")
+
+	// ErrHistoryPruned is the error returned when the heights that need to
+	// be indexed have already been pruned by the consensus backend.
+	ErrHistoryPruned = errors.New(ModuleName, 4, "staking/indexer: history pruned")
+)
+
+// Backend is a staking history indexer backend.
+type Backend interface {
+	// GetStatus returns the current status of the indexer.
+	GetStatus(ctx context.Context) (*Status, error)
+
+	// AccountHistory returns all staking events affecting the given account
+	// in the given height range, ordered by height.
+	//
+	// This includes general balance changes (transfers, burns, allowance
+	// changes), escrow share changes and debonding events.
+	AccountHistory(ctx context.Context, query *HistoryQuery) ([]*staking.Event, error)
+
+	// EscrowRewardHistory returns all rewards that have been added to the
+	// given escrow account in the given height range, ordered by height.
+	EscrowRewardHistory(ctx context.Context, query *HistoryQuery) ([]*EscrowReward, error)
+}
+
+// Status is the staking history indexer status.
+type Status struct {
+	// FirstHeight is the first indexed consensus height.
+	FirstHeight int64 `json:"first_height"`
+	// LastHeight is the last indexed consensus height.
+	LastHeight int64 `json:"last_height"`
+	// Stopped is true in case indexing has stopped (e.g., because the history that still needs to
+	// be indexed has been pruned) and the index will no longer be updated.
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// HistoryQuery is a staking history query.
+type HistoryQuery struct {
+	// Address is the account address.
+	Address staking.Address `json:"address"`
+	// FromHeight is the first height (inclusive) of the queried range.
+	//
+	// If zero, the query starts at the first indexed height.
+	FromHeight int64 `json:"from_height,omitempty"`
+	// ToHeight is the last height (inclusive) of the queried range.
+	//
+	// If zero, the query ends at the last indexed height.
+	ToHeight int64 `json:"to_height,omitempty"`
+}
+
+// ValidateBasic performs basic history query validity checks.
+func (q *HistoryQuery) ValidateBasic() error {
+	if !q.Address.IsValid() {
+		return fmt.Errorf("%w: invalid address", ErrInvalidArgument)
+	}
+	if q.FromHeight < 0 || q.ToHeight < 0 {
+		return fmt.Errorf("%w: negative height", ErrInvalidArgument)
+	}
+	if q.ToHeight != 0 && q.FromHeight > q.ToHeight {
+		return fmt.Errorf("%w: from height greater than to height", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// EscrowReward is a reward added to an escrow account.
+type EscrowReward struct {
+	// Height is the consensus height at which the reward was added.
+	Height int64 `json:"height"`
+	// Amount is the amount of base units added to the active escrow balance.
+	Amount quantity.Quantity `json:"amount"`
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+var (
+	// serviceName is the gRPC service name.
+	serviceName = cmnGrpc.NewServiceName("StakingIndexer")
+
+	// methodGetStatus is the GetStatus method.
+	methodGetStatus = serviceName.NewMethod("GetStatus", nil)
+	// methodAccountHistory is the AccountHistory method.
+	methodAccountHistory = serviceName.NewMethod("AccountHistory", HistoryQuery{})
+	// methodEscrowRewardHistory is the EscrowRewardHistory method.
+	methodEscrowRewardHistory = serviceName.NewMethod("EscrowRewardHistory", HistoryQuery{})
+
+	// serviceDesc is the gRPC service descriptor.
+	serviceDesc = grpc.ServiceDesc{
+		ServiceName: string(serviceName),
+		HandlerType: (*Backend)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: methodGetStatus.ShortName(),
+				Handler:    handlerGetStatus,
+			},
+			{
+				MethodName: methodAccountHistory.ShortName(),
+				Handler:    handlerAccountHistory,
+			},
+			{
+				MethodName: methodEscrowRewardHistory.ShortName(),
+				Handler:    handlerEscrowRewardHistory,
+			},
+		},
+		Streams: []grpc.StreamDesc{},
+	}
+)
+
+func handlerGetStatus( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	if interceptor == nil {
+		return srv.(Backend).GetStatus(ctx)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetStatus.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Backend).GetStatus(ctx)
+	}
+	return interceptor(ctx, nil, info, handler)
+}
+
+func handlerAccountHistory( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var query HistoryQuery
+	if err := dec(&query); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Backend).AccountHistory(ctx, &query)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodAccountHistory.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Backend).AccountHistory(ctx, req.(*HistoryQuery))
+	}
+	return interceptor(ctx, &query, info, handler)
+}
+
+func handlerEscrowRewardHistory( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var query HistoryQuery
+	if err := dec(&query); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Backend).EscrowRewardHistory(ctx, &query)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodEscrowRewardHistory.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Backend).EscrowRewardHistory(ctx, req.(*HistoryQuery))
+	}
+	return interceptor(ctx, &query, info, handler)
+}
+
+// RegisterService registers a new staking indexer backend service with the given gRPC server.
+func RegisterService(server *grpc.Server, service Backend) {
+	server.RegisterService(&serviceDesc, service)
+}
+
+type stakingIndexerClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *stakingIndexerClient) GetStatus(ctx context.Context) (*Status, error) {
+	var rsp Status
+	if err := c.conn.Invoke(ctx, methodGetStatus.FullName(), nil, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *stakingIndexerClient) AccountHistory(ctx context.Context, query *HistoryQuery) ([]*staking.Event, error) {
+	var rsp []*staking.Event
+	if err := c.conn.Invoke(ctx, methodAccountHistory.FullName(), query, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (c *stakingIndexerClient) EscrowRewardHistory(ctx context.Context, query *HistoryQuery) ([]*EscrowReward, error) {
+	var rsp []*EscrowReward
+	if err := c.conn.Invoke(ctx, methodEscrowRewardHistory.FullName(), query, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// NewStakingIndexerClient creates a new gRPC staking indexer client service.
+func NewStakingIndexerClient(c *grpc.ClientConn) Backend {
+	return &stakingIndexerClient{c}
+}
@@ -0,0 +1,309 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
+
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	"github.com/oasisprotocol/oasis-core/go/staking/indexer/api"
+)
+
+const dbVersion = 1
+
+var (
+	// metadataKeyFmt is the metadata key format.
+	//
+	// Value is CBOR-serialized dbMetadata.
+	metadataKeyFmt = keyformat.New(0x01)
+	// accountEventKeyFmt is the per-account event index key format.
+	//
+	// Key format is: 0x02 <address> <height> <event index>
+	//
+	// Value is CBOR-serialized staking.Event.
+	accountEventKeyFmt = keyformat.New(0x02, &staking.Address{}, int64(0), uint64(0))
+	// escrowRewardKeyFmt is the per-account escrow reward index key format.
+	//
+	// Key format is: 0x03 <address> <height> <event index>
+	//
+	// Value is CBOR-serialized api.EscrowReward.
+	escrowRewardKeyFmt = keyformat.New(0x03, &staking.Address{}, int64(0), uint64(0))
+)
+
+type dbMetadata struct {
+	// Version is the database schema version.
+	Version uint64 `json:"version"`
+
+	// FirstHeight is the first indexed consensus height.
+	FirstHeight int64 `json:"first_height"`
+	// LastHeight is the last indexed consensus height.
+	LastHeight int64 `json:"last_height"`
+}
+
+// DB is the staking history index database.
+type DB struct {
+	logger *logging.Logger
+
+	db *badger.DB
+	gc *cmnBadger.GCWorker
+}
+
+func newDB(fn string) (*DB, error) {
+	logger := logging.GetLogger("staking/indexer").With("path", fn)
+
+	opts := badger.DefaultOptions(fn)
+	opts = opts.WithLogger(cmnBadger.NewLogAdapter(logger))
+	opts = opts.WithSyncWrites(true)
+	opts = opts.WithCompression(options.None)
+
+	db, err := cmnBadger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("staking/indexer: failed to open database: %w", err)
+	}
+
+	d := &DB{
+		logger: logger,
+		db:     db,
+		gc:     cmnBadger.NewGCWorker(logger, db),
+	}
+
+	// Ensure metadata is valid.
+	if err = d.ensureMetadata(); err != nil {
+		d.close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *DB) queryGetMetadata(tx *badger.Txn) (*dbMetadata, error) {
+	item, err := tx.Get(metadataKeyFmt.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var meta dbMetadata
+	err = item.Value(func(val []byte) error {
+		return cbor.Unmarshal(val, &meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (d *DB) ensureMetadata() error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		meta, err := d.queryGetMetadata(tx)
+		switch err {
+		case nil:
+		case badger.ErrKeyNotFound:
+			// Create new metadata section.
+			meta := dbMetadata{
+				Version: dbVersion,
+			}
+			return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+		default:
+			return err
+		}
+
+		// Verify metadata section.
+		if meta.Version != dbVersion {
+			return fmt.Errorf("staking/indexer: unsupported database version (expected: %d got: %d)",
+				dbVersion,
+				meta.Version,
+			)
+		}
+		return nil
+	})
+}
+
+func (d *DB) metadata() (*dbMetadata, error) {
+	var meta *dbMetadata
+	err := d.db.View(func(tx *badger.Txn) error {
+		var err error
+		meta, err = d.queryGetMetadata(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// commit indexes all staking events emitted at the given height.
+//
+// Heights must be committed in sequence, without any gaps.
+func (d *DB) commit(height int64, events []*staking.Event) error {
+	return d.db.Update(func(tx *badger.Txn) error {
+		meta, err := d.queryGetMetadata(tx)
+		if err != nil {
+			return err
+		}
+
+		if meta.LastHeight != 0 && height != meta.LastHeight+1 {
+			return fmt.Errorf("staking/indexer: commit at non-sequential height (last: %d wanted: %d)",
+				meta.LastHeight,
+				height,
+			)
+		}
+
+		for idx, ev := range events {
+			raw := cbor.Marshal(ev)
+			for _, addr := range eventAddresses(ev) {
+				if err = tx.Set(accountEventKeyFmt.Encode(addr, height, uint64(idx)), raw); err != nil {
+					return err
+				}
+			}
+
+			if reward := eventEscrowReward(ev); reward != nil {
+				reward.Height = height
+				if err = tx.Set(escrowRewardKeyFmt.Encode(ev.Escrow.Add.Escrow, height, uint64(idx)), cbor.Marshal(reward)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if meta.FirstHeight == 0 {
+			meta.FirstHeight = height
+		}
+		meta.LastHeight = height
+
+		return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+	})
+}
+
+// iterate calls the given function for each value stored under the given key format for the
+// given address with heights in the inclusive range [fromHeight, toHeight].
+func (d *DB) iterate(
+	keyFmt *keyformat.KeyFormat,
+	addr staking.Address,
+	fromHeight int64,
+	toHeight int64,
+	fn func(val []byte) error,
+) error {
+	return d.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: keyFmt.Encode(addr)})
+		defer it.Close()
+
+		for it.Seek(keyFmt.Encode(addr, fromHeight)); it.Valid(); it.Next() {
+			item := it.Item()
+
+			var (
+				decAddr staking.Address
+				height  int64
+			)
+			if !keyFmt.Decode(item.Key(), &decAddr, &height) {
+				break
+			}
+			if height > toHeight {
+				break
+			}
+
+			if err := item.Value(fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *DB) accountHistory(addr staking.Address, fromHeight, toHeight int64) ([]*staking.Event, error) {
+	var events []*staking.Event
+	err := d.iterate(accountEventKeyFmt, addr, fromHeight, toHeight, func(val []byte) error {
+		var ev staking.Event
+		if err := cbor.UnmarshalTrusted(val, &ev); err != nil {
+			return err
+		}
+		events = append(events, &ev)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (d *DB) escrowRewardHistory(addr staking.Address, fromHeight, toHeight int64) ([]*api.EscrowReward, error) {
+	var rewards []*api.EscrowReward
+	err := d.iterate(escrowRewardKeyFmt, addr, fromHeight, toHeight, func(val []byte) error {
+		var reward api.EscrowReward
+		if err := cbor.UnmarshalTrusted(val, &reward); err != nil {
+			return err
+		}
+		rewards = append(rewards, &reward)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+func (d *DB) close() {
+	d.gc.Close()
+	d.db.Close()
+}
+
+// eventAddresses returns the deduplicated list of non-reserved account addresses affected by the
+// given staking event.
+func eventAddresses(ev *staking.Event) []staking.Address {
+	var addrs []staking.Address
+	switch {
+	case ev.Transfer != nil:
+		addrs = append(addrs, ev.Transfer.From, ev.Transfer.To)
+	case ev.Burn != nil:
+		addrs = append(addrs, ev.Burn.Owner)
+	case ev.Escrow != nil:
+		switch {
+		case ev.Escrow.Add != nil:
+			addrs = append(addrs, ev.Escrow.Add.Owner, ev.Escrow.Add.Escrow)
+		case ev.Escrow.Take != nil:
+			addrs = append(addrs, ev.Escrow.Take.Owner)
+		case ev.Escrow.DebondingStart != nil:
+			addrs = append(addrs, ev.Escrow.DebondingStart.Owner, ev.Escrow.DebondingStart.Escrow)
+		case ev.Escrow.Reclaim != nil:
+			addrs = append(addrs, ev.Escrow.Reclaim.Owner, ev.Escrow.Reclaim.Escrow)
+		}
+	case ev.AllowanceChange != nil:
+		addrs = append(addrs, ev.AllowanceChange.Owner, ev.AllowanceChange.Beneficiary)
+	}
+
+	var result []staking.Address
+	seen := make(map[staking.Address]bool)
+	for _, addr := range addrs {
+		// Reserved addresses (e.g., the common pool) are involved in a large number of events and
+		// are not indexed.
+		if addr.IsReserved() || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		result = append(result, addr)
+	}
+	return result
+}
+
+// eventEscrowReward returns the escrow reward represented by the given staking event or nil in
+// case the event does not represent an escrow reward.
+//
+// Rewards are added to the active escrow balance directly from the common pool without issuing
+// any new shares.
+func eventEscrowReward(ev *staking.Event) *api.EscrowReward {
+	if ev.Escrow == nil || ev.Escrow.Add == nil {
+		return nil
+	}
+	add := ev.Escrow.Add
+	if !add.Owner.Equal(staking.CommonPoolAddress) || !add.NewShares.IsZero() {
+		return nil
+	}
+	return &api.EscrowReward{
+		Height: ev.Height,
+		Amount: add.Amount,
+	}
+}
@@ -0,0 +1,324 @@
+// Package indexer implements the node-local staking history indexer.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	"github.com/oasisprotocol/oasis-core/go/staking/indexer/api"
+)
+
+const (
+	// CfgEnabled enables the staking history indexer.
+	CfgEnabled = "staking.indexer.enabled"
+
+	// DbFilename is the filename of the staking history index database.
+	DbFilename = "staking-indexer.db"
+)
+
+// Flags has the configuration flags.
+var Flags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var _ api.Backend = (*Indexer)(nil)
+
+// Indexer is the staking history indexer service.
+//
+// The indexer follows the consensus layer and for each block indexes all staking events that
+// affect any (non-reserved) account. On restart it resumes from the last indexed height.
+type Indexer struct {
+	logger *logging.Logger
+
+	consensus consensus.Backend
+
+	db *DB
+
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	stoppedCh chan struct{}
+	quitCh    chan struct{}
+}
+
+// Name returns the service name.
+func (idx *Indexer) Name() string {
+	return "staking history indexer"
+}
+
+// Start starts the service.
+func (idx *Indexer) Start() error {
+	go idx.worker()
+	return nil
+}
+
+// Stop halts the service.
+func (idx *Indexer) Stop() {
+	idx.cancelCtx()
+}
+
+// Quit returns a channel that will be closed when the service terminates.
+func (idx *Indexer) Quit() <-chan struct{} {
+	return idx.quitCh
+}
+
+// Cleanup performs the service specific post-termination cleanup.
+func (idx *Indexer) Cleanup() {
+	idx.db.close()
+}
+
+// Implements api.Backend.
+func (idx *Indexer) GetStatus(ctx context.Context) (*api.Status, error) {
+	meta, err := idx.db.metadata()
+	if err != nil {
+		return nil, err
+	}
+	var stopped bool
+	select {
+	case <-idx.stoppedCh:
+		stopped = true
+	default:
+	}
+	return &api.Status{
+		FirstHeight: meta.FirstHeight,
+		LastHeight:  meta.LastHeight,
+		Stopped:     stopped,
+	}, nil
+}
+
+// Implements api.Backend.
+func (idx *Indexer) AccountHistory(ctx context.Context, query *api.HistoryQuery) ([]*staking.Event, error) {
+	fromHeight, toHeight, err := idx.resolveRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return idx.db.accountHistory(query.Address, fromHeight, toHeight)
+}
+
+// Implements api.Backend.
+func (idx *Indexer) EscrowRewardHistory(ctx context.Context, query *api.HistoryQuery) ([]*api.EscrowReward, error) {
+	fromHeight, toHeight, err := idx.resolveRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return idx.db.escrowRewardHistory(query.Address, fromHeight, toHeight)
+}
+
+func (idx *Indexer) resolveRange(query *api.HistoryQuery) (int64, int64, error) {
+	if err := query.ValidateBasic(); err != nil {
+		return 0, 0, err
+	}
+
+	meta, err := idx.db.metadata()
+	if err != nil {
+		return 0, 0, err
+	}
+	if meta.LastHeight == 0 {
+		return 0, 0, api.ErrNotAvailable
+	}
+
+	fromHeight, toHeight := query.FromHeight, query.ToHeight
+	if fromHeight == 0 {
+		fromHeight = meta.FirstHeight
+	}
+	if toHeight == 0 {
+		toHeight = meta.LastHeight
+	}
+
+	if fromHeight < meta.FirstHeight || toHeight > meta.LastHeight {
+		return 0, 0, fmt.Errorf("%w: indexed range is [%d, %d]", api.ErrNotIndexed, meta.FirstHeight, meta.LastHeight)
+	}
+	if fromHeight > toHeight {
+		return 0, 0, fmt.Errorf("%w: from height greater than to height", api.ErrInvalidArgument)
+	}
+	return fromHeight, toHeight, nil
+}
+
+// indexHeight indexes all staking events emitted at the given height.
+func (idx *Indexer) indexHeight(ctx context.Context, height int64) error {
+	events, err := idx.consensus.Staking().GetEvents(ctx, height)
+	if err != nil {
+		return fmt.Errorf("failed to get staking events at height %d: %w", height, err)
+	}
+	return idx.db.commit(height, events)
+}
+
+// indexUpTo indexes all heights following the last indexed height up to and including the
+// given height.
+func (idx *Indexer) indexUpTo(ctx context.Context, height int64) error {
+	meta, err := idx.db.metadata()
+	if err != nil {
+		return err
+	}
+
+	startHeight := meta.LastHeight + 1
+	if meta.LastHeight == 0 {
+		// Nothing has been indexed yet, start at the oldest retained height.
+		var status *consensus.Status
+		status, err = idx.consensus.GetStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get consensus status: %w", err)
+		}
+		startHeight = status.LastRetainedHeight
+		if startHeight < status.GenesisHeight {
+			startHeight = status.GenesisHeight
+		}
+	}
+
+	if startHeight <= height {
+		idx.logger.Debug("indexing staking events",
+			"from_height", startHeight,
+			"to_height", height,
+		)
+	}
+
+	for h := startHeight; h <= height; h++ {
+		if err = idx.indexHeight(ctx, h); err != nil {
+			if prunedErr := idx.checkPruned(ctx, h); prunedErr != nil {
+				return prunedErr
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPruned returns ErrHistoryPruned in case the given height has been pruned by the consensus
+// backend, which means that indexing can never succeed.
+func (idx *Indexer) checkPruned(ctx context.Context, height int64) error {
+	status, err := idx.consensus.GetStatus(ctx)
+	if err != nil {
+		return nil
+	}
+	if height >= status.LastRetainedHeight {
+		return nil
+	}
+	return fmt.Errorf("%w: height %d not available (last retained height: %d)",
+		api.ErrHistoryPruned,
+		height,
+		status.LastRetainedHeight,
+	)
+}
+
+func (idx *Indexer) logTerminalError(err error) {
+	idx.logger.Error("staking history can no longer be indexed, remove the indexer database to start indexing from the oldest retained height",
+		"err", err,
+	)
+}
+
+func (idx *Indexer) worker() {
+	defer close(idx.quitCh)
+
+	idx.index()
+
+	// Indexing stopping on its own (e.g., due to history being pruned) should not terminate the
+	// node, so only quit once the service is stopped.
+	<-idx.ctx.Done()
+}
+
+func (idx *Indexer) index() {
+	defer close(idx.stoppedCh)
+
+	// Wait for the consensus backend to be synced.
+	select {
+	case <-idx.consensus.Synced():
+	case <-idx.ctx.Done():
+		return
+	}
+
+	blkCh, blkSub, err := idx.consensus.WatchBlocks(idx.ctx)
+	if err != nil {
+		idx.logger.Error("failed to watch blocks",
+			"err", err,
+		)
+		return
+	}
+	defer blkSub.Close()
+
+	// Catch up from the last indexed height. In case of failure, indexing will be retried on
+	// the next block unless the missing heights have been pruned.
+	status, err := idx.consensus.GetStatus(idx.ctx)
+	switch err {
+	case nil:
+		if err = idx.indexUpTo(idx.ctx, status.LatestHeight); err != nil {
+			if errors.Is(err, api.ErrHistoryPruned) {
+				idx.logTerminalError(err)
+				return
+			}
+			idx.logger.Error("failed to catch up with consensus",
+				"err", err,
+			)
+			break
+		}
+
+		idx.logger.Info("staking history indexer caught up",
+			"height", status.LatestHeight,
+		)
+	default:
+		idx.logger.Error("failed to get consensus status",
+			"err", err,
+		)
+	}
+
+	for {
+		select {
+		case <-idx.ctx.Done():
+			return
+		case blk, ok := <-blkCh:
+			if !ok {
+				idx.logger.Error("block channel closed, staking history indexing stopped")
+				return
+			}
+
+			// Any heights that failed to be indexed before will be retried.
+			err = idx.indexUpTo(idx.ctx, blk.Height)
+			switch {
+			case err == nil:
+			case errors.Is(err, api.ErrHistoryPruned):
+				idx.logTerminalError(err)
+				return
+			default:
+				idx.logger.Error("failed to index staking events",
+					"err", err,
+					"height", blk.Height,
+				)
+			}
+		}
+	}
+}
+
+// New creates a new staking history indexer.
+func New(dataDir string, consensus consensus.Backend) (*Indexer, error) {
+	db, err := newDB(filepath.Join(dataDir, DbFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	return &Indexer{
+		logger:    logging.GetLogger("staking/indexer"),
+		consensus: consensus,
+		db:        db,
+		ctx:       ctx,
+		cancelCtx: cancelCtx,
+		stoppedCh: make(chan struct{}),
+		quitCh:    make(chan struct{}),
+	}, nil
+}
+
+// Enabled reads our enabled flag from viper.
+func Enabled() bool {
+	return viper.GetBool(CfgEnabled)
+}
+
+func init() {
+	Flags.Bool(CfgEnabled, false, "Enable the staking history indexer")
+
+	_ = viper.BindPFlags(Flags)
+}
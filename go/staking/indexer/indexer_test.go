@@ -0,0 +1,295 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	"github.com/oasisprotocol/oasis-core/go/staking/indexer/api"
+)
+
+const testTimeout = 5 * time.Second
+
+func TestIndexer(t *testing.T) {
+	require := require.New(t)
+
+	// Create a new random temporary directory under /tmp.
+	dataDir, err := ioutil.TempDir("", "oasis-staking-indexer-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	addr1 := staking.NewAddress(signature.NewPublicKey("aaafffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+	addr2 := staking.NewAddress(signature.NewPublicKey("bbbfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+	addr3 := staking.NewAddress(signature.NewPublicKey("cccfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+
+	db, err := newDB(filepath.Join(dataDir, DbFilename))
+	require.NoError(err, "newDB")
+	idx := &Indexer{db: db}
+
+	ctx := context.Background()
+	query := &api.HistoryQuery{Address: addr1}
+
+	_, err = idx.AccountHistory(ctx, query)
+	require.Error(err, "AccountHistory should fail when nothing is indexed")
+	require.True(errors.Is(err, api.ErrNotAvailable))
+
+	// Height 10: transfer from addr1 to addr2.
+	err = db.commit(10, []*staking.Event{
+		{
+			Height:   10,
+			Transfer: &staking.TransferEvent{From: addr1, To: addr2, Amount: *quantity.NewFromUint64(100)},
+		},
+	})
+	require.NoError(err, "commit")
+
+	// Height 11: addr2 escrows to addr3, addr3 receives a reward.
+	err = db.commit(11, []*staking.Event{
+		{
+			Height: 11,
+			Escrow: &staking.EscrowEvent{Add: &staking.AddEscrowEvent{
+				Owner:     addr2,
+				Escrow:    addr3,
+				Amount:    *quantity.NewFromUint64(50),
+				NewShares: *quantity.NewFromUint64(50),
+			}},
+		},
+		{
+			Height: 11,
+			Escrow: &staking.EscrowEvent{Add: &staking.AddEscrowEvent{
+				Owner:  staking.CommonPoolAddress,
+				Escrow: addr3,
+				Amount: *quantity.NewFromUint64(5),
+			}},
+		},
+	})
+	require.NoError(err, "commit")
+
+	// Height 12: no events.
+	err = db.commit(12, nil)
+	require.NoError(err, "commit")
+
+	err = db.commit(14, nil)
+	require.Error(err, "commit should fail for non-sequential height")
+
+	status, err := idx.GetStatus(ctx)
+	require.NoError(err, "GetStatus")
+	require.EqualValues(10, status.FirstHeight)
+	require.EqualValues(12, status.LastHeight)
+
+	events, err := idx.AccountHistory(ctx, query)
+	require.NoError(err, "AccountHistory")
+	require.Len(events, 1)
+	require.NotNil(events[0].Transfer)
+	require.EqualValues(10, events[0].Height)
+
+	events, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr2})
+	require.NoError(err, "AccountHistory")
+	require.Len(events, 2)
+	require.EqualValues(10, events[0].Height)
+	require.EqualValues(11, events[1].Height)
+
+	events, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr2, FromHeight: 11, ToHeight: 12})
+	require.NoError(err, "AccountHistory")
+	require.Len(events, 1)
+	require.NotNil(events[0].Escrow.Add)
+
+	events, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr3})
+	require.NoError(err, "AccountHistory")
+	require.Len(events, 2)
+
+	rewards, err := idx.EscrowRewardHistory(ctx, &api.HistoryQuery{Address: addr3})
+	require.NoError(err, "EscrowRewardHistory")
+	require.Len(rewards, 1)
+	require.EqualValues(11, rewards[0].Height)
+	require.EqualValues(*quantity.NewFromUint64(5), rewards[0].Amount)
+
+	rewards, err = idx.EscrowRewardHistory(ctx, &api.HistoryQuery{Address: addr2})
+	require.NoError(err, "EscrowRewardHistory")
+	require.Len(rewards, 0)
+
+	_, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr1, FromHeight: 5})
+	require.Error(err, "AccountHistory should fail for non-indexed heights")
+	require.True(errors.Is(err, api.ErrNotIndexed))
+
+	_, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr1, ToHeight: 13})
+	require.Error(err, "AccountHistory should fail for non-indexed heights")
+	require.True(errors.Is(err, api.ErrNotIndexed))
+
+	_, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr1, FromHeight: 12, ToHeight: 11})
+	require.Error(err, "AccountHistory should fail for invalid range")
+	require.True(errors.Is(err, api.ErrInvalidArgument))
+
+	_, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: staking.CommonPoolAddress})
+	require.Error(err, "AccountHistory should fail for reserved addresses")
+	require.True(errors.Is(err, api.ErrInvalidArgument))
+
+	// Reopen the database and make sure the index persists.
+	db.close()
+	db, err = newDB(filepath.Join(dataDir, DbFilename))
+	require.NoError(err, "newDB")
+	defer db.close()
+	idx = &Indexer{db: db}
+
+	status, err = idx.GetStatus(ctx)
+	require.NoError(err, "GetStatus")
+	require.EqualValues(10, status.FirstHeight)
+	require.EqualValues(12, status.LastHeight)
+
+	events, err = idx.AccountHistory(ctx, &api.HistoryQuery{Address: addr2})
+	require.NoError(err, "AccountHistory")
+	require.Len(events, 2)
+
+	err = db.commit(13, nil)
+	require.NoError(err, "commit should resume at the next height")
+}
+
+func TestEventAddresses(t *testing.T) {
+	require := require.New(t)
+
+	addr1 := staking.NewAddress(signature.NewPublicKey("aaafffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+	addr2 := staking.NewAddress(signature.NewPublicKey("bbbfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+
+	addrs := eventAddresses(&staking.Event{
+		Transfer: &staking.TransferEvent{From: addr1, To: addr2},
+	})
+	require.Equal([]staking.Address{addr1, addr2}, addrs)
+
+	// Self-transfers should only be indexed once.
+	addrs = eventAddresses(&staking.Event{
+		Transfer: &staking.TransferEvent{From: addr1, To: addr1},
+	})
+	require.Equal([]staking.Address{addr1}, addrs)
+
+	// Reserved addresses should not be indexed nor affect deduplication.
+	addrs = eventAddresses(&staking.Event{
+		Escrow: &staking.EscrowEvent{Add: &staking.AddEscrowEvent{
+			Owner:  staking.CommonPoolAddress,
+			Escrow: addr2,
+		}},
+	})
+	require.Equal([]staking.Address{addr2}, addrs)
+}
+
+type testConsensus struct {
+	consensus.Backend
+
+	status *consensus.Status
+}
+
+func (c *testConsensus) GetStatus(ctx context.Context) (*consensus.Status, error) {
+	return c.status, nil
+}
+
+func (c *testConsensus) Synced() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+func (c *testConsensus) WatchBlocks(ctx context.Context) (<-chan *consensus.Block, pubsub.ClosableSubscription, error) {
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+	ch := make(chan *consensus.Block)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, sub, nil
+}
+
+func (c *testConsensus) Staking() staking.Backend {
+	return &testStaking{consensus: c}
+}
+
+type testStaking struct {
+	staking.Backend
+
+	consensus *testConsensus
+}
+
+func (s *testStaking) GetEvents(ctx context.Context, height int64) ([]*staking.Event, error) {
+	if height < s.consensus.status.LastRetainedHeight {
+		return nil, consensus.ErrVersionNotFound
+	}
+	return nil, nil
+}
+
+func TestIndexerPruned(t *testing.T) {
+	require := require.New(t)
+
+	dataDir, err := ioutil.TempDir("", "oasis-staking-indexer-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	db, err := newDB(filepath.Join(dataDir, DbFilename))
+	require.NoError(err, "newDB")
+	defer db.close()
+
+	backend := &testConsensus{
+		status: &consensus.Status{
+			GenesisHeight:      1,
+			LastRetainedHeight: 5,
+		},
+	}
+	idx := &Indexer{
+		logger:    logging.GetLogger("staking/indexer/test"),
+		consensus: backend,
+		db:        db,
+	}
+	ctx := context.Background()
+
+	// Indexing should start at the oldest retained height.
+	err = idx.indexUpTo(ctx, 10)
+	require.NoError(err, "indexUpTo")
+	status, err := idx.GetStatus(ctx)
+	require.NoError(err, "GetStatus")
+	require.EqualValues(5, status.FirstHeight)
+	require.EqualValues(10, status.LastHeight)
+
+	// Heights pruned before they could be indexed should result in a terminal error.
+	backend.status.LastRetainedHeight = 15
+	err = idx.indexUpTo(ctx, 20)
+	require.Error(err, "indexUpTo should fail for pruned heights")
+	require.True(errors.Is(err, api.ErrHistoryPruned))
+
+	// The indexer should stop indexing without terminating the service.
+	idx.ctx, idx.cancelCtx = context.WithCancel(ctx)
+	idx.stoppedCh = make(chan struct{})
+	idx.quitCh = make(chan struct{})
+	backend.status.LatestHeight = 20
+	require.NoError(idx.Start(), "Start")
+
+	select {
+	case <-idx.stoppedCh:
+	case <-time.After(testTimeout):
+		t.Fatalf("failed to wait for indexing to stop")
+	}
+	status, err = idx.GetStatus(ctx)
+	require.NoError(err, "GetStatus")
+	require.True(status.Stopped, "indexing should be stopped")
+	require.EqualValues(10, status.LastHeight)
+
+	select {
+	case <-idx.Quit():
+		t.Fatalf("service should not quit before being stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	idx.Stop()
+	select {
+	case <-idx.Quit():
+	case <-time.After(testTimeout):
+		t.Fatalf("failed to wait for the service to quit")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
 	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
 	badgerNodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/badger"
+	boltNodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/bolt"
 )
 
 const (
@@ -21,6 +22,12 @@ const (
 	// DBFileBadgerDB is the default BadgerDB backing store filename.
 	DBFileBadgerDB = "mkvs_storage.badger.db"
 
+	// BackendNameBoltDB is the name of the bbolt backed database backend.
+	BackendNameBoltDB = "bolt"
+
+	// DBFileBoltDB is the default bbolt backing store filename.
+	DBFileBoltDB = "mkvs_storage.bolt.db"
+
 	checkpointDir = "checkpoints"
 )
 
@@ -30,6 +37,8 @@ func DefaultFileName(backend string) string {
 	switch backend {
 	case BackendNameBadgerDB:
 		return DBFileBadgerDB
+	case BackendNameBoltDB:
+		return DBFileBoltDB
 	default:
 		panic("storage/database: can't get default filename for unknown backend")
 	}
@@ -45,20 +54,21 @@ type databaseBackend struct {
 	readOnly bool
 }
 
-// New constructs a new database backed storage Backend instance.
-func New(cfg *api.Config) (api.LocalBackend, error) {
-	ndbCfg := cfg.ToNodeDB()
-
-	var (
-		ndb nodedb.NodeDB
-		err error
-	)
-	switch cfg.Backend {
+// NewNodeDB constructs a new node database for the specified backend.
+func NewNodeDB(backend string, cfg *nodedb.Config) (nodedb.NodeDB, error) {
+	switch backend {
 	case BackendNameBadgerDB:
-		ndb, err = badgerNodedb.New(ndbCfg)
+		return badgerNodedb.New(cfg)
+	case BackendNameBoltDB:
+		return boltNodedb.New(cfg)
 	default:
-		err = errors.New("storage/database: unsupported backend")
+		return nil, errors.New("storage/database: unsupported backend")
 	}
+}
+
+// New constructs a new database backed storage Backend instance.
+func New(cfg *api.Config) (api.LocalBackend, error) {
+	ndb, err := NewNodeDB(cfg.Backend, cfg.ToNodeDB())
 	if err != nil {
 		return nil, fmt.Errorf("storage/database: failed to create node database: %w", err)
 	}
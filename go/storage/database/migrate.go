@@ -0,0 +1,431 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+// migrationChunkSize is the checkpoint chunk size used when copying full trees.
+const migrationChunkSize = 8 * 1024 * 1024
+
+// MigrationDisplayHelper is the interface used to report node database migration progress.
+type MigrationDisplayHelper interface {
+	// DisplayStep reports the start of a migration step.
+	DisplayStep(msg string)
+	// DisplayProgress reports progress of the current migration step.
+	DisplayProgress(msg string, current, total uint64)
+}
+
+// MigrateNodeDB copies all retained versions, together with their write logs, from the source
+// node database into the (empty) destination node database and verifies the result.
+//
+// Roots are copied by replaying write logs from the source database wherever possible. Roots
+// without a suitable write log are copied in full via checkpoints which are created in the given
+// temporary directory.
+func MigrateNodeDB(
+	ctx context.Context,
+	namespace common.Namespace,
+	src nodedb.NodeDB,
+	dst nodedb.NodeDB,
+	checkpointDir string,
+	display MigrationDisplayHelper,
+) error {
+	earliestVersion, err := src.GetEarliestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get earliest source version: %w", err)
+	}
+	latestVersion, err := src.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest source version: %w", err)
+	}
+
+	// Make sure that the destination database is empty.
+	dstLatestVersion, err := dst.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest destination version: %w", err)
+	}
+	dstRoots, err := dst.GetRootsForVersion(ctx, dstLatestVersion)
+	if err != nil {
+		return fmt.Errorf("failed to get destination roots: %w", err)
+	}
+	if dstLatestVersion != 0 || len(dstRoots) > 0 {
+		return fmt.Errorf("destination node database is not empty")
+	}
+
+	creator, err := checkpoint.NewFileCreator(checkpointDir, src)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint creator: %w", err)
+	}
+	restorer, err := checkpoint.NewRestorer(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint restorer: %w", err)
+	}
+	m := &migrator{
+		namespace: namespace,
+		src:       src,
+		dst:       dst,
+		creator:   creator,
+		restorer:  restorer,
+		earliest:  earliestVersion,
+	}
+
+	display.DisplayStep("copying versions")
+	total := latestVersion - earliestVersion + 1
+	for version := earliestVersion; version <= latestVersion; version++ {
+		display.DisplayProgress("copied versions", version-earliestVersion, total)
+		if err = m.copyVersion(ctx, version); err != nil {
+			return fmt.Errorf("failed to copy version %d: %w", version, err)
+		}
+	}
+	display.DisplayProgress("copied versions", total, total)
+
+	display.DisplayStep("verifying destination node database")
+	return m.verify(ctx, latestVersion)
+}
+
+type migrator struct {
+	namespace common.Namespace
+
+	src nodedb.NodeDB
+	dst nodedb.NodeDB
+
+	creator  checkpoint.Creator
+	restorer checkpoint.Restorer
+
+	earliest uint64
+}
+
+func (m *migrator) copyVersion(ctx context.Context, version uint64) error {
+	roots, err := m.src.GetRootsForVersion(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to get source roots: %w", err)
+	}
+
+	var prevRoots []node.Root
+	if version > m.earliest {
+		if prevRoots, err = m.dst.GetRootsForVersion(ctx, version-1); err != nil {
+			return fmt.Errorf("failed to get previous destination roots: %w", err)
+		}
+	}
+
+	// First copy all roots for which a write log is available as that also preserves write logs.
+	var remaining []node.Root
+	for _, root := range roots {
+		var copied bool
+		if copied, err = m.copyRootFromWriteLog(ctx, root, prevRoots); err != nil {
+			return fmt.Errorf("failed to copy root %s: %w", root.Hash, err)
+		}
+		if !copied {
+			remaining = append(remaining, root)
+		}
+	}
+
+	// Then copy any remaining roots in full.
+	if len(remaining) > 0 {
+		if err = m.copyRootsInFull(ctx, version, remaining); err != nil {
+			return err
+		}
+	}
+
+	// Finalize the version. In case there are no roots in this version, finalize it with an
+	// empty root as the source database did.
+	if len(roots) == 0 {
+		emptyRoot := node.Root{
+			Namespace: m.namespace,
+			Version:   version,
+			Type:      node.RootTypeState,
+		}
+		emptyRoot.Hash.Empty()
+		roots = []node.Root{emptyRoot}
+	}
+	if err = m.dst.Finalize(ctx, roots); err != nil {
+		return fmt.Errorf("failed to finalize: %w", err)
+	}
+	return nil
+}
+
+func (m *migrator) copyRootFromWriteLog(ctx context.Context, root node.Root, prevRoots []node.Root) (bool, error) {
+	for _, oldRoot := range writeLogCandidates(root, prevRoots) {
+		wl, err := m.src.GetWriteLog(ctx, oldRoot, root)
+		switch {
+		case err == nil:
+		case errors.Is(err, nodedb.ErrWriteLogNotFound):
+			continue
+		default:
+			return false, fmt.Errorf("failed to get write log: %w", err)
+		}
+
+		if err = m.applyWriteLog(ctx, oldRoot, root, wl); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// writeLogCandidates returns the roots at which a write log ending in the given root may start.
+//
+// Write logs either start at a root of the same type in the previous version or at an empty root
+// in the same version.
+func writeLogCandidates(root node.Root, prevRoots []node.Root) []node.Root {
+	var candidates []node.Root
+	for _, prevRoot := range prevRoots {
+		if prevRoot.Type == root.Type {
+			candidates = append(candidates, prevRoot)
+		}
+	}
+	emptyRoot := node.Root{
+		Namespace: root.Namespace,
+		Version:   root.Version,
+		Type:      root.Type,
+	}
+	emptyRoot.Hash.Empty()
+	return append(candidates, emptyRoot)
+}
+
+func (m *migrator) applyWriteLog(ctx context.Context, oldRoot, root node.Root, wl writelog.Iterator) error {
+	tree := mkvs.NewWithRoot(nil, m.dst, oldRoot)
+	defer tree.Close()
+
+	if err := tree.ApplyWriteLog(ctx, wl); err != nil {
+		return fmt.Errorf("failed to apply write log: %w", err)
+	}
+	_, rootHash, err := tree.Commit(ctx, root.Namespace, root.Version)
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return checkRootHash(root, rootHash)
+}
+
+func (m *migrator) copyRootsInFull(ctx context.Context, version uint64, roots []node.Root) error {
+	// Empty roots and roots in version zero (where multipart inserts are not possible) are
+	// rebuilt while all other roots are restored from checkpoints.
+	var restoreRoots []node.Root
+	for _, root := range roots {
+		if version > 0 && !root.Hash.IsEmpty() {
+			restoreRoots = append(restoreRoots, root)
+			continue
+		}
+		if err := m.rebuildRoot(ctx, root); err != nil {
+			return fmt.Errorf("failed to rebuild root %s: %w", root.Hash, err)
+		}
+	}
+	if len(restoreRoots) == 0 {
+		return nil
+	}
+
+	if err := m.dst.StartMultipartInsert(version); err != nil {
+		return fmt.Errorf("failed to start multipart insert: %w", err)
+	}
+	for _, root := range restoreRoots {
+		if err := m.restoreRoot(ctx, root); err != nil {
+			_ = m.dst.AbortMultipartInsert()
+			return fmt.Errorf("failed to restore root %s: %w", root.Hash, err)
+		}
+	}
+	return nil
+}
+
+func (m *migrator) restoreRoot(ctx context.Context, root node.Root) error {
+	cp, err := m.creator.CreateCheckpoint(ctx, root, migrationChunkSize)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer func() {
+		_ = m.creator.DeleteCheckpoint(ctx, cp.Version, root)
+	}()
+
+	if err = m.restorer.StartRestore(ctx, cp); err != nil {
+		return fmt.Errorf("failed to start restore: %w", err)
+	}
+	for idx := range cp.Chunks {
+		var chunk *checkpoint.ChunkMetadata
+		if chunk, err = cp.GetChunkMetadata(uint64(idx)); err != nil {
+			break
+		}
+
+		var buf bytes.Buffer
+		if err = m.creator.GetCheckpointChunk(ctx, chunk, &buf); err != nil {
+			break
+		}
+		// Restoring a chunk verifies it against the checkpoint root.
+		if _, err = m.restorer.RestoreChunk(ctx, uint64(idx), &buf); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		_ = m.restorer.AbortRestore(ctx)
+		return fmt.Errorf("failed to restore chunk: %w", err)
+	}
+	return nil
+}
+
+func (m *migrator) rebuildRoot(ctx context.Context, root node.Root) error {
+	srcTree := mkvs.NewWithRoot(nil, m.src, root)
+	defer srcTree.Close()
+	dstTree := mkvs.New(nil, m.dst, root.Type)
+	defer dstTree.Close()
+
+	it := srcTree.NewIterator(ctx)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		if err := dstTree.Insert(ctx, it.Key(), it.Value()); err != nil {
+			return fmt.Errorf("failed to insert: %w", err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to iterate: %w", err)
+	}
+
+	_, rootHash, err := dstTree.Commit(ctx, root.Namespace, root.Version)
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return checkRootHash(root, rootHash)
+}
+
+func (m *migrator) verify(ctx context.Context, latestVersion uint64) error {
+	dstEarliestVersion, err := m.dst.GetEarliestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get earliest destination version: %w", err)
+	}
+	dstLatestVersion, err := m.dst.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest destination version: %w", err)
+	}
+	if dstEarliestVersion != m.earliest || dstLatestVersion != latestVersion {
+		return fmt.Errorf("version range mismatch (expected: %d-%d got: %d-%d)",
+			m.earliest, latestVersion,
+			dstEarliestVersion, dstLatestVersion,
+		)
+	}
+
+	var prevRoots []node.Root
+	for version := m.earliest; version <= latestVersion; version++ {
+		srcRoots, err := m.src.GetRootsForVersion(ctx, version)
+		if err != nil {
+			return fmt.Errorf("failed to get source roots for version %d: %w", version, err)
+		}
+		dstRoots, err := m.dst.GetRootsForVersion(ctx, version)
+		if err != nil {
+			return fmt.Errorf("failed to get destination roots for version %d: %w", version, err)
+		}
+		if !sameRoots(srcRoots, dstRoots) {
+			return fmt.Errorf("roots mismatch for version %d (expected: %v got: %v)", version, srcRoots, dstRoots)
+		}
+
+		for _, root := range dstRoots {
+			if err = m.verifyRoot(ctx, root, prevRoots); err != nil {
+				return fmt.Errorf("failed to verify root %s in version %d: %w", root.Hash, version, err)
+			}
+		}
+		prevRoots = dstRoots
+	}
+	return nil
+}
+
+func (m *migrator) verifyRoot(ctx context.Context, root node.Root, prevRoots []node.Root) error {
+	// Make sure that all nodes reachable from the root are present.
+	if !root.Hash.IsEmpty() {
+		if err := nodedb.Visit(ctx, m.dst, root, func(context.Context, node.Node) bool {
+			return true
+		}); err != nil {
+			return fmt.Errorf("failed to traverse root: %w", err)
+		}
+	}
+
+	// Make sure that the write log copied from the source database (if any) is present and
+	// identical.
+	for _, oldRoot := range writeLogCandidates(root, prevRoots) {
+		srcWl, err := m.src.GetWriteLog(ctx, oldRoot, root)
+		switch {
+		case err == nil:
+		case errors.Is(err, nodedb.ErrWriteLogNotFound):
+			continue
+		default:
+			return fmt.Errorf("failed to get source write log: %w", err)
+		}
+
+		dstWl, err := m.dst.GetWriteLog(ctx, oldRoot, root)
+		if err != nil {
+			_ = writelog.DrainIterator(srcWl)
+			return fmt.Errorf("failed to get destination write log from root %s: %w", oldRoot.Hash, err)
+		}
+		return sameWriteLogs(srcWl, dstWl)
+	}
+	return nil
+}
+
+func checkRootHash(root node.Root, rootHash hash.Hash) error {
+	if !rootHash.Equal(&root.Hash) {
+		return fmt.Errorf("root hash mismatch (expected: %s got: %s)", root.Hash, rootHash)
+	}
+	return nil
+}
+
+func sameRoots(a, b []node.Root) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[node.Root]bool, len(a))
+	for _, root := range a {
+		set[root] = true
+	}
+	for _, root := range b {
+		if !set[root] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameWriteLogs checks whether the given write logs contain the same entries. As entry order
+// depends on how the tree was committed, entries are compared in key order.
+func sameWriteLogs(a, b writelog.Iterator) error {
+	wlA, err := collectWriteLog(a)
+	if err != nil {
+		return fmt.Errorf("failed to read source write log: %w", err)
+	}
+	wlB, err := collectWriteLog(b)
+	if err != nil {
+		return fmt.Errorf("failed to read destination write log: %w", err)
+	}
+	if !wlA.Equal(wlB) {
+		return fmt.Errorf("write log mismatch")
+	}
+	return nil
+}
+
+func collectWriteLog(it writelog.Iterator) (writelog.WriteLog, error) {
+	var wl writelog.WriteLog
+	for {
+		more, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+		entry, err := it.Value()
+		if err != nil {
+			return nil, err
+		}
+		wl = append(wl, entry)
+	}
+	sort.Slice(wl, func(i, j int) bool {
+		return bytes.Compare(wl[i].Key, wl[j].Key) < 0
+	})
+	return wl, nil
+}
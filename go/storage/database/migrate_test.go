@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+// numVersions is the number of versions in the test source node database.
+const numVersions = 5
+
+type nopDisplayHelper struct{}
+
+func (nopDisplayHelper) DisplayStep(msg string) {}
+
+func (nopDisplayHelper) DisplayProgress(msg string, current, total uint64) {}
+
+func TestMigrateNodeDB(t *testing.T) {
+	for _, discardWriteLogs := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DiscardWriteLogs=%t", discardWriteLogs), func(t *testing.T) {
+			doTestMigrateNodeDB(t, discardWriteLogs)
+		})
+	}
+}
+
+func doTestMigrateNodeDB(t *testing.T, discardWriteLogs bool) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	testNs := common.NewTestNamespaceFromSeed([]byte("database migrate test ns"), 0)
+
+	dir, err := ioutil.TempDir("", "oasis-storage-database-migrate-test")
+	require.NoError(err, "TempDir()")
+	defer os.RemoveAll(dir)
+
+	src, roots := newTestSourceNodeDB(t, dir, testNs, discardWriteLogs)
+	defer src.Close()
+
+	dst, err := NewNodeDB(BackendNameBoltDB, &nodedb.Config{
+		DB:               filepath.Join(dir, DefaultFileName(BackendNameBoltDB)),
+		NoFsync:          true,
+		Namespace:        testNs,
+		DiscardWriteLogs: discardWriteLogs,
+	})
+	require.NoError(err, "NewNodeDB(dst)")
+	defer dst.Close()
+
+	err = MigrateNodeDB(ctx, testNs, src, dst, filepath.Join(dir, "checkpoints"), nopDisplayHelper{})
+	require.NoError(err, "MigrateNodeDB")
+
+	earliestVersion, err := dst.GetEarliestVersion(ctx)
+	require.NoError(err, "GetEarliestVersion")
+	require.EqualValues(1, earliestVersion, "earliest version should be copied")
+	latestVersion, err := dst.GetLatestVersion(ctx)
+	require.NoError(err, "GetLatestVersion")
+	require.EqualValues(numVersions-1, latestVersion, "latest version should be copied")
+
+	for _, root := range roots {
+		require.True(dst.HasRoot(root), "root should be copied")
+
+		tree := mkvs.NewWithRoot(nil, dst, root)
+		value, err := tree.Get(ctx, []byte(fmt.Sprintf("key %d 1", root.Version)))
+		require.NoError(err, "Get")
+		require.EqualValues(fmt.Sprintf("value %d 1", root.Version), value)
+		value, err = tree.Get(ctx, []byte(fmt.Sprintf("key %d 0", root.Version-1)))
+		require.NoError(err, "Get")
+		require.Nil(value, "removed key should not be present")
+		tree.Close()
+	}
+
+	// Write logs should be copied for all but the earliest version.
+	for i := 1; i < len(roots); i++ {
+		it, err := dst.GetWriteLog(ctx, roots[i-1], roots[i])
+		if discardWriteLogs {
+			require.ErrorIs(err, nodedb.ErrWriteLogNotFound, "GetWriteLog")
+			continue
+		}
+		require.NoError(err, "GetWriteLog")
+
+		var entries int
+		for {
+			more, err := it.Next()
+			require.NoError(err, "it.Next()")
+			if !more {
+				break
+			}
+			_, err = it.Value()
+			require.NoError(err, "it.Value()")
+			entries++
+		}
+		require.Equal(11, entries, "write log should contain all inserts and removals")
+	}
+
+	// Migrating into a non-empty node database should fail.
+	err = MigrateNodeDB(ctx, testNs, src, dst, filepath.Join(dir, "checkpoints"), nopDisplayHelper{})
+	require.Error(err, "MigrateNodeDB should fail for a non-empty destination")
+}
+
+func TestMigrateNodeDBVerify(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	testNs := common.NewTestNamespaceFromSeed([]byte("database migrate verify test ns"), 0)
+
+	dir, err := ioutil.TempDir("", "oasis-storage-database-migrate-test")
+	require.NoError(err, "TempDir()")
+	defer os.RemoveAll(dir)
+
+	src, _ := newTestSourceNodeDB(t, dir, testNs, false)
+	defer src.Close()
+
+	// A destination that discards write logs can not hold the write logs of the older versions.
+	dst, err := NewNodeDB(BackendNameBoltDB, &nodedb.Config{
+		DB:               filepath.Join(dir, DefaultFileName(BackendNameBoltDB)),
+		NoFsync:          true,
+		Namespace:        testNs,
+		DiscardWriteLogs: true,
+	})
+	require.NoError(err, "NewNodeDB(dst)")
+	defer dst.Close()
+
+	err = MigrateNodeDB(ctx, testNs, src, dst, filepath.Join(dir, "checkpoints"), nopDisplayHelper{})
+	require.Error(err, "MigrateNodeDB should fail verification when write logs are missing")
+	require.Contains(err.Error(), "version 2", "verification should check the older versions")
+}
+
+// newTestSourceNodeDB creates a badger node database populated with a few versions, the earliest
+// of which has been pruned, and returns it together with the retained roots.
+func newTestSourceNodeDB(t *testing.T, dir string, testNs common.Namespace, discardWriteLogs bool) (nodedb.NodeDB, []node.Root) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	src, err := NewNodeDB(BackendNameBadgerDB, &nodedb.Config{
+		DB:               filepath.Join(dir, DefaultFileName(BackendNameBadgerDB)),
+		NoFsync:          true,
+		Namespace:        testNs,
+		MaxCacheSize:     16 * 1024 * 1024,
+		DiscardWriteLogs: discardWriteLogs,
+	})
+	require.NoError(err, "NewNodeDB(src)")
+
+	// Populate the source node database with a few versions.
+	root := node.Root{
+		Namespace: testNs,
+		Type:      node.RootTypeState,
+	}
+	root.Hash.Empty()
+	var roots []node.Root
+	for version := uint64(0); version < numVersions; version++ {
+		tree := mkvs.NewWithRoot(nil, src, root)
+		for i := 0; i < 10; i++ {
+			err = tree.Insert(ctx, []byte(fmt.Sprintf("key %d %d", version, i)), []byte(fmt.Sprintf("value %d %d", version, i)))
+			require.NoError(err, "Insert")
+		}
+		if version > 0 {
+			err = tree.Remove(ctx, []byte(fmt.Sprintf("key %d 0", version-1)))
+			require.NoError(err, "Remove")
+		}
+		_, rootHash, err := tree.Commit(ctx, testNs, version)
+		require.NoError(err, "Commit")
+		tree.Close()
+
+		root.Version = version
+		root.Hash = rootHash
+		err = src.Finalize(ctx, []node.Root{root})
+		require.NoError(err, "Finalize")
+		roots = append(roots, root)
+	}
+
+	// Prune the earliest version so that the earliest retained version needs to be copied in full.
+	err = src.Prune(ctx, 0)
+	require.NoError(err, "Prune")
+
+	return src, roots[1:]
+}
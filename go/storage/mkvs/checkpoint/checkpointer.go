@@ -40,6 +40,11 @@ type CheckpointerConfig struct {
 	//
 	// This must return exactly RootsPerVersion roots.
 	GetRoots func(context.Context, uint64) ([]node.Root, error)
+
+	// PausePruning can be used to pause pruning of the node database while a checkpoint is being
+	// created. It is called with true before checkpoint creation starts and with false after it
+	// completes.
+	PausePruning func(pause bool)
 }
 
 // CreationParameters are the checkpoint creation parameters used by the checkpointer.
@@ -136,6 +141,12 @@ func (c *checkpointer) checkpoint(ctx context.Context, version uint64, params *C
 	// Notify watchers about the checkpoint we are about to make.
 	c.cpNotifier.Broadcast(version)
 
+	// Make sure the version is not pruned while the checkpoint is being created.
+	if c.cfg.PausePruning != nil {
+		c.cfg.PausePruning(true)
+		defer c.cfg.PausePruning(false)
+	}
+
 	var roots []node.Root
 	if c.cfg.GetRoots == nil {
 		roots, err = c.ndb.GetRootsForVersion(ctx, version)
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -70,6 +71,7 @@ func testCheckpointer(t *testing.T, earliestVersion, interval uint64, preExistin
 	require.NoError(err, "NewFileCreator")
 
 	// Create a checkpointer.
+	var pruningPaused, numPruningPauses int32
 	cp, err := NewCheckpointer(ctx, ndb, fc, CheckpointerConfig{
 		Name:            "test",
 		Namespace:       testNs,
@@ -88,6 +90,14 @@ func testCheckpointer(t *testing.T, earliestVersion, interval uint64, preExistin
 			}
 			return ndb.GetRootsForVersion(ctx, version)
 		},
+		PausePruning: func(pause bool) {
+			if pause {
+				atomic.AddInt32(&pruningPaused, 1)
+				atomic.AddInt32(&numPruningPauses, 1)
+			} else {
+				atomic.AddInt32(&pruningPaused, -1)
+			}
+		},
 	})
 	require.NoError(err, "NewCheckpointer")
 
@@ -138,6 +148,10 @@ func testCheckpointer(t *testing.T, earliestVersion, interval uint64, preExistin
 		}
 	}
 
+	// Pruning should have been paused during checkpoint creation and resumed afterwards.
+	require.NotZero(atomic.LoadInt32(&numPruningPauses), "pruning should be paused while checkpointing")
+	require.Zero(atomic.LoadInt32(&pruningPaused), "pruning should be resumed after checkpointing")
+
 	// Force a checkpoint at a version outside the regular interval.
 	if interval > 1 {
 		cpVersion := round - interval + 1
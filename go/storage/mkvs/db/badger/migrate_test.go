@@ -453,7 +453,7 @@ func TestBadgerV5KeyVersioning(t *testing.T) {
 	}
 
 	var h hash.Hash
-	var th1, th2 typedHash
+	var th1, th2 node.TypedHash
 	var v uint64
 
 	for it.Rewind(); it.Valid(); it.Next() {
@@ -498,7 +498,7 @@ func prettyPrintDBV5(ndb api.NodeDB) { // nolint: deadcode, unused
 	defer it.Close()
 
 	var h hash.Hash
-	var th1, th2 typedHash
+	var th1, th2 node.TypedHash
 	var v uint64
 
 	for it.Rewind(); it.Valid(); it.Next() {
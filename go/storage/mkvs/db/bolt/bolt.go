@@ -0,0 +1,1135 @@
+// Package bolt provides a bbolt-backed node database.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+const (
+	dbVersion = 1
+
+	// dbFileName is the name of the database file inside the database directory.
+	dbFileName = "mkvs.db"
+
+	// openTimeout is the amount of time to wait for the database file lock.
+	openTimeout = 1 * time.Second
+
+	// multipartVersionNone is the value used for the multipart version in metadata
+	// when no multipart restore is in progress.
+	multipartVersionNone uint64 = 0
+)
+
+// errNoMetadata is the error returned when the database does not contain any metadata.
+var errNoMetadata = errors.New("mkvs/bolt: no metadata")
+
+var (
+	// versionedBucketName is the name of the bucket holding versioned keys.
+	versionedBucketName = []byte("versioned")
+	// metaBucketName is the name of the bucket holding all other keys.
+	metaBucketName = []byte("meta")
+
+	// nodeKeyFmt is the key format for nodes (node hash). Keys are versioned.
+	//
+	// Value is serialized node.
+	nodeKeyFmt = keyformat.New(0x00, &hash.Hash{})
+	// writeLogKeyFmt is the key format for write logs (version, new root,
+	// old root).
+	//
+	// Value is CBOR-serialized write log.
+	writeLogKeyFmt = keyformat.New(0x01, uint64(0), &node.TypedHash{}, &node.TypedHash{})
+	// rootsMetadataKeyFmt is the key format for roots metadata. The key format is (version).
+	//
+	// Value is CBOR-serialized rootsMetadata.
+	rootsMetadataKeyFmt = keyformat.New(0x02, uint64(0))
+	// rootUpdatedNodesKeyFmt is the key format for the pending updated nodes for the
+	// given root that need to be removed only in case the given root is not among
+	// the finalized roots. They key format is (version, root).
+	//
+	// Value is CBOR-serialized []updatedNode.
+	rootUpdatedNodesKeyFmt = keyformat.New(0x03, uint64(0), &node.TypedHash{})
+	// metadataKeyFmt is the key format for metadata.
+	//
+	// Value is CBOR-serialized metadata.
+	metadataKeyFmt = keyformat.New(0x04)
+	// multipartRestoreNodeLogKeyFmt is the key format for the nodes inserted during a chunk restore.
+	// Once a set of chunks is fully restored, these entries should be removed. If chunk restoration
+	// is interrupted for any reason, the nodes associated with these keys should be removed, along
+	// with these entries.
+	//
+	// Value is empty.
+	multipartRestoreNodeLogKeyFmt = keyformat.New(0x05, &node.TypedHash{})
+	// rootNodeKeyFmt is the key format for root nodes (typed node hash). Keys are versioned.
+	//
+	// Value is empty.
+	rootNodeKeyFmt = keyformat.New(0x06, &node.TypedHash{})
+	// gcLogKeyFmt is the key format for the log of versioned key updates (version, key). Once
+	// the version becomes the earliest version, any superseded entries of the key are removed.
+	//
+	// Value is empty.
+	gcLogKeyFmt = keyformat.New(0x07, uint64(0), []byte{})
+)
+
+// New creates a new bbolt-backed node database.
+func New(cfg *api.Config) (api.NodeDB, error) {
+	db := &boltNodeDB{
+		logger:           logging.GetLogger("mkvs/db/bolt"),
+		namespace:        cfg.Namespace,
+		readOnly:         cfg.ReadOnly,
+		discardWriteLogs: cfg.DiscardWriteLogs,
+	}
+
+	dir := cfg.DB
+	if cfg.MemoryOnly {
+		// bbolt does not support memory-only databases, so use a temporary directory that is
+		// removed when the database is closed.
+		db.logger.Warn("using memory-only mode, data will not be persisted")
+
+		var err error
+		if dir, err = ioutil.TempDir("", "oasis-mkvs-bolt"); err != nil {
+			return nil, fmt.Errorf("mkvs/bolt: failed to create temporary directory: %w", err)
+		}
+		db.tempDir = dir
+	}
+	if !cfg.ReadOnly {
+		if err := common.Mkdir(dir); err != nil {
+			db.cleanupTempDir()
+			return nil, fmt.Errorf("mkvs/bolt: failed to create database directory: %w", err)
+		}
+	}
+
+	var err error
+	if db.db, err = bbolt.Open(filepath.Join(dir, dbFileName), 0o600, &bbolt.Options{
+		Timeout:        openTimeout,
+		ReadOnly:       cfg.ReadOnly,
+		NoFreelistSync: true,
+		FreelistType:   bbolt.FreelistMapType,
+	}); err != nil {
+		db.cleanupTempDir()
+		return nil, fmt.Errorf("mkvs/bolt: failed to open database: %w", err)
+	}
+	db.db.NoSync = cfg.NoFsync
+
+	// Load database metadata.
+	if err = db.load(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("mkvs/bolt: failed to load metadata: %w", err)
+	}
+
+	// Cleanup any multipart restore remnants, since they can't be used anymore.
+	if !db.readOnly {
+		if err = db.cleanMultipartLocked(true); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("mkvs/bolt: failed to clean leftovers from multipart restore: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+type boltNodeDB struct { // nolint: maligned
+	logger *logging.Logger
+
+	namespace common.Namespace
+
+	readOnly         bool
+	discardWriteLogs bool
+
+	multipartVersion uint64
+
+	db      *bbolt.DB
+	tempDir string
+
+	// metaUpdateLock must be held at any point where metadata is read and updated.
+	metaUpdateLock sync.Mutex
+	meta           metadata
+
+	closeOnce sync.Once
+}
+
+func (d *boltNodeDB) load() error {
+	if d.readOnly {
+		return d.db.View(d.loadMetadata)
+	}
+
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{versionedBucketName, metaBucketName} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		err := d.loadMetadata(tx)
+		switch err {
+		case nil:
+			return nil
+		case errNoMetadata:
+		default:
+			return err
+		}
+
+		// No metadata exists, create some.
+		d.meta.value.Version = dbVersion
+		d.meta.value.Namespace = d.namespace
+		return d.meta.save(tx)
+	})
+}
+
+func (d *boltNodeDB) loadMetadata(tx *bbolt.Tx) error {
+	meta := tx.Bucket(metaBucketName)
+	if meta == nil || tx.Bucket(versionedBucketName) == nil {
+		return errNoMetadata
+	}
+	data := meta.Get(metadataKeyFmt.Encode())
+	if data == nil {
+		return errNoMetadata
+	}
+
+	// Metadata already exists, just load it and verify that it is
+	// compatible with what we have here.
+	if err := cbor.UnmarshalTrusted(data, &d.meta.value); err != nil {
+		return err
+	}
+
+	if d.meta.value.Version != dbVersion {
+		return fmt.Errorf("incompatible database version (expected: %d got: %d)",
+			dbVersion,
+			d.meta.value.Version,
+		)
+	}
+	if !d.meta.value.Namespace.Equal(&d.namespace) {
+		return fmt.Errorf("incompatible namespace (expected: %s got: %s)",
+			d.namespace,
+			d.meta.value.Namespace,
+		)
+	}
+	return nil
+}
+
+func (d *boltNodeDB) sanityCheckNamespace(ns common.Namespace) error {
+	if !ns.Equal(&d.namespace) {
+		return api.ErrBadNamespace
+	}
+	return nil
+}
+
+func (d *boltNodeDB) checkRoot(tx *bbolt.Tx, root node.Root) error {
+	rootHash := node.TypedHashFromRoot(root)
+	if value, _ := versionedGet(tx, rootNodeKeyFmt.Encode(&rootHash), root.Version); value == nil {
+		return api.ErrRootNotFound
+	}
+	return nil
+}
+
+// Assumes metaUpdateLock is held when called.
+func (d *boltNodeDB) cleanMultipartLocked(removeNodes bool) error {
+	var version uint64
+
+	if d.multipartVersion != multipartVersionNone {
+		version = d.multipartVersion
+	} else {
+		version = d.meta.getMultipartVersion()
+	}
+	if version == multipartVersionNone {
+		// No multipart in progress, but it's not an error to call in a situation like this.
+		return nil
+	}
+
+	if err := d.db.Update(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket(metaBucketName)
+
+		var logKeys [][]byte
+		cur := meta.Cursor()
+		prefix := multipartRestoreNodeLogKeyFmt.Encode()
+		for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			logKeys = append(logKeys, append([]byte{}, k...))
+		}
+
+		if removeNodes && len(logKeys) > 0 {
+			d.logger.Info("removing some nodes from a multipart restore")
+		}
+		for _, key := range logKeys {
+			if removeNodes {
+				var hash node.TypedHash
+				if !multipartRestoreNodeLogKeyFmt.Decode(key, &hash) {
+					panic("mkvs/bolt: bad iterator")
+				}
+				switch hash.Type() {
+				case node.RootTypeInvalid:
+					h := hash.Hash()
+					if err := versionedDelete(tx, nodeKeyFmt.Encode(&h), version); err != nil {
+						return err
+					}
+				default:
+					if err := versionedDelete(tx, rootNodeKeyFmt.Encode(&hash), version); err != nil {
+						return err
+					}
+				}
+			}
+			if err := meta.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return d.meta.setMultipartVersion(tx, multipartVersionNone)
+	}); err != nil {
+		return err
+	}
+
+	d.multipartVersion = multipartVersionNone
+	return nil
+}
+
+func (d *boltNodeDB) GetNode(root node.Root, ptr *node.Pointer) (node.Node, error) {
+	if ptr == nil || !ptr.IsClean() {
+		panic("mkvs/bolt: attempted to get invalid pointer from node database")
+	}
+	if err := d.sanityCheckNamespace(root.Namespace); err != nil {
+		return nil, err
+	}
+	// If the version is earlier than the earliest version, we don't have the node (it was pruned).
+	if root.Version < d.meta.getEarliestVersion() {
+		return nil, api.ErrNodeNotFound
+	}
+
+	var n node.Node
+	if err := d.db.View(func(tx *bbolt.Tx) error {
+		// Check if the root actually exists.
+		if err := d.checkRoot(tx, root); err != nil {
+			return err
+		}
+
+		data, _ := versionedGet(tx, nodeKeyFmt.Encode(&ptr.Hash), root.Version)
+		if data == nil {
+			return api.ErrNodeNotFound
+		}
+
+		var err error
+		if n, err = node.UnmarshalBinary(data); err != nil {
+			d.logger.Error("failed to unmarshal node",
+				"err", err,
+			)
+			return fmt.Errorf("mkvs/bolt: failed to unmarshal node: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+func (d *boltNodeDB) GetWriteLog(ctx context.Context, startRoot, endRoot node.Root) (writelog.Iterator, error) {
+	if d.discardWriteLogs {
+		return nil, api.ErrWriteLogNotFound
+	}
+	if !endRoot.Follows(&startRoot) {
+		return nil, api.ErrRootMustFollowOld
+	}
+	if err := d.sanityCheckNamespace(startRoot.Namespace); err != nil {
+		return nil, err
+	}
+	// If the version is earlier than the earliest version, we don't have the roots.
+	if endRoot.Version < d.meta.getEarliestVersion() {
+		return nil, api.ErrWriteLogNotFound
+	}
+
+	// Start at the end root and search towards the start root. This assumes that the
+	// chains are not long and that there is not a lot of forks as in that case performance
+	// would suffer.
+	//
+	// In reality the two common cases are:
+	// - State updates: s -> s' (a single hop)
+	// - I/O updates: empty -> i -> io (two hops)
+	//
+	// For this reason, we currently refuse to traverse more than two hops.
+	const maxAllowedHops = 2
+
+	type wlItem struct {
+		depth       uint8
+		endRootHash node.TypedHash
+		logKeys     [][]byte
+		logRoots    []node.TypedHash
+	}
+
+	var (
+		logs     []api.HashedDBWriteLog
+		logRoots []node.TypedHash
+	)
+	if err := d.db.View(func(tx *bbolt.Tx) error {
+		// Check if the root actually exists.
+		if err := d.checkRoot(tx, endRoot); err != nil {
+			return err
+		}
+
+		meta := tx.Bucket(metaBucketName)
+
+		// NOTE: We could use a proper deque, but as long as we keep the number of hops and
+		//       forks low, this should not be a problem.
+		queue := []*wlItem{{depth: 0, endRootHash: node.TypedHashFromRoot(endRoot)}}
+		startRootHash := node.TypedHashFromRoot(startRoot)
+		for len(queue) > 0 {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			curItem := queue[0]
+			queue = queue[1:]
+
+			// Iterate over all write logs that result in the current item.
+			prefix := writeLogKeyFmt.Encode(endRoot.Version, &curItem.endRootHash)
+			cur := meta.Cursor()
+			for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				var decVersion uint64
+				var decEndRootHash node.TypedHash
+				var decStartRootHash node.TypedHash
+
+				if !writeLogKeyFmt.Decode(k, &decVersion, &decEndRootHash, &decStartRootHash) {
+					panic("mkvs/bolt: bad iterator")
+				}
+
+				nextItem := wlItem{
+					depth:       curItem.depth + 1,
+					endRootHash: decStartRootHash,
+					// Only store log keys to avoid keeping everything in memory while
+					// we are searching for the right path.
+					logKeys:  append(append([][]byte{}, curItem.logKeys...), append([]byte{}, k...)),
+					logRoots: append(append([]node.TypedHash{}, curItem.logRoots...), curItem.endRootHash),
+				}
+				if nextItem.endRootHash.Equal(&startRootHash) {
+					// Path has been found, deserialize write logs as they can only be accessed
+					// while the transaction is open.
+					for _, key := range nextItem.logKeys {
+						var log api.HashedDBWriteLog
+						if err := cbor.UnmarshalTrusted(meta.Get(key), &log); err != nil {
+							return err
+						}
+						logs = append(logs, log)
+					}
+					logRoots = nextItem.logRoots
+					return nil
+				}
+
+				if nextItem.depth < maxAllowedHops {
+					queue = append(queue, &nextItem)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		return nil, api.ErrWriteLogNotFound
+	}
+
+	// Stream write logs.
+	var index int
+	return api.ReviveHashedDBWriteLogs(ctx,
+		func() (node.Root, api.HashedDBWriteLog, error) {
+			if index >= len(logs) {
+				return node.Root{}, nil, nil
+			}
+
+			root := node.Root{
+				Namespace: endRoot.Namespace,
+				Version:   endRoot.Version,
+				Type:      logRoots[index].Type(),
+				Hash:      logRoots[index].Hash(),
+			}
+			log := logs[index]
+			if log == nil {
+				// Make sure that an empty write log is not mistaken for the end of the stream.
+				log = api.HashedDBWriteLog{}
+			}
+
+			index++
+			return root, log, nil
+		},
+		func(root node.Root, h hash.Hash) (*node.LeafNode, error) {
+			leaf, err := d.GetNode(root, &node.Pointer{Hash: h, Clean: true})
+			if err != nil {
+				return nil, err
+			}
+			return leaf.(*node.LeafNode), nil
+		},
+		func() {},
+	)
+}
+
+func (d *boltNodeDB) GetLatestVersion(ctx context.Context) (uint64, error) {
+	version, _ := d.meta.getLastFinalizedVersion()
+	return version, nil
+}
+
+func (d *boltNodeDB) GetEarliestVersion(ctx context.Context) (uint64, error) {
+	return d.meta.getEarliestVersion(), nil
+}
+
+func (d *boltNodeDB) GetRootsForVersion(ctx context.Context, version uint64) (roots []node.Root, err error) {
+	// If the version is earlier than the earliest version, we don't have the roots.
+	if version < d.meta.getEarliestVersion() {
+		return nil, nil
+	}
+
+	var rootsMeta *rootsMetadata
+	if err = d.db.View(func(tx *bbolt.Tx) (vErr error) {
+		rootsMeta, vErr = loadRootsMetadata(tx, version)
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	for rootHash := range rootsMeta.Roots {
+		roots = append(roots, node.Root{
+			Namespace: d.namespace,
+			Version:   version,
+			Type:      rootHash.Type(),
+			Hash:      rootHash.Hash(),
+		})
+	}
+	return
+}
+
+func (d *boltNodeDB) HasRoot(root node.Root) bool {
+	if err := d.sanityCheckNamespace(root.Namespace); err != nil {
+		return false
+	}
+
+	// An empty root is always implicitly present.
+	if root.Hash.IsEmpty() {
+		return true
+	}
+
+	// If the version is earlier than the earliest version, we don't have the root.
+	if root.Version < d.meta.getEarliestVersion() {
+		return false
+	}
+
+	var rootsMeta *rootsMetadata
+	if err := d.db.View(func(tx *bbolt.Tx) (vErr error) {
+		rootsMeta, vErr = loadRootsMetadata(tx, root.Version)
+		return
+	}); err != nil {
+		panic(err)
+	}
+
+	_, exists := rootsMeta.Roots[node.TypedHashFromRoot(root)]
+	return exists
+}
+
+func (d *boltNodeDB) Finalize(ctx context.Context, roots []node.Root) error { // nolint: gocyclo
+	if d.readOnly {
+		return api.ErrReadOnly
+	}
+
+	if len(roots) == 0 {
+		return fmt.Errorf("mkvs/bolt: need at least one root to finalize")
+	}
+	version := roots[0].Version
+
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+
+	if d.multipartVersion != multipartVersionNone && d.multipartVersion != version {
+		return api.ErrInvalidMultipartVersion
+	}
+
+	// Make sure that the previous version has been finalized (if we are not restoring).
+	lastFinalizedVersion, exists := d.meta.getLastFinalizedVersion()
+	if d.multipartVersion == multipartVersionNone && version > 0 && exists && lastFinalizedVersion < (version-1) {
+		return api.ErrNotFinalized
+	}
+	// Make sure that this version has not yet been finalized.
+	if exists && version <= lastFinalizedVersion {
+		return api.ErrAlreadyFinalized
+	}
+
+	// Determine the set of finalized roots. Finalization is transitive, so if
+	// a parent root is finalized the child should be considered finalized too.
+	finalizedRoots := make(map[node.TypedHash]bool)
+	for _, root := range roots {
+		if root.Version != version {
+			return fmt.Errorf("mkvs/bolt: roots to finalize don't have matching versions")
+		}
+		finalizedRoots[node.TypedHashFromRoot(root)] = true
+	}
+
+	if err := d.db.Update(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket(metaBucketName)
+
+		var rootsChanged bool
+		rootsMeta, err := loadRootsMetadata(tx, version)
+		if err != nil {
+			return err
+		}
+
+		for updated := true; updated; {
+			updated = false
+
+			for rootHash, derivedRoots := range rootsMeta.Roots {
+				if len(derivedRoots) == 0 {
+					continue
+				}
+
+				for _, nextRoot := range derivedRoots {
+					if !finalizedRoots[rootHash] && finalizedRoots[nextRoot] {
+						finalizedRoots[rootHash] = true
+						updated = true
+					}
+				}
+			}
+		}
+
+		// Sanity check the input roots list.
+		for iroot := range finalizedRoots {
+			h := iroot.Hash()
+			if _, ok := rootsMeta.Roots[iroot]; !ok && !h.IsEmpty() {
+				return api.ErrRootNotFound
+			}
+		}
+
+		// Go through all roots and prune them based on whether they are finalized or not.
+		maybeLoneNodes := make(map[hash.Hash]bool)
+		notLoneNodes := make(map[hash.Hash]bool)
+
+		for rootHash := range rootsMeta.Roots {
+			rootUpdatedNodesKey := rootUpdatedNodesKeyFmt.Encode(version, &rootHash)
+
+			// Load hashes of nodes added during this version for this root.
+			data := meta.Get(rootUpdatedNodesKey)
+			if data == nil {
+				panic(fmt.Errorf("mkvs/bolt: missing root updated nodes index"))
+			}
+
+			var updatedNodes []updatedNode
+			if err = cbor.UnmarshalTrusted(data, &updatedNodes); err != nil {
+				panic(fmt.Errorf("mkvs/bolt: corrupted root updated nodes index: %w", err))
+			}
+
+			if finalizedRoots[rootHash] {
+				// Make sure not to remove any nodes shared with finalized roots.
+				for _, n := range updatedNodes {
+					if n.Removed {
+						maybeLoneNodes[n.Hash] = true
+					} else {
+						notLoneNodes[n.Hash] = true
+					}
+				}
+			} else {
+				// Remove any non-finalized roots. It is safe to remove these nodes as versioned
+				// keys make sure they are not removed if they are resurrected in any later
+				// version as long as we make sure that these nodes are not shared with any
+				// finalized roots added in the same version.
+				for _, n := range updatedNodes {
+					if !n.Removed {
+						maybeLoneNodes[n.Hash] = true
+					}
+				}
+
+				delete(rootsMeta.Roots, rootHash)
+				rootsChanged = true
+
+				// Remove write logs for the non-finalized root.
+				if !d.discardWriteLogs {
+					if err = deletePrefix(meta, writeLogKeyFmt.Encode(version, &rootHash)); err != nil {
+						return err
+					}
+				}
+			}
+
+			// Set of updated nodes no longer needed after finalization.
+			if err = meta.Delete(rootUpdatedNodesKey); err != nil {
+				return err
+			}
+		}
+
+		// Clean any lone nodes.
+		for h := range maybeLoneNodes {
+			if notLoneNodes[h] {
+				continue
+			}
+
+			if err = versionedDelete(tx, nodeKeyFmt.Encode(&h), version); err != nil {
+				return err
+			}
+		}
+
+		// Save roots metadata if changed.
+		if rootsChanged {
+			if err = rootsMeta.save(tx); err != nil {
+				return fmt.Errorf("mkvs/bolt: failed to save roots metadata: %w", err)
+			}
+		}
+
+		// Update last finalized version.
+		if err = d.meta.setLastFinalizedVersion(tx, version); err != nil {
+			return fmt.Errorf("mkvs/bolt: failed to set last finalized version: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Clean multipart metadata if there is any.
+	if d.multipartVersion != multipartVersionNone {
+		if err := d.cleanMultipartLocked(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *boltNodeDB) Prune(ctx context.Context, version uint64) error {
+	if d.readOnly {
+		return api.ErrReadOnly
+	}
+
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+
+	if d.multipartVersion != multipartVersionNone {
+		return api.ErrMultipartInProgress
+	}
+
+	// Make sure that the version that we try to prune has been finalized.
+	lastFinalizedVersion, exists := d.meta.getLastFinalizedVersion()
+	if !exists || lastFinalizedVersion < version {
+		return api.ErrNotFinalized
+	}
+	// Make sure that the version that we are trying to prune is the earliest version.
+	if version != d.meta.getEarliestVersion() {
+		return api.ErrNotEarliest
+	}
+
+	var rootsMeta *rootsMetadata
+	if err := d.db.View(func(tx *bbolt.Tx) (vErr error) {
+		rootsMeta, vErr = loadRootsMetadata(tx, version)
+		return
+	}); err != nil {
+		return err
+	}
+
+	// Collect all nodes of lone roots in version. This needs to happen before the write
+	// transaction is opened as traversal uses separate read transactions.
+	loneRoots := make([]node.TypedHash, 0, len(rootsMeta.Roots))
+	visitedNodes := make(map[hash.Hash]bool)
+	for rootHash, derivedRoots := range rootsMeta.Roots {
+		if len(derivedRoots) > 0 {
+			// Not a lone root.
+			continue
+		}
+		loneRoots = append(loneRoots, rootHash)
+
+		root := node.Root{
+			Namespace: d.namespace,
+			Version:   version,
+			Type:      rootHash.Type(),
+			Hash:      rootHash.Hash(),
+		}
+		if err := api.Visit(ctx, d, root, func(ctx context.Context, n node.Node) bool {
+			visitedNodes[n.GetHash()] = true
+			return true
+		}); err != nil {
+			return err
+		}
+	}
+
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		// Prune all nodes of lone roots created in this version.
+		for h := range visitedNodes {
+			nodeKey := nodeKeyFmt.Encode(&h)
+			value, nodeVersion := versionedGet(tx, nodeKey, version)
+			if value == nil {
+				return api.ErrNodeNotFound
+			}
+
+			if nodeVersion == version {
+				if err := versionedDelete(tx, nodeKey, version); err != nil {
+					return err
+				}
+			}
+		}
+		for _, rootHash := range loneRoots {
+			if err := versionedDelete(tx, rootNodeKeyFmt.Encode(&rootHash), version); err != nil {
+				return err
+			}
+		}
+
+		meta := tx.Bucket(metaBucketName)
+
+		// Delete roots metadata.
+		if err := meta.Delete(rootsMetadataKeyFmt.Encode(version)); err != nil {
+			return fmt.Errorf("mkvs/bolt: failed to remove roots metadata: %w", err)
+		}
+
+		// Prune all write logs in version.
+		if !d.discardWriteLogs {
+			if err := deletePrefix(meta, writeLogKeyFmt.Encode(version)); err != nil {
+				return err
+			}
+		}
+
+		// Update metadata.
+		if err := d.meta.setEarliestVersion(tx, version+1); err != nil {
+			return fmt.Errorf("mkvs/bolt: failed to set earliest version: %w", err)
+		}
+
+		// Discard everything invalidated at or below given version.
+		if err := collectGarbage(tx, version+1); err != nil {
+			return fmt.Errorf("mkvs/bolt: failed to collect garbage: %w", err)
+		}
+		return nil
+	})
+}
+
+func (d *boltNodeDB) StartMultipartInsert(version uint64) error {
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+
+	if version == multipartVersionNone {
+		return api.ErrInvalidMultipartVersion
+	}
+
+	if d.multipartVersion != multipartVersionNone {
+		if d.multipartVersion != version {
+			return api.ErrMultipartInProgress
+		}
+		// Multipart already initialized at the same version, so this was
+		// probably called e.g. as part of a further checkpoint restore.
+		return nil
+	}
+
+	if err := d.db.Update(func(tx *bbolt.Tx) error {
+		return d.meta.setMultipartVersion(tx, version)
+	}); err != nil {
+		return err
+	}
+
+	d.multipartVersion = version
+
+	return nil
+}
+
+func (d *boltNodeDB) AbortMultipartInsert() error {
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+
+	return d.cleanMultipartLocked(true)
+}
+
+func (d *boltNodeDB) NewBatch(oldRoot node.Root, version uint64, chunk bool) (api.Batch, error) {
+	if d.readOnly {
+		return nil, api.ErrReadOnly
+	}
+
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+
+	if d.multipartVersion != multipartVersionNone && d.multipartVersion != version {
+		return nil, api.ErrInvalidMultipartVersion
+	}
+	if chunk != (d.multipartVersion != multipartVersionNone) {
+		return nil, api.ErrMultipartInProgress
+	}
+
+	return &boltBatch{
+		db:      d,
+		oldRoot: oldRoot,
+		chunk:   chunk,
+	}, nil
+}
+
+func (d *boltNodeDB) Size() (size int64, err error) {
+	// Report the space used by data as the database file only grows in large increments and
+	// never shrinks.
+	err = d.db.View(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{versionedBucketName, metaBucketName} {
+			stats := tx.Bucket(name).Stats()
+			size += int64(stats.BranchInuse + stats.LeafInuse + stats.InlineBucketInuse)
+		}
+		return nil
+	})
+	return
+}
+
+func (d *boltNodeDB) Sync() error {
+	return d.db.Sync()
+}
+
+func (d *boltNodeDB) Close() {
+	d.closeOnce.Do(func() {
+		if err := d.db.Close(); err != nil {
+			d.logger.Error("close returned error",
+				"err", err,
+			)
+		}
+		d.cleanupTempDir()
+	})
+}
+
+func (d *boltNodeDB) cleanupTempDir() {
+	if d.tempDir == "" {
+		return
+	}
+	if err := os.RemoveAll(d.tempDir); err != nil {
+		d.logger.Error("failed to remove temporary directory",
+			"err", err,
+			"dir", d.tempDir,
+		)
+	}
+}
+
+// deletePrefix removes all keys with the given prefix from the given bucket.
+func deletePrefix(b *bbolt.Bucket, prefix []byte) error {
+	var keys [][]byte
+	cur := b.Cursor()
+	for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pendingNode struct {
+	hash hash.Hash
+	data []byte
+}
+
+type boltBatch struct {
+	api.BaseBatch
+
+	db *boltNodeDB
+
+	oldRoot node.Root
+	chunk   bool
+
+	nodes        []pendingNode
+	writeLog     writelog.WriteLog
+	annotations  writelog.Annotations
+	updatedNodes []updatedNode
+}
+
+func (ba *boltBatch) MaybeStartSubtree(subtree api.Subtree, depth node.Depth, subtreeRoot *node.Pointer) api.Subtree {
+	if subtree == nil {
+		return &boltSubtree{batch: ba}
+	}
+	return subtree
+}
+
+func (ba *boltBatch) PutWriteLog(writeLog writelog.WriteLog, annotations writelog.Annotations) error {
+	if ba.chunk {
+		return fmt.Errorf("mkvs/bolt: cannot put write log in chunk mode")
+	}
+	if ba.db.discardWriteLogs {
+		return nil
+	}
+
+	ba.writeLog = writeLog
+	ba.annotations = annotations
+	return nil
+}
+
+func (ba *boltBatch) RemoveNodes(nodes []node.Node) error {
+	if ba.chunk {
+		return fmt.Errorf("mkvs/bolt: cannot remove nodes in chunk mode")
+	}
+
+	for _, n := range nodes {
+		ba.updatedNodes = append(ba.updatedNodes, updatedNode{
+			Removed: true,
+			Hash:    n.GetHash(),
+		})
+	}
+	return nil
+}
+
+func (ba *boltBatch) Commit(root node.Root) error {
+	ba.db.metaUpdateLock.Lock()
+	defer ba.db.metaUpdateLock.Unlock()
+
+	if ba.db.multipartVersion != multipartVersionNone && ba.db.multipartVersion != root.Version {
+		return api.ErrInvalidMultipartVersion
+	}
+
+	if err := ba.db.sanityCheckNamespace(root.Namespace); err != nil {
+		return err
+	}
+	if !root.Follows(&ba.oldRoot) {
+		return api.ErrRootMustFollowOld
+	}
+
+	// Make sure that the version that we try to commit into has not yet been finalized.
+	lastFinalizedVersion, exists := ba.db.meta.getLastFinalizedVersion()
+	if exists && lastFinalizedVersion >= root.Version {
+		return api.ErrAlreadyFinalized
+	}
+
+	if err := ba.db.db.Update(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket(metaBucketName)
+		multipart := ba.db.multipartVersion != multipartVersionNone
+
+		rootsMeta, err := loadRootsMetadata(tx, root.Version)
+		if err != nil {
+			return err
+		}
+
+		rootHash := node.TypedHashFromRoot(root)
+		if rootsMeta.Roots[rootHash] != nil {
+			// Root already exists, no need to do anything since if the hash matches, everything
+			// will be identical and we would just be duplicating work.
+			//
+			// If we are importing a chunk, there can be multiple commits for the same root.
+			if !ba.chunk {
+				return nil
+			}
+		} else {
+			// Create root with no derived roots.
+			rootsMeta.Roots[rootHash] = []node.TypedHash{}
+
+			if err = rootsMeta.save(tx); err != nil {
+				return fmt.Errorf("mkvs/bolt: failed to save roots metadata: %w", err)
+			}
+		}
+
+		if err = versionedPut(tx, rootNodeKeyFmt.Encode(&rootHash), root.Version, []byte{}); err != nil {
+			return err
+		}
+		if multipart {
+			if err = meta.Put(multipartRestoreNodeLogKeyFmt.Encode(&rootHash), []byte{}); err != nil {
+				return err
+			}
+		}
+
+		// Store nodes. Nodes are stored in key order as that is much more efficient when
+		// inserting many keys in a single transaction.
+		sort.Slice(ba.nodes, func(i, j int) bool {
+			return bytes.Compare(ba.nodes[i].hash[:], ba.nodes[j].hash[:]) < 0
+		})
+		for _, n := range ba.nodes {
+			nodeKey := nodeKeyFmt.Encode(&n.hash)
+			if multipart {
+				if value, _ := versionedGet(tx, nodeKey, root.Version); value == nil {
+					th := node.TypedHashFromParts(node.RootTypeInvalid, n.hash)
+					if err = meta.Put(multipartRestoreNodeLogKeyFmt.Encode(&th), []byte{}); err != nil {
+						return err
+					}
+				}
+			}
+			if err = versionedPut(tx, nodeKey, root.Version, n.data); err != nil {
+				return err
+			}
+		}
+
+		if ba.chunk {
+			// Skip most of metadata updates if we are just importing chunks.
+			key := rootUpdatedNodesKeyFmt.Encode(root.Version, &rootHash)
+			if err = meta.Put(key, cbor.Marshal([]updatedNode{})); err != nil {
+				return fmt.Errorf("mkvs/bolt: set returned error: %w", err)
+			}
+			return nil
+		}
+
+		// Update the root link for the old root.
+		oldRootHash := node.TypedHashFromRoot(ba.oldRoot)
+		if !ba.oldRoot.Hash.IsEmpty() {
+			if ba.oldRoot.Version < ba.db.meta.getEarliestVersion() && ba.oldRoot.Version != root.Version {
+				return api.ErrPreviousVersionMismatch
+			}
+
+			var oldRootsMeta *rootsMetadata
+			oldRootsMeta, err = loadRootsMetadata(tx, ba.oldRoot.Version)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := oldRootsMeta.Roots[oldRootHash]; !ok {
+				return api.ErrRootNotFound
+			}
+
+			oldRootsMeta.Roots[oldRootHash] = append(oldRootsMeta.Roots[oldRootHash], rootHash)
+			if err = oldRootsMeta.save(tx); err != nil {
+				return fmt.Errorf("mkvs/bolt: failed to save old roots metadata: %w", err)
+			}
+		}
+
+		// Store updated nodes (only needed until the version is finalized).
+		key := rootUpdatedNodesKeyFmt.Encode(root.Version, &rootHash)
+		if err = meta.Put(key, cbor.Marshal(ba.updatedNodes)); err != nil {
+			return fmt.Errorf("mkvs/bolt: set returned error: %w", err)
+		}
+
+		// Store write log.
+		if ba.writeLog != nil && ba.annotations != nil {
+			log := api.MakeHashedDBWriteLog(ba.writeLog, ba.annotations)
+			key := writeLogKeyFmt.Encode(root.Version, &rootHash, &oldRootHash)
+			if err = meta.Put(key, cbor.Marshal(log)); err != nil {
+				return fmt.Errorf("mkvs/bolt: set new write log returned error: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	ba.Reset()
+	return ba.BaseBatch.Commit(root)
+}
+
+func (ba *boltBatch) Reset() {
+	ba.nodes = nil
+	ba.writeLog = nil
+	ba.annotations = nil
+	ba.updatedNodes = nil
+}
+
+type boltSubtree struct {
+	batch *boltBatch
+}
+
+func (s *boltSubtree) PutNode(depth node.Depth, ptr *node.Pointer) error {
+	data, err := ptr.Node.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	h := ptr.Node.GetHash()
+	s.batch.updatedNodes = append(s.batch.updatedNodes, updatedNode{Hash: h})
+	s.batch.nodes = append(s.batch.nodes, pendingNode{hash: h, data: data})
+	return nil
+}
+
+func (s *boltSubtree) VisitCleanNode(depth node.Depth, ptr *node.Pointer) error {
+	return nil
+}
+
+func (s *boltSubtree) Commit() error {
+	return nil
+}
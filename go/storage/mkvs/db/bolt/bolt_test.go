@@ -0,0 +1,182 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/tests"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+var (
+	nodePrefix = nodeKeyFmt.Encode()
+
+	logPrefix = multipartRestoreNodeLogKeyFmt.Encode()
+
+	testNs = common.NewTestNamespaceFromSeed([]byte("bolt node db test ns"), 0)
+)
+
+type testBackend struct{}
+
+func (b *testBackend) New(cfg *api.Config) (api.NodeDB, error) {
+	return New(cfg)
+}
+
+func (b *testBackend) NodeKeys(t *testing.T, ndb api.NodeDB) tests.KeySet {
+	return liveNodeKeys(require.New(t), ndb.(*boltNodeDB))
+}
+
+func (b *testBackend) MultipartLogKeys(t *testing.T, ndb api.NodeDB) tests.KeySet {
+	keys := tests.KeySet{}
+	err := ndb.(*boltNodeDB).db.View(func(tx *bbolt.Tx) error {
+		cur := tx.Bucket(metaBucketName).Cursor()
+		for k, _ := cur.Seek(logPrefix); k != nil && bytes.HasPrefix(k, logPrefix); k, _ = cur.Next() {
+			keys[string(k)] = struct{}{}
+		}
+		return nil
+	})
+	require.NoError(t, err, "View")
+	return keys
+}
+
+func TestBoltNodeDB(t *testing.T) {
+	tests.NodeDBImplementationTests(t, &testBackend{})
+}
+
+func countKeys(require *require.Assertions, db *boltNodeDB, bucket, prefix []byte) (count int) {
+	err := db.db.View(func(tx *bbolt.Tx) error {
+		cur := tx.Bucket(bucket).Cursor()
+		for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+			count++
+		}
+		return nil
+	})
+	require.NoError(err, "View")
+	return
+}
+
+func TestPruneCollectsGarbage(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "mkvs.test.bolt")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	ndb, err := New(&api.Config{
+		DB:        dir,
+		NoFsync:   true,
+		Namespace: testNs,
+	})
+	require.NoError(err, "New")
+	defer ndb.Close()
+	db := ndb.(*boltNodeDB)
+
+	tree := mkvs.New(nil, ndb, node.RootTypeState)
+	for i := 0; i < 10; i++ {
+		err = tree.Insert(ctx, []byte(fmt.Sprintf("key %d", i)), []byte(fmt.Sprintf("value %d", i)))
+		require.NoError(err, "Insert")
+	}
+	_, rootHash0, err := tree.Commit(ctx, testNs, 0)
+	require.NoError(err, "Commit")
+	root0 := node.Root{Namespace: testNs, Version: 0, Type: node.RootTypeState, Hash: rootHash0}
+	err = ndb.Finalize(ctx, []node.Root{root0})
+	require.NoError(err, "Finalize")
+	tree.Close()
+
+	tree = mkvs.NewWithRoot(nil, ndb, root0)
+	for i := 0; i < 5; i++ {
+		err = tree.Remove(ctx, []byte(fmt.Sprintf("key %d", i)))
+		require.NoError(err, "Remove")
+	}
+	err = tree.Insert(ctx, []byte("key 10"), []byte("value 10"))
+	require.NoError(err, "Insert")
+	_, rootHash1, err := tree.Commit(ctx, testNs, 1)
+	require.NoError(err, "Commit")
+	root1 := node.Root{Namespace: testNs, Version: 1, Type: node.RootTypeState, Hash: rootHash1}
+	err = ndb.Finalize(ctx, []node.Root{root1})
+	require.NoError(err, "Finalize")
+	tree.Close()
+
+	err = ndb.Prune(ctx, 0)
+	require.NoError(err, "Prune")
+
+	// Only the nodes reachable from the remaining root should be left.
+	reachable := make(map[hash.Hash]bool)
+	err = api.Visit(ctx, ndb, root1, func(ctx context.Context, n node.Node) bool {
+		reachable[n.GetHash()] = true
+		return true
+	})
+	require.NoError(err, "Visit")
+	require.Equal(len(reachable), countKeys(require, db, versionedBucketName, nodeKeyFmt.Encode()), "superseded nodes should be removed")
+	require.Equal(0, countKeys(require, db, metaBucketName, gcLogKeyFmt.Encode()), "garbage collection log should be empty")
+}
+
+func TestReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "mkvs.test.bolt")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	cfg := &api.Config{
+		DB:        dir,
+		NoFsync:   true,
+		Namespace: testNs,
+		ReadOnly:  true,
+	}
+	_, err = New(cfg)
+	require.Error(err, "New should fail for a missing read-only database")
+
+	cfg.ReadOnly = false
+	ndb, err := New(cfg)
+	require.NoError(err, "New")
+	ndb.Close()
+
+	cfg.ReadOnly = true
+	ndb, err = New(cfg)
+	require.NoError(err, "New")
+	defer ndb.Close()
+
+	var emptyRoot node.Root
+	emptyRoot.Empty()
+	emptyRoot.Namespace = testNs
+	_, err = ndb.NewBatch(emptyRoot, 0, false)
+	require.ErrorIs(err, api.ErrReadOnly, "NewBatch should fail for a read-only database")
+}
+
+// liveNodeKeys returns the (unversioned) keys of all nodes that are currently present in the
+// database.
+func liveNodeKeys(require *require.Assertions, db *boltNodeDB) tests.KeySet {
+	nodeKeys := tests.KeySet{}
+	err := db.db.View(func(tx *bbolt.Tx) error {
+		var lastKey []byte
+		cur := tx.Bucket(versionedBucketName).Cursor()
+		for vk, entry := cur.Seek(nodePrefix); vk != nil && bytes.HasPrefix(vk, nodePrefix); vk, entry = cur.Next() {
+			key := vk[:len(vk)-versionSize]
+			if bytes.Equal(key, lastKey) {
+				// Newer entries of the same key sort first, so this one is superseded.
+				continue
+			}
+			lastKey = append([]byte{}, key...)
+
+			if len(entry) > 0 && entry[0] == versionedEntryValue {
+				nodeKeys[string(key)] = struct{}{}
+			}
+		}
+		return nil
+	})
+	require.NoError(err, "liveNodeKeys()")
+	return nodeKeys
+}
@@ -0,0 +1,146 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"go.etcd.io/bbolt"
+)
+
+// Badger (used by the other node database backend) natively supports versioned keys which the
+// node database relies on to keep track of node lifetimes. As bbolt does not, versioned keys
+// are emulated by suffixing each key with its (inverted) version. Reading a key at a given
+// version returns the newest entry that was written at or before that version.
+
+const (
+	// versionSize is the size of the version suffix of versioned keys.
+	versionSize = 8
+
+	// versionedEntryDeleted marks a versioned entry as deleted.
+	versionedEntryDeleted byte = 0x00
+	// versionedEntryValue marks a versioned entry as containing a value.
+	versionedEntryValue byte = 0x01
+)
+
+// versionedKey returns the key under which the entry of the given key at the given version is
+// stored. The version is inverted so that newer entries of the same key sort first.
+func versionedKey(key []byte, version uint64) []byte {
+	vk := make([]byte, len(key)+versionSize)
+	copy(vk, key)
+	binary.BigEndian.PutUint64(vk[len(key):], ^version)
+	return vk
+}
+
+// isVersionedKeyOf returns true iff vk is a versioned key of the given key.
+func isVersionedKeyOf(vk, key []byte) bool {
+	return len(vk) == len(key)+versionSize && bytes.HasPrefix(vk, key)
+}
+
+// versionFromVersionedKey returns the version of the given versioned key.
+func versionFromVersionedKey(vk []byte) uint64 {
+	return ^binary.BigEndian.Uint64(vk[len(vk)-versionSize:])
+}
+
+// versionedGet returns a copy of the value of the given key as visible at the given version and
+// the version at which the value was written.
+//
+// In case the key does not exist at the given version, nil is returned.
+func versionedGet(tx *bbolt.Tx, key []byte, version uint64) ([]byte, uint64) {
+	cur := tx.Bucket(versionedBucketName).Cursor()
+	vk, entry := cur.Seek(versionedKey(key, version))
+	if vk == nil || !isVersionedKeyOf(vk, key) || len(entry) == 0 || entry[0] != versionedEntryValue {
+		return nil, 0
+	}
+	return append([]byte{}, entry[1:]...), versionFromVersionedKey(vk)
+}
+
+// versionedPut sets the value of the given key at the given version.
+//
+// A nil value marks the key as deleted starting with the given version.
+func versionedPut(tx *bbolt.Tx, key []byte, version uint64, value []byte) error {
+	entry := make([]byte, 1+len(value))
+	switch value {
+	case nil:
+		entry[0] = versionedEntryDeleted
+	default:
+		entry[0] = versionedEntryValue
+		copy(entry[1:], value)
+	}
+	if err := tx.Bucket(versionedBucketName).Put(versionedKey(key, version), entry); err != nil {
+		return err
+	}
+
+	// Record the update so that any superseded entries can be removed once the version becomes
+	// the earliest version.
+	return tx.Bucket(metaBucketName).Put(gcLogKeyFmt.Encode(version, key), []byte{})
+}
+
+// versionedDelete marks the given key as deleted starting with the given version.
+func versionedDelete(tx *bbolt.Tx, key []byte, version uint64) error {
+	return versionedPut(tx, key, version, nil)
+}
+
+// collectGarbage removes all versioned entries which are not visible at any version starting
+// with the given version.
+func collectGarbage(tx *bbolt.Tx, version uint64) error {
+	meta := tx.Bucket(metaBucketName)
+
+	// Collect all updates at or before the given version.
+	var logKeys [][]byte
+	cur := meta.Cursor()
+	prefix := gcLogKeyFmt.Encode()
+	for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		var logVersion uint64
+		if !gcLogKeyFmt.Decode(k, &logVersion) {
+			panic("mkvs/bolt: bad iterator")
+		}
+		if logVersion > version {
+			break
+		}
+		logKeys = append(logKeys, append([]byte{}, k...))
+	}
+
+	versioned := tx.Bucket(versionedBucketName)
+	for _, logKey := range logKeys {
+		var (
+			logVersion uint64
+			key        []byte
+		)
+		if !gcLogKeyFmt.Decode(logKey, &logVersion, &key) {
+			panic("mkvs/bolt: bad garbage collection log key")
+		}
+		if err := collectKeyGarbage(versioned, key, version); err != nil {
+			return err
+		}
+		if err := meta.Delete(logKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectKeyGarbage removes all entries of the given key which are not visible at any version
+// starting with the given version.
+func collectKeyGarbage(b *bbolt.Bucket, key []byte, version uint64) error {
+	var stale [][]byte
+	cur := b.Cursor()
+	vk, entry := cur.Seek(versionedKey(key, version))
+	if vk == nil || !isVersionedKeyOf(vk, key) {
+		return nil
+	}
+	// The first entry is visible at the given version and all older entries are superseded by
+	// it. In case the visible entry marks the key as deleted, it is not needed either.
+	if len(entry) == 0 || entry[0] == versionedEntryDeleted {
+		stale = append(stale, append([]byte{}, vk...))
+	}
+	for vk, _ = cur.Next(); vk != nil && isVersionedKeyOf(vk, key); vk, _ = cur.Next() {
+		stale = append(stale, append([]byte{}, vk...))
+	}
+
+	for _, vk := range stale {
+		if err := b.Delete(vk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+// Package tests is a collection of node database implementation test cases.
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+var (
+	testNs = common.NewTestNamespaceFromSeed([]byte("node db test ns"), 0)
+
+	testValues = [][]byte{
+		[]byte("colorless green ideas sleep furiously"),
+		[]byte("excepting understandable chairs piously"),
+		[]byte("at the prickle for rainbow hoovering"),
+	}
+)
+
+// KeySet is a set of raw database keys.
+type KeySet map[string]struct{}
+
+// Backend is a node database backend under test.
+type Backend interface {
+	// New creates a new node database instance with the given configuration.
+	New(cfg *api.Config) (api.NodeDB, error)
+
+	// NodeKeys returns the keys of all nodes that are currently present in the node database.
+	NodeKeys(t *testing.T, ndb api.NodeDB) KeySet
+
+	// MultipartLogKeys returns the keys of all multipart restore log entries that are currently
+	// present in the node database.
+	MultipartLogKeys(t *testing.T, ndb api.NodeDB) KeySet
+}
+
+type test struct {
+	t       *testing.T
+	require *require.Assertions
+	ctx     context.Context
+	dir     string
+	backend Backend
+	ndb     api.NodeDB
+	ckMeta  *checkpoint.Metadata
+	ckNodes KeySet
+}
+
+// NodeDBImplementationTests exercises the basic functionality of a node database backend.
+func NodeDBImplementationTests(t *testing.T, backend Backend) {
+	t.Run("MultipartRestore", func(t *testing.T) {
+		testMultipartRestore(t, backend)
+	})
+	t.Run("VersionChecks", func(t *testing.T) {
+		testVersionChecks(t, backend)
+	})
+	t.Run("ReadOnlyBatch", func(t *testing.T) {
+		testReadOnlyBatch(t, backend)
+	})
+	t.Run("FinalizeBasic", func(t *testing.T) {
+		testFinalizeBasic(t, backend)
+	})
+}
+
+func memoryConfig() *api.Config {
+	return &api.Config{
+		Namespace:  testNs,
+		NoFsync:    true,
+		MemoryOnly: true,
+	}
+}
+
+func fillDB(
+	ctx context.Context,
+	require *require.Assertions,
+	values [][]byte,
+	prevRoot *node.Root,
+	version, commitVersion uint64,
+	ndb api.NodeDB,
+) node.Root {
+	if prevRoot == nil {
+		emptyRoot := node.Root{
+			Namespace: testNs,
+			Version:   version,
+			Type:      node.RootTypeState,
+		}
+		emptyRoot.Hash.Empty()
+		prevRoot = &emptyRoot
+	}
+
+	tree := mkvs.NewWithRoot(nil, ndb, *prevRoot)
+	require.NotNil(tree, "NewWithRoot()")
+
+	var wl writelog.WriteLog
+	for i, val := range values {
+		wl = append(wl, writelog.LogEntry{Key: []byte(strconv.Itoa(i)), Value: val})
+	}
+
+	err := tree.ApplyWriteLog(ctx, writelog.NewStaticIterator(wl))
+	require.NoError(err, "ApplyWriteLog()")
+
+	_, hash, err := tree.Commit(ctx, testNs, commitVersion)
+	require.NoError(err, "Commit()")
+
+	return node.Root{
+		Namespace: testNs,
+		Version:   version + 1,
+		Type:      node.RootTypeState,
+		Hash:      hash,
+	}
+}
+
+func createCheckpoint(ctx *test, values [][]byte, version uint64) (*checkpoint.Metadata, KeySet) {
+	ndb, err := ctx.backend.New(memoryConfig())
+	ctx.require.NoError(err, "New()")
+	defer ndb.Close()
+	fc, err := checkpoint.NewFileCreator(ctx.dir, ndb)
+	ctx.require.NoError(err, "NewFileCreator()")
+
+	ckRoot := fillDB(ctx.ctx, ctx.require, values, nil, version, 2, ndb)
+	ckMeta, err := fc.CreateCheckpoint(ctx.ctx, ckRoot, 1024*1024)
+	ctx.require.NoError(err, "CreateCheckpoint()")
+
+	return ckMeta, ctx.backend.NodeKeys(ctx.t, ndb)
+}
+
+func verifyNodes(ctx *test, keySet KeySet) {
+	ctx.require.EqualValues(keySet, ctx.backend.NodeKeys(ctx.t, ctx.ndb), "database should contain exactly the expected nodes")
+}
+
+func checkNoLogKeys(ctx *test) {
+	ctx.require.Empty(ctx.backend.MultipartLogKeys(ctx.t, ctx.ndb), "checkNoLogKeys()")
+}
+
+func restoreCheckpoint(ctx *test, ckMeta *checkpoint.Metadata, ckNodes KeySet) checkpoint.Restorer {
+	fc, err := checkpoint.NewFileCreator(ctx.dir, ctx.ndb)
+	ctx.require.NoError(err, "NewFileCreator() - 2")
+
+	restorer, err := checkpoint.NewRestorer(ctx.ndb)
+	ctx.require.NoError(err, "NewRestorer()")
+
+	err = ctx.ndb.StartMultipartInsert(ckMeta.Root.Version)
+	ctx.require.NoError(err, "StartMultipartInsert()")
+	err = restorer.StartRestore(ctx.ctx, ckMeta)
+	ctx.require.NoError(err, "StartRestore()")
+	for i := range ckMeta.Chunks {
+		idx := uint64(i)
+		chunkMeta, err := ckMeta.GetChunkMetadata(idx)
+		ctx.require.NoError(err, fmt.Sprintf("GetChunkMetadata(%d)", idx))
+		func() {
+			r, w, err := os.Pipe()
+			ctx.require.NoError(err, "Pipe()")
+			errCh := make(chan error)
+			go func() {
+				_, errr := restorer.RestoreChunk(ctx.ctx, idx, r)
+				errCh <- errr
+			}()
+			err = fc.GetCheckpointChunk(ctx.ctx, chunkMeta, w)
+			w.Close()
+			errRestore := <-errCh
+			ctx.require.NoError(err, "GetCheckpointChunk()")
+			ctx.require.NoError(errRestore, "RestoreChunk()")
+		}()
+	}
+
+	verifyNodes(ctx, ckNodes)
+
+	return restorer
+}
+
+func testMultipartRestore(t *testing.T, backend Backend) {
+	wrap := func(testFunc func(ctx *test), initialValues [][]byte) func(*testing.T) {
+		return func(t *testing.T) {
+			require := require.New(t)
+
+			dir, err := ioutil.TempDir("", "oasis-storage-database-test")
+			require.NoError(err, "TempDir()")
+			defer os.RemoveAll(dir)
+
+			testCtx := &test{
+				t:       t,
+				require: require,
+				ctx:     context.Background(),
+				dir:     dir,
+				backend: backend,
+			}
+			testCtx.ckMeta, testCtx.ckNodes = createCheckpoint(testCtx, initialValues, 1)
+
+			testCtx.ndb, err = backend.New(memoryConfig())
+			require.NoError(err, "New() - 2")
+			defer testCtx.ndb.Close()
+
+			testFunc(testCtx)
+		}
+	}
+
+	t.Run("Abort", wrap(testAbort, testValues))
+	t.Run("Finalize", wrap(testFinalize, testValues))
+	t.Run("ExistingNodes", wrap(testExistingNodes, testValues[:1]))
+}
+
+func testAbort(ctx *test) {
+	// Abort a restore, check nodes again.
+	// There should be no leftover nodes, and the log keys should be gone too.
+	restorer := restoreCheckpoint(ctx, ctx.ckMeta, ctx.ckNodes)
+	err := restorer.AbortRestore(ctx.ctx)
+	ctx.require.NoError(err, "AbortRestore()")
+	err = ctx.ndb.AbortMultipartInsert()
+	ctx.require.NoError(err, "AbortMultipartInsert()")
+
+	verifyNodes(ctx, KeySet{})
+	checkNoLogKeys(ctx)
+}
+
+func testFinalize(ctx *test) {
+	// Finalize a restore, check nodes again.
+	// This time, all the restored nodes should be present, but the
+	// log keys should be gone.
+	restoreCheckpoint(ctx, ctx.ckMeta, ctx.ckNodes)
+
+	// Test parameter sanity checking first.
+	err := ctx.ndb.Finalize(ctx.ctx, nil)
+	ctx.require.Error(err, "Finalize with no roots should fail")
+
+	bogusRoot := ctx.ckMeta.Root
+	bogusRoot.Version++
+	err = ctx.ndb.Finalize(ctx.ctx, []node.Root{ctx.ckMeta.Root, bogusRoot})
+	ctx.require.Error(err, "Finalize with roots from different versions should fail")
+
+	err = ctx.ndb.Finalize(ctx.ctx, []node.Root{ctx.ckMeta.Root})
+	ctx.require.NoError(err, "Finalize()")
+
+	verifyNodes(ctx, ctx.ckNodes)
+	checkNoLogKeys(ctx)
+}
+
+func testExistingNodes(ctx *test) {
+	// Create two checkpoints, so we have two sets of nodes.
+	// The first checkpoint will be the base for a fresh database and must include
+	// a node from the second checkpoint, which will be used for multipart restore.
+	// The pre-existing node should then not be deleted after aborting the second
+	// checkpoint.
+
+	// Create the checkpoint to be used as the overriding restore.
+	ckMeta2, ckNodes2 := createCheckpoint(ctx, testValues, 2)
+	var overlap bool
+	for node1 := range ctx.ckNodes {
+		if _, ok := ckNodes2[node1]; ok {
+			overlap = true
+			break
+		}
+	}
+	ctx.require.Equal(true, overlap, "pointless test when no nodes would overlap")
+
+	// Restore first checkpoint. The database is empty.
+	restoreCheckpoint(ctx, ctx.ckMeta, ctx.ckNodes)
+	err := ctx.ndb.Finalize(ctx.ctx, []node.Root{ctx.ckMeta.Root})
+	ctx.require.NoError(err, "Finalize()")
+	verifyNodes(ctx, ctx.ckNodes)
+
+	// Restore the second checkpoint. One of the nodes from it already exists. After aborting,
+	// exactly the nodes from the first checkpoint should remain.
+	restorer := restoreCheckpoint(ctx, ckMeta2, ckNodes2)
+	err = restorer.AbortRestore(ctx.ctx)
+	ctx.require.NoError(err, "AbortRestore()")
+	err = ctx.ndb.AbortMultipartInsert()
+	ctx.require.NoError(err, "AbortMultipartInsert()")
+	verifyNodes(ctx, ctx.ckNodes)
+}
+
+func testVersionChecks(t *testing.T, backend Backend) {
+	require := require.New(t)
+	ndb, err := backend.New(memoryConfig())
+	require.NoError(err, "New()")
+	defer ndb.Close()
+
+	err = ndb.StartMultipartInsert(0)
+	require.Error(err, "StartMultipartInsert(0)")
+
+	err = ndb.StartMultipartInsert(42)
+	require.NoError(err, "StartMultipartInsert(42)")
+	err = ndb.StartMultipartInsert(44)
+	require.Error(err, "StartMultipartInsert(44)")
+
+	root := node.Root{}
+	_, err = ndb.NewBatch(root, 0, false) // Normal chunks not allowed during multipart.
+	require.Error(err, "NewBatch(.., 0, false)")
+	_, err = ndb.NewBatch(root, 13, true)
+	require.Error(err, "NewBatch(.., 13, true)")
+	batch, err := ndb.NewBatch(root, 42, true)
+	require.NoError(err, "NewBatch(.., 42, true)")
+	defer batch.Reset()
+
+	err = batch.Commit(root)
+	require.Error(err, "Commit(Root{0})")
+}
+
+func testReadOnlyBatch(t *testing.T, backend Backend) {
+	require := require.New(t)
+
+	// No way to initialize a readonly-database, so it needs to be created rw first.
+	// This means we need persistence.
+	dir, err := ioutil.TempDir("", "oasis-storage-database-test")
+	require.NoError(err, "TempDir()")
+	defer os.RemoveAll(dir)
+
+	readonlyCfg := memoryConfig()
+	readonlyCfg.MemoryOnly = false
+	readonlyCfg.ReadOnly = false
+	readonlyCfg.DB = dir
+
+	func() {
+		ndb, errRw := backend.New(readonlyCfg)
+		require.NoError(errRw, "New() - 1")
+		defer ndb.Close()
+	}()
+
+	readonlyCfg.ReadOnly = true
+	ndb, err := backend.New(readonlyCfg)
+	require.NoError(err, "New() - 2")
+	defer ndb.Close()
+
+	_, err = ndb.NewBatch(node.Root{}, 13, false)
+	require.Error(err, "NewBatch()")
+}
+
+func testFinalizeBasic(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	offset := func(vals [][]byte) [][]byte {
+		ret := make([][]byte, 0, len(vals))
+		for _, val := range vals {
+			ret = append(ret, append(val, 0x0a))
+		}
+		return ret
+	}
+
+	ndb, err := backend.New(memoryConfig())
+	require.NoError(err, "New()")
+	defer ndb.Close()
+
+	root1 := fillDB(ctx, require, testValues, nil, 1, 2, ndb)
+	err = ndb.Finalize(ctx, []node.Root{root1})
+	require.NoError(err, "Finalize({root1})")
+
+	// Finalize a corrupted root.
+	currentValues := offset(testValues)
+	root2 := fillDB(ctx, require, currentValues, &root1, 2, 3, ndb)
+	root2.Hash[3]++
+	err = ndb.Finalize(ctx, []node.Root{root2})
+	require.Errorf(err, "mkvs: root not found", "Finalize({root2-broken})")
+}
@@ -17,7 +17,6 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/background"
 	"github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/storage/database"
-	badgerNodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/badger"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/interop/fixtures"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
 )
@@ -27,6 +26,7 @@ const (
 	cfgServerDataDir = "datadir"
 
 	cfgServerFixture = "fixture"
+	cfgServerBackend = "backend"
 )
 
 var (
@@ -68,7 +68,7 @@ func doProtoServer(cmd *cobra.Command, args []string) {
 
 	// Initialize a dummy storage backend.
 	storageCfg := api.Config{
-		Backend:      database.BackendNameBadgerDB,
+		Backend:      viper.GetString(cfgServerBackend),
 		DB:           dataDir,
 		MaxCacheSize: 16 * 1024 * 1024,
 	}
@@ -77,7 +77,7 @@ func doProtoServer(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 		ndbCfg := storageCfg.ToNodeDB()
 		var ndb api.NodeDB
-		ndb, err = badgerNodedb.New(ndbCfg)
+		ndb, err = database.NewNodeDB(storageCfg.Backend, ndbCfg)
 		if err != nil {
 			logger.Error("failed to initialize node db",
 				"err", err,
@@ -153,5 +153,6 @@ func init() {
 	protoServerFlags.String(cfgServerSocket, "storage.sock", "path to storage protocol server socket")
 	protoServerFlags.String(cfgServerDataDir, "", "path to data directory")
 	protoServerFlags.String(cfgServerFixture, "", "fixture for initializing initial state")
+	protoServerFlags.String(cfgServerBackend, database.BackendNameBadgerDB, "storage database backend")
 	_ = viper.BindPFlags(protoServerFlags)
 }
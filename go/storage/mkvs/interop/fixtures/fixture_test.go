@@ -0,0 +1,79 @@
+package fixtures
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/storage/database"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	db "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+func populateFixture(t *testing.T, fixture Fixture, backend string) (db.NodeDB, *node.Root) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "mkvs.interop.fixture")
+	require.NoError(err, "TempDir")
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	ndb, err := database.NewNodeDB(backend, &db.Config{
+		DB:           dir,
+		Namespace:    common.Namespace{},
+		MaxCacheSize: 16 * 1024 * 1024,
+		NoFsync:      true,
+	})
+	require.NoError(err, "NewNodeDB")
+	t.Cleanup(ndb.Close)
+
+	root, err := fixture.Populate(context.Background(), ndb)
+	require.NoError(err, "Populate")
+	return ndb, root
+}
+
+func TestFixtureBackends(t *testing.T) {
+	registeredFixtures.Range(func(k, v interface{}) bool {
+		fixture := v.(Fixture)
+		t.Run(fixture.Name(), func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			badgerDB, badgerRoot := populateFixture(t, fixture, database.BackendNameBadgerDB)
+			boltDB, boltRoot := populateFixture(t, fixture, database.BackendNameBoltDB)
+			require.EqualValues(badgerRoot, boltRoot, "fixture roots should be the same for all backends")
+
+			// All entries should be readable from both backends.
+			badgerTree := mkvs.NewWithRoot(nil, badgerDB, *badgerRoot)
+			defer badgerTree.Close()
+			boltTree := mkvs.NewWithRoot(nil, boltDB, *boltRoot)
+			defer boltTree.Close()
+
+			badgerIt := badgerTree.NewIterator(ctx)
+			defer badgerIt.Close()
+			boltIt := boltTree.NewIterator(ctx)
+			defer boltIt.Close()
+
+			var numEntries int
+			boltIt.Rewind()
+			for badgerIt.Rewind(); badgerIt.Valid(); badgerIt.Next() {
+				require.True(boltIt.Valid(), "bolt iterator should have all entries")
+				require.EqualValues(badgerIt.Key(), boltIt.Key(), "keys should be the same")
+				require.EqualValues(badgerIt.Value(), boltIt.Value(), "values should be the same")
+				boltIt.Next()
+				numEntries++
+			}
+			require.NoError(badgerIt.Err(), "badger iterator")
+			require.NoError(boltIt.Err(), "bolt iterator")
+			require.False(boltIt.Valid(), "bolt iterator should not have any extra entries")
+			require.NotZero(numEntries, "fixture should not be empty")
+		})
+		return true
+	})
+}
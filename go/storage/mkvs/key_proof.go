@@ -0,0 +1,89 @@
+package mkvs
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+// Implements Tree.
+func (t *tree) GetKeyProof(ctx context.Context, key []byte) (*syncer.KeyProof, error) {
+	t.cache.Lock()
+	defer t.cache.Unlock()
+
+	if t.cache.isClosed() {
+		return nil, ErrClosed
+	}
+	if !t.cache.pendingRoot.IsClean() {
+		return nil, syncer.ErrDirtyRoot
+	}
+
+	// Remember where the path from root to target node ends (will end).
+	t.cache.markPosition()
+
+	// Always anchor the proof at the root so that it can be verified independently.
+	root := t.cache.syncRoot.Hash
+	pb := syncer.NewProofBuilder(root, root)
+	value, err := t.doGet(ctx, t.cache.pendingRoot, 0, key, doGetOptions{proofBuilder: pb}, false)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := pb.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syncer.KeyProof{
+		Key:    key,
+		Exists: value != nil,
+		Value:  value,
+		Proof:  *proof,
+	}, nil
+}
+
+// Implements Tree.
+func (t *tree) GetRangeProof(ctx context.Context, start, end []byte, limit uint16) (*syncer.RangeProof, error) {
+	t.cache.Lock()
+	if t.cache.isClosed() {
+		t.cache.Unlock()
+		return nil, ErrClosed
+	}
+	if !t.cache.pendingRoot.IsClean() {
+		t.cache.Unlock()
+		return nil, syncer.ErrDirtyRoot
+	}
+	root := t.cache.syncRoot.Hash
+	t.cache.Unlock()
+
+	// The iterator includes all visited nodes in the proof. This covers the path to the first
+	// key in the range, all keys in the range and the path to the first key after the range.
+	it := t.NewIterator(ctx, WithProof(root))
+	defer it.Close()
+
+	rp := syncer.RangeProof{
+		Start: start,
+		End:   end,
+	}
+	for it.Seek(start); it.Valid(); it.Next() {
+		if len(end) > 0 && it.Key().Compare(end) >= 0 {
+			break
+		}
+		if limit > 0 && len(rp.Entries) >= int(limit) {
+			// Reduce the range so that it ends at the first key that is not included.
+			rp.End = it.Key()
+			break
+		}
+		rp.Entries = append(rp.Entries, syncer.RangeEntry{Key: it.Key(), Value: it.Value()})
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	proof, err := it.GetProof()
+	if err != nil {
+		return nil, err
+	}
+	rp.Proof = *proof
+
+	return &rp, nil
+}
@@ -75,6 +75,21 @@ type Tree interface {
 	// starting with given prefixes.
 	PrefetchPrefixes(ctx context.Context, prefixes [][]byte, limit uint16) error
 
+	// GetKeyProof returns a proof that the given key either has a given value or is not present
+	// in the tree.
+	//
+	// The tree must not have any uncommitted modifications.
+	GetKeyProof(ctx context.Context, key []byte) (*syncer.KeyProof, error)
+
+	// GetRangeProof returns a proof of all entries in the key range [start, end). An empty end
+	// key means that the range is unbounded.
+	//
+	// If limit is non-zero and the range contains more than limit entries, the range in the
+	// returned proof is reduced so that it contains exactly limit entries.
+	//
+	// The tree must not have any uncommitted modifications.
+	GetRangeProof(ctx context.Context, start, end []byte, limit uint16) (*syncer.RangeProof, error)
+
 	// ApplyWriteLog applies the operations from a write log to the current tree.
 	//
 	// The caller is responsible for calling Commit.
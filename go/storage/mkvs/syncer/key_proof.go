@@ -0,0 +1,231 @@
+package syncer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+// ErrIncompleteProof is the error returned when a proof does not include all of the nodes that
+// are required to verify the claim it makes.
+var ErrIncompleteProof = errors.New("verifier: proof is incomplete")
+
+// KeyProof is a self-describing proof that a key either has a given value or is not present in
+// the tree with a given root.
+type KeyProof struct {
+	// Key is the key the proof is for.
+	Key []byte `json:"key"`
+	// Exists is true iff the key is present in the tree.
+	Exists bool `json:"exists"`
+	// Value is the value of the key in case it is present in the tree.
+	Value []byte `json:"value,omitempty"`
+	// Proof is the Merkle proof that includes all nodes on the lookup path.
+	Proof Proof `json:"proof"`
+}
+
+// RangeEntry is a key-value pair contained in a range proof.
+type RangeEntry struct {
+	// Key is the entry key.
+	Key []byte `json:"key"`
+	// Value is the entry value.
+	Value []byte `json:"value"`
+}
+
+// RangeProof is a self-describing proof that the given entries are the only entries present in
+// the key range [Start, End) of the tree with a given root.
+type RangeProof struct {
+	// Start is the (inclusive) start of the key range.
+	Start []byte `json:"start,omitempty"`
+	// End is the (exclusive) end of the key range. If empty, the range is unbounded.
+	End []byte `json:"end,omitempty"`
+	// Entries are all the entries in the key range, ordered by key.
+	Entries []RangeEntry `json:"entries,omitempty"`
+	// Proof is the Merkle proof that includes all nodes which may contain keys in the range.
+	Proof Proof `json:"proof"`
+}
+
+// VerifyKeyProof verifies that the key proof is valid for the given (trusted) root hash.
+//
+// In case verification succeeds, the caller may rely on the claim made by the proof.
+func (pv *ProofVerifier) VerifyKeyProof(ctx context.Context, root hash.Hash, proof *KeyProof) error {
+	if !proof.Exists && proof.Value != nil {
+		return errors.New("verifier: key proof for a missing key has a value")
+	}
+
+	ptr, err := pv.VerifyProof(ctx, root, &proof.Proof)
+	if err != nil {
+		return err
+	}
+
+	value, exists, err := lookupVerified(ctx, ptr, 0, proof.Key)
+	if err != nil {
+		return err
+	}
+	switch {
+	case exists != proof.Exists:
+		return fmt.Errorf("verifier: key existence mismatch (expected: %t got: %t)", proof.Exists, exists)
+	case !bytes.Equal(value, proof.Value):
+		return errors.New("verifier: key value mismatch")
+	default:
+		return nil
+	}
+}
+
+// VerifyRangeProof verifies that the range proof is valid for the given (trusted) root hash.
+//
+// In case verification succeeds, the caller may rely on the claim made by the proof.
+func (pv *ProofVerifier) VerifyRangeProof(ctx context.Context, root hash.Hash, proof *RangeProof) error {
+	ptr, err := pv.VerifyProof(ctx, root, &proof.Proof)
+	if err != nil {
+		return err
+	}
+
+	var end node.Key
+	if len(proof.End) > 0 {
+		end = proof.End
+	}
+	rc := rangeCollector{
+		ctx:   ctx,
+		start: proof.Start,
+		end:   end,
+	}
+	if err = rc.collect(ptr, 0, node.Key{}, 0); err != nil {
+		return err
+	}
+
+	if len(rc.entries) != len(proof.Entries) {
+		return fmt.Errorf("verifier: range entry count mismatch (expected: %d got: %d)",
+			len(proof.Entries),
+			len(rc.entries),
+		)
+	}
+	for i, entry := range rc.entries {
+		if !bytes.Equal(entry.Key, proof.Entries[i].Key) {
+			return fmt.Errorf("verifier: range entry %d key mismatch", i)
+		}
+		if !bytes.Equal(entry.Value, proof.Entries[i].Value) {
+			return fmt.Errorf("verifier: range entry %d value mismatch", i)
+		}
+	}
+	return nil
+}
+
+// lookupVerified looks up a key in a subtree obtained from a verified proof.
+//
+// The lookup follows the same path as a regular tree lookup would. In case a node on the path
+// is not available in the proof, ErrIncompleteProof is returned.
+func lookupVerified(ctx context.Context, ptr *node.Pointer, bitDepth node.Depth, key node.Key) ([]byte, bool, error) {
+	if ctx.Err() != nil {
+		return nil, false, ctx.Err()
+	}
+	if ptr == nil {
+		// Reached a nil node, there is nothing here.
+		return nil, false, nil
+	}
+	if ptr.Node == nil {
+		return nil, false, ErrIncompleteProof
+	}
+
+	switch n := ptr.Node.(type) {
+	case *node.InternalNode:
+		bitLength := bitDepth + n.LabelBitLength
+
+		// Does lookup key end here? Look into LeafNode.
+		if key.BitLength() == bitLength {
+			return lookupVerified(ctx, n.LeafNode, bitLength, key)
+		}
+		// Lookup key is too short for the current n.Label. It's not stored.
+		if key.BitLength() < bitLength {
+			return nil, false, nil
+		}
+		// Continue recursively based on a bit value.
+		if key.GetBit(bitLength) {
+			return lookupVerified(ctx, n.Right, bitLength, key)
+		}
+		return lookupVerified(ctx, n.Left, bitLength, key)
+	case *node.LeafNode:
+		// Reached a leaf node, check if key matches.
+		if n.Key.Equal(key) {
+			return n.Value, true, nil
+		}
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("verifier: unknown node type: %T", n)
+	}
+}
+
+type rangeCollector struct {
+	ctx     context.Context
+	start   node.Key
+	end     node.Key
+	entries []RangeEntry
+}
+
+// collect collects all entries in the key range from a subtree obtained from a verified proof.
+//
+// Entries are visited in key order. The path is the known key prefix of the subtree and
+// pathBits is its length in bits. In case a node that may contain keys in the range is not
+// available in the proof, ErrIncompleteProof is returned.
+func (rc *rangeCollector) collect(ptr *node.Pointer, bitDepth node.Depth, path node.Key, pathBits node.Depth) error {
+	if rc.ctx.Err() != nil {
+		return rc.ctx.Err()
+	}
+	if ptr == nil {
+		// Reached a nil node, there is nothing here.
+		return nil
+	}
+	if ptr.Node == nil {
+		if rc.mayContain(path, pathBits) {
+			return ErrIncompleteProof
+		}
+		return nil
+	}
+
+	switch n := ptr.Node.(type) {
+	case *node.InternalNode:
+		bitLength := bitDepth + n.LabelBitLength
+		newPath := path.Merge(bitDepth, n.Label, n.LabelBitLength)
+
+		// The leaf node key is a prefix of all other keys in the subtree so it comes first.
+		if err := rc.collect(n.LeafNode, bitLength, newPath, bitLength); err != nil {
+			return err
+		}
+		if err := rc.collect(n.Left, bitLength, newPath.AppendBit(bitLength, false), bitLength+1); err != nil {
+			return err
+		}
+		return rc.collect(n.Right, bitLength, newPath.AppendBit(bitLength, true), bitLength+1)
+	case *node.LeafNode:
+		if n.Key.Compare(rc.start) < 0 {
+			return nil
+		}
+		if rc.end != nil && n.Key.Compare(rc.end) >= 0 {
+			return nil
+		}
+		rc.entries = append(rc.entries, RangeEntry{Key: n.Key, Value: n.Value})
+		return nil
+	default:
+		return fmt.Errorf("verifier: unknown node type: %T", n)
+	}
+}
+
+// mayContain returns true iff a subtree with the given key prefix may contain keys in the range.
+func (rc *rangeCollector) mayContain(prefix node.Key, prefixBits node.Depth) bool {
+	// The smallest key in the subtree is the prefix itself (padded with zero bits).
+	minKey := prefix[:prefixBits.ToBytes()]
+	if rc.end != nil && minKey.Compare(rc.end) >= 0 {
+		return false
+	}
+
+	// All keys in the subtree are smaller than the range start iff the prefix is smaller than the
+	// corresponding bits of the range start.
+	for bit := node.Depth(0); bit < prefixBits && bit < rc.start.BitLength(); bit++ {
+		if pb, sb := prefix.GetBit(bit), rc.start.GetBit(bit); pb != sb {
+			return pb
+		}
+	}
+	return true
+}
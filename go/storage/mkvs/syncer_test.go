@@ -3,6 +3,7 @@ package mkvs
 import (
 	"context"
 	"encoding/base64"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -157,6 +158,217 @@ func TestProof(t *testing.T) {
 	require.Error(err, "VerifyProof should fail with invalid proof")
 }
 
+func TestKeyProof(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	keys, values := generateKeyValuePairsEx("", 100)
+	var ns common.Namespace
+
+	tree := New(nil, nil, node.RootTypeState)
+	defer tree.Close()
+	for i, key := range keys {
+		err := tree.Insert(ctx, key, values[i])
+		require.NoError(err, "Insert")
+	}
+	// Empty values are valid values.
+	err := tree.Insert(ctx, []byte("empty"), nil)
+	require.NoError(err, "Insert")
+	_, rootHash, err := tree.Commit(ctx, ns, 0)
+	require.NoError(err, "Commit")
+
+	var pv syncer.ProofVerifier
+	verify := func(proof *syncer.KeyProof) error {
+		// Make sure the proof survives serialization.
+		var dec syncer.KeyProof
+		require.NoError(cbor.Unmarshal(cbor.Marshal(proof), &dec), "Unmarshal")
+		return pv.VerifyKeyProof(ctx, rootHash, &dec)
+	}
+
+	// Inclusion proofs.
+	for i, key := range keys {
+		proof, err := tree.GetKeyProof(ctx, key)
+		require.NoError(err, "GetKeyProof")
+		require.True(proof.Exists, "key should exist")
+		require.EqualValues(values[i], proof.Value, "value should be correct")
+		require.NoError(verify(proof), "VerifyKeyProof should not fail with a valid proof")
+	}
+
+	proof, err := tree.GetKeyProof(ctx, []byte("empty"))
+	require.NoError(err, "GetKeyProof")
+	require.True(proof.Exists, "key should exist")
+	require.NoError(verify(proof), "VerifyKeyProof should not fail with a valid proof")
+
+	// Non-inclusion proofs.
+	for _, key := range [][]byte{
+		nil,
+		[]byte("k"),
+		[]byte("key"),
+		[]byte("key 1000"),
+		[]byte("key 5 "),
+		[]byte("zzz"),
+	} {
+		proof, err = tree.GetKeyProof(ctx, key)
+		require.NoError(err, "GetKeyProof")
+		require.False(proof.Exists, "key should not exist")
+		require.Nil(proof.Value, "missing key should not have a value")
+		require.NoError(verify(proof), "VerifyKeyProof should not fail with a valid proof")
+	}
+
+	// Invalid claims should not verify.
+	proof, err = tree.GetKeyProof(ctx, keys[42])
+	require.NoError(err, "GetKeyProof")
+
+	tampered := *proof
+	tampered.Value = []byte("bogus value")
+	require.Error(verify(&tampered), "VerifyKeyProof should fail with a wrong value")
+
+	tampered = *proof
+	tampered.Exists = false
+	tampered.Value = nil
+	require.Error(verify(&tampered), "VerifyKeyProof should fail with a wrong existence claim")
+
+	tampered = *proof
+	tampered.Key = []byte("zzz")
+	require.Error(verify(&tampered), "VerifyKeyProof should fail for a key that is not covered by the proof")
+
+	tampered = *proof
+	tampered.Key = keys[43]
+	require.Error(verify(&tampered), "VerifyKeyProof should fail for a key that is not covered by the proof")
+
+	bogusHash := hash.NewFromBytes([]byte("i am a bogus hash"))
+	err = pv.VerifyKeyProof(ctx, bogusHash, proof)
+	require.Error(err, "VerifyKeyProof should fail with proof for a different root")
+
+	// Empty tree.
+	emptyTree := New(nil, nil, node.RootTypeState)
+	defer emptyTree.Close()
+	proof, err = emptyTree.GetKeyProof(ctx, keys[0])
+	require.NoError(err, "GetKeyProof")
+	require.False(proof.Exists, "key should not exist")
+	var emptyHash hash.Hash
+	emptyHash.Empty()
+	err = pv.VerifyKeyProof(ctx, emptyHash, proof)
+	require.NoError(err, "VerifyKeyProof should not fail with a valid proof for an empty root")
+
+	// Dirty trees should be rejected.
+	err = tree.Insert(ctx, []byte("dirty"), []byte("value"))
+	require.NoError(err, "Insert")
+	_, err = tree.GetKeyProof(ctx, keys[0])
+	require.Equal(syncer.ErrDirtyRoot, err, "GetKeyProof should fail on a dirty tree")
+}
+
+func TestRangeProof(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	keys, values := generateKeyValuePairsEx("", 100)
+	var ns common.Namespace
+
+	tree := New(nil, nil, node.RootTypeState)
+	defer tree.Close()
+	items := make(map[string][]byte)
+	for i, key := range keys {
+		err := tree.Insert(ctx, key, values[i])
+		require.NoError(err, "Insert")
+		items[string(key)] = values[i]
+	}
+	_, rootHash, err := tree.Commit(ctx, ns, 0)
+	require.NoError(err, "Commit")
+
+	sortedKeys := make([]string, 0, len(items))
+	for k := range items {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	// expected returns the entries in the given key range.
+	expected := func(start, end []byte) (entries []syncer.RangeEntry) {
+		for _, k := range sortedKeys {
+			if k < string(start) || (len(end) > 0 && k >= string(end)) {
+				continue
+			}
+			entries = append(entries, syncer.RangeEntry{Key: []byte(k), Value: items[k]})
+		}
+		return
+	}
+
+	var pv syncer.ProofVerifier
+	verify := func(proof *syncer.RangeProof) error {
+		// Make sure the proof survives serialization.
+		var dec syncer.RangeProof
+		require.NoError(cbor.Unmarshal(cbor.Marshal(proof), &dec), "Unmarshal")
+		return pv.VerifyRangeProof(ctx, rootHash, &dec)
+	}
+
+	for _, tc := range []struct {
+		start []byte
+		end   []byte
+	}{
+		{nil, nil},
+		{[]byte("key 1"), []byte("key 2")},
+		{[]byte("key 42"), []byte("key 43")},
+		{[]byte("key 42"), []byte("key 42")},
+		{[]byte("key 5"), nil},
+		{nil, []byte("key 3")},
+		{[]byte("a"), []byte("b")},
+		{[]byte("key 99"), []byte("zzz")},
+		{[]byte("zzz"), nil},
+		{[]byte("k"), []byte("key 0")},
+	} {
+		proof, err := tree.GetRangeProof(ctx, tc.start, tc.end, 0)
+		require.NoError(err, "GetRangeProof")
+		require.EqualValues(expected(tc.start, tc.end), proof.Entries, "entries should be correct")
+		require.NoError(verify(proof), "VerifyRangeProof should not fail with a valid proof")
+	}
+
+	// Limited range proofs should reduce the range.
+	var start []byte
+	var total int
+	for {
+		proof, err := tree.GetRangeProof(ctx, start, nil, 7)
+		require.NoError(err, "GetRangeProof")
+		require.True(len(proof.Entries) <= 7, "number of entries should be limited")
+		require.EqualValues(expected(proof.Start, proof.End), proof.Entries, "entries should be correct")
+		require.NoError(verify(proof), "VerifyRangeProof should not fail with a valid proof")
+
+		total += len(proof.Entries)
+		if len(proof.End) == 0 {
+			break
+		}
+		start = proof.End
+	}
+	require.Equal(len(items), total, "all entries should be covered")
+
+	// Invalid claims should not verify.
+	proof, err := tree.GetRangeProof(ctx, []byte("key 1"), []byte("key 2"), 0)
+	require.NoError(err, "GetRangeProof")
+	require.True(len(proof.Entries) > 2, "range should contain multiple entries")
+
+	tampered := *proof
+	tampered.Entries = proof.Entries[1:]
+	require.Error(verify(&tampered), "VerifyRangeProof should fail with an omitted entry")
+
+	tampered = *proof
+	tampered.Entries = append([]syncer.RangeEntry{}, proof.Entries...)
+	tampered.Entries[1].Value = []byte("bogus value")
+	require.Error(verify(&tampered), "VerifyRangeProof should fail with a wrong value")
+
+	tampered = *proof
+	tampered.End = []byte("key 3")
+	err = verify(&tampered)
+	require.Error(err, "VerifyRangeProof should fail for a range that is not covered by the proof")
+	require.Equal(syncer.ErrIncompleteProof, err)
+
+	keyProof, err := tree.GetKeyProof(ctx, keys[42])
+	require.NoError(err, "GetKeyProof")
+	err = pv.VerifyRangeProof(ctx, rootHash, &syncer.RangeProof{
+		Entries: []syncer.RangeEntry{{Key: keys[42], Value: values[42]}},
+		Proof:   keyProof.Proof,
+	})
+	require.Equal(syncer.ErrIncompleteProof, err, "VerifyRangeProof should fail with a key proof")
+}
+
 func copyProof(p *syncer.Proof) *syncer.Proof {
 	if p == nil {
 		return nil
@@ -18,6 +18,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	db "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
 	badgerDb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/badger"
+	boltDb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/bolt"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
 	mkvsTests "github.com/oasisprotocol/oasis-core/go/storage/mkvs/tests"
@@ -2293,6 +2294,29 @@ func TestBadgerBackend(t *testing.T) {
 	}, nil)
 }
 
+func TestBoltBackend(t *testing.T) {
+	testBackend(t, func(t *testing.T) (NodeDBFactory, func()) {
+		// Create a new random temporary directory under /tmp.
+		dir, err := ioutil.TempDir("", "mkvs.test.bolt")
+		require.NoError(t, err, "TempDir")
+
+		// Create a bbolt-backed Node DB factory.
+		factory := func(ns common.Namespace) (db.NodeDB, error) {
+			return boltDb.New(&db.Config{
+				DB:        dir,
+				NoFsync:   true,
+				Namespace: ns,
+			})
+		}
+
+		cleanup := func() {
+			os.RemoveAll(dir)
+		}
+
+		return factory, cleanup
+	}, nil)
+}
+
 func BenchmarkInsertCommitBatch1(b *testing.B) {
 	benchmarkInsertBatch(b, 1, true)
 }
@@ -11,12 +11,15 @@ import (
 
 	cmnBackoff "github.com/oasisprotocol/oasis-core/go/common/backoff"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/indexer"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
@@ -24,13 +27,21 @@ import (
 )
 
 type pendingTx struct {
+	ctx    context.Context
 	txHash hash.Hash
 	ch     chan *api.SubmitTxResult
 }
 
+// txWatch is the set of subscribers to status updates of a single transaction.
+type txWatch struct {
+	notifier *pubsub.Broker
+	refs     int
+}
+
 // Node is a client node.
 type Node struct {
 	commonNode *committee.Node
+	indexer    *indexer.Indexer
 
 	stopCh   chan struct{}
 	stopOnce sync.Once
@@ -40,6 +51,9 @@ type Node struct {
 	checkCh *channels.InfiniteChannel
 	txCh    *channels.InfiniteChannel
 
+	txWatchesLock sync.Mutex
+	txWatches     map[hash.Hash]*txWatch
+
 	logger *logging.Logger
 }
 
@@ -66,6 +80,9 @@ func (n *Node) Quit() <-chan struct{} {
 
 // Cleanup performs the service specific post-termination cleanup.
 func (n *Node) Cleanup() {
+	if n.indexer != nil {
+		n.indexer.Cleanup()
+	}
 }
 
 // Initialized returns a channel that will be closed when the node is
@@ -74,6 +91,11 @@ func (n *Node) Initialized() <-chan struct{} {
 	return n.initCh
 }
 
+// Indexer returns the transaction indexer or nil if the indexer is disabled.
+func (n *Node) Indexer() *indexer.Indexer {
+	return n.indexer
+}
+
 func (n *Node) HandlePeerTx(ctx context.Context, tx []byte) error {
 	// Nothing to do here.
 	return nil
@@ -100,14 +122,21 @@ func (n *Node) HandleNewEventLocked(*roothash.Event) {
 func (n *Node) HandleRuntimeHostEvent(*host.Event) {
 }
 
-func (n *Node) SubmitTx(ctx context.Context, tx []byte) (<-chan *api.SubmitTxResult, *protocol.Error, error) {
+func (n *Node) ensureSynced(ctx context.Context) error {
 	// Make sure consensus is synced.
 	select {
 	case <-n.commonNode.Consensus.Synced():
+		return nil
 	case <-ctx.Done():
-		return nil, nil, ctx.Err()
+		return ctx.Err()
 	default:
-		return nil, nil, api.ErrNotSynced
+		return api.ErrNotSynced
+	}
+}
+
+func (n *Node) SubmitTx(ctx context.Context, tx []byte) (<-chan *api.SubmitTxResult, *protocol.Error, error) {
+	if err := n.ensureSynced(ctx); err != nil {
+		return nil, nil, err
 	}
 
 	// Submit transaction to the pool and wait for it to get checked.
@@ -121,6 +150,7 @@ func (n *Node) SubmitTx(ctx context.Context, tx []byte) (<-chan *api.SubmitTxRes
 
 	ch := make(chan *api.SubmitTxResult, 1)
 	n.txCh.In() <- &pendingTx{
+		ctx:    ctx,
 		txHash: hash.NewFromBytes(tx),
 		ch:     ch,
 	}
@@ -128,6 +158,231 @@ func (n *Node) SubmitTx(ctx context.Context, tx []byte) (<-chan *api.SubmitTxRes
 	return ch, nil, nil
 }
 
+// SubmitTxStream submits a transaction to the transaction pool and returns a stream of its
+// status updates.
+func (n *Node) SubmitTxStream(ctx context.Context, tx []byte) (<-chan *api.TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	if err := n.ensureSynced(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+	// Subscribe to updates before submitting so that the check result is not missed.
+	txHash := hash.NewFromBytes(tx)
+	updateSub := n.subscribeTxUpdates(txHash)
+
+	result, err := n.commonNode.TxPool.SubmitTx(ctx, tx, &txpool.TransactionMeta{Local: true})
+	if err != nil {
+		n.unsubscribeTxUpdates(txHash, updateSub)
+		sub.Close()
+		return nil, nil, err
+	}
+
+	resultCh := make(chan *api.SubmitTxResult, 1)
+	if result.IsSuccess() {
+		n.txCh.In() <- &pendingTx{
+			ctx:    ctx,
+			txHash: txHash,
+			ch:     resultCh,
+		}
+	} else {
+		resultCh <- &api.SubmitTxResult{
+			Result: &api.SubmitTxMetaResponse{
+				CheckTxError: &result.Error,
+			},
+		}
+	}
+
+	return n.watchTx(ctx, txHash, updateSub, resultCh), sub, nil
+}
+
+// WatchTx returns a stream of status updates for a transaction that has already been submitted.
+//
+// In case the transaction has already been included in a block (and the transaction indexer is
+// enabled), the stream only contains the final status update. In case the transaction is neither
+// pending in the local transaction pool nor included in a block, api.ErrNotFound is returned.
+func (n *Node) WatchTx(ctx context.Context, txHash hash.Hash) (<-chan *api.TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+	updateSub := n.subscribeTxUpdates(txHash)
+
+	// Check whether the transaction has already been included.
+	result, err := n.getIncludedTxResult(ctx, txHash)
+	switch {
+	case err == nil:
+		resultCh := make(chan *api.SubmitTxResult, 1)
+		resultCh <- result
+		return n.watchTx(ctx, txHash, updateSub, resultCh), sub, nil
+	case errors.Is(err, api.ErrNotFound):
+	default:
+		n.unsubscribeTxUpdates(txHash, updateSub)
+		sub.Close()
+		return nil, nil, err
+	}
+
+	resultCh := make(chan *api.SubmitTxResult, 1)
+	n.txCh.In() <- &pendingTx{
+		ctx:    ctx,
+		txHash: txHash,
+		ch:     resultCh,
+	}
+
+	// Make sure that the transaction is pending as otherwise there would never be a final status
+	// update. The transaction could have been resolved in the meantime so check for that first.
+	if len(resultCh) == 0 && !n.commonNode.TxPool.IsPending(txHash) {
+		n.unsubscribeTxUpdates(txHash, updateSub)
+		sub.Close()
+		return nil, nil, api.ErrNotFound
+	}
+
+	return n.watchTx(ctx, txHash, updateSub, resultCh), sub, nil
+}
+
+// getIncludedTxResult returns the result of the given transaction in case it has already been
+// included in a block. In case the transaction is not found or the transaction indexer is
+// disabled, api.ErrNotFound is returned.
+func (n *Node) getIncludedTxResult(ctx context.Context, txHash hash.Hash) (*api.SubmitTxResult, error) {
+	if n.indexer == nil {
+		return nil, api.ErrNotFound
+	}
+
+	res, err := n.indexer.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := n.commonNode.Runtime.History().GetBlock(ctx, res.Round)
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to get block %d: %w", res.Round, err)
+	}
+
+	tree := transaction.NewTree(n.commonNode.Runtime.Storage(), ioRootFromBlock(blk))
+	defer tree.Close()
+
+	matches, err := tree.GetTransactionMultiple(ctx, []hash.Hash{txHash})
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to get transaction from block I/O: %w", err)
+	}
+	tx, ok := matches[txHash]
+	if !ok {
+		return nil, api.ErrNotFound
+	}
+
+	return &api.SubmitTxResult{
+		Result: &api.SubmitTxMetaResponse{
+			Round:      blk.Header.Round,
+			BatchOrder: tx.BatchOrder,
+			Output:     tx.Output,
+		},
+	}, nil
+}
+
+// subscribeTxUpdates subscribes to status updates of the given transaction.
+func (n *Node) subscribeTxUpdates(txHash hash.Hash) *pubsub.Subscription {
+	n.txWatchesLock.Lock()
+	defer n.txWatchesLock.Unlock()
+
+	w := n.txWatches[txHash]
+	if w == nil {
+		w = &txWatch{
+			notifier: pubsub.NewBroker(false),
+		}
+		n.txWatches[txHash] = w
+	}
+	w.refs++
+
+	return w.notifier.Subscribe()
+}
+
+// unsubscribeTxUpdates closes a subscription obtained via subscribeTxUpdates.
+func (n *Node) unsubscribeTxUpdates(txHash hash.Hash, sub *pubsub.Subscription) {
+	sub.Close()
+
+	n.txWatchesLock.Lock()
+	defer n.txWatchesLock.Unlock()
+
+	w := n.txWatches[txHash]
+	if w == nil {
+		return
+	}
+	w.refs--
+	if w.refs <= 0 {
+		delete(n.txWatches, txHash)
+	}
+}
+
+// notifyTxUpdate notifies any subscribers of the given transaction status update.
+func (n *Node) notifyTxUpdate(update *api.TxStatusUpdate) {
+	n.txWatchesLock.Lock()
+	defer n.txWatchesLock.Unlock()
+
+	if w := n.txWatches[update.TxHash]; w != nil {
+		w.notifier.Broadcast(update)
+	}
+}
+
+// watchTx converts the transaction status updates and the final result of the given transaction
+// into a stream of status updates. The stream is closed after the final status update or when the
+// context is canceled.
+func (n *Node) watchTx(
+	ctx context.Context,
+	txHash hash.Hash,
+	updateSub *pubsub.Subscription,
+	resultCh <-chan *api.SubmitTxResult,
+) <-chan *api.TxStatusUpdate {
+	updateCh := make(chan *api.TxStatusUpdate)
+	updateSub.Unwrap(updateCh)
+
+	ch := make(chan *api.TxStatusUpdate)
+	go func() {
+		defer close(ch)
+		defer n.unsubscribeTxUpdates(txHash, updateSub)
+
+		send := func(update *api.TxStatusUpdate) bool {
+			select {
+			case ch <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-updateCh:
+				if !ok || !send(update) {
+					return
+				}
+			case result := <-resultCh:
+				send(txResultToStatusUpdate(txHash, result))
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+func txResultToStatusUpdate(txHash hash.Hash, result *api.SubmitTxResult) *api.TxStatusUpdate {
+	update := &api.TxStatusUpdate{
+		TxHash: txHash,
+		Result: result.Result,
+	}
+	switch {
+	case errors.Is(result.Error, api.ErrCheckTxFailed):
+		update.Status = api.TxStatusRejected
+	case errors.Is(result.Error, api.ErrTransactionEvicted):
+		update.Status = api.TxStatusEvicted
+	case result.Error != nil:
+		update.Status = api.TxStatusExpired
+	case result.Result.CheckTxError != nil:
+		update.Status = api.TxStatusRejected
+	default:
+		update.Status = api.TxStatusIncluded
+		update.Round = result.Result.Round
+	}
+	return update
+}
+
 func (n *Node) CheckTx(ctx context.Context, tx []byte) (*protocol.CheckTxResult, error) {
 	return n.commonNode.TxPool.SubmitTx(ctx, tx, &txpool.TransactionMeta{Local: true, Discard: true})
 }
@@ -166,7 +421,7 @@ func (n *Node) Query(ctx context.Context, round uint64, method string, args []by
 	return hrt.Query(ctx, annBlk.Block, lb, epoch, maxMessages, method, args)
 }
 
-func (n *Node) checkBlock(ctx context.Context, blk *block.Block, pending map[hash.Hash]*pendingTx) error {
+func (n *Node) checkBlock(ctx context.Context, blk *block.Block, pending map[hash.Hash][]*pendingTx) error {
 	if blk.Header.IORoot.IsEmpty() {
 		return nil
 	}
@@ -176,14 +431,7 @@ func (n *Node) checkBlock(ctx context.Context, blk *block.Block, pending map[has
 		return nil
 	}
 
-	ioRoot := storage.Root{
-		Namespace: blk.Header.Namespace,
-		Version:   blk.Header.Round,
-		Type:      storage.RootTypeIO,
-		Hash:      blk.Header.IORoot,
-	}
-
-	tree := transaction.NewTree(n.commonNode.Runtime.Storage(), ioRoot)
+	tree := transaction.NewTree(n.commonNode.Runtime.Storage(), ioRootFromBlock(blk))
 	defer tree.Close()
 
 	// Check if there's anything interesting in this block.
@@ -199,15 +447,16 @@ func (n *Node) checkBlock(ctx context.Context, blk *block.Block, pending map[has
 
 	var processed []hash.Hash
 	for txHash, tx := range matches {
-		pTx := pending[txHash]
-		pTx.ch <- &api.SubmitTxResult{
-			Result: &api.SubmitTxMetaResponse{
-				Round:      blk.Header.Round,
-				BatchOrder: tx.BatchOrder,
-				Output:     tx.Output,
-			},
+		for _, pTx := range pending[txHash] {
+			pTx.ch <- &api.SubmitTxResult{
+				Result: &api.SubmitTxMetaResponse{
+					Round:      blk.Header.Round,
+					BatchOrder: tx.BatchOrder,
+					Output:     tx.Output,
+				},
+			}
+			close(pTx.ch)
 		}
-		close(pTx.ch)
 		delete(pending, txHash)
 		processed = append(processed, txHash)
 	}
@@ -218,6 +467,83 @@ func (n *Node) checkBlock(ctx context.Context, blk *block.Block, pending map[has
 	return nil
 }
 
+func ioRootFromBlock(blk *block.Block) storage.Root {
+	return storage.Root{
+		Namespace: blk.Header.Namespace,
+		Version:   blk.Header.Round,
+		Type:      storage.RootTypeIO,
+		Hash:      blk.Header.IORoot,
+	}
+}
+
+// handleTxEvents converts transaction pool events into transaction status updates and notifies
+// any pending submitters of evicted transactions.
+func (n *Node) handleTxEvents(events []*txpool.TxEvent, pending map[hash.Hash][]*pendingTx) {
+	for _, ev := range events {
+		var status api.TxStatus
+		switch ev.Status {
+		case txpool.TxStatusAccepted:
+			status = api.TxStatusChecked
+		case txpool.TxStatusPublished:
+			status = api.TxStatusGossiped
+		case txpool.TxStatusScheduled:
+			status = api.TxStatusScheduled
+		case txpool.TxStatusExpired, txpool.TxStatusRejected, txpool.TxStatusEvicted:
+			// Evicted transactions will never be included in a block.
+			err := txEvictionError(ev.Status)
+			for _, pTx := range pending[ev.Hash] {
+				pTx.ch <- &api.SubmitTxResult{
+					Error: err,
+				}
+				close(pTx.ch)
+			}
+			delete(pending, ev.Hash)
+			continue
+		default:
+			// Included transactions are reported together with their results once the block is
+			// checked.
+			continue
+		}
+
+		n.notifyTxUpdate(&api.TxStatusUpdate{
+			TxHash: ev.Hash,
+			Status: status,
+			Round:  ev.Round,
+		})
+	}
+}
+
+// txEvictionError returns the error reported to submitters of a transaction that has been evicted
+// from the transaction pool with the given status.
+func txEvictionError(status txpool.TxStatus) error {
+	switch status {
+	case txpool.TxStatusExpired:
+		return api.ErrTransactionExpired
+	case txpool.TxStatusRejected:
+		return errors.WithContext(api.ErrCheckTxFailed, "transaction failed a recheck")
+	default:
+		return api.ErrTransactionEvicted
+	}
+}
+
+// prunePending removes pending transactions whose submitters are no longer interested in the
+// result.
+func prunePending(pending map[hash.Hash][]*pendingTx) {
+	for txHash, pTxs := range pending {
+		var active []*pendingTx
+		for _, pTx := range pTxs {
+			if pTx.ctx.Err() == nil {
+				active = append(active, pTx)
+			}
+		}
+		if len(active) == 0 {
+			delete(pending, txHash)
+			continue
+		}
+		pending[txHash] = active
+	}
+}
+
 func (n *Node) worker() {
 	defer close(n.quitCh)
 
@@ -237,6 +563,24 @@ func (n *Node) worker() {
 		cancel()
 	}()
 
+	// Start the transaction indexer if enabled.
+	if n.indexer != nil {
+		if err := n.indexer.Start(); err != nil {
+			n.logger.Error("failed to start transaction indexer",
+				"err", err,
+			)
+			close(n.initCh)
+			return
+		}
+		defer func() {
+			n.indexer.Stop()
+			<-n.indexer.Quit()
+		}()
+	}
+
+	txEventSub, txEventCh := n.commonNode.TxPool.WatchTxEvents()
+	defer txEventSub.Close()
+
 	// We are initialized.
 	close(n.initCh)
 
@@ -244,7 +588,7 @@ func (n *Node) worker() {
 		recheckTicker *backoff.Ticker
 		blocks        []*block.Block
 	)
-	pending := make(map[hash.Hash]*pendingTx)
+	pending := make(map[hash.Hash][]*pendingTx)
 	for {
 		var recheckCh <-chan time.Time
 		if recheckTicker != nil {
@@ -257,13 +601,18 @@ func (n *Node) worker() {
 			return
 		case rtx := <-n.txCh.Out():
 			tx := rtx.(*pendingTx)
-			pending[tx.txHash] = tx
+			pending[tx.txHash] = append(pending[tx.txHash], tx)
+			continue
+		case events := <-txEventCh:
+			n.handleTxEvents(events, pending)
 			continue
 		case blk := <-n.checkCh.Out():
 			blocks = append(blocks, blk.(*block.Block))
 		case <-recheckCh:
 		}
 
+		prunePending(pending)
+
 		// Check blocks.
 		var failedBlocks []*block.Block
 		for _, blk := range blocks {
@@ -295,14 +644,18 @@ func (n *Node) worker() {
 }
 
 // NewNode creates a new client node.
-func NewNode(commonNode *committee.Node) (*Node, error) {
+//
+// The transaction indexer is optional and may be nil.
+func NewNode(commonNode *committee.Node, indexer *indexer.Indexer) (*Node, error) {
 	n := &Node{
 		commonNode: commonNode,
+		indexer:    indexer,
 		stopCh:     make(chan struct{}),
 		quitCh:     make(chan struct{}),
 		initCh:     make(chan struct{}),
 		checkCh:    channels.NewInfiniteChannel(),
 		txCh:       channels.NewInfiniteChannel(),
+		txWatches:  make(map[hash.Hash]*txWatch),
 		logger:     logging.GetLogger("worker/client/committee").With("runtime_id", commonNode.Runtime.ID()),
 	}
 	return n, nil
@@ -0,0 +1,144 @@
+package committee
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
+)
+
+const recvTimeout = 1 * time.Second
+
+func newTestNode() *Node {
+	return &Node{
+		txWatches: make(map[hash.Hash]*txWatch),
+		logger:    logging.GetLogger("worker/client/committee/test"),
+	}
+}
+
+func requireUpdate(t *testing.T, ch <-chan *api.TxStatusUpdate, status api.TxStatus) *api.TxStatusUpdate {
+	select {
+	case update, ok := <-ch:
+		require.True(t, ok, "stream should not be closed")
+		require.Equal(t, status, update.Status, "status update should be correct")
+		return update
+	case <-time.After(recvTimeout):
+		require.FailNow(t, "timed out waiting for status update", "status: %s", status)
+		return nil
+	}
+}
+
+func requireClosed(t *testing.T, ch <-chan *api.TxStatusUpdate) {
+	select {
+	case update, ok := <-ch:
+		require.False(t, ok, "stream should be closed (got: %+v)", update)
+	case <-time.After(recvTimeout):
+		require.FailNow(t, "timed out waiting for stream to be closed")
+	}
+}
+
+func TestWatchTx(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	n := newTestNode()
+	txHash := hash.NewFromBytes([]byte("tx"))
+	otherHash := hash.NewFromBytes([]byte("other"))
+
+	resultCh := make(chan *api.SubmitTxResult, 1)
+	ch := n.watchTx(ctx, txHash, n.subscribeTxUpdates(txHash), resultCh)
+	require.Len(n.txWatches, 1, "watch should be registered")
+
+	// Only updates for the watched transaction should be delivered.
+	pending := make(map[hash.Hash][]*pendingTx)
+	n.handleTxEvents([]*txpool.TxEvent{
+		{Hash: otherHash, Status: txpool.TxStatusAccepted, Round: 10},
+		{Hash: txHash, Status: txpool.TxStatusAccepted, Round: 10},
+		{Hash: otherHash, Status: txpool.TxStatusPublished, Round: 10},
+		{Hash: txHash, Status: txpool.TxStatusPublished, Round: 10},
+		{Hash: txHash, Status: txpool.TxStatusScheduled, Round: 11},
+	}, pending)
+
+	update := requireUpdate(t, ch, api.TxStatusChecked)
+	require.Equal(txHash, update.TxHash, "update should be for the watched transaction")
+	require.EqualValues(10, update.Round, "update round should be correct")
+	requireUpdate(t, ch, api.TxStatusGossiped)
+	update = requireUpdate(t, ch, api.TxStatusScheduled)
+	require.EqualValues(11, update.Round, "update round should be correct")
+
+	// The stream should be closed after the final status update.
+	resultCh <- &api.SubmitTxResult{
+		Result: &api.SubmitTxMetaResponse{Round: 11, BatchOrder: 1},
+	}
+	update = requireUpdate(t, ch, api.TxStatusIncluded)
+	require.EqualValues(11, update.Round, "update round should be correct")
+	requireClosed(t, ch)
+
+	// The watch should be removed once the stream is closed.
+	require.Eventually(func() bool {
+		n.txWatchesLock.Lock()
+		defer n.txWatchesLock.Unlock()
+		return len(n.txWatches) == 0
+	}, recvTimeout, 10*time.Millisecond, "watch should be removed")
+
+	// Canceling the context should close the stream.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	ch = n.watchTx(cancelCtx, txHash, n.subscribeTxUpdates(txHash), make(chan *api.SubmitTxResult))
+	cancel()
+	requireClosed(t, ch)
+}
+
+func TestHandleTxEventsEvicted(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	n := newTestNode()
+	for _, tc := range []struct {
+		status       txpool.TxStatus
+		updateStatus api.TxStatus
+	}{
+		{txpool.TxStatusExpired, api.TxStatusExpired},
+		{txpool.TxStatusRejected, api.TxStatusRejected},
+		{txpool.TxStatusEvicted, api.TxStatusEvicted},
+	} {
+		txHash := hash.NewFromBytes([]byte(tc.status.String()))
+		resultCh := make(chan *api.SubmitTxResult, 1)
+		pending := map[hash.Hash][]*pendingTx{
+			txHash: {{ctx: ctx, txHash: txHash, ch: resultCh}},
+		}
+		ch := n.watchTx(ctx, txHash, n.subscribeTxUpdates(txHash), resultCh)
+
+		n.handleTxEvents([]*txpool.TxEvent{
+			{Hash: txHash, Status: tc.status, Round: 10},
+		}, pending)
+		require.Empty(pending, "evicted transaction should no longer be pending")
+
+		requireUpdate(t, ch, tc.updateStatus)
+		requireClosed(t, ch)
+
+		result := <-resultCh
+		require.Nil(result, "result channel should be closed")
+	}
+
+	// Errors should be mapped to the corresponding final statuses.
+	txHash := hash.NewFromBytes([]byte("tx"))
+	for _, tc := range []struct {
+		err    error
+		status api.TxStatus
+	}{
+		{api.ErrTransactionExpired, api.TxStatusExpired},
+		{errors.WithContext(api.ErrCheckTxFailed, "recheck"), api.TxStatusRejected},
+		{api.ErrTransactionEvicted, api.TxStatusEvicted},
+	} {
+		update := txResultToStatusUpdate(txHash, &api.SubmitTxResult{Error: tc.err})
+		require.Equal(tc.status, update.Status, "status should be correct for %v", tc.err)
+		require.True(update.Status.IsFinal(), "status should be final")
+	}
+}
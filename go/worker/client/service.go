@@ -13,6 +13,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/indexer"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 )
@@ -22,7 +23,7 @@ type service struct {
 }
 
 func (s *service) submitTx(ctx context.Context, request *api.SubmitTxRequest) (<-chan *api.SubmitTxResult, *protocol.Error, error) {
-	rt := s.w.runtimes[request.RuntimeID]
+	rt := s.w.getRuntime(request.RuntimeID)
 	if rt == nil {
 		return nil, nil, api.ErrNoHostedRuntime
 	}
@@ -84,9 +85,29 @@ func (s *service) SubmitTxNoWait(ctx context.Context, request *api.SubmitTxReque
 	return nil
 }
 
+// Implements api.RuntimeClient.
+func (s *service) SubmitTxStream(ctx context.Context, request *api.SubmitTxRequest) (<-chan *api.TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	rt := s.w.getRuntime(request.RuntimeID)
+	if rt == nil {
+		return nil, nil, api.ErrNoHostedRuntime
+	}
+
+	return rt.SubmitTxStream(ctx, request.Data)
+}
+
+// Implements api.RuntimeClient.
+func (s *service) WatchTx(ctx context.Context, request *api.WatchTxRequest) (<-chan *api.TxStatusUpdate, pubsub.ClosableSubscription, error) {
+	rt := s.w.getRuntime(request.RuntimeID)
+	if rt == nil {
+		return nil, nil, api.ErrNoHostedRuntime
+	}
+
+	return rt.WatchTx(ctx, request.TxHash)
+}
+
 // Implements api.RuntimeClient.
 func (s *service) CheckTx(ctx context.Context, request *api.CheckTxRequest) error {
-	rt := s.w.runtimes[request.RuntimeID]
+	rt := s.w.getRuntime(request.RuntimeID)
 	if rt == nil {
 		return api.ErrNoHostedRuntime
 	}
@@ -268,9 +289,132 @@ func (s *service) GetEvents(ctx context.Context, request *api.GetEventsRequest)
 	return events, nil
 }
 
+func (s *service) getIndexer(runtimeID common.Namespace) (*indexer.Indexer, error) {
+	rt := s.w.getRuntime(runtimeID)
+	if rt == nil {
+		return nil, api.ErrNoHostedRuntime
+	}
+
+	idx := rt.Indexer()
+	if idx == nil {
+		return nil, api.ErrIndexerDisabled
+	}
+	return idx, nil
+}
+
+// getIndexedTransactions fetches the transactions referenced by the given index results together
+// with their results and emitted events.
+func (s *service) getIndexedTransactions(
+	ctx context.Context,
+	runtimeID common.Namespace,
+	results []*indexer.Result,
+) ([]*api.IndexedTransaction, error) {
+	rt, err := s.w.commonWorker.RuntimeRegistry.GetRuntime(runtimeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group results by round so that each I/O tree only needs to be fetched once.
+	var rounds []uint64
+	byRound := make(map[uint64][]hash.Hash)
+	for _, result := range results {
+		if _, ok := byRound[result.Round]; !ok {
+			rounds = append(rounds, result.Round)
+		}
+		byRound[result.Round] = append(byRound[result.Round], result.TxHash)
+	}
+
+	txs := make(map[uint64]map[hash.Hash]*transaction.Transaction)
+	events := make(map[uint64]map[hash.Hash][]*api.PlainEvent)
+	for _, round := range rounds {
+		blk, err := rt.History().GetBlock(ctx, round)
+		if err != nil {
+			return nil, err
+		}
+
+		tree := s.getTxnTree(rt.Storage(), blk)
+		matches, err := tree.GetTransactionMultiple(ctx, byRound[round])
+		if err != nil {
+			tree.Close()
+			return nil, err
+		}
+		tags, err := tree.GetTags(ctx)
+		tree.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		eventsByHash := make(map[hash.Hash][]*api.PlainEvent)
+		for _, tag := range tags {
+			if _, ok := matches[tag.TxHash]; !ok {
+				continue
+			}
+			eventsByHash[tag.TxHash] = append(eventsByHash[tag.TxHash], &api.PlainEvent{
+				Key:   tag.Key,
+				Value: tag.Value,
+			})
+		}
+
+		txs[round] = matches
+		events[round] = eventsByHash
+	}
+
+	indexed := make([]*api.IndexedTransaction, 0, len(results))
+	for _, result := range results {
+		tx, ok := txs[result.Round][result.TxHash]
+		if !ok {
+			return nil, fmt.Errorf("client: indexed transaction %s not found in round %d", result.TxHash, result.Round)
+		}
+
+		indexed = append(indexed, &api.IndexedTransaction{
+			Round:      result.Round,
+			BatchOrder: result.BatchOrder,
+			TxHash:     result.TxHash,
+			Tx:         tx.Input,
+			Result:     tx.Output,
+			Events:     events[result.Round][result.TxHash],
+		})
+	}
+	return indexed, nil
+}
+
+// Implements api.RuntimeClient.
+func (s *service) GetTransactionByHash(ctx context.Context, request *api.GetTransactionByHashRequest) (*api.IndexedTransaction, error) {
+	idx, err := s.getIndexer(request.RuntimeID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := idx.GetTransaction(ctx, request.TxHash)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := s.getIndexedTransactions(ctx, request.RuntimeID, []*indexer.Result{result})
+	if err != nil {
+		return nil, err
+	}
+	return txs[0], nil
+}
+
+// Implements api.RuntimeClient.
+func (s *service) QueryTxs(ctx context.Context, request *api.QueryTxsRequest) ([]*api.IndexedTransaction, error) {
+	idx, err := s.getIndexer(request.RuntimeID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := idx.QueryTxs(ctx, &request.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getIndexedTransactions(ctx, request.RuntimeID, results)
+}
+
 // Implements api.RuntimeClient.
 func (s *service) Query(ctx context.Context, request *api.QueryRequest) (*api.QueryResponse, error) {
-	rt := s.w.runtimes[request.RuntimeID]
+	rt := s.w.getRuntime(request.RuntimeID)
 	if rt == nil {
 		return nil, api.ErrNoHostedRuntime
 	}
@@ -2,10 +2,15 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/grpc"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/indexer"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	"github.com/oasisprotocol/oasis-core/go/worker/client/committee"
 	workerCommon "github.com/oasisprotocol/oasis-core/go/worker/common"
@@ -18,8 +23,10 @@ type Worker struct {
 
 	commonWorker *workerCommon.Worker
 
-	runtimes map[common.Namespace]*committee.Node
+	runtimesLock sync.RWMutex
+	runtimes     map[common.Namespace]*committee.Node
 
+	stopCh chan struct{}
 	quitCh chan struct{}
 	initCh chan struct{}
 
@@ -46,22 +53,18 @@ func (w *Worker) Start() error {
 	go func() {
 		defer close(w.quitCh)
 
-		for _, rt := range w.runtimes {
-			<-rt.Quit()
-		}
+		workerCommon.WaitRuntimesQuit(w.stopCh, w.getRuntimeServices)
 	}()
 
 	// Wait for all runtimes to be initialized.
 	go func() {
-		for _, rt := range w.runtimes {
-			<-rt.Initialized()
-		}
+		workerCommon.WaitRuntimesInitialized(w.getRuntimeServices)
 
 		close(w.initCh)
 	}()
 
 	// Start runtime services.
-	for id, rt := range w.runtimes {
+	for id, rt := range w.getRuntimes() {
 		w.logger.Info("starting services for runtime",
 			"runtime_id", id,
 		)
@@ -81,7 +84,12 @@ func (w *Worker) Stop() {
 		return
 	}
 
-	for id, rt := range w.runtimes {
+	// Prevent any new runtimes from being added.
+	w.runtimesLock.Lock()
+	close(w.stopCh)
+	w.runtimesLock.Unlock()
+
+	for id, rt := range w.getRuntimes() {
 		w.logger.Info("stopping services for runtime",
 			"runtime_id", id,
 		)
@@ -106,7 +114,7 @@ func (w *Worker) Cleanup() {
 		return
 	}
 
-	for _, rt := range w.runtimes {
+	for _, rt := range w.getRuntimes() {
 		rt.Cleanup()
 	}
 }
@@ -117,6 +125,77 @@ func (w *Worker) Initialized() <-chan struct{} {
 	return w.initCh
 }
 
+func (w *Worker) getRuntime(id common.Namespace) *committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	return w.runtimes[id]
+}
+
+func (w *Worker) getRuntimes() map[common.Namespace]*committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make(map[common.Namespace]*committee.Node, len(w.runtimes))
+	for id, rt := range w.runtimes {
+		runtimes[id] = rt
+	}
+	return runtimes
+}
+
+func (w *Worker) getRuntimeServices() []workerCommon.RuntimeService {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make([]workerCommon.RuntimeService, 0, len(w.runtimes))
+	for _, rt := range w.runtimes {
+		runtimes = append(runtimes, rt)
+	}
+	return runtimes
+}
+
+// AddRuntime registers and starts a client committee node for a runtime that has been added
+// while the worker is running.
+func (w *Worker) AddRuntime(commonNode *committeeCommon.Node) error {
+	if !w.enabled {
+		return nil
+	}
+
+	if err := w.registerRuntime(commonNode); err != nil {
+		return err
+	}
+	return w.getRuntime(commonNode.Runtime.ID()).Start()
+}
+
+// RemoveRuntime stops the client committee node for the given runtime and unregisters it.
+func (w *Worker) RemoveRuntime(ctx context.Context, id common.Namespace) error {
+	w.runtimesLock.Lock()
+	rt, ok := w.runtimes[id]
+	delete(w.runtimes, id)
+	w.runtimesLock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	w.logger.Info("stopping services for runtime",
+		"runtime_id", id,
+	)
+
+	rt.Stop()
+	select {
+	case <-rt.Quit():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	rt.Cleanup()
+
+	w.logger.Info("runtime removed",
+		"runtime_id", id,
+	)
+
+	return nil
+}
+
 func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 	id := commonNode.Runtime.ID()
 
@@ -124,12 +203,36 @@ func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 		"runtime_id", id,
 	)
 
+	// Create the transaction indexer for the given runtime if enabled.
+	var idx *indexer.Indexer
+	if indexer.Enabled() {
+		var err error
+		idx, err = indexer.New(w.commonWorker.DataDir, commonNode.Runtime, commonNode.Consensus)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Submit evidence of any equivocation observed by the client node if enabled.
+	if w.commonWorker.GetConfig().EquivocationWatcher {
+		commonNode.EquivocationWatcher()
+	}
+
 	// Create committee node for the given runtime.
-	node, err := committee.NewNode(commonNode)
+	node, err := committee.NewNode(commonNode, idx)
 	if err != nil {
 		return err
 	}
 
+	w.runtimesLock.Lock()
+	defer w.runtimesLock.Unlock()
+
+	select {
+	case <-w.stopCh:
+		return fmt.Errorf("worker/client: worker is stopped")
+	default:
+	}
+
 	commonNode.AddHooks(node)
 	w.runtimes[id] = node
 
@@ -155,6 +258,7 @@ func New(grpcInternal *grpc.Server, commonWorker *workerCommon.Worker) (*Worker,
 		enabled:      enabled,
 		commonWorker: commonWorker,
 		runtimes:     make(map[common.Namespace]*committee.Node),
+		stopCh:       make(chan struct{}),
 		quitCh:       make(chan struct{}),
 		initCh:       make(chan struct{}),
 		logger:       logging.GetLogger("worker/client"),
@@ -0,0 +1,252 @@
+package committee
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+)
+
+// equivocationRetainRounds is the number of past rounds for which the equivocation watcher keeps
+// observed proposals and executor commitments.
+const equivocationRetainRounds = 16
+
+type equivocationKey struct {
+	round  uint64
+	nodeID signature.PublicKey
+}
+
+// EquivocationWatcher keeps track of proposals and executor commitments observed for a runtime
+// and submits equivocation evidence in case a node signs conflicting ones for the same round.
+//
+// Executor commitments are observed in roothash events and in executor commit transactions
+// included in consensus blocks, as the roothash commitment pool rejects any subsequent commitment
+// from a node that already committed in the same round.
+type EquivocationWatcher struct {
+	sync.Mutex
+
+	node      *Node
+	runtimeID common.Namespace
+
+	proposals map[equivocationKey]*commitment.Proposal
+	commits   map[equivocationKey]*commitment.ExecutorCommitment
+	reported  map[equivocationKey]bool
+
+	logger *logging.Logger
+}
+
+// ObserveProposal records the given (already verified) proposal and submits equivocation evidence
+// in case it conflicts with a previously observed proposal from the same node for the same round.
+func (w *EquivocationWatcher) ObserveProposal(proposal *commitment.Proposal) {
+	w.submitEvidence(w.observeProposal(proposal))
+}
+
+func (w *EquivocationWatcher) observeProposal(proposal *commitment.Proposal) *roothash.Evidence {
+	w.Lock()
+	defer w.Unlock()
+
+	// Batch must be omitted when the proposal is submitted as evidence.
+	p := *proposal
+	p.Batch = nil
+
+	key := equivocationKey{round: p.Header.Round, nodeID: p.NodeID}
+	existing, ok := w.proposals[key]
+	if !ok {
+		w.proposals[key] = &p
+		return nil
+	}
+	if existing.Header.Equal(&p.Header) {
+		return nil
+	}
+
+	return w.checkEvidenceLocked(key, &roothash.Evidence{
+		ID: w.runtimeID,
+		EquivocationProposal: &roothash.EquivocationProposalEvidence{
+			ProposalA: *existing,
+			ProposalB: p,
+		},
+	})
+}
+
+// observeExecutorCommitment records the given (already verified) executor commitment and returns
+// equivocation evidence in case it conflicts with a previously observed commitment from the same
+// node for the same round.
+func (w *EquivocationWatcher) observeExecutorCommitment(commit *commitment.ExecutorCommitment) *roothash.Evidence {
+	w.Lock()
+	defer w.Unlock()
+
+	// Messages must be omitted when the commitment is submitted as evidence.
+	c := *commit
+	c.Messages = nil
+
+	key := equivocationKey{round: c.Header.Round, nodeID: c.NodeID}
+	existing, ok := w.commits[key]
+	if !ok {
+		w.commits[key] = &c
+		return nil
+	}
+	if existing.Header.MostlyEqual(&c.Header) {
+		return nil
+	}
+
+	return w.checkEvidenceLocked(key, &roothash.Evidence{
+		ID: w.runtimeID,
+		EquivocationExecutor: &roothash.EquivocationExecutorEvidence{
+			CommitA: *existing,
+			CommitB: c,
+		},
+	})
+}
+
+// observeConsensusTransactions records executor commitments for the watched runtime contained in
+// the given raw consensus transactions and returns any resulting equivocation evidence.
+//
+// Transactions are observed regardless of whether they were executed successfully, as conflicting
+// commitments are rejected by the roothash commitment pool but still included in the block.
+func (w *EquivocationWatcher) observeConsensusTransactions(rawTxs [][]byte) []*roothash.Evidence {
+	var evidence []*roothash.Evidence
+	for _, rawTx := range rawTxs {
+		var sigTx transaction.SignedTransaction
+		if err := cbor.Unmarshal(rawTx, &sigTx); err != nil {
+			continue
+		}
+		var tx transaction.Transaction
+		if err := sigTx.Open(&tx); err != nil {
+			continue
+		}
+		if tx.Method != roothash.MethodExecutorCommit {
+			continue
+		}
+		var xc roothash.ExecutorCommit
+		if err := cbor.Unmarshal(tx.Body, &xc); err != nil {
+			continue
+		}
+		if !xc.ID.Equal(&w.runtimeID) {
+			continue
+		}
+
+		for i := range xc.Commits {
+			commit := &xc.Commits[i]
+			if err := commit.Verify(w.runtimeID); err != nil {
+				w.logger.Debug("ignoring executor commitment with invalid signature",
+					"round", commit.Header.Round,
+					"node_id", commit.NodeID,
+					"err", err,
+				)
+				continue
+			}
+			if ev := w.observeExecutorCommitment(commit); ev != nil {
+				evidence = append(evidence, ev)
+			}
+		}
+	}
+	return evidence
+}
+
+// handleConsensusBlock observes the executor commitments contained in the consensus block at the
+// given height.
+func (w *EquivocationWatcher) handleConsensusBlock(height int64) {
+	rawTxs, err := w.node.Consensus.GetTransactions(w.node.ctx, height)
+	if err != nil {
+		w.logger.Error("failed to fetch consensus transactions",
+			"err", err,
+			"height", height,
+		)
+		return
+	}
+
+	for _, ev := range w.observeConsensusTransactions(rawTxs) {
+		w.submitEvidence(ev)
+	}
+}
+
+func (w *EquivocationWatcher) checkEvidenceLocked(key equivocationKey, ev *roothash.Evidence) *roothash.Evidence {
+	if w.reported[key] {
+		return nil
+	}
+	if err := ev.ValidateBasic(); err != nil {
+		w.logger.Debug("ignoring conflicting messages that are not valid evidence",
+			"round", key.round,
+			"node_id", key.nodeID,
+			"err", err,
+		)
+		return nil
+	}
+	w.reported[key] = true
+
+	return ev
+}
+
+// prune removes all observations that are too old to be useful as evidence.
+func (w *EquivocationWatcher) prune(round uint64) {
+	w.Lock()
+	defer w.Unlock()
+
+	if round < equivocationRetainRounds {
+		return
+	}
+	minRound := round - equivocationRetainRounds
+
+	for key := range w.proposals {
+		if key.round < minRound {
+			delete(w.proposals, key)
+		}
+	}
+	for key := range w.commits {
+		if key.round < minRound {
+			delete(w.commits, key)
+		}
+	}
+	for key := range w.reported {
+		if key.round < minRound {
+			delete(w.reported, key)
+		}
+	}
+}
+
+// submitEvidence submits the given equivocation evidence to the consensus layer.
+func (w *EquivocationWatcher) submitEvidence(ev *roothash.Evidence) {
+	if ev == nil {
+		return
+	}
+
+	w.logger.Warn("equivocation detected, submitting evidence",
+		"evidence", ev,
+	)
+	equivocationEvidenceCount.With(w.node.getMetricLabels()).Inc()
+
+	go func() {
+		tx := roothash.NewEvidenceTx(0, nil, ev)
+		err := consensus.SignAndSubmitTx(w.node.ctx, w.node.Consensus, w.node.Identity.NodeSigner, tx)
+		switch {
+		case err == nil:
+			w.logger.Info("equivocation evidence submitted")
+		case errors.Is(err, roothash.ErrDuplicateEvidence):
+			// Someone else has already submitted evidence for the same misbehaviour.
+			w.logger.Debug("equivocation evidence already submitted")
+		default:
+			w.logger.Error("failed to submit equivocation evidence",
+				"err", err,
+			)
+		}
+	}()
+}
+
+func newEquivocationWatcher(node *Node) *EquivocationWatcher {
+	runtimeID := node.Runtime.ID()
+	return &EquivocationWatcher{
+		node:      node,
+		runtimeID: runtimeID,
+		proposals: make(map[equivocationKey]*commitment.Proposal),
+		commits:   make(map[equivocationKey]*commitment.ExecutorCommitment),
+		reported:  make(map[equivocationKey]bool),
+		logger:    logging.GetLogger("worker/common/committee/equivocation").With("runtime_id", runtimeID),
+	}
+}
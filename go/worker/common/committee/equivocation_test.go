@@ -0,0 +1,207 @@
+package committee
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+)
+
+type testRuntime struct {
+	runtimeRegistry.Runtime
+
+	id common.Namespace
+}
+
+func (r *testRuntime) ID() common.Namespace {
+	return r.id
+}
+
+func newTestEquivocationWatcher(runtimeID common.Namespace) *EquivocationWatcher {
+	return newEquivocationWatcher(&Node{Runtime: &testRuntime{id: runtimeID}})
+}
+
+func newTestProposal(t *testing.T, signer signature.Signer, runtimeID common.Namespace, round uint64, batch string) *commitment.Proposal {
+	var batchHash hash.Hash
+	batchHash.FromBytes([]byte(batch))
+
+	proposal := &commitment.Proposal{
+		NodeID: signer.Public(),
+		Header: commitment.ProposalHeader{
+			Round:     round,
+			BatchHash: batchHash,
+		},
+		Batch: []hash.Hash{batchHash},
+	}
+	err := proposal.Sign(signer, runtimeID)
+	require.NoError(t, err, "Sign")
+	return proposal
+}
+
+func newTestExecutorCommitment(t *testing.T, signer signature.Signer, runtimeID common.Namespace, round uint64, state string) *commitment.ExecutorCommitment {
+	var ioRoot, stateRoot, messagesHash hash.Hash
+	ioRoot.Empty()
+	stateRoot.FromBytes([]byte(state))
+	messagesHash.Empty()
+
+	commit := &commitment.ExecutorCommitment{
+		NodeID: signer.Public(),
+		Header: commitment.ExecutorCommitmentHeader{
+			ComputeResultsHeader: commitment.ComputeResultsHeader{
+				Round:        round,
+				IORoot:       &ioRoot,
+				StateRoot:    &stateRoot,
+				MessagesHash: &messagesHash,
+			},
+		},
+	}
+	err := commit.Sign(signer, runtimeID)
+	require.NoError(t, err, "Sign")
+	return commit
+}
+
+func newTestExecutorCommitTx(t *testing.T, signer signature.Signer, runtimeID common.Namespace, commits ...*commitment.ExecutorCommitment) []byte {
+	var cs []commitment.ExecutorCommitment
+	for _, commit := range commits {
+		cs = append(cs, *commit)
+	}
+	sigTx, err := transaction.Sign(signer, roothash.NewExecutorCommitTx(0, nil, runtimeID, cs))
+	require.NoError(t, err, "transaction.Sign")
+	return cbor.Marshal(sigTx)
+}
+
+func TestEquivocationWatcherProposals(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("equivocation watcher"), 0)
+	signer, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+	otherSigner, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	w := newTestEquivocationWatcher(runtimeID)
+
+	proposalA := newTestProposal(t, signer, runtimeID, 10, "batch a")
+	require.Nil(w.observeProposal(proposalA), "first proposal should not produce evidence")
+	require.Nil(w.observeProposal(proposalA), "identical proposal should not produce evidence")
+	require.Len(proposalA.Batch, 1, "observed proposal should not be modified")
+
+	// Proposals from other nodes or for other rounds do not conflict.
+	require.Nil(w.observeProposal(newTestProposal(t, otherSigner, runtimeID, 10, "batch b")))
+	require.Nil(w.observeProposal(newTestProposal(t, signer, runtimeID, 11, "batch b")))
+
+	// A conflicting proposal for the same round should produce evidence.
+	proposalB := newTestProposal(t, signer, runtimeID, 10, "batch b")
+	ev := w.observeProposal(proposalB)
+	require.NotNil(ev, "conflicting proposal should produce evidence")
+	require.Equal(runtimeID, ev.ID, "evidence should be for the watched runtime")
+	require.NotNil(ev.EquivocationProposal, "evidence should be proposal equivocation evidence")
+	require.Nil(ev.EquivocationExecutor)
+	require.True(ev.EquivocationProposal.ProposalA.Header.Equal(&proposalA.Header))
+	require.True(ev.EquivocationProposal.ProposalB.Header.Equal(&proposalB.Header))
+	require.NoError(ev.ValidateBasic(), "evidence should be valid")
+
+	// Evidence should only be reported once.
+	require.Nil(w.observeProposal(newTestProposal(t, signer, runtimeID, 10, "batch c")), "evidence should only be reported once")
+
+	// Proposals with invalid signatures are not valid evidence.
+	invalid := newTestProposal(t, signer, runtimeID, 11, "batch c")
+	invalid.Signature = proposalA.Signature
+	require.Nil(w.observeProposal(invalid), "invalid evidence should not be reported")
+
+	// Pruning should forget old rounds.
+	w.prune(11 + equivocationRetainRounds + 1)
+	require.Empty(w.proposals, "old proposals should be pruned")
+	require.Empty(w.reported, "old reports should be pruned")
+}
+
+func TestEquivocationWatcherExecutorCommitments(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("equivocation watcher"), 0)
+	signer, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+	otherSigner, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	w := newTestEquivocationWatcher(runtimeID)
+
+	commitA := newTestExecutorCommitment(t, signer, runtimeID, 10, "state a")
+	require.Nil(w.observeExecutorCommitment(commitA), "first commitment should not produce evidence")
+	require.Nil(w.observeExecutorCommitment(commitA), "identical commitment should not produce evidence")
+
+	// Commitments from other nodes or for other rounds do not conflict.
+	require.Nil(w.observeExecutorCommitment(newTestExecutorCommitment(t, otherSigner, runtimeID, 10, "state b")))
+	require.Nil(w.observeExecutorCommitment(newTestExecutorCommitment(t, signer, runtimeID, 11, "state b")))
+
+	// A conflicting commitment for the same round should produce evidence.
+	commitB := newTestExecutorCommitment(t, signer, runtimeID, 10, "state b")
+	ev := w.observeExecutorCommitment(commitB)
+	require.NotNil(ev, "conflicting commitment should produce evidence")
+	require.Equal(runtimeID, ev.ID, "evidence should be for the watched runtime")
+	require.NotNil(ev.EquivocationExecutor, "evidence should be executor equivocation evidence")
+	require.Nil(ev.EquivocationProposal)
+	require.True(ev.EquivocationExecutor.CommitA.Header.MostlyEqual(&commitA.Header))
+	require.True(ev.EquivocationExecutor.CommitB.Header.MostlyEqual(&commitB.Header))
+	require.NoError(ev.ValidateBasic(), "evidence should be valid")
+
+	// Evidence should only be reported once.
+	require.Nil(w.observeExecutorCommitment(newTestExecutorCommitment(t, signer, runtimeID, 10, "state c")), "evidence should only be reported once")
+
+	// Pruning should forget old rounds.
+	w.prune(11 + equivocationRetainRounds + 1)
+	require.Empty(w.commits, "old commitments should be pruned")
+	require.Empty(w.reported, "old reports should be pruned")
+}
+
+func TestEquivocationWatcherConsensusTransactions(t *testing.T) {
+	require := require.New(t)
+
+	signature.SetChainContext("test: oasis-core tests")
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("equivocation watcher"), 0)
+	otherRuntimeID := common.NewTestNamespaceFromSeed([]byte("equivocation watcher"), 1)
+	signer, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner")
+
+	w := newTestEquivocationWatcher(runtimeID)
+
+	commitA := newTestExecutorCommitment(t, signer, runtimeID, 10, "state a")
+	commitB := newTestExecutorCommitment(t, signer, runtimeID, 10, "state b")
+	invalid := newTestExecutorCommitment(t, signer, runtimeID, 10, "state c")
+	invalid.Signature = commitA.Signature
+
+	evidence := w.observeConsensusTransactions([][]byte{
+		[]byte("not a transaction"),
+		// Commitments for other runtimes and invalid commitments should be ignored.
+		newTestExecutorCommitTx(t, signer, otherRuntimeID, newTestExecutorCommitment(t, signer, otherRuntimeID, 10, "state d")),
+		newTestExecutorCommitTx(t, signer, runtimeID, invalid),
+		newTestExecutorCommitTx(t, signer, runtimeID, commitA),
+	})
+	require.Empty(evidence, "non-conflicting transactions should not produce evidence")
+
+	// A conflicting commitment in a later block should produce evidence, even though it would be
+	// rejected by the commitment pool.
+	evidence = w.observeConsensusTransactions([][]byte{
+		newTestExecutorCommitTx(t, signer, runtimeID, commitB),
+	})
+	require.Len(evidence, 1, "conflicting commitment should produce evidence")
+	require.NotNil(evidence[0].EquivocationExecutor, "evidence should be executor equivocation evidence")
+	require.True(evidence[0].EquivocationExecutor.CommitA.Header.MostlyEqual(&commitA.Header))
+	require.True(evidence[0].EquivocationExecutor.CommitB.Header.MostlyEqual(&commitB.Header))
+	require.NoError(evidence[0].ValidateBasic(), "evidence should be valid")
+}
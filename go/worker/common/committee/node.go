@@ -11,6 +11,7 @@ import (
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common/identity"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	control "github.com/oasisprotocol/oasis-core/go/control/api"
 	keymanagerApi "github.com/oasisprotocol/oasis-core/go/keymanager/api"
@@ -19,10 +20,12 @@ import (
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/multi"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/txsync"
 )
 
 var (
@@ -61,6 +64,13 @@ var (
 		},
 		[]string{"runtime"},
 	)
+	equivocationEvidenceCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_execution_equivocation_evidence_count",
+			Help: "Number of equivocation evidence submissions.",
+		},
+		[]string{"runtime"},
+	)
 
 	nodeCollectors = []prometheus.Collector{
 		processedBlockCount,
@@ -68,6 +78,7 @@ var (
 		failedRoundCount,
 		epochTransitionCount,
 		epochNumber,
+		equivocationEvidenceCount,
 	}
 
 	metricsOnce sync.Once
@@ -119,6 +130,9 @@ type Node struct {
 
 	hooks []NodeHooks
 
+	// epochTransitions is notified on every epoch transition and runtime suspension.
+	epochTransitions *pubsub.Broker
+
 	// Mutable and shared between nodes' workers.
 	// Guarded by .CrossNode.
 	CrossNode             sync.Mutex
@@ -129,6 +143,14 @@ type Node struct {
 	CurrentEpoch          beacon.EpochTime
 	Height                int64
 
+	// runtimeDegraded is true while the hosted runtime is restarting too often.
+	// Guarded by .CrossNode.
+	runtimeDegraded bool
+
+	// equivocationWatcher is only set when a worker enabled the equivocation watcher.
+	// Guarded by .CrossNode.
+	equivocationWatcher *EquivocationWatcher
+
 	logger *logging.Logger
 }
 
@@ -167,6 +189,8 @@ func (n *Node) Quit() <-chan struct{} {
 
 // Cleanup performs the service specific post-termination cleanup.
 func (n *Node) Cleanup() {
+	// Make sure that any group services are stopped even if the node was never started.
+	n.cancelCtx()
 }
 
 // Initialized returns a channel that will be closed when the node is
@@ -175,12 +199,54 @@ func (n *Node) Initialized() <-chan struct{} {
 	return n.initCh
 }
 
+// WaitNotElected waits until the node is no longer a member of the runtime's executor committee.
+//
+// In case the node is stopped while waiting, the method returns immediately as the node can no
+// longer participate in any committees.
+func (n *Node) WaitNotElected(ctx context.Context) error {
+	sub := n.epochTransitions.Subscribe()
+	defer sub.Close()
+
+	for {
+		epoch := n.Group.GetEpochSnapshot()
+		if !epoch.IsExecutorMember() {
+			return nil
+		}
+
+		n.logger.Info("waiting for the node to no longer be elected",
+			"epoch", epoch.GetEpochNumber(),
+		)
+
+		select {
+		case <-sub.Untyped():
+		case <-n.stopCh:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // AddHooks adds a NodeHooks to be called.
 // There is no going back.
 func (n *Node) AddHooks(hooks NodeHooks) {
 	n.hooks = append(n.hooks, hooks)
 }
 
+// EquivocationWatcher returns the runtime's equivocation watcher, enabling it on first use.
+//
+// Once enabled, the watcher observes executor commitments in roothash events and consensus blocks
+// and submits evidence of any equivocation it detects.
+func (n *Node) EquivocationWatcher() *EquivocationWatcher {
+	n.CrossNode.Lock()
+	defer n.CrossNode.Unlock()
+
+	if n.equivocationWatcher == nil {
+		n.equivocationWatcher = newEquivocationWatcher(n)
+	}
+	return n.equivocationWatcher
+}
+
 // GetStatus returns the common committee node status.
 func (n *Node) GetStatus(ctx context.Context) (*api.Status, error) {
 	n.CrossNode.Lock()
@@ -203,6 +269,15 @@ func (n *Node) GetStatus(ctx context.Context) (*api.Status, error) {
 	return &status, nil
 }
 
+// IsRuntimeDegraded returns true iff the hosted runtime is degraded because it has been restarted
+// more often than allowed by the runtime restart policy.
+func (n *Node) IsRuntimeDegraded() bool {
+	n.CrossNode.Lock()
+	defer n.CrossNode.Unlock()
+
+	return n.runtimeDegraded
+}
+
 func (n *Node) getMetricLabels() prometheus.Labels {
 	return prometheus.Labels{
 		"runtime": n.Runtime.ID().String(),
@@ -227,6 +302,7 @@ func (n *Node) handleEpochTransitionLocked(height int64) {
 	for _, hooks := range n.hooks {
 		hooks.HandleEpochTransitionLocked(epoch)
 	}
+	n.epochTransitions.Broadcast(epoch)
 }
 
 // Guarded by n.CrossNode.
@@ -240,9 +316,32 @@ func (n *Node) handleSuspendLocked(height int64) {
 	for _, hooks := range n.hooks {
 		hooks.HandleEpochTransitionLocked(epoch)
 	}
+	n.epochTransitions.Broadcast(epoch)
 }
 
 // Guarded by n.CrossNode.
+func (n *Node) activateNextRuntimeVersionLocked() {
+	if n.RuntimeHostNode == nil {
+		return
+	}
+
+	err := n.ActivateNextHostedRuntime()
+	switch {
+	case err == nil:
+		n.logger.Info("activated next runtime version",
+			"epoch", n.CurrentEpoch,
+			"version", n.CurrentDescriptor.ScheduledVersion.Version,
+		)
+	case errors.Is(err, multi.ErrNoNextVersion):
+		// Next version is either not configured or has already been activated.
+	default:
+		n.logger.Warn("failed to activate next runtime version",
+			"err", err,
+			"epoch", n.CurrentEpoch,
+		)
+	}
+}
+
 func (n *Node) handleNewBlockLocked(blk *block.Block, height int64) {
 	processedBlockCount.With(n.getMetricLabels()).Inc()
 
@@ -298,6 +397,15 @@ func (n *Node) handleNewBlockLocked(blk *block.Block, height int64) {
 		}
 	}
 
+	// Switch to the next runtime version once a scheduled version change takes effect.
+	if ad := n.CurrentDescriptor; ad != nil && ad.ScheduledVersion != nil && n.CurrentEpoch >= ad.ScheduledVersion.Epoch {
+		n.activateNextRuntimeVersionLocked()
+	}
+
+	if n.equivocationWatcher != nil {
+		n.equivocationWatcher.prune(header.Round)
+	}
+
 	for _, hooks := range n.hooks {
 		hooks.HandleNewBlockEarlyLocked(blk)
 	}
@@ -357,12 +465,22 @@ func (n *Node) handleNewBlockLocked(blk *block.Block, height int64) {
 func (n *Node) handleNewEventLocked(ev *roothash.Event) {
 	processedEventCount.With(n.getMetricLabels()).Inc()
 
+	if ev.ExecutorCommitted != nil && n.equivocationWatcher != nil {
+		n.equivocationWatcher.submitEvidence(n.equivocationWatcher.observeExecutorCommitment(&ev.ExecutorCommitted.Commit))
+	}
+
 	for _, hooks := range n.hooks {
 		hooks.HandleNewEventLocked(ev)
 	}
 }
 
 func (n *Node) handleRuntimeHostEvent(ev *host.Event) {
+	if ev.Degraded != nil {
+		n.CrossNode.Lock()
+		n.runtimeDegraded = ev.Degraded.Degraded
+		n.CrossNode.Unlock()
+	}
+
 	for _, hooks := range n.hooks {
 		hooks.HandleRuntimeHostEvent(ev)
 	}
@@ -497,11 +615,16 @@ func (n *Node) worker() {
 			if blk == nil {
 				return
 			}
+			var equivocationWatcher *EquivocationWatcher
 			func() {
 				n.CrossNode.Lock()
 				defer n.CrossNode.Unlock()
 				n.Height = blk.Height
+				equivocationWatcher = n.equivocationWatcher
 			}()
+			if equivocationWatcher != nil {
+				equivocationWatcher.handleConsensusBlock(blk.Height)
+			}
 		case blk := <-blocks:
 			// We are initialized after we have received the first block. This makes sure that any
 			// history reindexing has been completed.
@@ -567,19 +690,20 @@ func NewNode(
 	}
 
 	n := &Node{
-		HostNode:   hostNode,
-		Runtime:    runtime,
-		Identity:   identity,
-		KeyManager: keymanager,
-		Consensus:  consensus,
-		Group:      group,
-		P2P:        p2pHost,
-		ctx:        ctx,
-		cancelCtx:  cancel,
-		stopCh:     make(chan struct{}),
-		quitCh:     make(chan struct{}),
-		initCh:     make(chan struct{}),
-		logger:     logging.GetLogger("worker/common/committee").With("runtime_id", runtime.ID()),
+		HostNode:         hostNode,
+		Runtime:          runtime,
+		Identity:         identity,
+		KeyManager:       keymanager,
+		Consensus:        consensus,
+		Group:            group,
+		P2P:              p2pHost,
+		ctx:              ctx,
+		cancelCtx:        cancel,
+		stopCh:           make(chan struct{}),
+		quitCh:           make(chan struct{}),
+		initCh:           make(chan struct{}),
+		epochTransitions: pubsub.NewBroker(false),
+		logger:           logging.GetLogger("worker/common/committee").With("runtime_id", runtime.ID()),
 	}
 
 	// Prepare the runtime host node helpers.
@@ -599,5 +723,8 @@ func NewNode(
 	// Register transaction message handler as that is something that all workers must handle.
 	p2pHost.RegisterHandler(runtime.ID(), p2p.TopicKindTx, &txMsgHandler{n})
 
+	// Register transaction sync protocol handler so that peers can fetch any missing transactions.
+	p2pHost.RegisterProtocol(runtime.ID(), txsync.ProtocolName, txsync.NewServer(txPool).HandleStream)
+
 	return n, nil
 }
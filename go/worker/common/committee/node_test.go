@@ -0,0 +1,86 @@
+package committee
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+func TestWaitNotElected(t *testing.T) {
+	require := require.New(t)
+
+	group := &Group{
+		activeEpoch: &epoch{
+			epochNumber: 1,
+			executorCommittee: &CommitteeInfo{
+				Roles: []scheduler.Role{scheduler.RoleWorker},
+			},
+		},
+	}
+	n := &Node{
+		Group:            group,
+		stopCh:           make(chan struct{}),
+		epochTransitions: pubsub.NewBroker(false),
+		logger:           logging.GetLogger("worker/common/committee/test"),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.WaitNotElected(context.Background())
+	}()
+
+	// Transition into an epoch where the node is still elected.
+	group.Lock()
+	group.activeEpoch = &epoch{
+		epochNumber: 2,
+		executorCommittee: &CommitteeInfo{
+			Roles: []scheduler.Role{scheduler.RoleBackupWorker},
+		},
+	}
+	group.Unlock()
+	n.epochTransitions.Broadcast(group.GetEpochSnapshot())
+
+	select {
+	case err := <-errCh:
+		require.FailNow("should wait while the node is elected", "err: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Transition into an epoch where the node is no longer elected.
+	group.Lock()
+	group.activeEpoch = &epoch{
+		epochNumber: 3,
+	}
+	group.Unlock()
+	n.epochTransitions.Broadcast(group.GetEpochSnapshot())
+
+	select {
+	case err := <-errCh:
+		require.NoError(err, "WaitNotElected")
+	case <-time.After(time.Second):
+		require.FailNow("should return once the node is no longer elected")
+	}
+
+	// Waiting while elected should be aborted by context cancellation.
+	group.Lock()
+	group.activeEpoch.executorCommittee = &CommitteeInfo{
+		Roles: []scheduler.Role{scheduler.RoleWorker},
+	}
+	group.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := n.WaitNotElected(ctx)
+	require.ErrorIs(err, context.DeadlineExceeded, "WaitNotElected should honor the context")
+
+	// Waiting while elected should return once the node is stopped.
+	close(n.stopCh)
+	err = n.WaitNotElected(context.Background())
+	require.NoError(err, "WaitNotElected should return once the node is stopped")
+}
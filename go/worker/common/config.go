@@ -25,10 +25,17 @@ var (
 	CfgSentryAddresses = "worker.sentry.address"
 
 	cfgMaxTxPoolSize       = "worker.tx_pool.schedule_max_tx_pool_size"
+	cfgMaxTxsPerSender     = "worker.tx_pool.schedule_max_txs_per_sender"
 	cfgScheduleTxCacheSize = "worker.tx_pool.schedule_tx_cache_size"
 	cfgStaleTxCacheSize    = "worker.tx_pool.stale_tx_cache_size"
 	cfgCheckTxMaxBatchSize = "worker.tx_pool.check_tx_max_batch_size"
 	cfgRecheckInterval     = "worker.tx_pool.recheck_interval"
+	cfgMaxLifetimeRounds   = "worker.tx_pool.max_lifetime_rounds"
+	cfgMaxLifetime         = "worker.tx_pool.max_lifetime"
+	cfgJournalEnabled      = "worker.tx_pool.journal.enabled"
+	cfgJournalMaxAge       = "worker.tx_pool.journal.max_age"
+
+	cfgEquivocationWatcherEnabled = "worker.executor.equivocation_watcher.enabled"
 
 	// Flags has the configuration flags.
 	Flags = flag.NewFlagSet("", flag.ContinueOnError)
@@ -41,6 +48,11 @@ type Config struct { // nolint: maligned
 	SentryAddresses []node.TLSAddress
 
 	TxPool txpool.Config
+	// TxPoolJournal enables the persistent transaction pool journal.
+	TxPoolJournal bool
+
+	// EquivocationWatcher enables submission of evidence for observed proposer and executor equivocation.
+	EquivocationWatcher bool
 
 	logger *logging.Logger
 }
@@ -94,6 +106,7 @@ func NewConfig() (*Config, error) {
 		SentryAddresses: sentryAddresses,
 		TxPool: txpool.Config{
 			MaxPoolSize:          viper.GetUint64(cfgMaxTxPoolSize),
+			MaxTxsPerSender:      viper.GetUint64(cfgMaxTxsPerSender),
 			MaxCheckTxBatchSize:  viper.GetUint64(cfgCheckTxMaxBatchSize),
 			MaxLastSeenCacheSize: viper.GetUint64(cfgScheduleTxCacheSize),
 			MaxStaleCacheSize:    viper.GetUint64(cfgStaleTxCacheSize),
@@ -102,8 +115,15 @@ func NewConfig() (*Config, error) {
 			RepublishInterval: 60 * time.Second,
 
 			RecheckInterval: viper.GetUint64(cfgRecheckInterval),
+
+			MaxLifetimeRounds: viper.GetUint64(cfgMaxLifetimeRounds),
+			MaxLifetime:       viper.GetDuration(cfgMaxLifetime),
+
+			JournalMaxAge: viper.GetDuration(cfgJournalMaxAge),
 		},
-		logger: logging.GetLogger("worker/config"),
+		TxPoolJournal:       viper.GetBool(cfgJournalEnabled),
+		EquivocationWatcher: viper.GetBool(cfgEquivocationWatcherEnabled),
+		logger:              logging.GetLogger("worker/config"),
 	}
 
 	return &cfg, nil
@@ -115,10 +135,17 @@ func init() {
 	Flags.StringSlice(CfgSentryAddresses, []string{}, "Address(es) of sentry node(s) to connect to of the form [PubKey@]ip:port (where PubKey@ part represents base64 encoded node TLS public key)")
 
 	Flags.Uint64(cfgMaxTxPoolSize, 10_000, "Maximum size of the scheduling transaction pool")
+	Flags.Uint64(cfgMaxTxsPerSender, 0, "Maximum number of scheduled transactions per sender (0 = unlimited, only used by the ordered scheduler)")
 	Flags.Uint64(cfgScheduleTxCacheSize, 10_000, "Maximum cache size of recently scheduled transactions to prevent re-scheduling")
 	Flags.Uint64(cfgStaleTxCacheSize, 64, "Maximum cache size of recently cleared transactions")
 	Flags.Uint64(cfgCheckTxMaxBatchSize, 10_000, "Maximum check tx batch size")
 	Flags.Uint64(cfgRecheckInterval, 32, "Transaction recheck interval (in rounds)")
+	Flags.Uint64(cfgMaxLifetimeRounds, 0, "Maximum number of rounds a transaction can stay in the pool before being evicted (0 = unlimited)")
+	Flags.Duration(cfgMaxLifetime, 1*time.Hour, "Maximum duration a transaction can stay in the pool before being evicted (0 = unlimited)")
+	Flags.Bool(cfgJournalEnabled, false, "Enable the persistent transaction pool journal so pending transactions survive restarts")
+	Flags.Duration(cfgJournalMaxAge, 1*time.Hour, "Maximum age of journaled transactions (0 = unlimited)")
+
+	Flags.Bool(cfgEquivocationWatcherEnabled, false, "Enable submission of evidence for proposer and executor equivocation observed by compute and client nodes")
 
 	_ = viper.BindPFlags(Flags)
 }
@@ -49,7 +49,8 @@ type Handler interface {
 }
 
 type topicHandler struct {
-	ctx context.Context
+	ctx       context.Context
+	cancelCtx context.CancelFunc
 
 	p2p *P2P
 
@@ -272,8 +273,10 @@ func newTopicHandler(p *P2P, runtimeID common.Namespace, kind TopicKind, handler
 		return "", nil, fmt.Errorf("worker/common/p2p: failed to join topic '%s': %w", topicID, err)
 	}
 
+	ctx, cancelCtx := context.WithCancel(p.ctx)
 	h := &topicHandler{
-		ctx:          p.ctx, // TODO: Should this support individual cancelation?
+		ctx:          ctx,
+		cancelCtx:    cancelCtx,
 		p2p:          p,
 		topic:        topic,
 		host:         p.host,
@@ -288,6 +291,7 @@ func newTopicHandler(p *P2P, runtimeID common.Namespace, kind TopicKind, handler
 			"err", err,
 		)
 		_ = topic.Close()
+		cancelCtx()
 
 		return "", nil, fmt.Errorf("worker/common/p2p: failed to relay topic '%s': %w", topicID, err)
 	}
@@ -296,6 +300,12 @@ func newTopicHandler(p *P2P, runtimeID common.Namespace, kind TopicKind, handler
 	return topicID, h, nil
 }
 
+func (h *topicHandler) close() error {
+	h.cancelCtx()
+	h.cancelRelay()
+	return h.topic.Close()
+}
+
 func peerIDToPublicKey(peerID core.PeerID) (signature.PublicKey, error) {
 	pk, err := peerID.ExtractPublicKey()
 	if err != nil {
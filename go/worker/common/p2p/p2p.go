@@ -11,6 +11,7 @@ import (
 	"github.com/libp2p/go-libp2p"
 	core "github.com/libp2p/go-libp2p-core"
 	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/libp2p/go-libp2p-core/transport"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	pb "github.com/libp2p/go-libp2p-pubsub/pb"
@@ -65,6 +66,7 @@ type P2P struct {
 
 	registerAddresses []multiaddr.Multiaddr
 	topics            map[common.Namespace]map[TopicKind]*topicHandler
+	protocols         map[common.Namespace][]protocol.ID
 
 	logger *logging.Logger
 }
@@ -230,6 +232,34 @@ func (p *P2P) RegisterHandler(runtimeID common.Namespace, kind TopicKind, handle
 	)
 }
 
+// RemoveRuntime unregisters all topic and protocol handlers registered for the specified runtime.
+func (p *P2P) RemoveRuntime(runtimeID common.Namespace) {
+	p.Lock()
+	defer p.Unlock()
+
+	for kind, h := range p.topics[runtimeID] {
+		topicID := p.topicIDForRuntime(runtimeID, kind)
+		_ = p.pubsub.UnregisterTopicValidator(topicID)
+		if err := h.close(); err != nil {
+			p.logger.Warn("failed to close topic",
+				"err", err,
+				"runtime_id", runtimeID,
+				"kind", kind,
+			)
+		}
+	}
+	delete(p.topics, runtimeID)
+
+	for _, protocolID := range p.protocols[runtimeID] {
+		p.host.RemoveStreamHandler(protocolID)
+	}
+	delete(p.protocols, runtimeID)
+
+	p.logger.Debug("removed runtime handlers",
+		"runtime_id", runtimeID,
+	)
+}
+
 func (p *P2P) handleConnection(conn core.Conn) {
 	if conn.Stat().Direction != network.DirInbound {
 		return
@@ -329,6 +359,7 @@ func New(ctx context.Context, identity *identity.Identity, consensus consensus.B
 		pubsub:            pubsub,
 		registerAddresses: registerAddresses,
 		topics:            make(map[common.Namespace]map[TopicKind]*topicHandler),
+		protocols:         make(map[common.Namespace][]protocol.ID),
 		logger:            logging.GetLogger("worker/common/p2p"),
 	}
 	p.host.Network().SetConnHandler(p.handleConnection)
@@ -73,6 +73,36 @@ func (mgr *PeerManager) KnownPeers() []core.PeerID {
 	return peers
 }
 
+// PeersForNodes returns the peer IDs of the known peers that correspond to the given nodes.
+//
+// Peers that are currently connected are ordered first, otherwise the order of the given nodes is
+// preserved. Nodes that are not known to the peer manager are skipped.
+func (mgr *PeerManager) PeersForNodes(nodeIDs []signature.PublicKey) []core.PeerID {
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	peersByNode := make(map[signature.PublicKey]core.PeerID, len(mgr.peers))
+	for peerID, p := range mgr.peers {
+		peersByNode[p.node.ID] = peerID
+	}
+
+	var connected, disconnected []core.PeerID
+	for _, nodeID := range nodeIDs {
+		peerID, ok := peersByNode[nodeID]
+		if !ok {
+			continue
+		}
+
+		switch mgr.host.Network().Connectedness(peerID) {
+		case network.Connected:
+			connected = append(connected, peerID)
+		default:
+			disconnected = append(disconnected, peerID)
+		}
+	}
+	return append(connected, disconnected...)
+}
+
 // SetNodes sets the membership of the gossipsub network.
 func (mgr *PeerManager) SetNodes(nodes []*node.Node) {
 	mgr.Lock()
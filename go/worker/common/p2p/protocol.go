@@ -0,0 +1,100 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+)
+
+// RegisterProtocol registers a stream handler for the given runtime protocol.
+//
+// Any peer (including peers that are not registered in the consensus layer registry) can open
+// streams to the registered protocol so handlers must only serve publicly available data.
+func (p *P2P) RegisterProtocol(runtimeID common.Namespace, name string, handler network.StreamHandler) {
+	protocolID := p.protocolIDForRuntime(runtimeID, name)
+	p.host.SetStreamHandler(protocolID, handler)
+
+	p.Lock()
+	p.protocols[runtimeID] = append(p.protocols[runtimeID], protocolID)
+	p.Unlock()
+
+	p.logger.Debug("registered new protocol handler",
+		"runtime_id", runtimeID,
+		"protocol", protocolID,
+	)
+}
+
+// NewStream opens a new stream to the given peer for the given runtime protocol.
+func (p *P2P) NewStream(ctx context.Context, peerID core.PeerID, runtimeID common.Namespace, name string) (network.Stream, error) {
+	stream, err := p.host.NewStream(ctx, peerID, p.protocolIDForRuntime(runtimeID, name))
+	if err != nil {
+		return nil, fmt.Errorf("worker/common/p2p: failed to open stream: %w", err)
+	}
+	return stream, nil
+}
+
+// PeersForProtocol returns a list of peers that may support the given runtime protocol.
+//
+// The returned list contains (in order):
+//
+// - Currently connected peers that support the protocol.
+// - Disconnected peers that were previously seen supporting the protocol. This includes peers
+//   that are not registered in the consensus layer registry and have connected to us before, as
+//   the peer manager only maintains connections with registered nodes.
+// - Registered nodes for which protocol support is not yet known as we have not been connected to
+//   them yet.
+//
+// Streams to disconnected peers can still be opened via NewStream as long as their addresses are
+// known, in which case a new connection is established.
+func (p *P2P) PeersForProtocol(runtimeID common.Namespace, name string) []core.PeerID {
+	protocolID := string(p.protocolIDForRuntime(runtimeID, name))
+	ps := p.host.Peerstore()
+
+	var connected, disconnected, unknown []core.PeerID
+	seen := make(map[core.PeerID]bool)
+	for _, peerID := range ps.PeersWithAddrs() {
+		if peerID == p.host.ID() {
+			continue
+		}
+		supported, err := ps.SupportsProtocols(peerID, protocolID)
+		if err != nil || len(supported) == 0 {
+			continue
+		}
+		seen[peerID] = true
+
+		switch p.host.Network().Connectedness(peerID) {
+		case network.Connected:
+			connected = append(connected, peerID)
+		default:
+			disconnected = append(disconnected, peerID)
+		}
+	}
+	for _, peerID := range p.KnownPeers() {
+		if seen[peerID] {
+			continue
+		}
+		// Skip peers that we have talked to and that do not support the protocol.
+		if protocols, err := ps.GetProtocols(peerID); err == nil && len(protocols) > 0 {
+			continue
+		}
+		unknown = append(unknown, peerID)
+	}
+
+	peers := append(connected, disconnected...)
+	return append(peers, unknown...)
+}
+
+func (p *P2P) protocolIDForRuntime(runtimeID common.Namespace, name string) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/oasis/%s/%d/%s/%s",
+		p.chainContext,
+		version.RuntimeCommitteeProtocol.Major,
+		runtimeID.String(),
+		name,
+	))
+}
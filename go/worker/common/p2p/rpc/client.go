@@ -0,0 +1,230 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/network"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+// DefaultMaxPeerResponseTime is the default maximum time a peer has to respond to a request.
+const DefaultMaxPeerResponseTime = 5 * time.Second
+
+// ErrNoPeers is the error returned when no peers were given to route a call to.
+var ErrNoPeers = errors.New(ModuleName, 3, "rpc: no peers available")
+
+// P2P is the subset of the P2P layer needed by RPC clients.
+type P2P interface {
+	// NewStream opens a new stream to the given peer for the given runtime protocol.
+	NewStream(ctx context.Context, peerID core.PeerID, runtimeID common.Namespace, name string) (network.Stream, error)
+}
+
+// CallOption is a per-call option setter.
+type CallOption func(opts *callOptions)
+
+type callOptions struct {
+	maxPeerResponseTime time.Duration
+	maxResponseSize     uint32
+}
+
+// WithMaxPeerResponseTime configures the maximum time a peer has to respond to a request.
+func WithMaxPeerResponseTime(d time.Duration) CallOption {
+	return func(opts *callOptions) {
+		opts.maxPeerResponseTime = d
+	}
+}
+
+// WithMaxResponseSize configures the maximum size of a response accepted by the client.
+func WithMaxResponseSize(size uint32) CallOption {
+	return func(opts *callOptions) {
+		opts.maxResponseSize = size
+	}
+}
+
+func newCallOptions(opts ...CallOption) *callOptions {
+	co := &callOptions{
+		maxPeerResponseTime: DefaultMaxPeerResponseTime,
+		maxResponseSize:     DefaultMaxResponseSize,
+	}
+	for _, opt := range opts {
+		opt(co)
+	}
+	return co
+}
+
+// Client is an RPC client for a given protocol.
+type Client interface {
+	// Call routes the given RPC method call to the given peer and decodes the response into rsp
+	// (which may be nil in case the response should be ignored).
+	Call(ctx context.Context, peerID core.PeerID, method string, body, rsp interface{}, opts ...CallOption) error
+
+	// CallOne routes the given RPC method call to the given peers, one at a time and in order,
+	// until one of them succeeds. It returns the peer that successfully responded.
+	CallOne(ctx context.Context, peers []core.PeerID, method string, body, rsp interface{}, opts ...CallOption) (core.PeerID, error)
+
+	// CallMulti routes the given RPC method call to all of the given peers concurrently and returns
+	// the successfully decoded responses together with the peers that sent them.
+	//
+	// Each response is decoded into a newly allocated value of the same type as rspTyp and the
+	// returned responses are pointers to these values.
+	CallMulti(ctx context.Context, peers []core.PeerID, method string, body, rspTyp interface{}, opts ...CallOption) ([]interface{}, []core.PeerID)
+}
+
+type client struct {
+	p2p       P2P
+	runtimeID common.Namespace
+	protocol  string
+
+	logger *logging.Logger
+}
+
+func (c *client) Call(
+	ctx context.Context,
+	peerID core.PeerID,
+	method string,
+	body, rsp interface{},
+	opts ...CallOption,
+) error {
+	co := newCallOptions(opts...)
+
+	ctx, cancel := context.WithTimeout(ctx, co.maxPeerResponseTime)
+	defer cancel()
+
+	stream, err := c.p2p.NewStream(ctx, peerID, c.runtimeID, c.protocol)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	deadline, _ := ctx.Deadline()
+	_ = stream.SetDeadline(deadline)
+
+	// Not all transports support deadlines, so make sure to also abort the stream when the context
+	// is done.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = stream.Reset()
+		case <-doneCh:
+		}
+	}()
+
+	request := Request{
+		Method: method,
+		Body:   cbor.Marshal(body),
+	}
+	if err = writeMessage(stream, &request, DefaultMaxRequestSize); err != nil {
+		_ = stream.Reset()
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	_ = stream.CloseWrite()
+
+	var response Response
+	if err = readMessage(stream, &response, co.maxResponseSize); err != nil {
+		_ = stream.Reset()
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if response.Error != nil {
+		return errors.FromCode(response.Error.Module, response.Error.Code, response.Error.Message)
+	}
+	if rsp != nil {
+		if err = cbor.Unmarshal(response.Ok, rsp); err != nil {
+			return fmt.Errorf("malformed response: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *client) CallOne(
+	ctx context.Context,
+	peers []core.PeerID,
+	method string,
+	body, rsp interface{},
+	opts ...CallOption,
+) (core.PeerID, error) {
+	if len(peers) == 0 {
+		return "", ErrNoPeers
+	}
+
+	var err error
+	for _, peerID := range peers {
+		if err = c.Call(ctx, peerID, method, body, rsp, opts...); err == nil {
+			return peerID, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		c.logger.Debug("failed to call method on peer",
+			"err", err,
+			"method", method,
+			"peer_id", peerID,
+		)
+	}
+	return "", err
+}
+
+func (c *client) CallMulti(
+	ctx context.Context,
+	peers []core.PeerID,
+	method string,
+	body, rspTyp interface{},
+	opts ...CallOption,
+) ([]interface{}, []core.PeerID) {
+	typ := reflect.TypeOf(rspTyp)
+
+	var (
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		rsps     []interface{}
+		rspPeers []core.PeerID
+	)
+	for _, peerID := range peers {
+		wg.Add(1)
+		go func(peerID core.PeerID) {
+			defer wg.Done()
+
+			rsp := reflect.New(typ).Interface()
+			if err := c.Call(ctx, peerID, method, body, rsp, opts...); err != nil {
+				c.logger.Debug("failed to call method on peer",
+					"err", err,
+					"method", method,
+					"peer_id", peerID,
+				)
+				return
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			rsps = append(rsps, rsp)
+			rspPeers = append(rspPeers, peerID)
+		}(peerID)
+	}
+	wg.Wait()
+
+	return rsps, rspPeers
+}
+
+// NewClient creates a new RPC client for the given runtime protocol.
+func NewClient(p2p P2P, runtimeID common.Namespace, protocol string) Client {
+	return &client{
+		p2p:       p2p,
+		runtimeID: runtimeID,
+		protocol:  protocol,
+		logger: logging.GetLogger("worker/common/p2p/rpc/client").With(
+			"runtime_id", runtimeID,
+			"protocol", protocol,
+		),
+	}
+}
@@ -0,0 +1,242 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+)
+
+const (
+	testProtocol = "test"
+
+	methodEcho     = "Echo"
+	methodFail     = "Fail"
+	methodSleep    = "Sleep"
+	methodLarge    = "Large"
+	methodLeak     = "Leak"
+	methodInternal = "Internal"
+)
+
+var errTestFailure = errors.New("worker/common/p2p/rpc/test", 1, "test: failure")
+
+type testEchoRequest struct {
+	Message string `json:"message"`
+}
+
+type testEchoResponse struct {
+	Message string `json:"message"`
+	Server  int    `json:"server"`
+}
+
+type testService struct {
+	index int
+}
+
+func (s *testService) HandleRequest(ctx context.Context, method string, body cbor.RawMessage) (interface{}, error) {
+	switch method {
+	case methodEcho:
+		var rq testEchoRequest
+		if err := cbor.Unmarshal(body, &rq); err != nil {
+			return nil, ErrBadRequest
+		}
+		return &testEchoResponse{Message: rq.Message, Server: s.index}, nil
+	case methodFail:
+		return nil, errTestFailure
+	case methodSleep:
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+		return nil, nil
+	case methodLarge:
+		return make([]byte, 1024), nil
+	case methodLeak:
+		return nil, fmt.Errorf("%w: secret", errTestFailure)
+	case methodInternal:
+		return nil, fmt.Errorf("secret")
+	default:
+		return nil, ErrMethodNotSupported
+	}
+}
+
+// testP2P is a P2P layer backed by a mock libp2p host.
+type testP2P struct {
+	host core.Host
+}
+
+func (p *testP2P) NewStream(ctx context.Context, peerID core.PeerID, runtimeID common.Namespace, name string) (network.Stream, error) {
+	return p.host.NewStream(ctx, peerID, testProtocolID(runtimeID, name))
+}
+
+func testProtocolID(runtimeID common.Namespace, name string) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/oasis/test/%s/%s", runtimeID, name))
+}
+
+// newTestNetwork creates a mock network with a client host and the given number of hosts serving
+// the test protocol (the last one with a reduced maximum response size).
+func newTestNetwork(t *testing.T, numServers int, srvOpts ...ServerOption) (Client, []core.PeerID) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mn, err := mocknet.FullMeshLinked(ctx, numServers+1)
+	require.NoError(err, "FullMeshLinked")
+
+	var runtimeID common.Namespace
+	hosts := mn.Hosts()
+	t.Cleanup(func() {
+		for _, host := range hosts {
+			_ = host.Close()
+		}
+		cancel()
+	})
+	var peers []core.PeerID
+	for i, host := range hosts[1:] {
+		opts := append([]ServerOption{}, srvOpts...)
+		if i == numServers-1 {
+			opts = append(opts, WithMaxServerResponseSize(64))
+		}
+		srv := NewServer(testProtocol, &testService{index: i}, opts...)
+		host.SetStreamHandler(testProtocolID(runtimeID, testProtocol), srv.HandleStream)
+		peers = append(peers, host.ID())
+	}
+
+	return NewClient(&testP2P{host: hosts[0]}, runtimeID, testProtocol), peers
+}
+
+func TestCall(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc, peers := newTestNetwork(t, 2)
+
+	// Successful call.
+	var rsp testEchoResponse
+	err := rc.Call(ctx, peers[0], methodEcho, &testEchoRequest{Message: "hello"}, &rsp)
+	require.NoError(err, "Call")
+	require.Equal("hello", rsp.Message, "response should be decoded")
+	require.Equal(0, rsp.Server, "response should come from the called peer")
+
+	// Responses can be ignored.
+	err = rc.Call(ctx, peers[0], methodEcho, &testEchoRequest{Message: "hello"}, nil)
+	require.NoError(err, "Call without a response")
+
+	// Errors should be propagated together with their codes.
+	err = rc.Call(ctx, peers[0], methodFail, nil, nil)
+	require.True(errors.Is(err, errTestFailure), "service errors should be propagated (got: %v)", err)
+	err = rc.Call(ctx, peers[0], "Unknown", nil, nil)
+	require.True(errors.Is(err, ErrMethodNotSupported), "unsupported methods should be reported (got: %v)", err)
+	err = rc.Call(ctx, peers[0], methodEcho, 42, nil)
+	require.True(errors.Is(err, ErrBadRequest), "malformed requests should be reported (got: %v)", err)
+
+	// Error messages should not be sent to peers.
+	err = rc.Call(ctx, peers[0], methodLeak, nil, nil)
+	require.True(errors.Is(err, errTestFailure), "service errors should be propagated (got: %v)", err)
+	require.NotContains(err.Error(), "secret", "error messages should not be propagated")
+	err = rc.Call(ctx, peers[0], methodInternal, nil, nil)
+	require.True(errors.Is(err, ErrInternal), "errors without codes should be reported as internal (got: %v)", err)
+	require.NotContains(err.Error(), "secret", "error messages should not be propagated")
+
+	// Slow peers should time out.
+	start := time.Now()
+	err = rc.Call(ctx, peers[0], methodSleep, nil, nil, WithMaxPeerResponseTime(100*time.Millisecond))
+	require.Error(err, "Call to a slow peer should fail")
+	require.Less(int64(time.Since(start)), int64(time.Second), "Call should time out")
+
+	// Responses over the client size limit should be rejected.
+	err = rc.Call(ctx, peers[0], methodLarge, nil, nil, WithMaxResponseSize(64))
+	require.Error(err, "Call with a response over the client size limit should fail")
+	err = rc.Call(ctx, peers[0], methodLarge, nil, nil)
+	require.NoError(err, "Call with a response under the size limit")
+
+	// Responses over the server size limit should not be sent.
+	err = rc.Call(ctx, peers[1], methodLarge, nil, nil)
+	require.Error(err, "Call with a response over the server size limit should fail")
+}
+
+func TestCallOne(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc, peers := newTestNetwork(t, 2)
+
+	_, err := rc.CallOne(ctx, nil, methodEcho, &testEchoRequest{}, nil)
+	require.True(errors.Is(err, ErrNoPeers), "CallOne without peers should fail")
+
+	// Peers should be tried in order until one succeeds.
+	var rsp testEchoResponse
+	peerID, err := rc.CallOne(ctx, peers, methodEcho, &testEchoRequest{Message: "hello"}, &rsp)
+	require.NoError(err, "CallOne")
+	require.Equal(peers[0], peerID, "first peer should respond")
+	require.Equal(0, rsp.Server, "response should come from the first peer")
+
+	peerID, err = rc.CallOne(ctx, peers, methodLarge, nil, nil, WithMaxResponseSize(2048))
+	require.NoError(err, "CallOne")
+	require.Equal(peers[0], peerID, "first peer should respond")
+
+	peerID, err = rc.CallOne(ctx, []core.PeerID{peers[1], peers[0]}, methodLarge, nil, nil)
+	require.NoError(err, "CallOne should skip failing peers")
+	require.Equal(peers[0], peerID, "second peer should respond after the first one failed")
+
+	// The last error should be returned if all peers fail.
+	_, err = rc.CallOne(ctx, []core.PeerID{peers[1], peers[0]}, methodFail, nil, nil)
+	require.True(errors.Is(err, errTestFailure), "CallOne should return the last error (got: %v)", err)
+}
+
+func TestCallMulti(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc, peers := newTestNetwork(t, 3)
+
+	rsps, rspPeers := rc.CallMulti(ctx, peers, methodEcho, &testEchoRequest{Message: "hello"}, testEchoResponse{})
+	require.Len(rsps, 3, "all peers should respond")
+	require.ElementsMatch(peers, rspPeers, "all peers should respond")
+	for i, rsp := range rsps {
+		echo := rsp.(*testEchoResponse)
+		require.Equal("hello", echo.Message, "response should be decoded")
+		require.Equal(peers[echo.Server], rspPeers[i], "responses should match peers")
+	}
+
+	// Failing peers should be omitted.
+	rsps, rspPeers = rc.CallMulti(ctx, peers, methodLarge, nil, []byte{})
+	require.Len(rsps, 2, "only peers that successfully respond should be returned")
+	require.ElementsMatch(peers[:2], rspPeers, "only peers that successfully respond should be returned")
+
+	rsps, rspPeers = rc.CallMulti(ctx, peers, methodFail, nil, testEchoResponse{})
+	require.Empty(rsps, "no responses should be returned when all peers fail")
+	require.Empty(rspPeers, "no peers should be returned when all peers fail")
+}
+
+func TestMaxPeerStreams(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc, peers := newTestNetwork(t, 1, WithMaxPeerStreams(1))
+
+	slowCh := make(chan error, 1)
+	go func() {
+		slowCh <- rc.Call(ctx, peers[0], methodSleep, nil, nil)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// Streams over the per-peer limit should be rejected.
+	err := rc.Call(ctx, peers[0], methodEcho, &testEchoRequest{Message: "hello"}, nil)
+	require.Error(err, "Call over the per-peer stream limit should fail")
+
+	require.NoError(<-slowCh, "Call under the per-peer stream limit")
+
+	// Streams should be accepted again once the previous ones are done.
+	err = rc.Call(ctx, peers[0], methodEcho, &testEchoRequest{Message: "hello"}, nil)
+	require.NoError(err, "Call after the previous stream is done")
+}
@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/network"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+const (
+	// DefaultMaxRequestSize is the default maximum size of a request.
+	DefaultMaxRequestSize = 1 * 1024 * 1024
+
+	// DefaultMaxResponseSize is the default maximum size of a response.
+	DefaultMaxResponseSize = 16 * 1024 * 1024
+
+	// DefaultMaxPeerStreams is the default maximum number of concurrent streams per peer.
+	DefaultMaxPeerStreams = 16
+
+	// requestReadTimeout is the maximum time the server waits for the client to send a request.
+	requestReadTimeout = 5 * time.Second
+	// requestHandleTimeout is the maximum time the server spends handling a request.
+	requestHandleTimeout = 60 * time.Second
+	// responseWriteTimeout is the maximum time the server waits for the client to read a response.
+	responseWriteTimeout = 60 * time.Second
+)
+
+// Service is an RPC service implementation.
+type Service interface {
+	// HandleRequest handles an incoming RPC request.
+	//
+	// The returned response is CBOR-encoded and sent back to the client. Only the module and code
+	// of returned errors are sent back to the client.
+	HandleRequest(ctx context.Context, method string, body cbor.RawMessage) (interface{}, error)
+}
+
+// Server is an RPC server for a given protocol.
+type Server interface {
+	// HandleStream handles an incoming stream.
+	HandleStream(stream network.Stream)
+}
+
+// ServerOption is a server option setter.
+type ServerOption func(opts *serverOptions)
+
+type serverOptions struct {
+	maxRequestSize  uint32
+	maxResponseSize uint32
+	maxPeerStreams  int
+}
+
+// WithMaxRequestSize configures the maximum size of a request accepted by the server.
+func WithMaxRequestSize(size uint32) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxRequestSize = size
+	}
+}
+
+// WithMaxServerResponseSize configures the maximum size of a response sent by the server.
+func WithMaxServerResponseSize(size uint32) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxResponseSize = size
+	}
+}
+
+// WithMaxPeerStreams configures the maximum number of concurrent streams handled for a single
+// peer. Any additional streams are reset.
+func WithMaxPeerStreams(n int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxPeerStreams = n
+	}
+}
+
+type server struct {
+	serverOptions
+
+	service Service
+
+	peerStreamsLock sync.Mutex
+	peerStreams     map[core.PeerID]int
+
+	logger *logging.Logger
+}
+
+func (s *server) acquirePeerStream(peerID core.PeerID) bool {
+	s.peerStreamsLock.Lock()
+	defer s.peerStreamsLock.Unlock()
+
+	if s.peerStreams[peerID] >= s.maxPeerStreams {
+		return false
+	}
+	s.peerStreams[peerID]++
+	return true
+}
+
+func (s *server) releasePeerStream(peerID core.PeerID) {
+	s.peerStreamsLock.Lock()
+	defer s.peerStreamsLock.Unlock()
+
+	s.peerStreams[peerID]--
+	if s.peerStreams[peerID] <= 0 {
+		delete(s.peerStreams, peerID)
+	}
+}
+
+func (s *server) HandleStream(stream network.Stream) {
+	peerID := stream.Conn().RemotePeer()
+	if !s.acquirePeerStream(peerID) {
+		s.logger.Debug("too many concurrent streams from peer",
+			"peer_id", peerID,
+		)
+		_ = stream.Reset()
+		return
+	}
+	defer s.releasePeerStream(peerID)
+	defer stream.Close()
+
+	_ = stream.SetReadDeadline(time.Now().Add(requestReadTimeout))
+
+	var request Request
+	if err := readMessage(stream, &request, s.maxRequestSize); err != nil {
+		s.logger.Debug("failed to read request",
+			"err", err,
+			"peer_id", peerID,
+		)
+		_ = stream.Reset()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestHandleTimeout)
+	defer cancel()
+
+	var response Response
+	rsp, err := s.service.HandleRequest(ctx, request.Method, request.Body)
+	switch err {
+	case nil:
+		response.Ok = cbor.Marshal(rsp)
+	default:
+		s.logger.Debug("failed to handle request",
+			"err", err,
+			"method", request.Method,
+			"peer_id", peerID,
+		)
+
+		// Error messages may contain private information, so only send the error code.
+		module, code := errors.Code(err)
+		if module == errors.UnknownModule {
+			module, code = errors.Code(ErrInternal)
+		}
+		response.Error = &Error{
+			Module: module,
+			Code:   code,
+		}
+	}
+
+	_ = stream.SetWriteDeadline(time.Now().Add(responseWriteTimeout))
+	if err = writeMessage(stream, &response, s.maxResponseSize); err != nil {
+		s.logger.Debug("failed to write response",
+			"err", err,
+			"method", request.Method,
+			"peer_id", peerID,
+		)
+		_ = stream.Reset()
+	}
+}
+
+// NewServer creates a new RPC server for the given protocol.
+func NewServer(protocol string, service Service, opts ...ServerOption) Server {
+	s := &server{
+		serverOptions: serverOptions{
+			maxRequestSize:  DefaultMaxRequestSize,
+			maxResponseSize: DefaultMaxResponseSize,
+			maxPeerStreams:  DefaultMaxPeerStreams,
+		},
+		service:     service,
+		peerStreams: make(map[core.PeerID]int),
+		logger:      logging.GetLogger("worker/common/p2p/rpc/server").With("protocol", protocol),
+	}
+	for _, opt := range opts {
+		opt(&s.serverOptions)
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+// Package rpc implements a request/response protocol abstraction on top of the worker P2P layer.
+package rpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+)
+
+// NOTE: Bump the version of all protocols built on top of this package if you change any of the
+//       structures below.
+
+// ModuleName is the module name used for error definitions.
+const ModuleName = "worker/common/p2p/rpc"
+
+var (
+	// ErrMethodNotSupported is the error returned when the requested method is not supported.
+	ErrMethodNotSupported = errors.New(ModuleName, 1, "rpc: method not supported")
+
+	// ErrBadRequest is the error returned when the request is malformed.
+	ErrBadRequest = errors.New(ModuleName, 2, "rpc: bad request")
+
+	// ErrInternal is the error returned when the server fails to handle a request due to an
+	// error that has no error code.
+	ErrInternal = errors.New(ModuleName, 4, "rpc: internal error")
+)
+
+// Request is a request sent by the client.
+type Request struct {
+	// Method is the name of the method.
+	Method string `json:"method"`
+	// Body is the method-specific body.
+	Body cbor.RawMessage `json:"body"`
+}
+
+// Response is a response to a previously sent request.
+type Response struct {
+	// Ok is the method-specific response in case of success.
+	Ok cbor.RawMessage `json:"ok,omitempty"`
+	// Error is an error response in case of failure.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is a message body representing an error.
+type Error struct {
+	Module  string `json:"module,omitempty"`
+	Code    uint32 `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// String returns a string representation of this error.
+func (e Error) String() string {
+	return fmt.Sprintf("error: module: %s code: %d message: %s", e.Module, e.Code, e.Message)
+}
+
+// writeMessage writes a single length-prefixed CBOR-encoded message to the given writer.
+func writeMessage(w io.Writer, msg interface{}, maxSize uint32) error {
+	data := cbor.Marshal(msg)
+	if uint64(len(data)) > uint64(maxSize) {
+		return fmt.Errorf("message too large (size: %d max: %d)", len(data), maxSize)
+	}
+
+	var rawLength [4]byte
+	binary.BigEndian.PutUint32(rawLength[:], uint32(len(data)))
+	if _, err := w.Write(rawLength[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readMessage reads a single length-prefixed CBOR-encoded message from the given reader.
+func readMessage(r io.Reader, msg interface{}, maxSize uint32) error {
+	var rawLength [4]byte
+	if _, err := io.ReadFull(r, rawLength[:]); err != nil {
+		return err
+	}
+	length := binary.BigEndian.Uint32(rawLength[:])
+	if length > maxSize {
+		return fmt.Errorf("message too large (size: %d max: %d)", length, maxSize)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return cbor.Unmarshal(data, msg)
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+func TestMessageFraming(t *testing.T) {
+	require := require.New(t)
+
+	request := Request{
+		Method: "Test",
+		Body:   cbor.Marshal([]byte("hello world")),
+	}
+
+	var buf bytes.Buffer
+	err := writeMessage(&buf, &request, DefaultMaxRequestSize)
+	require.NoError(err, "writeMessage")
+	size := buf.Len()
+
+	var decoded Request
+	err = readMessage(bytes.NewReader(buf.Bytes()), &decoded, DefaultMaxRequestSize)
+	require.NoError(err, "readMessage")
+	require.EqualValues(request, decoded, "decoded message should be equal")
+
+	// Messages over the size limit should be rejected.
+	err = writeMessage(&buf, &request, uint32(size-5))
+	require.Error(err, "writeMessage should fail for too large messages")
+	err = readMessage(bytes.NewReader(buf.Bytes()), &decoded, uint32(size-5))
+	require.Error(err, "readMessage should fail for too large messages")
+
+	// Truncated messages should be rejected.
+	err = readMessage(bytes.NewReader(buf.Bytes()[:size-1]), &decoded, DefaultMaxRequestSize)
+	require.Error(err, "readMessage should fail for truncated messages")
+}
@@ -0,0 +1,132 @@
+package txsync
+
+import (
+	"context"
+	"fmt"
+
+	core "github.com/libp2p/go-libp2p-core"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+// Client is a transaction sync protocol client.
+type Client interface {
+	// GetTxs fetches the requested transactions from the given peers. Peers are queried one at a
+	// time and in order until either all transactions have been obtained or there are no more
+	// peers to query.
+	//
+	// The returned transactions are verified against the requested hashes and any transactions
+	// that were not requested are dropped. Note that the response may not contain all of the
+	// requested transactions.
+	GetTxs(ctx context.Context, peers []core.PeerID, request *GetTxsRequest) (*GetTxsResponse, error)
+}
+
+type client struct {
+	rc rpc.Client
+
+	logger *logging.Logger
+}
+
+func (c *client) GetTxs(ctx context.Context, peers []core.PeerID, request *GetTxsRequest) (*GetTxsResponse, error) {
+	if len(peers) == 0 {
+		return nil, rpc.ErrNoPeers
+	}
+
+	missing := make(map[hash.Hash]bool, len(request.Txs))
+	for _, txHash := range request.Txs {
+		missing[txHash] = true
+	}
+
+	var rsp GetTxsResponse
+	for _, peerID := range peers {
+		if len(missing) == 0 {
+			break
+		}
+
+		txs, err := c.getTxsFromPeer(ctx, peerID, request.Txs, missing)
+		rsp.Txs = append(rsp.Txs, txs...)
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			c.logger.Warn("failed to get transactions from peer",
+				"err", err,
+				"peer_id", peerID,
+			)
+		}
+	}
+	return &rsp, nil
+}
+
+func (c *client) getTxsFromPeer(
+	ctx context.Context,
+	peerID core.PeerID,
+	requested []hash.Hash,
+	missing map[hash.Hash]bool,
+) ([][]byte, error) {
+	// Only request transactions that have not yet been received from other peers, preserving the
+	// original order.
+	pending := make([]hash.Hash, 0, len(missing))
+	for _, txHash := range requested {
+		if missing[txHash] {
+			pending = append(pending, txHash)
+		}
+	}
+
+	var txs [][]byte
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > maxGetTxsCount {
+			n = maxGetTxsCount
+		}
+
+		var rsp GetTxsResponse
+		err := c.rc.Call(ctx, peerID, MethodGetTxs, &GetTxsRequest{Txs: pending[:n]}, &rsp,
+			rpc.WithMaxPeerResponseTime(getTxsTimeout),
+		)
+		if err != nil {
+			return txs, err
+		}
+		pending = pending[n:]
+
+		accepted, unrequested := acceptTxs(missing, rsp.Txs)
+		txs = append(txs, accepted...)
+		if unrequested > 0 {
+			return txs, fmt.Errorf("peer returned %d unrequested transactions", unrequested)
+		}
+	}
+	return txs, nil
+}
+
+// acceptTxs returns the given transactions that match one of the missing hashes and removes the
+// hashes of accepted transactions from the missing set. It also returns the number of
+// transactions that were not requested (or were duplicates).
+func acceptTxs(missing map[hash.Hash]bool, txs [][]byte) ([][]byte, int) {
+	var (
+		accepted    [][]byte
+		unrequested int
+	)
+	for _, tx := range txs {
+		txHash := hash.NewFromBytes(tx)
+		if !missing[txHash] {
+			unrequested++
+			continue
+		}
+		delete(missing, txHash)
+		accepted = append(accepted, tx)
+	}
+	return accepted, unrequested
+}
+
+// NewClient creates a new transaction sync protocol client.
+func NewClient(p2p *p2p.P2P, runtimeID common.Namespace) Client {
+	return &client{
+		rc:     rpc.NewClient(p2p, runtimeID, ProtocolName),
+		logger: logging.GetLogger("worker/common/p2p/txsync/client").With("runtime_id", runtimeID),
+	}
+}
@@ -0,0 +1,137 @@
+package txsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+// testP2P is a P2P layer backed by a mock libp2p host.
+type testP2P struct {
+	host core.Host
+}
+
+func (p *testP2P) NewStream(ctx context.Context, peerID core.PeerID, runtimeID common.Namespace, name string) (network.Stream, error) {
+	return p.host.NewStream(ctx, peerID, testProtocolID(runtimeID, name))
+}
+
+func testProtocolID(runtimeID common.Namespace, name string) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/oasis/test/%s/%s", runtimeID, name))
+}
+
+// maliciousService is a transaction sync service that returns unrequested transactions.
+type maliciousService struct {
+	txs [][]byte
+}
+
+func (s *maliciousService) HandleRequest(ctx context.Context, method string, body cbor.RawMessage) (interface{}, error) {
+	return &GetTxsResponse{Txs: s.txs}, nil
+}
+
+// newTestNetwork creates a mock network with a client host and hosts serving the transaction sync
+// protocol using the given services.
+func newTestNetwork(t *testing.T, services ...rpc.Service) (Client, []core.PeerID) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mn, err := mocknet.FullMeshLinked(ctx, len(services)+1)
+	require.NoError(err, "FullMeshLinked")
+
+	var runtimeID common.Namespace
+	hosts := mn.Hosts()
+	t.Cleanup(func() {
+		for _, host := range hosts {
+			_ = host.Close()
+		}
+		cancel()
+	})
+	var peers []core.PeerID
+	for i, host := range hosts[1:] {
+		srv := rpc.NewServer(ProtocolName, services[i])
+		host.SetStreamHandler(testProtocolID(runtimeID, ProtocolName), srv.HandleStream)
+		peers = append(peers, host.ID())
+	}
+
+	return &client{
+		rc:     rpc.NewClient(&testP2P{host: hosts[0]}, runtimeID, ProtocolName),
+		logger: logging.GetLogger("worker/common/p2p/txsync/test"),
+	}, peers
+}
+
+func TestAcceptTxs(t *testing.T) {
+	require := require.New(t)
+
+	txA := []byte("tx A")
+	txB := []byte("tx B")
+	txC := []byte("tx C")
+
+	missing := map[hash.Hash]bool{
+		hash.NewFromBytes(txA): true,
+		hash.NewFromBytes(txB): true,
+	}
+
+	// Only requested transactions should be accepted.
+	accepted, unrequested := acceptTxs(missing, [][]byte{txA, txC})
+	require.Equal([][]byte{txA}, accepted, "only requested transactions should be accepted")
+	require.Equal(1, unrequested, "unrequested transactions should be counted")
+	require.Len(missing, 1, "accepted transactions should no longer be missing")
+	require.True(missing[hash.NewFromBytes(txB)])
+
+	// Duplicate transactions should not be accepted twice.
+	accepted, unrequested = acceptTxs(missing, [][]byte{txA, txB, txB})
+	require.Equal([][]byte{txB}, accepted, "duplicate transactions should not be accepted")
+	require.Equal(2, unrequested, "duplicate transactions should be counted")
+	require.Empty(missing, "all transactions should have been received")
+}
+
+func TestGetTxs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	txA := []byte("tx A")
+	txB := []byte("tx B")
+	txC := []byte("tx C")
+	txs := []hash.Hash{hash.NewFromBytes(txA), hash.NewFromBytes(txB), hash.NewFromBytes(txC)}
+
+	tc, peers := newTestNetwork(t,
+		&service{txPool: newTestTxPool(txA)},
+		&maliciousService{txs: [][]byte{txB, []byte("tx D")}},
+		&service{txPool: newTestTxPool(txA, txB, txC)},
+	)
+
+	_, err := tc.GetTxs(ctx, nil, &GetTxsRequest{Txs: txs})
+	require.ErrorIs(err, rpc.ErrNoPeers, "GetTxs without peers should fail")
+
+	// Peers should be queried in order until all transactions have been obtained.
+	rsp, err := tc.GetTxs(ctx, []core.PeerID{peers[0], peers[2]}, &GetTxsRequest{Txs: txs})
+	require.NoError(err, "GetTxs")
+	require.Equal([][]byte{txA, txB, txC}, rsp.Txs, "all transactions should be obtained")
+
+	// Requested transactions from peers returning unrequested transactions should be accepted,
+	// but the remaining ones should be fetched from other peers.
+	rsp, err = tc.GetTxs(ctx, peers[1:], &GetTxsRequest{Txs: txs})
+	require.NoError(err, "GetTxs")
+	require.Equal([][]byte{txB, txA, txC}, rsp.Txs, "all transactions should be obtained")
+
+	// Partial responses should be returned in case no peer has all of the transactions.
+	rsp, err = tc.GetTxs(ctx, peers[:2], &GetTxsRequest{Txs: txs})
+	require.NoError(err, "GetTxs")
+	require.Equal([][]byte{txA, txB}, rsp.Txs, "available transactions should be obtained")
+
+	// Peers should not be queried after all transactions have been obtained.
+	rsp, err = tc.GetTxs(ctx, []core.PeerID{peers[2], peers[1]}, &GetTxsRequest{Txs: txs})
+	require.NoError(err, "GetTxs")
+	require.Equal([][]byte{txA, txB, txC}, rsp.Txs, "all transactions should be obtained")
+}
@@ -0,0 +1,37 @@
+// Package txsync implements the transaction sync P2P protocol.
+//
+// The protocol allows nodes to fetch transactions that are referenced by a proposed batch but are
+// missing from their local transaction pool.
+package txsync
+
+import (
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+)
+
+// NOTE: Bump the ProtocolName version if you change any of the structures below.
+
+const (
+	// ProtocolName is the name of the transaction sync protocol.
+	ProtocolName = "txsync/1"
+
+	// MethodGetTxs is the GetTxs method.
+	MethodGetTxs = "GetTxs"
+
+	// maxGetTxsCount is the maximum number of transactions that can be requested at once.
+	maxGetTxsCount = 128
+
+	// getTxsTimeout is the timeout for fetching transactions from a peer.
+	getTxsTimeout = 5 * time.Second
+)
+
+// GetTxsRequest is a GetTxs request.
+type GetTxsRequest struct {
+	Txs []hash.Hash `json:"txs"`
+}
+
+// GetTxsResponse is a response to a GetTxs request.
+type GetTxsResponse struct {
+	Txs [][]byte `json:"txs,omitempty"`
+}
@@ -0,0 +1,50 @@
+package txsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+type service struct {
+	txPool txpool.TransactionPool
+}
+
+func (s *service) HandleRequest(ctx context.Context, method string, body cbor.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodGetTxs:
+		var rq GetTxsRequest
+		if err := cbor.Unmarshal(body, &rq); err != nil {
+			return nil, rpc.ErrBadRequest
+		}
+
+		return s.handleGetTxs(ctx, &rq)
+	default:
+		return nil, rpc.ErrMethodNotSupported
+	}
+}
+
+func (s *service) handleGetTxs(ctx context.Context, request *GetTxsRequest) (*GetTxsResponse, error) {
+	if len(request.Txs) > maxGetTxsCount {
+		return nil, fmt.Errorf("%w: too many transactions requested", rpc.ErrBadRequest)
+	}
+
+	var rsp GetTxsResponse
+	txs, _ := s.txPool.GetKnownBatch(request.Txs)
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		rsp.Txs = append(rsp.Txs, tx.Raw())
+	}
+	return &rsp, nil
+}
+
+// NewServer creates a new transaction sync protocol server serving transactions from the given
+// transaction pool.
+func NewServer(txPool txpool.TransactionPool) rpc.Server {
+	return rpc.NewServer(ProtocolName, &service{txPool: txPool})
+}
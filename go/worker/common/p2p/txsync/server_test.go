@@ -0,0 +1,69 @@
+package txsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+// testTxPool is a transaction pool that only knows about a fixed set of transactions.
+type testTxPool struct {
+	txpool.TransactionPool
+
+	txs map[hash.Hash]*transaction.CheckedTransaction
+}
+
+func (tp *testTxPool) GetKnownBatch(batch []hash.Hash) ([]*transaction.CheckedTransaction, map[hash.Hash]int) {
+	txs := make([]*transaction.CheckedTransaction, 0, len(batch))
+	missing := make(map[hash.Hash]int)
+	for idx, txHash := range batch {
+		tx := tp.txs[txHash]
+		if tx == nil {
+			missing[txHash] = idx
+		}
+		txs = append(txs, tx)
+	}
+	return txs, missing
+}
+
+func newTestTxPool(txs ...[]byte) *testTxPool {
+	tp := &testTxPool{
+		txs: make(map[hash.Hash]*transaction.CheckedTransaction),
+	}
+	for _, tx := range txs {
+		tp.txs[hash.NewFromBytes(tx)] = transaction.RawCheckedTransaction(tx)
+	}
+	return tp
+}
+
+func TestServerGetTxs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	txA := []byte("tx A")
+	txB := []byte("tx B")
+	txC := []byte("tx C")
+	s := &service{txPool: newTestTxPool(txA, txB)}
+
+	// Only known transactions should be returned, in the requested order.
+	rsp, err := s.handleGetTxs(ctx, &GetTxsRequest{
+		Txs: []hash.Hash{hash.NewFromBytes(txB), hash.NewFromBytes(txC), hash.NewFromBytes(txA)},
+	})
+	require.NoError(err, "handleGetTxs")
+	require.Equal([][]byte{txB, txA}, rsp.Txs, "known transactions should be returned")
+
+	rsp, err = s.handleGetTxs(ctx, &GetTxsRequest{Txs: []hash.Hash{hash.NewFromBytes(txC)}})
+	require.NoError(err, "handleGetTxs")
+	require.Empty(rsp.Txs, "unknown transactions should not be returned")
+
+	// Requesting too many transactions at once should be rejected.
+	_, err = s.handleGetTxs(ctx, &GetTxsRequest{Txs: make([]hash.Hash, maxGetTxsCount+1)})
+	require.True(errors.Is(err, rpc.ErrBadRequest), "too many requested transactions should be rejected")
+}
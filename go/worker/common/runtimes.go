@@ -0,0 +1,53 @@
+package common
+
+// RuntimeService is a per-runtime service managed by a worker.
+type RuntimeService interface {
+	// Quit returns a channel that will be closed when the service terminates.
+	Quit() <-chan struct{}
+
+	// Initialized returns a channel that will be closed when the service is initialized.
+	Initialized() <-chan struct{}
+}
+
+// WaitRuntimesInitialized blocks until the services of all runtimes returned by getRuntimes are
+// initialized.
+//
+// Runtimes that are added while waiting are waited for as well, while runtimes that terminate
+// before being initialized (e.g., because they were removed) are skipped.
+func WaitRuntimesInitialized(getRuntimes func() []RuntimeService) {
+	waited := make(map[RuntimeService]bool)
+	for {
+		var pending []RuntimeService
+		for _, rt := range getRuntimes() {
+			if waited[rt] {
+				continue
+			}
+			waited[rt] = true
+			pending = append(pending, rt)
+		}
+		if len(pending) == 0 {
+			return
+		}
+
+		for _, rt := range pending {
+			select {
+			case <-rt.Initialized():
+			case <-rt.Quit():
+			}
+		}
+	}
+}
+
+// WaitRuntimesQuit blocks until the worker is stopped (stopCh is closed) and the services of all
+// runtimes returned by getRuntimes have terminated.
+//
+// As runtimes may be added and removed while the worker is running, the set of runtimes is only
+// considered after the worker has been stopped. Workers must not register any new runtimes after
+// that point.
+func WaitRuntimesQuit(stopCh <-chan struct{}, getRuntimes func() []RuntimeService) {
+	<-stopCh
+
+	for _, rt := range getRuntimes() {
+		<-rt.Quit()
+	}
+}
@@ -0,0 +1,119 @@
+package common
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const recvTimeout = 1 * time.Second
+
+type testRuntimeService struct {
+	quitCh chan struct{}
+	initCh chan struct{}
+}
+
+func (s *testRuntimeService) Quit() <-chan struct{} {
+	return s.quitCh
+}
+
+func (s *testRuntimeService) Initialized() <-chan struct{} {
+	return s.initCh
+}
+
+func newTestRuntimeService() *testRuntimeService {
+	return &testRuntimeService{
+		quitCh: make(chan struct{}),
+		initCh: make(chan struct{}),
+	}
+}
+
+type testRuntimeServices struct {
+	sync.Mutex
+
+	services []RuntimeService
+}
+
+func (s *testRuntimeServices) add(svc RuntimeService) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.services = append(s.services, svc)
+}
+
+func (s *testRuntimeServices) get() []RuntimeService {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]RuntimeService{}, s.services...)
+}
+
+func requireBlocked(t *testing.T, ch <-chan struct{}, msg string) {
+	select {
+	case <-ch:
+		require.FailNow(t, msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func requireDone(t *testing.T, ch <-chan struct{}, msg string) {
+	select {
+	case <-ch:
+	case <-time.After(recvTimeout):
+		require.FailNow(t, msg)
+	}
+}
+
+func TestWaitRuntimesInitialized(t *testing.T) {
+	var services testRuntimeServices
+	first := newTestRuntimeService()
+	services.add(first)
+
+	doneCh := make(chan struct{})
+	go func() {
+		WaitRuntimesInitialized(services.get)
+		close(doneCh)
+	}()
+
+	// Add a runtime while waiting for the first one to be initialized.
+	added := newTestRuntimeService()
+	services.add(added)
+	// Add a runtime that will terminate before being initialized.
+	removed := newTestRuntimeService()
+	services.add(removed)
+
+	close(first.initCh)
+	requireBlocked(t, doneCh, "should wait for runtimes added while waiting")
+
+	close(removed.quitCh)
+	requireBlocked(t, doneCh, "should wait for runtimes added while waiting")
+
+	close(added.initCh)
+	requireDone(t, doneCh, "should return once all runtimes are initialized")
+}
+
+func TestWaitRuntimesQuit(t *testing.T) {
+	var services testRuntimeServices
+	stopCh := make(chan struct{})
+
+	doneCh := make(chan struct{})
+	go func() {
+		WaitRuntimesQuit(stopCh, services.get)
+		close(doneCh)
+	}()
+
+	// Without any runtimes, the worker should only terminate after being stopped.
+	requireBlocked(t, doneCh, "should wait for the worker to be stopped")
+
+	// Runtimes added while running should be waited for.
+	added := newTestRuntimeService()
+	services.add(added)
+
+	close(stopCh)
+	requireBlocked(t, doneCh, "should wait for runtimes added while running")
+
+	close(added.quitCh)
+	requireDone(t, doneCh, "should return once all runtimes have terminated")
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/grpc"
@@ -37,10 +38,12 @@ type Worker struct {
 	RuntimeRegistry   runtimeRegistry.Registry
 	GenesisDoc        *genesis.Document
 
-	runtimes map[common.Namespace]*committee.Node
+	runtimesLock sync.RWMutex
+	runtimes     map[common.Namespace]*committee.Node
 
 	ctx       context.Context
 	cancelCtx context.CancelFunc
+	stopCh    chan struct{}
 	quitCh    chan struct{}
 	initCh    chan struct{}
 
@@ -67,24 +70,20 @@ func (w *Worker) Start() error {
 	go func() {
 		defer close(w.quitCh)
 
-		for _, rt := range w.runtimes {
-			<-rt.Quit()
-		}
+		WaitRuntimesQuit(w.stopCh, w.getRuntimeServices)
 
 		<-w.Grpc.Quit()
 	}()
 
 	// Wait for all runtimes to be initialized.
 	go func() {
-		for _, rt := range w.runtimes {
-			<-rt.Initialized()
-		}
+		WaitRuntimesInitialized(w.getRuntimeServices)
 
 		close(w.initCh)
 	}()
 
 	// Start runtime services.
-	for id, rt := range w.runtimes {
+	for id, rt := range w.GetRuntimes() {
 		w.logger.Info("starting services for runtime",
 			"runtime_id", id,
 		)
@@ -104,7 +103,12 @@ func (w *Worker) Stop() {
 		return
 	}
 
-	for id, rt := range w.runtimes {
+	// Prevent any new runtimes from being added.
+	w.runtimesLock.Lock()
+	close(w.stopCh)
+	w.runtimesLock.Unlock()
+
+	for id, rt := range w.GetRuntimes() {
 		w.logger.Info("stopping services for runtime",
 			"runtime_id", id,
 		)
@@ -132,7 +136,7 @@ func (w *Worker) Cleanup() {
 		return
 	}
 
-	for _, rt := range w.runtimes {
+	for _, rt := range w.GetRuntimes() {
 		rt.Cleanup()
 	}
 
@@ -152,22 +156,132 @@ func (w *Worker) GetConfig() Config {
 
 // GetRuntimes returns a map of configured runtimes.
 func (w *Worker) GetRuntimes() map[common.Namespace]*committee.Node {
-	return w.runtimes
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make(map[common.Namespace]*committee.Node, len(w.runtimes))
+	for id, rt := range w.runtimes {
+		runtimes[id] = rt
+	}
+	return runtimes
+}
+
+func (w *Worker) getRuntimeServices() []RuntimeService {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make([]RuntimeService, 0, len(w.runtimes))
+	for _, rt := range w.runtimes {
+		runtimes = append(runtimes, rt)
+	}
+	return runtimes
 }
 
 // GetRuntime returns a common committee node for the given runtime (if available).
 //
 // In case the runtime with the specified id was not configured for this node it returns nil.
 func (w *Worker) GetRuntime(id common.Namespace) *committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
 	return w.runtimes[id]
 }
 
+// AddRuntime registers and starts services for a new runtime while the worker is running.
+//
+// The setup function is called before the runtime services are started so that other workers can
+// register their hooks with the new committee node.
+func (w *Worker) AddRuntime(runtime runtimeRegistry.Runtime, setupFn func(*committee.Node) error) error {
+	if !w.enabled {
+		return fmt.Errorf("worker/common: worker is disabled")
+	}
+
+	id := runtime.ID()
+	if err := w.registerRuntime(runtime); err != nil {
+		return err
+	}
+	rt := w.GetRuntime(id)
+
+	if err := setupFn(rt); err != nil {
+		w.runtimesLock.Lock()
+		delete(w.runtimes, id)
+		w.runtimesLock.Unlock()
+
+		rt.Stop()
+		rt.Cleanup()
+		w.P2P.RemoveRuntime(id)
+		return err
+	}
+
+	w.logger.Info("starting services for runtime",
+		"runtime_id", id,
+	)
+
+	return rt.Start()
+}
+
+// RemoveRuntime stops all services for the given runtime and unregisters it from the worker.
+//
+// The services are only stopped once the node is no longer elected into any of the runtime's
+// committees, so the caller should make sure that the node is no longer registered for the runtime.
+func (w *Worker) RemoveRuntime(ctx context.Context, id common.Namespace) error {
+	rt := w.GetRuntime(id)
+	if rt == nil {
+		return nil
+	}
+
+	if err := rt.WaitNotElected(ctx); err != nil {
+		return err
+	}
+
+	w.runtimesLock.Lock()
+	delete(w.runtimes, id)
+	w.runtimesLock.Unlock()
+
+	w.logger.Info("stopping services for runtime",
+		"runtime_id", id,
+	)
+
+	rt.Stop()
+	select {
+	case <-rt.Quit():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	rt.Cleanup()
+
+	w.P2P.RemoveRuntime(id)
+
+	w.logger.Info("runtime removed",
+		"runtime_id", id,
+	)
+
+	return nil
+}
+
 func (w *Worker) registerRuntime(runtime runtimeRegistry.Runtime) error {
 	id := runtime.ID()
 	w.logger.Info("registering new runtime",
 		"runtime_id", id,
 	)
 
+	w.runtimesLock.Lock()
+	defer w.runtimesLock.Unlock()
+
+	select {
+	case <-w.stopCh:
+		return fmt.Errorf("worker/common: worker is stopped")
+	default:
+	}
+	if _, ok := w.runtimes[id]; ok {
+		return fmt.Errorf("worker/common: runtime already registered: %s", id)
+	}
+
+	txPoolCfg := w.cfg.TxPool
+	if w.cfg.TxPoolJournal {
+		txPoolCfg.JournalDir = runtimeRegistry.GetRuntimeStateDir(w.DataDir, id)
+	}
+
 	node, err := committee.NewNode(
 		w.HostNode,
 		runtime,
@@ -175,7 +289,7 @@ func (w *Worker) registerRuntime(runtime runtimeRegistry.Runtime) error {
 		w.KeyManager,
 		w.Consensus,
 		w.P2P,
-		&w.cfg.TxPool,
+		&txPoolCfg,
 	)
 	if err != nil {
 		return err
@@ -231,6 +345,7 @@ func newWorker(
 		runtimes:          make(map[common.Namespace]*committee.Node),
 		ctx:               ctx,
 		cancelCtx:         cancelCtx,
+		stopCh:            make(chan struct{}),
 		quitCh:            make(chan struct{}),
 		initCh:            make(chan struct{}),
 		logger:            logging.GetLogger("worker/common"),
@@ -2,6 +2,7 @@ package committee
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
@@ -15,6 +16,8 @@ type unresolvedBatch struct {
 
 	batch      transaction.RawBatch
 	missingTxs map[hash.Hash]int
+	// fetchStartTime is the time at which fetching missing transactions from peers started.
+	fetchStartTime time.Time
 
 	maxBatchSizeBytes uint64
 }
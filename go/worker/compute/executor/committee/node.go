@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	core "github.com/libp2p/go-libp2p-core"
 	"github.com/prometheus/client_golang/prometheus"
 
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common/crash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
@@ -28,6 +31,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
 	p2pError "github.com/oasisprotocol/oasis-core/go/worker/common/p2p/error"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/txsync"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 )
 
@@ -48,6 +52,9 @@ var (
 	proposeTimeoutDelay = 2 * time.Second
 	// abortTimeout is the duration to wait for the runtime to abort.
 	abortTimeout = 5 * time.Second
+	// missingTxsFetchInterval is the duration to wait for missing transactions to be received via
+	// gossip before fetching them from peers, and between subsequent fetch attempts.
+	missingTxsFetchInterval = 500 * time.Millisecond
 )
 
 var (
@@ -100,6 +107,20 @@ var (
 		},
 		[]string{"runtime"},
 	)
+	missingTxsFetchCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_execution_missing_txs_fetch_count",
+			Help: "Number of requests to fetch missing proposed transactions from peers.",
+		},
+		[]string{"runtime"},
+	)
+	missingTxsFetchTime = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "oasis_worker_execution_missing_txs_fetch_time",
+			Help: "Time it takes to obtain all missing proposed transactions after fetching them from peers has started (seconds).",
+		},
+		[]string{"runtime"},
+	)
 	nodeCollectors = []prometheus.Collector{
 		discrepancyDetectedCount,
 		abortedBatchCount,
@@ -108,6 +129,8 @@ var (
 		batchProcessingTime,
 		batchRuntimeProcessingTime,
 		batchSize,
+		missingTxsFetchCount,
+		missingTxsFetchTime,
 	}
 
 	metricsOnce sync.Once
@@ -118,6 +141,12 @@ type Node struct { // nolint: maligned
 	runtimeReady         bool
 	runtimeVersion       version.Version
 	runtimeCapabilityTEE *node.CapabilityTEE
+	// runtimeNext is the started event of the next runtime version in case it is being hosted
+	// ahead of a scheduled version change.
+	runtimeNext *host.StartedEvent
+	// runtimeDegraded is true while the runtime is restarting too often. A degraded runtime is not
+	// advertised so the node does not get elected into executor committees.
+	runtimeDegraded bool
 
 	limitsLastUpdateLock sync.Mutex
 	// limitsLastUpdate is the round of the last update of the round weight limits.
@@ -126,6 +155,8 @@ type Node struct { // nolint: maligned
 	// Guarded by .commonNode.CrossNode.
 	proposingTimeout bool
 	prevEpochWorker  bool
+	// epochTransitioned is true after the first epoch transition since startup was handled.
+	epochTransitioned bool
 
 	commonNode   *committee.Node
 	commonCfg    commonWorker.Config
@@ -147,6 +178,9 @@ type Node struct { // nolint: maligned
 	roundCancelCtx context.CancelFunc
 
 	storage storage.LocalBackend
+	txSync  txsync.Client
+	// equivocationWatcher is only set when the equivocation watcher is enabled.
+	equivocationWatcher *committee.EquivocationWatcher
 
 	stateTransitions *pubsub.Broker
 	// Bump this when we need to change what the worker selects over.
@@ -281,11 +315,7 @@ func (n *Node) transitionLocked(state NodeState) {
 func (n *Node) HandleEpochTransitionLocked(epoch *committee.EpochSnapshot) {
 	switch {
 	case epoch.IsExecutorWorker():
-		if !n.prevEpochWorker {
-			// Clear incoming queue and cache of any stale transactions in case
-			// we were not part of the compute committee in previous epoch.
-			n.commonNode.TxPool.Clear()
-		}
+		n.clearStaleTxsLocked()
 		fallthrough
 	case epoch.IsExecutorBackupWorker():
 		n.transitionLocked(StateWaitingForBatch{})
@@ -293,6 +323,20 @@ func (n *Node) HandleEpochTransitionLocked(epoch *committee.EpochSnapshot) {
 		n.transitionLocked(StateNotReady{})
 	}
 	n.prevEpochWorker = epoch.IsExecutorWorker()
+	n.epochTransitioned = true
+}
+
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) clearStaleTxsLocked() {
+	switch {
+	case !n.epochTransitioned:
+		// The first epoch transition after startup is always forced and the pool can only contain
+		// transactions submitted since (or replayed from the journal), so none of them are stale.
+	case !n.prevEpochWorker:
+		// Clear incoming queue and cache of any stale transactions in case
+		// we were not part of the compute committee in previous epoch.
+		n.commonNode.TxPool.Clear()
+	}
 }
 
 // HandleNewBlockEarlyLocked implements NodeHooks.
@@ -431,12 +475,119 @@ func (n *Node) handleNewCheckedTransactions(txs []*transaction.CheckedTransactio
 		delete(state.batch.missingTxs, tx.Hash())
 	}
 	if len(state.batch.missingTxs) == 0 {
+		// Transactions could have been received either from peers or via gossip.
+		if !state.batch.fetchStartTime.IsZero() {
+			missingTxsFetchTime.With(n.getMetricLabels()).Observe(time.Since(state.batch.fetchStartTime).Seconds())
+		}
+
 		// We have all transactions, signal the node to start processing the batch.
 		n.logger.Info("received all transactions needed for batch processing")
 		n.startProcessingBatchLocked(state.batch)
 	}
 }
 
+// fetchMissingTxs periodically fetches any transactions that are still needed to resolve the given
+// batch from the proposer and other executor committee members until either all transactions have
+// been received or the node is no longer waiting for them.
+func (n *Node) fetchMissingTxs(ctx context.Context, batch *unresolvedBatch, epoch *committee.EpochSnapshot) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(missingTxsFetchInterval):
+		}
+
+		missing := n.getMissingTxs(batch)
+		if len(missing) == 0 {
+			return
+		}
+
+		n.logger.Debug("fetching missing transactions from peers",
+			"num_missing", len(missing),
+		)
+		missingTxsFetchCount.With(n.getMetricLabels()).Inc()
+
+		rsp, err := n.txSync.GetTxs(ctx, n.getMissingTxsPeers(batch, epoch), &txsync.GetTxsRequest{
+			Txs: missing,
+		})
+		if err != nil {
+			n.logger.Warn("failed to fetch missing transactions from peers",
+				"err", err,
+			)
+			continue
+		}
+		if len(rsp.Txs) == 0 || ctx.Err() != nil {
+			continue
+		}
+
+		// The fetched transactions have been verified against the proposed hashes and as the batch
+		// has been signed by the transaction scheduler, they can be used without being checked.
+		n.commonNode.TxPool.SubmitProposedBatch(rsp.Txs)
+
+		txs := make([]*transaction.CheckedTransaction, 0, len(rsp.Txs))
+		for _, rawTx := range rsp.Txs {
+			txs = append(txs, transaction.RawCheckedTransaction(rawTx))
+		}
+		n.handleNewCheckedTransactions(txs)
+
+		if len(rsp.Txs) == len(missing) {
+			return
+		}
+	}
+}
+
+// getMissingTxs returns the hashes of transactions that are still missing in order to resolve the
+// given batch, ordered by their position in the batch. In case the node is no longer waiting for
+// transactions of the given batch, nil is returned.
+//
+// The first call that returns any missing transactions marks the start of fetching them.
+func (n *Node) getMissingTxs(batch *unresolvedBatch) []hash.Hash {
+	n.commonNode.CrossNode.Lock()
+	defer n.commonNode.CrossNode.Unlock()
+
+	state, ok := n.state.(StateWaitingForTxs)
+	if !ok || state.batch != batch {
+		return nil
+	}
+
+	missing := make([]hash.Hash, 0, len(batch.missingTxs))
+	for txHash := range batch.missingTxs {
+		missing = append(missing, txHash)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return batch.missingTxs[missing[i]] < batch.missingTxs[missing[j]]
+	})
+	if len(missing) > 0 && batch.fetchStartTime.IsZero() {
+		batch.fetchStartTime = time.Now()
+	}
+	return missing
+}
+
+// getMissingTxsPeers returns the peers that should be asked for missing transactions of the given
+// batch. The proposer is always asked first as it must have all of the proposed transactions.
+func (n *Node) getMissingTxsPeers(batch *unresolvedBatch, epoch *committee.EpochSnapshot) []core.PeerID {
+	proposerID := batch.proposal.NodeID
+	peers := n.commonNode.P2P.PeersForNodes([]signature.PublicKey{proposerID})
+
+	executorCommittee := epoch.GetExecutorCommittee()
+	if executorCommittee == nil {
+		return peers
+	}
+
+	ownID := n.commonNode.Identity.NodeSigner.Public()
+	seen := make(map[signature.PublicKey]bool)
+	var nodeIDs []signature.PublicKey
+	for _, member := range executorCommittee.Committee.Members {
+		nodeID := member.PublicKey
+		if seen[nodeID] || nodeID.Equal(proposerID) || nodeID.Equal(ownID) {
+			continue
+		}
+		seen[nodeID] = true
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	return append(peers, n.commonNode.P2P.PeersForNodes(nodeIDs)...)
+}
+
 // removeTxBatch removes a batch from scheduling queue.
 func (n *Node) removeTxBatch(batch transaction.RawBatch) error {
 	hashes := make([]hash.Hash, len(batch))
@@ -974,9 +1125,11 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 	}
 	if resolvedBatch == nil {
 		// Some transactions are missing so we cannot start processing the batch just yet.
-		// Transition into StateWaitingForTxs and wait for peers to republish transactions.
+		// Transition into StateWaitingForTxs and wait for peers to republish transactions while
+		// also actively fetching them from the proposer and other committee members.
 		n.logger.Debug("some transactions are missing", "num_missing", len(batch.missingTxs))
 		n.transitionLocked(StateWaitingForTxs{batch})
+		go n.fetchMissingTxs(n.roundCtx, batch, n.commonNode.Group.GetEpochSnapshot())
 		return
 	}
 
@@ -1359,16 +1512,38 @@ func (n *Node) nudgeAvailability(force bool) {
 	lastRoundAvailable := (err == nil)
 
 	switch {
-	case n.runtimeReady && lastRoundAvailable:
+	case n.runtimeReady && !n.runtimeDegraded && lastRoundAvailable:
 		// Executor is ready to process requests.
 		if n.roleProvider.IsAvailable() && !force {
 			break
 		}
 
+		next := n.runtimeNext
 		n.roleProvider.SetAvailable(func(nd *node.Node) error {
 			rt := nd.AddOrUpdateRuntime(n.commonNode.Runtime.ID())
 			rt.Version = n.runtimeVersion
 			rt.Capabilities.TEE = n.runtimeCapabilityTEE
+
+			// In case the next runtime version is already running, also register for it so that
+			// we can be elected as soon as the scheduled version change takes effect. This is
+			// only allowed while the version change is scheduled in the runtime descriptor.
+			if next == nil || (next.Version == rt.Version && next.CapabilityTEE == nil && rt.Capabilities.TEE == nil) {
+				return nil
+			}
+			rd, err := n.commonNode.Runtime.RegistryDescriptor(n.ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get runtime registry descriptor: %w", err)
+			}
+			if rd.ScheduledVersion == nil {
+				return nil
+			}
+			nd.Runtimes = append(nd.Runtimes, &node.Runtime{
+				ID:      rt.ID,
+				Version: next.Version,
+				Capabilities: node.Capabilities{
+					TEE: next.CapabilityTEE,
+				},
+			})
 			return nil
 		})
 	default:
@@ -1395,6 +1570,18 @@ func (n *Node) HandleRuntimeHostEvent(ev *host.Event) {
 	case ev.FailedToStart != nil, ev.Stopped != nil:
 		// Runtime failed to start or was stopped -- we can no longer service requests.
 		n.runtimeReady = false
+	case ev.NextVersion != nil:
+		// Next runtime version status has changed.
+		n.runtimeNext = ev.NextVersion.Started
+	case ev.ResourceExceeded != nil:
+		// Runtime exceeded its resource limits, it will be stopped.
+		n.logger.Warn("runtime exceeded its resource limits",
+			"resource", ev.ResourceExceeded.Resource,
+		)
+		n.runtimeReady = false
+	case ev.Degraded != nil:
+		// Runtime degraded state has changed.
+		n.runtimeDegraded = ev.Degraded.Degraded
 	default:
 		// Unknown event.
 		n.logger.Warn("unknown worker event",
@@ -1539,8 +1726,12 @@ func NewNode(
 		state:            StateNotReady{},
 		stateTransitions: pubsub.NewBroker(false),
 		reselect:         make(chan struct{}, 1),
+		txSync:           txsync.NewClient(commonNode.P2P, commonNode.Runtime.ID()),
 		logger:           logging.GetLogger("worker/executor/committee").With("runtime_id", commonNode.Runtime.ID()),
 	}
+	if commonCfg.EquivocationWatcher {
+		n.equivocationWatcher = commonNode.EquivocationWatcher()
+	}
 
 	// Register prune handler.
 	commonNode.Runtime.History().Pruner().RegisterHandler(&pruneHandler{commonNode: commonNode})
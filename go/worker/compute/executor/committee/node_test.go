@@ -0,0 +1,196 @@
+package committee
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	"github.com/oasisprotocol/oasis-core/go/runtime/txpool"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+)
+
+var testRuntimeID = common.NewTestNamespaceFromSeed([]byte("executor committee test"), 0)
+
+// testRuntime is a hosted runtime that accepts all transactions.
+type testRuntime struct {
+	host.RichRuntime
+}
+
+func (r *testRuntime) CheckTx(
+	ctx context.Context,
+	rb *block.Block,
+	lb *consensus.LightBlock,
+	epoch beacon.EpochTime,
+	maxMessages uint32,
+	batch transaction.RawBatch,
+) ([]protocol.CheckTxResult, error) {
+	return make([]protocol.CheckTxResult, len(batch)), nil
+}
+
+func (r *testRuntime) WaitHostedRuntime(ctx context.Context) (host.RichRuntime, error) {
+	return r, nil
+}
+
+type testPublisher struct{}
+
+func (p *testPublisher) PublishTx(ctx context.Context, tx []byte) error {
+	return nil
+}
+
+func (p *testPublisher) GetMinRepublishInterval() time.Duration {
+	return time.Hour
+}
+
+func newTestTxPool(t *testing.T, journalDir string) txpool.TransactionPool {
+	tp, err := txpool.New(testRuntimeID, &txpool.Config{
+		MaxPoolSize:          100,
+		MaxCheckTxBatchSize:  100,
+		MaxLastSeenCacheSize: 100,
+		MaxStaleCacheSize:    100,
+		RepublishInterval:    time.Hour,
+		RecheckInterval:      1000,
+		JournalDir:           journalDir,
+	}, &testRuntime{}, &testPublisher{})
+	require.NoError(t, err, "txpool.New")
+	require.NoError(t, tp.Start(), "Start")
+	return tp
+}
+
+func processBlock(t *testing.T, tp txpool.TransactionPool, round uint64) {
+	blk := block.NewGenesisBlock(testRuntimeID, 0)
+	blk.Header.Round = round
+	blk.Header.HeaderType = block.EpochTransition
+
+	err := tp.ProcessBlock(&txpool.BlockInfo{
+		RuntimeBlock:   blk,
+		ConsensusBlock: &consensus.LightBlock{},
+		ActiveDescriptor: &registry.Runtime{
+			TxnScheduler: registry.TxnSchedulerParameters{
+				Algorithm:         registry.TxnSchedulerSimple,
+				BatchFlushTimeout: time.Hour,
+				MaxBatchSize:      10,
+				MaxBatchSizeBytes: 1024,
+			},
+		},
+	})
+	require.NoError(t, err, "ProcessBlock")
+}
+
+// waitTxStatus waits for an event with the given status for the given transaction.
+func waitTxStatus(t *testing.T, ch <-chan []*txpool.TxEvent, txHash hash.Hash, status txpool.TxStatus) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case events := <-ch:
+			for _, ev := range events {
+				if ev.Hash.Equal(&txHash) && ev.Status == status {
+					return
+				}
+			}
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for transaction event", "status: %s", status)
+		}
+	}
+}
+
+func TestMissingTxs(t *testing.T) {
+	require := require.New(t)
+
+	txA := []byte("tx A")
+	txB := []byte("tx B")
+	txC := []byte("tx C")
+	batch := &unresolvedBatch{
+		proposal: &commitment.Proposal{},
+		missingTxs: map[hash.Hash]int{
+			hash.NewFromBytes(txC): 2,
+			hash.NewFromBytes(txA): 0,
+			hash.NewFromBytes(txB): 1,
+		},
+	}
+	n := &Node{
+		commonNode: &committee.Node{},
+		state:      StateNotReady{},
+		logger:     logging.GetLogger("worker/executor/committee/test"),
+	}
+
+	// Nothing should be fetched when not waiting for transactions of the batch.
+	require.Nil(n.getMissingTxs(batch), "no transactions should be missing when not waiting")
+	require.True(batch.fetchStartTime.IsZero(), "fetching should not be started")
+
+	n.state = StateWaitingForTxs{batch: &unresolvedBatch{proposal: &commitment.Proposal{}}}
+	require.Nil(n.getMissingTxs(batch), "no transactions should be missing for other batches")
+
+	// Missing transactions should be ordered by their position in the batch.
+	n.state = StateWaitingForTxs{batch: batch}
+	missing := n.getMissingTxs(batch)
+	require.Equal([]hash.Hash{hash.NewFromBytes(txA), hash.NewFromBytes(txB), hash.NewFromBytes(txC)}, missing)
+	fetchStartTime := batch.fetchStartTime
+	require.False(fetchStartTime.IsZero(), "fetching should be started")
+
+	// Transactions received from any source should no longer be missing.
+	n.handleNewCheckedTransactions([]*transaction.CheckedTransaction{
+		transaction.RawCheckedTransaction(txB),
+		transaction.RawCheckedTransaction([]byte("tx D")),
+	})
+	missing = n.getMissingTxs(batch)
+	require.Equal([]hash.Hash{hash.NewFromBytes(txA), hash.NewFromBytes(txC)}, missing)
+	require.Equal(fetchStartTime, batch.fetchStartTime, "fetch start time should not change")
+}
+
+func TestJournalReplayAfterRestart(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-executor-committee-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	tx := []byte("tx")
+	txHash := hash.NewFromBytes(tx)
+	func() {
+		tp := newTestTxPool(t, dir)
+		defer tp.Stop()
+
+		processBlock(t, tp, 10)
+		result, err := tp.SubmitTx(context.Background(), tx, &txpool.TransactionMeta{Local: true})
+		require.NoError(err, "SubmitTx")
+		require.True(result.IsSuccess(), "transaction should pass checks")
+	}()
+
+	// Restart the executor with the same journal.
+	tp := newTestTxPool(t, dir)
+	defer tp.Stop()
+	sub, ch := tp.WatchTxEvents()
+	defer sub.Close()
+
+	n := &Node{
+		commonNode: &committee.Node{TxPool: tp},
+		logger:     logging.GetLogger("worker/executor/committee/test"),
+	}
+
+	processBlock(t, tp, 11)
+	waitTxStatus(t, ch, txHash, txpool.TxStatusAccepted)
+
+	// The first epoch transition after startup should not drop replayed transactions.
+	n.clearStaleTxsLocked()
+	n.epochTransitioned = true
+	require.True(tp.IsPending(txHash), "replayed transaction should be pending after the first epoch transition")
+
+	// Later epoch transitions should still clear stale transactions.
+	n.clearStaleTxsLocked()
+	require.False(tp.IsPending(txHash), "stale transaction should be cleared when joining the committee")
+}
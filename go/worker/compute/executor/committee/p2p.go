@@ -87,6 +87,10 @@ func (h *committeeMsgHandler) HandleMessage(ctx context.Context, peerID signatur
 			return p2pError.Permanent(err)
 		}
 
+		if h.n.equivocationWatcher != nil {
+			h.n.equivocationWatcher.ObserveProposal(proposal)
+		}
+
 		err := h.n.queueBatchBlocking(ctx, proposal)
 		if err != nil {
 			return err
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
@@ -21,10 +22,12 @@ type Worker struct {
 	commonWorker *workerCommon.Worker
 	registration *registration.Worker
 
-	runtimes map[common.Namespace]*committee.Node
+	runtimesLock sync.RWMutex
+	runtimes     map[common.Namespace]*committee.Node
 
 	ctx       context.Context
 	cancelCtx context.CancelFunc
+	stopCh    chan struct{}
 	quitCh    chan struct{}
 	initCh    chan struct{}
 
@@ -52,17 +55,13 @@ func (w *Worker) Start() error {
 		defer close(w.quitCh)
 		defer (w.cancelCtx)()
 
-		for _, rt := range w.runtimes {
-			<-rt.Quit()
-		}
+		workerCommon.WaitRuntimesQuit(w.stopCh, w.getRuntimeServices)
 	}()
 
 	// Wait for all runtimes to be initialized and for the node
 	// to be registered for the current epoch.
 	go func() {
-		for _, rt := range w.runtimes {
-			<-rt.Initialized()
-		}
+		workerCommon.WaitRuntimesInitialized(w.getRuntimeServices)
 
 		<-w.registration.InitialRegistrationCh()
 
@@ -70,7 +69,7 @@ func (w *Worker) Start() error {
 	}()
 
 	// Start runtime services.
-	for id, rt := range w.runtimes {
+	for id, rt := range w.getRuntimes() {
 		w.logger.Info("starting services for runtime",
 			"runtime_id", id,
 		)
@@ -90,7 +89,12 @@ func (w *Worker) Stop() {
 		return
 	}
 
-	for id, rt := range w.runtimes {
+	// Prevent any new runtimes from being added.
+	w.runtimesLock.Lock()
+	close(w.stopCh)
+	w.runtimesLock.Unlock()
+
+	for id, rt := range w.getRuntimes() {
 		w.logger.Info("stopping services for runtime",
 			"runtime_id", id,
 		)
@@ -115,7 +119,7 @@ func (w *Worker) Cleanup() {
 		return
 	}
 
-	for _, rt := range w.runtimes {
+	for _, rt := range w.getRuntimes() {
 		rt.Cleanup()
 	}
 }
@@ -131,9 +135,86 @@ func (w *Worker) Initialized() <-chan struct{} {
 // In case the runtime with the specified id was not registered it
 // returns nil.
 func (w *Worker) GetRuntime(id common.Namespace) *committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
 	return w.runtimes[id]
 }
 
+func (w *Worker) getRuntimes() map[common.Namespace]*committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make(map[common.Namespace]*committee.Node, len(w.runtimes))
+	for id, rt := range w.runtimes {
+		runtimes[id] = rt
+	}
+	return runtimes
+}
+
+func (w *Worker) getRuntimeServices() []workerCommon.RuntimeService {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make([]workerCommon.RuntimeService, 0, len(w.runtimes))
+	for _, rt := range w.runtimes {
+		runtimes = append(runtimes, rt)
+	}
+	return runtimes
+}
+
+// AddRuntime registers and starts a executor committee node for a runtime that has been added
+// while the worker is running.
+func (w *Worker) AddRuntime(commonNode *committeeCommon.Node) error {
+	if !w.enabled {
+		return nil
+	}
+
+	if err := w.registerRuntime(commonNode); err != nil {
+		return err
+	}
+	return w.GetRuntime(commonNode.Runtime.ID()).Start()
+}
+
+// RemoveRuntime stops the executor committee node for the given runtime and unregisters it.
+//
+// The executor committee node is only stopped once the node is no longer elected into the
+// runtime's executor committee so that it does not fail to perform its duties.
+func (w *Worker) RemoveRuntime(ctx context.Context, id common.Namespace) error {
+	rt := w.GetRuntime(id)
+	if rt == nil {
+		return nil
+	}
+
+	if commonNode := w.commonWorker.GetRuntime(id); commonNode != nil {
+		if err := commonNode.WaitNotElected(ctx); err != nil {
+			return err
+		}
+	}
+
+	w.runtimesLock.Lock()
+	delete(w.runtimes, id)
+	w.runtimesLock.Unlock()
+
+	w.logger.Info("stopping services for runtime",
+		"runtime_id", id,
+	)
+
+	rt.Stop()
+	select {
+	case <-rt.Quit():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	rt.Cleanup()
+
+	w.logger.Info("runtime removed",
+		"runtime_id", id,
+	)
+
+	return nil
+}
+
 func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 	id := commonNode.Runtime.ID()
 	w.logger.Info("registering new runtime",
@@ -155,6 +236,15 @@ func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 		return err
 	}
 
+	w.runtimesLock.Lock()
+	defer w.runtimesLock.Unlock()
+
+	select {
+	case <-w.stopCh:
+		return fmt.Errorf("worker/executor: worker is stopped")
+	default:
+	}
+
 	commonNode.AddHooks(node)
 	w.runtimes[id] = node
 
@@ -188,6 +278,7 @@ func New(
 		runtimes:     make(map[common.Namespace]*committee.Node),
 		ctx:          ctx,
 		cancelCtx:    cancelCtx,
+		stopCh:       make(chan struct{}),
 		quitCh:       make(chan struct{}),
 		initCh:       make(chan struct{}),
 		logger:       logging.GetLogger("worker/executor"),
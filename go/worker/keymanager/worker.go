@@ -515,6 +515,18 @@ func (w *Worker) worker() { // nolint: gocyclo
 				// Worker failed to start or was stopped -- we can no longer service requests.
 				currentStartedEvent = nil
 				w.roleProvider.SetUnavailable()
+			case ev.ResourceExceeded != nil:
+				// Worker exceeded its resource limits and will be stopped.
+				w.logger.Warn("runtime exceeded its resource limits",
+					"resource", ev.ResourceExceeded.Resource,
+				)
+			case ev.Degraded != nil:
+				// Worker degraded state has changed, nothing to do as the key manager keeps
+				// servicing requests whenever the runtime is running.
+				w.logger.Warn("runtime degraded state changed",
+					"degraded", ev.Degraded.Degraded,
+					"restarts", ev.Degraded.Restarts,
+				)
 			default:
 				// Unknown event.
 				w.logger.Warn("unknown worker event",
@@ -630,6 +630,27 @@ func (w *Worker) newRoleProvider(role node.RolesMask, runtimeID *common.Namespac
 	return rp, nil
 }
 
+// RemoveRuntimeRoleProviders removes all role provider slots for the given runtime and triggers
+// a node re-registration so that the runtime is no longer included in the node descriptor.
+func (w *Worker) RemoveRuntimeRoleProviders(runtimeID common.Namespace) {
+	w.logger.Debug("removing runtime role providers",
+		"id", runtimeID,
+	)
+
+	w.Lock()
+	var roleProviders []*roleProvider
+	for _, rp := range w.roleProviders {
+		if rp.runtimeID != nil && rp.runtimeID.Equal(&runtimeID) {
+			continue
+		}
+		roleProviders = append(roleProviders, rp)
+	}
+	w.roleProviders = roleProviders
+	w.Unlock()
+
+	w.registerCh <- struct{}{}
+}
+
 func (w *Worker) gatherConsensusAddresses(sentryConsensusAddrs []node.ConsensusAddress) ([]node.ConsensusAddress, error) {
 	var consensusAddrs []node.ConsensusAddress
 	var err error
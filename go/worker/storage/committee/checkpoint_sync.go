@@ -6,26 +6,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 
-	"github.com/oasisprotocol/oasis-core/go/runtime/nodes/grpc"
 	storageApi "github.com/oasisprotocol/oasis-core/go/storage/api"
-	storageClient "github.com/oasisprotocol/oasis-core/go/storage/client"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	"github.com/oasisprotocol/oasis-core/go/worker/storage/p2p/checkpointsync"
 )
 
 const (
-	// cpListTimeout is the timeout for fetching a list of checkpoints from a node.
-	cpListTimeout = 5 * time.Second
-	// cpListsTimeout is the timeout for fetching checkpoints from all nodes.
+	// cpListsTimeout is the timeout for fetching checkpoints from all peers.
 	cpListsTimeout = 30 * time.Second
-	// cpRestoreTimeout is the timeout for restoring a checkpoint chunk from a node.
+	// cpRestoreTimeout is the timeout for restoring a checkpoint chunk.
 	cpRestoreTimeout = 60 * time.Second
+	// cpMaxWorkers is the maximum number of concurrent chunk workers.
+	cpMaxWorkers = 16
 
 	retryInterval = 1 * time.Second
 	maxRetries    = 30
@@ -41,11 +39,6 @@ const (
 // ErrNoUsableCheckpoints is the error returned when none of the checkpoints could be synced.
 var ErrNoUsableCheckpoints = errors.New("storage: no checkpoint could be synced")
 
-type restoreResult struct {
-	done bool
-	err  error
-}
-
 type chunkHeap struct {
 	array  []*checkpoint.ChunkMetadata
 	length int
@@ -67,141 +60,100 @@ func (h *chunkHeap) Pop() interface{} {
 	return ret
 }
 
-// goWithNodes runs the given operation with all the connections in the provided nodesClient.
-func (n *Node) goWithNodes(
-	nodesClient grpc.NodesClient,
-	fn func(context.Context, *grpc.ConnWithNodeMeta) error,
-) (
-	context.CancelFunc,
-	chan interface{},
-	error,
+func (n *Node) chunkWorker(
+	ctx context.Context,
+	cp *checkpointsync.Checkpoint,
+	chunkDispatchCh <-chan *checkpoint.ChunkMetadata,
+	chunkReturnCh chan<- *checkpoint.ChunkMetadata,
+	errorCh chan<- int,
 ) {
-	connCh := make(chan []*grpc.ConnWithNodeMeta)
-	connGetter := func() error {
-		conns := nodesClient.GetConnectionsWithMeta()
-		if len(conns) == 0 {
-			return storageClient.ErrStorageNotAvailable
+	returnChunk := func(chunk *checkpoint.ChunkMetadata) {
+		select {
+		case chunkReturnCh <- chunk:
+		case <-ctx.Done():
 		}
-		connCh <- conns
-		return nil
-	}
-	go func() {
-		sched := backoff.WithMaxRetries(backoff.NewConstantBackOff(retryInterval), maxRetries)
-		_ = backoff.Retry(connGetter, backoff.WithContext(sched, n.ctx))
-		close(connCh)
-	}()
-	conns, ok := <-connCh
-	if !ok || len(conns) == 0 {
-		return nil, nil, storageClient.ErrStorageNotAvailable
 	}
-
-	workerCtx, workerCancel := context.WithCancel(n.ctx)
-	var workerGroup sync.WaitGroup
-	doneCh := make(chan interface{})
-
-	for _, conn := range conns {
-		workerGroup.Add(1)
-		go func(conn *grpc.ConnWithNodeMeta) {
-			defer workerGroup.Done()
-			op := func() error {
-				return fn(workerCtx, conn)
-			}
-			sched := backoff.WithMaxRetries(backoff.NewConstantBackOff(retryInterval), maxRetries)
-			_ = backoff.Retry(op, backoff.WithContext(sched, workerCtx))
-		}(conn)
+	reportStatus := func(status int) {
+		select {
+		case errorCh <- status:
+		case <-ctx.Done():
+		}
 	}
-	go func() {
-		defer close(doneCh)
-		workerGroup.Wait()
-	}()
-
-	return workerCancel, doneCh, nil
-}
 
-func (n *Node) nodeWorker(
-	ctx context.Context,
-	conn *grpc.ConnWithNodeMeta,
-	chunkDispatchCh chan *checkpoint.ChunkMetadata,
-	chunkReturnCh chan *checkpoint.ChunkMetadata,
-	errorCh chan int,
-) error {
-	api := storageApi.NewStorageClient(conn.ClientConn)
 	for {
 		var chunk *checkpoint.ChunkMetadata
 		var ok bool
 		select {
 		case <-ctx.Done():
-			return backoff.Permanent(ctx.Err())
+			return
 		case chunk, ok = <-chunkDispatchCh:
 			if !ok {
-				return nil
+				return
 			}
 		}
 
-		chunkCtx, cancel := context.WithTimeout(ctx, cpRestoreTimeout)
-		defer cancel()
-
-		restoreCh := make(chan *restoreResult)
-		rd, wr := io.Pipe()
-		go func() {
-			done, err := n.localStorage.Checkpointer().RestoreChunk(chunkCtx, chunk.Index, rd)
-			restoreCh <- &restoreResult{
-				done: done,
-				err:  err,
-			}
-		}()
-		err := api.GetCheckpointChunk(chunkCtx, chunk, wr)
-		wr.Close()
-		result := <-restoreCh
-		cancel()
-
-		// GetCheckpointChunk errors.
-		// The chunk probably always needs to be returned here
-		// (otherwise there's a deadlock risk with one worker's backoff just aborting
-		// and another worker then blocking on its chunk).
+		// Fetch the chunk from peers. Chunks are verified against their digest by the client.
+		var buf bytes.Buffer
+		err := n.checkpointSync.GetCheckpointChunk(ctx, chunk, cp, &buf)
 		switch {
 		case err == nil:
-			// Fall out of the switch.
-		case err != nil:
-			n.logger.Error("can't fetch chunk from storage node", "node", conn.Node.ID, "chunk", chunk.Index, "err", err)
-			chunkReturnCh <- chunk
-			fallthrough
-		case errors.Is(err, checkpoint.ErrChunkNotFound):
-			return backoff.Permanent(err)
+		case errors.Is(err, checkpointsync.ErrNoPeers):
+			// None of the peers that advertised the checkpoint are usable anymore.
+			n.logger.Error("no peers left to fetch chunk from",
+				"chunk", chunk.Index,
+				"root", chunk.Root,
+			)
+			reportStatus(checkpointStatusNext)
+			return
 		default:
-			return err
+			// The chunk needs to be returned here as otherwise no other worker could retry it.
+			n.logger.Error("can't fetch chunk from peers",
+				"chunk", chunk.Index,
+				"root", chunk.Root,
+				"err", err,
+			)
+			returnChunk(chunk)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
 		}
 
-		// RestoreChunk errors.
+		chunkCtx, cancel := context.WithTimeout(ctx, cpRestoreTimeout)
+		done, err := n.localStorage.Checkpointer().RestoreChunk(chunkCtx, chunk.Index, &buf)
+		cancel()
+
 		switch {
-		case result.done:
+		case done:
 			// Signal to the toplevel handler that we're done.
-			chunkReturnCh <- nil
-			return nil
-		case result.err != nil:
+			returnChunk(nil)
+			return
+		case err == nil:
+		default:
 			n.logger.Error("chunk restoration failed",
-				"node", conn.Node.ID,
 				"chunk", chunk.Index,
 				"root", chunk.Root,
-				"err", result.err,
+				"err", err,
 			)
 			switch {
-			case errors.Is(result.err, checkpoint.ErrChunkCorrupted):
-				chunkReturnCh <- chunk
-				return result.err
-			case errors.Is(result.err, checkpoint.ErrChunkProofVerificationFailed):
-				errorCh <- checkpointStatusNext
-				return backoff.Permanent(result.err)
+			case errors.Is(err, checkpoint.ErrChunkProofVerificationFailed):
+				// The chunk matches the checkpoint manifest but not the checkpoint root, so all
+				// peers that advertised the checkpoint are serving invalid data.
+				n.checkpointSync.RecordInvalidCheckpoint(cp)
+				reportStatus(checkpointStatusNext)
 			default:
-				errorCh <- checkpointStatusBail
-				return backoff.Permanent(result.err)
+				reportStatus(checkpointStatusBail)
 			}
+			return
 		}
 	}
 }
 
-func (n *Node) handleCheckpoint(check *checkpoint.Metadata, nodesClient grpc.NodesClient, groupSize uint16) (cpStatus int, rerr error) {
-	if err := n.localStorage.Checkpointer().StartRestore(n.ctx, check); err != nil {
+func (n *Node) handleCheckpoint(check *checkpointsync.Checkpoint) (cpStatus int, rerr error) {
+	if err := n.localStorage.Checkpointer().StartRestore(n.ctx, check.Metadata); err != nil {
 		// Any previous restores were already aborted by the driver up the call stack, so
 		// things should have been going smoothly here; bail.
 		return checkpointStatusBail, fmt.Errorf("can't start checkpoint restore: %w", err)
@@ -222,20 +174,30 @@ func (n *Node) handleCheckpoint(check *checkpoint.Metadata, nodesClient grpc.Nod
 		}
 	}()
 
-	chunkDispatchCh := make(chan *checkpoint.ChunkMetadata)
-	defer close(chunkDispatchCh)
-
-	chunkReturnCh := make(chan *checkpoint.ChunkMetadata, groupSize)
-	errorCh := make(chan int, groupSize)
-
-	worker := func(ctx context.Context, conn *grpc.ConnWithNodeMeta) error {
-		return n.nodeWorker(ctx, conn, chunkDispatchCh, chunkReturnCh, errorCh)
+	// Use one chunk worker per peer that advertised the checkpoint.
+	numWorkers := len(check.Peers)
+	if numWorkers > cpMaxWorkers {
+		numWorkers = cpMaxWorkers
 	}
 
-	cancel, doneCh, err := n.goWithNodes(nodesClient, worker)
-	if err != nil {
-		return checkpointStatusBail, fmt.Errorf("can't fetch chunks from committee nodes: %w", err)
+	chunkDispatchCh := make(chan *checkpoint.ChunkMetadata)
+	chunkReturnCh := make(chan *checkpoint.ChunkMetadata, numWorkers)
+	errorCh := make(chan int, numWorkers)
+
+	workerCtx, cancel := context.WithCancel(n.ctx)
+	var workerGroup sync.WaitGroup
+	doneCh := make(chan struct{})
+	for i := 0; i < numWorkers; i++ {
+		workerGroup.Add(1)
+		go func() {
+			defer workerGroup.Done()
+			n.chunkWorker(workerCtx, check, chunkDispatchCh, chunkReturnCh, errorCh)
+		}()
 	}
+	go func() {
+		defer close(doneCh)
+		workerGroup.Wait()
+	}()
 	// Cancel on exit and wait for the worker pool to drain so that the abort
 	// above can proceed safely.
 	defer func() {
@@ -261,6 +223,7 @@ func (n *Node) handleCheckpoint(check *checkpoint.Metadata, nodesClient grpc.Nod
 	n.logger.Debug("checkpoint chunks prepared for dispatch",
 		"chunks", len(check.Chunks),
 		"checkpoint_root", check.Root,
+		"num_peers", len(check.Peers),
 	)
 
 	// Feed the workers with chunks.
@@ -296,8 +259,8 @@ func (n *Node) handleCheckpoint(check *checkpoint.Metadata, nodesClient grpc.Nod
 			next = nil
 
 		case <-doneCh:
-			// No usable committee connections left, move on to the next checkpoint.
-			return checkpointStatusNext, storageClient.ErrStorageNotAvailable
+			// No workers left, move on to the next checkpoint.
+			return checkpointStatusNext, checkpointsync.ErrNoPeers
 		}
 
 		if next != nil {
@@ -306,60 +269,29 @@ func (n *Node) handleCheckpoint(check *checkpoint.Metadata, nodesClient grpc.Nod
 	}
 }
 
-func (n *Node) getCheckpointList(nodesClient grpc.NodesClient) ([]*checkpoint.Metadata, error) {
-	// Get checkpoint list from all current committee members.
-	listCh := make(chan []*checkpoint.Metadata)
-	req := &checkpoint.GetCheckpointsRequest{
-		Version:   1,
-		Namespace: n.commonNode.Runtime.ID(),
+func (n *Node) getCheckpointList() ([]*checkpointsync.Checkpoint, error) {
+	ctx, cancel := context.WithTimeout(n.ctx, cpListsTimeout)
+	defer cancel()
+
+	// Get checkpoint list from all connected peers, waiting for peers to become available.
+	req := &checkpointsync.GetCheckpointsRequest{
+		Version: 1,
 	}
-	getter := func(opCtx context.Context, conn *grpc.ConnWithNodeMeta) error {
-		ctx, cancel := context.WithTimeout(opCtx, cpListTimeout)
-		defer cancel()
-
-		api := storageApi.NewStorageClient(conn.ClientConn)
-		meta, err := api.GetCheckpoints(ctx, req)
-		if err != nil {
-			n.logger.Error("error calling GetCheckpoints",
-				"err", err,
-				"node", conn.Node.ID,
-				"this_node", n.commonNode.Identity.NodeSigner.Public,
-			)
-			return err
+	var list []*checkpointsync.Checkpoint
+	getter := func() error {
+		var err error
+		list, err = n.checkpointSync.GetCheckpoints(ctx, req)
+		if err != nil && !errors.Is(err, checkpointsync.ErrNoPeers) {
+			return backoff.Permanent(err)
 		}
-		n.logger.Debug("got checkpoint list from a node",
-			"length", len(meta),
-			"node", conn.Node.ID,
-		)
-		listCh <- meta
-		return nil
+		return err
 	}
-
-	cancel, doneCh, err := n.goWithNodes(nodesClient, getter)
-	if err != nil {
+	sched := backoff.WithMaxRetries(backoff.NewConstantBackOff(retryInterval), maxRetries)
+	if err := backoff.Retry(getter, backoff.WithContext(sched, ctx)); err != nil {
 		return nil, err
 	}
-	defer cancel()
-	// Wait at most cpListsTimeout for results.
-	go func() {
-		<-time.After(cpListsTimeout)
-		cancel()
-	}()
-
-	var list []*checkpoint.Metadata
-resultLoop:
-	for {
-		select {
-		case <-doneCh:
-			break resultLoop
-		case <-n.ctx.Done():
-			return nil, n.ctx.Err()
-		case meta := <-listCh:
-			list = append(list, meta...)
-		}
-	}
 
-	// Prepare the list: sort and deduplicate.
+	// Prepare the list: sort by descending version.
 	sort.Slice(list, func(i, j int) bool {
 		// Descending!
 		if list[j].Root.Version == list[i].Root.Version {
@@ -367,17 +299,8 @@ resultLoop:
 		}
 		return list[j].Root.Version < list[i].Root.Version
 	})
-	retList := make([]*checkpoint.Metadata, len(list))
-	var prevCheckpoint *checkpoint.Metadata
-	cursor := 0
-	for i := 0; i < len(list); i++ {
-		if prevCheckpoint == nil || !list[i].Root.Equal(&prevCheckpoint.Root) {
-			retList[cursor] = list[i]
-			cursor++
-		}
-	}
 
-	return retList[:cursor], nil
+	return list, nil
 }
 
 func (n *Node) checkCheckpointUsable(cp *checkpoint.Metadata, remainingMask outstandingMask) bool {
@@ -417,16 +340,10 @@ func (n *Node) syncCheckpoints(genesisRound uint64) (*blockSummary, error) {
 	// for errors, driven by remainingRoots.
 	var syncState blockSummary
 
-	// Try getting the active descriptor first.
-	descriptor, err := n.commonNode.Runtime.ActiveDescriptor(n.ctx)
+	// Fetch metadata from peers.
+	metadata, err := n.getCheckpointList()
 	if err != nil {
-		return nil, fmt.Errorf("can't get runtime descriptor: %w", err)
-	}
-
-	// Fetch metadata from the current committee.
-	metadata, err := n.getCheckpointList(n.storageNodesGrpc)
-	if err != nil {
-		return nil, fmt.Errorf("can't get checkpoint list from storage committee: %w", err)
+		return nil, fmt.Errorf("can't get checkpoint list from peers: %w", err)
 	}
 
 	// Try all the checkpoints now, from most recent backwards.
@@ -449,7 +366,7 @@ func (n *Node) syncCheckpoints(genesisRound uint64) (*blockSummary, error) {
 	}()
 
 	for _, check := range metadata {
-		if check.Root.Version < genesisRound || !n.checkCheckpointUsable(check, remainingRoots) {
+		if check.Root.Version < genesisRound || !n.checkCheckpointUsable(check.Metadata, remainingRoots) {
 			continue
 		}
 
@@ -470,10 +387,7 @@ func (n *Node) syncCheckpoints(genesisRound uint64) (*blockSummary, error) {
 			syncState.Roots = nil
 		}
 
-		// Compute how many nodes we will be asking for checkpoints.
-		groupSize := descriptor.Executor.GroupSize + descriptor.Executor.GroupBackupSize
-
-		status, err := n.handleCheckpoint(check, n.storageNodesGrpc, groupSize)
+		status, err := n.handleCheckpoint(check)
 		switch status {
 		case checkpointStatusDone:
 			n.logger.Info("successfully restored from checkpoint", "root", check.Root, "mask", mask)
@@ -35,6 +35,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 	"github.com/oasisprotocol/oasis-core/go/worker/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/storage/p2p/checkpointsync"
 )
 
 var (
@@ -186,6 +187,7 @@ type Node struct { // nolint: maligned
 	workerCommonCfg workerCommon.Config
 
 	checkpointer           checkpoint.Checkpointer
+	checkpointSync         checkpointsync.Client
 	checkpointSyncDisabled bool
 	checkpointSyncForced   bool
 
@@ -339,6 +341,10 @@ func NewNode(
 		}
 	}
 
+	// Serve checkpoints to peers and create a client for syncing checkpoints from peers.
+	commonNode.P2P.RegisterProtocol(rtID, checkpointsync.ProtocolName, checkpointsync.NewServer(rtID, localStorage).HandleStream)
+	n.checkpointSync = checkpointsync.NewClient(commonNode.P2P, rtID)
+
 	// Register prune handler.
 	commonNode.Runtime.History().Pruner().RegisterHandler(&pruneHandler{
 		logger: n.logger,
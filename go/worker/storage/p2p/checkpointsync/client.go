@@ -0,0 +1,331 @@
+package checkpointsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	core "github.com/libp2p/go-libp2p-core"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+const (
+	// peerScoreMax is the maximum score of a peer.
+	peerScoreMax = 10
+	// peerScoreMin is the minimum score of a peer.
+	peerScoreMin = -30
+	// peerScoreIgnored is the score at or below which a peer is no longer used.
+	peerScoreIgnored = -10
+
+	// peerScoreDecayInterval is the interval after which a peer's score moves one point back
+	// towards zero, so that peers are eventually retried after transient failures.
+	peerScoreDecayInterval = 1 * time.Minute
+	// maxTrackedPeers is the maximum number of peers for which scores are kept.
+	maxTrackedPeers = 1024
+
+	// peerScoreSuccess is the score change after a peer successfully serves a request.
+	peerScoreSuccess = 1
+	// peerScoreFailure is the score change after a peer fails to serve a request (e.g., because it
+	// is slow or unreachable).
+	peerScoreFailure = -2
+	// peerScoreInvalid is the score change after a peer serves a chunk which fails hash
+	// verification or advertises a checkpoint which fails proof verification.
+	peerScoreInvalid = -20
+)
+
+// ErrNoPeers is the error returned when no peers are available to serve a request.
+var ErrNoPeers = errors.New(moduleName, 1, "checkpointsync: no peers available")
+
+// Checkpoint contains checkpoint metadata together with the peers that advertised it.
+type Checkpoint struct {
+	*checkpoint.Metadata
+
+	// Peers are the peers that advertised the checkpoint.
+	Peers []core.PeerID
+}
+
+// Client is a checkpoint sync protocol client.
+type Client interface {
+	// GetCheckpoints returns the checkpoints advertised by all connected peers.
+	GetCheckpoints(ctx context.Context, request *GetCheckpointsRequest) ([]*Checkpoint, error)
+
+	// GetCheckpointChunk fetches the given chunk of the given checkpoint from one of the peers that
+	// advertised the checkpoint.
+	//
+	// The chunk is verified against its digest before it is written to the given writer and peers
+	// that serve corrupted chunks are penalized.
+	//
+	// Concurrent requests are spread across all well-behaved peers that advertised the checkpoint.
+	GetCheckpointChunk(ctx context.Context, chunk *checkpoint.ChunkMetadata, cp *Checkpoint, w io.Writer) error
+
+	// RecordInvalidCheckpoint penalizes all peers that advertised the given checkpoint after one
+	// of its chunks failed proof verification against the checkpoint root.
+	RecordInvalidCheckpoint(cp *Checkpoint)
+}
+
+type peerScore struct {
+	score   int
+	updated time.Time
+}
+
+// current returns the score decayed towards zero based on the time since the last update.
+func (ps *peerScore) current(now time.Time) int {
+	decay := int(now.Sub(ps.updated) / peerScoreDecayInterval)
+	switch {
+	case ps.score > decay:
+		return ps.score - decay
+	case ps.score < -decay:
+		return ps.score + decay
+	default:
+		return 0
+	}
+}
+
+type client struct {
+	p2p *p2p.P2P
+	rc  rpc.Client
+
+	runtimeID common.Namespace
+
+	scoresLock sync.Mutex
+	scores     map[core.PeerID]*peerScore
+
+	// nextPeer is used to spread concurrent chunk requests across peers.
+	nextPeer uint64
+
+	logger *logging.Logger
+}
+
+func (c *client) GetCheckpoints(ctx context.Context, request *GetCheckpointsRequest) ([]*Checkpoint, error) {
+	peers := c.usablePeers(c.p2p.PeersForProtocol(c.runtimeID, ProtocolName))
+	if len(peers) == 0 {
+		return nil, ErrNoPeers
+	}
+
+	rsps, rspPeers := c.rc.CallMulti(ctx, peers, MethodGetCheckpoints, request, GetCheckpointsResponse{},
+		rpc.WithMaxPeerResponseTime(getCheckpointsTimeout),
+	)
+
+	// Update peer scores based on which peers responded.
+	responded := make(map[core.PeerID]bool, len(rspPeers))
+	for _, peerID := range rspPeers {
+		responded[peerID] = true
+		c.updatePeerScore(peerID, peerScoreSuccess)
+	}
+	for _, peerID := range peers {
+		if !responded[peerID] {
+			c.updatePeerScore(peerID, peerScoreFailure)
+		}
+	}
+
+	// Merge identical checkpoints advertised by different peers.
+	var cps []*Checkpoint
+	cpsByHash := make(map[hash.Hash]*Checkpoint)
+	for i, rsp := range rsps {
+		for _, md := range rsp.(*GetCheckpointsResponse).Checkpoints {
+			h := hash.NewFrom(md)
+			cp := cpsByHash[h]
+			if cp == nil {
+				cp = &Checkpoint{Metadata: md}
+				cpsByHash[h] = cp
+				cps = append(cps, cp)
+			}
+			cp.Peers = append(cp.Peers, rspPeers[i])
+		}
+	}
+	return cps, nil
+}
+
+func (c *client) GetCheckpointChunk(ctx context.Context, chunk *checkpoint.ChunkMetadata, cp *Checkpoint, w io.Writer) error {
+	peers := c.usablePeers(cp.Peers)
+	if len(peers) == 0 {
+		return ErrNoPeers
+	}
+	peers = c.rotatePeers(peers)
+
+	var err error
+	for _, peerID := range peers {
+		var data []byte
+		data, err = c.getCheckpointChunkFromPeer(ctx, peerID, chunk)
+		switch {
+		case err == nil:
+			c.updatePeerScore(peerID, peerScoreSuccess)
+			_, err = w.Write(data)
+			return err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, checkpoint.ErrChunkCorrupted):
+			c.updatePeerScore(peerID, peerScoreInvalid)
+		default:
+			c.updatePeerScore(peerID, peerScoreFailure)
+		}
+
+		c.logger.Warn("failed to get checkpoint chunk from peer",
+			"err", err,
+			"peer_id", peerID,
+			"root", chunk.Root,
+			"chunk", chunk.Index,
+		)
+	}
+	return fmt.Errorf("failed to get checkpoint chunk from any peer: %w", err)
+}
+
+func (c *client) RecordInvalidCheckpoint(cp *Checkpoint) {
+	for _, peerID := range cp.Peers {
+		c.updatePeerScore(peerID, peerScoreInvalid)
+	}
+
+	c.logger.Warn("penalized peers that advertised an invalid checkpoint",
+		"root", cp.Root,
+		"num_peers", len(cp.Peers),
+	)
+}
+
+func (c *client) getCheckpointChunkFromPeer(ctx context.Context, peerID core.PeerID, chunk *checkpoint.ChunkMetadata) ([]byte, error) {
+	var rsp GetCheckpointChunkResponse
+	err := c.rc.Call(ctx, peerID, MethodGetCheckpointChunk, chunk, &rsp,
+		rpc.WithMaxPeerResponseTime(getCheckpointChunkTimeout),
+		rpc.WithMaxResponseSize(maxChunkResponseSize),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hb := hash.NewBuilder()
+	_, _ = hb.Write(rsp.Chunk)
+	if h := hb.Build(); !h.Equal(&chunk.Digest) {
+		return nil, fmt.Errorf("%w: digest incorrect (expected: %s got: %s)",
+			checkpoint.ErrChunkCorrupted,
+			chunk.Digest,
+			h,
+		)
+	}
+	return rsp.Chunk, nil
+}
+
+// usablePeers returns the given peers without the ignored ones, ordered by descending score.
+// Peers with equal scores are shuffled to spread the load.
+func (c *client) usablePeers(peers []core.PeerID) []core.PeerID {
+	c.scoresLock.Lock()
+	defer c.scoresLock.Unlock()
+
+	now := time.Now()
+	scores := make(map[core.PeerID]int, len(peers))
+	usable := make([]core.PeerID, 0, len(peers))
+	for _, peerID := range peers {
+		score := c.peerScoreLocked(peerID, now)
+		if score > peerScoreIgnored {
+			scores[peerID] = score
+			usable = append(usable, peerID)
+		}
+	}
+	rand.Shuffle(len(usable), func(i, j int) {
+		usable[i], usable[j] = usable[j], usable[i]
+	})
+	sort.SliceStable(usable, func(i, j int) bool {
+		return scores[usable[i]] > scores[usable[j]]
+	})
+	return usable
+}
+
+// rotatePeers rotates the well-behaved (non-negative score) peers at the front of the given list
+// of usable peers so that concurrent requests don't all start with the same peer.
+func (c *client) rotatePeers(peers []core.PeerID) []core.PeerID {
+	c.scoresLock.Lock()
+	now := time.Now()
+	var numGood int
+	for numGood < len(peers) && c.peerScoreLocked(peers[numGood], now) >= 0 {
+		numGood++
+	}
+	c.scoresLock.Unlock()
+	if numGood < 2 {
+		return peers
+	}
+
+	offset := int(atomic.AddUint64(&c.nextPeer, 1) % uint64(numGood))
+	rotated := make([]core.PeerID, 0, len(peers))
+	rotated = append(rotated, peers[offset:numGood]...)
+	rotated = append(rotated, peers[:offset]...)
+	return append(rotated, peers[numGood:]...)
+}
+
+func (c *client) peerScoreLocked(peerID core.PeerID, now time.Time) int {
+	ps := c.scores[peerID]
+	if ps == nil {
+		return 0
+	}
+	return ps.current(now)
+}
+
+func (c *client) updatePeerScore(peerID core.PeerID, delta int) {
+	c.scoresLock.Lock()
+	defer c.scoresLock.Unlock()
+
+	now := time.Now()
+	ps := c.scores[peerID]
+	if ps == nil {
+		c.evictPeerScoresLocked(now)
+		ps = &peerScore{}
+		c.scores[peerID] = ps
+	}
+
+	score := ps.current(now) + delta
+	switch {
+	case score > peerScoreMax:
+		score = peerScoreMax
+	case score < peerScoreMin:
+		score = peerScoreMin
+	}
+	ps.score = score
+	ps.updated = now
+}
+
+// evictPeerScoresLocked makes room for a new peer score in case the maximum number of tracked
+// peers has been reached by removing all scores that have fully decayed or, if there are none, the
+// least recently updated score.
+func (c *client) evictPeerScoresLocked(now time.Time) {
+	if len(c.scores) < maxTrackedPeers {
+		return
+	}
+
+	var (
+		oldestPeer    core.PeerID
+		oldestUpdated time.Time
+	)
+	for peerID, ps := range c.scores {
+		if ps.current(now) == 0 {
+			delete(c.scores, peerID)
+			continue
+		}
+		if oldestUpdated.IsZero() || ps.updated.Before(oldestUpdated) {
+			oldestPeer = peerID
+			oldestUpdated = ps.updated
+		}
+	}
+	if len(c.scores) >= maxTrackedPeers {
+		delete(c.scores, oldestPeer)
+	}
+}
+
+// NewClient creates a new checkpoint sync protocol client.
+func NewClient(p2p *p2p.P2P, runtimeID common.Namespace) Client {
+	return &client{
+		p2p:       p2p,
+		rc:        rpc.NewClient(p2p, runtimeID, ProtocolName),
+		runtimeID: runtimeID,
+		scores:    make(map[core.PeerID]*peerScore),
+		logger:    logging.GetLogger("worker/storage/p2p/checkpointsync/client").With("runtime_id", runtimeID),
+	}
+}
@@ -0,0 +1,123 @@
+package checkpointsync
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	core "github.com/libp2p/go-libp2p-core"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+)
+
+func TestPeerScoring(t *testing.T) {
+	require := require.New(t)
+
+	c := NewClient(nil, common.Namespace{}).(*client)
+	peerA := core.PeerID("peer A")
+	peerB := core.PeerID("peer B")
+	peerC := core.PeerID("peer C")
+	peers := []core.PeerID{peerA, peerB, peerC}
+
+	require.Len(c.usablePeers(peers), 3, "all peers should initially be usable")
+
+	// Peers that successfully serve requests should be preferred.
+	c.updatePeerScore(peerB, peerScoreSuccess)
+	c.updatePeerScore(peerC, peerScoreFailure)
+	usable := c.usablePeers(peers)
+	require.Equal([]core.PeerID{peerB, peerA, peerC}, usable, "peers should be ordered by score")
+
+	// Scores should be bounded.
+	for i := 0; i < 2*peerScoreMax; i++ {
+		c.updatePeerScore(peerB, peerScoreSuccess)
+	}
+	require.EqualValues(peerScoreMax, c.scores[peerB].score)
+
+	// Peers serving corrupted chunks should be ignored, even if they were previously good.
+	c.updatePeerScore(peerB, peerScoreInvalid)
+	usable = c.usablePeers(peers)
+	require.Equal([]core.PeerID{peerA, peerC}, usable, "peers serving corrupted chunks should be ignored")
+
+	// Slow or unreachable peers should eventually be ignored.
+	for i := 0; i < -peerScoreIgnored/-peerScoreFailure; i++ {
+		c.updatePeerScore(peerC, peerScoreFailure)
+	}
+	usable = c.usablePeers(peers)
+	require.Equal([]core.PeerID{peerA}, usable, "failing peers should be ignored")
+}
+
+func TestPeerScoreDecay(t *testing.T) {
+	require := require.New(t)
+
+	c := NewClient(nil, common.Namespace{}).(*client)
+	peerA := core.PeerID("peer A")
+	peerB := core.PeerID("peer B")
+	peers := []core.PeerID{peerA, peerB}
+
+	// Peers ignored after transient failures should be retried after their scores decay.
+	for i := 0; i < -peerScoreIgnored/-peerScoreFailure; i++ {
+		c.updatePeerScore(peerB, peerScoreFailure)
+	}
+	require.Equal([]core.PeerID{peerA}, c.usablePeers(peers), "failing peers should be ignored")
+	c.scores[peerB].updated = c.scores[peerB].updated.Add(-peerScoreDecayInterval)
+	require.Equal([]core.PeerID{peerA, peerB}, c.usablePeers(peers), "failing peers should be retried after decay")
+
+	// Peers serving invalid data should be ignored for longer.
+	c.updatePeerScore(peerB, peerScoreInvalid)
+	c.updatePeerScore(peerB, peerScoreInvalid)
+	c.scores[peerB].updated = c.scores[peerB].updated.Add(-peerScoreDecayInterval)
+	require.Equal([]core.PeerID{peerA}, c.usablePeers(peers), "invalid peers should still be ignored")
+	c.scores[peerB].updated = c.scores[peerB].updated.Add((peerScoreIgnored - peerScoreMin) * -peerScoreDecayInterval)
+	require.Equal([]core.PeerID{peerA, peerB}, c.usablePeers(peers), "invalid peers should be retried after decay")
+
+	// Good scores should decay as well.
+	c.updatePeerScore(peerA, peerScoreSuccess)
+	c.scores[peerA].updated = c.scores[peerA].updated.Add(-peerScoreDecayInterval)
+	require.EqualValues(0, c.scores[peerA].current(time.Now()), "scores should decay towards zero")
+}
+
+func TestPeerScoreBound(t *testing.T) {
+	require := require.New(t)
+
+	c := NewClient(nil, common.Namespace{}).(*client)
+	for i := 0; i < 2*maxTrackedPeers; i++ {
+		c.updatePeerScore(core.PeerID(fmt.Sprintf("peer %d", i)), peerScoreFailure)
+	}
+	require.Len(c.scores, maxTrackedPeers, "number of tracked peers should be bounded")
+
+	// Fully decayed scores should be evicted first.
+	for _, ps := range c.scores {
+		ps.updated = ps.updated.Add(-2 * peerScoreDecayInterval)
+	}
+	c.updatePeerScore(core.PeerID("new peer"), peerScoreFailure)
+	require.Len(c.scores, 1, "fully decayed scores should be evicted")
+}
+
+func TestPeerRotation(t *testing.T) {
+	require := require.New(t)
+
+	c := NewClient(nil, common.Namespace{}).(*client)
+	peerA := core.PeerID("peer A")
+	peerB := core.PeerID("peer B")
+	peerC := core.PeerID("peer C")
+	c.updatePeerScore(peerA, peerScoreSuccess)
+	c.updatePeerScore(peerC, peerScoreFailure)
+	peers := c.usablePeers([]core.PeerID{peerA, peerB, peerC})
+
+	// Consecutive requests should start with different well-behaved peers while failing peers
+	// should always be tried last.
+	first := make(map[core.PeerID]bool)
+	for i := 0; i < 2; i++ {
+		rotated := c.rotatePeers(peers)
+		require.Len(rotated, 3, "rotation should keep all peers")
+		require.Equal(peerC, rotated[2], "failing peers should be tried last")
+		first[rotated[0]] = true
+	}
+	require.Len(first, 2, "requests should start with different peers")
+
+	// Peers that advertised an invalid checkpoint should no longer be used.
+	c.RecordInvalidCheckpoint(&Checkpoint{Metadata: &checkpoint.Metadata{}, Peers: []core.PeerID{peerA, peerC}})
+	require.Equal([]core.PeerID{peerB}, c.usablePeers(peers), "peers advertising invalid checkpoints should be ignored")
+}
@@ -0,0 +1,54 @@
+// Package checkpointsync implements the storage checkpoint sync P2P protocol.
+//
+// The protocol allows any node that has a runtime's storage checkpoints (including nodes that are
+// not registered in the consensus layer registry) to serve checkpoint metadata and chunks to peers
+// that are syncing.
+package checkpointsync
+
+import (
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+)
+
+// NOTE: Bump the ProtocolName version if you change any of the structures below.
+
+const (
+	// ProtocolName is the name of the checkpoint sync protocol.
+	ProtocolName = "checkpointsync/1"
+
+	// MethodGetCheckpoints is the GetCheckpoints method.
+	MethodGetCheckpoints = "GetCheckpoints"
+	// MethodGetCheckpointChunk is the GetCheckpointChunk method.
+	MethodGetCheckpointChunk = "GetCheckpointChunk"
+
+	// maxChunkSize is the maximum size of a checkpoint chunk that will be accepted from a peer.
+	maxChunkSize = 128 * 1024 * 1024
+	// maxChunkResponseSize is the maximum size of a GetCheckpointChunk response.
+	maxChunkResponseSize = maxChunkSize + 1024
+
+	// getCheckpointsTimeout is the timeout for fetching a list of checkpoints from a peer.
+	getCheckpointsTimeout = 5 * time.Second
+	// getCheckpointChunkTimeout is the timeout for fetching a checkpoint chunk from a peer.
+	getCheckpointChunkTimeout = 60 * time.Second
+)
+
+const moduleName = "worker/storage/p2p/checkpointsync"
+
+// GetCheckpointsRequest is a GetCheckpoints request.
+type GetCheckpointsRequest struct {
+	Version uint16 `json:"version"`
+}
+
+// GetCheckpointsResponse is a response to a GetCheckpoints request.
+type GetCheckpointsResponse struct {
+	Checkpoints []*checkpoint.Metadata `json:"checkpoints,omitempty"`
+}
+
+// GetCheckpointChunkRequest is a GetCheckpointChunk request.
+type GetCheckpointChunkRequest = checkpoint.ChunkMetadata
+
+// GetCheckpointChunkResponse is a response to a GetCheckpointChunk request.
+type GetCheckpointChunkResponse struct {
+	Chunk []byte `json:"chunk,omitempty"`
+}
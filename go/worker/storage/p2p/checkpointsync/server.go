@@ -0,0 +1,80 @@
+package checkpointsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p/rpc"
+)
+
+type service struct {
+	runtimeID common.Namespace
+	provider  checkpoint.ChunkProvider
+}
+
+func (s *service) HandleRequest(ctx context.Context, method string, body cbor.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodGetCheckpoints:
+		var rq GetCheckpointsRequest
+		if err := cbor.Unmarshal(body, &rq); err != nil {
+			return nil, rpc.ErrBadRequest
+		}
+
+		return s.handleGetCheckpoints(ctx, &rq)
+	case MethodGetCheckpointChunk:
+		var rq GetCheckpointChunkRequest
+		if err := cbor.Unmarshal(body, &rq); err != nil {
+			return nil, rpc.ErrBadRequest
+		}
+
+		return s.handleGetCheckpointChunk(ctx, &rq)
+	default:
+		return nil, rpc.ErrMethodNotSupported
+	}
+}
+
+func (s *service) handleGetCheckpoints(ctx context.Context, request *GetCheckpointsRequest) (*GetCheckpointsResponse, error) {
+	cps, err := s.provider.GetCheckpoints(ctx, &checkpoint.GetCheckpointsRequest{
+		Version:   request.Version,
+		Namespace: s.runtimeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetCheckpointsResponse{
+		Checkpoints: cps,
+	}, nil
+}
+
+func (s *service) handleGetCheckpointChunk(ctx context.Context, request *GetCheckpointChunkRequest) (*GetCheckpointChunkResponse, error) {
+	if !request.Root.Namespace.Equal(&s.runtimeID) {
+		return nil, fmt.Errorf("%w: unsupported namespace", rpc.ErrBadRequest)
+	}
+
+	var buf bytes.Buffer
+	if err := s.provider.GetCheckpointChunk(ctx, request, &buf); err != nil {
+		return nil, err
+	}
+
+	return &GetCheckpointChunkResponse{
+		Chunk: buf.Bytes(),
+	}, nil
+}
+
+// NewServer creates a new checkpoint sync protocol server serving checkpoints from the given
+// chunk provider.
+func NewServer(runtimeID common.Namespace, provider checkpoint.ChunkProvider) rpc.Server {
+	return rpc.NewServer(
+		ProtocolName,
+		&service{
+			runtimeID: runtimeID,
+			provider:  provider,
+		},
+		rpc.WithMaxServerResponseSize(maxChunkResponseSize),
+	)
+}
@@ -9,7 +9,7 @@ import (
 var _ api.StorageWorker = (*Worker)(nil)
 
 func (w *Worker) GetLastSyncedRound(ctx context.Context, request *api.GetLastSyncedRoundRequest) (*api.GetLastSyncedRoundResponse, error) {
-	node := w.runtimes[request.RuntimeID]
+	node := w.GetRuntime(request.RuntimeID)
 	if node == nil {
 		return nil, api.ErrRuntimeNotFound
 	}
@@ -23,7 +23,7 @@ func (w *Worker) GetLastSyncedRound(ctx context.Context, request *api.GetLastSyn
 }
 
 func (w *Worker) WaitForRound(ctx context.Context, request *api.WaitForRoundRequest) (*api.WaitForRoundResponse, error) {
-	node := w.runtimes[request.RuntimeID]
+	node := w.GetRuntime(request.RuntimeID)
 	if node == nil {
 		return nil, api.ErrRuntimeNotFound
 	}
@@ -42,7 +42,7 @@ func (w *Worker) WaitForRound(ctx context.Context, request *api.WaitForRoundRequ
 }
 
 func (w *Worker) PauseCheckpointer(ctx context.Context, request *api.PauseCheckpointerRequest) error {
-	node := w.runtimes[request.RuntimeID]
+	node := w.GetRuntime(request.RuntimeID)
 	if node == nil {
 		return api.ErrRuntimeNotFound
 	}
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/spf13/viper"
 
@@ -34,11 +36,15 @@ type Worker struct {
 	logger       *logging.Logger
 
 	initCh chan struct{}
+	stopCh chan struct{}
 	quitCh chan struct{}
 
-	runtimes   map[common.Namespace]*committee.Node
-	watchState *persistent.ServiceStore
-	fetchPool  *workerpool.Pool
+	runtimesLock sync.RWMutex
+	runtimes     map[common.Namespace]*committee.Node
+
+	watchState      *persistent.ServiceStore
+	fetchPool       *workerpool.Pool
+	checkpointerCfg *checkpoint.CheckpointerConfig
 
 	grpcPolicy *policy.DynamicRuntimePolicyChecker
 }
@@ -66,6 +72,7 @@ func New(
 		registration: registration,
 		logger:       logging.GetLogger("worker/storage"),
 		initCh:       make(chan struct{}),
+		stopCh:       make(chan struct{}),
 		quitCh:       make(chan struct{}),
 		runtimes:     make(map[common.Namespace]*committee.Node),
 	}
@@ -94,7 +101,7 @@ func New(
 			return node.GetLocalStorage(), nil
 		},
 		func() {
-			for _, node := range s.runtimes {
+			for _, node := range s.getRuntimes() {
 				<-node.Initialized()
 			}
 		},
@@ -105,16 +112,15 @@ func New(
 		storage: localRouter,
 	})
 
-	var checkpointerCfg *checkpoint.CheckpointerConfig
 	if !viper.GetBool(CfgWorkerCheckpointerDisabled) {
-		checkpointerCfg = &checkpoint.CheckpointerConfig{
+		s.checkpointerCfg = &checkpoint.CheckpointerConfig{
 			CheckInterval: viper.GetDuration(CfgWorkerCheckpointCheckInterval),
 		}
 	}
 
 	// Start storage node for every runtime.
 	for _, rt := range s.commonWorker.GetRuntimes() {
-		if err := s.registerRuntime(commonWorker.DataDir, rt, checkpointerCfg); err != nil {
+		if err := s.registerRuntime(rt); err != nil {
 			return nil, err
 		}
 	}
@@ -125,7 +131,7 @@ func New(
 	return s, nil
 }
 
-func (w *Worker) registerRuntime(dataDir string, commonNode *committeeCommon.Node, checkpointerCfg *checkpoint.CheckpointerConfig) error {
+func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 	id := commonNode.Runtime.ID()
 	w.logger.Info("registering new runtime",
 		"runtime_id", id,
@@ -143,7 +149,7 @@ func (w *Worker) registerRuntime(dataDir string, commonNode *committeeCommon.Nod
 		}
 	}
 
-	path, err := runtimeRegistry.EnsureRuntimeStateDir(dataDir, id)
+	path, err := runtimeRegistry.EnsureRuntimeStateDir(w.commonWorker.DataDir, id)
 	if err != nil {
 		return err
 	}
@@ -162,12 +168,22 @@ func (w *Worker) registerRuntime(dataDir string, commonNode *committeeCommon.Nod
 		rpRPC,
 		w.commonWorker.GetConfig(),
 		localStorage,
-		checkpointerCfg,
+		w.checkpointerCfg,
 		viper.GetBool(CfgWorkerCheckpointSyncDisabled),
 	)
 	if err != nil {
 		return err
 	}
+
+	w.runtimesLock.Lock()
+	defer w.runtimesLock.Unlock()
+
+	select {
+	case <-w.stopCh:
+		return fmt.Errorf("worker/storage: worker is stopped")
+	default:
+	}
+
 	commonNode.Runtime.RegisterStorage(newSyncedLocalStorage(node, localStorage))
 	commonNode.AddHooks(node)
 	w.runtimes[id] = node
@@ -206,13 +222,13 @@ func (w *Worker) Start() error {
 		return nil
 	}
 
+	runtimes := w.getRuntimes()
+
 	// Wait for all runtimes to terminate.
 	go func() {
 		defer close(w.quitCh)
 
-		for _, r := range w.runtimes {
-			<-r.Quit()
-		}
+		workerCommon.WaitRuntimesQuit(w.stopCh, w.getRuntimeServices)
 		if w.fetchPool != nil {
 			<-w.fetchPool.Quit()
 		}
@@ -220,16 +236,14 @@ func (w *Worker) Start() error {
 
 	// Start all runtimes and wait for initialization.
 	go func() {
-		w.logger.Info("starting storage sync services", "num_runtimes", len(w.runtimes))
+		w.logger.Info("starting storage sync services", "num_runtimes", len(runtimes))
 
-		for _, r := range w.runtimes {
+		for _, r := range runtimes {
 			_ = r.Start()
 		}
 
 		// Wait for runtimes to be initialized and the node to be registered.
-		for _, r := range w.runtimes {
-			<-r.Initialized()
-		}
+		workerCommon.WaitRuntimesInitialized(w.getRuntimeServices)
 
 		<-w.registration.InitialRegistrationCh()
 
@@ -248,7 +262,12 @@ func (w *Worker) Stop() {
 		return
 	}
 
-	for _, r := range w.runtimes {
+	// Prevent any new runtimes from being added.
+	w.runtimesLock.Lock()
+	close(w.stopCh)
+	w.runtimesLock.Unlock()
+
+	for _, r := range w.getRuntimes() {
 		r.Stop()
 	}
 	if w.fetchPool != nil {
@@ -272,5 +291,68 @@ func (w *Worker) Cleanup() {
 //
 // In case the runtime with the specified id was not configured for this node it returns nil.
 func (w *Worker) GetRuntime(id common.Namespace) *committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
 	return w.runtimes[id]
 }
+
+func (w *Worker) getRuntimes() []*committee.Node {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make([]*committee.Node, 0, len(w.runtimes))
+	for _, r := range w.runtimes {
+		runtimes = append(runtimes, r)
+	}
+	return runtimes
+}
+
+func (w *Worker) getRuntimeServices() []workerCommon.RuntimeService {
+	w.runtimesLock.RLock()
+	defer w.runtimesLock.RUnlock()
+
+	runtimes := make([]workerCommon.RuntimeService, 0, len(w.runtimes))
+	for _, r := range w.runtimes {
+		runtimes = append(runtimes, r)
+	}
+	return runtimes
+}
+
+// AddRuntime registers and starts a storage committee node for a runtime that has been added
+// while the worker is running.
+func (w *Worker) AddRuntime(commonNode *committeeCommon.Node) error {
+	if !w.enabled {
+		return nil
+	}
+
+	if err := w.registerRuntime(commonNode); err != nil {
+		return err
+	}
+	return w.GetRuntime(commonNode.Runtime.ID()).Start()
+}
+
+// RemoveRuntime stops the storage committee node for the given runtime and unregisters it.
+func (w *Worker) RemoveRuntime(ctx context.Context, id common.Namespace) error {
+	w.runtimesLock.Lock()
+	node, ok := w.runtimes[id]
+	delete(w.runtimes, id)
+	w.runtimesLock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	node.Stop()
+	select {
+	case <-node.Quit():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	node.Cleanup()
+
+	w.logger.Info("runtime removed",
+		"runtime_id", id,
+	)
+
+	return nil
+}
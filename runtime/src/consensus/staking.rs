@@ -82,6 +82,33 @@ pub struct GeneralAccount {
 
     #[cbor(optional)]
     pub allowances: Option<BTreeMap<Address, Quantity>>,
+
+    #[cbor(optional)]
+    pub vesting: Option<VestingSchedule>,
+}
+
+/// Vesting (lockup) schedule of a general account.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Hash, cbor::Encode, cbor::Decode)]
+pub struct VestingSchedule {
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub amount: Quantity,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub start: EpochTime,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub cliff: EpochTime,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub end: EpochTime,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub escrowed: Quantity,
 }
 
 /// Escrow account.
@@ -4,7 +4,7 @@
 //! This should only be used for testing.
 use std::{
     any::Any,
-    fmt,
+    env, fmt,
     process::{Child, Command},
     thread, time,
 };
@@ -23,6 +23,12 @@ use crate::{
 static PROTOCOL_SERVER_BINARY: Option<&'static str> =
     option_env!("OASIS_STORAGE_PROTOCOL_SERVER_BINARY");
 
+/// Environment variable selecting the protocol server storage backend.
+const PROTOCOL_SERVER_BACKEND_ENV: &str = "OASIS_STORAGE_PROTOCOL_SERVER_BACKEND";
+
+/// Default protocol server storage backend.
+const PROTOCOL_SERVER_DEFAULT_BACKEND: &str = "badger";
+
 /// Interoperability protocol server for testing storage.
 pub struct ProtocolServer {
     server_process: Child,
@@ -61,11 +67,15 @@ impl ProtocolServer {
 
         // Start protocol server.
         let server_binary = PROTOCOL_SERVER_BINARY.expect("no server binary configured");
+        let backend = env::var(PROTOCOL_SERVER_BACKEND_ENV)
+            .unwrap_or_else(|_| PROTOCOL_SERVER_DEFAULT_BACKEND.to_string());
         let mut server_cmd = Command::new(server_binary);
         server_cmd
             .arg("proto-server")
             .arg("--datadir")
             .arg(datadir.path())
+            .arg("--backend")
+            .arg(backend)
             .arg("--socket")
             .arg(socket_path.clone())
             .arg("--fixture")
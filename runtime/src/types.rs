@@ -296,6 +296,37 @@ pub struct CheckTxMetadata {
 
     #[cbor(optional)]
     pub weights: Option<BTreeMap<TransactionWeight, u64>>,
+
+    /// Opaque identifier of the transaction sender (used for per-sender ordering).
+    #[cbor(optional)]
+    #[cbor(default)]
+    #[cbor(skip_serializing_if = "Vec::is_empty")]
+    pub sender: Vec<u8>,
+
+    /// Transaction sequence number (nonce) among transactions of the same sender.
+    #[cbor(optional)]
+    #[cbor(default)]
+    #[cbor(skip_serializing_if = "num_traits::Zero::is_zero")]
+    pub sender_seq: u64,
+
+    /// Sequence number of the sender's next transaction that can be executed given the current
+    /// state. Must be set whenever `sender` is set as it is used to detect sequence gaps.
+    #[cbor(optional)]
+    #[cbor(default)]
+    #[cbor(skip_serializing_if = "num_traits::Zero::is_zero")]
+    pub sender_state_seq: u64,
+
+    /// Last round in which the transaction is still valid (zero means no round-based expiry).
+    #[cbor(optional)]
+    #[cbor(default)]
+    #[cbor(skip_serializing_if = "num_traits::Zero::is_zero")]
+    pub expiry_round: u64,
+
+    /// UNIX timestamp after which the transaction expires (zero means no time-based expiry).
+    #[cbor(optional)]
+    #[cbor(default)]
+    #[cbor(skip_serializing_if = "num_traits::Zero::is_zero")]
+    pub expiry_time: u64,
 }
 
 /// Transaction weight kind.